@@ -12,9 +12,13 @@ import (
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authenticator"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/daemon"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/run"
 	"github.com/leg100/otf/internal/runner"
+	"github.com/leg100/otf/internal/secrets"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -78,6 +82,7 @@ func parseFlags(ctx context.Context, args []string, out io.Writer) error {
 	cmd.Flags().StringVar(&cfg.Host, "hostname", "", "User-facing hostname for otf")
 	cmd.Flags().StringVar(&cfg.SiteToken, "site-token", "", "API token with site-wide unlimited permissions. Use with care.")
 	cmd.Flags().StringSliceVar(&cfg.SiteAdmins, "site-admins", nil, "Promote a list of users to site admin.")
+	cmd.Flags().StringVar(&cfg.SCIMToken, "scim-token", "", "Token with which an identity provider authenticates to the SCIM API, permitting it to manage users and teams.")
 	cmd.Flags().BytesHexVar(&cfg.Secret, "secret", nil, "Hex-encoded 16 byte secret for cryptographic work. Required.")
 	cmd.Flags().Int64Var(&cfg.MaxConfigSize, "max-config-size", cfg.MaxConfigSize, "Maximum permitted configuration size in bytes.")
 	cmd.Flags().StringVar(&cfg.WebhookHost, "webhook-hostname", "", "External hostname for otf webhooks")
@@ -95,12 +100,15 @@ func parseFlags(ctx context.Context, args []string, out io.Writer) error {
 	cmd.Flags().MarkDeprecated("cache-expiry", "cache no longer implemented so this flag has no effect")
 
 	cmd.Flags().DurationVar(&cfg.DeleteRunsAfter, "delete-runs-after", 0, "Delete runs older than the specified age. Specifying 0 disables run deletion.")
+	cmd.Flags().DurationVar(&cfg.AssessmentInterval, "assessment-interval", run.DefaultAssessmentInterval, "Interval between health assessments of workspaces that have assessments enabled.")
 	cmd.Flags().DurationVar(&cfg.DeleteConfigsAfter, "delete-configs-after", 0, "Delete configs older than the specified age. Specifying 0 disables config deletion.")
+	cmd.Flags().DurationVar(&cfg.RevokeTokensUnusedAfter, "revoke-tokens-unused-after", 0, "Revoke user, team, organization and agent tokens that have not been used for the specified duration. Specifying 0 disables token revocation.")
 
 	cmd.Flags().BoolVar(&cfg.SSL, "ssl", false, "Toggle SSL")
 	cmd.Flags().StringVar(&cfg.CertFile, "cert-file", "", "Path to SSL certificate (required if enabling SSL)")
 	cmd.Flags().StringVar(&cfg.KeyFile, "key-file", "", "Path to SSL key (required if enabling SSL)")
 	cmd.Flags().BoolVar(&cfg.EnableRequestLogging, "log-http-requests", false, "Log HTTP requests")
+	cmd.Flags().StringSliceVar(&cfg.TrustedProxies, "trusted-proxies", nil, "CIDR ranges or IP addresses of proxies trusted to set the X-Forwarded-For header.")
 
 	cmd.Flags().Var(cfg.GithubHostname, "github-hostname", "github hostname")
 	cmd.Flags().StringVar(&cfg.GithubClientID, "github-client-id", "", "github client ID")
@@ -110,6 +118,8 @@ func parseFlags(ctx context.Context, args []string, out io.Writer) error {
 	cmd.Flags().StringVar(&cfg.GitlabClientID, "gitlab-client-id", "", "gitlab client ID")
 	cmd.Flags().StringVar(&cfg.GitlabClientSecret, "gitlab-client-secret", "", "gitlab client secret")
 
+	cmd.Flags().Var(cfg.BitbucketHostname, "bitbucket-hostname", "bitbucket default hostname")
+
 	cmd.Flags().Var(cfg.ForgejoHostname, "forgejo-hostname", "forgejo default hostname")
 
 	cmd.Flags().StringVar(&cfg.OIDC.Name, "oidc-name", "", "User friendly OIDC name")
@@ -119,22 +129,51 @@ func parseFlags(ctx context.Context, args []string, out io.Writer) error {
 	cmd.Flags().StringSliceVar(&cfg.OIDC.Scopes, "oidc-scopes", authenticator.DefaultOIDCScopes, "OIDC scopes")
 	cmd.Flags().StringVar(&cfg.OIDC.UsernameClaim, "oidc-username-claim", string(authenticator.DefaultClaim), "OIDC claim to be used for username (name, email, or sub)")
 
+	cmd.Flags().StringVar(&cfg.SAML.Name, "saml-name", authenticator.DefaultSAMLName, "User friendly SAML identity provider name")
+	cmd.Flags().StringVar(&cfg.SAML.IDPMetadataURL, "saml-idp-metadata-url", "", "SAML identity provider metadata URL")
+	cmd.Flags().StringVar(&cfg.SAML.CertificateFile, "saml-certificate-file", "", "Path to SAML service provider certificate")
+	cmd.Flags().StringVar(&cfg.SAML.KeyFile, "saml-key-file", "", "Path to SAML service provider private key")
+	cmd.Flags().StringVar(&cfg.SAML.UsernameAttribute, "saml-username-attribute", "", "SAML assertion attribute to be used for username. If unspecified then the NameID is used")
+	cmd.Flags().StringVar(&cfg.SAML.GroupsAttribute, "saml-groups-attribute", authenticator.DefaultSAMLGroupsAttribute, "SAML assertion attribute listing the user's groups")
+	cmd.Flags().StringSliceVar(&cfg.SAML.TeamMappings, "saml-team-mapping", nil, "Map a SAML group to a team, in the format <group>=<organization>/<team>")
+
 	cmd.Flags().BoolVar(&cfg.RestrictOrganizationCreation, "restrict-org-creation", false, "Restrict organization creation capability to site admin role")
 
 	cmd.Flags().StringVar(&cfg.GoogleIAPAudience, "google-jwt-audience", "", "The Google JWT audience claim for validation. If unspecified then validation is skipped")
 
+	cmd.Flags().IntVar(&cfg.OrganizationJobQuota, "organization-job-quota", 0, "Maximum number of jobs belonging to an organization that server runners execute concurrently. Specifying 0 disables the quota.")
+
 	cmd.Flags().DurationVar(&cfg.PlanningTimeout, "planning-timeout", 2*time.Hour, "Timeout for plans.")
 	cmd.Flags().DurationVar(&cfg.ApplyingTimeout, "applying-timeout", 24*time.Hour, "Timeout for applies.")
 
-	cmd.Flags().Var(cfg.DefaultEngine, "default-engine", "Default engine for runs: terraform or tofu")
+	cmd.Flags().Var(cfg.DefaultEngine, "default-engine", "Default engine for runs: terraform, tofu, or a custom engine")
+
+	cmd.Flags().StringVar(&cfg.EngineMirrorKeyring, "engine-mirror-keyring", "", "Path to a file containing the ASCII-armored GPG public keys trusted to sign engine releases added to the engine mirror.")
+
+	cmd.Flags().StringVar(&cfg.PriceCatalog, "price-catalog", "", "Path to a JSON or CSV price catalog for estimating the costs of runs.")
 
 	logr.RegisterFlags(cmd.Flags(), &loggerConfig)
 	runner.RegisterFlags(cmd.Flags(), cfg.RunnerConfig)
+	blob.RegisterFlags(cmd.Flags(), &cfg.BlobStore)
+	encryption.RegisterFlags(cmd.Flags(), &cfg.Encryption)
+	secrets.RegisterFlags(cmd.Flags(), &cfg.Secrets)
 
 	if err := cmdutil.SetFlagsFromEnvVariables(cmd.Flags()); err != nil {
 		return errors.Wrap(err, "failed to populate config from environment vars")
 	}
 
+	migrateBlobsCmd, err := newMigrateBlobsCommand()
+	if err != nil {
+		return err
+	}
+	cmd.AddCommand(migrateBlobsCmd)
+
+	rotateEncryptionKeyCmd, err := newRotateEncryptionKeyCommand()
+	if err != nil {
+		return err
+	}
+	cmd.AddCommand(rotateEncryptionKeyCmd)
+
 	cmd.SetArgs(args)
 	return cmd.ExecuteContext(ctx)
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	cmdutil "github.com/leg100/otf/cmd"
+	"github.com/leg100/otf/internal/blob"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/sql"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateBlobsCommand constructs a command that moves blobs from the
+// database to the configured blob store.
+func newMigrateBlobsCommand() (*cobra.Command, error) {
+	var (
+		database     string
+		blobConfig   blob.Config
+		loggerConfig logr.Config
+	)
+	cmd := &cobra.Command{
+		Use:   "migrate-blobs",
+		Short: "Move blobs from the database to the blob store",
+		Long: `Move blobs - state files, configuration tarballs, plan files, logs and module
+tarballs - from the database to the blob store specified with --blob-store.
+
+otfd should not be running while blobs are migrated. Once migrated, otfd must
+be started with the same blob store flags.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := logr.New(loggerConfig)
+			if err != nil {
+				return err
+			}
+			db, err := sql.New(cmd.Context(), logger, database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			target, err := blob.New(cmd.Context(), blobConfig, db)
+			if err != nil {
+				return fmt.Errorf("constructing blob store: %w", err)
+			}
+			n, err := blob.Migrate(cmd.Context(), logger, db, target)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Migrated %d blobs to %s blob store\n", n, blobConfig.Backend)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&database, "database", defaultDatabase, "Postgres connection string")
+	blob.RegisterFlags(cmd.Flags(), &blobConfig)
+	logr.RegisterFlags(cmd.Flags(), &loggerConfig)
+
+	if err := cmdutil.SetFlagsFromEnvVariables(cmd.Flags()); err != nil {
+		return nil, errors.Wrap(err, "failed to populate config from environment vars")
+	}
+	return cmd, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	cmdutil "github.com/leg100/otf/cmd"
+	"github.com/leg100/otf/internal/encryption"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/sql"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// newRotateEncryptionKeyCommand constructs a command that re-encrypts secrets
+// in the database with the current key encryption key.
+func newRotateEncryptionKeyCommand() (*cobra.Command, error) {
+	var (
+		database         string
+		encryptionConfig encryption.Config
+		loggerConfig     logr.Config
+	)
+	cmd := &cobra.Command{
+		Use:   "rotate-encryption-key",
+		Short: "Re-encrypt secrets with a new encryption key",
+		Long: `Re-encrypt secrets - sensitive variable values, SSH private keys and VCS
+provider tokens - with the key specified with --encryption-key-file. Secrets
+encrypted with the key specified with --previous-encryption-key-file are
+decrypted and re-encrypted, as are secrets that were stored before encryption
+was enabled.
+
+To rotate the key, first restart otfd with the new key specified with
+--encryption-key-file and the old key with --previous-encryption-key-file. Then
+run this command with the same flags. Once it has finished, otfd can be
+restarted without --previous-encryption-key-file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := logr.New(loggerConfig)
+			if err != nil {
+				return err
+			}
+			cipher, err := encryption.New(encryptionConfig)
+			if err != nil {
+				return fmt.Errorf("constructing cipher: %w", err)
+			}
+			db, err := sql.New(cmd.Context(), logger, database)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			n, err := encryption.Rotate(cmd.Context(), logger, db, cipher)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted %d secrets\n", n)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&database, "database", defaultDatabase, "Postgres connection string")
+	encryption.RegisterFlags(cmd.Flags(), &encryptionConfig)
+	logr.RegisterFlags(cmd.Flags(), &loggerConfig)
+
+	if err := cmdutil.SetFlagsFromEnvVariables(cmd.Flags()); err != nil {
+		return nil, errors.Wrap(err, "failed to populate config from environment vars")
+	}
+	return cmd, nil
+}
@@ -17,6 +17,7 @@ type Route struct{}
 func (a *Route) IsPath(path string) bool {
 	return strings.HasPrefix(path, tfeapi.APIPrefixV2) ||
 		strings.HasPrefix(path, tfeapi.ModuleV1Prefix) ||
+		strings.HasPrefix(path, tfeapi.ProviderV1Prefix) ||
 		strings.HasPrefix(path, otfhttp.APIBasePath)
 }
 
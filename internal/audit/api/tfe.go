@@ -0,0 +1,196 @@
+// Package api provides http handlers for the audit trail API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tfeapi"
+	"github.com/leg100/otf/internal/tfeapi/types"
+)
+
+// TFEAPI implements the TFE audit trail API:
+//
+// https://developer.hashicorp.com/terraform/cloud-docs/api-docs/audit-trails
+type TFEAPI struct {
+	Client tfeClient
+}
+
+type tfeClient interface {
+	ListEvents(ctx context.Context, org resource.ID, opts audit.ListOptions) (*resource.Page[*audit.Event], error)
+}
+
+type (
+	// tfeAuditTrailList is the response to a request to list audit trail
+	// events. Unlike the rest of the TFE API it is plain JSON rather than
+	// JSON:API.
+	tfeAuditTrailList struct {
+		Data       []tfeAuditTrail `json:"data"`
+		Pagination tfePagination   `json:"pagination"`
+	}
+
+	tfeAuditTrail struct {
+		ID        resource.TfeID      `json:"id"`
+		Version   string              `json:"version"`
+		Type      string              `json:"type"`
+		Timestamp time.Time           `json:"timestamp"`
+		Auth      tfeAuditTrailAuth   `json:"auth"`
+		Request   tfeAuditTrailReq    `json:"request"`
+		Resource  tfeAuditTrailTarget `json:"resource"`
+	}
+
+	tfeAuditTrailAuth struct {
+		AccessorID     *resource.TfeID `json:"accessor_id"`
+		Description    string          `json:"description"`
+		Type           string          `json:"type"`
+		ImpersonatorID *string         `json:"impersonator_id"`
+		OrganizationID string          `json:"organization_id"`
+	}
+
+	tfeAuditTrailReq struct {
+		ID        *string `json:"id"`
+		IPAddress *string `json:"ip_address"`
+	}
+
+	tfeAuditTrailTarget struct {
+		ID     resource.TfeID          `json:"id"`
+		Type   string                  `json:"type"`
+		Action string                  `json:"action"`
+		Meta   map[string]audit.Change `json:"meta"`
+	}
+
+	// tfePagination uses underscores rather than the hyphens used by the
+	// JSON:API endpoints.
+	tfePagination struct {
+		CurrentPage  int  `json:"current_page"`
+		PreviousPage *int `json:"prev_page"`
+		NextPage     *int `json:"next_page"`
+		TotalPages   int  `json:"total_pages"`
+		TotalCount   int  `json:"total_count"`
+	}
+)
+
+func (a *TFEAPI) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organization/audit-trail", a.listAuditTrail).Methods("GET")
+}
+
+func (a *TFEAPI) listAuditTrail(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		types.PageOptions
+		// Organization is only required if the request is not authenticated
+		// with an organization token.
+		Organization *organization.Name `schema:"organization"`
+		// Since and Until are ISO8601 timestamps.
+		Since *string `schema:"since"`
+		Until *string `schema:"until"`
+	}
+	if err := decode.Query(&params, r.URL.Query()); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	org, err := organizationFromRequest(r.Context(), params.Organization)
+	if err != nil {
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	opts := audit.ListOptions{PageOptions: resource.PageOptions(params.PageOptions)}
+	if opts.Since, err = parseTime("since", params.Since); err != nil {
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	if opts.Until, err = parseTime("until", params.Until); err != nil {
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	page, err := a.Client.ListEvents(r.Context(), org, opts)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	response := tfeAuditTrailList{
+		Data: make([]tfeAuditTrail, len(page.Items)),
+		Pagination: tfePagination{
+			CurrentPage:  page.CurrentPage,
+			PreviousPage: page.PreviousPage,
+			NextPage:     page.NextPage,
+			TotalPages:   page.TotalPages,
+			TotalCount:   page.TotalCount,
+		},
+	}
+	for i, event := range page.Items {
+		response.Data[i] = convert(event)
+	}
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// organizationFromRequest determines the organization whose audit trail is
+// requested. The TFE API only permits organization tokens to access the audit
+// trail, and the organization is that of the token; OTF additionally permits
+// other subjects, e.g. an owner using a user token, to specify the
+// organization.
+func organizationFromRequest(ctx context.Context, name *organization.Name) (resource.ID, error) {
+	subject, err := authz.SubjectFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := subject.(*organization.OrganizationToken); ok {
+		return token.Organization, nil
+	}
+	if name == nil {
+		return nil, &internal.ErrMissingParameter{Parameter: "organization"}
+	}
+	return *name, nil
+}
+
+func parseTime(param string, s *string) (*time.Time, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", param, err)
+	}
+	return &t, nil
+}
+
+func convert(from *audit.Event) tfeAuditTrail {
+	to := tfeAuditTrail{
+		ID:        from.ID,
+		Version:   "0",
+		Type:      "Resource",
+		Timestamp: from.Timestamp,
+		Auth: tfeAuditTrailAuth{
+			AccessorID:     from.SubjectID,
+			Description:    from.Subject,
+			Type:           "Client",
+			OrganizationID: from.Organization,
+		},
+		Request: tfeAuditTrailReq{
+			IPAddress: from.IPAddress,
+		},
+		Resource: tfeAuditTrailTarget{
+			ID:     from.ResourceID,
+			Type:   from.ResourceID.Kind().Full(),
+			Action: from.Action.String(),
+			Meta:   from.Changes,
+		},
+	}
+	if from.SubjectID == nil {
+		// Change was made by the system, e.g. the site admin.
+		to.Auth.Type = "System"
+	}
+	return to
+}
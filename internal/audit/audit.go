@@ -0,0 +1,158 @@
+// Package audit records an audit trail of changes made to resources within an
+// organization.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+type (
+	// Event records a change made to a resource by a subject.
+	Event struct {
+		ID        resource.TfeID `db:"audit_event_id"`
+		Timestamp time.Time      `db:"timestamp"`
+		// Organization is the name of the organization to which the resource
+		// belongs.
+		Organization string `db:"organization_name"`
+		// Subject is the name of the subject that made the change, e.g. a
+		// username.
+		Subject string `db:"subject"`
+		// SubjectID is the ID of the subject that made the change. Nil if the
+		// subject has no ID, e.g. the site admin.
+		SubjectID *resource.TfeID `db:"subject_id"`
+		// Action is the change made to the resource.
+		Action resource.Action `db:"action"`
+		// ResourceID is the ID of the resource that was changed.
+		ResourceID resource.TfeID `db:"resource_id"`
+		// IPAddress is the IP address of the client that made the change. Nil
+		// if the change was not made via an HTTP request, e.g. a run applied
+		// automatically.
+		IPAddress *string `db:"ip_address"`
+		// Changes are the fields of the resource that were changed, keyed by
+		// field name.
+		Changes map[string]Change `db:"changes"`
+	}
+
+	// Change is a change to the value of a field.
+	Change struct {
+		Before any `json:"before,omitempty"`
+		After  any `json:"after,omitempty"`
+	}
+
+	// RecordOptions are options for recording an event.
+	RecordOptions struct {
+		// Organization to which the resource belongs. If nil then the
+		// organization is resolved from Parent.
+		Organization resource.ID
+		// Parent is the ID of a resource within the organization, e.g. the
+		// workspace to which a variable belongs. Only required if
+		// Organization is nil.
+		Parent resource.ID
+		// Action is the change made to the resource. Required.
+		Action resource.Action
+		// ResourceID is the ID of the resource that was changed. Required.
+		ResourceID resource.TfeID
+		// Before is the resource before the change, or nil if the resource
+		// was created.
+		Before any
+		// After is the resource after the change, or nil if the resource was
+		// deleted.
+		After any
+	}
+
+	ListOptions struct {
+		resource.PageOptions
+		// Since filters events to those recorded at or after the given time.
+		Since *time.Time
+		// Until filters events to those recorded before the given time.
+		Until *time.Time
+	}
+)
+
+// diff computes the changes between two versions of a resource, comparing
+// their fields. Either version may be nil, i.e. when the resource is created
+// or deleted.
+//
+// Each version is compared by its JSON representation, so fields excluded from
+// JSON are excluded from the diff. Callers can provide an alternative
+// representation, i.e. to redact secrets, in the form of a slog.Value group.
+func diff(before, after any) (map[string]Change, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]Change)
+	for k, v := range from {
+		if w, ok := to[k]; !ok || !reflect.DeepEqual(v, w) {
+			changes[k] = Change{Before: v, After: w}
+		}
+	}
+	for k, w := range to {
+		if _, ok := from[k]; !ok {
+			changes[k] = Change{After: w}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+// fields converts a resource into a map of its fields.
+func fields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if value, ok := v.(slog.Value); ok {
+		return groupFields(value), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling resource: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("resource is not a JSON object: %w", err)
+	}
+	return m, nil
+}
+
+func groupFields(value slog.Value) map[string]any {
+	m := make(map[string]any)
+	for _, attr := range value.Resolve().Group() {
+		v := attr.Value.Resolve()
+		switch v.Kind() {
+		case slog.KindGroup:
+			m[attr.Key] = groupFields(v)
+		case slog.KindAny:
+			// Normalize values, e.g. IDs, to their string representation, to
+			// ensure they can be compared with values read back from the
+			// database.
+			m[attr.Key] = fmt.Sprint(v.Any())
+		default:
+			m[attr.Key] = v.Any()
+		}
+	}
+	return m
+}
+
+// LogValue implements slog.LogValuer.
+func (e *Event) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", e.ID.String()),
+		slog.String("organization", e.Organization),
+		slog.String("subject", e.Subject),
+		slog.String("action", e.Action.String()),
+		slog.String("resource_id", e.ResourceID.String()),
+	)
+}
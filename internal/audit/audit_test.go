@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	type widget struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   map[string]Change
+	}{
+		{
+			name:  "created",
+			after: widget{Name: "foo", Count: 1},
+			want: map[string]Change{
+				"name":  {After: "foo"},
+				"count": {After: float64(1)},
+			},
+		},
+		{
+			name:   "deleted",
+			before: widget{Name: "foo", Count: 1},
+			want: map[string]Change{
+				"name":  {Before: "foo"},
+				"count": {Before: float64(1)},
+			},
+		},
+		{
+			name:   "updated",
+			before: widget{Name: "foo", Count: 1},
+			after:  widget{Name: "foo", Count: 2},
+			want: map[string]Change{
+				"count": {Before: float64(1), After: float64(2)},
+			},
+		},
+		{
+			name:   "unchanged",
+			before: widget{Name: "foo", Count: 1},
+			after:  widget{Name: "foo", Count: 1},
+			want:   nil,
+		},
+		{
+			name:   "log value",
+			before: slog.GroupValue(slog.String("value", "*****"), slog.Bool("sensitive", false)),
+			after:  slog.GroupValue(slog.String("value", "*****"), slog.Bool("sensitive", true)),
+			want: map[string]Change{
+				"sensitive": {Before: false, After: true},
+			},
+		},
+		{
+			name: "neither",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diff(tt.before, tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
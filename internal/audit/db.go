@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type pgdb struct {
+	*sql.DB
+}
+
+// create inserts an event. The insert is performed in its own transaction, or a
+// savepoint if the context already carries a transaction, so that a failure to
+// record an event does not abort the transaction in which the change was made.
+func (db *pgdb) create(ctx context.Context, event *Event) error {
+	return db.Tx(ctx, func(ctx context.Context) error {
+		return db.insert(ctx, event)
+	})
+}
+
+func (db *pgdb) insert(ctx context.Context, event *Event) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO audit_events (
+    audit_event_id,
+    timestamp,
+    organization_name,
+    subject,
+    subject_id,
+    action,
+    resource_id,
+    ip_address,
+    changes
+) VALUES (
+    @id,
+    @timestamp,
+    @organization_name,
+    @subject,
+    @subject_id,
+    @action,
+    @resource_id,
+    @ip_address,
+    @changes
+)
+`, pgx.NamedArgs{
+		"id":                event.ID,
+		"timestamp":         event.Timestamp,
+		"organization_name": event.Organization,
+		"subject":           event.Subject,
+		"subject_id":        event.SubjectID,
+		"action":            event.Action,
+		"resource_id":       event.ResourceID,
+		"ip_address":        event.IPAddress,
+		"changes":           event.Changes,
+	})
+	return err
+}
+
+func (db *pgdb) list(ctx context.Context, org resource.ID, opts ListOptions) (*resource.Page[*Event], error) {
+	args := pgx.NamedArgs{
+		"organization_name": org.String(),
+		"since":             opts.Since,
+		"until":             opts.Until,
+		"limit":             sql.GetLimit(opts.PageOptions),
+		"offset":            sql.GetOffset(opts.PageOptions),
+	}
+	rows := db.Query(ctx, `
+SELECT *
+FROM audit_events
+WHERE organization_name = @organization_name
+AND   (@since::timestamptz IS NULL OR timestamp >= @since)
+AND   (@until::timestamptz IS NULL OR timestamp < @until)
+ORDER BY timestamp DESC
+LIMIT @limit::int
+OFFSET @offset::int
+`, args)
+	items, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Event])
+	if err != nil {
+		return nil, err
+	}
+	count, err := db.Int(ctx, `
+SELECT count(*)
+FROM audit_events
+WHERE organization_name = @organization_name
+AND   (@since::timestamptz IS NULL OR timestamp >= @since)
+AND   (@until::timestamptz IS NULL OR timestamp < @until)
+`, args)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewPage(items, opts.PageOptions, &count), nil
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type (
+	// Alias service to permit embedding it with other services in a struct
+	// without a name clash.
+	AuditService = Service
+
+	Service struct {
+		logr.Logger
+		*authz.Authorizer
+
+		db *pgdb
+	}
+
+	Options struct {
+		DB         *sql.DB
+		Logger     logr.Logger
+		Authorizer *authz.Authorizer
+	}
+
+	// Recorder records audit events. Services record an event upon
+	// successfully making a change to a resource.
+	Recorder interface {
+		Record(ctx context.Context, opts RecordOptions)
+	}
+
+	// identifiable is a subject with an ID.
+	identifiable interface {
+		GetID() resource.TfeID
+	}
+)
+
+func NewService(opts Options) *Service {
+	return &Service{
+		Logger:     opts.Logger,
+		Authorizer: opts.Authorizer,
+		db:         &pgdb{opts.DB},
+	}
+}
+
+// Record an audit event. The subject that made the change, and the IP address
+// of its client, are retrieved from the context.
+//
+// An error is logged rather than returned, because the change has already been
+// made by the time it is recorded.
+func (s *Service) Record(ctx context.Context, opts RecordOptions) {
+	event, err := s.newEvent(ctx, opts)
+	if err != nil {
+		s.Error(err, "constructing audit event", "resource_id", opts.ResourceID, "action", opts.Action)
+		return
+	}
+	if err := s.db.create(ctx, event); err != nil {
+		s.Error(err, "recording audit event", "event", event)
+		return
+	}
+	s.V(9).Info("recorded audit event", "event", event)
+}
+
+func (s *Service) newEvent(ctx context.Context, opts RecordOptions) (*Event, error) {
+	subject, err := authz.SubjectFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org := opts.Organization
+	if org == nil {
+		org, err = s.ResolveOrganization(ctx, opts.Parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := diff(opts.Before, opts.After)
+	if err != nil {
+		return nil, err
+	}
+	event := &Event{
+		ID:           resource.NewTfeID(resource.AuditEventKind),
+		Timestamp:    internal.CurrentTimestamp(nil),
+		Organization: org.String(),
+		Subject:      subject.String(),
+		Action:       opts.Action,
+		ResourceID:   opts.ResourceID,
+		Changes:      changes,
+	}
+	if subj, ok := subject.(identifiable); ok {
+		event.SubjectID = new(subj.GetID())
+	}
+	if ip, ok := otfhttp.ClientIPFromContext(ctx); ok {
+		event.IPAddress = &ip
+	}
+	return event, nil
+}
+
+// ListEvents lists audit events for an organization, most recent first.
+func (s *Service) ListEvents(ctx context.Context, org resource.ID, opts ListOptions) (*resource.Page[*Event], error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.AuditEventKind, org)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.db.list(ctx, org, opts)
+	if err != nil {
+		s.Error(err, "listing audit events", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed audit events", "organization", org, "count", len(page.Items), "subject", subject)
+	return page, nil
+}
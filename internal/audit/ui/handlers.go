@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/audit"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/ui/helpers"
+)
+
+// dateLayout is the layout of the dates submitted by the filter form.
+const dateLayout = "2006-01-02"
+
+type Handlers struct {
+	Client AuditService
+}
+
+type AuditService interface {
+	ListEvents(ctx context.Context, org resource.ID, opts audit.ListOptions) (*resource.Page[*audit.Event], error)
+}
+
+func (h *Handlers) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/audit-events", h.listEvents).Methods("GET")
+}
+
+func (h *Handlers) listEvents(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+		Since        string            `schema:"since"`
+		Until        string            `schema:"until"`
+		resource.PageOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	opts := audit.ListOptions{PageOptions: params.PageOptions}
+	if params.Since != "" {
+		since, err := time.Parse(dateLayout, params.Since)
+		if err != nil {
+			helpers.Error(r, w, fmt.Sprintf("invalid since date: %s", err), helpers.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		opts.Since = &since
+	}
+	if params.Until != "" {
+		until, err := time.Parse(dateLayout, params.Until)
+		if err != nil {
+			helpers.Error(r, w, fmt.Sprintf("invalid until date: %s", err), helpers.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		// include events recorded on the until date
+		until = until.AddDate(0, 0, 1)
+		opts.Until = &until
+	}
+
+	page, err := h.Client.ListEvents(r.Context(), params.Organization, opts)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		listEvents(listEventsProps{
+			organization: params.Organization,
+			page:         page,
+			since:        params.Since,
+			until:        params.Until,
+		}),
+		"audit log",
+		w,
+		r,
+		helpers.WithOrganization(params.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Audit Log"},
+		),
+	)
+}
@@ -24,6 +24,8 @@ func (*fakeSessionService) StartSession(w http.ResponseWriter, r *http.Request,
 
 type fakeUserService struct {
 	userID resource.TfeID
+
+	added, removed []resource.TfeID
 }
 
 func (f *fakeUserService) GetUser(ctx context.Context, spec user.UserSpec) (*user.User, error) {
@@ -37,3 +39,13 @@ func (f *fakeUserService) Create(ctx context.Context, username string, opts ...u
 func (f *fakeUserService) UpdateAvatar(ctx context.Context, username user.Username, avatarURL string) error {
 	return nil
 }
+
+func (f *fakeUserService) AddTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error {
+	f.added = append(f.added, teamID)
+	return nil
+}
+
+func (f *fakeUserService) RemoveTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error {
+	f.removed = append(f.removed, teamID)
+	return nil
+}
@@ -62,7 +62,7 @@ func newTestOAuthServerClient(t *testing.T, userID resource.TfeID) *OAuthClient
 		fakeTokenHandler{},
 		&fakeURLClient{}, //("otf-server.com"),
 		&fakeSessionService{},
-		&fakeUserService{userID},
+		&fakeUserService{userID: userID},
 		OAuthConfig{
 			BaseURL: &internal.WebURL{URL: *u},
 			Endpoint: oauth2.Endpoint{
@@ -0,0 +1,410 @@
+package authenticator
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/team"
+	"github.com/leg100/otf/internal/ui/helpers"
+	userpkg "github.com/leg100/otf/internal/user"
+)
+
+const (
+	samlCookieName = "saml-request-id"
+
+	// DefaultSAMLName is the default name of the SAML identity provider.
+	DefaultSAMLName = "saml"
+	// DefaultSAMLGroupsAttribute is the default name of the assertion
+	// attribute listing the groups the user belongs to.
+	DefaultSAMLGroupsAttribute = "groups"
+)
+
+var (
+	ErrMissingSAMLCertificate = errors.New("must specify both saml-certificate-file and saml-key-file")
+	ErrInvalidSAMLTeamMapping = errors.New("team mapping must be in the format <group>=<organization>/<team>")
+)
+
+type (
+	// SAMLConfig is the configuration for a SAML 2.0 identity provider.
+	SAMLConfig struct {
+		// Name is the user-friendly identifier of the identity provider.
+		Name string
+		// IDPMetadataURL is the URL of the identity provider's metadata.
+		IDPMetadataURL string
+		// CertificateFile is the path to the certificate OTF uses to sign
+		// requests.
+		CertificateFile string
+		// KeyFile is the path to the private key for CertificateFile.
+		KeyFile string
+		// UsernameAttribute is the assertion attribute that provides the
+		// username. If empty then the assertion's NameID is used.
+		UsernameAttribute string
+		// GroupsAttribute is the assertion attribute that lists the groups the
+		// user belongs to.
+		GroupsAttribute string
+		// TeamMappings map identity provider groups to OTF teams, each in the
+		// format <group>=<organization>/<team>.
+		TeamMappings []string
+		// Skip TLS Verification when retrieving identity provider metadata.
+		SkipTLSVerification bool
+	}
+
+	// SAMLClient performs the service provider role in a SAML 2.0 single
+	// sign-on flow.
+	SAMLClient struct {
+		logr.Logger
+		SAMLConfig
+
+		Icon templ.Component
+
+		sp       saml.ServiceProvider
+		mappings []teamMapping
+		sessions SessionStarter
+		users    userService
+		teams    teamClient
+		urls     urlClient
+	}
+
+	// teamMapping maps an identity provider group to a team.
+	teamMapping struct {
+		group        string
+		organization organization.Name
+		team         string
+	}
+
+	teamClient interface {
+		GetTeam(ctx context.Context, organization organization.Name, name string) (*team.Team, error)
+	}
+)
+
+func newSAMLClient(
+	ctx context.Context,
+	logger logr.Logger,
+	urls urlClient,
+	sessions SessionStarter,
+	users userService,
+	teams teamClient,
+	cfg SAMLConfig,
+) (*SAMLClient, error) {
+	if cfg.CertificateFile == "" || cfg.KeyFile == "" {
+		return nil, ErrMissingSAMLCertificate
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultSAMLName
+	}
+	if cfg.GroupsAttribute == "" {
+		cfg.GroupsAttribute = DefaultSAMLGroupsAttribute
+	}
+	mappings, err := parseTeamMappings(cfg.TeamMappings)
+	if err != nil {
+		return nil, err
+	}
+	keyPair, err := tls.LoadX509KeyPair(cfg.CertificateFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading saml certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("parsing saml certificate: %w", err)
+	}
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("saml key does not support signing")
+	}
+	metadataURL, err := url.Parse(cfg.IDPMetadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing saml idp metadata url: %w", err)
+	}
+	httpClient := http.DefaultClient
+	if cfg.SkipTLSVerification {
+		httpClient = &http.Client{Transport: otfhttp.InsecureTransport}
+	}
+	idpMetadata, err := samlsp.FetchMetadata(ctx, httpClient, *metadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving saml idp metadata: %w", err)
+	}
+	return &SAMLClient{
+		Logger:     logger,
+		SAMLConfig: cfg,
+		Icon:       samlIcon(),
+		sp: saml.ServiceProvider{
+			Key:         key,
+			Certificate: cert,
+			IDPMetadata: idpMetadata,
+			HTTPClient:  httpClient,
+		},
+		mappings: mappings,
+		sessions: sessions,
+		users:    users,
+		teams:    teams,
+		urls:     urls,
+	}, nil
+}
+
+// String provides a human-readable identifier for the SAML client.
+func (a *SAMLClient) String() string { return a.Name }
+
+func (a *SAMLClient) RequestPath() string {
+	return "/saml/" + a.String() + "/login"
+}
+
+func (a *SAMLClient) metadataPath() string {
+	return "/saml/" + a.String() + "/metadata"
+}
+
+func (a *SAMLClient) acsPath() string {
+	return "/saml/" + a.String() + "/acs"
+}
+
+func (a *SAMLClient) addHandlers(r *mux.Router) {
+	r.HandleFunc(a.metadataPath(), a.metadataHandler).Methods("GET")
+	r.HandleFunc(a.RequestPath(), a.requestHandler).Methods("GET")
+	r.HandleFunc(a.acsPath(), a.acsHandler).Methods("POST")
+}
+
+// serviceProvider returns the SAML service provider - note this is done at
+// run-time because its URLs use an OTF hostname that may only be determined at
+// run-time.
+func (a *SAMLClient) serviceProvider() *saml.ServiceProvider {
+	sp := a.sp
+	sp.MetadataURL = a.mustParseURL(a.metadataPath())
+	sp.AcsURL = a.mustParseURL(a.acsPath())
+	sp.EntityID = sp.MetadataURL.String()
+	return &sp
+}
+
+func (a *SAMLClient) mustParseURL(path string) url.URL {
+	u, err := url.Parse(a.urls.URL(path))
+	if err != nil {
+		panic(err.Error())
+	}
+	return *u
+}
+
+// metadataHandler serves the service provider metadata, for registering OTF
+// with the identity provider.
+func (a *SAMLClient) metadataHandler(w http.ResponseWriter, r *http.Request) {
+	buf, err := xml.MarshalIndent(a.serviceProvider().Metadata(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(buf)
+}
+
+// requestHandler initiates the SAML flow, redirecting the user to the identity
+// provider with an authentication request.
+func (a *SAMLClient) requestHandler(w http.ResponseWriter, r *http.Request) {
+	sp := a.serviceProvider()
+	req, err := sp.MakeAuthenticationRequest(
+		sp.GetSSOBindingLocation(saml.HTTPRedirectBinding),
+		saml.HTTPRedirectBinding,
+		saml.HTTPPostBinding,
+	)
+	if err != nil {
+		http.Error(w, "unable to make authentication request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	redirectURL, err := req.Redirect("", sp)
+	if err != nil {
+		http.Error(w, "unable to make authentication request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// The identity provider posts its response from a different site, so the
+	// cookie must be permitted on cross-site requests.
+	http.SetCookie(w, &http.Cookie{
+		Name:     samlCookieName,
+		Value:    req.ID,
+		Path:     "/",
+		MaxAge:   300, // 5 minutes
+		HttpOnly: true,
+		Secure:   true, // HTTPS only
+		SameSite: http.SameSiteNoneMode,
+	})
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+// acsHandler is the assertion consumer service: it validates the signed
+// assertion posted by the identity provider, retrieving or creating the
+// asserted user, synchronising their team memberships, and starting a new OTF
+// user session.
+func (a *SAMLClient) acsHandler(w http.ResponseWriter, r *http.Request) {
+	assertion, err := func() (*saml.Assertion, error) {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		cookie, err := r.Cookie(samlCookieName)
+		if err != nil {
+			return nil, fmt.Errorf("missing saml request cookie (the cookie expires after 5 minutes)")
+		}
+		assertion, err := a.serviceProvider().ParseResponse(r, []string{cookie.Value})
+		if err != nil {
+			// The library deliberately hides the reason for an invalid
+			// response, so log it for the benefit of the administrator.
+			var invalid *saml.InvalidResponseError
+			if errors.As(err, &invalid) {
+				a.Error(invalid.PrivateErr, "validating saml response")
+			}
+			return nil, err
+		}
+		return assertion, nil
+	}()
+	if err != nil {
+		helpers.FlashError(w, err.Error())
+		http.Redirect(w, r, path.Login(), http.StatusFound)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: samlCookieName, Path: "/", MaxAge: -1})
+
+	username, err := a.username(assertion)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	// Retrieve user with asserted username, creating the user if necessary.
+	//
+	// Use privileged context to authorize access to user service endpoints.
+	ctx := authz.AddSubjectToContext(r.Context(), &authz.Superuser{Username: "saml_client"})
+	user, err := a.users.GetUser(ctx, userpkg.UserSpec{Username: &username})
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		user, err = a.users.Create(ctx, username.String())
+	}
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	if err := a.syncTeams(ctx, user, attributeValues(assertion, a.GroupsAttribute)); err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	if err := a.sessions.StartSession(w, r, user.ID); err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+}
+
+// username extracts the username from an assertion.
+func (a *SAMLClient) username(assertion *saml.Assertion) (userpkg.Username, error) {
+	var name string
+	if a.UsernameAttribute == "" {
+		if assertion.Subject != nil && assertion.Subject.NameID != nil {
+			name = assertion.Subject.NameID.Value
+		}
+	} else if values := attributeValues(assertion, a.UsernameAttribute); len(values) > 0 {
+		name = values[0]
+	}
+	if name == "" {
+		return userpkg.Username{}, fmt.Errorf("saml assertion is missing a username")
+	}
+	return userpkg.NewUsername(name)
+}
+
+// syncTeams synchronises the user's memberships of mapped teams with the
+// groups asserted by the identity provider: the user is added to teams mapped
+// from their groups and removed from mapped teams that are not. Memberships of
+// teams that are not mapped are left alone.
+func (a *SAMLClient) syncTeams(ctx context.Context, user *userpkg.User, groups []string) error {
+	type teamKey struct {
+		organization organization.Name
+		name         string
+	}
+	// Determine whether the user should be a member of each mapped team. A
+	// team can be mapped from more than one group, in which case membership
+	// of any of those groups is sufficient.
+	var (
+		keys   []teamKey
+		member = make(map[teamKey]bool)
+	)
+	for _, m := range a.mappings {
+		key := teamKey{organization: m.organization, name: m.team}
+		if _, ok := member[key]; !ok {
+			keys = append(keys, key)
+		}
+		member[key] = member[key] || slices.Contains(groups, m.group)
+	}
+	for _, key := range keys {
+		mapped, err := a.teams.GetTeam(ctx, key.organization, key.name)
+		if errors.Is(err, internal.ErrResourceNotFound) {
+			// Skip teams that have yet to be created.
+			a.V(1).Info("skipping missing saml mapped team", "organization", key.organization, "team", key.name)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("retrieving team: %w", err)
+		}
+		isMember := slices.ContainsFunc(user.Teams, func(t *team.Team) bool {
+			return t.ID == mapped.ID
+		})
+		switch {
+		case member[key] && !isMember:
+			if err := a.users.AddTeamMembership(ctx, mapped.ID, []userpkg.Username{user.Username}); err != nil {
+				return fmt.Errorf("adding team membership: %w", err)
+			}
+		case !member[key] && isMember:
+			if err := a.users.RemoveTeamMembership(ctx, mapped.ID, []userpkg.Username{user.Username}); err != nil {
+				return fmt.Errorf("removing team membership: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// attributeValues returns the values of the named attribute in an assertion,
+// matching either its name or its friendly name.
+func attributeValues(assertion *saml.Assertion, name string) (values []string) {
+	for _, stmt := range assertion.AttributeStatements {
+		for _, attr := range stmt.Attributes {
+			if attr.Name != name && attr.FriendlyName != name {
+				continue
+			}
+			for _, v := range attr.Values {
+				values = append(values, v.Value)
+			}
+		}
+	}
+	return values
+}
+
+// parseTeamMappings parses team mappings, each in the format
+// <group>=<organization>/<team>.
+func parseTeamMappings(mappings []string) ([]teamMapping, error) {
+	parsed := make([]teamMapping, len(mappings))
+	for i, m := range mappings {
+		group, orgAndTeam, ok := strings.Cut(m, "=")
+		if !ok || group == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSAMLTeamMapping, m)
+		}
+		org, teamName, ok := strings.Cut(orgAndTeam, "/")
+		if !ok || teamName == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSAMLTeamMapping, m)
+		}
+		orgName, err := organization.NewName(org)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSAMLTeamMapping, m, err)
+		}
+		parsed[i] = teamMapping{group: group, organization: orgName, team: teamName}
+	}
+	return parsed, nil
+}
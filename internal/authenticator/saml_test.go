@@ -0,0 +1,226 @@
+package authenticator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/team"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/leg100/otf/internal/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAMLClient_requestHandler(t *testing.T) {
+	client := newTestSAMLClient(t, &fakeUserService{}, &fakeTeamService{})
+
+	r := httptest.NewRequest("GET", "/saml/saml/login", nil)
+	w := httptest.NewRecorder()
+	client.requestHandler(w, r)
+
+	assert.Equal(t, http.StatusFound, w.Result().StatusCode)
+
+	loc, err := w.Result().Location()
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", loc.Host)
+	assert.NotEmpty(t, loc.Query().Get("SAMLRequest"))
+
+	if assert.Equal(t, 1, len(w.Result().Cookies())) {
+		assert.Equal(t, samlCookieName, w.Result().Cookies()[0].Name)
+		assert.NotEmpty(t, w.Result().Cookies()[0].Value)
+	}
+}
+
+func TestSAMLClient_metadataHandler(t *testing.T) {
+	client := newTestSAMLClient(t, &fakeUserService{}, &fakeTeamService{})
+
+	r := httptest.NewRequest("GET", "/saml/saml/metadata", nil)
+	w := httptest.NewRecorder()
+	client.metadataHandler(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	var got saml.EntityDescriptor
+	require.NoError(t, xml.NewDecoder(w.Body).Decode(&got))
+	assert.Equal(t, "https://otf.example.com/saml/saml/metadata", got.EntityID)
+	if assert.Equal(t, 1, len(got.SPSSODescriptors)) {
+		acs := got.SPSSODescriptors[0].AssertionConsumerServices
+		if assert.NotEmpty(t, acs) {
+			assert.Equal(t, "https://otf.example.com/saml/saml/acs", acs[0].Location)
+		}
+	}
+}
+
+func TestSAMLClient_username(t *testing.T) {
+	assertion := &saml.Assertion{
+		Subject: &saml.Subject{NameID: &saml.NameID{Value: "bobby"}},
+		AttributeStatements: []saml.AttributeStatement{
+			{
+				Attributes: []saml.Attribute{
+					{
+						Name:         "urn:oid:0.9.2342.19200300.100.1.3",
+						FriendlyName: "mail",
+						Values:       []saml.AttributeValue{{Value: "bobby@example.com"}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		attribute string
+		want      string
+	}{
+		{"name id", "", "bobby"},
+		{"attribute name", "urn:oid:0.9.2342.19200300.100.1.3", "bobby@example.com"},
+		{"attribute friendly name", "mail", "bobby@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &SAMLClient{SAMLConfig: SAMLConfig{UsernameAttribute: tt.attribute}}
+			got, err := client.username(assertion)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	t.Run("missing attribute", func(t *testing.T) {
+		client := &SAMLClient{SAMLConfig: SAMLConfig{UsernameAttribute: "uid"}}
+		_, err := client.username(assertion)
+		assert.Error(t, err)
+	})
+}
+
+func TestSAMLClient_syncTeams(t *testing.T) {
+	ctx := context.Background()
+	devs := &team.Team{ID: testutils.ParseID(t, "team-devs"), Name: "devs", Organization: organization.NewTestName(t)}
+	ops := &team.Team{ID: testutils.ParseID(t, "team-ops"), Name: "ops", Organization: devs.Organization}
+	teams := &fakeTeamService{teams: []*team.Team{devs, ops}}
+	org := devs.Organization.String()
+
+	users := &fakeUserService{}
+	client := newTestSAMLClient(t, users, teams,
+		"engineering="+org+"/devs",
+		"contractors="+org+"/devs",
+		"sre="+org+"/ops",
+		"admins="+org+"/missing",
+	)
+	bobby := &user.User{Username: user.MustUsername("bobby"), Teams: []*team.Team{ops}}
+
+	err := client.syncTeams(ctx, bobby, []string{"contractors", "admins"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []resource.TfeID{devs.ID}, users.added)
+	assert.Equal(t, []resource.TfeID{ops.ID}, users.removed)
+}
+
+func TestParseTeamMappings(t *testing.T) {
+	got, err := parseTeamMappings([]string{"engineering=acme/devs"})
+	require.NoError(t, err)
+	acme, err := organization.NewName("acme")
+	require.NoError(t, err)
+	assert.Equal(t, []teamMapping{{group: "engineering", organization: acme, team: "devs"}}, got)
+
+	for _, invalid := range []string{"engineering", "=acme/devs", "engineering=acme", "engineering=acme/", "engineering=/devs"} {
+		_, err := parseTeamMappings([]string{invalid})
+		assert.ErrorIs(t, err, ErrInvalidSAMLTeamMapping, invalid)
+	}
+}
+
+// newTestSAMLClient creates an identity provider metadata server for testing
+// purposes and returns a SAML client configured to use it.
+func newTestSAMLClient(t *testing.T, users userService, teams teamClient, mappings ...string) *SAMLClient {
+	idp := saml.EntityDescriptor{
+		EntityID: "https://idp.example.com/metadata",
+		IDPSSODescriptors: []saml.IDPSSODescriptor{
+			{
+				SingleSignOnServices: []saml.Endpoint{
+					{Binding: saml.HTTPRedirectBinding, Location: "https://idp.example.com/sso"},
+				},
+			},
+		},
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, xml.NewEncoder(w).Encode(&idp))
+	}))
+	t.Cleanup(srv.Close)
+
+	certFile, keyFile := newTestCertificate(t)
+	client, err := newSAMLClient(
+		context.Background(),
+		logr.Discard(),
+		&fakeHostURLClient{},
+		&fakeSessionService{},
+		users,
+		teams,
+		SAMLConfig{
+			IDPMetadataURL:      srv.URL,
+			CertificateFile:     certFile,
+			KeyFile:             keyFile,
+			TeamMappings:        mappings,
+			SkipTLSVerification: true,
+		},
+	)
+	require.NoError(t, err)
+	return client
+}
+
+// newTestCertificate writes a self-signed certificate and its key to a
+// temporary directory and returns their paths.
+func newTestCertificate(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "otf"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	return certFile, keyFile
+}
+
+type fakeTeamService struct {
+	teams []*team.Team
+}
+
+func (f *fakeTeamService) GetTeam(ctx context.Context, organization organization.Name, name string) (*team.Team, error) {
+	for _, t := range f.teams {
+		if t.Organization == organization && t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, internal.ErrResourceNotFound
+}
+
+// fakeHostURLClient constructs absolute URLs, which SAML requires.
+type fakeHostURLClient struct{}
+
+func (f *fakeHostURLClient) URL(path string) string { return "https://otf.example.com" + path }
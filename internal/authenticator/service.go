@@ -5,6 +5,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/user"
 )
 
@@ -13,8 +14,10 @@ type (
 		Logger               logr.Logger
 		URLClient            urlClient
 		UserService          userService
+		TeamService          teamClient
 		SessionClient        SessionStarter
 		IDTokenHandlerConfig OIDCConfig
+		SAMLConfig           SAMLConfig
 		SkipTLSVerification  bool
 	}
 
@@ -29,12 +32,15 @@ type (
 		UserService userService
 		sessions    SessionStarter
 		clients     []*OAuthClient
+		samlClient  *SAMLClient
 	}
 
 	userService interface {
 		GetUser(ctx context.Context, spec user.UserSpec) (*user.User, error)
 		Create(ctx context.Context, username string, opts ...user.NewUserOption) (*user.User, error)
 		UpdateAvatar(ctx context.Context, username user.Username, avatarURL string) error
+		AddTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error
+		RemoveTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error
 	}
 
 	urlClient interface {
@@ -44,7 +50,7 @@ type (
 
 // NewAuthenticatorService constructs a service for logging users onto
 // the system. Supports multiple clients: zero or more clients that support an
-// opaque token, and one client that supports IDToken/OIDC.
+// opaque token, one client that supports IDToken/OIDC, and one SAML client.
 func NewAuthenticatorService(ctx context.Context, opts Options) (*Service, error) {
 	svc := Service{
 		Logger:      opts.Logger,
@@ -52,6 +58,24 @@ func NewAuthenticatorService(ctx context.Context, opts Options) (*Service, error
 		sessions:    opts.SessionClient,
 		urls:        opts.URLClient,
 	}
+	// Construct SAML client
+	if opts.SAMLConfig.IDPMetadataURL != "" {
+		opts.SAMLConfig.SkipTLSVerification = opts.SkipTLSVerification
+		client, err := newSAMLClient(
+			ctx,
+			opts.Logger,
+			opts.URLClient,
+			opts.SessionClient,
+			opts.UserService,
+			opts.TeamService,
+			opts.SAMLConfig,
+		)
+		if err != nil {
+			return nil, err
+		}
+		svc.samlClient = client
+		opts.Logger.V(0).Info("activated SAML client", "name", client.Name)
+	}
 	// Construct client with OIDC IDToken handler
 	if opts.IDTokenHandlerConfig.ClientID == "" && opts.IDTokenHandlerConfig.ClientSecret == "" {
 		// skip creating OIDC authenticator when creds are unspecified
@@ -90,12 +114,20 @@ func (a *Service) AddHandlers(r *mux.Router) {
 	for _, authenticator := range a.clients {
 		authenticator.addHandlers(r)
 	}
+	if a.samlClient != nil {
+		a.samlClient.addHandlers(r)
+	}
 }
 
 func (a *Service) Clients() []*OAuthClient {
 	return a.clients
 }
 
+// SAMLClient returns the SAML client, or nil if SAML is not configured.
+func (a *Service) SAMLClient() *SAMLClient {
+	return a.samlClient
+}
+
 func (a *Service) RegisterOAuthClient(cfg OpaqueHandlerConfig) error {
 	// Construct clients with opaque token handlers
 	if cfg.ClientID == "" && cfg.ClientSecret == "" {
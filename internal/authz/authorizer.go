@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 
 	"github.com/leg100/otf/internal"
+	otfhttp "github.com/leg100/otf/internal/http"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
 )
@@ -15,6 +17,7 @@ import (
 type Authorizer struct {
 	logr.Logger
 	WorkspacePolicyGetter
+	WorkspaceTagsGetter
 
 	parentResolvers map[resource.Kind]ParentResolver
 }
@@ -44,6 +47,9 @@ func (a *Authorizer) RegisterParentResolver(kind resource.Kind, resolver ParentR
 // WorkspacePolicyGetter retrieves a workspace's policy.
 type WorkspacePolicyGetter func(ctx context.Context, workspaceID resource.ID) (WorkspacePolicy, error)
 
+// WorkspaceTagsGetter retrieves the names of a workspace's tags.
+type WorkspaceTagsGetter func(ctx context.Context, workspaceID resource.ID) ([]string, error)
+
 // WorkspacePolicy checks whether a subject is permitted to carry out an action
 // on a workspace.
 type WorkspacePolicy interface {
@@ -95,6 +101,10 @@ func (a *Authorizer) Authorize(ctx context.Context, action resource.Action, kind
 			err = internal.ErrAccessNotPermitted
 		}
 	}
+	if err == nil {
+		// Subject's access may be further restricted by a scope.
+		err = a.checkScope(ctx, action, kind, ar)
+	}
 	if err != nil {
 		if !cfg.disableLogs {
 			// TODO: disambiguate between logging errors due to subject lacking
@@ -114,25 +124,68 @@ func (a *Authorizer) Authorize(ctx context.Context, action resource.Action, kind
 	return subj, nil
 }
 
-func (a *Authorizer) generateRequest(ctx context.Context, resourceID resource.ID) (Request, error) {
-	req := Request{ID: resourceID}
-	if resourceID == resource.SiteID {
-		return req, nil
+// checkScope checks whether the scope of the subject in the context, if any,
+// permits the access request.
+func (a *Authorizer) checkScope(ctx context.Context, action resource.Action, kind resource.Kind, req Request) error {
+	scope, ok := ScopeFromContext(ctx)
+	if !ok {
+		return nil
 	}
-	// Retrieve resource lineage
-	for {
-		parent, ok := a.parentResolvers[resourceID.Kind()]
+	if !scope.permitsAction(action, kind) {
+		return internal.ErrAccessNotPermitted
+	}
+	if len(scope.CIDRs) > 0 {
+		// Deny access if the client's address is unknown.
+		ip, ok := otfhttp.ClientIPFromContext(ctx)
 		if !ok {
-			break
+			return internal.ErrAccessNotPermitted
 		}
-		parentID, err := parent(ctx, resourceID)
+		addr, err := netip.ParseAddr(ip)
 		if err != nil {
-			return Request{}, fmt.Errorf("resolving ID: %w", err)
+			return internal.ErrAccessNotPermitted
 		}
-		req.lineage = append(req.lineage, parentID)
-		// now try looking up parent of parent
-		resourceID = parentID
+		if !scope.permitsAddr(addr) {
+			return internal.ErrAccessNotPermitted
+		}
+	}
+	if !scope.restrictsWorkspaces() {
+		return nil
+	}
+	workspaceID := req.Workspace()
+	if workspaceID == nil {
+		// A scope restricted to workspaces only permits retrieving resources
+		// outside of a workspace, e.g. the organization's entitlements, which
+		// clients such as terraform need to retrieve before they can interact
+		// with a workspace.
+		if action != resource.Get {
+			return internal.ErrAccessNotPermitted
+		}
+		return nil
+	}
+	var tags []string
+	if len(scope.Tags) > 0 {
+		var err error
+		tags, err = a.WorkspaceTagsGetter(ctx, workspaceID)
+		if err != nil {
+			return fmt.Errorf("fetching workspace tags: %w", err)
+		}
+	}
+	if !scope.permitsWorkspace(workspaceID, tags) {
+		return internal.ErrAccessNotPermitted
+	}
+	return nil
+}
+
+func (a *Authorizer) generateRequest(ctx context.Context, resourceID resource.ID) (Request, error) {
+	req := Request{ID: resourceID}
+	if resourceID == resource.SiteID {
+		return req, nil
+	}
+	lineage, err := a.lineage(ctx, resourceID)
+	if err != nil {
+		return Request{}, err
 	}
+	req.lineage = lineage
 	// If the requested resource is a workspace or belongs to a workspace then
 	// fetch its workspace policy.
 	if req.Workspace() != nil {
@@ -145,6 +198,39 @@ func (a *Authorizer) generateRequest(ctx context.Context, resourceID resource.ID
 	return req, nil
 }
 
+// lineage retrieves the direct line of ancestors of a resource, starting with
+// its parent.
+func (a *Authorizer) lineage(ctx context.Context, resourceID resource.ID) ([]resource.ID, error) {
+	var lineage []resource.ID
+	for {
+		parent, ok := a.parentResolvers[resourceID.Kind()]
+		if !ok {
+			return lineage, nil
+		}
+		parentID, err := parent(ctx, resourceID)
+		if err != nil {
+			return nil, fmt.Errorf("resolving ID: %w", err)
+		}
+		lineage = append(lineage, parentID)
+		// now try looking up parent of parent
+		resourceID = parentID
+	}
+}
+
+// ResolveOrganization retrieves the organization to which a resource belongs,
+// or the organization itself if the resource is an organization.
+func (a *Authorizer) ResolveOrganization(ctx context.Context, resourceID resource.ID) (resource.ID, error) {
+	lineage, err := a.lineage(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	org := Request{ID: resourceID, lineage: lineage}.Organization()
+	if org == nil {
+		return nil, fmt.Errorf("resource does not belong to an organization: %s", resourceID)
+	}
+	return org, nil
+}
+
 // CanAccess is a helper to boil down an access request to a true/false
 // decision, with any error encountered interpreted as false.
 func (a *Authorizer) CanAccess(ctx context.Context, action resource.Action, kind resource.Kind, id resource.ID) bool {
@@ -54,6 +54,26 @@ var (
 			resource.TagKind: map[resource.Action]bool{
 				resource.List: true,
 			},
+			resource.PolicySetKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.PolicyKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.ProjectKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.RegistryProviderKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.RunTaskKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
 		},
 	}
 
@@ -95,6 +115,24 @@ var (
 			resource.ChunkKind: map[resource.Action]bool{
 				resource.Tail: true,
 			},
+			resource.PolicyCheckKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.CostEstimateKind: map[resource.Action]bool{
+				resource.Get: true,
+			},
+			resource.WorkspaceRunTaskKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.TaskStageKind: map[resource.Action]bool{
+				resource.Get:  true,
+				resource.List: true,
+			},
+			resource.TaskResultKind: map[resource.Action]bool{
+				resource.Get: true,
+			},
 		},
 	}
 
@@ -154,6 +192,12 @@ var (
 			},
 			resource.RunTriggerKind: map[resource.Action]bool{
 				resource.Create: true,
+				resource.Update: true,
+				resource.Delete: true,
+			},
+			resource.WorkspaceRunTaskKind: map[resource.Action]bool{
+				resource.Create: true,
+				resource.Update: true,
 				resource.Delete: true,
 			},
 		},
@@ -203,6 +247,61 @@ var (
 			resource.ModuleVersionKind: map[resource.Action]bool{
 				resource.Create: true,
 			},
+			resource.RegistryProviderKind: map[resource.Action]bool{
+				resource.Create: true,
+				resource.Delete: true,
+			},
+			resource.RegistryProviderVersionKind: map[resource.Action]bool{
+				resource.Create: true,
+				resource.Update: true,
+				resource.Delete: true,
+			},
+		},
+	}
+
+	// PolicyManagerRole is scoped to an organization and permits management of
+	// policy sets and their policies.
+	PolicyManagerRole = Role{
+		name: "policy-manager",
+		permissions: map[resource.Kind]map[resource.Action]bool{
+			resource.PolicySetKind: map[resource.Action]bool{
+				resource.Create: true,
+				resource.Update: true,
+				resource.Delete: true,
+			},
+			resource.PolicyKind: map[resource.Action]bool{
+				resource.Create: true,
+				resource.Update: true,
+				resource.Delete: true,
+			},
+		},
+	}
+
+	// ProjectManagerRole is scoped to an organization and permits management
+	// of projects and the teams' access to projects.
+	ProjectManagerRole = Role{
+		name: "project-manager",
+		permissions: map[resource.Kind]map[resource.Action]bool{
+			resource.ProjectKind: map[resource.Action]bool{
+				resource.Create:          true,
+				resource.Update:          true,
+				resource.Delete:          true,
+				resource.SetPermission:   true,
+				resource.UnsetPermission: true,
+			},
+		},
+	}
+
+	// PolicyOverrideRole is scoped to an organization and permits overriding
+	// failed mandatory policy checks.
+	PolicyOverrideRole = Role{
+		name: "policy-override",
+		permissions: map[resource.Kind]map[resource.Action]bool{
+			resource.PolicyCheckKind: map[resource.Action]bool{
+				resource.Get:      true,
+				resource.List:     true,
+				resource.Override: true,
+			},
 		},
 	}
 )
@@ -0,0 +1,207 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+type scopeCtxKeyType string
+
+const scopeCtxKey scopeCtxKeyType = "scope"
+
+// Scope restricts the access otherwise granted to a subject, e.g. an API token
+// that is only permitted to carry out a subset of what its owner is permitted
+// to do. Each field places a restriction on access, and an empty field places
+// no restriction.
+type Scope struct {
+	// Actions restricts access to the given actions.
+	Actions []resource.Action `json:"actions,omitempty"`
+	// Kinds restricts access to the given kinds of resources.
+	Kinds []resource.Kind `json:"kinds,omitempty"`
+	// Workspaces restricts access to the given workspaces and the resources
+	// belonging to them.
+	Workspaces []resource.TfeID `json:"workspaces,omitempty"`
+	// Tags restricts access to workspaces with any of the given tags and the
+	// resources belonging to them.
+	Tags []string `json:"tags,omitempty"`
+	// CIDRs restricts access to requests originating from the given IP
+	// address ranges.
+	CIDRs []netip.Prefix `json:"cidrs,omitempty"`
+}
+
+// ScopeSpec is a string representation of a scope, e.g. as provided by a web
+// form or command line flags. Each value may contain several comma-separated
+// items.
+type ScopeSpec struct {
+	Actions    []string `schema:"actions"`
+	Kinds      []string `schema:"kinds"`
+	Workspaces []string `schema:"workspaces"`
+	Tags       []string `schema:"tags"`
+	CIDRs      []string `schema:"cidrs"`
+}
+
+// Parse parses the spec into a scope.
+func (spec ScopeSpec) Parse() (Scope, error) {
+	var scope Scope
+	for _, s := range splitItems(spec.Actions) {
+		action, err := resource.ParseAction(s)
+		if err != nil {
+			return Scope{}, err
+		}
+		scope.Actions = append(scope.Actions, action)
+	}
+	for _, s := range splitItems(spec.Kinds) {
+		kind, err := resource.ParseKind(s)
+		if err != nil {
+			return Scope{}, err
+		}
+		scope.Kinds = append(scope.Kinds, kind)
+	}
+	for _, s := range splitItems(spec.Workspaces) {
+		id, err := resource.ParseTfeID(s)
+		if err != nil {
+			return Scope{}, fmt.Errorf("invalid workspace ID: %w", err)
+		}
+		if id.Kind() != resource.WorkspaceKind {
+			return Scope{}, fmt.Errorf("invalid workspace ID: %s", s)
+		}
+		scope.Workspaces = append(scope.Workspaces, id)
+	}
+	scope.Tags = splitItems(spec.Tags)
+	for _, s := range splitItems(spec.CIDRs) {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			// Permit a single IP address in lieu of a CIDR.
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return Scope{}, fmt.Errorf("invalid CIDR: %w", err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		scope.CIDRs = append(scope.CIDRs, prefix.Masked())
+	}
+	return scope, nil
+}
+
+// splitItems splits values containing comma-separated items into individual
+// items, skipping empty items.
+func splitItems(values []string) (items []string) {
+	for _, v := range values {
+		for item := range strings.SplitSeq(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// ScopedSubject is a subject whose access is restricted by a scope.
+type ScopedSubject struct {
+	Subject
+	Scope Scope
+}
+
+// IsZero returns true if the scope places no restrictions on access.
+func (s Scope) IsZero() bool {
+	return len(s.Actions) == 0 &&
+		len(s.Kinds) == 0 &&
+		len(s.Workspaces) == 0 &&
+		len(s.Tags) == 0 &&
+		len(s.CIDRs) == 0
+}
+
+// Restrictions returns a human-readable description of each restriction placed
+// on access by the scope.
+func (s Scope) Restrictions() []string {
+	var restrictions []string
+	add := func(name string, items []string) {
+		if len(items) > 0 {
+			restrictions = append(restrictions, name+": "+strings.Join(items, ", "))
+		}
+	}
+	add("actions", toStrings(s.Actions))
+	add("kinds", toStrings(s.Kinds))
+	add("workspaces", toStrings(s.Workspaces))
+	add("tags", s.Tags)
+	add("source IPs", toStrings(s.CIDRs))
+	return restrictions
+}
+
+func toStrings[T fmt.Stringer](values []T) []string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = v.String()
+	}
+	return items
+}
+
+// restrictsWorkspaces returns true if the scope restricts access to particular
+// workspaces.
+func (s Scope) restrictsWorkspaces() bool {
+	return len(s.Workspaces) > 0 || len(s.Tags) > 0
+}
+
+// permitsAction determines whether the scope permits the action on the kind of
+// resource.
+func (s Scope) permitsAction(action resource.Action, kind resource.Kind) bool {
+	if len(s.Actions) > 0 && !slices.Contains(s.Actions, action) {
+		return false
+	}
+	if len(s.Kinds) > 0 && !slices.Contains(s.Kinds, kind) {
+		return false
+	}
+	return true
+}
+
+// permitsWorkspace determines whether the scope permits access to the
+// workspace with the given ID and tags.
+func (s Scope) permitsWorkspace(workspaceID resource.ID, tags []string) bool {
+	if !s.restrictsWorkspaces() {
+		return true
+	}
+	for _, id := range s.Workspaces {
+		if id.String() == workspaceID.String() {
+			return true
+		}
+	}
+	for _, tag := range tags {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// permitsAddr determines whether the scope permits a request originating from
+// the IP address.
+func (s Scope) permitsAddr(addr netip.Addr) bool {
+	if len(s.CIDRs) == 0 {
+		return true
+	}
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range s.CIDRs {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeFromContext retrieves the scope of the subject from a context, returning
+// false if the subject is not scoped.
+func ScopeFromContext(ctx context.Context) (Scope, bool) {
+	scope, ok := ctx.Value(scopeCtxKey).(*Scope)
+	if !ok || scope == nil {
+		return Scope{}, false
+	}
+	return *scope, true
+}
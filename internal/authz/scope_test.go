@@ -0,0 +1,219 @@
+package authz
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizer_Scope(t *testing.T) {
+	org := resource.NewTfeID(resource.OrganizationKind)
+	ws1 := resource.NewTfeID(resource.WorkspaceKind)
+	ws2 := resource.NewTfeID(resource.WorkspaceKind)
+	run1 := resource.NewTfeID(resource.RunKind)
+
+	authorizer := NewAuthorizer(logr.Discard())
+	authorizer.RegisterParentResolver(resource.RunKind, func(context.Context, resource.ID) (resource.ID, error) {
+		return ws1, nil
+	})
+	authorizer.RegisterParentResolver(resource.WorkspaceKind, func(context.Context, resource.ID) (resource.ID, error) {
+		return org, nil
+	})
+	authorizer.WorkspacePolicyGetter = func(context.Context, resource.ID) (WorkspacePolicy, error) {
+		return nil, nil
+	}
+	authorizer.WorkspaceTagsGetter = func(_ context.Context, id resource.ID) ([]string, error) {
+		if id == ws1 {
+			return []string{"dev"}, nil
+		}
+		return []string{"prod"}, nil
+	}
+
+	tests := []struct {
+		name   string
+		scope  Scope
+		action resource.Action
+		kind   resource.Kind
+		id     resource.ID
+		want   error
+	}{
+		{
+			name:   "permitted action",
+			scope:  Scope{Actions: []resource.Action{resource.Get}},
+			action: resource.Get,
+			kind:   resource.WorkspaceKind,
+			id:     ws1,
+		},
+		{
+			name:   "forbidden action",
+			scope:  Scope{Actions: []resource.Action{resource.Get}},
+			action: resource.Delete,
+			kind:   resource.WorkspaceKind,
+			id:     ws1,
+			want:   internal.ErrAccessNotPermitted,
+		},
+		{
+			name:   "forbidden kind",
+			scope:  Scope{Kinds: []resource.Kind{resource.RunKind}},
+			action: resource.Get,
+			kind:   resource.WorkspaceKind,
+			id:     ws1,
+			want:   internal.ErrAccessNotPermitted,
+		},
+		{
+			name:   "permitted workspace",
+			scope:  Scope{Workspaces: []resource.TfeID{ws1}},
+			action: resource.Apply,
+			kind:   resource.RunKind,
+			id:     run1,
+		},
+		{
+			name:   "forbidden workspace",
+			scope:  Scope{Workspaces: []resource.TfeID{ws1}},
+			action: resource.Get,
+			kind:   resource.WorkspaceKind,
+			id:     ws2,
+			want:   internal.ErrAccessNotPermitted,
+		},
+		{
+			name:   "permitted tag",
+			scope:  Scope{Tags: []string{"dev"}},
+			action: resource.Update,
+			kind:   resource.WorkspaceKind,
+			id:     ws1,
+		},
+		{
+			name:   "forbidden tag",
+			scope:  Scope{Tags: []string{"dev"}},
+			action: resource.Update,
+			kind:   resource.WorkspaceKind,
+			id:     ws2,
+			want:   internal.ErrAccessNotPermitted,
+		},
+		{
+			name:   "workspace scope permits retrieving organization resource",
+			scope:  Scope{Workspaces: []resource.TfeID{ws1}},
+			action: resource.Get,
+			kind:   resource.EntitlementKind,
+			id:     org,
+		},
+		{
+			name:   "workspace scope forbids listing organization resources",
+			scope:  Scope{Workspaces: []resource.TfeID{ws1}},
+			action: resource.List,
+			kind:   resource.WorkspaceKind,
+			id:     org,
+			want:   internal.ErrAccessNotPermitted,
+		},
+		{
+			name:   "source IP missing from context",
+			scope:  Scope{CIDRs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+			action: resource.Get,
+			kind:   resource.WorkspaceKind,
+			id:     ws1,
+			want:   internal.ErrAccessNotPermitted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := AddSubjectToContext(t.Context(), &ScopedSubject{
+				Subject: &Superuser{},
+				Scope:   tt.scope,
+			})
+			_, err := authorizer.Authorize(ctx, tt.action, tt.kind, tt.id)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestAddSubjectToContext_Scope(t *testing.T) {
+	scoped := &ScopedSubject{
+		Subject: &Superuser{Username: "scoped"},
+		Scope:   Scope{Actions: []resource.Action{resource.Get}},
+	}
+	ctx := AddSubjectToContext(t.Context(), scoped)
+
+	// Scoped subject is unwrapped.
+	subj, err := SubjectFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, scoped.Subject, subj)
+
+	scope, ok := ScopeFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, scoped.Scope, scope)
+
+	// Replacing the subject removes the scope.
+	ctx = AddSubjectToContext(ctx, &Superuser{})
+	_, ok = ScopeFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestScope_PermitsAddr(t *testing.T) {
+	scope := Scope{CIDRs: []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}}
+
+	assert.True(t, scope.permitsAddr(netip.MustParseAddr("10.1.2.3")))
+	assert.True(t, scope.permitsAddr(netip.MustParseAddr("::ffff:10.1.2.3")))
+	assert.True(t, scope.permitsAddr(netip.MustParseAddr("2001:db8::1")))
+	assert.False(t, scope.permitsAddr(netip.MustParseAddr("192.168.1.1")))
+	assert.False(t, scope.permitsAddr(netip.Addr{}))
+	assert.True(t, Scope{}.permitsAddr(netip.Addr{}))
+}
+
+func TestScopeSpec_Parse(t *testing.T) {
+	ws := resource.NewTfeID(resource.WorkspaceKind)
+
+	got, err := ScopeSpec{
+		Actions:    []string{"get, list"},
+		Kinds:      []string{"ws"},
+		Workspaces: []string{ws.String()},
+		Tags:       []string{"dev,", "prod"},
+		CIDRs:      []string{"10.1.2.3/8,192.168.1.1"},
+	}.Parse()
+	require.NoError(t, err)
+
+	assert.Equal(t, Scope{
+		Actions:    []resource.Action{resource.Get, resource.List},
+		Kinds:      []resource.Kind{resource.WorkspaceKind},
+		Workspaces: []resource.TfeID{ws},
+		Tags:       []string{"dev", "prod"},
+		CIDRs: []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.168.1.1/32"),
+		},
+	}, got)
+
+	t.Run("invalid workspace ID", func(t *testing.T) {
+		_, err := ScopeSpec{Workspaces: []string{"run-123"}}.Parse()
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid CIDR", func(t *testing.T) {
+		_, err := ScopeSpec{CIDRs: []string{"not-an-ip"}}.Parse()
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		_, err := ScopeSpec{Actions: []string{"get,lsit"}}.Parse()
+		assert.EqualError(t, err, "unknown action: lsit")
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := ScopeSpec{Kinds: []string{"workspace"}}.Parse()
+		assert.EqualError(t, err, "unknown kind: workspace")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := ScopeSpec{Actions: []string{""}}.Parse()
+		require.NoError(t, err)
+		assert.True(t, got.IsZero())
+	})
+}
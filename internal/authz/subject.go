@@ -22,8 +22,16 @@ type Subject interface {
 	String() string
 }
 
-// AddSubjectToContext adds a subject to a context
+// AddSubjectToContext adds a subject to a context. If the subject is scoped then
+// its scope is added to the context too, otherwise any scope belonging to a
+// previous subject is removed.
 func AddSubjectToContext(ctx context.Context, subj Subject) context.Context {
+	var scope *Scope
+	if scoped, ok := subj.(*ScopedSubject); ok {
+		scope = &scoped.Scope
+		subj = scoped.Subject
+	}
+	ctx = context.WithValue(ctx, scopeCtxKey, scope)
 	return context.WithValue(ctx, subjectCtxKey, subj)
 }
 
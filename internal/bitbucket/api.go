@@ -0,0 +1,116 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/leg100/otf/internal"
+)
+
+// api makes requests to a Bitbucket REST API. There is no SDK that supports
+// both Bitbucket Cloud and Bitbucket Data Center, so requests are made by hand.
+type api struct {
+	// root of the REST API
+	url   url.URL
+	token string
+	http  *http.Client
+}
+
+func newAPI(apiURL url.URL, token string, skipTLSVerification bool) *api {
+	client := &http.Client{}
+	if skipTLSVerification {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+		client.Transport = transport
+	}
+	return &api{url: apiURL, token: token, http: client}
+}
+
+// endpoint constructs the URL of an API endpoint from path segments and query
+// parameters.
+func (a *api) endpoint(query url.Values, segments ...string) string {
+	u := a.url
+	for _, s := range segments {
+		u = *u.JoinPath(s)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// do sends a request to the URL, encoding in as the JSON request body if
+// non-nil, and decoding the JSON response body into out if non-nil.
+// internal.ErrResourceNotFound is returned if the API responds with a 404.
+func (a *api) do(ctx context.Context, method, u string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(req, resp); err != nil {
+		return err
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response from %s %s: %w", method, req.URL.Path, err)
+	}
+	return nil
+}
+
+// download retrieves the raw contents of the URL.
+func (a *api) download(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(req, resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func checkResponse(req *http.Request, resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, internal.ErrResourceNotFound)
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package bitbucket
+
+import "github.com/leg100/otf/internal"
+
+// DefaultBaseURL is the base URL of Bitbucket Cloud.
+var DefaultBaseURL = internal.MustWebURL("https://bitbucket.org")
+
+// cloudHostname is the hostname of Bitbucket Cloud. Providers with any other
+// hostname are assumed to be Bitbucket Data Center (or Server) instances.
+const cloudHostname = "bitbucket.org"
+
+// cloudAPIURL is the URL of the Bitbucket Cloud REST API.
+var cloudAPIURL = internal.MustWebURL("https://api.bitbucket.org/2.0")
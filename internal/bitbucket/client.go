@@ -0,0 +1,85 @@
+package bitbucket
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/leg100/otf/internal/vcs"
+)
+
+// NewTokenClient constructs a client for Bitbucket Cloud if the base URL is
+// that of Bitbucket Cloud; otherwise it constructs a client for Bitbucket Data
+// Center.
+func NewTokenClient(opts vcs.NewTokenClientOptions) (vcs.Client, error) {
+	if opts.BaseURL == nil {
+		return nil, errors.New("missing base URL")
+	}
+	if opts.BaseURL.Host == cloudHostname {
+		return newCloudClient(opts.BaseURL.URL, cloudAPIURL.URL, opts.Token, opts.SkipTLSVerification), nil
+	}
+	return newServerClient(opts.BaseURL.URL, opts.Token, opts.SkipTLSVerification), nil
+}
+
+// buildStatus is the status of a build of a commit. Bitbucket Cloud and Data
+// Center share the same representation.
+type buildStatus struct {
+	Key         string `json:"key"`
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// buildState maps an OTF status to the state of a Bitbucket build status.
+func buildState(status vcs.Status) (string, error) {
+	switch status {
+	case vcs.PendingStatus:
+		return "INPROGRESS", nil
+	case vcs.SuccessStatus:
+		return "SUCCESSFUL", nil
+	case vcs.ErrorStatus, vcs.FailureStatus:
+		return "FAILED", nil
+	default:
+		return "", fmt.Errorf("invalid vcs status: %s", status)
+	}
+}
+
+// buildKey returns the key identifying the build status for a workspace.
+// Bitbucket keeps one status per key for each commit.
+func buildKey(workspace string) string {
+	return "otf/" + workspace
+}
+
+// webhookEvents maps OTF event types to the names of Bitbucket webhook
+// events.
+type webhookEvents map[vcs.EventType][]string
+
+// toBitbucket maps OTF event types to Bitbucket webhook events.
+func (m webhookEvents) toBitbucket(events []vcs.EventType) ([]string, error) {
+	var rv []string
+	for _, event := range events {
+		names, ok := m[event]
+		if !ok {
+			return nil, fmt.Errorf("bitbucket does not have an event type corresponding to '%s'", event)
+		}
+		for _, name := range names {
+			if !slices.Contains(rv, name) {
+				rv = append(rv, name)
+			}
+		}
+	}
+	return rv, nil
+}
+
+// fromBitbucket maps Bitbucket webhook events to OTF event types. Tag events
+// are indistinguishable from push events and are reported as the latter.
+func (m webhookEvents) fromBitbucket(names []string) []vcs.EventType {
+	var rv []vcs.EventType
+	for _, event := range []vcs.EventType{vcs.EventTypePush, vcs.EventTypePull, vcs.EventTypeComment} {
+		if slices.ContainsFunc(m[event], func(name string) bool { return slices.Contains(names, name) }) {
+			rv = append(rv, event)
+		}
+	}
+	return rv
+}
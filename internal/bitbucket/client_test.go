@@ -0,0 +1,338 @@
+package bitbucket
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCommit = "000111222333444555666777888999aaabbbcccd"
+
+var testRepo = vcs.NewMustRepo("acme", "test")
+
+// flavours of bitbucket, each providing a function to construct a client for
+// a test server
+var flavours = []struct {
+	name      string
+	newClient func(*testing.T, ...TestServerOption) (vcs.Client, *TestServer)
+}{
+	{
+		name: "cloud",
+		newClient: func(t *testing.T, opts ...TestServerOption) (vcs.Client, *TestServer) {
+			return newTestServerCloudClient(t, opts...)
+		},
+	},
+	{
+		name: "data center",
+		newClient: func(t *testing.T, opts ...TestServerOption) (vcs.Client, *TestServer) {
+			return newTestServerServerClient(t, opts...)
+		},
+	},
+}
+
+func TestNewTokenClient(t *testing.T) {
+	client, err := NewTokenClient(vcs.NewTokenClientOptions{
+		BaseURL: internal.MustWebURL("https://bitbucket.org"),
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &CloudClient{}, client)
+
+	client, err = NewTokenClient(vcs.NewTokenClientOptions{
+		BaseURL: internal.MustWebURL("https://bitbucket.example.com"),
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &ServerClient{}, client)
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, _ := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithDefaultBranch("main"),
+			)
+
+			got, err := client.GetDefaultBranch(t.Context(), "acme/test")
+			require.NoError(t, err)
+			assert.Equal(t, "main", got)
+
+			_, err = client.GetDefaultBranch(t.Context(), "acme/nonexistent-repo")
+			assert.ErrorIs(t, err, internal.ErrResourceNotFound)
+		})
+	}
+}
+
+func TestListRepositories(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, _ := flavour.newClient(t, WithRepo(testRepo))
+
+			got, err := client.ListRepositories(t.Context(), vcs.ListRepositoriesOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, []vcs.Repo{testRepo}, got)
+		})
+	}
+}
+
+func TestGetRepoTarball(t *testing.T) {
+	t.Run("cloud", func(t *testing.T) {
+		want, err := os.ReadFile("../testdata/forgejo.tar.gz")
+		require.NoError(t, err)
+		client, _ := newTestServerCloudClient(t,
+			WithRepo(testRepo),
+			WithDefaultBranch("main"),
+			WithCommit(testCommit),
+			WithArchive(want),
+		)
+
+		got, gotref, err := client.GetRepoTarball(t.Context(), vcs.GetRepoTarballOptions{
+			Repo: testRepo,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testCommit, gotref)
+
+		// unpack and compare contents of file within archive
+		wanttmp := t.TempDir()
+		gottmp := t.TempDir()
+		require.NoError(t, internal.Unpack(bytes.NewReader(want), wanttmp))
+		require.NoError(t, internal.Unpack(bytes.NewReader(got), gottmp))
+		wanttf, err := os.ReadFile(path.Join(wanttmp, "test", "main.tf")) // archive has a "test/" prefix
+		require.NoError(t, err)
+		gottf, err := os.ReadFile(path.Join(gottmp, "main.tf")) // client stripped that prefix
+		require.NoError(t, err)
+		assert.Equal(t, wanttf, gottf)
+	})
+
+	t.Run("data center", func(t *testing.T) {
+		// data center archives have no top-level directory and are returned
+		// as-is.
+		want, err := os.ReadFile("../testdata/unpack.tar.gz")
+		require.NoError(t, err)
+		client, _ := newTestServerServerClient(t,
+			WithRepo(testRepo),
+			WithDefaultBranch("main"),
+			WithCommit(testCommit),
+			WithArchive(want),
+		)
+
+		got, gotref, err := client.GetRepoTarball(t.Context(), vcs.GetRepoTarballOptions{
+			Repo: testRepo,
+			Ref:  new("tags/v1.0.0"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testCommit, gotref)
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestWebhook(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, srv := flavour.newClient(t, WithRepo(testRepo))
+
+			id, err := client.CreateWebhook(t.Context(), vcs.CreateWebhookOptions{
+				Repo:     testRepo,
+				Secret:   "secret",
+				Endpoint: "https://otf.example.com/webhooks/vcs/123",
+				Events:   []vcs.EventType{vcs.EventTypePush, vcs.EventTypeTag, vcs.EventTypePull},
+			})
+			require.NoError(t, err)
+			created := <-srv.WebhookEvents
+			assert.Equal(t, vcs.ActionCreated, created.Action)
+			assert.Equal(t, "secret", created.Hook.secret)
+
+			got, err := client.GetWebhook(t.Context(), vcs.GetWebhookOptions{Repo: testRepo, ID: id})
+			require.NoError(t, err)
+			assert.Equal(t, vcs.Webhook{
+				ID:       id,
+				Repo:     testRepo,
+				Events:   []vcs.EventType{vcs.EventTypePush, vcs.EventTypePull},
+				Endpoint: "https://otf.example.com/webhooks/vcs/123",
+			}, got)
+
+			err = client.UpdateWebhook(t.Context(), id, vcs.UpdateWebhookOptions{
+				Repo:     testRepo,
+				Secret:   "secret",
+				Endpoint: "https://otf.example.com/webhooks/vcs/123",
+				Events:   []vcs.EventType{vcs.EventTypePush, vcs.EventTypePull, vcs.EventTypeComment},
+			})
+			require.NoError(t, err)
+			updated := <-srv.WebhookEvents
+			assert.Equal(t, vcs.ActionUpdated, updated.Action)
+
+			err = client.DeleteWebhook(t.Context(), vcs.DeleteWebhookOptions{Repo: testRepo, ID: id})
+			require.NoError(t, err)
+			assert.False(t, srv.HasWebhook())
+
+			_, err = client.GetWebhook(t.Context(), vcs.GetWebhookOptions{Repo: testRepo, ID: id})
+			assert.ErrorIs(t, err, internal.ErrResourceNotFound)
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, srv := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithCommit(testCommit),
+			)
+
+			err := client.SetStatus(t.Context(), vcs.SetStatusOptions{
+				Workspace:   "dev",
+				Repo:        testRepo,
+				Ref:         testCommit,
+				Status:      vcs.SuccessStatus,
+				TargetURL:   "https://otf.example.com/runs/run-123",
+				Description: "planned: +1/~0/-0",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, &buildStatus{
+				Key:         "otf/dev",
+				State:       "SUCCESSFUL",
+				Name:        "otf/dev",
+				URL:         "https://otf.example.com/runs/run-123",
+				Description: "planned: +1/~0/-0",
+			}, srv.GetStatus(t, t.Context()))
+		})
+	}
+}
+
+func TestListTags(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, _ := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithTags("v1.0.0", "v1.1.0", "old-v1.0.0"),
+			)
+
+			got, err := client.ListTags(t.Context(), vcs.ListTagsOptions{
+				Repo:   testRepo,
+				Prefix: "v1",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"tags/v1.0.0", "tags/v1.1.0"}, got)
+		})
+	}
+}
+
+func TestListPullRequestFiles(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, _ := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithPullRequest("2", "main.tf", "modules/vpc/main.tf"),
+			)
+
+			got, err := client.ListPullRequestFiles(t.Context(), testRepo, 2)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"main.tf", "modules/vpc/main.tf"}, got)
+
+			// changed files between two commits are listed via the same
+			// stub.
+			got, err = client.(vcs.ChangedFilesLister).ListChangedFiles(t.Context(), testRepo, "abc", testCommit)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"main.tf", "modules/vpc/main.tf"}, got)
+		})
+	}
+}
+
+func TestGetPullRequest(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, srv := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithCommit(testCommit),
+				WithPullRequest("2"),
+				WithPullRequestApproved(),
+			)
+
+			got, err := client.GetPullRequest(t.Context(), testRepo, 2)
+			require.NoError(t, err)
+			assert.Equal(t, 2, got.Number)
+			assert.Equal(t, "my pull request", got.Title)
+			assert.Equal(t, "dev", got.Branch)
+			// cloud's abbreviated commit hash should have been expanded
+			assert.Equal(t, testCommit, got.CommitSHA)
+			assert.Contains(t, got.URL, srv.URL)
+			assert.Contains(t, got.CommitURL, testCommit)
+			assert.True(t, got.Approved)
+			assert.True(t, got.Mergeable)
+			assert.False(t, got.Fork)
+		})
+	}
+}
+
+func TestHasWriteAccess(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, _ := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithWriters("bobby"),
+			)
+
+			got, err := client.HasWriteAccess(t.Context(), testRepo, "bobby")
+			require.NoError(t, err)
+			assert.True(t, got)
+
+			// only an exact match grants access
+			got, err = client.HasWriteAccess(t.Context(), testRepo, "bob")
+			require.NoError(t, err)
+			assert.False(t, got)
+		})
+	}
+}
+
+func TestGetCommit(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, srv := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithCommit(testCommit),
+			)
+
+			got, err := client.GetCommit(t.Context(), testRepo, "main")
+			require.NoError(t, err)
+			assert.Equal(t, testCommit, got.SHA)
+			assert.Contains(t, got.URL, testCommit)
+			assert.Equal(t, "bob", got.Author.Username)
+			assert.Contains(t, got.Author.ProfileURL, srv.URL)
+			assert.Contains(t, got.Author.AvatarURL, srv.URL)
+		})
+	}
+}
+
+func TestPullRequestComment(t *testing.T) {
+	for _, flavour := range flavours {
+		t.Run(flavour.name, func(t *testing.T) {
+			client, srv := flavour.newClient(t,
+				WithRepo(testRepo),
+				WithPullRequest("2"),
+			)
+
+			id, err := client.CreatePullRequestComment(t.Context(), vcs.CreatePullRequestCommentOptions{
+				Repo:              testRepo,
+				PullRequestNumber: 2,
+				Body:              "planned",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testCommentID, id)
+			assert.Equal(t, "planned", srv.GetComment(t, t.Context()))
+
+			err = client.UpdatePullRequestComment(t.Context(), vcs.UpdatePullRequestCommentOptions{
+				Repo:              testRepo,
+				PullRequestNumber: 2,
+				ID:                id,
+				Body:              "applied",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "applied", srv.GetComment(t, t.Context()))
+		})
+	}
+}
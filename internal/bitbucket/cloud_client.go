@@ -0,0 +1,480 @@
+package bitbucket
+
+// related docs: https://developer.atlassian.com/cloud/bitbucket/rest/intro/
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/vcs"
+)
+
+// cloudEvents maps OTF event types to Bitbucket Cloud webhook events.
+var cloudEvents = webhookEvents{
+	vcs.EventTypePush: {"repo:push"},
+	vcs.EventTypeTag:  {"repo:push"},
+	vcs.EventTypePull: {
+		"pullrequest:created",
+		"pullrequest:updated",
+		"pullrequest:fulfilled",
+		"pullrequest:rejected",
+	},
+	vcs.EventTypeComment: {"pullrequest:comment_created"},
+}
+
+type (
+	// CloudClient is a client for Bitbucket Cloud. A repo's owner is its
+	// workspace, and its name is its slug.
+	CloudClient struct {
+		api *api
+		// web is the base URL of the Bitbucket website
+		web url.URL
+	}
+
+	cloudPage[T any] struct {
+		Values []T    `json:"values"`
+		Next   string `json:"next"`
+	}
+
+	cloudLink struct {
+		Href string `json:"href"`
+	}
+
+	cloudRepository struct {
+		FullName   string `json:"full_name"`
+		MainBranch *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+		Links struct {
+			HTML cloudLink `json:"html"`
+		} `json:"links"`
+	}
+
+	cloudUser struct {
+		UUID        string `json:"uuid"`
+		DisplayName string `json:"display_name"`
+		Nickname    string `json:"nickname"`
+		Links       struct {
+			HTML   cloudLink `json:"html"`
+			Avatar cloudLink `json:"avatar"`
+		} `json:"links"`
+	}
+
+	cloudCommit struct {
+		Hash  string `json:"hash"`
+		Links struct {
+			HTML cloudLink `json:"html"`
+		} `json:"links"`
+		Author struct {
+			Raw  string     `json:"raw"`
+			User *cloudUser `json:"user"`
+		} `json:"author"`
+	}
+
+	cloudPullRequest struct {
+		ID     int    `json:"id"`
+		Title  string `json:"title"`
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+			Commit struct {
+				// NOTE: abbreviated commit hash
+				Hash string `json:"hash"`
+			} `json:"commit"`
+			Repository cloudRepository `json:"repository"`
+		} `json:"source"`
+		Destination struct {
+			Repository cloudRepository `json:"repository"`
+		} `json:"destination"`
+		Participants []struct {
+			Approved bool `json:"approved"`
+		} `json:"participants"`
+		Links struct {
+			HTML cloudLink `json:"html"`
+		} `json:"links"`
+	}
+
+	cloudComment struct {
+		ID      int `json:"id"`
+		Content struct {
+			Raw string `json:"raw"`
+		} `json:"content"`
+	}
+
+	cloudWebhook struct {
+		UUID        string   `json:"uuid,omitempty"`
+		Description string   `json:"description"`
+		URL         string   `json:"url"`
+		Active      bool     `json:"active"`
+		Secret      string   `json:"secret,omitempty"`
+		Events      []string `json:"events"`
+	}
+
+	cloudDiffStat struct {
+		Status string `json:"status"`
+		Old    *struct {
+			Path string `json:"path"`
+		} `json:"old"`
+		New *struct {
+			Path string `json:"path"`
+		} `json:"new"`
+	}
+)
+
+func newCloudClient(web, apiURL url.URL, token string, skipTLSVerification bool) *CloudClient {
+	return &CloudClient{
+		api: newAPI(apiURL, token, skipTLSVerification),
+		web: web,
+	}
+}
+
+// cloudList retrieves all pages of results starting at the URL.
+func cloudList[T any](ctx context.Context, a *api, u string) ([]T, error) {
+	var rv []T
+	for u != "" {
+		var page cloudPage[T]
+		if err := a.do(ctx, "GET", u, nil, &page); err != nil {
+			return nil, err
+		}
+		rv = append(rv, page.Values...)
+		u = page.Next
+	}
+	return rv, nil
+}
+
+func (c *CloudClient) repoEndpoint(repo vcs.Repo, query url.Values, segments ...string) string {
+	return c.api.endpoint(query, append([]string{"repositories", repo.Owner(), repo.Name()}, segments...)...)
+}
+
+func (c *CloudClient) ListRepositories(ctx context.Context, opts vcs.ListRepositoriesOptions) ([]vcs.Repo, error) {
+	query := url.Values{
+		// only list repositories on which the user can create webhooks
+		"role": {"admin"},
+		// retrieve repositories in order of most recently updated
+		"sort": {"-updated_on"},
+	}
+	if opts.PageSize > 0 {
+		query.Set("pagelen", strconv.Itoa(opts.PageSize))
+	}
+	var page cloudPage[cloudRepository]
+	if err := c.api.do(ctx, "GET", c.api.endpoint(query, "repositories"), nil, &page); err != nil {
+		return nil, err
+	}
+	repos := make([]vcs.Repo, len(page.Values))
+	for i, repo := range page.Values {
+		var err error
+		repos[i], err = vcs.NewRepoFromString(repo.FullName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+func (c *CloudClient) GetDefaultBranch(ctx context.Context, identifier string) (string, error) {
+	repo, err := vcs.NewRepoFromString(identifier)
+	if err != nil {
+		return "", err
+	}
+	var bbrepo cloudRepository
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(repo, nil), nil, &bbrepo); err != nil {
+		return "", err
+	}
+	if bbrepo.MainBranch == nil {
+		return "", errors.New("repository has no main branch")
+	}
+	return bbrepo.MainBranch.Name, nil
+}
+
+func (c *CloudClient) GetRepoTarball(ctx context.Context, opts vcs.GetRepoTarballOptions) ([]byte, string, error) {
+	var ref string
+	if opts.Ref != nil {
+		ref = *opts.Ref
+	}
+	if ref == "" {
+		// nil means default branch
+		var err error
+		ref, err = c.GetDefaultBranch(ctx, opts.Repo.String())
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	// resolve the ref to a commit so that the caller knows exactly which
+	// commit the tarball contains.
+	commit, err := c.getCommit(ctx, opts.Repo, ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving ref %s: %w", ref, err)
+	}
+	// archives are only available from the website rather than the API.
+	link := c.web.JoinPath(opts.Repo.Owner(), opts.Repo.Name(), "get", commit.Hash+".tar.gz")
+	tarball, err := c.api.download(ctx, link.String())
+	if err != nil {
+		return nil, "", fmt.Errorf("retrieving archive: %w", err)
+	}
+
+	// Bitbucket tarball contents are contained within a top-level directory
+	// named after the workspace, repo and commit. We want the tarball without
+	// this directory, so we re-tar the contents without the top-level
+	// directory.
+	untarpath, err := os.MkdirTemp("", fmt.Sprintf("bitbucket-%s-%s-*", opts.Repo.Owner(), opts.Repo.Name()))
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(untarpath)
+	}()
+	if err := internal.Unpack(bytes.NewReader(tarball), untarpath); err != nil {
+		return nil, "", err
+	}
+	contents, err := os.ReadDir(untarpath)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(contents) != 1 {
+		return nil, "", fmt.Errorf("expected only one top-level directory; instead got %s", contents)
+	}
+	tarball, err = internal.Pack(path.Join(untarpath, contents[0].Name()))
+	if err != nil {
+		return nil, "", err
+	}
+	return tarball, commit.Hash, nil
+}
+
+func (c *CloudClient) CreateWebhook(ctx context.Context, opts vcs.CreateWebhookOptions) (string, error) {
+	events, err := cloudEvents.toBitbucket(opts.Events)
+	if err != nil {
+		return "", err
+	}
+	hook := cloudWebhook{
+		Description: "otf",
+		URL:         opts.Endpoint,
+		Active:      true,
+		Secret:      opts.Secret,
+		Events:      events,
+	}
+	if err := c.api.do(ctx, "POST", c.repoEndpoint(opts.Repo, nil, "hooks"), &hook, &hook); err != nil {
+		return "", err
+	}
+	return hook.UUID, nil
+}
+
+func (c *CloudClient) UpdateWebhook(ctx context.Context, id string, opts vcs.UpdateWebhookOptions) error {
+	events, err := cloudEvents.toBitbucket(opts.Events)
+	if err != nil {
+		return err
+	}
+	hook := cloudWebhook{
+		Description: "otf",
+		URL:         opts.Endpoint,
+		Active:      true,
+		Secret:      opts.Secret,
+		Events:      events,
+	}
+	return c.api.do(ctx, "PUT", c.repoEndpoint(opts.Repo, nil, "hooks", id), &hook, nil)
+}
+
+func (c *CloudClient) GetWebhook(ctx context.Context, opts vcs.GetWebhookOptions) (vcs.Webhook, error) {
+	var hook cloudWebhook
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(opts.Repo, nil, "hooks", opts.ID), nil, &hook); err != nil {
+		// a deleted webhook results in internal.ErrResourceNotFound
+		return vcs.Webhook{}, err
+	}
+	return vcs.Webhook{
+		ID:       hook.UUID,
+		Repo:     opts.Repo,
+		Events:   cloudEvents.fromBitbucket(hook.Events),
+		Endpoint: hook.URL,
+	}, nil
+}
+
+func (c *CloudClient) DeleteWebhook(ctx context.Context, opts vcs.DeleteWebhookOptions) error {
+	return c.api.do(ctx, "DELETE", c.repoEndpoint(opts.Repo, nil, "hooks", opts.ID), nil, nil)
+}
+
+func (c *CloudClient) SetStatus(ctx context.Context, opts vcs.SetStatusOptions) error {
+	state, err := buildState(opts.Status)
+	if err != nil {
+		return err
+	}
+	status := buildStatus{
+		Key:         buildKey(opts.Workspace),
+		State:       state,
+		Name:        buildKey(opts.Workspace),
+		URL:         opts.TargetURL,
+		Description: opts.Description,
+	}
+	return c.api.do(ctx, "POST", c.repoEndpoint(opts.Repo, nil, "commit", opts.Ref, "statuses", "build"), &status, nil)
+}
+
+func (c *CloudClient) ListTags(ctx context.Context, opts vcs.ListTagsOptions) ([]string, error) {
+	query := url.Values{"pagelen": {"100"}}
+	if opts.Prefix != "" {
+		// the query only matches tags containing the prefix; those that
+		// don't start with the prefix are filtered out below.
+		query.Set("q", fmt.Sprintf("name ~ %q", opts.Prefix))
+	}
+	tags, err := cloudList[struct {
+		Name string `json:"name"`
+	}](ctx, c.api, c.repoEndpoint(opts.Repo, query, "refs", "tags"))
+	if err != nil {
+		return nil, err
+	}
+	rv := []string{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag.Name, opts.Prefix) {
+			rv = append(rv, "tags/"+tag.Name)
+		}
+	}
+	return rv, nil
+}
+
+// ListPullRequestFiles returns the paths of files that are modified in the pull request
+func (c *CloudClient) ListPullRequestFiles(ctx context.Context, repo vcs.Repo, pull int) ([]string, error) {
+	stats, err := cloudList[cloudDiffStat](ctx, c.api, c.repoEndpoint(repo, nil, "pullrequests", strconv.Itoa(pull), "diffstat"))
+	if err != nil {
+		return nil, err
+	}
+	return diffStatPaths(stats), nil
+}
+
+// ListChangedFiles returns the paths of files changed between two commits.
+func (c *CloudClient) ListChangedFiles(ctx context.Context, repo vcs.Repo, from, to string) ([]string, error) {
+	stats, err := cloudList[cloudDiffStat](ctx, c.api, c.repoEndpoint(repo, nil, "diffstat", to+".."+from))
+	if err != nil {
+		return nil, err
+	}
+	return diffStatPaths(stats), nil
+}
+
+func (c *CloudClient) GetPullRequest(ctx context.Context, repo vcs.Repo, pull int) (vcs.PullRequest, error) {
+	var pr cloudPullRequest
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(repo, nil, "pullrequests", strconv.Itoa(pull)), nil, &pr); err != nil {
+		return vcs.PullRequest{}, err
+	}
+	// expand the abbreviated commit hash
+	commit, err := c.getCommit(ctx, repo, pr.Source.Commit.Hash)
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	// the API doesn't report whether a pull request is mergeable, but
+	// conflicting files are reported in its diffstat.
+	stats, err := cloudList[cloudDiffStat](ctx, c.api, c.repoEndpoint(repo, nil, "pullrequests", strconv.Itoa(pull), "diffstat"))
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	rv := vcs.PullRequest{
+		Number:    pr.ID,
+		URL:       pr.Links.HTML.Href,
+		Title:     pr.Title,
+		Branch:    pr.Source.Branch.Name,
+		CommitSHA: commit.Hash,
+		CommitURL: commit.Links.HTML.Href,
+		Mergeable: !slices.ContainsFunc(stats, func(stat cloudDiffStat) bool {
+			return stat.Status == "merge conflict"
+		}),
+		Fork: pr.Source.Repository.FullName != pr.Destination.Repository.FullName,
+	}
+	for _, participant := range pr.Participants {
+		if participant.Approved {
+			rv.Approved = true
+		}
+	}
+	return rv, nil
+}
+
+// HasWriteAccess determines whether the user, identified by their UUID, has
+// write access to the repo.
+func (c *CloudClient) HasWriteAccess(ctx context.Context, repo vcs.Repo, user string) (bool, error) {
+	perms, err := cloudList[struct {
+		Permission string `json:"permission"`
+	}](ctx, c.api, c.api.endpoint(url.Values{
+		"q": {fmt.Sprintf("user.uuid=%q", user)},
+	}, "workspaces", repo.Owner(), "permissions", "repositories", repo.Name()))
+	if err != nil {
+		return false, err
+	}
+	for _, perm := range perms {
+		switch perm.Permission {
+		case "admin", "write":
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetCommit retrieves commit from the repo with the given git ref
+func (c *CloudClient) GetCommit(ctx context.Context, repo vcs.Repo, ref string) (vcs.Commit, error) {
+	commit, err := c.getCommit(ctx, repo, ref)
+	if err != nil {
+		return vcs.Commit{}, err
+	}
+	rv := vcs.Commit{
+		SHA: commit.Hash,
+		URL: commit.Links.HTML.Href,
+	}
+	if user := commit.Author.User; user != nil {
+		rv.Author = user.commitAuthor()
+	} else {
+		// author is not linked to a Bitbucket account
+		rv.Author.Username = commit.Author.Raw
+	}
+	return rv, nil
+}
+
+func (c *CloudClient) getCommit(ctx context.Context, repo vcs.Repo, ref string) (cloudCommit, error) {
+	var commit cloudCommit
+	err := c.api.do(ctx, "GET", c.repoEndpoint(repo, nil, "commit", strings.TrimPrefix(ref, "tags/")), nil, &commit)
+	return commit, err
+}
+
+func (c *CloudClient) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	var comment cloudComment
+	comment.Content.Raw = opts.Body
+	if err := c.api.do(ctx, "POST", c.repoEndpoint(opts.Repo, nil, "pullrequests", strconv.Itoa(opts.PullRequestNumber), "comments"), &comment, &comment); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(comment.ID), nil
+}
+
+func (c *CloudClient) UpdatePullRequestComment(ctx context.Context, opts vcs.UpdatePullRequestCommentOptions) error {
+	var comment cloudComment
+	comment.Content.Raw = opts.Body
+	return c.api.do(ctx, "PUT", c.repoEndpoint(opts.Repo, nil, "pullrequests", strconv.Itoa(opts.PullRequestNumber), "comments", opts.ID), &comment, nil)
+}
+
+func (u cloudUser) commitAuthor() vcs.CommitAuthor {
+	username := u.Nickname
+	if username == "" {
+		username = u.DisplayName
+	}
+	return vcs.CommitAuthor{
+		Username:   username,
+		ProfileURL: u.Links.HTML.Href,
+		AvatarURL:  u.Links.Avatar.Href,
+	}
+}
+
+// diffStatPaths returns the paths of files in a diffstat. The path of a
+// deleted file is its old path.
+func diffStatPaths(stats []cloudDiffStat) []string {
+	paths := make([]string, 0, len(stats))
+	for _, stat := range stats {
+		switch {
+		case stat.New != nil:
+			paths = append(paths, stat.New.Path)
+		case stat.Old != nil:
+			paths = append(paths, stat.Old.Path)
+		}
+	}
+	return paths
+}
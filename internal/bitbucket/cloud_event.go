@@ -0,0 +1,155 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/leg100/otf/internal/vcs"
+)
+
+type (
+	cloudPushEvent struct {
+		Actor      cloudUser       `json:"actor"`
+		Repository cloudRepository `json:"repository"`
+		Push       struct {
+			Changes []struct {
+				// New is nil if the ref was deleted
+				New *cloudPushRef `json:"new"`
+				// Old is nil if the ref was created
+				Old *cloudPushRef `json:"old"`
+			} `json:"changes"`
+		} `json:"push"`
+	}
+
+	cloudPushRef struct {
+		Type   string      `json:"type"` // branch or tag
+		Name   string      `json:"name"`
+		Target cloudCommit `json:"target"`
+	}
+
+	cloudPullRequestEvent struct {
+		Actor       cloudUser        `json:"actor"`
+		Repository  cloudRepository  `json:"repository"`
+		PullRequest cloudPullRequest `json:"pullrequest"`
+		// Only populated for comment events
+		Comment cloudComment `json:"comment"`
+	}
+)
+
+func handleCloudPushEvent(b []byte) (*vcs.EventPayload, error) {
+	var event cloudPushEvent
+	if err := json.Unmarshal(b, &event); err != nil {
+		return nil, err
+	}
+	if len(event.Push.Changes) == 0 {
+		return nil, vcs.NewErrIgnoreEvent("push event contains no changes")
+	}
+	// a push event only contains more than one change if more than one ref is
+	// pushed at once; only the first is handled.
+	change := event.Push.Changes[0]
+
+	repo, err := vcs.NewRepoFromString(event.Repository.FullName)
+	if err != nil {
+		return nil, err
+	}
+	to := vcs.EventPayload{Repo: repo}
+	setSender(&to, event.Actor.commitAuthor())
+
+	ref := change.New
+	switch {
+	case change.New != nil:
+		to.Action = vcs.ActionCreated
+		to.CommitSHA = change.New.Target.Hash
+		to.CommitURL = change.New.Target.Links.HTML.Href
+		// push events don't list the paths of changed files, which are
+		// instead retrieved by comparing the commits before and after the
+		// push.
+		if change.Old != nil {
+			to.PreviousCommitSHA = change.Old.Target.Hash
+		}
+	case change.Old != nil && change.Old.Type == "tag":
+		ref = change.Old
+		to.Action = vcs.ActionDeleted
+		to.CommitSHA = change.Old.Target.Hash
+	default:
+		return nil, vcs.NewErrIgnoreEvent("branch deleted")
+	}
+	switch ref.Type {
+	case "branch":
+		to.Type = vcs.EventTypePush
+		to.Branch = ref.Name
+	case "tag":
+		to.Type = vcs.EventTypeTag
+		to.Tag = ref.Name
+	default:
+		return nil, fmt.Errorf("unknown ref type: %s", ref.Type)
+	}
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return &to, nil
+}
+
+func handleCloudPullRequestEvent(eventtype string, b []byte) (*vcs.EventPayload, error) {
+	to, err := newCloudPullRequestPayload(b)
+	if err != nil {
+		return nil, err
+	}
+	to.Type = vcs.EventTypePull
+	switch eventtype {
+	case "pullrequest:created":
+		to.Action = vcs.ActionCreated
+	case "pullrequest:updated":
+		to.Action = vcs.ActionUpdated
+	case "pullrequest:fulfilled":
+		to.Action = vcs.ActionMerged
+	case "pullrequest:rejected":
+		// declined
+		to.Action = vcs.ActionDeleted
+	}
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return to, nil
+}
+
+func handleCloudCommentEvent(b []byte) (*vcs.EventPayload, error) {
+	to, err := newCloudPullRequestPayload(b)
+	if err != nil {
+		return nil, err
+	}
+	to.Type = vcs.EventTypeComment
+	to.Action = vcs.ActionCreated
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return to, nil
+}
+
+// newCloudPullRequestPayload populates an OTF event from the details common
+// to pull request and comment events.
+func newCloudPullRequestPayload(b []byte) (*vcs.EventPayload, error) {
+	var event cloudPullRequestEvent
+	if err := json.Unmarshal(b, &event); err != nil {
+		return nil, err
+	}
+	repo, err := vcs.NewRepoFromString(event.Repository.FullName)
+	if err != nil {
+		return nil, err
+	}
+	pr := event.PullRequest
+	to := vcs.EventPayload{
+		Repo: repo,
+		// NOTE: the commit hash is abbreviated
+		CommitSHA:         pr.Source.Commit.Hash,
+		CommitURL:         event.Repository.Links.HTML.Href + "/commits/" + pr.Source.Commit.Hash,
+		Branch:            pr.Source.Branch.Name,
+		PullRequestNumber: pr.ID,
+		PullRequestURL:    pr.Links.HTML.Href,
+		PullRequestTitle:  pr.Title,
+		Comment:           event.Comment.Content.Raw,
+	}
+	setSender(&to, event.Actor.commitAuthor())
+	to.SenderID = event.Actor.UUID
+	return &to, nil
+}
@@ -0,0 +1,86 @@
+package bitbucket
+
+// related docs:
+//
+// https://support.atlassian.com/bitbucket-cloud/docs/event-payloads/
+// https://confluence.atlassian.com/bitbucketserver/event-payload-938025882.html
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/leg100/otf/internal/vcs"
+)
+
+const (
+	httpHeaderSignature = "X-Hub-Signature"
+	httpHeaderEvent     = "X-Event-Key"
+)
+
+// HandleEvent handles events from both Bitbucket Cloud and Bitbucket Data
+// Center, which use different names for their events.
+func HandleEvent(r *http.Request, secret string) (*vcs.EventPayload, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(secret, r.Header.Get(httpHeaderSignature), b); err != nil {
+		return nil, err
+	}
+
+	eventtype := r.Header.Get(httpHeaderEvent)
+
+	switch eventtype {
+	// Bitbucket Cloud events
+	case "repo:push":
+		return handleCloudPushEvent(b)
+	case "pullrequest:created", "pullrequest:updated", "pullrequest:fulfilled", "pullrequest:rejected":
+		return handleCloudPullRequestEvent(eventtype, b)
+	case "pullrequest:comment_created":
+		return handleCloudCommentEvent(b)
+	// Bitbucket Data Center events
+	case "repo:refs_changed":
+		return handleServerPushEvent(b)
+	case "pr:opened", "pr:from_ref_updated", "pr:merged", "pr:declined", "pr:deleted":
+		return handleServerPullRequestEvent(eventtype, b)
+	case "pr:comment:added":
+		return handleServerCommentEvent(b)
+	default:
+		// includes the diagnostics:ping event sent when testing a webhook
+		return nil, vcs.NewErrIgnoreEvent("unsupported event: %s", eventtype)
+	}
+}
+
+// verifySignature verifies the signature of a payload, which takes the form
+// sha256=<hex-encoded HMAC>.
+func verifySignature(secret, signature string, payload []byte) error {
+	if signature == "" {
+		return errors.New("no signature found")
+	}
+	encoded, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return errors.New("unsupported signature algorithm")
+	}
+	got, err := hex.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errors.New("invalid payload")
+	}
+	return nil
+}
+
+// setSender populates the event with the user that triggered it.
+func setSender(to *vcs.EventPayload, sender vcs.CommitAuthor) {
+	to.SenderUsername = sender.Username
+	to.SenderHTMLURL = sender.ProfileURL
+	to.SenderAvatarURL = sender.AvatarURL
+}
@@ -0,0 +1,243 @@
+package bitbucket
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/leg100/otf/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHandler(t *testing.T) {
+	var (
+		cloudSender = vcs.EventPayload{
+			SenderUsername:  "bob",
+			SenderAvatarURL: "https://avatar-management.services.atlassian.com/bob/128",
+			SenderHTMLURL:   "https://bitbucket.org/%7Bd301aafa-d676-4ee0-88be-962be7417567%7D/",
+		}
+		serverSender = vcs.EventPayload{
+			SenderUsername: "bob",
+			SenderHTMLURL:  "https://bitbucket.example.com/users/bob",
+		}
+	)
+	// withSender populates the event with the sender's details
+	withSender := func(sender, event vcs.EventPayload) *vcs.EventPayload {
+		event.SenderUsername = sender.SenderUsername
+		event.SenderAvatarURL = sender.SenderAvatarURL
+		event.SenderHTMLURL = sender.SenderHTMLURL
+		return &event
+	}
+
+	tests := []struct {
+		name      string
+		eventType string
+		body      string
+		want      *vcs.EventPayload
+		ignore    bool
+	}{
+		{
+			name:      "cloud push",
+			eventType: "repo:push",
+			body:      "./testdata/cloud_push.json",
+			want: withSender(cloudSender, vcs.EventPayload{
+				Type:              vcs.EventTypePush,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("acme", "terraform"),
+				Branch:            "main",
+				CommitSHA:         "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL:         "https://bitbucket.org/acme/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+				PreviousCommitSHA: "1e65c05c1d5171631d92438a13901ca7dae9618c",
+			}),
+		},
+		{
+			name:      "cloud tag created",
+			eventType: "repo:push",
+			body:      "./testdata/cloud_tag_created.json",
+			want: withSender(cloudSender, vcs.EventPayload{
+				Type:      vcs.EventTypeTag,
+				Action:    vcs.ActionCreated,
+				Repo:      vcs.NewMustRepo("acme", "terraform"),
+				Tag:       "v1.0.0",
+				CommitSHA: "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL: "https://bitbucket.org/acme/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+			}),
+		},
+		{
+			name:      "cloud branch deleted",
+			eventType: "repo:push",
+			body:      "./testdata/cloud_branch_deleted.json",
+			ignore:    true,
+		},
+		{
+			name:      "cloud pull request created",
+			eventType: "pullrequest:created",
+			body:      "./testdata/cloud_pullrequest_created.json",
+			want: withSender(cloudSender, vcs.EventPayload{
+				Type:              vcs.EventTypePull,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("acme", "terraform"),
+				Branch:            "update-vpc",
+				CommitSHA:         "9fec847784ab",
+				CommitURL:         "https://bitbucket.org/acme/terraform/commits/9fec847784ab",
+				PullRequestNumber: 7,
+				PullRequestURL:    "https://bitbucket.org/acme/terraform/pull-requests/7",
+				PullRequestTitle:  "Update VPC CIDR",
+				SenderID:          "{d301aafa-d676-4ee0-88be-962be7417567}",
+			}),
+		},
+		{
+			name:      "cloud pull request merged",
+			eventType: "pullrequest:fulfilled",
+			body:      "./testdata/cloud_pullrequest_created.json",
+			want: withSender(cloudSender, vcs.EventPayload{
+				Type:              vcs.EventTypePull,
+				Action:            vcs.ActionMerged,
+				Repo:              vcs.NewMustRepo("acme", "terraform"),
+				Branch:            "update-vpc",
+				CommitSHA:         "9fec847784ab",
+				CommitURL:         "https://bitbucket.org/acme/terraform/commits/9fec847784ab",
+				PullRequestNumber: 7,
+				PullRequestURL:    "https://bitbucket.org/acme/terraform/pull-requests/7",
+				PullRequestTitle:  "Update VPC CIDR",
+				SenderID:          "{d301aafa-d676-4ee0-88be-962be7417567}",
+			}),
+		},
+		{
+			name:      "cloud pull request comment",
+			eventType: "pullrequest:comment_created",
+			body:      "./testdata/cloud_pullrequest_comment.json",
+			want: withSender(cloudSender, vcs.EventPayload{
+				Type:              vcs.EventTypeComment,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("acme", "terraform"),
+				Branch:            "update-vpc",
+				CommitSHA:         "9fec847784ab",
+				CommitURL:         "https://bitbucket.org/acme/terraform/commits/9fec847784ab",
+				PullRequestNumber: 7,
+				PullRequestURL:    "https://bitbucket.org/acme/terraform/pull-requests/7",
+				PullRequestTitle:  "Update VPC CIDR",
+				SenderID:          "{d301aafa-d676-4ee0-88be-962be7417567}",
+				Comment:           "otf plan",
+			}),
+		},
+		{
+			name:      "data center push",
+			eventType: "repo:refs_changed",
+			body:      "./testdata/server_push.json",
+			want: withSender(serverSender, vcs.EventPayload{
+				Type:              vcs.EventTypePush,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("ACME", "terraform"),
+				Branch:            "main",
+				CommitSHA:         "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL:         "https://bitbucket.example.com/projects/ACME/repos/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+				PreviousCommitSHA: "1e65c05c1d5171631d92438a13901ca7dae9618c",
+			}),
+		},
+		{
+			name:      "data center tag deleted",
+			eventType: "repo:refs_changed",
+			body:      "./testdata/server_tag_deleted.json",
+			want: withSender(serverSender, vcs.EventPayload{
+				Type:      vcs.EventTypeTag,
+				Action:    vcs.ActionDeleted,
+				Repo:      vcs.NewMustRepo("ACME", "terraform"),
+				Tag:       "v1.0.0",
+				CommitSHA: "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL: "https://bitbucket.example.com/projects/ACME/repos/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+			}),
+		},
+		{
+			name:      "data center pull request opened",
+			eventType: "pr:opened",
+			body:      "./testdata/server_pr_opened.json",
+			want: withSender(serverSender, vcs.EventPayload{
+				Type:              vcs.EventTypePull,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("ACME", "terraform"),
+				Branch:            "update-vpc",
+				CommitSHA:         "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL:         "https://bitbucket.example.com/projects/ACME/repos/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+				PullRequestNumber: 7,
+				PullRequestURL:    "https://bitbucket.example.com/projects/ACME/repos/terraform/pull-requests/7",
+				PullRequestTitle:  "Update VPC CIDR",
+				SenderID:          "bob",
+			}),
+		},
+		{
+			name:      "data center pull request merged",
+			eventType: "pr:merged",
+			body:      "./testdata/server_pr_merged.json",
+			want: withSender(serverSender, vcs.EventPayload{
+				Type:              vcs.EventTypePull,
+				Action:            vcs.ActionMerged,
+				Repo:              vcs.NewMustRepo("ACME", "terraform"),
+				Branch:            "update-vpc",
+				CommitSHA:         "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL:         "https://bitbucket.example.com/projects/ACME/repos/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+				PullRequestNumber: 7,
+				PullRequestURL:    "https://bitbucket.example.com/projects/ACME/repos/terraform/pull-requests/7",
+				PullRequestTitle:  "Update VPC CIDR",
+				SenderID:          "bob",
+			}),
+		},
+		{
+			name:      "data center pull request comment",
+			eventType: "pr:comment:added",
+			body:      "./testdata/server_pr_comment.json",
+			want: withSender(serverSender, vcs.EventPayload{
+				Type:              vcs.EventTypeComment,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("ACME", "terraform"),
+				Branch:            "update-vpc",
+				CommitSHA:         "9fec847784abb10b2fa567ee63b85bd238955d0e",
+				CommitURL:         "https://bitbucket.example.com/projects/ACME/repos/terraform/commits/9fec847784abb10b2fa567ee63b85bd238955d0e",
+				PullRequestNumber: 7,
+				PullRequestURL:    "https://bitbucket.example.com/projects/ACME/repos/terraform/pull-requests/7",
+				PullRequestTitle:  "Update VPC CIDR",
+				SenderID:          "bob",
+				Comment:           "otf plan",
+			}),
+		},
+		{
+			name:      "data center webhook test",
+			eventType: "diagnostics:ping",
+			body:      "./testdata/server_ping.json",
+			ignore:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := os.ReadFile(tt.body)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest("POST", "/", bytes.NewReader(payload))
+			r.Header.Add("Content-type", "application/json")
+			r.Header.Add(httpHeaderEvent, tt.eventType)
+			r.Header.Add(httpHeaderSignature, signature("abc123", payload))
+			got, err := HandleEvent(r, "abc123")
+			if tt.ignore {
+				var ignore vcs.ErrIgnoreEvent
+				assert.True(t, errors.As(err, &ignore))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEventHandler_InvalidSignature(t *testing.T) {
+	payload, err := os.ReadFile("./testdata/cloud_push.json")
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(payload))
+	r.Header.Add(httpHeaderEvent, "repo:push")
+	r.Header.Add(httpHeaderSignature, signature("wrong-secret", payload))
+	_, err = HandleEvent(r, "abc123")
+	assert.EqualError(t, err, "invalid payload")
+}
@@ -0,0 +1,462 @@
+package bitbucket
+
+// related docs: https://developer.atlassian.com/server/bitbucket/rest/
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/leg100/otf/internal/vcs"
+)
+
+// serverEvents maps OTF event types to Bitbucket Data Center webhook events.
+var serverEvents = webhookEvents{
+	vcs.EventTypePush: {"repo:refs_changed"},
+	vcs.EventTypeTag:  {"repo:refs_changed"},
+	vcs.EventTypePull: {
+		"pr:opened",
+		"pr:from_ref_updated",
+		"pr:merged",
+		"pr:declined",
+		"pr:deleted",
+	},
+	vcs.EventTypeComment: {"pr:comment:added"},
+}
+
+type (
+	// ServerClient is a client for Bitbucket Data Center (formerly Bitbucket
+	// Server). A repo's owner is its project key, and its name is its slug.
+	ServerClient struct {
+		api *api
+		// web is the base URL of the Bitbucket website
+		web url.URL
+	}
+
+	serverPage[T any] struct {
+		Values        []T  `json:"values"`
+		IsLastPage    bool `json:"isLastPage"`
+		NextPageStart int  `json:"nextPageStart"`
+	}
+
+	serverLinks struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	}
+
+	serverRepository struct {
+		Slug    string `json:"slug"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+		Links serverLinks `json:"links"`
+	}
+
+	serverUser struct {
+		Name         string      `json:"name"`
+		Slug         string      `json:"slug"`
+		DisplayName  string      `json:"displayName"`
+		EmailAddress string      `json:"emailAddress"`
+		AvatarURL    string      `json:"avatarUrl"`
+		Links        serverLinks `json:"links"`
+	}
+
+	serverRef struct {
+		ID           string           `json:"id"`
+		DisplayID    string           `json:"displayId"`
+		LatestCommit string           `json:"latestCommit"`
+		Repository   serverRepository `json:"repository"`
+	}
+
+	serverCommit struct {
+		ID     string     `json:"id"`
+		Author serverUser `json:"author"`
+	}
+
+	serverPullRequest struct {
+		ID        int         `json:"id"`
+		Title     string      `json:"title"`
+		FromRef   serverRef   `json:"fromRef"`
+		ToRef     serverRef   `json:"toRef"`
+		Links     serverLinks `json:"links"`
+		Reviewers []struct {
+			Approved bool `json:"approved"`
+		} `json:"reviewers"`
+	}
+
+	serverComment struct {
+		ID      int    `json:"id,omitempty"`
+		Version int    `json:"version"`
+		Text    string `json:"text"`
+	}
+
+	serverWebhook struct {
+		ID            int      `json:"id,omitempty"`
+		Name          string   `json:"name"`
+		URL           string   `json:"url"`
+		Active        bool     `json:"active"`
+		Events        []string `json:"events"`
+		Configuration struct {
+			Secret string `json:"secret,omitempty"`
+		} `json:"configuration"`
+	}
+
+	serverChange struct {
+		Path struct {
+			ToString string `json:"toString"`
+		} `json:"path"`
+	}
+)
+
+func newServerClient(web url.URL, token string, skipTLSVerification bool) *ServerClient {
+	return &ServerClient{
+		api: newAPI(*web.JoinPath("rest", "api", "1.0"), token, skipTLSVerification),
+		web: web,
+	}
+}
+
+// serverList retrieves all pages of results from the URL.
+func serverList[T any](ctx context.Context, a *api, u string) ([]T, error) {
+	next, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	query := next.Query()
+	query.Set("limit", "100")
+
+	var rv []T
+	for {
+		next.RawQuery = query.Encode()
+		var page serverPage[T]
+		if err := a.do(ctx, "GET", next.String(), nil, &page); err != nil {
+			return nil, err
+		}
+		rv = append(rv, page.Values...)
+		if page.IsLastPage {
+			return rv, nil
+		}
+		query.Set("start", strconv.Itoa(page.NextPageStart))
+	}
+}
+
+func (c *ServerClient) repoEndpoint(repo vcs.Repo, query url.Values, segments ...string) string {
+	return c.api.endpoint(query, append([]string{"projects", repo.Owner(), "repos", repo.Name()}, segments...)...)
+}
+
+func (c *ServerClient) ListRepositories(ctx context.Context, opts vcs.ListRepositoriesOptions) ([]vcs.Repo, error) {
+	query := url.Values{
+		// only list repositories on which the user can create webhooks
+		"permission": {"REPO_ADMIN"},
+	}
+	if opts.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(opts.PageSize))
+	}
+	var page serverPage[serverRepository]
+	if err := c.api.do(ctx, "GET", c.api.endpoint(query, "repos"), nil, &page); err != nil {
+		return nil, err
+	}
+	repos := make([]vcs.Repo, len(page.Values))
+	for i, repo := range page.Values {
+		var err error
+		repos[i], err = vcs.NewRepo(repo.Project.Key, repo.Slug)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+func (c *ServerClient) GetDefaultBranch(ctx context.Context, identifier string) (string, error) {
+	repo, err := vcs.NewRepoFromString(identifier)
+	if err != nil {
+		return "", err
+	}
+	var ref serverRef
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(repo, nil, "default-branch"), nil, &ref); err != nil {
+		return "", err
+	}
+	return ref.DisplayID, nil
+}
+
+func (c *ServerClient) GetRepoTarball(ctx context.Context, opts vcs.GetRepoTarballOptions) ([]byte, string, error) {
+	var ref string
+	if opts.Ref != nil {
+		ref = *opts.Ref
+	}
+	if ref == "" {
+		// nil means default branch
+		var err error
+		ref, err = c.GetDefaultBranch(ctx, opts.Repo.String())
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	// resolve the ref to a commit so that the caller knows exactly which
+	// commit the tarball contains.
+	commit, err := c.getCommit(ctx, opts.Repo, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	// unlike other providers, the contents of the archive are not placed
+	// within a top-level directory.
+	tarball, err := c.api.download(ctx, c.repoEndpoint(opts.Repo, url.Values{
+		"at":     {commit.ID},
+		"format": {"tar.gz"},
+	}, "archive"))
+	if err != nil {
+		return nil, "", err
+	}
+	return tarball, commit.ID, nil
+}
+
+func (c *ServerClient) CreateWebhook(ctx context.Context, opts vcs.CreateWebhookOptions) (string, error) {
+	hook, err := newServerWebhook(opts)
+	if err != nil {
+		return "", err
+	}
+	if err := c.api.do(ctx, "POST", c.repoEndpoint(opts.Repo, nil, "webhooks"), &hook, &hook); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(hook.ID), nil
+}
+
+func (c *ServerClient) UpdateWebhook(ctx context.Context, id string, opts vcs.UpdateWebhookOptions) error {
+	hook, err := newServerWebhook(vcs.CreateWebhookOptions(opts))
+	if err != nil {
+		return err
+	}
+	return c.api.do(ctx, "PUT", c.repoEndpoint(opts.Repo, nil, "webhooks", id), &hook, nil)
+}
+
+func (c *ServerClient) GetWebhook(ctx context.Context, opts vcs.GetWebhookOptions) (vcs.Webhook, error) {
+	var hook serverWebhook
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(opts.Repo, nil, "webhooks", opts.ID), nil, &hook); err != nil {
+		// a deleted webhook results in internal.ErrResourceNotFound
+		return vcs.Webhook{}, err
+	}
+	return vcs.Webhook{
+		ID:       strconv.Itoa(hook.ID),
+		Repo:     opts.Repo,
+		Events:   serverEvents.fromBitbucket(hook.Events),
+		Endpoint: hook.URL,
+	}, nil
+}
+
+func (c *ServerClient) DeleteWebhook(ctx context.Context, opts vcs.DeleteWebhookOptions) error {
+	return c.api.do(ctx, "DELETE", c.repoEndpoint(opts.Repo, nil, "webhooks", opts.ID), nil, nil)
+}
+
+func newServerWebhook(opts vcs.CreateWebhookOptions) (serverWebhook, error) {
+	events, err := serverEvents.toBitbucket(opts.Events)
+	if err != nil {
+		return serverWebhook{}, err
+	}
+	hook := serverWebhook{
+		Name:   "otf",
+		URL:    opts.Endpoint,
+		Active: true,
+		Events: events,
+	}
+	hook.Configuration.Secret = opts.Secret
+	return hook, nil
+}
+
+func (c *ServerClient) SetStatus(ctx context.Context, opts vcs.SetStatusOptions) error {
+	state, err := buildState(opts.Status)
+	if err != nil {
+		return err
+	}
+	status := buildStatus{
+		Key:         buildKey(opts.Workspace),
+		State:       state,
+		Name:        buildKey(opts.Workspace),
+		URL:         opts.TargetURL,
+		Description: opts.Description,
+	}
+	return c.api.do(ctx, "POST", c.repoEndpoint(opts.Repo, nil, "commits", opts.Ref, "builds"), &status, nil)
+}
+
+func (c *ServerClient) ListTags(ctx context.Context, opts vcs.ListTagsOptions) ([]string, error) {
+	// the filter only matches tags containing the prefix; those that don't
+	// start with the prefix are filtered out below.
+	tags, err := serverList[serverRef](ctx, c.api, c.repoEndpoint(opts.Repo, url.Values{
+		"filterText": {opts.Prefix},
+	}, "tags"))
+	if err != nil {
+		return nil, err
+	}
+	rv := []string{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag.DisplayID, opts.Prefix) {
+			rv = append(rv, "tags/"+tag.DisplayID)
+		}
+	}
+	return rv, nil
+}
+
+// ListPullRequestFiles returns the paths of files that are modified in the pull request
+func (c *ServerClient) ListPullRequestFiles(ctx context.Context, repo vcs.Repo, pull int) ([]string, error) {
+	changes, err := serverList[serverChange](ctx, c.api, c.repoEndpoint(repo, nil, "pull-requests", strconv.Itoa(pull), "changes"))
+	if err != nil {
+		return nil, err
+	}
+	return changedPaths(changes), nil
+}
+
+// ListChangedFiles returns the paths of files changed between two commits.
+func (c *ServerClient) ListChangedFiles(ctx context.Context, repo vcs.Repo, from, to string) ([]string, error) {
+	// NOTE: the API compares the commits the other way round, listing the
+	// changes in its 'from' commit that are not in its 'to' commit.
+	changes, err := serverList[serverChange](ctx, c.api, c.repoEndpoint(repo, url.Values{
+		"from": {to},
+		"to":   {from},
+	}, "compare", "changes"))
+	if err != nil {
+		return nil, err
+	}
+	return changedPaths(changes), nil
+}
+
+func (c *ServerClient) GetPullRequest(ctx context.Context, repo vcs.Repo, pull int) (vcs.PullRequest, error) {
+	var pr serverPullRequest
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(repo, nil, "pull-requests", strconv.Itoa(pull)), nil, &pr); err != nil {
+		return vcs.PullRequest{}, err
+	}
+	var merge struct {
+		Conflicted bool `json:"conflicted"`
+	}
+	if err := c.api.do(ctx, "GET", c.repoEndpoint(repo, nil, "pull-requests", strconv.Itoa(pull), "merge"), nil, &merge); err != nil {
+		return vcs.PullRequest{}, err
+	}
+	from, to := pr.FromRef.Repository, pr.ToRef.Repository
+	rv := vcs.PullRequest{
+		Number:    pr.ID,
+		URL:       pr.Links.href(),
+		Title:     pr.Title,
+		Branch:    pr.FromRef.DisplayID,
+		CommitSHA: pr.FromRef.LatestCommit,
+		CommitURL: c.commitURL(repo, pr.FromRef.LatestCommit),
+		Mergeable: !merge.Conflicted,
+		Fork:      from.Project.Key != to.Project.Key || from.Slug != to.Slug,
+	}
+	for _, reviewer := range pr.Reviewers {
+		if reviewer.Approved {
+			rv.Approved = true
+		}
+	}
+	return rv, nil
+}
+
+// HasWriteAccess determines whether the user, identified by their username,
+// has write access to the repo.
+func (c *ServerClient) HasWriteAccess(ctx context.Context, repo vcs.Repo, user string) (bool, error) {
+	// the filter matches users whose username, name or email address
+	// contains the string, so it is necessary to check for an exact match.
+	users, err := serverList[serverUser](ctx, c.api, c.api.endpoint(url.Values{
+		"filter":                      {user},
+		"permission.1":                {"REPO_WRITE"},
+		"permission.1.projectKey":     {repo.Owner()},
+		"permission.1.repositorySlug": {repo.Name()},
+	}, "users"))
+	if err != nil {
+		return false, err
+	}
+	for _, u := range users {
+		if u.Name == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetCommit retrieves commit from the repo with the given git ref
+func (c *ServerClient) GetCommit(ctx context.Context, repo vcs.Repo, ref string) (vcs.Commit, error) {
+	commit, err := c.getCommit(ctx, repo, ref)
+	if err != nil {
+		return vcs.Commit{}, err
+	}
+	rv := vcs.Commit{
+		SHA: commit.ID,
+		URL: c.commitURL(repo, commit.ID),
+		Author: vcs.CommitAuthor{
+			Username:   commit.Author.username(),
+			ProfileURL: commit.Author.Links.href(),
+		},
+	}
+	if commit.Author.AvatarURL != "" {
+		// avatar URLs are relative to the base URL
+		if avatar, err := c.web.Parse(commit.Author.AvatarURL); err == nil {
+			rv.Author.AvatarURL = avatar.String()
+		}
+	}
+	return rv, nil
+}
+
+// getCommit retrieves the commit that a ref points to.
+func (c *ServerClient) getCommit(ctx context.Context, repo vcs.Repo, ref string) (serverCommit, error) {
+	if tag, ok := strings.CutPrefix(ref, "tags/"); ok {
+		ref = "refs/tags/" + tag
+	}
+	var page serverPage[serverCommit]
+	err := c.api.do(ctx, "GET", c.repoEndpoint(repo, url.Values{
+		"until":      {ref},
+		"limit":      {"1"},
+		"avatarSize": {"64"},
+	}, "commits"), nil, &page)
+	if err != nil {
+		return serverCommit{}, err
+	}
+	if len(page.Values) == 0 {
+		return serverCommit{}, errors.New("ref not found")
+	}
+	return page.Values[0], nil
+}
+
+func (c *ServerClient) commitURL(repo vcs.Repo, sha string) string {
+	return c.web.JoinPath("projects", repo.Owner(), "repos", repo.Name(), "commits", sha).String()
+}
+
+func (c *ServerClient) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	comment := serverComment{Text: opts.Body}
+	if err := c.api.do(ctx, "POST", c.repoEndpoint(opts.Repo, nil, "pull-requests", strconv.Itoa(opts.PullRequestNumber), "comments"), &comment, &comment); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(comment.ID), nil
+}
+
+func (c *ServerClient) UpdatePullRequestComment(ctx context.Context, opts vcs.UpdatePullRequestCommentOptions) error {
+	u := c.repoEndpoint(opts.Repo, nil, "pull-requests", strconv.Itoa(opts.PullRequestNumber), "comments", opts.ID)
+	// the current version of the comment must be provided when updating it.
+	var comment serverComment
+	if err := c.api.do(ctx, "GET", u, nil, &comment); err != nil {
+		return err
+	}
+	comment.Text = opts.Body
+	return c.api.do(ctx, "PUT", u, &comment, nil)
+}
+
+func (l serverLinks) href() string {
+	if len(l.Self) == 0 {
+		return ""
+	}
+	return l.Self[0].Href
+}
+
+func (u serverUser) username() string {
+	if u.Slug != "" {
+		return u.Slug
+	}
+	// author is not linked to a Bitbucket account
+	return u.Name
+}
+
+func changedPaths(changes []serverChange) []string {
+	paths := make([]string, len(changes))
+	for i, change := range changes {
+		paths[i] = change.Path.ToString
+	}
+	return paths
+}
@@ -0,0 +1,186 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/leg100/otf/internal/vcs"
+)
+
+type (
+	serverPushEvent struct {
+		Actor      serverUser       `json:"actor"`
+		Repository serverRepository `json:"repository"`
+		Changes    []struct {
+			Ref struct {
+				ID        string `json:"id"`
+				DisplayID string `json:"displayId"`
+				Type      string `json:"type"` // BRANCH or TAG
+			} `json:"ref"`
+			FromHash string `json:"fromHash"`
+			ToHash   string `json:"toHash"`
+			Type     string `json:"type"` // ADD, UPDATE or DELETE
+		} `json:"changes"`
+	}
+
+	serverPullRequestEvent struct {
+		Actor       serverUser        `json:"actor"`
+		PullRequest serverPullRequest `json:"pullRequest"`
+		// Only populated for comment events
+		Comment serverComment `json:"comment"`
+	}
+)
+
+func handleServerPushEvent(b []byte) (*vcs.EventPayload, error) {
+	var event serverPushEvent
+	if err := json.Unmarshal(b, &event); err != nil {
+		return nil, err
+	}
+	if len(event.Changes) == 0 {
+		return nil, vcs.NewErrIgnoreEvent("push event contains no changes")
+	}
+	// a push event only contains more than one change if more than one ref is
+	// pushed at once; only the first is handled.
+	change := event.Changes[0]
+
+	repo, err := vcs.NewRepo(event.Repository.Project.Key, event.Repository.Slug)
+	if err != nil {
+		return nil, err
+	}
+	to := vcs.EventPayload{Repo: repo}
+	setSender(&to, event.Actor.sender(event.Repository))
+
+	switch change.Type {
+	case "ADD", "UPDATE":
+		to.Action = vcs.ActionCreated
+		to.CommitSHA = change.ToHash
+		// push events don't list the paths of changed files, which are
+		// instead retrieved by comparing the commits before and after the
+		// push.
+		if change.Type == "UPDATE" {
+			to.PreviousCommitSHA = change.FromHash
+		}
+	case "DELETE":
+		if change.Ref.Type != "TAG" {
+			return nil, vcs.NewErrIgnoreEvent("branch deleted")
+		}
+		to.Action = vcs.ActionDeleted
+		to.CommitSHA = change.FromHash
+	default:
+		return nil, fmt.Errorf("unknown change type: %s", change.Type)
+	}
+	to.CommitURL = event.Repository.commitURL(to.CommitSHA)
+	switch change.Ref.Type {
+	case "BRANCH":
+		to.Type = vcs.EventTypePush
+		to.Branch = change.Ref.DisplayID
+	case "TAG":
+		to.Type = vcs.EventTypeTag
+		to.Tag = change.Ref.DisplayID
+	default:
+		return nil, fmt.Errorf("unknown ref type: %s", change.Ref.Type)
+	}
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return &to, nil
+}
+
+func handleServerPullRequestEvent(eventtype string, b []byte) (*vcs.EventPayload, error) {
+	to, err := newServerPullRequestPayload(b)
+	if err != nil {
+		return nil, err
+	}
+	to.Type = vcs.EventTypePull
+	switch eventtype {
+	case "pr:opened":
+		to.Action = vcs.ActionCreated
+	case "pr:from_ref_updated":
+		to.Action = vcs.ActionUpdated
+	case "pr:merged":
+		to.Action = vcs.ActionMerged
+	case "pr:declined", "pr:deleted":
+		to.Action = vcs.ActionDeleted
+	}
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return to, nil
+}
+
+func handleServerCommentEvent(b []byte) (*vcs.EventPayload, error) {
+	to, err := newServerPullRequestPayload(b)
+	if err != nil {
+		return nil, err
+	}
+	to.Type = vcs.EventTypeComment
+	to.Action = vcs.ActionCreated
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return to, nil
+}
+
+// newServerPullRequestPayload populates an OTF event from the details common
+// to pull request and comment events.
+func newServerPullRequestPayload(b []byte) (*vcs.EventPayload, error) {
+	var event serverPullRequestEvent
+	if err := json.Unmarshal(b, &event); err != nil {
+		return nil, err
+	}
+	pr := event.PullRequest
+	// the repository is that of the target branch; the source branch may
+	// belong to a fork.
+	bbrepo := pr.ToRef.Repository
+	repo, err := vcs.NewRepo(bbrepo.Project.Key, bbrepo.Slug)
+	if err != nil {
+		return nil, err
+	}
+	to := vcs.EventPayload{
+		Repo:              repo,
+		CommitSHA:         pr.FromRef.LatestCommit,
+		CommitURL:         bbrepo.commitURL(pr.FromRef.LatestCommit),
+		Branch:            pr.FromRef.DisplayID,
+		PullRequestNumber: pr.ID,
+		PullRequestURL:    pr.Links.href(),
+		PullRequestTitle:  pr.Title,
+		Comment:           event.Comment.Text,
+	}
+	setSender(&to, event.Actor.sender(bbrepo))
+	to.SenderID = event.Actor.Name
+	return &to, nil
+}
+
+// webURL derives the base URL of the Bitbucket website from the link to the
+// repository, which is either <base>/projects/<key>/repos/<slug>/browse, or
+// <base>/users/<user>/repos/<slug>/browse for personal repositories. An empty
+// string is returned if the repository has no link.
+func (r serverRepository) webURL() string {
+	href := r.Links.href()
+	for _, sep := range []string{"/projects/", "/users/"} {
+		if base, _, ok := strings.Cut(href, sep); ok {
+			return base
+		}
+	}
+	return ""
+}
+
+func (r serverRepository) commitURL(sha string) string {
+	base := r.webURL()
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/projects/%s/repos/%s/commits/%s", base, r.Project.Key, r.Slug, sha)
+}
+
+// sender returns the user that triggered an event. Event payloads don't
+// include links for users, so the link to the user's profile is derived from
+// the repository's link.
+func (u serverUser) sender(repo serverRepository) vcs.CommitAuthor {
+	author := vcs.CommitAuthor{Username: u.username()}
+	if base := repo.webURL(); base != "" && u.Slug != "" {
+		author.ProfileURL = base + "/users/" + u.Slug
+	}
+	return author
+}
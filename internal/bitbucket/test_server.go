@@ -0,0 +1,673 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// TestServer is a fake Bitbucket server, serving both the Bitbucket Cloud
+	// API (beneath /2.0) and the Bitbucket Data Center API (beneath
+	// /rest/api/1.0).
+	TestServer struct {
+		// webhook created/updated/deleted events channel
+		WebhookEvents chan webhookEvent
+		statuses      chan *buildStatus
+		// bodies of comments created/updated
+		comments chan string
+
+		*httptest.Server
+		*testdb
+		mux        *http.ServeMux
+		disableTLS bool
+	}
+
+	TestServerOption func(*TestServer)
+
+	testdb struct {
+		repo          *vcs.Repo
+		commit        *string
+		defaultBranch string
+		tarball       []byte
+		tags          []string
+		webhook       *hook
+
+		// pull request stub
+		pullNumber   string
+		pullFiles    []string
+		pullApproved bool
+
+		// identifiers of users with write access to the repo
+		writers []string
+
+		// url of server, only populated once server starts
+		url *string
+	}
+
+	hook struct {
+		ID     string
+		URL    string
+		Events []string
+		secret string
+	}
+
+	webhookEvent struct {
+		Action vcs.Action
+		Hook   *hook
+	}
+)
+
+const (
+	testHookCloudID  = "{2d8f1e3c-8b9a-4c2e-9f6b-0a1b2c3d4e5f}"
+	testHookServerID = "123"
+	testCommentID    = "456"
+)
+
+// newTestServerCloudClient creates a bitbucket server for testing purposes and
+// returns a Bitbucket Cloud client configured to access the server.
+func newTestServerCloudClient(t *testing.T, opts ...TestServerOption) (*CloudClient, *TestServer) {
+	s, u := NewTestServer(t, opts...)
+	return newCloudClient(*u, *u.JoinPath("2.0"), "token", true), s
+}
+
+// newTestServerServerClient creates a bitbucket server for testing purposes
+// and returns a Bitbucket Data Center client configured to access the server.
+func newTestServerServerClient(t *testing.T, opts ...TestServerOption) (*ServerClient, *TestServer) {
+	s, u := NewTestServer(t, opts...)
+	return newServerClient(*u, "token", true), s
+}
+
+func NewTestServer(t *testing.T, opts ...TestServerOption) (*TestServer, *url.URL) {
+	srv := TestServer{
+		testdb:        &testdb{},
+		WebhookEvents: make(chan webhookEvent, 999),
+		statuses:      make(chan *buildStatus, 999),
+		comments:      make(chan string, 999),
+		mux:           http.NewServeMux(),
+	}
+	for _, o := range opts {
+		o(&srv)
+	}
+
+	writeJSON := func(w http.ResponseWriter, status int, v any) {
+		out, err := json.Marshal(v)
+		require.NoError(t, err)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(out)
+	}
+
+	// handlePull registers a handler for a pull request endpoint, responding
+	// with 404 to requests for pull requests other than the stub.
+	handlePull := func(pattern string, h http.HandlerFunc) {
+		srv.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("pull") != srv.pullNumber {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			h(w, r)
+		})
+	}
+
+	if srv.repo != nil {
+		var (
+			cloudRepo  = "/2.0/repositories/" + srv.repo.String()
+			serverRepo = "/rest/api/1.0/projects/" + srv.repo.Owner() + "/repos/" + srv.repo.Name()
+		)
+
+		// Bitbucket Cloud endpoints
+
+		srv.mux.HandleFunc("GET /2.0/repositories", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"values": []map[string]any{{"full_name": srv.repo.String()}},
+			})
+		})
+
+		srv.mux.HandleFunc("GET "+cloudRepo, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"full_name":  srv.repo.String(),
+				"mainbranch": map[string]string{"name": srv.defaultBranch},
+			})
+		})
+
+		if srv.commit != nil {
+			srv.mux.HandleFunc("GET "+cloudRepo+"/commit/{ref}", func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, map[string]any{
+					"hash": *srv.commit,
+					"links": map[string]any{
+						"html": map[string]string{"href": *srv.url + "/" + srv.repo.String() + "/commits/" + *srv.commit},
+					},
+					"author": map[string]any{
+						"raw": "Bob <bob@example.com>",
+						"user": map[string]any{
+							"nickname": "bob",
+							"links": map[string]any{
+								"html":   map[string]string{"href": *srv.url + "/bob/"},
+								"avatar": map[string]string{"href": *srv.url + "/avatar/bob"},
+							},
+						},
+					},
+				})
+			})
+
+			srv.mux.HandleFunc("POST "+cloudRepo+"/commit/"+*srv.commit+"/statuses/build", srv.handleStatus)
+		}
+
+		if srv.tarball != nil && srv.commit != nil {
+			srv.mux.HandleFunc("GET /"+srv.repo.String()+"/get/"+*srv.commit+".tar.gz", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(srv.tarball)
+			})
+		}
+
+		srv.mux.HandleFunc("POST "+cloudRepo+"/hooks", func(w http.ResponseWriter, r *http.Request) {
+			var opts cloudWebhook
+			if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			srv.saveHook(vcs.ActionCreated, testHookCloudID, opts.URL, opts.Secret, opts.Events)
+			opts.UUID = testHookCloudID
+			writeJSON(w, http.StatusCreated, opts)
+		})
+
+		srv.mux.HandleFunc(cloudRepo+"/hooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if srv.webhook == nil || r.PathValue("id") != testHookCloudID {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			switch r.Method {
+			case "PUT":
+				var opts cloudWebhook
+				if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				srv.saveHook(vcs.ActionUpdated, testHookCloudID, opts.URL, opts.Secret, opts.Events)
+				fallthrough
+			case "GET":
+				writeJSON(w, http.StatusOK, cloudWebhook{
+					UUID:   srv.webhook.ID,
+					URL:    srv.webhook.URL,
+					Active: true,
+					Events: srv.webhook.Events,
+				})
+			case "DELETE":
+				srv.deleteHook()
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+
+		srv.mux.HandleFunc("GET "+cloudRepo+"/refs/tags", func(w http.ResponseWriter, r *http.Request) {
+			var tags []map[string]string
+			for _, tag := range srv.tags {
+				tags = append(tags, map[string]string{"name": tag})
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"values": tags})
+		})
+
+		cloudDiffStats := func(w http.ResponseWriter, r *http.Request) {
+			var stats []map[string]any
+			for _, f := range srv.pullFiles {
+				stats = append(stats, map[string]any{"new": map[string]string{"path": f}})
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"values": stats})
+		}
+		handlePull("GET "+cloudRepo+"/pullrequests/{pull}/diffstat", cloudDiffStats)
+		srv.mux.HandleFunc("GET "+cloudRepo+"/diffstat/{spec}", cloudDiffStats)
+
+		if srv.commit != nil {
+			handlePull("GET "+cloudRepo+"/pullrequests/{pull}", func(w http.ResponseWriter, r *http.Request) {
+				number, _ := strconv.Atoi(srv.pullNumber)
+				writeJSON(w, http.StatusOK, map[string]any{
+					"id":    number,
+					"title": "my pull request",
+					"source": map[string]any{
+						"branch":     map[string]string{"name": "dev"},
+						"commit":     map[string]string{"hash": (*srv.commit)[:12]},
+						"repository": map[string]string{"full_name": srv.repo.String()},
+					},
+					"destination": map[string]any{
+						"repository": map[string]string{"full_name": srv.repo.String()},
+					},
+					"participants": []map[string]any{
+						{"approved": srv.pullApproved},
+					},
+					"links": map[string]any{
+						"html": map[string]string{"href": *srv.url + "/" + srv.repo.String() + "/pull-requests/" + srv.pullNumber},
+					},
+				})
+			})
+		}
+
+		srv.mux.HandleFunc("GET /2.0/workspaces/"+srv.repo.Owner()+"/permissions/repositories/"+srv.repo.Name(), func(w http.ResponseWriter, r *http.Request) {
+			var perms []map[string]string
+			for _, writer := range srv.writers {
+				if r.URL.Query().Get("q") == fmt.Sprintf("user.uuid=%q", writer) {
+					perms = append(perms, map[string]string{"permission": "write"})
+				}
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"values": perms})
+		})
+
+		handlePull("POST "+cloudRepo+"/pullrequests/{pull}/comments", func(w http.ResponseWriter, r *http.Request) {
+			var comment cloudComment
+			if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			srv.comments <- comment.Content.Raw
+			comment.ID, _ = strconv.Atoi(testCommentID)
+			writeJSON(w, http.StatusCreated, comment)
+		})
+
+		handlePull("PUT "+cloudRepo+"/pullrequests/{pull}/comments/"+testCommentID, func(w http.ResponseWriter, r *http.Request) {
+			var comment cloudComment
+			if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			srv.comments <- comment.Content.Raw
+			writeJSON(w, http.StatusOK, comment)
+		})
+
+		// Bitbucket Data Center endpoints
+
+		srv.mux.HandleFunc("GET /rest/api/1.0/repos", func(w http.ResponseWriter, r *http.Request) {
+			var repo serverRepository
+			repo.Slug = srv.repo.Name()
+			repo.Project.Key = srv.repo.Owner()
+			writeJSON(w, http.StatusOK, serverPage[serverRepository]{
+				Values:     []serverRepository{repo},
+				IsLastPage: true,
+			})
+		})
+
+		srv.mux.HandleFunc("GET "+serverRepo+"/default-branch", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, serverRef{
+				ID:        "refs/heads/" + srv.defaultBranch,
+				DisplayID: srv.defaultBranch,
+			})
+		})
+
+		if srv.commit != nil {
+			srv.mux.HandleFunc("GET "+serverRepo+"/commits", func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, map[string]any{
+					"values": []map[string]any{{
+						"id": *srv.commit,
+						"author": map[string]any{
+							"name":      "bob",
+							"slug":      "bob",
+							"avatarUrl": "/users/bob/avatar.png?s=64",
+							"links": map[string]any{
+								"self": []map[string]string{{"href": *srv.url + "/users/bob"}},
+							},
+						},
+					}},
+					"isLastPage": true,
+				})
+			})
+
+			srv.mux.HandleFunc("POST "+serverRepo+"/commits/"+*srv.commit+"/builds", srv.handleStatus)
+		}
+
+		if srv.tarball != nil && srv.commit != nil {
+			srv.mux.HandleFunc("GET "+serverRepo+"/archive", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("at") != *srv.commit || r.URL.Query().Get("format") != "tar.gz" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write(srv.tarball)
+			})
+		}
+
+		srv.mux.HandleFunc("POST "+serverRepo+"/webhooks", func(w http.ResponseWriter, r *http.Request) {
+			var opts serverWebhook
+			if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			srv.saveHook(vcs.ActionCreated, testHookServerID, opts.URL, opts.Configuration.Secret, opts.Events)
+			opts.ID, _ = strconv.Atoi(testHookServerID)
+			writeJSON(w, http.StatusCreated, opts)
+		})
+
+		srv.mux.HandleFunc(serverRepo+"/webhooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if srv.webhook == nil || r.PathValue("id") != testHookServerID {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			switch r.Method {
+			case "PUT":
+				var opts serverWebhook
+				if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				srv.saveHook(vcs.ActionUpdated, testHookServerID, opts.URL, opts.Configuration.Secret, opts.Events)
+				fallthrough
+			case "GET":
+				id, _ := strconv.Atoi(srv.webhook.ID)
+				writeJSON(w, http.StatusOK, serverWebhook{
+					ID:     id,
+					URL:    srv.webhook.URL,
+					Active: true,
+					Events: srv.webhook.Events,
+				})
+			case "DELETE":
+				srv.deleteHook()
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+
+		srv.mux.HandleFunc("GET "+serverRepo+"/tags", func(w http.ResponseWriter, r *http.Request) {
+			var tags []serverRef
+			for _, tag := range srv.tags {
+				tags = append(tags, serverRef{ID: "refs/tags/" + tag, DisplayID: tag})
+			}
+			writeJSON(w, http.StatusOK, serverPage[serverRef]{Values: tags, IsLastPage: true})
+		})
+
+		serverChanges := func(w http.ResponseWriter, r *http.Request) {
+			var changes []serverChange
+			for _, f := range srv.pullFiles {
+				var change serverChange
+				change.Path.ToString = f
+				changes = append(changes, change)
+			}
+			writeJSON(w, http.StatusOK, serverPage[serverChange]{Values: changes, IsLastPage: true})
+		}
+		handlePull("GET "+serverRepo+"/pull-requests/{pull}/changes", serverChanges)
+		srv.mux.HandleFunc("GET "+serverRepo+"/compare/changes", serverChanges)
+
+		if srv.commit != nil {
+			handlePull("GET "+serverRepo+"/pull-requests/{pull}", func(w http.ResponseWriter, r *http.Request) {
+				number, _ := strconv.Atoi(srv.pullNumber)
+				pr := serverPullRequest{
+					ID:    number,
+					Title: "my pull request",
+					FromRef: serverRef{
+						ID:           "refs/heads/dev",
+						DisplayID:    "dev",
+						LatestCommit: *srv.commit,
+					},
+					Reviewers: []struct {
+						Approved bool `json:"approved"`
+					}{
+						{Approved: srv.pullApproved},
+					},
+				}
+				pr.FromRef.Repository.Slug = srv.repo.Name()
+				pr.FromRef.Repository.Project.Key = srv.repo.Owner()
+				pr.ToRef.Repository = pr.FromRef.Repository
+				pr.Links.Self = append(pr.Links.Self, struct {
+					Href string `json:"href"`
+				}{Href: *srv.url + "/projects/" + srv.repo.Owner() + "/repos/" + srv.repo.Name() + "/pull-requests/" + srv.pullNumber})
+				writeJSON(w, http.StatusOK, pr)
+			})
+		}
+
+		handlePull("GET "+serverRepo+"/pull-requests/{pull}/merge", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]bool{"conflicted": false})
+		})
+
+		srv.mux.HandleFunc("GET /rest/api/1.0/users", func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if query.Get("permission.1") != "REPO_WRITE" ||
+				query.Get("permission.1.projectKey") != srv.repo.Owner() ||
+				query.Get("permission.1.repositorySlug") != srv.repo.Name() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// mimic the API, which matches users whose username contains the
+			// filter
+			var users []serverUser
+			for _, writer := range srv.writers {
+				if strings.Contains(writer, query.Get("filter")) {
+					users = append(users, serverUser{Name: writer, Slug: writer})
+				}
+			}
+			writeJSON(w, http.StatusOK, serverPage[serverUser]{Values: users, IsLastPage: true})
+		})
+
+		handlePull("POST "+serverRepo+"/pull-requests/{pull}/comments", func(w http.ResponseWriter, r *http.Request) {
+			var comment serverComment
+			if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			srv.comments <- comment.Text
+			comment.ID, _ = strconv.Atoi(testCommentID)
+			writeJSON(w, http.StatusCreated, comment)
+		})
+
+		handlePull(serverRepo+"/pull-requests/{pull}/comments/"+testCommentID, func(w http.ResponseWriter, r *http.Request) {
+			id, _ := strconv.Atoi(testCommentID)
+			switch r.Method {
+			case "GET":
+				writeJSON(w, http.StatusOK, serverComment{ID: id, Version: 1, Text: "old"})
+			case "PUT":
+				var comment serverComment
+				if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				// the current version must be provided
+				if comment.Version != 1 {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				srv.comments <- comment.Text
+				writeJSON(w, http.StatusOK, comment)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+	}
+
+	if srv.disableTLS {
+		srv.Server = httptest.NewServer(srv.mux)
+	} else {
+		srv.Server = httptest.NewTLSServer(srv.mux)
+	}
+	t.Cleanup(srv.Close)
+	srv.url = &srv.URL
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return &srv, u
+}
+
+func (s *TestServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var status buildStatus
+	if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	s.statuses <- &status
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *TestServer) saveHook(action vcs.Action, id, url, secret string, events []string) {
+	// persist hook to the 'db'
+	s.webhook = &hook{
+		ID:     id,
+		URL:    url,
+		Events: events,
+		secret: secret,
+	}
+	// notify tests
+	s.WebhookEvents <- webhookEvent{
+		Action: action,
+		Hook:   s.webhook,
+	}
+}
+
+func (s *TestServer) deleteHook() {
+	// notify tests
+	s.WebhookEvents <- webhookEvent{
+		Action: vcs.ActionDeleted,
+		Hook:   s.webhook,
+	}
+	// delete hook from 'db'
+	s.webhook = nil
+}
+
+func WithRepo(repo vcs.Repo) TestServerOption {
+	return func(srv *TestServer) {
+		srv.repo = &repo
+	}
+}
+
+func WithCommit(commit string) TestServerOption {
+	return func(srv *TestServer) {
+		srv.commit = &commit
+	}
+}
+
+func WithDefaultBranch(branch string) TestServerOption {
+	return func(srv *TestServer) {
+		srv.defaultBranch = branch
+	}
+}
+
+func WithTags(tags ...string) TestServerOption {
+	return func(srv *TestServer) {
+		srv.tags = tags
+	}
+}
+
+func WithPullRequest(pullNumber string, changedPaths ...string) TestServerOption {
+	return func(srv *TestServer) {
+		srv.pullNumber = pullNumber
+		srv.pullFiles = changedPaths
+	}
+}
+
+// WithPullRequestApproved approves the pull request stub.
+func WithPullRequestApproved() TestServerOption {
+	return func(srv *TestServer) {
+		srv.pullApproved = true
+	}
+}
+
+// WithWriters grants write access to the repo to the users, identified by
+// their UUID on Bitbucket Cloud, or by their username on Bitbucket Data
+// Center.
+func WithWriters(users ...string) TestServerOption {
+	return func(srv *TestServer) {
+		srv.writers = users
+	}
+}
+
+func WithArchive(tarball []byte) TestServerOption {
+	return func(srv *TestServer) {
+		srv.tarball = tarball
+	}
+}
+
+func WithHandler(path string, h http.HandlerFunc) TestServerOption {
+	return func(srv *TestServer) {
+		srv.mux.HandleFunc(path, h)
+	}
+}
+
+func WithDisableTLS() TestServerOption {
+	return func(srv *TestServer) {
+		srv.disableTLS = true
+	}
+}
+
+func (s *TestServer) HasWebhook() bool {
+	return s.webhook != nil
+}
+
+// SendEvent sends an event to the registered webhook.
+func (s *TestServer) SendEvent(t *testing.T, event string, payload []byte) {
+	t.Helper()
+
+	require.True(t, s.HasWebhook())
+	SendEventRequest(t, event, s.webhook.URL, s.webhook.secret, payload)
+}
+
+// GetStatus retrieves a build status off the queue, timing out after 10
+// seconds if nothing is on the queue.
+func (s *TestServer) GetStatus(t *testing.T, ctx context.Context) *buildStatus {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	select {
+	case status := <-s.statuses:
+		return status
+	case <-ctx.Done():
+		t.Fatalf("bitbucket server: waiting to receive build status: %s", ctx.Err().Error())
+	}
+	return nil
+}
+
+// GetComment retrieves the body of a created or updated comment off the
+// queue, timing out after 10 seconds if nothing is on the queue.
+func (s *TestServer) GetComment(t *testing.T, ctx context.Context) string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	select {
+	case comment := <-s.comments:
+		return comment
+	case <-ctx.Done():
+		t.Fatalf("bitbucket server: waiting to receive comment: %s", ctx.Err().Error())
+	}
+	return ""
+}
+
+// SendEventRequest sends a bitbucket event via a http request to the url,
+// signed with the secret.
+func SendEventRequest(t *testing.T, event string, url, secret string, payload []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Add("Content-type", "application/json")
+	req.Header.Add(httpHeaderEvent, event)
+	req.Header.Add(httpHeaderSignature, signature(secret, payload))
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	if !assert.Equal(t, http.StatusOK, res.StatusCode) {
+		response, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		t.Fatal(string(response))
+	}
+}
+
+// signature generates the signature for a payload.
+func signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
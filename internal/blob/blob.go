@@ -0,0 +1,106 @@
+// Package blob provides storage of blobs, i.e. large binary objects such as
+// state files, configuration tarballs, plan files and logs, separately from
+// the relational data in the database.
+package blob
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/leg100/otf/internal/sql"
+)
+
+const (
+	// DBBackend stores blobs in the database. This is the default.
+	DBBackend = "db"
+	// FileBackend stores blobs on the local filesystem.
+	FileBackend = "file"
+	// S3Backend stores blobs in an S3-compatible object store.
+	S3Backend = "s3"
+)
+
+// Store is a store of blobs. Each blob is identified by a unique key, which is
+// a slash-delimited path, e.g. state/sv-123.
+type Store interface {
+	// Put stores a blob, overwriting any existing blob with the same key.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get retrieves a blob. If the blob does not exist then
+	// internal.ErrResourceNotFound is returned.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete deletes a blob. No error is returned if the blob does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// Config configures the blob store.
+type Config struct {
+	// Backend is one of db, file or s3.
+	Backend string
+	// Path is the directory in which blobs are stored by the file backend.
+	Path string
+	// S3 configures the s3 backend.
+	S3 S3Config
+}
+
+// New constructs a blob store according to the config.
+func New(ctx context.Context, cfg Config, db *sql.DB) (Store, error) {
+	switch cfg.Backend {
+	case DBBackend, "":
+		return &DBStore{DB: db}, nil
+	case FileBackend:
+		return NewFileStore(cfg.Path)
+	case S3Backend:
+		return NewS3Store(ctx, cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob store backend: %s", cfg.Backend)
+	}
+}
+
+// validateKey ensures a key is a relative path that does not escape its
+// parent directory, which is of particular importance to the file backend.
+func validateKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("blob key cannot be empty")
+	}
+	if strings.HasPrefix(key, "/") {
+		return fmt.Errorf("blob key cannot be an absolute path: %s", key)
+	}
+	for part := range strings.SplitSeq(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key: %s", key)
+		}
+	}
+	return nil
+}
+
+// StateKey is the key for a state version's state file.
+func StateKey(stateVersionID fmt.Stringer) string {
+	return "state/" + stateVersionID.String()
+}
+
+// ConfigKey is the key for a configuration version's tarball.
+func ConfigKey(configVersionID fmt.Stringer) string {
+	return "configs/" + configVersionID.String()
+}
+
+// PlanFileKey is the key for a run's plan file in the given format, either
+// bin or json.
+func PlanFileKey(runID fmt.Stringer, format string) string {
+	return fmt.Sprintf("runs/%s/plan.%s", runID, format)
+}
+
+// LogsKey is the key for the complete logs of a run phase.
+func LogsKey(runID fmt.Stringer, phase string) string {
+	return fmt.Sprintf("runs/%s/logs/%s", runID, phase)
+}
+
+// ModuleTarballKey is the key for a module version's tarball.
+func ModuleTarballKey(moduleVersionID fmt.Stringer) string {
+	return "modules/" + moduleVersionID.String() + ".tar.gz"
+}
+
+// EngineArchiveKey is the key for an engine archive hosted by the engine
+// mirror.
+func EngineArchiveKey(engine, version, filename string) string {
+	return fmt.Sprintf("engines/%s/%s/%s", engine, version, filename)
+}
@@ -0,0 +1,117 @@
+package blob
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	stores := map[string]func(t *testing.T) Store{
+		"file": func(t *testing.T) Store {
+			store, err := NewFileStore(t.TempDir())
+			require.NoError(t, err)
+			return store
+		},
+		// Run against MinIO, e.g.:
+		//
+		// docker run -p 9000:9000 minio/minio server /data
+		//
+		// and create a bucket, before setting the environment variables below.
+		"s3": func(t *testing.T) Store {
+			endpoint, ok := os.LookupEnv("OTF_TEST_S3_ENDPOINT")
+			if !ok {
+				t.Skip("OTF_TEST_S3_ENDPOINT needed for s3 blob store test")
+			}
+			store, err := NewS3Store(ctx, S3Config{
+				Endpoint:        endpoint,
+				Bucket:          os.Getenv("OTF_TEST_S3_BUCKET"),
+				Prefix:          t.Name(),
+				AccessKeyID:     os.Getenv("OTF_TEST_S3_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("OTF_TEST_S3_SECRET_ACCESS_KEY"),
+				Insecure:        true,
+			})
+			require.NoError(t, err)
+			return store
+		},
+	}
+	for name, fn := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := fn(t)
+
+			t.Run("put and get", func(t *testing.T) {
+				err := store.Put(ctx, "state/sv-1", []byte("state"))
+				require.NoError(t, err)
+
+				got, err := store.Get(ctx, "state/sv-1")
+				require.NoError(t, err)
+				assert.Equal(t, []byte("state"), got)
+			})
+
+			t.Run("overwrite", func(t *testing.T) {
+				err := store.Put(ctx, "runs/run-1/plan.bin", []byte("old"))
+				require.NoError(t, err)
+				err = store.Put(ctx, "runs/run-1/plan.bin", []byte("new"))
+				require.NoError(t, err)
+
+				got, err := store.Get(ctx, "runs/run-1/plan.bin")
+				require.NoError(t, err)
+				assert.Equal(t, []byte("new"), got)
+			})
+
+			t.Run("get missing", func(t *testing.T) {
+				_, err := store.Get(ctx, "state/does-not-exist")
+				assert.ErrorIs(t, err, internal.ErrResourceNotFound)
+			})
+
+			t.Run("delete", func(t *testing.T) {
+				err := store.Put(ctx, "configs/cv-1", []byte("config"))
+				require.NoError(t, err)
+
+				err = store.Delete(ctx, "configs/cv-1")
+				require.NoError(t, err)
+
+				_, err = store.Get(ctx, "configs/cv-1")
+				assert.ErrorIs(t, err, internal.ErrResourceNotFound)
+			})
+
+			t.Run("delete missing", func(t *testing.T) {
+				err := store.Delete(ctx, "configs/does-not-exist")
+				assert.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"valid", "state/sv-123", true},
+		{"nested", "runs/run-123/logs/plan", true},
+		{"empty", "", false},
+		{"absolute", "/etc/passwd", false},
+		{"parent directory", "state/../../etc/passwd", false},
+		{"current directory", "state/./sv-123", false},
+		{"empty part", "state//sv-123", false},
+		{"trailing slash", "state/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKey(tt.key)
+			if tt.want {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package blob
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/sql"
+)
+
+// DBStore stores blobs in the database.
+type DBStore struct {
+	*sql.DB
+}
+
+func (s *DBStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	_, err := s.Exec(ctx, `
+INSERT INTO blobs (
+    key,
+    data
+) VALUES (
+    $1,
+    $2
+) ON CONFLICT (key) DO UPDATE SET data = $2
+`, key, data)
+	return err
+}
+
+func (s *DBStore) Get(ctx context.Context, key string) ([]byte, error) {
+	rows := s.Query(ctx, `
+SELECT data
+FROM blobs
+WHERE key = $1
+`, key)
+	return sql.CollectOneType[[]byte](rows)
+}
+
+func (s *DBStore) Delete(ctx context.Context, key string) error {
+	_, err := s.Exec(ctx, `
+DELETE
+FROM blobs
+WHERE key = $1
+`, key)
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		return nil
+	}
+	return err
+}
+
+// keys lists the keys of all blobs in the database.
+func (s *DBStore) keys(ctx context.Context) ([]string, error) {
+	rows := s.Query(ctx, `
+SELECT key
+FROM blobs
+ORDER BY key
+`)
+	return sql.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,81 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/leg100/otf/internal"
+)
+
+// FileStore stores blobs on the local filesystem, with each blob written to a
+// file at a path relative to a root directory.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore constructs a file store that stores blobs in the given
+// directory, creating the directory if it does not exist.
+func NewFileStore(root string) (*FileStore, error) {
+	if root == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "blob-store-path"}
+	}
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, fmt.Errorf("creating blob store directory: %w", err)
+	}
+	return &FileStore{root: root}, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first and then rename it, to ensure readers
+	// never see a partially written blob.
+	f, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, internal.ErrResourceNotFound
+	}
+	return data, err
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
@@ -0,0 +1,17 @@
+package blob
+
+import "github.com/spf13/pflag"
+
+// RegisterFlags adds flags for configuring the blob store to the given flag
+// set.
+func RegisterFlags(flags *pflag.FlagSet, cfg *Config) {
+	flags.StringVar(&cfg.Backend, "blob-store", DBBackend, "Blob store backend: db, file or s3")
+	flags.StringVar(&cfg.Path, "blob-store-path", "", "Directory in which to store blobs when using the file backend")
+	flags.StringVar(&cfg.S3.Endpoint, "s3-endpoint", "", "Endpoint of the S3-compatible service when using the s3 backend")
+	flags.StringVar(&cfg.S3.Bucket, "s3-bucket", "", "Bucket in which to store blobs when using the s3 backend")
+	flags.StringVar(&cfg.S3.Prefix, "s3-prefix", "", "Prefix prepended to blob keys when using the s3 backend")
+	flags.StringVar(&cfg.S3.Region, "s3-region", "", "Region of the S3 bucket")
+	flags.StringVar(&cfg.S3.AccessKeyID, "s3-access-key-id", "", "S3 access key ID. If unset, credentials are sourced from the environment.")
+	flags.StringVar(&cfg.S3.SecretAccessKey, "s3-secret-access-key", "", "S3 secret access key")
+	flags.BoolVar(&cfg.S3.Insecure, "s3-insecure", false, "Connect to the S3-compatible service without TLS")
+}
@@ -0,0 +1,40 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/sql"
+)
+
+// Migrate moves blobs from the database to the target store. Each blob is
+// deleted from the database only once it has been written to the target, so
+// the migration can safely be interrupted and re-run.
+//
+// OTF should not be running while blobs are migrated, otherwise new blobs
+// written to the database would be left behind.
+func Migrate(ctx context.Context, logger logr.Logger, db *sql.DB, target Store) (int, error) {
+	if _, ok := target.(*DBStore); ok {
+		return 0, fmt.Errorf("cannot migrate blobs to the database")
+	}
+	source := &DBStore{DB: db}
+	keys, err := source.keys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing blobs: %w", err)
+	}
+	for i, key := range keys {
+		data, err := source.Get(ctx, key)
+		if err != nil {
+			return i, fmt.Errorf("retrieving blob %s: %w", key, err)
+		}
+		if err := target.Put(ctx, key, data); err != nil {
+			return i, fmt.Errorf("writing blob %s: %w", key, err)
+		}
+		if err := source.Delete(ctx, key); err != nil {
+			return i, fmt.Errorf("deleting blob %s from database: %w", key, err)
+		}
+		logger.V(1).Info("migrated blob", "key", key, "bytes", len(data))
+	}
+	return len(keys), nil
+}
@@ -0,0 +1,78 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/sql"
+)
+
+// By default check for orphaned blobs every minute
+var reaperDefaultCheckInterval = time.Minute
+
+// Reaper deletes blobs belonging to resources that have since been deleted.
+//
+// Deleting a resource, e.g. a state version, triggers the insertion of the keys
+// of its blobs into the blob_deletions table, whether the resource is deleted
+// directly or by cascade. The reaper periodically drains that table, deleting
+// each blob from the store.
+type Reaper struct {
+	logr.Logger
+	*sql.DB
+
+	Store                 Store
+	OverrideCheckInterval time.Duration
+}
+
+// Start the reaper daemon.
+func (r *Reaper) Start(ctx context.Context) error {
+	interval := reaperDefaultCheckInterval
+	if r.OverrideCheckInterval != 0 {
+		interval = r.OverrideCheckInterval
+	}
+
+	r.reap(ctx)
+
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reap(ctx)
+		}
+	}
+}
+
+func (r *Reaper) reap(ctx context.Context) {
+	rows := r.Query(ctx, `
+SELECT DISTINCT key
+FROM blob_deletions
+`)
+	keys, err := sql.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.Error(err, "retrieving blobs for deletion")
+		return
+	}
+	for _, key := range keys {
+		if err := r.Store.Delete(ctx, key); err != nil {
+			// Leave the key in place to retry on the next check.
+			r.Error(err, "deleting blob", "key", key)
+			continue
+		}
+		_, err := r.Exec(ctx, `
+DELETE
+FROM blob_deletions
+WHERE key = $1
+`, key)
+		if err != nil && !errors.Is(err, internal.ErrResourceNotFound) {
+			r.Error(err, "removing blob deletion", "key", key)
+			continue
+		}
+		r.V(9).Info("deleted blob", "key", key)
+	}
+}
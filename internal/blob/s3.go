@@ -0,0 +1,128 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/leg100/otf/internal"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config configures the S3 blob store.
+type S3Config struct {
+	// Endpoint is the host, and optionally the port, of the S3-compatible
+	// service, e.g. s3.amazonaws.com or localhost:9000.
+	Endpoint string
+	// Bucket is the name of the bucket in which to store blobs. It must
+	// already exist.
+	Bucket string
+	// Prefix is prepended to every blob key, permitting the bucket to be
+	// shared with other applications.
+	Prefix string
+	Region string
+	// AccessKeyID and SecretAccessKey are static credentials. If unset then
+	// credentials are sourced from the environment, the AWS credentials file
+	// or the EC2 instance metadata service, in that order.
+	AccessKeyID     string
+	SecretAccessKey string
+	// Insecure disables TLS, which is only advisable for testing, e.g.
+	// against a local MinIO server.
+	Insecure bool
+}
+
+// S3Store stores blobs in an S3-compatible object store.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store constructs an S3 store, checking the bucket exists.
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "s3-endpoint"}
+	}
+	if cfg.Bucket == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "s3-bucket"}
+	}
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{},
+	})
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !cfg.Insecure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("constructing s3 client: %w", err)
+	}
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("checking s3 bucket exists: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("s3 bucket does not exist: %s", cfg.Bucket)
+	}
+	return &S3Store{
+		client: client,
+		bucket: cfg.Bucket,
+		prefix: cfg.Prefix,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, s.objectName(key), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	return err
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.convertError(err)
+	}
+	defer obj.Close()
+	// Errors such as a missing object are only returned upon reading the
+	// object.
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, s.convertError(err)
+	}
+	return data, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	// S3 does not return an error when deleting an object that does not
+	// exist.
+	return s.client.RemoveObject(ctx, s.bucket, s.objectName(key), minio.RemoveObjectOptions{})
+}
+
+func (s *S3Store) objectName(key string) string {
+	return path.Join(s.prefix, key)
+}
+
+func (s *S3Store) convertError(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return internal.ErrResourceNotFound
+	}
+	return err
+}
@@ -8,8 +8,10 @@ import (
 
 	cmdutil "github.com/leg100/otf/cmd"
 	"github.com/leg100/otf/internal"
+	enginecli "github.com/leg100/otf/internal/engine/cli"
 	otfhttp "github.com/leg100/otf/internal/http"
 	organizationcli "github.com/leg100/otf/internal/organization/cli"
+	registryprovidercli "github.com/leg100/otf/internal/registryprovider/cli"
 	runcli "github.com/leg100/otf/internal/run/cli"
 	runnercli "github.com/leg100/otf/internal/runner/cli"
 	statecli "github.com/leg100/otf/internal/state/cli"
@@ -62,6 +64,8 @@ func (a *CLI) Run(ctx context.Context, args []string, out io.Writer) error {
 	cmd.AddCommand(runcli.NewCommand(a.client))
 	cmd.AddCommand(statecli.NewCommand(a.client))
 	cmd.AddCommand(runnercli.NewAgentsCommand(a.client))
+	cmd.AddCommand(registryprovidercli.NewCommand(a.client))
+	cmd.AddCommand(enginecli.NewCommand(a.client))
 
 	if err := cmdutil.SetFlagsFromEnvVariables(cmd.Flags()); err != nil {
 		return errors.Wrap(err, "failed to populate config from environment vars")
@@ -3,6 +3,7 @@ package client
 
 import (
 	configversionapi "github.com/leg100/otf/internal/configversion/api"
+	engineapi "github.com/leg100/otf/internal/engine/api"
 	otfhttp "github.com/leg100/otf/internal/http"
 	"github.com/leg100/otf/internal/logr"
 	organizationapi "github.com/leg100/otf/internal/organization/api"
@@ -30,6 +31,7 @@ type (
 		*variableapi.VariableClient
 		*runnerapi.RunnerClient
 		*sshkeyapi.SSHKeyClient
+		*engineapi.EngineClient
 	}
 )
 
@@ -70,5 +72,6 @@ func (c *Client) new(httpClient *otfhttp.Client) *Client {
 		VariableClient:     &variableapi.Client{Client: httpClient},
 		RunnerClient:       &runnerapi.Client{Client: httpClient},
 		SSHKeyClient:       &sshkeyapi.Client{Client: httpClient},
+		EngineClient:       &engineapi.Client{Client: httpClient},
 	}
 }
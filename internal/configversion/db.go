@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
@@ -14,6 +15,8 @@ import (
 
 type pgdb struct {
 	*sql.DB // provides access to generated SQL queries
+
+	blobs blob.Store
 }
 
 func (db *pgdb) CreateConfigurationVersion(ctx context.Context, cv *ConfigurationVersion) error {
@@ -113,16 +116,14 @@ INSERT INTO ingress_attributes (
 }
 
 func (db *pgdb) UploadConfigurationVersion(ctx context.Context, id resource.TfeID, config []byte) error {
+	if err := db.blobs.Put(ctx, blob.ConfigKey(id), config); err != nil {
+		return err
+	}
 	_, err := db.Exec(ctx, `
 UPDATE configuration_versions
-SET
-    config = @config,
-    status = 'uploaded'
-WHERE configuration_version_id = @id
-`, pgx.NamedArgs{
-		"config": config,
-		"id":     id,
-	})
+SET status = 'uploaded'
+WHERE configuration_version_id = $1
+`, id)
 	return err
 }
 
@@ -248,13 +249,17 @@ ORDER BY cv.created_at DESC
 }
 
 func (db *pgdb) GetConfig(ctx context.Context, id resource.TfeID) ([]byte, error) {
+	// Only retrieve config once it has been fully uploaded.
 	row := db.Query(ctx, `
-SELECT config
+SELECT configuration_version_id
 FROM configuration_versions
 WHERE configuration_version_id = $1
 AND   status                   = 'uploaded'
 `, id)
-	return sql.CollectOneType[[]byte](row)
+	if _, err := sql.CollectOneType[resource.TfeID](row); err != nil {
+		return nil, err
+	}
+	return db.blobs.Get(ctx, blob.ConfigKey(id))
 }
 
 func (db *pgdb) DeleteConfigurationVersion(ctx context.Context, id resource.TfeID) error {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
@@ -28,6 +29,7 @@ type (
 		Logger     logr.Logger
 		Authorizer *authz.Authorizer
 		DB         *sql.DB
+		BlobStore  blob.Store
 	}
 )
 
@@ -37,7 +39,7 @@ func NewService(opts Options) *Service {
 		Authorizer: opts.Authorizer,
 		IconDB:     source.NewIconDB(),
 	}
-	svc.db = &pgdb{opts.DB}
+	svc.db = &pgdb{opts.DB, opts.BlobStore}
 
 	// Provide a means of looking up a config version's parent workspace.
 	opts.Authorizer.RegisterParentResolver(resource.ConfigVersionKind,
@@ -12,6 +12,8 @@ var (
 	UI        Source = "tfe-ui"
 	Terraform Source = "terraform+cloud"
 	Trigger   Source = "tfe-run-trigger"
+	// Assessment is the source of runs created by health assessments.
+	Assessment Source = "otf-assessment"
 )
 
 // Source is the source or origin of the configuration
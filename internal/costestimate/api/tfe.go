@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/costestimate"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tfeapi"
+	"github.com/leg100/otf/internal/tfeapi/types"
+)
+
+type TFEAPI struct {
+	*tfeapi.Responder
+	Client tfeClient
+}
+
+type tfeClient interface {
+	GetCostEstimate(ctx context.Context, id resource.TfeID) (*costestimate.CostEstimate, error)
+}
+
+func (a *TFEAPI) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/cost-estimates/{cost_estimate_id}", a.getCostEstimate).Methods("GET")
+}
+
+func (a *TFEAPI) getCostEstimate(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("cost_estimate_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	estimate, err := a.Client.GetCostEstimate(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, toCostEstimate(estimate), http.StatusOK)
+}
+
+func toCostEstimate(from *costestimate.CostEstimate) *types.CostEstimate {
+	to := &types.CostEstimate{
+		ID:                      from.ID,
+		DeltaMonthlyCost:        costestimate.FormatCost(from.DeltaMonthlyCost()),
+		MatchedResourcesCount:   from.MatchedResourcesCount(),
+		PriorMonthlyCost:        costestimate.FormatCost(from.PriorMonthlyCost),
+		ProposedMonthlyCost:     costestimate.FormatCost(from.ProposedMonthlyCost),
+		ResourcesCount:          len(from.Resources),
+		Status:                  types.CostEstimateStatus(from.Status),
+		UnmatchedResourcesCount: from.UnmatchedResourcesCount(),
+		StatusTimestamps: &types.CostEstimateStatusTimestamps{
+			QueuedAt: from.CreatedAt,
+		},
+	}
+	if from.ErrorMessage != nil {
+		to.ErrorMessage = *from.ErrorMessage
+	}
+	switch from.Status {
+	case costestimate.EstimateFinished:
+		to.StatusTimestamps.FinishedAt = from.UpdatedAt
+	case costestimate.EstimateErrored:
+		to.StatusTimestamps.ErroredAt = from.UpdatedAt
+	}
+	return to
+}
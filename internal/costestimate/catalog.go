@@ -0,0 +1,267 @@
+package costestimate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HoursPerMonth is the number of hours in a month, used to convert hourly
+// prices into monthly prices.
+const HoursPerMonth = 730
+
+// csvColumns are the columns of a CSV price sheet.
+var csvColumns = []string{"resource_type", "match", "hourly_cost", "monthly_cost", "quantity"}
+
+type (
+	// Catalog prices resources.
+	Catalog interface {
+		// MonthlyCost returns the monthly cost of a resource of the given type
+		// with the given attributes. False is returned if the catalog does not
+		// have a price for the resource.
+		MonthlyCost(resourceType string, attributes map[string]any) (float64, bool)
+	}
+
+	// PriceSheet is a catalog of prices, typically loaded from a file.
+	PriceSheet struct {
+		// prices keyed by resource type
+		prices map[string][]Price
+	}
+
+	// Price is the price of a type of resource, e.g. aws_instance.
+	Price struct {
+		ResourceType string `json:"resource_type"`
+		// Match restricts the price to resources with the given attribute
+		// values, e.g. instance_type=t3.micro. Nested attributes are
+		// referenced using dots, e.g. root_block_device.0.volume_type. If a
+		// resource matches more than one price then the price with the most
+		// matching attributes is used.
+		Match map[string]string `json:"match,omitempty"`
+		// HourlyCost is the cost per hour. Mutually exclusive with
+		// MonthlyCost.
+		HourlyCost float64 `json:"hourly_cost,omitempty"`
+		// MonthlyCost is the cost per month. Mutually exclusive with
+		// HourlyCost.
+		MonthlyCost float64 `json:"monthly_cost,omitempty"`
+		// Quantity optionally names a numeric attribute by which the cost is
+		// multiplied, e.g. the size in GB of a storage volume.
+		Quantity string `json:"quantity,omitempty"`
+	}
+)
+
+// NewPriceSheet constructs a price sheet from a list of prices.
+func NewPriceSheet(prices []Price) (*PriceSheet, error) {
+	sheet := &PriceSheet{prices: make(map[string][]Price)}
+	for i, p := range prices {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("price %d: %w", i+1, err)
+		}
+		sheet.prices[p.ResourceType] = append(sheet.prices[p.ResourceType], p)
+	}
+	// Sort each resource type's prices so that the most specific price is
+	// matched first.
+	for _, prices := range sheet.prices {
+		slices.SortStableFunc(prices, func(a, b Price) int {
+			return len(b.Match) - len(a.Match)
+		})
+	}
+	return sheet, nil
+}
+
+// LoadPriceSheet loads a price sheet from a JSON or CSV file, the format
+// determined by the file extension.
+//
+// A JSON file contains a list of prices:
+//
+//	[
+//	  {"resource_type": "aws_instance", "match": {"instance_type": "t3.micro"}, "hourly_cost": 0.0104},
+//	  {"resource_type": "aws_ebs_volume", "match": {"type": "gp3"}, "monthly_cost": 0.08, "quantity": "size"}
+//	]
+//
+// A CSV file has a header row followed by a row for each price. Match
+// conditions are separated by semi-colons:
+//
+//	resource_type,match,hourly_cost,monthly_cost,quantity
+//	aws_instance,instance_type=t3.micro,0.0104,,
+//	aws_ebs_volume,type=gp3,,0.08,size
+func LoadPriceSheet(path string) (*PriceSheet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var prices []Price
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&prices); err != nil {
+			return nil, fmt.Errorf("decoding price sheet: %w", err)
+		}
+	case ".csv":
+		prices, err = readCSV(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading price sheet: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported price sheet file extension: %s", ext)
+	}
+	return NewPriceSheet(prices)
+}
+
+func readCSV(r io.Reader) ([]Price, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(csvColumns, name) {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["resource_type"]; !ok {
+		return nil, errors.New("missing column: resource_type")
+	}
+	var prices []Price
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		p := Price{
+			ResourceType: field("resource_type"),
+			Quantity:     field("quantity"),
+		}
+		if match := field("match"); match != "" {
+			p.Match = make(map[string]string)
+			for cond := range strings.SplitSeq(match, ";") {
+				k, v, ok := strings.Cut(cond, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid match: %s", line, cond)
+				}
+				p.Match[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+		for name, dst := range map[string]*float64{
+			"hourly_cost":  &p.HourlyCost,
+			"monthly_cost": &p.MonthlyCost,
+		} {
+			if v := field(name); v != "" {
+				if *dst, err = strconv.ParseFloat(v, 64); err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s: %w", line, name, err)
+				}
+			}
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
+
+// MonthlyCost implements Catalog.
+func (s *PriceSheet) MonthlyCost(resourceType string, attributes map[string]any) (float64, bool) {
+	for _, p := range s.prices[resourceType] {
+		if cost, ok := p.monthlyCost(attributes); ok {
+			return cost, true
+		}
+	}
+	return 0, false
+}
+
+func (p Price) validate() error {
+	if p.ResourceType == "" {
+		return errors.New("missing resource type")
+	}
+	if p.HourlyCost < 0 || p.MonthlyCost < 0 {
+		return errors.New("cost cannot be negative")
+	}
+	if p.HourlyCost > 0 && p.MonthlyCost > 0 {
+		return errors.New("cannot specify both an hourly and a monthly cost")
+	}
+	return nil
+}
+
+// monthlyCost returns the monthly cost of a resource with the given
+// attributes. False is returned if the resource does not match the price.
+func (p Price) monthlyCost(attributes map[string]any) (float64, bool) {
+	for k, want := range p.Match {
+		got, ok := lookup(attributes, k)
+		if !ok || format(got) != want {
+			return 0, false
+		}
+	}
+	cost := p.MonthlyCost
+	if p.HourlyCost > 0 {
+		cost = p.HourlyCost * HoursPerMonth
+	}
+	if p.Quantity != "" {
+		v, ok := lookup(attributes, p.Quantity)
+		if !ok {
+			return 0, false
+		}
+		quantity, ok := v.(float64)
+		if !ok {
+			return 0, false
+		}
+		cost *= quantity
+	}
+	return cost, true
+}
+
+// lookup retrieves an attribute value, where nested attributes are referenced
+// using dots, e.g. root_block_device.0.volume_size.
+func lookup(attributes map[string]any, key string) (any, bool) {
+	var v any = attributes
+	for part := range strings.SplitSeq(key, ".") {
+		switch current := v.(type) {
+		case map[string]any:
+			next, ok := current[part]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(current) {
+				return nil, false
+			}
+			v = current[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+// format formats a JSON attribute value for comparison with a match value.
+func format(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
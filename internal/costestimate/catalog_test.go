@@ -0,0 +1,63 @@
+package costestimate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPriceSheet(t *testing.T) {
+	for _, path := range []string{"./testdata/prices.json", "./testdata/prices.csv"} {
+		t.Run(path, func(t *testing.T) {
+			sheet, err := LoadPriceSheet(path)
+			require.NoError(t, err)
+
+			tests := []struct {
+				name         string
+				resourceType string
+				attributes   map[string]any
+				want         float64
+				wantMatch    bool
+			}{
+				{"most specific price", "aws_instance", map[string]any{"instance_type": "t3.micro"}, 0.0104 * HoursPerMonth, true},
+				{"fallback price", "aws_instance", map[string]any{"instance_type": "m5.large"}, 0.1 * HoursPerMonth, true},
+				{"quantity", "aws_ebs_volume", map[string]any{"type": "gp3", "size": float64(100)}, 8, true},
+				{"missing quantity", "aws_ebs_volume", map[string]any{"type": "gp3"}, 0, false},
+				{"unmatched attribute", "aws_ebs_volume", map[string]any{"type": "io2", "size": float64(100)}, 0, false},
+				{"free", "aws_vpc", map[string]any{}, 0, true},
+				{"unknown resource type", "aws_s3_bucket", map[string]any{}, 0, false},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, ok := sheet.MonthlyCost(tt.resourceType, tt.attributes)
+					assert.Equal(t, tt.wantMatch, ok)
+					assert.InDelta(t, tt.want, got, 0.0001)
+				})
+			}
+		})
+	}
+}
+
+func TestNewPriceSheet_Invalid(t *testing.T) {
+	_, err := NewPriceSheet([]Price{{HourlyCost: 1}})
+	assert.Error(t, err)
+
+	_, err = NewPriceSheet([]Price{{ResourceType: "aws_instance", HourlyCost: 1, MonthlyCost: 1}})
+	assert.Error(t, err)
+}
+
+func TestLookup(t *testing.T) {
+	attributes := map[string]any{
+		"root_block_device": []any{
+			map[string]any{"volume_type": "gp3"},
+		},
+	}
+
+	got, ok := lookup(attributes, "root_block_device.0.volume_type")
+	require.True(t, ok)
+	assert.Equal(t, "gp3", got)
+
+	_, ok = lookup(attributes, "root_block_device.1.volume_type")
+	assert.False(t, ok)
+}
@@ -0,0 +1,173 @@
+// Package costestimate estimates the monthly costs of the resources in a run's
+// plan.
+package costestimate
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/resource"
+)
+
+const (
+	// EstimateFinished indicates the costs have been estimated.
+	EstimateFinished Status = "finished"
+	// EstimateErrored indicates the costs could not be estimated.
+	EstimateErrored Status = "errored"
+)
+
+type (
+	// Status is the status of a cost estimate.
+	Status string
+
+	// CostEstimate is an estimate of the monthly cost of a run's resources,
+	// both before and after the run's proposed changes. There is at most one
+	// estimate per run, and its ID is derived from the run ID.
+	CostEstimate struct {
+		ID           resource.TfeID `db:"cost_estimate_id"`
+		CreatedAt    time.Time      `db:"created_at"`
+		UpdatedAt    time.Time      `db:"updated_at"`
+		RunID        resource.TfeID `db:"run_id"`
+		Status       Status         `db:"status"`
+		ErrorMessage *string        `db:"error_message"`
+		// PriorMonthlyCost is the monthly cost of the resources before the
+		// run's changes.
+		PriorMonthlyCost float64 `db:"prior_monthly_cost"`
+		// ProposedMonthlyCost is the monthly cost of the resources after the
+		// run's changes.
+		ProposedMonthlyCost float64 `db:"proposed_monthly_cost"`
+		// Resources are the estimates for the individual resources in the
+		// plan.
+		Resources []ResourceEstimate `db:"resources"`
+	}
+
+	// ResourceEstimate is an estimate of the monthly cost of an individual
+	// resource.
+	ResourceEstimate struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		// Matched is true if the resource was found in the price catalog.
+		Matched             bool    `json:"matched"`
+		PriorMonthlyCost    float64 `json:"prior_monthly_cost"`
+		ProposedMonthlyCost float64 `json:"proposed_monthly_cost"`
+	}
+
+	// planFile is the subset of the schema of a JSON plan file needed to
+	// estimate costs.
+	planFile struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+			Mode    string `json:"mode"`
+			Type    string `json:"type"`
+			Change  struct {
+				Before map[string]any `json:"before"`
+				After  map[string]any `json:"after"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+)
+
+// ID returns the ID of the cost estimate for the given run.
+func ID(runID resource.TfeID) resource.TfeID {
+	return resource.ConvertTfeID(runID, resource.CostEstimateKind)
+}
+
+func newCostEstimate(runID resource.TfeID) *CostEstimate {
+	now := internal.CurrentTimestamp(nil)
+	return &CostEstimate{
+		ID:        ID(runID),
+		CreatedAt: now,
+		UpdatedAt: now,
+		RunID:     runID,
+		Resources: []ResourceEstimate{},
+	}
+}
+
+// estimate prices the managed resources in the JSON plan file, both before
+// and after their proposed changes.
+func (e *CostEstimate) estimate(plan []byte, catalog Catalog) error {
+	var pf planFile
+	if err := json.Unmarshal(plan, &pf); err != nil {
+		return fmt.Errorf("decoding plan: %w", err)
+	}
+	for _, rc := range pf.ResourceChanges {
+		if rc.Mode != "managed" {
+			// skip data sources
+			continue
+		}
+		est := ResourceEstimate{Address: rc.Address, Type: rc.Type}
+		// A resource that is to be created has no prior state, and a resource
+		// that is to be destroyed has no proposed state.
+		if rc.Change.Before != nil {
+			if cost, ok := catalog.MonthlyCost(rc.Type, rc.Change.Before); ok {
+				est.PriorMonthlyCost = cost
+				est.Matched = true
+			}
+		}
+		if rc.Change.After != nil {
+			if cost, ok := catalog.MonthlyCost(rc.Type, rc.Change.After); ok {
+				est.ProposedMonthlyCost = cost
+				est.Matched = true
+			}
+		}
+		e.PriorMonthlyCost += est.PriorMonthlyCost
+		e.ProposedMonthlyCost += est.ProposedMonthlyCost
+		e.Resources = append(e.Resources, est)
+	}
+	return nil
+}
+
+// finish sets the status of the estimate. If err is non-nil then the estimate
+// is marked as errored.
+func (e *CostEstimate) finish(err error) {
+	if err != nil {
+		e.Status = EstimateErrored
+		e.ErrorMessage = new(err.Error())
+	} else {
+		e.Status = EstimateFinished
+	}
+	e.UpdatedAt = internal.CurrentTimestamp(nil)
+}
+
+// DeltaMonthlyCost is the change in monthly cost proposed by the run.
+func (e *CostEstimate) DeltaMonthlyCost() float64 {
+	return e.ProposedMonthlyCost - e.PriorMonthlyCost
+}
+
+// MatchedResourcesCount is the number of resources found in the price
+// catalog.
+func (e *CostEstimate) MatchedResourcesCount() (n int) {
+	for _, r := range e.Resources {
+		if r.Matched {
+			n++
+		}
+	}
+	return
+}
+
+// UnmatchedResourcesCount is the number of resources not found in the price
+// catalog.
+func (e *CostEstimate) UnmatchedResourcesCount() int {
+	return len(e.Resources) - e.MatchedResourcesCount()
+}
+
+// LogValue implements slog.LogValuer.
+func (e *CostEstimate) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", e.ID.String()),
+		slog.String("run_id", e.RunID.String()),
+		slog.String("status", string(e.Status)),
+		slog.String("prior_monthly_cost", FormatCost(e.PriorMonthlyCost)),
+		slog.String("proposed_monthly_cost", FormatCost(e.ProposedMonthlyCost)),
+		slog.Int("matched", e.MatchedResourcesCount()),
+		slog.Int("unmatched", e.UnmatchedResourcesCount()),
+	)
+}
+
+// FormatCost formats a cost to the nearest cent.
+func FormatCost(cost float64) string {
+	return fmt.Sprintf("%.2f", cost)
+}
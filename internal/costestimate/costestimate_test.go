@@ -0,0 +1,43 @@
+package costestimate
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/leg100/otf/internal/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostEstimate_Estimate(t *testing.T) {
+	plan, err := os.ReadFile("./testdata/plan.json")
+	require.NoError(t, err)
+	catalog, err := LoadPriceSheet("./testdata/prices.json")
+	require.NoError(t, err)
+
+	estimate := newCostEstimate(resource.NewTfeID(resource.RunKind))
+	err = estimate.estimate(plan, catalog)
+	require.NoError(t, err)
+	estimate.finish(nil)
+
+	assert.Equal(t, EstimateFinished, estimate.Status)
+	// data sources are skipped
+	assert.Len(t, estimate.Resources, 4)
+	assert.Equal(t, 3, estimate.MatchedResourcesCount())
+	assert.Equal(t, 1, estimate.UnmatchedResourcesCount())
+	// t3.micro instance
+	assert.Equal(t, "7.59", FormatCost(estimate.PriorMonthlyCost))
+	// m5.large instance plus 100GB volume
+	assert.Equal(t, "81.00", FormatCost(estimate.ProposedMonthlyCost))
+	assert.Equal(t, "73.41", FormatCost(estimate.DeltaMonthlyCost()))
+}
+
+func TestCostEstimate_Errored(t *testing.T) {
+	estimate := newCostEstimate(resource.NewTfeID(resource.RunKind))
+	estimate.finish(errors.New("plan not found"))
+
+	assert.Equal(t, EstimateErrored, estimate.Status)
+	require.NotNil(t, estimate.ErrorMessage)
+	assert.Equal(t, "plan not found", *estimate.ErrorMessage)
+}
@@ -0,0 +1,79 @@
+package costestimate
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type pgdb struct {
+	*sql.DB
+}
+
+// upsert persists a cost estimate, replacing any existing estimate for the
+// same run.
+func (db *pgdb) upsert(ctx context.Context, estimate *CostEstimate) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO cost_estimates (
+    cost_estimate_id,
+    created_at,
+    updated_at,
+    status,
+    error_message,
+    prior_monthly_cost,
+    proposed_monthly_cost,
+    resources,
+    run_id
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @status,
+    @error_message,
+    @prior_monthly_cost,
+    @proposed_monthly_cost,
+    @resources,
+    @run_id
+)
+ON CONFLICT (cost_estimate_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    status = EXCLUDED.status,
+    error_message = EXCLUDED.error_message,
+    prior_monthly_cost = EXCLUDED.prior_monthly_cost,
+    proposed_monthly_cost = EXCLUDED.proposed_monthly_cost,
+    resources = EXCLUDED.resources
+`,
+		pgx.NamedArgs{
+			"id":                    estimate.ID,
+			"created_at":            estimate.CreatedAt,
+			"updated_at":            estimate.UpdatedAt,
+			"status":                estimate.Status,
+			"error_message":         estimate.ErrorMessage,
+			"prior_monthly_cost":    estimate.PriorMonthlyCost,
+			"proposed_monthly_cost": estimate.ProposedMonthlyCost,
+			"resources":             estimate.Resources,
+			"run_id":                estimate.RunID,
+		},
+	)
+	return err
+}
+
+func (db *pgdb) get(ctx context.Context, id resource.TfeID) (*CostEstimate, error) {
+	rows := db.Query(ctx, `
+SELECT
+    cost_estimate_id,
+    created_at,
+    updated_at,
+    run_id,
+    status,
+    error_message,
+    prior_monthly_cost,
+    proposed_monthly_cost,
+    resources
+FROM cost_estimates
+WHERE cost_estimate_id = $1
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[CostEstimate])
+}
@@ -0,0 +1,39 @@
+package costestimate
+
+import (
+	"context"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/runstatus"
+)
+
+// Estimator estimates the costs of runs that have entered the cost estimating
+// state.
+type Estimator struct {
+	logr.Logger
+
+	Runs      estimatorRunClient
+	Estimates *Service
+}
+
+type estimatorRunClient interface {
+	ListRuns(ctx context.Context, opts run.ListOptions) (*resource.Page[*run.Run], error)
+	WatchRuns(context.Context) (<-chan pubsub.Event[*run.Event], func(), error)
+}
+
+// Start the estimator.
+func (e *Estimator) Start(ctx context.Context) error {
+	watcher := &run.StatusWatcher{
+		Logger:   e.Logger,
+		Runs:     e.Runs,
+		Statuses: []runstatus.Status{runstatus.CostEstimating},
+		Handler: func(ctx context.Context, runID resource.TfeID) error {
+			_, err := e.Estimates.estimateRun(ctx, runID)
+			return err
+		},
+	}
+	return watcher.Start(ctx)
+}
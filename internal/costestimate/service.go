@@ -0,0 +1,109 @@
+package costestimate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type (
+	// Alias service to permit embedding it with other services in a struct
+	// without a name clash.
+	CostEstimateService = Service
+
+	Service struct {
+		logr.Logger
+		*authz.Authorizer
+
+		db      *pgdb
+		runs    runClient
+		catalog Catalog
+	}
+
+	Options struct {
+		DB         *sql.DB
+		Logger     logr.Logger
+		Authorizer *authz.Authorizer
+		RunClient  runClient
+		// Catalog prices resources. If nil then no resources are priced.
+		Catalog Catalog
+	}
+
+	runClient interface {
+		GetRunPlanFile(ctx context.Context, id resource.TfeID, format run.PlanFormat) ([]byte, error)
+		FinishCostEstimate(ctx context.Context, id resource.TfeID) (*run.Run, error)
+	}
+)
+
+func NewService(opts Options) *Service {
+	svc := &Service{
+		Logger:     opts.Logger,
+		Authorizer: opts.Authorizer,
+		db:         &pgdb{opts.DB},
+		runs:       opts.RunClient,
+		catalog:    opts.Catalog,
+	}
+	if svc.catalog == nil {
+		svc.catalog = &PriceSheet{}
+	}
+	// Register parent resolver so the authorizer can resolve cost estimate ->
+	// run.
+	opts.Authorizer.RegisterParentResolver(resource.CostEstimateKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			return resource.ConvertTfeID(id.(resource.TfeID), resource.RunKind), nil
+		},
+	)
+	return svc
+}
+
+// GetCostEstimate retrieves a cost estimate.
+func (s *Service) GetCostEstimate(ctx context.Context, id resource.TfeID) (*CostEstimate, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.CostEstimateKind, id)
+	if err != nil {
+		return nil, err
+	}
+	estimate, err := s.db.get(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving cost estimate", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved cost estimate", "estimate", estimate, "subject", subject)
+	return estimate, nil
+}
+
+// GetRunCostEstimate retrieves the cost estimate for a run.
+func (s *Service) GetRunCostEstimate(ctx context.Context, runID resource.TfeID) (*CostEstimate, error) {
+	return s.GetCostEstimate(ctx, ID(runID))
+}
+
+// estimateRun estimates the costs of a run's plan, persists the estimate, and
+// then updates the run accordingly. An errored estimate does not prevent the
+// run from proceeding.
+func (s *Service) estimateRun(ctx context.Context, runID resource.TfeID) (*CostEstimate, error) {
+	estimate := newCostEstimate(runID)
+	estimate.finish(func() error {
+		plan, err := s.runs.GetRunPlanFile(ctx, runID, run.PlanFormatJSON)
+		if err != nil {
+			return fmt.Errorf("retrieving plan: %w", err)
+		}
+		return estimate.estimate(plan, s.catalog)
+	}())
+	err := s.db.Tx(ctx, func(ctx context.Context) error {
+		if err := s.db.upsert(ctx, estimate); err != nil {
+			return fmt.Errorf("saving cost estimate: %w", err)
+		}
+		_, err := s.runs.FinishCostEstimate(ctx, runID)
+		return err
+	})
+	if err != nil {
+		s.Error(err, "estimating costs", "run_id", runID)
+		return nil, err
+	}
+	s.V(0).Info("estimated costs", "estimate", estimate)
+	return estimate, nil
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/costestimate"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/ui/helpers"
+)
+
+type Handlers struct {
+	Client CostEstimateService
+}
+
+type CostEstimateService interface {
+	GetRunCostEstimate(ctx context.Context, runID resource.TfeID) (*costestimate.CostEstimate, error)
+}
+
+func (h *Handlers) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/runs/{run_id}/cost-estimate", h.getRunCostEstimate).Methods("GET")
+}
+
+// getRunCostEstimate renders the cost estimate for a run, for embedding in the
+// run page.
+func (h *Handlers) getRunCostEstimate(w http.ResponseWriter, r *http.Request) {
+	runID, err := decode.ID("run_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	estimate, err := h.Client.GetRunCostEstimate(r.Context(), runID)
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		// Costs are yet to be estimated.
+		estimate = nil
+	} else if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	helpers.Render(costEstimate(estimate), w, r)
+}
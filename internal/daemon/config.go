@@ -6,12 +6,16 @@ import (
 
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authenticator"
+	"github.com/leg100/otf/internal/bitbucket"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/configversion"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/engine"
 	"github.com/leg100/otf/internal/forgejo"
 	"github.com/leg100/otf/internal/github"
 	"github.com/leg100/otf/internal/gitlab"
 	"github.com/leg100/otf/internal/runner"
+	"github.com/leg100/otf/internal/secrets"
 )
 
 var ErrInvalidSecretLength = errors.New("secret must be 16 bytes in size")
@@ -26,12 +30,15 @@ type Config struct {
 	GitlabHostname               *internal.WebURL
 	GitlabClientID               string
 	GitlabClientSecret           string
+	BitbucketHostname            *internal.WebURL
 	ForgejoHostname              *internal.WebURL // TODO: forgejo is often self-hosted, and there may be more than one of them.  this should be a per-VCS setting
 	OIDC                         authenticator.OIDCConfig
+	SAML                         authenticator.SAMLConfig
 	Secret                       []byte // 16-byte secret for signing URLs and encrypting payloads
 	PublicKeyPath                string
 	PrivateKeyPath               string
 	SiteToken                    string
+	SCIMToken                    string
 	Host                         string
 	WebhookHost                  string
 	Address                      string
@@ -40,6 +47,7 @@ type Config struct {
 	SSL                          bool
 	CertFile, KeyFile            string
 	EnableRequestLogging         bool
+	TrustedProxies               []string
 	RestrictOrganizationCreation bool
 	SiteAdmins                   []string
 	SkipTLSVerification          bool
@@ -49,8 +57,17 @@ type Config struct {
 	DefaultEngine                *engine.Engine
 	DeleteRunsAfter              time.Duration
 	DeleteConfigsAfter           time.Duration
+	RevokeTokensUnusedAfter      time.Duration
+	OrganizationJobQuota         int
 	OverrideDeleterInterval      time.Duration
+	AssessmentInterval           time.Duration
+	OverrideAssessorInterval     time.Duration
 	GoogleIAPAudience            string
+	BlobStore                    blob.Config
+	Encryption                   encryption.Config
+	Secrets                      secrets.Config
+	PriceCatalog                 string
+	EngineMirrorKeyring          string
 
 	// Overrides for testing purposes.
 	DisableScheduler     bool
@@ -61,12 +78,13 @@ type Config struct {
 // NewConfig constructs an otfd configuration with defaults.
 func NewConfig() Config {
 	return Config{
-		RunnerConfig:    runner.NewDefaultConfig(),
-		MaxConfigSize:   configversion.DefaultConfigMaxSize,
-		DefaultEngine:   engine.Default,
-		GithubHostname:  github.DefaultBaseURL(),
-		GitlabHostname:  gitlab.DefaultBaseURL,
-		ForgejoHostname: forgejo.DefaultBaseURL,
+		RunnerConfig:      runner.NewDefaultConfig(),
+		MaxConfigSize:     configversion.DefaultConfigMaxSize,
+		DefaultEngine:     engine.Default,
+		GithubHostname:    github.DefaultBaseURL(),
+		GitlabHostname:    gitlab.DefaultBaseURL,
+		BitbucketHostname: bitbucket.DefaultBaseURL,
+		ForgejoHostname:   forgejo.DefaultBaseURL,
 	}
 }
 
@@ -11,15 +11,25 @@ import (
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/api"
 	apiauth "github.com/leg100/otf/internal/api/auth"
+	"github.com/leg100/otf/internal/audit"
+	auditapi "github.com/leg100/otf/internal/audit/api"
+	auditui "github.com/leg100/otf/internal/audit/ui"
 	"github.com/leg100/otf/internal/authenticator"
 	"github.com/leg100/otf/internal/authn"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/bitbucket"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/configversion"
 	configversionapi "github.com/leg100/otf/internal/configversion/api"
 	"github.com/leg100/otf/internal/connections"
+	"github.com/leg100/otf/internal/costestimate"
+	costestimateapi "github.com/leg100/otf/internal/costestimate/api"
+	costestimateui "github.com/leg100/otf/internal/costestimate/ui"
 	"github.com/leg100/otf/internal/disco"
 	"github.com/leg100/otf/internal/dynamiccreds"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/engine"
+	engineapi "github.com/leg100/otf/internal/engine/api"
 	"github.com/leg100/otf/internal/forgejo"
 	"github.com/leg100/otf/internal/github"
 	githubui "github.com/leg100/otf/internal/github/ui"
@@ -36,7 +46,15 @@ import (
 	"github.com/leg100/otf/internal/organization"
 	orgapi "github.com/leg100/otf/internal/organization/api"
 	orgui "github.com/leg100/otf/internal/organization/ui"
+	"github.com/leg100/otf/internal/policy"
+	policyapi "github.com/leg100/otf/internal/policy/api"
+	policyui "github.com/leg100/otf/internal/policy/ui"
+	"github.com/leg100/otf/internal/project"
+	projectapi "github.com/leg100/otf/internal/project/api"
+	projectui "github.com/leg100/otf/internal/project/ui"
 	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/registryprovider"
+	registryproviderapi "github.com/leg100/otf/internal/registryprovider/api"
 	"github.com/leg100/otf/internal/repohooks"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/run"
@@ -48,6 +66,11 @@ import (
 	"github.com/leg100/otf/internal/runner"
 	runnerapi "github.com/leg100/otf/internal/runner/api"
 	runnerui "github.com/leg100/otf/internal/runner/ui"
+	"github.com/leg100/otf/internal/runtask"
+	runtaskapi "github.com/leg100/otf/internal/runtask/api"
+	runtaskui "github.com/leg100/otf/internal/runtask/ui"
+	"github.com/leg100/otf/internal/scim"
+	"github.com/leg100/otf/internal/secrets"
 	"github.com/leg100/otf/internal/sql"
 	"github.com/leg100/otf/internal/sshkey"
 	sshkeyapi "github.com/leg100/otf/internal/sshkey/api"
@@ -60,6 +83,7 @@ import (
 	teamui "github.com/leg100/otf/internal/team/ui"
 	"github.com/leg100/otf/internal/tfeapi"
 	"github.com/leg100/otf/internal/tokens"
+	tokensui "github.com/leg100/otf/internal/tokens/ui"
 	"github.com/leg100/otf/internal/ui"
 	uiauth "github.com/leg100/otf/internal/ui/auth"
 	"github.com/leg100/otf/internal/ui/session"
@@ -75,33 +99,40 @@ import (
 	"github.com/leg100/otf/internal/workspace"
 	workspaceapi "github.com/leg100/otf/internal/workspace/api"
 	workspaceui "github.com/leg100/otf/internal/workspace/ui"
+	"golang.org/x/crypto/openpgp"
 	"golang.org/x/sync/errgroup"
 )
 
 type (
 	Daemon struct {
-		DB             *sql.DB
-		Organizations  *organization.Service
-		Runs           *run.Service
-		Workspaces     *workspace.Service
-		Variables      *variable.Service
-		Notifications  *notifications.Service
-		State          *state.Service
-		Configs        *configversion.Service
-		Modules        *module.Service
-		VCSProviders   *vcs.Service
-		Tokens         *tokens.Service
-		Sessions       *session.Service
-		Teams          *team.Service
-		Users          *user.Service
-		GithubApp      *github.Service
-		RepoHooks      *repohooks.Service
-		Runners        *runner.Service
-		Connections    *connections.Service
-		System         *internal.HostnameService
-		SSHKeys        *sshkey.Service
-		RunTriggers    *trigger.Service
-		AuthMiddleware []mux.MiddlewareFunc
+		DB                *sql.DB
+		Organizations     *organization.Service
+		Runs              *run.Service
+		Workspaces        *workspace.Service
+		Variables         *variable.Service
+		Notifications     *notifications.Service
+		State             *state.Service
+		Configs           *configversion.Service
+		Modules           *module.Service
+		VCSProviders      *vcs.Service
+		Tokens            *tokens.Service
+		Sessions          *session.Service
+		Teams             *team.Service
+		Users             *user.Service
+		GithubApp         *github.Service
+		RepoHooks         *repohooks.Service
+		Runners           *runner.Service
+		Connections       *connections.Service
+		System            *internal.HostnameService
+		SSHKeys           *sshkey.Service
+		Policies          *policy.Service
+		RunTasks          *runtask.Service
+		CostEstimates     *costestimate.Service
+		Projects          *project.Service
+		RegistryProviders *registryprovider.Service
+		RunTriggers       *trigger.Service
+		Audit             *audit.Service
+		AuthMiddleware    []mux.MiddlewareFunc
 
 		netListener net.Listener
 		server      *http.Server
@@ -143,15 +174,49 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		netListener.Addr().(*net.TCPAddr),
 	)
 
+	// blobStore stores state, configs, plan files, logs, etc.
+	blobStore, err := blob.New(ctx, cfg.BlobStore, db)
+	if err != nil {
+		return nil, fmt.Errorf("constructing blob store: %w", err)
+	}
+
+	// cipher encrypts secrets such as sensitive variables, ssh keys, vcs
+	// tokens and github app keys
+	cipher, err := encryption.New(cfg.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("constructing cipher: %w", err)
+	}
+	if !cipher.Enabled() {
+		logger.Info("no encryption key configured: secrets are stored unencrypted in the database")
+	}
+
+	// secretResolver retrieves the values of variables sourced from external
+	// secret stores
+	secretResolver, err := secrets.New(cfg.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("constructing secret providers: %w", err)
+	}
+
 	// responder responds to TFE API requests
 	responder := tfeapi.NewResponder(logger)
 
 	// Setup url signer
 	signer := tfeapi.NewSigner(cfg.Secret)
 
+	authorizer := authz.NewAuthorizer(logger)
+
+	auditService := audit.NewService(audit.Options{
+		Logger:     logger,
+		Authorizer: authorizer,
+		DB:         db,
+	})
+
 	tokensService, err := tokens.NewService(tokens.Options{
-		Logger: logger,
-		Secret: cfg.Secret,
+		Logger:        logger,
+		Secret:        cfg.Secret,
+		DB:            db,
+		Authorizer:    authorizer,
+		AuditRecorder: auditService,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("setting up token service: %w", err)
@@ -200,14 +265,13 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		workspaceBroker,
 	)
 
-	authorizer := authz.NewAuthorizer(logger)
-
 	orgService := organization.NewService(organization.Options{
 		Logger:                       logger,
 		Authorizer:                   authorizer,
 		DB:                           db,
 		RestrictOrganizationCreation: cfg.RestrictOrganizationCreation,
 		TokensService:                tokensService,
+		AuditRecorder:                auditService,
 	})
 
 	teamService := team.NewService(team.Options{
@@ -216,6 +280,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		DB:                  db,
 		OrganizationService: orgService,
 		TokensService:       tokensService,
+		AuditRecorder:       auditService,
 	})
 	userService := user.NewService(user.Options{
 		Logger:        logger,
@@ -224,6 +289,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		TokensService: tokensService,
 		SiteToken:     cfg.SiteToken,
 		TeamService:   teamService,
+		AuditRecorder: auditService,
 	})
 	// promote nominated users to site admin
 	if err := userService.SetSiteAdmins(ctx, cfg.SiteAdmins...); err != nil {
@@ -280,15 +346,27 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		Route:  &uiauth.Route{},
 		Logger: logger,
 	}
+	// SCIM requests
+	scimAuthMiddleware := &authn.Middleware{
+		Authenticators: []authn.Authenticator{
+			// Authenticate SCIM requests from an identity provider using the
+			// dedicated SCIM token.
+			&scim.Authenticator{Token: cfg.SCIMToken},
+		},
+		Route:  &scim.Route{},
+		Logger: logger,
+	}
 	authMiddleware := []mux.MiddlewareFunc{
 		apiAuthMiddleware.Authenticate,
 		uiAuthMiddleware.Authenticate,
+		scimAuthMiddleware.Authenticate,
 	}
 
 	configService := configversion.NewService(configversion.Options{
 		Logger:     logger,
 		Authorizer: authorizer,
 		DB:         db,
+		BlobStore:  blobStore,
 	})
 
 	vcsService := vcs.NewService(vcs.Options{
@@ -296,7 +374,9 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		Authorizer:          authorizer,
 		DB:                  db,
 		SourceIconRegistrar: configService,
+		Cipher:              cipher,
 		SkipTLSVerification: cfg.SkipTLSVerification,
+		AuditRecorder:       auditService,
 	})
 
 	vcsEventBroker := &vcs.Broker{}
@@ -309,6 +389,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		GithubAPIURL:        cfg.GithubHostname,
 		SkipTLSVerification: cfg.SkipTLSVerification,
 		VCSEventBroker:      vcsEventBroker,
+		Cipher:              cipher,
 	})
 
 	repoService := repohooks.NewService(repohooks.Options{
@@ -323,6 +404,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 			VCSService:          vcsService,
 		},
 		VCSEventBroker: vcsEventBroker,
+		Cipher:         cipher,
 	})
 
 	connectionService := connections.NewService(connections.Options{
@@ -331,9 +413,20 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		VCSProviderService: vcsService,
 		RepoHooksService:   repoService,
 	})
+	// keyring verifies engine releases added to the engine mirror
+	var engineKeyring openpgp.EntityList
+	if cfg.EngineMirrorKeyring != "" {
+		engineKeyring, err = engine.ReadKeyring(cfg.EngineMirrorKeyring)
+		if err != nil {
+			return nil, err
+		}
+	}
 	engineService := engine.NewService(engine.Options{
-		Logger: logger,
-		DB:     db,
+		Logger:     logger,
+		DB:         db,
+		Authorizer: authorizer,
+		BlobStore:  blobStore,
+		Keyring:    engineKeyring,
 	})
 	workspaceService := workspace.NewService(workspace.Options{
 		Logger:            logger,
@@ -349,7 +442,8 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 			EngineService:       engineService,
 			OrganizationService: orgService,
 		},
-		Broker: workspaceBroker,
+		Broker:        workspaceBroker,
+		AuditRecorder: auditService,
 	})
 
 	runTriggerService := trigger.NewService(trigger.Options{
@@ -381,6 +475,8 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		DaemonCtx:          ctx,
 		Broker:             runBroker,
 		ChunkBroker:        chunkBroker,
+		BlobStore:          blobStore,
+		AuditRecorder:      auditService,
 	})
 	moduleService := module.NewService(module.Options{
 		Logger:             logger,
@@ -390,18 +486,29 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		ConnectionsService: connectionService,
 		RepohookService:    repoService,
 		VCSEventSubscriber: vcsEventBroker,
+		BlobStore:          blobStore,
 	})
-	stateService := state.NewService(state.Options{
+	registryProviderService := registryprovider.NewService(registryprovider.Options{
 		Logger:     logger,
 		Authorizer: authorizer,
 		DB:         db,
 	})
+	stateService := state.NewService(state.Options{
+		Logger:        logger,
+		Authorizer:    authorizer,
+		DB:            db,
+		BlobStore:     blobStore,
+		AuditRecorder: auditService,
+	})
 	variableService := variable.NewService(variable.Options{
 		Logger:           logger,
 		Authorizer:       authorizer,
 		DB:               db,
 		WorkspaceService: workspaceService,
 		RunClient:        runService,
+		AuditRecorder:    auditService,
+		Cipher:           cipher,
+		Secrets:          secretResolver,
 	})
 	dynamiccredsService, err := dynamiccreds.NewService(dynamiccreds.Options{
 		HostnameService: hostnameService,
@@ -431,13 +538,22 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		URLClient:            hostnameService,
 		SessionClient:        sessionService,
 		UserService:          userService,
+		TeamService:          teamService,
 		IDTokenHandlerConfig: cfg.OIDC,
+		SAMLConfig:           cfg.SAML,
 		SkipTLSVerification:  cfg.SkipTLSVerification,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Bitbucket registrations
+	bitbucket.RegisterVCSKind(
+		vcsService,
+		cfg.BitbucketHostname,
+		cfg.SkipTLSVerification,
+	)
+
 	// Forgejo registrations
 	forgejo.RegisterVCSKind(
 		vcsService,
@@ -475,9 +591,53 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 	}
 
 	sshkeyService := sshkey.NewService(sshkey.Options{
+		Logger:        logger,
+		Authorizer:    authorizer,
+		DB:            db,
+		AuditRecorder: auditService,
+		Cipher:        cipher,
+	})
+
+	policyService := policy.NewService(policy.Options{
+		Logger:            logger,
+		Authorizer:        authorizer,
+		DB:                db,
+		RunClient:         runService,
+		VCSProviderClient: vcsService,
+	})
+
+	runTaskService := runtask.NewService(runtask.Options{
+		Logger:          logger,
+		Authorizer:      authorizer,
+		DB:              db,
+		RunClient:       runService,
+		WorkspaceClient: workspaceService,
+		TokensService:   tokensService,
+		HostnameService: hostnameService,
+		Cipher:          cipher,
+	})
+
+	// catalog prices resources for cost estimates
+	var priceCatalog costestimate.Catalog
+	if cfg.PriceCatalog != "" {
+		priceCatalog, err = costestimate.LoadPriceSheet(cfg.PriceCatalog)
+		if err != nil {
+			return nil, fmt.Errorf("loading price catalog: %w", err)
+		}
+	}
+	costEstimateService := costestimate.NewService(costestimate.Options{
 		Logger:     logger,
 		Authorizer: authorizer,
 		DB:         db,
+		RunClient:  runService,
+		Catalog:    priceCatalog,
+	})
+
+	projectService := project.NewService(project.Options{
+		Logger:        logger,
+		Authorizer:    authorizer,
+		DB:            db,
+		AuditRecorder: auditService,
 	})
 
 	serverRunner, err := runner.New(
@@ -515,11 +675,12 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 	}
 
 	notificationService := notifications.NewService(notifications.Options{
-		Logger:     logger,
-		Authorizer: authorizer,
-		DB:         db,
-		Listener:   sqlListener,
-		Broker:     notificationBroker,
+		Logger:        logger,
+		Authorizer:    authorizer,
+		DB:            db,
+		Listener:      sqlListener,
+		Broker:        notificationBroker,
+		AuditRecorder: auditService,
 	})
 
 	// Handlers for the TFE API
@@ -567,6 +728,26 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 				Client:    sshkeyService,
 				Responder: responder,
 			},
+			&policyapi.TFEAPI{
+				Client:     policyService,
+				Authorizer: authorizer,
+				Responder:  responder,
+			},
+			&runtaskapi.TFEAPI{
+				Client:    runTaskService,
+				Responder: responder,
+			},
+			&costestimateapi.TFEAPI{
+				Client:    costEstimateService,
+				Responder: responder,
+			},
+			&projectapi.TFEAPI{
+				Client:    projectService,
+				Responder: responder,
+			},
+			&auditapi.TFEAPI{
+				Client: auditService,
+			},
 			configversionapi.NewTFEAPI(
 				logger,
 				configService,
@@ -660,6 +841,14 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 				Client:    runnerService,
 				Responder: responder,
 			},
+			&registryproviderapi.API{
+				Client:    registryProviderService,
+				Responder: responder,
+			},
+			&engineapi.API{
+				Client:    engineService,
+				Responder: responder,
+			},
 		},
 	}
 
@@ -738,6 +927,39 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 			&sshkeyui.Handlers{
 				Client: sshkeyService,
 			},
+			&policyui.Handlers{
+				Client:     policyService,
+				Authorizer: authorizer,
+			},
+			&runtaskui.Handlers{
+				Client: struct {
+					*runtask.RunTaskService
+					*workspace.WorkspaceService
+				}{
+					RunTaskService:   runTaskService,
+					WorkspaceService: workspaceService,
+				},
+				Authorizer: authorizer,
+			},
+			&costestimateui.Handlers{
+				Client: costEstimateService,
+			},
+			&projectui.Handlers{
+				Client: struct {
+					*project.ProjectService
+					*team.TeamService
+				}{
+					ProjectService: projectService,
+					TeamService:    teamService,
+				},
+				Authorizer: authorizer,
+			},
+			&auditui.Handlers{
+				Client: auditService,
+			},
+			&tokensui.Handlers{
+				Client: tokensService,
+			},
 			&userui.Handlers{
 				Client: userService,
 			},
@@ -751,6 +973,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 					*engine.EngineService
 					*vcs.VCSService
 					*team.TeamService
+					*project.ProjectService
 				}{
 					RunService:       runService,
 					UserService:      userService,
@@ -760,6 +983,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 					EngineService:    engineService,
 					VCSService:       vcsService,
 					TeamService:      teamService,
+					ProjectService:   projectService,
 				},
 				Authorizer:     authorizer,
 				SingleRunTable: runuiHandlers.SingleRunTable,
@@ -820,6 +1044,17 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 			Client: moduleService,
 			Signer: signer,
 		},
+		&registryprovider.Registry{
+			Client: registryProviderService,
+			Signer: signer,
+		},
+		&scim.Server{
+			Logger:        logger,
+			Users:         userService,
+			Teams:         teamService,
+			Organizations: orgService,
+			URLs:          hostnameService,
+		},
 	}
 
 	// Construct subsystems; ordered by start order
@@ -844,6 +1079,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 				runService,
 				configService,
 				hostnameService,
+				db,
 			),
 		},
 		{
@@ -851,6 +1087,36 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 			Logger: logger,
 			System: runService.MetricsCollector,
 		},
+		{
+			Name:      "policy-checker",
+			Logger:    logger,
+			Exclusive: true,
+			System: &policy.Checker{
+				Logger:   logger.WithValues("component", "policy-checker"),
+				Runs:     runService,
+				Policies: policyService,
+			},
+		},
+		{
+			Name:      "run-task-dispatcher",
+			Logger:    logger,
+			Exclusive: true,
+			System: &runtask.Dispatcher{
+				Logger: logger.WithValues("component", "run-task-dispatcher"),
+				Runs:   runService,
+				Tasks:  runTaskService,
+			},
+		},
+		{
+			Name:      "cost-estimator",
+			Logger:    logger,
+			Exclusive: true,
+			System: &costestimate.Estimator{
+				Logger:    logger.WithValues("component", "cost-estimator"),
+				Runs:      runService,
+				Estimates: costEstimateService,
+			},
+		},
 		{
 			Name:      "timeout",
 			Logger:    logger,
@@ -863,6 +1129,18 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 				Runs:                  runService,
 			},
 		},
+		{
+			Name:      "assessor",
+			Logger:    logger,
+			Exclusive: true,
+			System: &run.Assessor{
+				Logger:                logger.WithValues("component", "assessor"),
+				Interval:              cfg.AssessmentInterval,
+				OverrideCheckInterval: cfg.OverrideAssessorInterval,
+				Runs:                  runService,
+				Workspaces:            workspaceService,
+			},
+		},
 		{
 			Name:   "run-deleter",
 			Logger: logger,
@@ -883,6 +1161,28 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 				AgeThreshold:          cfg.DeleteConfigsAfter,
 			},
 		},
+		{
+			Name:      "token-revoker",
+			Logger:    logger,
+			Exclusive: true,
+			System: &resource.Deleter[*tokens.Token]{
+				Logger:                logger.WithValues("component", "token-revoker"),
+				OverrideCheckInterval: cfg.OverrideDeleterInterval,
+				Client:                &tokenRevokerAdapter{svc: tokensService},
+				AgeThreshold:          cfg.RevokeTokensUnusedAfter,
+			},
+		},
+		{
+			Name:      "blob-reaper",
+			Logger:    logger,
+			Exclusive: true,
+			System: &blob.Reaper{
+				Logger:                logger.WithValues("component", "blob-reaper"),
+				DB:                    db,
+				Store:                 blobStore,
+				OverrideCheckInterval: cfg.OverrideDeleterInterval,
+			},
+		},
 		{
 			Name:      "notifier",
 			Logger:    logger,
@@ -900,7 +1200,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 			Name:      "job-allocator",
 			Logger:    logger,
 			Exclusive: true,
-			System:    runnerService.NewAllocator(logger),
+			System:    runnerService.NewAllocator(logger, cfg.OrganizationJobQuota),
 		},
 		{
 			Name:      "runner-manager",
@@ -965,6 +1265,7 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 		CertFile:             cfg.CertFile,
 		KeyFile:              cfg.KeyFile,
 		EnableRequestLogging: cfg.EnableRequestLogging,
+		TrustedProxies:       cfg.TrustedProxies,
 		Middleware:           authMiddleware,
 		Handlers:             handlers,
 	})
@@ -973,31 +1274,37 @@ func New(ctx context.Context, logger logr.Logger, cfg Config) (*Daemon, error) {
 	}
 
 	return &Daemon{
-		Organizations:  orgService,
-		System:         hostnameService,
-		Runs:           runService,
-		Workspaces:     workspaceService,
-		Variables:      variableService,
-		Notifications:  notificationService,
-		State:          stateService,
-		Configs:        configService,
-		Modules:        moduleService,
-		VCSProviders:   vcsService,
-		Tokens:         tokensService,
-		Sessions:       sessionService,
-		Teams:          teamService,
-		Users:          userService,
-		RepoHooks:      repoService,
-		GithubApp:      githubAppService,
-		Connections:    connectionService,
-		Runners:        runnerService,
-		SSHKeys:        sshkeyService,
-		RunTriggers:    runTriggerService,
-		DB:             db,
-		AuthMiddleware: authMiddleware,
-		netListener:    netListener,
-		server:         server,
-		subsystems:     subsystems,
+		Organizations:     orgService,
+		System:            hostnameService,
+		Runs:              runService,
+		Workspaces:        workspaceService,
+		Variables:         variableService,
+		Notifications:     notificationService,
+		State:             stateService,
+		Configs:           configService,
+		Modules:           moduleService,
+		VCSProviders:      vcsService,
+		Tokens:            tokensService,
+		Sessions:          sessionService,
+		Teams:             teamService,
+		Users:             userService,
+		RepoHooks:         repoService,
+		GithubApp:         githubAppService,
+		Connections:       connectionService,
+		Runners:           runnerService,
+		SSHKeys:           sshkeyService,
+		Policies:          policyService,
+		RunTasks:          runTaskService,
+		CostEstimates:     costEstimateService,
+		Projects:          projectService,
+		RegistryProviders: registryProviderService,
+		RunTriggers:       runTriggerService,
+		Audit:             auditService,
+		DB:                db,
+		AuthMiddleware:    authMiddleware,
+		netListener:       netListener,
+		server:            server,
+		subsystems:        subsystems,
 	}, nil
 }
 
@@ -1049,6 +1356,20 @@ func (a *configVersionDeleterAdapter) Delete(ctx context.Context, id resource.Tf
 	return a.svc.DeleteConfigVersion(ctx, id)
 }
 
+// tokenRevokerAdapter adapts tokens.Service to the resource.deleterClient
+// interface, revoking tokens that have not been used within the age threshold.
+type tokenRevokerAdapter struct {
+	svc *tokens.Service
+}
+
+func (a *tokenRevokerAdapter) ListOlderThan(ctx context.Context, age time.Time) ([]*tokens.Token, error) {
+	return a.svc.ListTokensUnusedSince(ctx, age)
+}
+
+func (a *tokenRevokerAdapter) Delete(ctx context.Context, id resource.TfeID) error {
+	return a.svc.RevokeTokens(ctx, []resource.TfeID{id})
+}
+
 // runDeleterAdapter adapts run.Service to the resource.deleterClient interface.
 type runDeleterAdapter struct {
 	svc *run.Service
@@ -0,0 +1,221 @@
+// Package encryption provides envelope encryption of secret material stored in
+// the database, i.e. sensitive variable values, SSH private keys and VCS
+// provider tokens.
+//
+// Each value is encrypted with its own randomly generated data encryption key
+// (DEK). The DEK is in turn encrypted ("wrapped") with a key encryption key
+// (KEK) held by a KMS, and the wrapped DEK is stored alongside the encrypted
+// value. The KEK itself is never stored in the database.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leg100/otf/internal"
+)
+
+const (
+	// LocalKMSBackend wraps data encryption keys with a key encryption key
+	// read from a local file. This is the default.
+	LocalKMSBackend = "local"
+
+	// envelopePrefix prefixes every encrypted value, distinguishing it from
+	// values written before encryption was enabled.
+	envelopePrefix = "otfenc:v1:"
+
+	// dekSize is the size in bytes of a data encryption key (AES-256).
+	dekSize = 32
+)
+
+// ErrNoKey is returned when decrypting a value but no key encryption key is
+// configured.
+var ErrNoKey = errors.New("value is encrypted but no encryption key is configured")
+
+// KMS is a key management service that wraps and unwraps data encryption keys
+// with a key encryption key. Implementations may hold the key encryption key
+// locally or delegate to a remote service.
+type KMS interface {
+	// KeyID uniquely identifies the key encryption key. It is stored alongside
+	// each encrypted value in order to identify the key with which to decrypt
+	// it, and must not contain a colon.
+	KeyID() string
+	// WrapKey encrypts a data encryption key.
+	WrapKey(ctx context.Context, dek []byte) ([]byte, error)
+	// UnwrapKey decrypts a data encryption key.
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// Config configures encryption.
+type Config struct {
+	// KMS is the KMS backend. Only local is currently supported.
+	KMS string
+	// KeyFile is the path to a file containing the key encryption key for
+	// the local backend. If empty then encryption is disabled.
+	KeyFile string
+	// PreviousKeyFile is the path to a file containing the previous key
+	// encryption key for the local backend, permitting values encrypted with
+	// the previous key to be decrypted until they are re-encrypted with the
+	// current key.
+	PreviousKeyFile string
+}
+
+// New constructs a cipher according to the config.
+func New(cfg Config) (*Cipher, error) {
+	switch cfg.KMS {
+	case LocalKMSBackend, "":
+		if cfg.KeyFile == "" {
+			if cfg.PreviousKeyFile != "" {
+				return nil, fmt.Errorf("previous encryption key specified without an encryption key")
+			}
+			return NewCipher(nil), nil
+		}
+		current, err := NewLocalKMSFromFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		var previous []KMS
+		if cfg.PreviousKeyFile != "" {
+			kms, err := NewLocalKMSFromFile(cfg.PreviousKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			previous = append(previous, kms)
+		}
+		return NewCipher(current, previous...), nil
+	default:
+		return nil, fmt.Errorf("unknown KMS backend: %s", cfg.KMS)
+	}
+}
+
+// Cipher encrypts and decrypts secret material using envelope encryption.
+//
+// A cipher without a KMS leaves values unencrypted. Values that were written
+// before encryption was enabled are returned as-is when decrypted, and are
+// encrypted the next time they are written or when the key is rotated.
+type Cipher struct {
+	current  KMS
+	previous []KMS
+}
+
+// NewCipher constructs a cipher that encrypts with the current KMS and
+// decrypts with either the current or any of the previous KMSs. If current is
+// nil then encryption is disabled.
+func NewCipher(current KMS, previous ...KMS) *Cipher {
+	return &Cipher{current: current, previous: previous}
+}
+
+// Enabled returns true if values are encrypted.
+func (c *Cipher) Enabled() bool {
+	return c != nil && c.current != nil
+}
+
+// Encrypt encrypts plaintext. If encryption is disabled then the plaintext is
+// returned unchanged.
+func (c *Cipher) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	if !c.Enabled() {
+		return plaintext, nil
+	}
+	dek := make([]byte, dekSize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+	ciphertext, err := internal.Encrypt(plaintext, dek)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := c.current.WrapKey(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("wrapping data encryption key: %w", err)
+	}
+	envelope := envelopePrefix + c.current.KeyID() + ":" + base64.RawURLEncoding.EncodeToString(wrapped) + ":" + ciphertext
+	return []byte(envelope), nil
+}
+
+// Decrypt decrypts a value encrypted with Encrypt. Unencrypted values are
+// returned unchanged.
+func (c *Cipher) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if !c.Enabled() {
+		return nil, ErrNoKey
+	}
+	keyID, wrapped, ciphertext, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	kms := c.kms(keyID)
+	if kms == nil {
+		return nil, fmt.Errorf("value is encrypted with unknown encryption key: %s", keyID)
+	}
+	dek, err := kms.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping data encryption key: %w", err)
+	}
+	return internal.Decrypt(ciphertext, dek)
+}
+
+// EncryptString is a convenience wrapper around Encrypt for strings.
+func (c *Cipher) EncryptString(ctx context.Context, plaintext string) (string, error) {
+	encrypted, err := c.Encrypt(ctx, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return string(encrypted), nil
+}
+
+// DecryptString is a convenience wrapper around Decrypt for strings.
+func (c *Cipher) DecryptString(ctx context.Context, data string) (string, error) {
+	decrypted, err := c.Decrypt(ctx, []byte(data))
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
+
+// isCurrent returns true if the value is encrypted with the current key.
+func (c *Cipher) isCurrent(data []byte) bool {
+	if !c.Enabled() || !IsEncrypted(data) {
+		return false
+	}
+	keyID, _, _, err := parseEnvelope(data)
+	return err == nil && keyID == c.current.KeyID()
+}
+
+func (c *Cipher) kms(keyID string) KMS {
+	if c.current.KeyID() == keyID {
+		return c.current
+	}
+	for _, kms := range c.previous {
+		if kms.KeyID() == keyID {
+			return kms
+		}
+	}
+	return nil
+}
+
+// IsEncrypted returns true if the value was encrypted with a Cipher.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(envelopePrefix))
+}
+
+// parseEnvelope parses an encrypted value of the form:
+//
+//	otfenc:v1:<key id>:<wrapped data encryption key>:<ciphertext>
+func parseEnvelope(data []byte) (keyID string, wrapped []byte, ciphertext string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(string(data), envelopePrefix), ":", 3)
+	if len(parts) != 3 {
+		return "", nil, "", errors.New("malformed encrypted value")
+	}
+	wrapped, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	return parts[0], wrapped, parts[2], nil
+}
@@ -0,0 +1,122 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKMS(t *testing.T, b byte) *LocalKMS {
+	t.Helper()
+
+	kms, err := NewLocalKMS(bytes.Repeat([]byte{b}, 32))
+	require.NoError(t, err)
+	return kms
+}
+
+func TestCipher(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t, 1)
+	cipher := NewCipher(kms)
+
+	encrypted, err := cipher.Encrypt(ctx, []byte("hunter2"))
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "hunter2")
+	assert.True(t, cipher.isCurrent(encrypted))
+
+	t.Run("decrypt", func(t *testing.T) {
+		got, err := cipher.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", string(got))
+	})
+
+	t.Run("each value has its own data encryption key", func(t *testing.T) {
+		again, err := cipher.Encrypt(ctx, []byte("hunter2"))
+		require.NoError(t, err)
+		assert.NotEqual(t, encrypted, again)
+	})
+
+	t.Run("unencrypted value is returned as-is", func(t *testing.T) {
+		got, err := cipher.Decrypt(ctx, []byte("plaintext"))
+		require.NoError(t, err)
+		assert.Equal(t, "plaintext", string(got))
+		assert.False(t, cipher.isCurrent(got))
+	})
+
+	t.Run("decrypt with previous key", func(t *testing.T) {
+		rotated := NewCipher(newTestKMS(t, 2), kms)
+		assert.False(t, rotated.isCurrent(encrypted))
+
+		got, err := rotated.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", string(got))
+	})
+
+	t.Run("decrypt with unknown key", func(t *testing.T) {
+		_, err := NewCipher(newTestKMS(t, 2)).Decrypt(ctx, encrypted)
+		assert.ErrorContains(t, err, "unknown encryption key")
+	})
+
+	t.Run("decrypt without key", func(t *testing.T) {
+		_, err := NewCipher(nil).Decrypt(ctx, encrypted)
+		assert.ErrorIs(t, err, ErrNoKey)
+	})
+
+	t.Run("malformed value", func(t *testing.T) {
+		_, err := cipher.Decrypt(ctx, []byte("otfenc:v1:garbage"))
+		assert.ErrorContains(t, err, "malformed encrypted value")
+	})
+}
+
+func TestCipher_Disabled(t *testing.T) {
+	var nilCipher *Cipher
+	for _, cipher := range []*Cipher{nilCipher, NewCipher(nil)} {
+		assert.False(t, cipher.Enabled())
+
+		got, err := cipher.EncryptString(context.Background(), "hunter2")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("0101010101010101010101010101010101010101010101010101010101010101\n"), 0o600))
+	shortKeyFile := filepath.Join(dir, "short")
+	require.NoError(t, os.WriteFile(shortKeyFile, []byte("0101"), 0o600))
+
+	t.Run("local", func(t *testing.T) {
+		cipher, err := New(Config{KeyFile: keyFile})
+		require.NoError(t, err)
+		assert.True(t, cipher.Enabled())
+		assert.Equal(t, newTestKMS(t, 1).KeyID(), cipher.current.KeyID())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cipher, err := New(Config{KMS: LocalKMSBackend})
+		require.NoError(t, err)
+		assert.False(t, cipher.Enabled())
+	})
+
+	t.Run("key too short", func(t *testing.T) {
+		_, err := New(Config{KeyFile: shortKeyFile})
+		assert.ErrorContains(t, err, "must be 32 bytes")
+	})
+
+	t.Run("previous key without current key", func(t *testing.T) {
+		_, err := New(Config{PreviousKeyFile: keyFile})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown kms", func(t *testing.T) {
+		_, err := New(Config{KMS: "vault", KeyFile: keyFile})
+		assert.ErrorContains(t, err, "unknown KMS backend")
+	})
+}
@@ -0,0 +1,10 @@
+package encryption
+
+import "github.com/spf13/pflag"
+
+// RegisterFlags adds flags for configuring encryption to the given flag set.
+func RegisterFlags(flags *pflag.FlagSet, cfg *Config) {
+	flags.StringVar(&cfg.KMS, "encryption-kms", LocalKMSBackend, "KMS backend holding the key encryption key: local")
+	flags.StringVar(&cfg.KeyFile, "encryption-key-file", "", "Path to a file containing the hex-encoded 32 byte key encryption key. If unset then secrets are stored unencrypted.")
+	flags.StringVar(&cfg.PreviousKeyFile, "previous-encryption-key-file", "", "Path to a file containing the previous key encryption key, permitting secrets encrypted with the previous key to be decrypted while the key is rotated.")
+}
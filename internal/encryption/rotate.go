@@ -0,0 +1,102 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/sql"
+)
+
+// column is a database column containing secret material.
+type column struct {
+	table  string
+	id     string
+	name   string
+	filter string
+	// bytea is true if the column is of type BYTEA rather than TEXT.
+	bytea bool
+}
+
+// columns lists every database column containing secret material.
+var columns = []column{
+	{table: "variables", id: "variable_id", name: "value", filter: "sensitive"},
+	{table: "ssh_keys", id: "ssh_key_id", name: "private_key", bytea: true},
+	{table: "vcs_providers", id: "vcs_provider_id", name: "token", filter: "token IS NOT NULL"},
+	{table: "github_apps", id: "github_app_id", name: "private_key"},
+	{table: "github_apps", id: "github_app_id", name: "webhook_secret"},
+	{table: "run_tasks", id: "run_task_id", name: "hmac_key", filter: "hmac_key IS NOT NULL"},
+	{table: "repohooks", id: "repohook_id", name: "secret"},
+}
+
+// Rotate re-encrypts every secret in the database that is not already
+// encrypted with the cipher's current key, including secrets that were written
+// before encryption was enabled. The cipher must be able to decrypt secrets
+// encrypted with the previous key. It returns the number of secrets
+// re-encrypted.
+//
+// Secrets in each table are re-encrypted within a transaction, so the rotation
+// can safely be interrupted and re-run.
+func Rotate(ctx context.Context, logger logr.Logger, db *sql.DB, cipher *Cipher) (int, error) {
+	if !cipher.Enabled() {
+		return 0, fmt.Errorf("no encryption key configured")
+	}
+	var total int
+	for _, col := range columns {
+		err := db.Tx(ctx, func(ctx context.Context) error {
+			n, err := rotateColumn(ctx, db, cipher, col)
+			if err != nil {
+				return err
+			}
+			total += n
+			logger.V(1).Info("re-encrypted secrets", "table", col.table, "column", col.name, "count", n)
+			return nil
+		})
+		if err != nil {
+			return total, fmt.Errorf("re-encrypting %s.%s: %w", col.table, col.name, err)
+		}
+	}
+	return total, nil
+}
+
+func rotateColumn(ctx context.Context, db *sql.DB, cipher *Cipher, col column) (int, error) {
+	type row struct {
+		ID    string `db:"id"`
+		Value []byte `db:"value"`
+	}
+	// Cast the ID to text because not every table uses a text ID.
+	q := fmt.Sprintf("SELECT %s::text AS id, %s AS value FROM %s", col.id, col.name, col.table)
+	if col.filter != "" {
+		q += " WHERE " + col.filter
+	}
+	q += " FOR UPDATE"
+	rows, err := sql.CollectRows(db.Query(ctx, q), pgx.RowToStructByName[row])
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, r := range rows {
+		if cipher.isCurrent(r.Value) {
+			continue
+		}
+		plaintext, err := cipher.Decrypt(ctx, r.Value)
+		if err != nil {
+			return n, fmt.Errorf("decrypting %s: %w", r.ID, err)
+		}
+		encrypted, err := cipher.Encrypt(ctx, plaintext)
+		if err != nil {
+			return n, fmt.Errorf("encrypting %s: %w", r.ID, err)
+		}
+		var value any = string(encrypted)
+		if col.bytea {
+			value = encrypted
+		}
+		_, err = db.Exec(ctx, fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s::text = $2", col.table, col.name, col.id), value, r.ID)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
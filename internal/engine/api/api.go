@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/engine"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/tfeapi"
+)
+
+type API struct {
+	*tfeapi.Responder
+	Client apiClient
+}
+
+type apiClient interface {
+	UploadMirrorVersion(ctx context.Context, opts engine.UploadMirrorVersionOptions) (*engine.MirrorVersion, error)
+	SyncMirrorVersion(ctx context.Context, opts engine.MirrorVersionOptions) (*engine.MirrorVersion, error)
+	ListMirrorVersions(ctx context.Context) ([]*engine.MirrorVersion, error)
+	DeleteMirrorVersion(ctx context.Context, opts engine.MirrorVersionOptions) error
+	DownloadEngine(ctx context.Context, engine *engine.Engine, version, os, arch string) ([]byte, error)
+
+	CreateCustomEngine(ctx context.Context, opts engine.CreateCustomEngineOptions) (*engine.CustomEngine, error)
+	UpdateCustomEngine(ctx context.Context, name string, opts engine.UpdateCustomEngineOptions) (*engine.CustomEngine, error)
+	GetCustomEngine(ctx context.Context, name string) (*engine.CustomEngine, error)
+	ListCustomEngines(ctx context.Context) ([]*engine.CustomEngine, error)
+	DeleteCustomEngine(ctx context.Context, name string) error
+}
+
+// mirrorVersionParams identify an engine archive in the mirror from the request
+// path.
+type mirrorVersionParams struct {
+	Engine  engine.Engine `schema:"engine,required"`
+	Version string        `schema:"version,required"`
+	OS      string        `schema:"os,required"`
+	Arch    string        `schema:"arch,required"`
+}
+
+func (a *API) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/engine-mirror", a.listMirrorVersions).Methods("GET")
+	r.HandleFunc("/engine-mirror/upload", a.uploadMirrorVersion).Methods("POST")
+	r.HandleFunc("/engine-mirror/sync", a.syncMirrorVersion).Methods("POST")
+	r.HandleFunc("/engine-mirror/{engine}/{version}/{os}/{arch}", a.deleteMirrorVersion).Methods("DELETE")
+	r.HandleFunc("/engine-mirror/{engine}/{version}/{os}/{arch}/download", a.downloadEngine).Methods("GET")
+
+	r.HandleFunc("/custom-engines", a.createCustomEngine).Methods("POST")
+	r.HandleFunc("/custom-engines", a.listCustomEngines).Methods("GET")
+	r.HandleFunc("/custom-engines/{name}", a.getCustomEngine).Methods("GET")
+	r.HandleFunc("/custom-engines/{name}", a.updateCustomEngine).Methods("PATCH")
+	r.HandleFunc("/custom-engines/{name}", a.deleteCustomEngine).Methods("DELETE")
+}
+
+func (a *API) uploadMirrorVersion(w http.ResponseWriter, r *http.Request) {
+	var opts engine.UploadMirrorVersionOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	version, err := a.Client.UploadMirrorVersion(r.Context(), opts)
+	if err != nil {
+		mirrorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+func (a *API) syncMirrorVersion(w http.ResponseWriter, r *http.Request) {
+	var opts engine.MirrorVersionOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	version, err := a.Client.SyncMirrorVersion(r.Context(), opts)
+	if err != nil {
+		mirrorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+func (a *API) listMirrorVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := a.Client.ListMirrorVersions(r.Context())
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(versions)
+}
+
+func (a *API) deleteMirrorVersion(w http.ResponseWriter, r *http.Request) {
+	var params mirrorVersionParams
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	err := a.Client.DeleteMirrorVersion(r.Context(), engine.MirrorVersionOptions{
+		Engine:  &params.Engine,
+		Version: params.Version,
+		OS:      params.OS,
+		Arch:    params.Arch,
+	})
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) downloadEngine(w http.ResponseWriter, r *http.Request) {
+	var params mirrorVersionParams
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	archive, err := a.Client.DownloadEngine(r.Context(), &params.Engine, params.Version, params.OS, params.Arch)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Write(archive)
+}
+
+func (a *API) createCustomEngine(w http.ResponseWriter, r *http.Request) {
+	var opts engine.CreateCustomEngineOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	custom, err := a.Client.CreateCustomEngine(r.Context(), opts)
+	if err != nil {
+		customEngineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(custom)
+}
+
+func (a *API) updateCustomEngine(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name string `schema:"name,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var opts engine.UpdateCustomEngineOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	custom, err := a.Client.UpdateCustomEngine(r.Context(), params.Name, opts)
+	if err != nil {
+		customEngineError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(custom)
+}
+
+func (a *API) getCustomEngine(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name string `schema:"name,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	custom, err := a.Client.GetCustomEngine(r.Context(), params.Name)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(custom)
+}
+
+func (a *API) listCustomEngines(w http.ResponseWriter, r *http.Request) {
+	engines, err := a.Client.ListCustomEngines(r.Context())
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(engines)
+}
+
+func (a *API) deleteCustomEngine(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name string `schema:"name,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if err := a.Client.DeleteCustomEngine(r.Context(), params.Name); err != nil {
+		customEngineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// customEngineError writes an error arising from registering, updating or
+// deleting a custom engine, responding with 422 if the engine is invalid.
+func customEngineError(w http.ResponseWriter, err error) {
+	var fkerr *internal.ForeignKeyError
+	switch {
+	case errors.Is(err, engine.ErrInvalidVersion),
+		errors.Is(err, engine.ErrInvalidURLTemplate),
+		errors.Is(err, engine.ErrBuiltinEngine):
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+	case errors.As(err, &fkerr):
+		tfeapi.Error(w, errors.New("engine is in use by workspaces or runs"), tfeapi.WithStatus(http.StatusConflict))
+	default:
+		tfeapi.Error(w, err)
+	}
+}
+
+// mirrorError writes an error arising from adding an engine version to the
+// mirror, responding with 422 if the engine version is invalid or could not be
+// verified.
+func mirrorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, engine.ErrInvalidVersion),
+		errors.Is(err, engine.ErrInvalidSignature),
+		errors.Is(err, engine.ErrShasumMismatch),
+		errors.Is(err, engine.ErrNoSigningKeys):
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+	default:
+		tfeapi.Error(w, err)
+	}
+}
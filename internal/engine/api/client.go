@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/leg100/otf/internal/engine"
+	otfhttp "github.com/leg100/otf/internal/http"
+)
+
+// Alias client to permit embedding it with other clients in a struct
+// without a name clash.
+type EngineClient = Client
+
+type Client struct {
+	*otfhttp.Client
+}
+
+func (c *Client) UploadMirrorVersion(ctx context.Context, opts engine.UploadMirrorVersionOptions) (*engine.MirrorVersion, error) {
+	req, err := c.NewRequest("POST", "engine-mirror/upload", &opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.doMirrorVersion(ctx, req)
+}
+
+func (c *Client) SyncMirrorVersion(ctx context.Context, opts engine.MirrorVersionOptions) (*engine.MirrorVersion, error) {
+	req, err := c.NewRequest("POST", "engine-mirror/sync", &opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.doMirrorVersion(ctx, req)
+}
+
+func (c *Client) doMirrorVersion(ctx context.Context, req *retryablehttp.Request) (*engine.MirrorVersion, error) {
+	var buf bytes.Buffer
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, err
+	}
+	var version engine.MirrorVersion
+	if err := json.Unmarshal(buf.Bytes(), &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (c *Client) ListMirrorVersions(ctx context.Context) ([]*engine.MirrorVersion, error) {
+	req, err := c.NewRequest("GET", "engine-mirror", nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, err
+	}
+	var versions []*engine.MirrorVersion
+	if err := json.Unmarshal(buf.Bytes(), &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (c *Client) DeleteMirrorVersion(ctx context.Context, opts engine.MirrorVersionOptions) error {
+	req, err := c.NewRequest("DELETE", mirrorVersionPath(opts.Engine, opts.Version, opts.OS, opts.Arch), nil)
+	if err != nil {
+		return err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+func (c *Client) DownloadEngine(ctx context.Context, engine *engine.Engine, version, os, arch string) ([]byte, error) {
+	req, err := c.NewRequest("GET", mirrorVersionPath(engine, version, os, arch)+"/download", nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func mirrorVersionPath(engine *engine.Engine, version, os, arch string) string {
+	return fmt.Sprintf("engine-mirror/%s/%s/%s/%s",
+		url.QueryEscape(engine.String()),
+		url.QueryEscape(version),
+		url.QueryEscape(os),
+		url.QueryEscape(arch),
+	)
+}
+
+func (c *Client) CreateCustomEngine(ctx context.Context, opts engine.CreateCustomEngineOptions) (*engine.CustomEngine, error) {
+	req, err := c.NewRequest("POST", "custom-engines", &opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCustomEngine(ctx, req)
+}
+
+func (c *Client) UpdateCustomEngine(ctx context.Context, name string, opts engine.UpdateCustomEngineOptions) (*engine.CustomEngine, error) {
+	req, err := c.NewRequest("PATCH", "custom-engines/"+url.QueryEscape(name), &opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCustomEngine(ctx, req)
+}
+
+func (c *Client) GetCustomEngine(ctx context.Context, name string) (*engine.CustomEngine, error) {
+	req, err := c.NewRequest("GET", "custom-engines/"+url.QueryEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.doCustomEngine(ctx, req)
+}
+
+func (c *Client) doCustomEngine(ctx context.Context, req *retryablehttp.Request) (*engine.CustomEngine, error) {
+	var buf bytes.Buffer
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, err
+	}
+	var custom engine.CustomEngine
+	if err := json.Unmarshal(buf.Bytes(), &custom); err != nil {
+		return nil, err
+	}
+	return &custom, nil
+}
+
+func (c *Client) ListCustomEngines(ctx context.Context) ([]*engine.CustomEngine, error) {
+	req, err := c.NewRequest("GET", "custom-engines", nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, err
+	}
+	var engines []*engine.CustomEngine
+	if err := json.Unmarshal(buf.Bytes(), &engines); err != nil {
+		return nil, err
+	}
+	return engines, nil
+}
+
+func (c *Client) DeleteCustomEngine(ctx context.Context, name string) error {
+	req, err := c.NewRequest("DELETE", "custom-engines/"+url.QueryEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+// GetEngine retrieves an engine by name, retrieving the details of a custom
+// engine from the server.
+func (c *Client) GetEngine(ctx context.Context, name string) (*engine.Engine, error) {
+	var e engine.Engine
+	if err := e.Set(name); err != nil {
+		return nil, err
+	}
+	if !e.Custom() {
+		return &e, nil
+	}
+	custom, err := c.GetCustomEngine(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return custom.Engine(), nil
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/leg100/otf/internal/engine"
+	engineapi "github.com/leg100/otf/internal/engine/api"
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/spf13/cobra"
+)
+
+type CLI struct {
+	client client
+}
+
+type client interface {
+	UploadMirrorVersion(ctx context.Context, opts engine.UploadMirrorVersionOptions) (*engine.MirrorVersion, error)
+	SyncMirrorVersion(ctx context.Context, opts engine.MirrorVersionOptions) (*engine.MirrorVersion, error)
+	ListMirrorVersions(ctx context.Context) ([]*engine.MirrorVersion, error)
+	DeleteMirrorVersion(ctx context.Context, opts engine.MirrorVersionOptions) error
+
+	CreateCustomEngine(ctx context.Context, opts engine.CreateCustomEngineOptions) (*engine.CustomEngine, error)
+	UpdateCustomEngine(ctx context.Context, name string, opts engine.UpdateCustomEngineOptions) (*engine.CustomEngine, error)
+	ListCustomEngines(ctx context.Context) ([]*engine.CustomEngine, error)
+	DeleteCustomEngine(ctx context.Context, name string) error
+}
+
+func NewCommand(apiClient *otfhttp.Client) *cobra.Command {
+	cli := &CLI{}
+	cmd := &cobra.Command{
+		Use:   "engines",
+		Short: "Engine mirror and custom engine management",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Parent().PersistentPreRunE(cmd.Parent(), args); err != nil {
+				return err
+			}
+			cli.client = &engineapi.Client{Client: apiClient}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(cli.uploadCommand())
+	cmd.AddCommand(cli.syncCommand())
+	cmd.AddCommand(cli.listCommand())
+	cmd.AddCommand(cli.deleteCommand())
+	cmd.AddCommand(cli.customCommand())
+
+	return cmd
+}
+
+func (a *CLI) uploadCommand() *cobra.Command {
+	var (
+		opts        engine.UploadMirrorVersionOptions
+		shasumsFile string
+		sigFile     string
+	)
+	opts.Engine = engine.Terraform()
+
+	cmd := &cobra.Command{
+		Use:   "upload [zip files...]",
+		Short: "Upload engine archives to the mirror",
+		Long: `Upload engine archives to the mirror, for use in environments without access to the engine's release site.
+
+Each zip file must follow the naming convention <engine>_<version>_<os>_<arch>.zip, e.g. terraform_1.9.0_linux_amd64.zip, and have a matching entry in the SHA256SUMS file.`,
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shasums, err := os.ReadFile(shasumsFile)
+			if err != nil {
+				return fmt.Errorf("reading SHA256SUMS: %w", err)
+			}
+			sig, err := os.ReadFile(sigFile)
+			if err != nil {
+				return fmt.Errorf("reading SHA256SUMS signature: %w", err)
+			}
+			opts.Shasums = shasums
+			opts.ShasumsSignature = sig
+
+			for _, path := range args {
+				opts.Version, opts.OS, opts.Arch, err = parseFilename(opts.Engine, filepath.Base(path))
+				if err != nil {
+					return err
+				}
+				opts.Archive, err = os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				version, err := a.client.UploadMirrorVersion(cmd.Context(), opts)
+				if err != nil {
+					return fmt.Errorf("uploading %s: %w", path, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully uploaded %s\n", version.Filename())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Var(opts.Engine, "engine", "Engine to upload: terraform, tofu, or a custom engine")
+	cmd.Flags().StringVar(&shasumsFile, "shasums", "", "Path to the SHA256SUMS file")
+	cmd.Flags().StringVar(&sigFile, "shasums-sig", "", "Path to the SHA256SUMS signature file")
+	cmd.MarkFlagRequired("shasums")
+	cmd.MarkFlagRequired("shasums-sig")
+
+	return cmd
+}
+
+func (a *CLI) syncCommand() *cobra.Command {
+	opts := engine.MirrorVersionOptions{
+		Engine: engine.Terraform(),
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+	}
+
+	cmd := &cobra.Command{
+		Use:           "sync [version]",
+		Short:         "Sync an engine version from upstream to the mirror",
+		Long:          "Instruct the server to retrieve an engine version from the engine's release site, verify its signature and add it to the mirror.",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Version = args[0]
+			version, err := a.client.SyncMirrorVersion(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully synced %s\n", version.Filename())
+			return nil
+		},
+	}
+
+	cmd.Flags().Var(opts.Engine, "engine", "Engine to sync: terraform or tofu")
+	cmd.Flags().StringVar(&opts.OS, "os", opts.OS, "Operating system of the engine binary")
+	cmd.Flags().StringVar(&opts.Arch, "arch", opts.Arch, "Architecture of the engine binary")
+
+	return cmd
+}
+
+func (a *CLI) listCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List engine archives in the mirror",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versions, err := a.client.ListMirrorVersions(cmd.Context())
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(versions) == 0 {
+				fmt.Fprintln(out, "No engine archives found")
+				return nil
+			}
+			for _, v := range versions {
+				fmt.Fprintf(out, "%s %s %s/%s %s\n", v.Engine, v.Version, v.OS, v.Arch, v.Shasum)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func (a *CLI) deleteCommand() *cobra.Command {
+	opts := engine.MirrorVersionOptions{
+		Engine: engine.Terraform(),
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+	}
+
+	cmd := &cobra.Command{
+		Use:           "delete [version]",
+		Short:         "Delete an engine archive from the mirror",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Version = args[0]
+			if err := a.client.DeleteMirrorVersion(cmd.Context(), opts); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully deleted %s %s %s/%s\n", opts.Engine, opts.Version, opts.OS, opts.Arch)
+			return nil
+		},
+	}
+
+	cmd.Flags().Var(opts.Engine, "engine", "Engine to delete: terraform, tofu, or a custom engine")
+	cmd.Flags().StringVar(&opts.OS, "os", opts.OS, "Operating system of the engine binary")
+	cmd.Flags().StringVar(&opts.Arch, "arch", opts.Arch, "Architecture of the engine binary")
+
+	return cmd
+}
+
+// parseFilename extracts the version, OS and architecture from an engine zip
+// filename, e.g. terraform_1.9.0_linux_amd64.zip.
+func parseFilename(e *engine.Engine, filename string) (string, string, string, error) {
+	prefix := e.String() + "_"
+	rest, ok := strings.CutPrefix(filename, prefix)
+	if !ok {
+		return "", "", "", fmt.Errorf("%s: filename must begin with %s", filename, prefix)
+	}
+	rest, ok = strings.CutSuffix(rest, ".zip")
+	if !ok {
+		return "", "", "", fmt.Errorf("%s: filename must end with .zip", filename)
+	}
+	parts := strings.Split(rest, "_")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("%s: filename must end with <version>_<os>_<arch>.zip", filename)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
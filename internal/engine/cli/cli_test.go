@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilename(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		version, goos, goarch, err := parseFilename(engine.Tofu(), "tofu_1.9.0_linux_arm64.zip")
+		require.NoError(t, err)
+		assert.Equal(t, "1.9.0", version)
+		assert.Equal(t, "linux", goos)
+		assert.Equal(t, "arm64", goarch)
+	})
+
+	t.Run("wrong engine", func(t *testing.T) {
+		_, _, _, err := parseFilename(engine.Terraform(), "tofu_1.9.0_linux_arm64.zip")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing arch", func(t *testing.T) {
+		_, _, _, err := parseFilename(engine.Terraform(), "terraform_1.9.0_linux.zip")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leg100/otf/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+func (a *CLI) customCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "custom",
+		Short: "Custom engine management",
+	}
+
+	cmd.AddCommand(a.createCustomCommand())
+	cmd.AddCommand(a.updateCustomCommand())
+	cmd.AddCommand(a.listCustomCommand())
+	cmd.AddCommand(a.deleteCustomCommand())
+
+	return cmd
+}
+
+func (a *CLI) createCustomCommand() *cobra.Command {
+	var (
+		opts        engine.CreateCustomEngineOptions
+		shasumsTmpl string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Register a custom engine",
+		Long: `Register a custom engine, e.g. an internal fork of terraform or a wrapper binary such as terragrunt, for selection by workspaces.
+
+The URL templates may contain the placeholders {version}, {os} and {arch}, which are substituted when the engine is downloaded.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			if shasumsTmpl != "" {
+				opts.ShasumsURLTemplate = &shasumsTmpl
+			}
+			custom, err := a.client.CreateCustomEngine(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully created engine %s\n", custom.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Versions, "versions", nil, "Versions of the engine available for selection")
+	cmd.Flags().StringVar(&opts.URLTemplate, "url-template", "", "URL template for downloading the engine, e.g. https://example.com/{version}/acme_{version}_{os}_{arch}.zip")
+	cmd.Flags().StringVar(&shasumsTmpl, "shasums-url-template", "", "URL template for the SHA256SUMS file against which downloads are verified")
+	cmd.MarkFlagRequired("versions")
+	cmd.MarkFlagRequired("url-template")
+
+	return cmd
+}
+
+func (a *CLI) updateCustomCommand() *cobra.Command {
+	var opts engine.UpdateCustomEngineOptions
+
+	cmd := &cobra.Command{
+		Use:           "update [name]",
+		Short:         "Update a custom engine",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if flags.Changed("url-template") {
+				tmpl, _ := flags.GetString("url-template")
+				opts.URLTemplate = &tmpl
+			}
+			if flags.Changed("shasums-url-template") {
+				tmpl, _ := flags.GetString("shasums-url-template")
+				opts.ShasumsURLTemplate = &tmpl
+			}
+			custom, err := a.client.UpdateCustomEngine(cmd.Context(), args[0], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully updated engine %s\n", custom.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Versions, "versions", nil, "Versions of the engine available for selection, replacing existing versions")
+	cmd.Flags().String("url-template", "", "URL template for downloading the engine")
+	cmd.Flags().String("shasums-url-template", "", "URL template for the SHA256SUMS file. Specify an empty string to disable verification.")
+
+	return cmd
+}
+
+func (a *CLI) listCustomCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List custom engines",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			engines, err := a.client.ListCustomEngines(cmd.Context())
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(engines) == 0 {
+				fmt.Fprintln(out, "No custom engines found")
+				return nil
+			}
+			for _, e := range engines {
+				fmt.Fprintf(out, "%s %s %s\n", e.Name, strings.Join(e.Versions, ","), e.URLTemplate)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func (a *CLI) deleteCustomCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "delete [name]",
+		Short:         "Delete a custom engine",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.client.DeleteCustomEngine(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully deleted engine %s\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/semver"
+)
+
+var (
+	// ErrBuiltinEngine is returned when attempting to register, update or
+	// delete a custom engine with the name of a built-in engine.
+	ErrBuiltinEngine = errors.New("cannot modify a built-in engine")
+	// ErrInvalidURLTemplate is returned when a custom engine's URL template
+	// is not a valid http(s) URL containing the {version} placeholder.
+	ErrInvalidURLTemplate = errors.New("URL template must be a http(s) URL containing the {version} placeholder")
+
+	// nameRegex validates an engine name.
+	nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+type (
+	// CustomEngine is an engine registered by the site admin in addition to
+	// the built-in engines, e.g. an internal fork of terraform or a wrapper
+	// binary such as terragrunt.
+	CustomEngine struct {
+		Name string `json:"name" db:"name"`
+		// Versions available for selection by workspaces.
+		Versions []string `json:"versions" db:"versions"`
+		// URLTemplate is the URL from which a version of the engine is
+		// downloaded, with the placeholders {version}, {os} and {arch}, e.g.
+		// https://example.com/acme/{version}/acme_{version}_{os}_{arch}.zip.
+		// The download is either a zip archive containing a binary with the
+		// same name as the engine, or the binary itself.
+		URLTemplate string `json:"url_template" db:"url_template"`
+		// ShasumsURLTemplate is the optional URL of a SHA256SUMS file listing
+		// the checksum of the download, with the same placeholders as the
+		// URLTemplate. If set then the download is verified against the
+		// checksum.
+		ShasumsURLTemplate *string   `json:"shasums_url_template,omitempty" db:"shasums_url_template"`
+		CreatedAt          time.Time `json:"created_at" db:"created_at"`
+		UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	}
+
+	CreateCustomEngineOptions struct {
+		Name               string   `json:"name"`
+		Versions           []string `json:"versions"`
+		URLTemplate        string   `json:"url_template"`
+		ShasumsURLTemplate *string  `json:"shasums_url_template,omitempty"`
+	}
+
+	UpdateCustomEngineOptions struct {
+		Versions    []string `json:"versions,omitempty"`
+		URLTemplate *string  `json:"url_template,omitempty"`
+		// ShasumsURLTemplate set to an empty string removes the template.
+		ShasumsURLTemplate *string `json:"shasums_url_template,omitempty"`
+	}
+)
+
+func newCustomEngine(opts CreateCustomEngineOptions) (*CustomEngine, error) {
+	if !nameRegex.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid engine name: %s", opts.Name)
+	}
+	if isBuiltin(opts.Name) {
+		return nil, ErrBuiltinEngine
+	}
+	engine := &CustomEngine{
+		Name:      opts.Name,
+		CreatedAt: internal.CurrentTimestamp(nil),
+		UpdatedAt: internal.CurrentTimestamp(nil),
+	}
+	if err := engine.setVersions(opts.Versions); err != nil {
+		return nil, err
+	}
+	err := engine.Update(UpdateCustomEngineOptions{
+		URLTemplate:        &opts.URLTemplate,
+		ShasumsURLTemplate: opts.ShasumsURLTemplate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+func (e *CustomEngine) Update(opts UpdateCustomEngineOptions) error {
+	if opts.Versions != nil {
+		if err := e.setVersions(opts.Versions); err != nil {
+			return err
+		}
+	}
+	if opts.URLTemplate != nil {
+		if err := validateURLTemplate(*opts.URLTemplate); err != nil {
+			return err
+		}
+		e.URLTemplate = *opts.URLTemplate
+	}
+	if opts.ShasumsURLTemplate != nil {
+		if *opts.ShasumsURLTemplate == "" {
+			e.ShasumsURLTemplate = nil
+		} else {
+			if err := validateURLTemplate(*opts.ShasumsURLTemplate); err != nil {
+				return fmt.Errorf("invalid SHA256SUMS URL template: %w", err)
+			}
+			e.ShasumsURLTemplate = opts.ShasumsURLTemplate
+		}
+	}
+	e.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// setVersions sets the versions of the engine, sorted in descending order.
+func (e *CustomEngine) setVersions(versions []string) error {
+	if len(versions) == 0 {
+		return errors.New("at least one version must be specified")
+	}
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			return fmt.Errorf("%w: %s", ErrInvalidVersion, v)
+		}
+		if semver.Compare(v, MinEngineVersion) < 0 {
+			return fmt.Errorf("%w: %s is below the minimum version %s", ErrInvalidVersion, v, MinEngineVersion)
+		}
+	}
+	versions = slices.Clone(versions)
+	slices.SortFunc(versions, func(a, b string) int {
+		return semver.Compare(b, a)
+	})
+	e.Versions = slices.Compact(versions)
+	return nil
+}
+
+// Engine returns the engine, for use by workspaces and runs.
+func (e *CustomEngine) Engine() *Engine {
+	return &Engine{
+		Name:           e.Name,
+		DefaultVersion: e.Versions[0],
+		client: &customClient{
+			versions:           e.Versions,
+			urlTemplate:        e.URLTemplate,
+			shasumsURLTemplate: e.ShasumsURLTemplate,
+		},
+	}
+}
+
+// LogValue implements slog.LogValuer.
+func (e *CustomEngine) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", e.Name),
+		slog.Any("versions", e.Versions),
+	)
+}
+
+func validateURLTemplate(tmpl string) error {
+	if !strings.Contains(tmpl, "{version}") {
+		return ErrInvalidURLTemplate
+	}
+	u, err := url.Parse(expandURLTemplate(tmpl, "1.0.0", "linux", "amd64"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURLTemplate
+	}
+	return nil
+}
+
+func expandURLTemplate(tmpl, version, os, arch string) string {
+	return strings.NewReplacer(
+		"{version}", version,
+		"{os}", os,
+		"{arch}", arch,
+	).Replace(tmpl)
+}
+
+// customClient provides access to the upstream releases of a custom engine.
+type customClient struct {
+	versions           []string // sorted in descending order
+	urlTemplate        string
+	shasumsURLTemplate *string
+}
+
+// getLatestVersion returns the highest of the engine's versions.
+func (c *customClient) getLatestVersion(context.Context) (string, error) {
+	return c.versions[0], nil
+}
+
+func (c *customClient) sourceURL(version string) *url.URL {
+	u, _ := url.Parse(expandURLTemplate(c.urlTemplate, version, runtime.GOOS, runtime.GOARCH))
+	return u
+}
+
+// releaseURL returns the URL of a file alongside the download.
+func (c *customClient) releaseURL(version, filename string) *url.URL {
+	u := c.sourceURL(version)
+	u.Path = path.Join(path.Dir(u.Path), filename)
+	return u
+}
+
+// signatureFilename returns an empty string because custom engines do not
+// publish signatures.
+func (c *customClient) signatureFilename(string) string {
+	return ""
+}
+
+// shasumsURL returns the URL of the SHA256SUMS file for the given version, or
+// nil if the engine does not publish checksums.
+func (c *customClient) shasumsURL(version string) *url.URL {
+	if c.shasumsURLTemplate == nil {
+		return nil
+	}
+	u, _ := url.Parse(expandURLTemplate(*c.shasumsURLTemplate, version, runtime.GOOS, runtime.GOARCH))
+	return u
+}
+
+func (c *customClient) checkVersion(version string) error {
+	if !slices.Contains(c.versions, version) {
+		return fmt.Errorf("%w: %s is not an available version", ErrInvalidVersion, version)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCustomEngine(t *testing.T) {
+	opts := CreateCustomEngineOptions{
+		Name:               "acme",
+		Versions:           []string{"1.8.0", "1.10.0", "1.9.0"},
+		URLTemplate:        "https://example.com/acme/{version}/acme_{version}_{os}_{arch}.zip",
+		ShasumsURLTemplate: new("https://example.com/acme/{version}/acme_{version}_SHA256SUMS"),
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := newCustomEngine(opts)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"1.10.0", "1.9.0", "1.8.0"}, got.Versions)
+
+		engine := got.Engine()
+		assert.True(t, engine.Custom())
+		assert.Equal(t, "1.10.0", engine.DefaultVersion)
+		assert.NoError(t, engine.CheckVersion("1.9.0"))
+		assert.ErrorIs(t, engine.CheckVersion("1.7.0"), ErrInvalidVersion)
+
+		want := fmt.Sprintf("https://example.com/acme/1.9.0/acme_1.9.0_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+		assert.Equal(t, want, engine.client.sourceURL("1.9.0").String())
+		c := engine.client.(checksummer)
+		assert.Equal(t, "https://example.com/acme/1.9.0/acme_1.9.0_SHA256SUMS", c.shasumsURL("1.9.0").String())
+	})
+
+	t.Run("built-in engine name", func(t *testing.T) {
+		o := opts
+		o.Name = "tofu"
+		_, err := newCustomEngine(o)
+		assert.ErrorIs(t, err, ErrBuiltinEngine)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		o := opts
+		o.Name = "Acme Engine"
+		_, err := newCustomEngine(o)
+		assert.Error(t, err)
+	})
+
+	t.Run("no versions", func(t *testing.T) {
+		o := opts
+		o.Versions = nil
+		_, err := newCustomEngine(o)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		o := opts
+		o.Versions = []string{"1.x"}
+		_, err := newCustomEngine(o)
+		assert.ErrorIs(t, err, ErrInvalidVersion)
+	})
+
+	t.Run("url template missing version", func(t *testing.T) {
+		o := opts
+		o.URLTemplate = "https://example.com/acme.zip"
+		_, err := newCustomEngine(o)
+		assert.ErrorIs(t, err, ErrInvalidURLTemplate)
+	})
+
+	t.Run("url template with invalid scheme", func(t *testing.T) {
+		o := opts
+		o.URLTemplate = "ftp://example.com/acme_{version}.zip"
+		_, err := newCustomEngine(o)
+		assert.ErrorIs(t, err, ErrInvalidURLTemplate)
+	})
+}
+
+func TestCustomEngine_Update(t *testing.T) {
+	engine, err := newCustomEngine(CreateCustomEngineOptions{
+		Name:               "acme",
+		Versions:           []string{"1.8.0"},
+		URLTemplate:        "https://example.com/acme_{version}.zip",
+		ShasumsURLTemplate: new("https://example.com/acme_{version}_SHA256SUMS"),
+	})
+	require.NoError(t, err)
+
+	err = engine.Update(UpdateCustomEngineOptions{
+		Versions:           []string{"1.8.0", "1.9.0"},
+		ShasumsURLTemplate: new(""),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1.9.0", "1.8.0"}, engine.Versions)
+	assert.Nil(t, engine.ShasumsURLTemplate)
+	assert.Equal(t, "https://example.com/acme_{version}.zip", engine.URLTemplate)
+}
+
+func TestEngine_Set(t *testing.T) {
+	var e Engine
+	require.NoError(t, e.Set("tofu"))
+	assert.False(t, e.Custom())
+
+	require.NoError(t, e.Set("acme"))
+	assert.True(t, e.Custom())
+	// any version is permitted until the custom engine is resolved
+	assert.NoError(t, e.CheckVersion("1.2.3"))
+
+	assert.Error(t, e.Set("Acme Engine"))
+}
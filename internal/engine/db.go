@@ -47,3 +47,163 @@ WHERE engine = $1
 	}
 	return version, checkpoint, nil
 }
+
+func (db *db) upsertMirrorVersion(ctx context.Context, v *MirrorVersion) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO engine_mirror_versions (
+    engine,
+    version,
+    os,
+    arch,
+    shasum,
+    created_at
+) VALUES (
+    @engine,
+    @version,
+    @os,
+    @arch,
+    @shasum,
+    @created_at
+) ON CONFLICT (engine, version, os, arch) DO UPDATE
+SET shasum     = @shasum,
+    created_at = @created_at
+`, pgx.NamedArgs{
+		"engine":     v.Engine,
+		"version":    v.Version,
+		"os":         v.OS,
+		"arch":       v.Arch,
+		"shasum":     v.Shasum,
+		"created_at": v.CreatedAt,
+	})
+	return err
+}
+
+func (db *db) getMirrorVersion(ctx context.Context, engine *Engine, version, os, arch string) (*MirrorVersion, error) {
+	rows := db.Query(ctx, `
+SELECT engine, version, os, arch, shasum, created_at
+FROM engine_mirror_versions
+WHERE engine = $1
+AND version = $2
+AND os = $3
+AND arch = $4
+`, engine, version, os, arch)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[MirrorVersion])
+}
+
+func (db *db) listMirrorVersions(ctx context.Context) ([]*MirrorVersion, error) {
+	rows := db.Query(ctx, `
+SELECT engine, version, os, arch, shasum, created_at
+FROM engine_mirror_versions
+`)
+	versions, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[MirrorVersion])
+	if err != nil {
+		return nil, err
+	}
+	sortMirrorVersions(versions)
+	return versions, nil
+}
+
+func (db *db) deleteMirrorVersion(ctx context.Context, engine *Engine, version, os, arch string) error {
+	_, err := db.Exec(ctx, `
+DELETE
+FROM engine_mirror_versions
+WHERE engine = $1
+AND version = $2
+AND os = $3
+AND arch = $4
+`, engine, version, os, arch)
+	return err
+}
+
+func (db *db) createCustomEngine(ctx context.Context, engine *CustomEngine) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO engines (
+    name,
+    versions,
+    url_template,
+    shasums_url_template,
+    created_at,
+    updated_at
+) VALUES (
+    @name,
+    @versions,
+    @url_template,
+    @shasums_url_template,
+    @created_at,
+    @updated_at
+)
+`, pgx.NamedArgs{
+		"name":                 engine.Name,
+		"versions":             engine.Versions,
+		"url_template":         engine.URLTemplate,
+		"shasums_url_template": engine.ShasumsURLTemplate,
+		"created_at":           engine.CreatedAt,
+		"updated_at":           engine.UpdatedAt,
+	})
+	return err
+}
+
+func (db *db) updateCustomEngine(ctx context.Context, name string, updateFunc func(context.Context, *CustomEngine) error) (*CustomEngine, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*CustomEngine, error) {
+			rows := db.Query(ctx, `
+SELECT name, versions, url_template, shasums_url_template, created_at, updated_at
+FROM engines
+WHERE name = $1
+AND url_template IS NOT NULL
+FOR UPDATE
+`, name)
+			return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[CustomEngine])
+		},
+		updateFunc,
+		func(ctx context.Context, engine *CustomEngine) error {
+			_, err := db.Exec(ctx, `
+UPDATE engines
+SET versions             = @versions,
+    url_template         = @url_template,
+    shasums_url_template = @shasums_url_template,
+    updated_at           = @updated_at
+WHERE name = @name
+`, pgx.NamedArgs{
+				"name":                 engine.Name,
+				"versions":             engine.Versions,
+				"url_template":         engine.URLTemplate,
+				"shasums_url_template": engine.ShasumsURLTemplate,
+				"updated_at":           engine.UpdatedAt,
+			})
+			return err
+		},
+	)
+}
+
+func (db *db) getCustomEngine(ctx context.Context, name string) (*CustomEngine, error) {
+	rows := db.Query(ctx, `
+SELECT name, versions, url_template, shasums_url_template, created_at, updated_at
+FROM engines
+WHERE name = $1
+AND url_template IS NOT NULL
+`, name)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[CustomEngine])
+}
+
+func (db *db) listCustomEngines(ctx context.Context) ([]*CustomEngine, error) {
+	rows := db.Query(ctx, `
+SELECT name, versions, url_template, shasums_url_template, created_at, updated_at
+FROM engines
+WHERE url_template IS NOT NULL
+ORDER BY name
+`)
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[CustomEngine])
+}
+
+func (db *db) deleteCustomEngine(ctx context.Context, name string) error {
+	_, err := db.Exec(ctx, `
+DELETE
+FROM engines
+WHERE name = $1
+AND url_template IS NOT NULL
+`, name)
+	return err
+}
@@ -3,11 +3,16 @@ package engine
 import (
 	"archive/zip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/sdassow/atomic"
 )
@@ -19,17 +24,35 @@ type download struct {
 
 	version   string
 	src, dest string
-	binary    string
-	client    *http.Client
+	// shasumsSrc is the optional URL of a SHA256SUMS file against which the
+	// download is verified.
+	shasumsSrc string
+	binary     string
+	engine     *Engine
+	client     *http.Client
+	mirror     Mirror
 }
 
 func (d *download) download(ctx context.Context) error {
-	zipfile, err := d.getZipfile(ctx)
+	getZipfile := d.getZipfile
+	if d.mirror != nil {
+		getZipfile = d.getZipfileFromMirror
+	}
+	zipfile, err := getZipfile(ctx)
 	if err != nil {
+		if d.mirror != nil {
+			return fmt.Errorf("downloading zipfile from engine mirror: %w", err)
+		}
 		return fmt.Errorf("downloading zipfile from %s: %w", d.src, err)
 	}
 	defer os.Remove(zipfile)
 
+	if d.mirror == nil && d.shasumsSrc != "" {
+		if err := d.verify(ctx, zipfile); err != nil {
+			return fmt.Errorf("verifying download: %w", err)
+		}
+	}
+
 	if err := os.MkdirAll(filepath.Dir(d.dest), 0o777); err != nil {
 		return fmt.Errorf("creating directory: %w", err)
 	}
@@ -42,19 +65,90 @@ func (d *download) download(ctx context.Context) error {
 }
 
 func (d *download) getZipfile(ctx context.Context) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", d.src, nil)
+	res, err := d.get(ctx, d.src)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	tmp, err := os.CreateTemp("", "engine-download-*")
+	if err != nil {
+		return "", fmt.Errorf("creating placeholder for download: %w", err)
+	}
+	defer tmp.Close()
+
+	fmt.Fprintf(d, "downloading %s, version %s\n", d.binary, d.version)
+
+	_, err = io.Copy(tmp, res.Body)
+	if err != nil {
+		return "", fmt.Errorf("copying to disk: %w", err)
+	}
+
+	return tmp.Name(), nil
+}
+
+func (d *download) get(ctx context.Context, src string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", src, nil)
 	if err != nil {
-		return "", fmt.Errorf("building request: %w", err)
+		return nil, fmt.Errorf("building request: %w", err)
 	}
 
 	res, err := d.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("sending request: %w", err)
+		return nil, fmt.Errorf("sending request: %w", err)
 	}
-	defer res.Body.Close()
 
 	if res.StatusCode != 200 {
-		return "", fmt.Errorf("received non-200 HTTP code: %d", res.StatusCode)
+		res.Body.Close()
+		return nil, fmt.Errorf("received non-200 HTTP code: %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+// verify verifies the checksum of the downloaded file matches its entry in the
+// SHA256SUMS file.
+func (d *download) verify(ctx context.Context, path string) error {
+	res, err := d.get(ctx, d.shasumsSrc)
+	if err != nil {
+		return fmt.Errorf("retrieving %s: %w", d.shasumsSrc, err)
+	}
+	defer res.Body.Close()
+
+	shasums, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(d.src)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Base(u.Path)
+	expected, ok := parseShasums(shasums)[filename]
+	if !ok {
+		return fmt.Errorf("%w: no entry for %s", ErrShasumMismatch, filename)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != expected {
+		return fmt.Errorf("%w: %s", ErrShasumMismatch, filename)
+	}
+	return nil
+}
+
+func (d *download) getZipfileFromMirror(ctx context.Context) (string, error) {
+	fmt.Fprintf(d, "downloading %s, version %s, from engine mirror\n", d.binary, d.version)
+
+	archive, err := d.mirror.DownloadEngine(ctx, d.engine, d.version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
 	}
 
 	tmp, err := os.CreateTemp("", "engine-download-*")
@@ -63,11 +157,8 @@ func (d *download) getZipfile(ctx context.Context) (string, error) {
 	}
 	defer tmp.Close()
 
-	fmt.Fprintf(d, "downloading %s, version %s\n", d.binary, d.version)
-
-	_, err = io.Copy(tmp, res.Body)
-	if err != nil {
-		return "", fmt.Errorf("copying to disk: %w", err)
+	if _, err := tmp.Write(archive); err != nil {
+		return "", fmt.Errorf("writing to disk: %w", err)
 	}
 
 	return tmp.Name(), nil
@@ -75,7 +166,18 @@ func (d *download) getZipfile(ctx context.Context) (string, error) {
 
 func (d *download) unzip(zipfile string) error {
 	zr, err := zip.OpenReader(zipfile)
-	if err != nil {
+	if errors.Is(err, zip.ErrFormat) {
+		// Not a zip archive: the download is the binary itself.
+		f, err := os.Open(zipfile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := atomic.WriteFile(d.dest, f, atomic.DefaultFileMode(0o755)); err != nil {
+			return fmt.Errorf("writing binary: %w", err)
+		}
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("opening archive: %s: %w", zipfile, err)
 	}
 	defer zr.Close()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -23,13 +24,26 @@ type downloader struct {
 	client  *http.Client // client for downloading from server via http
 	lock    *flock.Flock // ensures only one download at a time
 	engine  *Engine
+	mirror  Mirror
 	logger  logr.Logger
 }
 
+// Mirror provides engine archives from otfd's engine mirror.
+type Mirror interface {
+	DownloadEngine(ctx context.Context, engine *Engine, version, os, arch string) ([]byte, error)
+}
+
+// checksummer is implemented by engine clients that publish the checksums of
+// their downloads.
+type checksummer interface {
+	shasumsURL(version string) *url.URL
+}
+
 // NewDownloader constructs a terraform downloader, with destdir set as the
 // parent directory into which the binaries are downloaded. Pass an empty string
-// to use a default.
-func NewDownloader(logger logr.Logger, engine *Engine, destdir string) (*downloader, error) {
+// to use a default. If mirror is non-nil then binaries are downloaded from the
+// mirror rather than from upstream.
+func NewDownloader(logger logr.Logger, engine *Engine, destdir string, mirror Mirror) (*downloader, error) {
 	if destdir == "" {
 		destdir = DefaultBinDir
 	}
@@ -44,6 +58,7 @@ func NewDownloader(logger logr.Logger, engine *Engine, destdir string) (*downloa
 		client:  &http.Client{},
 		lock:    flock.New(lockFile),
 		engine:  engine,
+		mirror:  mirror,
 		logger:  logger.WithValues("component", "engine-downloader"),
 	}, nil
 }
@@ -73,14 +88,22 @@ func (d *downloader) Download(ctx context.Context, version string, w io.Writer)
 		return d.dest(version), nil
 	}
 
-	err = (&download{
+	dl := &download{
 		Writer:  w,
 		version: version,
 		src:     d.engine.client.sourceURL(version).String(),
 		dest:    d.dest(version),
 		binary:  d.engine.String(),
+		engine:  d.engine,
 		client:  d.client,
-	}).download(ctx)
+		mirror:  d.mirror,
+	}
+	if c, ok := d.engine.client.(checksummer); ok {
+		if u := c.shasumsURL(version); u != nil {
+			dl.shasumsSrc = u.String()
+		}
+	}
+	err = dl.download(ctx)
 
 	return d.dest(version), err
 }
@@ -2,7 +2,10 @@ package engine
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -42,7 +45,7 @@ func TestDownloader(t *testing.T) {
 		client: &fakeEngineClient{u: u},
 	}
 
-	dl, err := NewDownloader(logr.Discard(), engine, t.TempDir())
+	dl, err := NewDownloader(logr.Discard(), engine, t.TempDir(), nil)
 	require.NoError(t, err)
 	dl.client = &http.Client{Transport: otfhttp.InsecureTransport}
 
@@ -66,3 +69,52 @@ func TestDownloader(t *testing.T) {
 	assert.Equal(t, "I am a fake terraform binary\n", string(tfbin))
 	assert.Equal(t, "", buf.String())
 }
+
+func TestDownloader_CustomEngine(t *testing.T) {
+	binary := []byte("I am a fake acme binary\n")
+	sum := sha256.Sum256(binary)
+	filename := fmt.Sprintf("acme_1.2.3_%s_%s", runtime.GOOS, runtime.GOARCH)
+
+	// setup web server serving the raw binary along with a SHA256SUMS file.
+	shasums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filename)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/acme/1.2.3/"+filename, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	mux.HandleFunc("/acme/1.2.3/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(shasums))
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	custom, err := newCustomEngine(CreateCustomEngineOptions{
+		Name:               "acme",
+		Versions:           []string{"1.2.3"},
+		URLTemplate:        srv.URL + "/acme/{version}/acme_{version}_{os}_{arch}",
+		ShasumsURLTemplate: new(srv.URL + "/acme/{version}/SHA256SUMS"),
+	})
+	require.NoError(t, err)
+
+	t.Run("verified", func(t *testing.T) {
+		dl, err := NewDownloader(logr.Discard(), custom.Engine(), t.TempDir(), nil)
+		require.NoError(t, err)
+		dl.client = &http.Client{Transport: otfhttp.InsecureTransport}
+
+		path, err := dl.Download(t.Context(), "1.2.3", io.Discard)
+		require.NoError(t, err)
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, binary, got)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		shasums = fmt.Sprintf("%s  %s\n", hex.EncodeToString(make([]byte, 32)), filename)
+
+		dl, err := NewDownloader(logr.Discard(), custom.Engine(), t.TempDir(), nil)
+		require.NoError(t, err)
+		dl.client = &http.Client{Transport: otfhttp.InsecureTransport}
+
+		_, err = dl.Download(t.Context(), "1.2.3", io.Discard)
+		assert.ErrorIs(t, err, ErrShasumMismatch)
+	})
+}
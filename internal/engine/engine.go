@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 )
 
 // MinEngineVersion specifies the minimum engine version accepted by OTF.
@@ -27,6 +28,7 @@ var (
 	ErrInvalidVersion = errors.New("invalid engine version")
 )
 
+// Engines returns the built-in engines.
 func Engines() []*Engine {
 	return []*Engine{
 		Terraform(),
@@ -49,10 +51,28 @@ type Client interface {
 	// sourceURL returns the URL for retrieving a given version of the engine
 	// binary.
 	sourceURL(version string) *url.URL
+	// releaseURL returns the URL for retrieving a file belonging to a given
+	// release of the engine, e.g. a zip archive or SHA256SUMS file.
+	releaseURL(version, filename string) *url.URL
+	// signatureFilename returns the filename of the detached GPG signature of
+	// the SHA256SUMS file for a given release of the engine.
+	signatureFilename(version string) string
 }
 
 func (e *Engine) String() string { return e.Name }
 
+// ArchiveFilename returns the filename of the zip archive containing the engine
+// binary for the given version, OS and architecture.
+func (e *Engine) ArchiveFilename(version, os, arch string) string {
+	return fmt.Sprintf("%s_%s_%s_%s.zip", e.Name, version, os, arch)
+}
+
+// shasumsFilename returns the filename of the file listing the SHA256
+// checksums of the archives for the given version.
+func (e *Engine) shasumsFilename(version string) string {
+	return fmt.Sprintf("%s_%s_SHA256SUMS", e.Name, version)
+}
+
 func (e *Engine) Type() string { return "engine" }
 
 func (e *Engine) Set(v string) error {
@@ -85,6 +105,25 @@ func (e *Engine) Value() (driver.Value, error) {
 	return e.Name, nil
 }
 
+// Custom returns true if the engine is a custom engine rather than a built-in
+// engine.
+func (e *Engine) Custom() bool {
+	return !isBuiltin(e.Name)
+}
+
+// CheckVersion checks whether the version is available for selection. Any
+// version is available for the built-in engines, whereas a custom engine
+// permits only its listed versions.
+//
+// NOTE: a custom engine must first be resolved via the service, otherwise any
+// version is permitted.
+func (e *Engine) CheckVersion(version string) error {
+	if c, ok := e.client.(*customClient); ok {
+		return c.checkVersion(version)
+	}
+	return nil
+}
+
 func (e *Engine) set(v string) error {
 	switch v {
 	case "terraform":
@@ -92,7 +131,18 @@ func (e *Engine) set(v string) error {
 	case "tofu":
 		*e = *Tofu()
 	default:
-		return fmt.Errorf("no engine found named %s: must be either 'terraform' or 'tofu'", v)
+		// A custom engine, the details of which are retrieved from the
+		// database by the service.
+		if !nameRegex.MatchString(v) {
+			return fmt.Errorf("invalid engine name: %s", v)
+		}
+		*e = Engine{Name: v}
 	}
 	return nil
 }
+
+func isBuiltin(name string) bool {
+	return slices.ContainsFunc(Engines(), func(e *Engine) bool {
+		return e.Name == name
+	})
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/semver"
+	"golang.org/x/crypto/openpgp"
+)
+
+var (
+	// ErrNoSigningKeys is returned when attempting to add an engine version to
+	// the mirror without any trusted signing keys having been configured.
+	ErrNoSigningKeys = errors.New("no trusted engine signing keys configured")
+	// ErrInvalidSignature is returned when the signature of an engine
+	// version's SHA256SUMS file cannot be verified with the trusted signing
+	// keys.
+	ErrInvalidSignature = errors.New("SHA256SUMS signature cannot be verified with trusted signing keys")
+	// ErrShasumMismatch is returned when an engine archive does not match its
+	// entry in the SHA256SUMS file.
+	ErrShasumMismatch = errors.New("engine archive checksum does not match SHA256SUMS")
+
+	// platformRegex validates an OS or architecture, e.g. linux or amd64.
+	platformRegex = regexp.MustCompile(`^[a-z0-9]+$`)
+)
+
+type (
+	// MirrorVersion is an engine archive for a specific version, OS and
+	// architecture, hosted by otfd's engine mirror for download by runners.
+	MirrorVersion struct {
+		Engine    string    `json:"engine" db:"engine"`
+		Version   string    `json:"version" db:"version"`
+		OS        string    `json:"os" db:"os"`
+		Arch      string    `json:"arch" db:"arch"`
+		Shasum    string    `json:"shasum" db:"shasum"`
+		CreatedAt time.Time `json:"created_at" db:"created_at"`
+	}
+
+	// MirrorVersionOptions identify an engine archive in the mirror.
+	MirrorVersionOptions struct {
+		Engine  *Engine `json:"engine"`
+		Version string  `json:"version"`
+		OS      string  `json:"os"`
+		Arch    string  `json:"arch"`
+	}
+
+	// UploadMirrorVersionOptions are options for uploading an engine archive to
+	// the mirror.
+	UploadMirrorVersionOptions struct {
+		MirrorVersionOptions
+
+		// Archive is the zip archive containing the engine binary.
+		Archive []byte `json:"archive"`
+		// Shasums is the release's SHA256SUMS file, which must contain an
+		// entry for the archive.
+		Shasums []byte `json:"shasums"`
+		// ShasumsSignature is the detached GPG signature of the SHA256SUMS
+		// file, either binary or ASCII-armored.
+		ShasumsSignature []byte `json:"shasums_signature"`
+	}
+)
+
+func newMirrorVersion(keyring openpgp.EntityList, opts UploadMirrorVersionOptions) (*MirrorVersion, error) {
+	if opts.Engine == nil {
+		return nil, errors.New("engine is required")
+	}
+	if !semver.IsValid(opts.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if !platformRegex.MatchString(opts.OS) || !platformRegex.MatchString(opts.Arch) {
+		return nil, fmt.Errorf("invalid platform: %s_%s", opts.OS, opts.Arch)
+	}
+	if err := verifyShasums(keyring, opts.Shasums, opts.ShasumsSignature); err != nil {
+		return nil, err
+	}
+	filename := opts.Engine.ArchiveFilename(opts.Version, opts.OS, opts.Arch)
+	expected, ok := parseShasums(opts.Shasums)[filename]
+	if !ok {
+		return nil, fmt.Errorf("%w: no entry for %s", ErrShasumMismatch, filename)
+	}
+	sum := sha256.Sum256(opts.Archive)
+	shasum := hex.EncodeToString(sum[:])
+	if expected != shasum {
+		return nil, fmt.Errorf("%w: %s", ErrShasumMismatch, filename)
+	}
+	return &MirrorVersion{
+		Engine:    opts.Engine.String(),
+		Version:   opts.Version,
+		OS:        opts.OS,
+		Arch:      opts.Arch,
+		Shasum:    shasum,
+		CreatedAt: internal.CurrentTimestamp(nil),
+	}, nil
+}
+
+// Filename returns the filename of the engine archive.
+func (v *MirrorVersion) Filename() string {
+	return fmt.Sprintf("%s_%s_%s_%s.zip", v.Engine, v.Version, v.OS, v.Arch)
+}
+
+// LogValue implements slog.LogValuer.
+func (v *MirrorVersion) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("engine", v.Engine),
+		slog.String("version", v.Version),
+		slog.String("os", v.OS),
+		slog.String("arch", v.Arch),
+	)
+}
+
+// ReadKeyring reads a file containing one or more ASCII-armored GPG public
+// keys trusted to sign engine releases.
+func ReadKeyring(path string) (openpgp.EntityList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keyring, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading engine signing keys: %w", err)
+	}
+	return keyring, nil
+}
+
+// verifyShasums verifies the signature of a SHA256SUMS file using the trusted
+// signing keys.
+func verifyShasums(keyring openpgp.EntityList, shasums, sig []byte) error {
+	if len(keyring) == 0 {
+		return ErrNoSigningKeys
+	}
+	_, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(shasums), bytes.NewReader(sig))
+	if err == nil {
+		return nil
+	}
+	// The signature may instead be ASCII-armored.
+	if _, armoredErr := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(shasums), bytes.NewReader(sig)); armoredErr == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+}
+
+// parseShasums parses a SHA256SUMS file, returning a map of filename to
+// checksum.
+func parseShasums(shasums []byte) map[string]string {
+	m := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(shasums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		m[fields[1]] = fields[0]
+	}
+	return m
+}
+
+// sortMirrorVersions sorts mirror versions by engine, then in descending order
+// of version, and then by platform.
+func sortMirrorVersions(versions []*MirrorVersion) {
+	slices.SortFunc(versions, func(a, b *MirrorVersion) int {
+		if n := strings.Compare(a.Engine, b.Engine); n != 0 {
+			return n
+		}
+		if n := semver.Compare(b.Version, a.Version); n != 0 {
+			return n
+		}
+		if n := strings.Compare(a.OS, b.OS); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Arch, b.Arch)
+	})
+}
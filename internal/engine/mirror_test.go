@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestNewMirrorVersion(t *testing.T) {
+	signer, err := openpgp.NewEntity("otf", "", "otf@example.com", nil)
+	require.NoError(t, err)
+	stranger, err := openpgp.NewEntity("stranger", "", "stranger@example.com", nil)
+	require.NoError(t, err)
+
+	archive := []byte("zip contents")
+	sum := sha256.Sum256(archive)
+	shasums := fmt.Appendf(nil, "%s  terraform_1.9.0_linux_amd64.zip\n", hex.EncodeToString(sum[:]))
+
+	sign := func(t *testing.T, entity *openpgp.Entity, armored bool) []byte {
+		var buf bytes.Buffer
+		if armored {
+			require.NoError(t, openpgp.ArmoredDetachSign(&buf, entity, bytes.NewReader(shasums), nil))
+		} else {
+			require.NoError(t, openpgp.DetachSign(&buf, entity, bytes.NewReader(shasums), nil))
+		}
+		return buf.Bytes()
+	}
+	opts := func(sig []byte) UploadMirrorVersionOptions {
+		return UploadMirrorVersionOptions{
+			MirrorVersionOptions: MirrorVersionOptions{
+				Engine:  Terraform(),
+				Version: "1.9.0",
+				OS:      "linux",
+				Arch:    "amd64",
+			},
+			Archive:          archive,
+			Shasums:          shasums,
+			ShasumsSignature: sig,
+		}
+	}
+	keyring := openpgp.EntityList{signer}
+
+	t.Run("binary signature", func(t *testing.T) {
+		got, err := newMirrorVersion(keyring, opts(sign(t, signer, false)))
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(sum[:]), got.Shasum)
+		assert.Equal(t, "terraform_1.9.0_linux_amd64.zip", got.Filename())
+	})
+
+	t.Run("armored signature", func(t *testing.T) {
+		_, err := newMirrorVersion(keyring, opts(sign(t, signer, true)))
+		require.NoError(t, err)
+	})
+
+	t.Run("untrusted signature", func(t *testing.T) {
+		_, err := newMirrorVersion(keyring, opts(sign(t, stranger, false)))
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("no signing keys", func(t *testing.T) {
+		_, err := newMirrorVersion(nil, opts(sign(t, signer, false)))
+		assert.ErrorIs(t, err, ErrNoSigningKeys)
+	})
+
+	t.Run("shasum mismatch", func(t *testing.T) {
+		o := opts(sign(t, signer, false))
+		o.Archive = []byte("tampered")
+		_, err := newMirrorVersion(keyring, o)
+		assert.ErrorIs(t, err, ErrShasumMismatch)
+	})
+
+	t.Run("missing shasum entry", func(t *testing.T) {
+		o := opts(sign(t, signer, false))
+		o.Arch = "arm64"
+		_, err := newMirrorVersion(keyring, o)
+		assert.ErrorIs(t, err, ErrShasumMismatch)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		o := opts(sign(t, signer, false))
+		o.Version = "latest"
+		_, err := newMirrorVersion(keyring, o)
+		assert.ErrorIs(t, err, ErrInvalidVersion)
+	})
+}
@@ -3,11 +3,18 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
+	"golang.org/x/crypto/openpgp"
 )
 
 type (
@@ -16,21 +23,35 @@ type (
 	EngineService = Service
 
 	Service struct {
-		logger logr.Logger
-		db     *db
+		logger     logr.Logger
+		authorizer *authz.Authorizer
+		db         *db
+		blobs      blob.Store
+		keyring    openpgp.EntityList
+		// client for retrieving engine releases from upstream
+		client *http.Client
 	}
 
 	Options struct {
-		Logger logr.Logger
-		DB     *sql.DB
-		BinDir string // destination directory for binaries
+		Logger     logr.Logger
+		DB         *sql.DB
+		BinDir     string // destination directory for binaries
+		Authorizer *authz.Authorizer
+		BlobStore  blob.Store
+		// Keyring contains the GPG public keys trusted to sign the SHA256SUMS
+		// files of engine releases added to the mirror.
+		Keyring openpgp.EntityList
 	}
 )
 
 func NewService(opts Options) *Service {
 	return &Service{
-		logger: opts.Logger,
-		db:     &db{opts.DB},
+		logger:     opts.Logger,
+		authorizer: opts.Authorizer,
+		db:         &db{opts.DB},
+		blobs:      opts.BlobStore,
+		keyring:    opts.Keyring,
+		client:     &http.Client{},
 	}
 }
 
@@ -38,6 +59,15 @@ func NewService(opts Options) *Service {
 // fetched; if it has not yet been fetched then the default version is returned
 // instead along with zero time.
 func (s *Service) GetLatest(ctx context.Context, engine *Engine) (string, time.Time, error) {
+	if engine.Custom() {
+		// The latest version of a custom engine is the highest of its
+		// registered versions.
+		custom, err := s.db.getCustomEngine(ctx, engine.Name)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return custom.Versions[0], time.Time{}, nil
+	}
 	latest, checkpoint, err := s.db.getLatest(ctx, engine)
 	if errors.Is(err, internal.ErrResourceNotFound) {
 		// no latest version has yet been persisted to the database so return
@@ -52,3 +82,269 @@ func (s *Service) GetLatest(ctx context.Context, engine *Engine) (string, time.T
 func (s *Service) UpdateLatestVersion(ctx context.Context, engine *Engine, version string) error {
 	return s.db.updateLatestVersion(ctx, engine, version)
 }
+
+// GetEngine retrieves an engine by name, either a built-in engine or a custom
+// engine. Engine details are needed by all runners and are not sensitive, so no
+// authorization is performed.
+func (s *Service) GetEngine(ctx context.Context, name string) (*Engine, error) {
+	var engine Engine
+	if err := engine.set(name); err != nil {
+		return nil, err
+	}
+	if isBuiltin(name) {
+		return &engine, nil
+	}
+	custom, err := s.db.getCustomEngine(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving engine %s: %w", name, err)
+	}
+	return custom.Engine(), nil
+}
+
+// ListEngines lists the built-in engines followed by the custom engines.
+func (s *Service) ListEngines(ctx context.Context) ([]*Engine, error) {
+	custom, err := s.db.listCustomEngines(ctx)
+	if err != nil {
+		s.logger.Error(err, "listing engines")
+		return nil, err
+	}
+	engines := Engines()
+	for _, c := range custom {
+		engines = append(engines, c.Engine())
+	}
+	return engines, nil
+}
+
+// CreateCustomEngine registers a custom engine. Only the site admin can
+// register engines.
+func (s *Service) CreateCustomEngine(ctx context.Context, opts CreateCustomEngineOptions) (*CustomEngine, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.Create, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := newCustomEngine(opts)
+	if err != nil {
+		s.logger.Error(err, "constructing custom engine", "name", opts.Name, "subject", subject)
+		return nil, err
+	}
+	if err := s.db.createCustomEngine(ctx, engine); err != nil {
+		s.logger.Error(err, "creating custom engine", "engine", engine, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(0).Info("created custom engine", "engine", engine, "subject", subject)
+	return engine, nil
+}
+
+// UpdateCustomEngine updates a custom engine. Only the site admin can update
+// engines.
+func (s *Service) UpdateCustomEngine(ctx context.Context, name string, opts UpdateCustomEngineOptions) (*CustomEngine, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.Update, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := s.db.updateCustomEngine(ctx, name, func(ctx context.Context, engine *CustomEngine) error {
+		return engine.Update(opts)
+	})
+	if err != nil {
+		s.logger.Error(err, "updating custom engine", "name", name, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(0).Info("updated custom engine", "engine", engine, "subject", subject)
+	return engine, nil
+}
+
+// GetCustomEngine retrieves a custom engine. Engine details are needed by all
+// runners and are not sensitive, so no authorization is performed.
+func (s *Service) GetCustomEngine(ctx context.Context, name string) (*CustomEngine, error) {
+	engine, err := s.db.getCustomEngine(ctx, name)
+	if err != nil {
+		s.logger.Error(err, "retrieving custom engine", "name", name)
+		return nil, err
+	}
+	s.logger.V(9).Info("retrieved custom engine", "engine", engine)
+	return engine, nil
+}
+
+// ListCustomEngines lists custom engines. Engine details are needed by all
+// runners and are not sensitive, so no authorization is performed.
+func (s *Service) ListCustomEngines(ctx context.Context) ([]*CustomEngine, error) {
+	engines, err := s.db.listCustomEngines(ctx)
+	if err != nil {
+		s.logger.Error(err, "listing custom engines")
+		return nil, err
+	}
+	s.logger.V(9).Info("listed custom engines", "count", len(engines))
+	return engines, nil
+}
+
+// DeleteCustomEngine deletes a custom engine. An engine cannot be deleted
+// whilst it is used by workspaces or runs. Only the site admin can delete
+// engines.
+func (s *Service) DeleteCustomEngine(ctx context.Context, name string) error {
+	subject, err := s.authorizer.Authorize(ctx, resource.Delete, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return err
+	}
+	if isBuiltin(name) {
+		return ErrBuiltinEngine
+	}
+	if err := s.db.deleteCustomEngine(ctx, name); err != nil {
+		s.logger.Error(err, "deleting custom engine", "name", name, "subject", subject)
+		return err
+	}
+	s.logger.V(0).Info("deleted custom engine", "name", name, "subject", subject)
+	return nil
+}
+
+// UploadMirrorVersion adds an engine archive to the mirror. The signature of
+// the SHA256SUMS file is verified using the trusted signing keys and the
+// archive's checksum must match its entry in the file. Only the site admin can
+// upload engine archives.
+func (s *Service) UploadMirrorVersion(ctx context.Context, opts UploadMirrorVersionOptions) (*MirrorVersion, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.Create, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.uploadMirrorVersion(ctx, opts)
+	if err != nil {
+		s.logger.Error(err, "uploading engine to mirror", "engine", opts.Engine, "version", opts.Version, "os", opts.OS, "arch", opts.Arch, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(0).Info("uploaded engine to mirror", "mirror_version", version, "bytes", len(opts.Archive), "subject", subject)
+	return version, nil
+}
+
+// SyncMirrorVersion retrieves an engine archive along with its SHA256SUMS file
+// and signature from the engine's upstream release site and adds it to the
+// mirror. Only the site admin can sync engine archives.
+func (s *Service) SyncMirrorVersion(ctx context.Context, opts MirrorVersionOptions) (*MirrorVersion, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.Create, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := func() (*MirrorVersion, error) {
+		if opts.Engine == nil {
+			return nil, errors.New("engine is required")
+		}
+		if opts.Engine.Custom() {
+			return nil, errors.New("custom engines do not publish signed releases and must be uploaded instead")
+		}
+		upload := UploadMirrorVersionOptions{MirrorVersionOptions: opts}
+		upload.Archive, err = s.fetch(ctx, opts.Engine, opts.Version, opts.Engine.ArchiveFilename(opts.Version, opts.OS, opts.Arch))
+		if err != nil {
+			return nil, err
+		}
+		upload.Shasums, err = s.fetch(ctx, opts.Engine, opts.Version, opts.Engine.shasumsFilename(opts.Version))
+		if err != nil {
+			return nil, err
+		}
+		upload.ShasumsSignature, err = s.fetch(ctx, opts.Engine, opts.Version, opts.Engine.client.signatureFilename(opts.Version))
+		if err != nil {
+			return nil, err
+		}
+		return s.uploadMirrorVersion(ctx, upload)
+	}()
+	if err != nil {
+		s.logger.Error(err, "syncing engine to mirror", "engine", opts.Engine, "version", opts.Version, "os", opts.OS, "arch", opts.Arch, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(0).Info("synced engine to mirror", "mirror_version", version, "subject", subject)
+	return version, nil
+}
+
+func (s *Service) uploadMirrorVersion(ctx context.Context, opts UploadMirrorVersionOptions) (*MirrorVersion, error) {
+	version, err := newMirrorVersion(s.keyring, opts)
+	if err != nil {
+		return nil, err
+	}
+	key := blob.EngineArchiveKey(version.Engine, version.Version, version.Filename())
+	if err := s.blobs.Put(ctx, key, opts.Archive); err != nil {
+		return nil, err
+	}
+	if err := s.db.upsertMirrorVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// fetch retrieves a file belonging to an engine release from upstream.
+func (s *Service) fetch(ctx context.Context, engine *Engine, version, filename string) ([]byte, error) {
+	u := engine.client.releaseURL(version, filename).String()
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("retrieving %s: received non-200 HTTP code: %d", u, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ListMirrorVersions lists the engine archives in the mirror. Only the site
+// admin can list engine archives.
+func (s *Service) ListMirrorVersions(ctx context.Context) ([]*MirrorVersion, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.List, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.db.listMirrorVersions(ctx)
+	if err != nil {
+		s.logger.Error(err, "listing mirrored engines", "subject", subject)
+		return nil, err
+	}
+	s.logger.V(9).Info("listed mirrored engines", "count", len(versions), "subject", subject)
+	return versions, nil
+}
+
+// DeleteMirrorVersion deletes an engine archive from the mirror. Only the site
+// admin can delete engine archives.
+func (s *Service) DeleteMirrorVersion(ctx context.Context, opts MirrorVersionOptions) error {
+	subject, err := s.authorizer.Authorize(ctx, resource.Delete, resource.EngineKind, resource.SiteID)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		version, err := s.db.getMirrorVersion(ctx, opts.Engine, opts.Version, opts.OS, opts.Arch)
+		if err != nil {
+			return err
+		}
+		if err := s.db.deleteMirrorVersion(ctx, opts.Engine, opts.Version, opts.OS, opts.Arch); err != nil {
+			return err
+		}
+		return s.blobs.Delete(ctx, blob.EngineArchiveKey(version.Engine, version.Version, version.Filename()))
+	}()
+	if err != nil {
+		s.logger.Error(err, "deleting mirrored engine", "engine", opts.Engine, "version", opts.Version, "os", opts.OS, "arch", opts.Arch, "subject", subject)
+		return err
+	}
+	s.logger.V(0).Info("deleted mirrored engine", "engine", opts.Engine, "version", opts.Version, "os", opts.OS, "arch", opts.Arch, "subject", subject)
+	return nil
+}
+
+// DownloadEngine downloads an engine archive from the mirror. Engine archives
+// are publicly available upstream, so any authenticated subject, including
+// runners and jobs, may download them.
+func (s *Service) DownloadEngine(ctx context.Context, engine *Engine, version, os, arch string) ([]byte, error) {
+	subject, err := authz.SubjectFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := func() ([]byte, error) {
+		mv, err := s.db.getMirrorVersion(ctx, engine, version, os, arch)
+		if err != nil {
+			return nil, err
+		}
+		return s.blobs.Get(ctx, blob.EngineArchiveKey(mv.Engine, mv.Version, mv.Filename()))
+	}()
+	if err != nil {
+		s.logger.Error(err, "downloading mirrored engine", "engine", engine, "version", version, "os", os, "arch", arch, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(9).Info("downloaded mirrored engine", "engine", engine, "version", version, "os", os, "arch", arch, "subject", subject)
+	return archive, nil
+}
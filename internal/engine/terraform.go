@@ -31,16 +31,21 @@ type terraformClient struct {
 }
 
 func (f *terraformClient) sourceURL(version string) *url.URL {
+	return f.releaseURL(version, fmt.Sprintf("terraform_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH))
+}
+
+func (f *terraformClient) releaseURL(version, filename string) *url.URL {
 	return &url.URL{
 		Scheme: "https",
 		Host:   hashicorpReleasesHost,
-		Path: path.Join(
-			"terraform",
-			version,
-			fmt.Sprintf("terraform_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH)),
+		Path:   path.Join("terraform", version, filename),
 	}
 }
 
+func (f *terraformClient) signatureFilename(version string) string {
+	return fmt.Sprintf("terraform_%s_SHA256SUMS.sig", version)
+}
+
 // getLatestVersion retrieves the latest version string for terraform, following
 // semver syntax (e.g. 1.9.0)
 func (f *terraformClient) getLatestVersion(ctx context.Context) (string, error) {
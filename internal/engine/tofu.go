@@ -27,6 +27,10 @@ type tofuClient struct {
 }
 
 func (t *tofuClient) sourceURL(version string) *url.URL {
+	return t.releaseURL(version, fmt.Sprintf("tofu_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH))
+}
+
+func (t *tofuClient) releaseURL(version, filename string) *url.URL {
 	return &url.URL{
 		Scheme: "https",
 		Host:   "github.com",
@@ -36,10 +40,14 @@ func (t *tofuClient) sourceURL(version string) *url.URL {
 			"releases",
 			"download",
 			fmt.Sprintf("v%s", version),
-			fmt.Sprintf("tofu_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH)),
+			filename),
 	}
 }
 
+func (t *tofuClient) signatureFilename(version string) string {
+	return fmt.Sprintf("tofu_%s_SHA256SUMS.gpgsig", version)
+}
+
 func (t *tofuClient) getLatestVersion(ctx context.Context) (string, error) {
 	client := github.NewClient(nil)
 	if t.endpoint != nil {
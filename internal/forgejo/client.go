@@ -177,9 +177,10 @@ func vcsOptToStringSlice(opts vcs.CreateWebhookOptions) ([]string, error) {
 	rv := make([]string, 0, len(opts.Events))
 	for _, event := range opts.Events {
 		lookup, ok := map[string]string{
-			"pull": "pull_request",
-			"push": "push",
-			"tag":  "push",
+			"pull":    "pull_request",
+			"push":    "push",
+			"tag":     "push",
+			"comment": "pull_request_comment",
 		}[string(event)]
 		if !ok {
 			return nil, fmt.Errorf("forgejo does not have an event type corresponding to '%s'", event)
@@ -195,8 +196,9 @@ func stringSliceToVcs(es []string) ([]vcs.EventType, error) {
 	rv := make([]vcs.EventType, 0, len(es))
 	for _, event := range es {
 		lookup, ok := map[string]vcs.EventType{
-			"pull_request": "pull",
-			"push":         "push",
+			"pull_request":         "pull",
+			"push":                 "push",
+			"pull_request_comment": "comment",
 		}[string(event)]
 		if !ok {
 			return nil, fmt.Errorf("otf does not have an event type corresponding to '%s'", event)
@@ -411,6 +413,116 @@ func (c *Client) ListTags(ctx context.Context, opts vcs.ListTagsOptions) ([]stri
 	return rv, nil
 }
 
+func (c *Client) GetPullRequest(ctx context.Context, repo vcs.Repo, pull int) (vcs.PullRequest, error) {
+	pr, _, err := c.client.GetPullRequest(repo.Owner(), repo.Name(), int64(pull))
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	rv := vcs.PullRequest{
+		Number: int(pr.Index),
+		URL:    pr.HTMLURL,
+		Title:  pr.Title,
+	}
+	if pr.Head != nil {
+		rv.Branch = pr.Head.Ref
+		rv.CommitSHA = pr.Head.Sha
+	}
+	if pr.Base != nil && pr.Base.Repository != nil {
+		rv.CommitURL = pr.Base.Repository.HTMLURL + "/commit/" + rv.CommitSHA
+	}
+	if pr.Head != nil && pr.Base != nil {
+		rv.Fork = pr.Head.RepoID != pr.Base.RepoID
+	}
+	rv.Mergeable = pr.Mergeable
+	rv.Approved, err = c.isApproved(repo, pull)
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	return rv, nil
+}
+
+// isApproved determines whether the latest review of at least one reviewer
+// approves the pull request.
+func (c *Client) isApproved(repo vcs.Repo, pull int) (bool, error) {
+	opt := forgejo.ListPullReviewsOptions{
+		ListOptions: forgejo.ListOptions{
+			Page:     0,
+			PageSize: 50,
+		},
+	}
+	latest := make(map[int64]forgejo.ReviewStateType)
+	resp := &forgejo.Response{NextPage: 1, LastPage: 1}
+	for resp.LastPage > opt.Page {
+		opt.Page = resp.NextPage
+		var reviews []*forgejo.PullReview
+		var err error
+		reviews, resp, err = c.client.ListPullReviews(repo.Owner(), repo.Name(), int64(pull), opt)
+		if err != nil {
+			return false, err
+		}
+		// reviews are listed in chronological order
+		for _, review := range reviews {
+			if review.Reviewer == nil || review.Dismissed {
+				continue
+			}
+			switch review.State {
+			case forgejo.ReviewStateApproved, forgejo.ReviewStateRequestChanges:
+				latest[review.Reviewer.ID] = review.State
+			}
+		}
+	}
+	for _, state := range latest {
+		if state == forgejo.ReviewStateApproved {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasWriteAccess determines whether the user, identified by their username,
+// has write access to the repo.
+func (c *Client) HasWriteAccess(ctx context.Context, repo vcs.Repo, user string) (bool, error) {
+	perm, resp, err := c.client.CollaboratorPermission(repo.Owner(), repo.Name(), user)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if perm == nil {
+		return false, nil
+	}
+	switch perm.Permission {
+	case forgejo.AccessModeWrite, forgejo.AccessModeAdmin, forgejo.AccessModeOwner:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// CreatePullRequestComment creates a comment on a pull request. Forgejo
+// treats pull requests as issues for the purposes of comments.
+func (c *Client) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	comment, _, err := c.client.CreateIssueComment(opts.Repo.Owner(), opts.Repo.Name(), int64(opts.PullRequestNumber), forgejo.CreateIssueCommentOption{
+		Body: opts.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(comment.ID, 10), nil
+}
+
+func (c *Client) UpdatePullRequestComment(ctx context.Context, opts vcs.UpdatePullRequestCommentOptions) error {
+	intID, err := strconv.ParseInt(opts.ID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.client.EditIssueComment(opts.Repo.Owner(), opts.Repo.Name(), intID, forgejo.EditIssueCommentOption{
+		Body: opts.Body,
+	})
+	return err
+}
+
 // ListPullRequestFiles returns the paths of files that are modified in the pull request
 func (c *Client) ListPullRequestFiles(ctx context.Context, repo vcs.Repo, pull int) ([]string, error) {
 	opt := forgejo.ListPullRequestFilesOptions{
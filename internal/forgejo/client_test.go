@@ -2,7 +2,9 @@ package forgejo
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 	"testing"
@@ -351,3 +353,98 @@ func TestSetStatus(t *testing.T) {
 	assert.NotNil(t, status)
 	assert.Equal(t, forgejo.StatusPending, status.State)
 }
+
+func TestPullRequestComment(t *testing.T) {
+	var body string
+	client := newTestServerClient(t,
+		WithRepo(vcs.NewMustRepo("acme", "test")),
+		WithHandler("POST /api/v1/repos/acme/test/issues/432/comments", func(w http.ResponseWriter, r *http.Request) {
+			var opt forgejo.CreateIssueCommentOption
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&opt))
+			body = opt.Body
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":301}`))
+		}),
+		WithHandler("PATCH /api/v1/repos/acme/test/issues/comments/301", func(w http.ResponseWriter, r *http.Request) {
+			var opt forgejo.EditIssueCommentOption
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&opt))
+			body = opt.Body
+			_, _ = w.Write([]byte(`{"id":301}`))
+		}),
+	)
+
+	id, err := client.CreatePullRequestComment(t.Context(), vcs.CreatePullRequestCommentOptions{
+		Repo:              vcs.NewMustRepo("acme", "test"),
+		PullRequestNumber: 432,
+		Body:              "plan summary",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "301", id)
+	assert.Equal(t, "plan summary", body)
+
+	err = client.UpdatePullRequestComment(t.Context(), vcs.UpdatePullRequestCommentOptions{
+		Repo:              vcs.NewMustRepo("acme", "test"),
+		PullRequestNumber: 432,
+		ID:                id,
+		Body:              "updated plan summary",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "updated plan summary", body)
+}
+
+func TestGetPullRequest(t *testing.T) {
+	client := newTestServerClient(t,
+		WithRepo(vcs.NewMustRepo("acme", "test")),
+		WithHandler("GET /api/v1/repos/acme/test/pulls/432", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"number":432,"mergeable":true,"head":{"ref":"dev","sha":"abc123","repo_id":2},"base":{"repo_id":1,"repo":{"html_url":"https://forgejo.example.com/acme/test"}}}`))
+		}),
+		WithHandler("GET /api/v1/repos/acme/test/pulls/432/reviews", func(w http.ResponseWriter, r *http.Request) {
+			// alice's approval is superseded by her request for changes,
+			// but bob's approval stands.
+			_, _ = w.Write([]byte(`[
+				{"user":{"id":1,"login":"alice"},"state":"APPROVED"},
+				{"user":{"id":2,"login":"bob"},"state":"APPROVED"},
+				{"user":{"id":1,"login":"alice"},"state":"REQUEST_CHANGES"}
+			]`))
+		}),
+	)
+
+	got, err := client.GetPullRequest(t.Context(), vcs.NewMustRepo("acme", "test"), 432)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", got.Branch)
+	assert.Equal(t, "https://forgejo.example.com/acme/test/commit/abc123", got.CommitURL)
+	assert.True(t, got.Approved)
+	assert.True(t, got.Mergeable)
+	assert.True(t, got.Fork)
+}
+
+func TestHasWriteAccess(t *testing.T) {
+	client := newTestServerClient(t,
+		WithRepo(vcs.NewMustRepo("acme", "test")),
+		WithHandler("GET /api/v1/repos/acme/test/collaborators/{user}/permission", func(w http.ResponseWriter, r *http.Request) {
+			switch r.PathValue("user") {
+			case "alice":
+				_, _ = w.Write([]byte(`{"permission":"write"}`))
+			case "bob":
+				_, _ = w.Write([]byte(`{"permission":"read"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+
+	for _, tc := range []struct {
+		user string
+		want bool
+	}{
+		{user: "alice", want: true},
+		{user: "bob", want: false},
+		{user: "mallory", want: false},
+	} {
+		t.Run(tc.user, func(t *testing.T) {
+			got, err := client.HasWriteAccess(t.Context(), vcs.NewMustRepo("acme", "test"), tc.user)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
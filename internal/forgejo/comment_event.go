@@ -0,0 +1,67 @@
+package forgejo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v2"
+	"github.com/leg100/otf/internal/vcs"
+)
+
+type IssueCommentEvent struct {
+	Action      string               `json:"action"`
+	Issue       forgejo.Issue        `json:"issue"`
+	PullRequest *forgejo.PullRequest `json:"pull_request"`
+	Comment     forgejo.Comment      `json:"comment"`
+	Repository  forgejo.Repository   `json:"repository"`
+	Sender      forgejo.User         `json:"sender"`
+	IsPull      bool                 `json:"is_pull"`
+}
+
+func handleIssueCommentEvent(b []byte) (*vcs.EventPayload, error) {
+	event := IssueCommentEvent{}
+	err := json.Unmarshal(b, &event)
+	if err != nil {
+		return nil, err
+	}
+	// ignore comments on issues; only comments on pull requests are of
+	// interest.
+	if !event.IsPull {
+		return nil, vcs.NewErrIgnoreEvent("comment is not on a pull request")
+	}
+	// ignore edited and deleted comments
+	if event.Action != "created" {
+		return nil, vcs.NewErrIgnoreEvent("unsupported action: %s", event.Action)
+	}
+
+	// convert forgejo comment event to an OTF event
+	to := vcs.EventPayload{
+		Type:   vcs.EventTypeComment,
+		Action: vcs.ActionCreated,
+	}
+
+	repo, err := vcs.NewRepo(event.Repository.Owner.UserName, event.Repository.Name)
+	if err != nil {
+		return nil, err
+	}
+	to.Repo = repo
+
+	if event.PullRequest != nil && event.PullRequest.Head != nil {
+		to.Branch = event.PullRequest.Head.Ref
+	}
+	to.Comment = event.Comment.Body
+	to.PullRequestNumber = int(event.Issue.Index)
+	to.PullRequestURL = event.Issue.HTMLURL
+	to.PullRequestTitle = event.Issue.Title
+
+	to.DefaultBranch = event.Repository.DefaultBranch
+	to.SenderUsername = event.Sender.UserName
+	to.SenderAvatarURL = event.Sender.AvatarURL
+	to.SenderHTMLURL = buildUserURL(event.Repository.HTMLURL, event.Repository.FullName, event.Sender.UserName)
+	to.SenderID = event.Sender.UserName
+
+	if err := to.Validate(); err != nil {
+		return nil, fmt.Errorf("failed building OTF event: %w", err)
+	}
+	return &to, nil
+}
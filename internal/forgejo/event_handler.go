@@ -44,6 +44,9 @@ func HandleEvent(r *http.Request, secret string) (*vcs.EventPayload, error) {
 		return handlePushEvent(b.Bytes())
 	case "pull_request":
 		return handlePullRequestEvent(b.Bytes())
+	case "issue_comment", "pull_request_comment":
+		// forgejo sends comments on pull requests as issue comments
+		return handleIssueCommentEvent(b.Bytes())
 	// forgejo has no "installation" event type.
 	default:
 		return nil, vcs.NewErrIgnoreEvent("unsupported event: %s", eventtype)
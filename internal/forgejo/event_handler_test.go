@@ -126,7 +126,21 @@ func TestEventHandler(t *testing.T) {
 			eventType: "issue_comment",
 			body:      "./testdata/pr_comment.json",
 			sig:       genSigForFile("./testdata/pr_comment.json", secret),
-			ignore:    true,
+			want: &vcs.EventPayload{
+				Type:              vcs.EventTypeComment,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("tf", "thing"),
+				Branch:            "test",
+				DefaultBranch:     "main",
+				Comment:           "otf plan",
+				PullRequestNumber: 64,
+				PullRequestURL:    "https://forgejo.example.com/tf/thing/pulls/64",
+				PullRequestTitle:  "test",
+				SenderUsername:    "atlantis",
+				SenderAvatarURL:   "https://forgejo.example.com/avatars/ca84687fc2214d7d04117b4a064f50f330cabe71c7499a3cfaeee2af35e75bc4",
+				SenderHTMLURL:     "https://forgejo.example.com/atlantis",
+				SenderID:          "atlantis",
+			},
 		},
 		{
 			name:      "PR closed",
@@ -394,6 +394,8 @@ func (g *Client) CreateWebhook(ctx context.Context, opts vcs.CreateWebhookOption
 			events = append(events, "push")
 		case vcs.EventTypePull:
 			events = append(events, "pull_request")
+		case vcs.EventTypeComment:
+			events = append(events, "issue_comment")
 		}
 	}
 
@@ -425,6 +427,8 @@ func (g *Client) UpdateWebhook(ctx context.Context, id string, opts vcs.UpdateWe
 			events = append(events, "push")
 		case vcs.EventTypePull:
 			events = append(events, "pull_request")
+		case vcs.EventTypeComment:
+			events = append(events, "issue_comment")
 		}
 	}
 
@@ -464,6 +468,8 @@ func (g *Client) GetWebhook(ctx context.Context, opts vcs.GetWebhookOptions) (vc
 			events = append(events, vcs.EventTypePush)
 		case "pull_request":
 			events = append(events, vcs.EventTypePull)
+		case "issue_comment":
+			events = append(events, vcs.EventTypeComment)
 		}
 	}
 
@@ -582,3 +588,98 @@ func (g *Client) GetCommit(ctx context.Context, repo vcs.Repo, ref string) (vcs.
 		},
 	}, nil
 }
+
+func (g *Client) GetPullRequest(ctx context.Context, repo vcs.Repo, pull int) (vcs.PullRequest, error) {
+	pr, _, err := g.client.PullRequests.Get(ctx, repo.Owner(), repo.Name(), pull)
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	approved, err := g.isApproved(ctx, repo, pull)
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	return vcs.PullRequest{
+		Number:    pr.GetNumber(),
+		URL:       pr.GetHTMLURL(),
+		Title:     pr.GetTitle(),
+		Branch:    pr.GetHead().GetRef(),
+		CommitSHA: pr.GetHead().GetSHA(),
+		// commits on the head of a pull request are accessible via the base
+		// repo, even if the head is a fork.
+		CommitURL: pr.GetBase().GetRepo().GetHTMLURL() + "/commit/" + pr.GetHead().GetSHA(),
+		Approved:  approved,
+		// mergeability is nil whilst github is still computing it, in which
+		// case it is treated as unmergeable.
+		Mergeable: pr.GetMergeable(),
+		Fork:      pr.GetHead().GetRepo().GetFullName() != pr.GetBase().GetRepo().GetFullName(),
+	}, nil
+}
+
+// isApproved determines whether the latest review of at least one reviewer
+// approves the pull request.
+func (g *Client) isApproved(ctx context.Context, repo vcs.Repo, pull int) (bool, error) {
+	latest := make(map[string]string)
+	opts := github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := g.client.PullRequests.ListReviews(ctx, repo.Owner(), repo.Name(), pull, &opts)
+		if err != nil {
+			return false, err
+		}
+		// reviews are listed in chronological order
+		for _, review := range reviews {
+			switch review.GetState() {
+			case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+				latest[review.GetUser().GetLogin()] = review.GetState()
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	for _, state := range latest {
+		if state == "APPROVED" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasWriteAccess determines whether the user, identified by their login, has
+// write access to the repo.
+func (g *Client) HasWriteAccess(ctx context.Context, repo vcs.Repo, user string) (bool, error) {
+	level, _, err := g.client.Repositories.GetPermissionLevel(ctx, repo.Owner(), repo.Name(), user)
+	if err != nil {
+		return false, err
+	}
+	// the maintain role is reported as write permission.
+	switch level.GetPermission() {
+	case "admin", "write":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// CreatePullRequestComment creates a comment on a pull request. GitHub treats
+// pull requests as issues for the purposes of comments.
+func (g *Client) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	comment, _, err := g.client.Issues.CreateComment(ctx, opts.Repo.Owner(), opts.Repo.Name(), opts.PullRequestNumber, &github.IssueComment{
+		Body: new(opts.Body),
+	})
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(comment.GetID(), 10), nil
+}
+
+func (g *Client) UpdatePullRequestComment(ctx context.Context, opts vcs.UpdatePullRequestCommentOptions) error {
+	intID, err := strconv.ParseInt(opts.ID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Issues.EditComment(ctx, opts.Repo.Owner(), opts.Repo.Name(), intID, &github.IssueComment{
+		Body: new(opts.Body),
+	})
+	return err
+}
@@ -236,6 +236,142 @@ func TestGetWebhook(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPullRequestComment(t *testing.T) {
+	srv, u := testserver.NewTestServer(t,
+		testserver.WithRepo(vcs.NewMustRepo("acme", "terraform")),
+		testserver.WithPullRequest("2"),
+	)
+	client, err := NewClient(ClientOptions{
+		BaseURL:             &internal.WebURL{URL: *u},
+		SkipTLSVerification: true,
+		OAuthToken:          &oauth2.Token{AccessToken: "fake-token"},
+	})
+	require.NoError(t, err)
+
+	id, err := client.CreatePullRequestComment(t.Context(), vcs.CreatePullRequestCommentOptions{
+		Repo:              vcs.NewMustRepo("acme", "terraform"),
+		PullRequestNumber: 2,
+		Body:              "plan summary",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1", id)
+	assert.Equal(t, "plan summary", srv.GetComment(t, t.Context()).GetBody())
+
+	err = client.UpdatePullRequestComment(t.Context(), vcs.UpdatePullRequestCommentOptions{
+		Repo:              vcs.NewMustRepo("acme", "terraform"),
+		PullRequestNumber: 2,
+		ID:                id,
+		Body:              "updated plan summary",
+	})
+	require.NoError(t, err)
+	got := srv.GetComment(t, t.Context())
+	assert.Equal(t, int64(1), got.GetID())
+	assert.Equal(t, "updated plan summary", got.GetBody())
+}
+
+func TestGetPullRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// reviews are listed in chronological order
+		reviews      []*github.PullRequestReview
+		wantApproved bool
+	}{
+		{
+			name: "approved",
+			reviews: []*github.PullRequestReview{
+				{User: &github.User{Login: new("alice")}, State: new("COMMENTED")},
+				{User: &github.User{Login: new("alice")}, State: new("APPROVED")},
+			},
+			wantApproved: true,
+		},
+		{
+			name:         "no reviews",
+			wantApproved: false,
+		},
+		{
+			name: "approval superseded by request for changes",
+			reviews: []*github.PullRequestReview{
+				{User: &github.User{Login: new("alice")}, State: new("APPROVED")},
+				{User: &github.User{Login: new("alice")}, State: new("CHANGES_REQUESTED")},
+			},
+			wantApproved: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v3/repos/acme/terraform/pulls/2", func(w http.ResponseWriter, r *http.Request) {
+				pr := &github.PullRequest{
+					Number:    new(2),
+					Mergeable: new(true),
+					Head: &github.PullRequestBranch{
+						Ref:  new("dev"),
+						SHA:  new("abc123"),
+						Repo: &github.Repository{FullName: new("bob/terraform")},
+					},
+					Base: &github.PullRequestBranch{
+						Repo: &github.Repository{FullName: new("acme/terraform")},
+					},
+				}
+				require.NoError(t, json.NewEncoder(w).Encode(pr))
+			})
+			mux.HandleFunc("GET /api/v3/repos/acme/terraform/pulls/2/reviews", func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewEncoder(w).Encode(tc.reviews))
+			})
+			client := newMuxClient(t, mux)
+
+			got, err := client.GetPullRequest(t.Context(), vcs.NewMustRepo("acme", "terraform"), 2)
+			require.NoError(t, err)
+			assert.Equal(t, 2, got.Number)
+			assert.Equal(t, "dev", got.Branch)
+			assert.Equal(t, tc.wantApproved, got.Approved)
+			assert.True(t, got.Mergeable)
+			assert.True(t, got.Fork)
+		})
+	}
+}
+
+func TestHasWriteAccess(t *testing.T) {
+	for _, tc := range []struct {
+		permission string
+		want       bool
+	}{
+		{permission: "admin", want: true},
+		{permission: "write", want: true},
+		{permission: "read", want: false},
+		{permission: "none", want: false},
+	} {
+		t.Run(tc.permission, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v3/repos/acme/terraform/collaborators/bob/permission", func(w http.ResponseWriter, r *http.Request) {
+				level := &github.RepositoryPermissionLevel{Permission: new(tc.permission)}
+				require.NoError(t, json.NewEncoder(w).Encode(level))
+			})
+			client := newMuxClient(t, mux)
+
+			got, err := client.HasWriteAccess(t.Context(), vcs.NewMustRepo("acme", "terraform"), "bob")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// newMuxClient returns a client configured to access a server that serves
+// the mux.
+func newMuxClient(t *testing.T, mux *http.ServeMux) *Client {
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client, err := NewClient(ClientOptions{
+		BaseURL:             &internal.WebURL{URL: *u},
+		SkipTLSVerification: true,
+		OAuthToken:          &oauth2.Token{AccessToken: "fake-token"},
+	})
+	require.NoError(t, err)
+	return client
+}
+
 // newTestServerClient creates a github server for testing purposes and
 // returns a client configured to access the server.
 func newTestServerClient(t *testing.T, opts ...testserver.TestServerOption) *Client {
@@ -2,9 +2,11 @@ package github
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/sql"
 )
 
@@ -13,10 +15,21 @@ type appDB struct {
 	*sql.DB
 	baseURL             *internal.WebURL
 	skipTLSVerification bool
+
+	// cipher encrypts the app's private key and webhook secret
+	cipher *encryption.Cipher
 }
 
 func (db *appDB) create(ctx context.Context, app *App) error {
-	_, err := db.Exec(ctx, `
+	webhookSecret, err := db.cipher.EncryptString(ctx, app.WebhookSecret)
+	if err != nil {
+		return fmt.Errorf("encrypting webhook secret: %w", err)
+	}
+	privateKey, err := db.cipher.EncryptString(ctx, app.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("encrypting private key: %w", err)
+	}
+	_, err = db.Exec(ctx, `
 INSERT INTO github_apps (
     github_app_id,
     webhook_secret,
@@ -31,8 +44,8 @@ INSERT INTO github_apps (
     $5
 )`,
 		app.ID,
-		app.WebhookSecret,
-		app.PrivateKey,
+		webhookSecret,
+		privateKey,
 		app.Slug,
 		app.Organization,
 	)
@@ -53,6 +66,14 @@ func (db *appDB) get(ctx context.Context) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	m.WebhookSecret, err = db.cipher.DecryptString(ctx, m.WebhookSecret)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting webhook secret: %w", err)
+	}
+	m.PrivateKey, err = db.cipher.DecryptString(ctx, m.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting private key: %w", err)
+	}
 
 	client, err := NewClient(ClientOptions{
 		BaseURL:             db.baseURL,
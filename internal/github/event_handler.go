@@ -113,6 +113,39 @@ func HandleEvent(r *http.Request, secret string) (*vcs.EventPayload, error) {
 		// commit-url isn't provided in a pull-request event so one is
 		// constructed instead
 		to.CommitURL = event.GetRepo().GetHTMLURL() + "/commit/" + to.CommitSHA
+	case *github.IssueCommentEvent:
+		// ignore comments on issues; only comments on pull requests are
+		// of interest.
+		if !event.GetIssue().IsPullRequest() {
+			return nil, vcs.NewErrIgnoreEvent("comment is not on a pull request")
+		}
+		// ignore edited and deleted comments
+		if event.GetAction() != "created" {
+			return nil, vcs.NewErrIgnoreEvent("unsupported action: %s", event.GetAction())
+		}
+		to.Type = vcs.EventTypeComment
+		to.Action = vcs.ActionCreated
+
+		repo, err := vcs.NewRepo(event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName())
+		if err != nil {
+			return nil, err
+		}
+		to.Repo = repo
+
+		to.PullRequestNumber = event.GetIssue().GetNumber()
+		to.PullRequestURL = event.GetIssue().GetHTMLURL()
+		to.PullRequestTitle = event.GetIssue().GetTitle()
+		to.Comment = event.GetComment().GetBody()
+		to.DefaultBranch = event.GetRepo().GetDefaultBranch()
+
+		to.SenderUsername = event.GetSender().GetLogin()
+		to.SenderAvatarURL = event.GetSender().GetAvatarURL()
+		to.SenderHTMLURL = event.GetSender().GetHTMLURL()
+		to.SenderID = event.GetSender().GetLogin()
+
+		if install := event.GetInstallation(); install != nil {
+			to.GithubAppInstallID = install.ID
+		}
 	case *github.InstallationEvent:
 		// ignore events other than uninstallation events
 		if event.GetAction() != "deleted" {
@@ -119,6 +119,33 @@ func TestEventHandler(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"pull request comment",
+			"issue_comment",
+			"./testdata/github_pull_comment.json",
+			&vcs.EventPayload{
+				Type:              vcs.EventTypeComment,
+				Repo:              vcs.NewMustRepo("leg100", "otf-workspaces"),
+				DefaultBranch:     "master",
+				PullRequestNumber: 2,
+				PullRequestURL:    "https://github.com/leg100/otf-workspaces/pull/2",
+				PullRequestTitle:  "pr-2",
+				Comment:           "otf plan -w dev",
+				Action:            vcs.ActionCreated,
+				SenderUsername:    "leg100",
+				SenderAvatarURL:   "https://avatars.githubusercontent.com/u/75728?v=4",
+				SenderHTMLURL:     "https://github.com/leg100",
+				SenderID:          "leg100",
+			},
+			false,
+		},
+		{
+			"ignore issue comment",
+			"issue_comment",
+			"./testdata/github_issue_comment.json",
+			nil,
+			true,
+		},
 		{
 			"ignore github app install created",
 			"installation",
@@ -6,6 +6,7 @@ import (
 
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
@@ -33,6 +34,8 @@ type (
 		Authorizer          *authz.Authorizer
 		VCSService          *vcs.Service
 		VCSEventBroker      *vcs.Broker
+		// Cipher encrypts the app's private key and webhook secret.
+		Cipher *encryption.Cipher
 	}
 )
 
@@ -44,6 +47,7 @@ func NewService(opts Options) *Service {
 			DB:                  opts.DB,
 			baseURL:             opts.GithubAPIURL,
 			skipTLSVerification: opts.SkipTLSVerification,
+			cipher:              opts.Cipher,
 		},
 	}
 	registerVCSKinds(&svc, opts.VCSService, opts.GithubAPIURL, opts.SkipTLSVerification)
@@ -12,6 +12,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -37,6 +39,8 @@ type (
 	TestServer struct {
 		// status updates received from otfd
 		statuses chan *github.StatusEvent
+		// pull request comments created or updated by otfd
+		comments chan *github.IssueComment
 
 		// webhook created/updated/deleted events channel
 		WebhookEvents chan webhookEvent
@@ -61,6 +65,8 @@ type (
 		// pull request stub
 		pullNumber string
 		pullFiles  []string
+		// ID of the most recently created pull request comment
+		lastCommentID atomic.Int64
 
 		// url of server, only populated once server starts
 		url *string
@@ -86,6 +92,7 @@ func NewTestServer(t *testing.T, opts ...TestServerOption) (*TestServer, *url.UR
 	srv := TestServer{
 		testdb:        &testdb{},
 		statuses:      make(chan *github.StatusEvent, 999),
+		comments:      make(chan *github.IssueComment, 999),
 		WebhookEvents: make(chan webhookEvent, 999),
 		mux:           http.NewServeMux(),
 	}
@@ -284,6 +291,48 @@ func NewTestServer(t *testing.T, opts ...TestServerOption) (*TestServer, *url.UR
 			w.Header().Add("Content-Type", "application/json")
 			w.Write(out)
 		})
+		if srv.pullNumber != "" {
+			// https://docs.github.com/en/rest/issues/comments?apiVersion=2022-11-28#create-an-issue-comment
+			srv.mux.HandleFunc("POST /api/v3/repos/"+srv.repo.String()+"/issues/"+srv.pullNumber+"/comments", func(w http.ResponseWriter, r *http.Request) {
+				var comment github.IssueComment
+				if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				comment.ID = new(srv.lastCommentID.Add(1))
+				srv.comments <- &comment
+				out, err := json.Marshal(&comment)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				w.Header().Add("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write(out)
+			})
+			// https://docs.github.com/en/rest/issues/comments?apiVersion=2022-11-28#update-an-issue-comment
+			srv.mux.HandleFunc("PATCH /api/v3/repos/"+srv.repo.String()+"/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+				id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+				if err != nil || id < 1 || id > srv.lastCommentID.Load() {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				var comment github.IssueComment
+				if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				comment.ID = new(id)
+				srv.comments <- &comment
+				out, err := json.Marshal(&comment)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				w.Header().Add("Content-Type", "application/json")
+				w.Write(out)
+			})
+		}
 		if srv.commit != nil {
 			// https://docs.github.com/en/rest/commits/commits?apiVersion=2022-11-28#get-a-commit
 			srv.mux.HandleFunc("/api/v3/repos/"+srv.repo.String()+"/commits/"+*srv.commit, func(w http.ResponseWriter, r *http.Request) {
@@ -420,6 +469,23 @@ func (s *TestServer) GetStatus(t *testing.T, ctx context.Context) *github.Status
 	return nil
 }
 
+// GetComment retrieves a created or updated pull request comment off the
+// queue, timing out after 10 seconds if nothing is on the queue.
+func (s *TestServer) GetComment(t *testing.T, ctx context.Context) *github.IssueComment {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	select {
+	case comment := <-s.comments:
+		return comment
+	case <-ctx.Done():
+		t.Fatalf("github server: waiting to receive pull request comment: %s", ctx.Err().Error())
+	}
+	return nil
+}
+
 // SendEventRequest sends a GitHub event via a http request to the url, signed with the secret,
 func SendEventRequest(t *testing.T, event GithubEvent, url, secret string, payload []byte) {
 	t.Helper()
@@ -90,11 +90,12 @@ func (h *Handlers) newGithubApp(w http.ResponseWriter, r *http.Request) {
 		HookAttrs:   hookAttrs{URL: h.Hostnames.WebhookURL(github.AppEventsPath)},
 		Redirect:    h.Hostnames.URL(path.ExchangeCodeGithubApp()),
 		Description: "Trigger terraform runs in OTF from GitHub",
-		Events:      []string{"push", "pull_request"},
+		Events:      []string{"push", "pull_request", "issue_comment"},
 		Public:      false,
 		Permissions: map[string]string{
 			"checks":        "write",
 			"contents":      "read",
+			"issues":        "read",
 			"metadata":      "read",
 			"pull_requests": "write",
 			"statuses":      "write",
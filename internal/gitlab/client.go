@@ -192,6 +192,8 @@ func (g *Client) CreateWebhook(ctx context.Context, opts vcs.CreateWebhookOption
 			addOpts.PushEvents = new(true)
 		case vcs.EventTypePull:
 			addOpts.MergeRequestsEvents = new(true)
+		case vcs.EventTypeComment:
+			addOpts.NoteEvents = new(true)
 		}
 	}
 
@@ -219,6 +221,8 @@ func (g *Client) UpdateWebhook(ctx context.Context, id string, opts vcs.UpdateWe
 			editOpts.PushEvents = new(true)
 		case vcs.EventTypePull:
 			editOpts.MergeRequestsEvents = new(true)
+		case vcs.EventTypeComment:
+			editOpts.NoteEvents = new(true)
 		}
 	}
 
@@ -250,6 +254,9 @@ func (g *Client) GetWebhook(ctx context.Context, opts vcs.GetWebhookOptions) (vc
 	if hook.MergeRequestsEvents {
 		events = append(events, vcs.EventTypePull)
 	}
+	if hook.NoteEvents {
+		events = append(events, vcs.EventTypeComment)
+	}
 
 	return vcs.Webhook{
 		ID:       opts.ID,
@@ -289,6 +296,74 @@ func (g *Client) SetStatus(ctx context.Context, opts vcs.SetStatusOptions) error
 	return err
 }
 
+func (g *Client) GetPullRequest(ctx context.Context, repo vcs.Repo, pull int) (vcs.PullRequest, error) {
+	mr, _, err := g.client.MergeRequests.GetMergeRequest(repo.String(), int64(pull), &gitlab.GetMergeRequestsOptions{})
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	// construct commit URL from the merge request URL, which takes the form
+	// <project_url>/-/merge_requests/<iid>
+	projectURL, _, _ := strings.Cut(mr.WebURL, "/-/merge_requests/")
+	approvals, _, err := g.client.MergeRequestApprovals.GetConfiguration(repo.String(), int64(pull))
+	if err != nil {
+		return vcs.PullRequest{}, err
+	}
+	return vcs.PullRequest{
+		Number:    int(mr.IID),
+		URL:       mr.WebURL,
+		Title:     mr.Title,
+		Branch:    mr.SourceBranch,
+		CommitSHA: mr.SHA,
+		CommitURL: projectURL + "/-/commit/" + mr.SHA,
+		// gitlab deems a merge request approved if it requires no approvals,
+		// so at least one approval is also required.
+		Approved:  approvals.Approved && len(approvals.ApprovedBy) > 0,
+		Mergeable: !mr.HasConflicts,
+		Fork:      mr.SourceProjectID != mr.TargetProjectID,
+	}, nil
+}
+
+// HasWriteAccess determines whether the user, identified by their ID, has
+// write access to the project, i.e. they are a member with at least the
+// developer role, either of the project or of one of its ancestor groups.
+func (g *Client) HasWriteAccess(ctx context.Context, repo vcs.Repo, user string) (bool, error) {
+	id, err := strconv.ParseInt(user, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID: %w", err)
+	}
+	member, resp, err := g.client.ProjectMembers.GetInheritedProjectMember(repo.String(), id)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// user is not a member
+			return false, nil
+		}
+		return false, err
+	}
+	return member.AccessLevel >= gitlab.DeveloperPermissions, nil
+}
+
+// CreatePullRequestComment creates a note on a merge request.
+func (g *Client) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	note, _, err := g.client.Notes.CreateMergeRequestNote(opts.Repo.String(), int64(opts.PullRequestNumber), &gitlab.CreateMergeRequestNoteOptions{
+		Body: new(opts.Body),
+	})
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(note.ID, 10), nil
+}
+
+func (g *Client) UpdatePullRequestComment(ctx context.Context, opts vcs.UpdatePullRequestCommentOptions) error {
+	intID, err := strconv.ParseInt(opts.ID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.client.Notes.UpdateMergeRequestNote(opts.Repo.String(), int64(opts.PullRequestNumber), intID, &gitlab.UpdateMergeRequestNoteOptions{
+		Body: new(opts.Body),
+	})
+	return err
+}
+
 func (g *Client) ListPullRequestFiles(ctx context.Context, repo vcs.Repo, pull int) ([]string, error) {
 	diffs, _, err := g.client.MergeRequests.ListMergeRequestDiffs(repo.String(), int64(pull), &gitlab.ListMergeRequestDiffsOptions{})
 	if err != nil {
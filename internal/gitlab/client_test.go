@@ -164,6 +164,125 @@ func TestClient_ListPullRequestFiles(t *testing.T) {
 	assert.Equal(t, []string{"dev.tf", "main.tf", "prod.tf"}, got)
 }
 
+func TestClient_GetPullRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		approvals    string
+		wantApproved bool
+	}{
+		{
+			name:         "approved",
+			approvals:    `{"approved":true,"approved_by":[{"user":{"username":"alice"}}]}`,
+			wantApproved: true,
+		},
+		{
+			name:         "requires no approvals",
+			approvals:    `{"approved":true,"approved_by":[]}`,
+			wantApproved: false,
+		},
+		{
+			name:         "insufficient approvals",
+			approvals:    `{"approved":false,"approved_by":[{"user":{"username":"alice"}}]}`,
+			wantApproved: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mux, client := setup(t)
+
+			mux.HandleFunc("GET /api/v4/projects/acme%2Fterraform/merge_requests/1", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"iid":1,"web_url":"https://gitlab.com/acme/terraform/-/merge_requests/1","source_branch":"dev","sha":"abc123","has_conflicts":true,"source_project_id":2,"target_project_id":1}`)
+			})
+			mux.HandleFunc("GET /api/v4/projects/acme%2Fterraform/merge_requests/1/approvals", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.approvals)
+			})
+
+			got, err := client.GetPullRequest(t.Context(), vcs.NewMustRepo("acme", "terraform"), 1)
+			require.NoError(t, err)
+			assert.Equal(t, "dev", got.Branch)
+			assert.Equal(t, "https://gitlab.com/acme/terraform/-/commit/abc123", got.CommitURL)
+			assert.Equal(t, tc.wantApproved, got.Approved)
+			assert.False(t, got.Mergeable)
+			assert.True(t, got.Fork)
+		})
+	}
+}
+
+func TestClient_HasWriteAccess(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("GET /api/v4/projects/acme%2Fterraform/members/all/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "1":
+			fmt.Fprint(w, `{"id":1,"access_level":30}`)
+		case "2":
+			fmt.Fprint(w, `{"id":2,"access_level":20}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Not found"}`)
+		}
+	})
+
+	repo := vcs.NewMustRepo("acme", "terraform")
+	for _, tc := range []struct {
+		name string
+		user string
+		want bool
+	}{
+		{name: "developer", user: "1", want: true},
+		{name: "reporter", user: "2", want: false},
+		{name: "non-member", user: "3", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := client.HasWriteAccess(t.Context(), repo, tc.user)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClient_CreatePullRequestComment(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("POST /api/v4/projects/acme%2Fterraform/merge_requests/1/notes", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		err := json.NewDecoder(r.Body).Decode(&body)
+		require.NoError(t, err)
+		assert.Equal(t, "plan summary", body["body"])
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":301,"body":"plan summary"}`)
+	})
+
+	got, err := client.CreatePullRequestComment(t.Context(), vcs.CreatePullRequestCommentOptions{
+		Repo:              vcs.NewMustRepo("acme", "terraform"),
+		PullRequestNumber: 1,
+		Body:              "plan summary",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "301", got)
+}
+
+func TestClient_UpdatePullRequestComment(t *testing.T) {
+	mux, client := setup(t)
+
+	mux.HandleFunc("PUT /api/v4/projects/acme%2Fterraform/merge_requests/1/notes/301", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		err := json.NewDecoder(r.Body).Decode(&body)
+		require.NoError(t, err)
+		assert.Equal(t, "updated plan summary", body["body"])
+
+		fmt.Fprint(w, `{"id":301,"body":"updated plan summary"}`)
+	})
+
+	err := client.UpdatePullRequestComment(t.Context(), vcs.UpdatePullRequestCommentOptions{
+		Repo:              vcs.NewMustRepo("acme", "terraform"),
+		PullRequestNumber: 1,
+		ID:                "301",
+		Body:              "updated plan summary",
+	})
+	require.NoError(t, err)
+}
+
 func TestClient_GetCommit(t *testing.T) {
 	mux, client := setup(t)
 
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/leg100/otf/internal"
@@ -85,6 +86,10 @@ func HandleEvent(r *http.Request, secret string) (*vcs.EventPayload, error) {
 			to.Action = vcs.ActionCreated
 		case "update":
 			to.Action = vcs.ActionUpdated
+		case "merge":
+			to.Action = vcs.ActionMerged
+		case "close":
+			to.Action = vcs.ActionDeleted
 		default:
 			return nil, vcs.NewErrIgnoreEvent("unsupported action: %s", event.ObjectAttributes.Action)
 		}
@@ -97,6 +102,34 @@ func HandleEvent(r *http.Request, secret string) (*vcs.EventPayload, error) {
 		to.SenderUsername = event.User.Username
 		to.SenderAvatarURL = event.User.AvatarURL
 		to.SenderHTMLURL = userURL(origin, event.User.Username)
+	case *gitlab.MergeCommentEvent:
+		// ignore edited comments
+		if event.ObjectAttributes.Action == "update" {
+			return nil, vcs.NewErrIgnoreEvent("unsupported action: %s", event.ObjectAttributes.Action)
+		}
+		// ignore comments generated by gitlab itself
+		if event.ObjectAttributes.System {
+			return nil, vcs.NewErrIgnoreEvent("ignoring system note")
+		}
+		to.Type = vcs.EventTypeComment
+		to.Action = vcs.ActionCreated
+
+		repo, err := vcs.NewRepoFromString(event.Project.PathWithNamespace)
+		if err != nil {
+			return nil, err
+		}
+		to.Repo = repo
+
+		to.Comment = event.ObjectAttributes.Note
+		to.Branch = event.MergeRequest.SourceBranch
+		to.PullRequestNumber = int(event.MergeRequest.IID)
+		to.PullRequestURL = event.MergeRequest.URL
+		to.PullRequestTitle = event.MergeRequest.Title
+		to.DefaultBranch = event.Project.DefaultBranch
+		to.SenderUsername = event.User.Username
+		to.SenderAvatarURL = event.User.AvatarURL
+		to.SenderHTMLURL = userURL(origin, event.User.Username)
+		to.SenderID = strconv.FormatInt(event.User.ID, 10)
 	case *gitlab.TagEvent:
 		to.Type = vcs.EventTypeTag
 
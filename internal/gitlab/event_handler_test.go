@@ -78,6 +78,26 @@ func TestEventHandler(t *testing.T) {
 				SenderHTMLURL:     "https://github.com/leg100",
 			},
 		},
+		{
+			"merge request comment",
+			"Note Hook",
+			"./testdata/merge_comment.json",
+			&vcs.EventPayload{
+				Type:              vcs.EventTypeComment,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("leg100", "otf-workspaces"),
+				Branch:            "pr-1",
+				DefaultBranch:     "master",
+				Comment:           "otf apply",
+				PullRequestNumber: 1,
+				PullRequestURL:    "https://gitlab.com/leg100/otf-workspaces/-/merge_requests/1",
+				PullRequestTitle:  "Pr 1",
+				SenderUsername:    "leg100",
+				SenderAvatarURL:   "https://secure.gravatar.com/avatar/de3ca65d31c67b63a795b88c677bba5d?s=80&d=identicon",
+				SenderHTMLURL:     "https://github.com/leg100",
+				SenderID:          "1464950",
+			},
+		},
 		{
 			"push tag",
 			"Tag Push Hook",
@@ -6,8 +6,8 @@ package http
 import (
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strings"
 
@@ -78,17 +78,57 @@ func ParseURL(address string) (*url.URL, error) {
 	return u, nil
 }
 
-// GetClientIP gets the client's IP address
-func GetClientIP(r *http.Request) (string, error) {
-	// reverse proxy adds client IP to an HTTP header, and each successive proxy
-	// adds a client IP, so we want the leftmost IP.
-	if hdr := r.Header.Get("X-Forwarded-For"); hdr != "" {
-		first, _, _ := strings.Cut(hdr, ",")
-		addr := strings.TrimSpace(first)
-		if isIP := net.ParseIP(addr); isIP != nil {
-			return addr, nil
+// GetClientIP gets the client's IP address. Unless the peer is one of the
+// trusted proxies, the peer's address is the client's address. Otherwise the
+// X-Forwarded-For header is consulted: each proxy appends the address of its
+// own peer to the header, so the header is read from right to left, skipping
+// trusted proxies, and the first address that isn't trusted is the client's.
+// Addresses to the left of that may have been forged by the client and are
+// ignored.
+func GetClientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, error) {
+	peer, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("parsing remote address: %w", err)
+	}
+	addr := peer.Addr().Unmap()
+	var hops []string
+	for _, hdr := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(hdr, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(addr, trustedProxies); i-- {
+		addr, err = netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("parsing X-Forwarded-For header: %w", err)
+		}
+		addr = addr.Unmap()
+	}
+	return addr, nil
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses the CIDR ranges or IP addresses of trusted
+// proxies.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, len(proxies))
+	for i, s := range proxies {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			// Permit a single IP address in lieu of a CIDR.
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
 		}
+		prefixes[i] = prefix.Masked()
 	}
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	return host, err
+	return prefixes, nil
 }
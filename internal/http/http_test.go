@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -81,11 +82,16 @@ func TestParseURL(t *testing.T) {
 }
 
 func TestGetClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+	}
 	tests := []struct {
 		name      string
-		forwarded string
+		forwarded []string
 		remote    string
 		want      string
+		wantErr   bool
 	}{
 		{
 			name:   "no forwarded header",
@@ -93,26 +99,79 @@ func TestGetClientIP(t *testing.T) {
 			want:   "1.2.3.4",
 		},
 		{
-			name:      "forwarded header",
+			name:      "forwarded header from untrusted peer",
 			remote:    "1.2.3.4:1234",
-			forwarded: "5.6.7.8",
+			forwarded: []string{"5.6.7.8"},
+			want:      "1.2.3.4",
+		},
+		{
+			name:      "forwarded header from trusted proxy",
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"5.6.7.8"},
 			want:      "5.6.7.8",
 		},
 		{
-			name:      "forwarded header with multiple ips",
-			remote:    "1.2.3.4:1234",
-			forwarded: "5.6.7.8, 9.0.1.2",
+			name:      "rightmost untrusted hop",
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"1.1.1.1, 5.6.7.8, 192.168.1.1"},
+			want:      "5.6.7.8",
+		},
+		{
+			name:      "multiple forwarded headers",
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"1.1.1.1", "5.6.7.8"},
 			want:      "5.6.7.8",
 		},
+		{
+			name:      "all hops trusted",
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"10.0.0.2, 10.0.0.3"},
+			want:      "10.0.0.2",
+		},
+		{
+			name:      "ipv4-mapped ipv6 peer",
+			remote:    "[::ffff:10.0.0.1]:1234",
+			forwarded: []string{"5.6.7.8"},
+			want:      "5.6.7.8",
+		},
+		{
+			name:      "invalid forwarded address",
+			remote:    "10.0.0.1:1234",
+			forwarded: []string{"not-an-ip"},
+			wantErr:   true,
+		},
+		{
+			name:    "invalid remote address",
+			remote:  "not-an-ip",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := httptest.NewRequest("", "/", nil)
-			r.Header.Add("X-Forwarded-For", tt.forwarded)
+			for _, hdr := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", hdr)
+			}
 			r.RemoteAddr = tt.remote
-			got, err := GetClientIP(r)
+			got, err := GetClientIP(r, trusted)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
 			require.NoError(t, err)
-			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, got.String())
 		})
 	}
 }
+
+func TestParseTrustedProxies(t *testing.T) {
+	got, err := ParseTrustedProxies([]string{"10.1.2.3/8", "192.168.1.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.1/32"),
+	}, got)
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/felixge/httpsnoop"
@@ -28,6 +29,7 @@ const (
 	// before shutdown.
 	shutdownTimeout     = 1 * time.Second
 	headersKey      key = "headers"
+	clientIPKey     key = "client-ip"
 )
 
 var versionPayload = json.MustMarshal(struct {
@@ -46,6 +48,9 @@ type (
 		SSL                  bool
 		CertFile, KeyFile    string
 		EnableRequestLogging bool
+		// CIDR ranges or IP addresses of proxies trusted to set the
+		// X-Forwarded-For header.
+		TrustedProxies []string
 
 		Handlers []internal.Handlers
 		// middleware to intercept requests, executed in the order given.
@@ -71,6 +76,10 @@ func NewServer(logger logr.Logger, cfg ServerConfig) (*Server, error) {
 			return nil, fmt.Errorf("must provide both --cert-file and --key-file")
 		}
 	}
+	trustedProxies, err := ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
 
 	r := mux.NewRouter()
 
@@ -101,6 +110,8 @@ func NewServer(logger logr.Logger, cfg ServerConfig) (*Server, error) {
 		})
 	})
 
+	r.Use(clientIPMiddleware(trustedProxies))
+
 	// Subject service routes to provided middleware, verifying tokens,
 	// sessions.
 	svcRouter.Use(cfg.Middleware...)
@@ -139,6 +150,19 @@ func NewServer(logger logr.Logger, cfg ServerConfig) (*Server, error) {
 	}, nil
 }
 
+// clientIPMiddleware adds the client's IP address to the context. The
+// X-Forwarded-For header is only honoured if set by a trusted proxy.
+func clientIPMiddleware(trustedProxies []netip.Prefix) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, err := GetClientIP(r, trustedProxies); err == nil {
+				r = r.WithContext(context.WithValue(r.Context(), clientIPKey, ip.String()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // Start starts serving http traffic on the given listener and waits until the server exits due to
 // error or the context is cancelled.
 func (s *Server) Start(ctx context.Context, ln net.Listener) (err error) {
@@ -174,6 +198,13 @@ func (s *Server) Start(ctx context.Context, ln net.Listener) (err error) {
 	}
 }
 
+// ClientIPFromContext retrieves the IP address of the client that made the
+// request from the context.
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey).(string)
+	return ip, ok
+}
+
 func HeadersFromContext(ctx context.Context) (http.Header, error) {
 	headers, ok := ctx.Value(headersKey).(http.Header)
 	if !ok {
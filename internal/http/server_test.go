@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       string
+		wantOK     bool
+	}{
+		{
+			name:       "no proxy",
+			remoteAddr: "203.0.113.7:1234",
+			want:       "203.0.113.7",
+			wantOK:     true,
+		},
+		{
+			name:       "ignore header set by untrusted peer",
+			remoteAddr: "203.0.113.7:1234",
+			xff:        "198.51.100.1",
+			want:       "203.0.113.7",
+			wantOK:     true,
+		},
+		{
+			name:       "honour header set by trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        "198.51.100.1",
+			want:       "198.51.100.1",
+			wantOK:     true,
+		},
+		{
+			name:       "invalid remote address",
+			remoteAddr: "bogus",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got string
+				ok  bool
+			)
+			handler := clientIPMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, ok = ClientIPFromContext(r.Context())
+			}))
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/configversion/source"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_Assessment demonstrates a health assessment being
+// performed on a workspace with assessments enabled.
+func TestIntegration_Assessment(t *testing.T) {
+	integrationTest(t)
+
+	// Check very frequently for workspaces due an assessment.
+	daemon, org, ctx := setup(t, withAssessments(time.Hour, 100*time.Millisecond))
+
+	ws, err := daemon.Workspaces.CreateWorkspace(ctx, workspace.CreateOptions{
+		Name:               new("workspace-" + internal.GenerateRandomString(6)),
+		Organization:       &org.Name,
+		AssessmentsEnabled: new(true),
+	})
+	require.NoError(t, err)
+
+	// Only workspaces that have had a run are assessed.
+	cv := daemon.createAndUploadConfigurationVersion(t, ctx, ws, nil)
+	applied := daemon.createRun(t, ctx, ws, cv, &run.CreateOptions{AutoApply: new(true)})
+	daemon.waitRunStatus(t, ctx, applied.ID, runstatus.Applied)
+
+	// Wait for the assessment run to finish.
+	for event := range daemon.runEvents {
+		if event.Payload.Source != source.Assessment {
+			continue
+		}
+		assert.True(t, event.Payload.RefreshOnly)
+		assert.True(t, event.Payload.PlanOnly)
+		if event.Payload.Status == runstatus.PlannedAndFinished {
+			break
+		}
+		if runstatus.Done(event.Payload.Status) {
+			t.Fatalf("assessment run finished with status %s", event.Payload.Status)
+		}
+	}
+
+	// Nothing has changed since the run was applied so no drift is
+	// expected.
+	assert.Eventually(t, func() bool {
+		ws = daemon.getWorkspace(t, ctx, ws.ID)
+		return ws.Assessment != nil
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, workspace.AssessmentNoDrift, ws.Assessment.Status)
+}
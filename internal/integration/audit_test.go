@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
+	"github.com/leg100/otf/internal/project"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sshkey"
+	otfuser "github.com/leg100/otf/internal/user"
+	"github.com/leg100/otf/internal/variable"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Audit(t *testing.T) {
+	integrationTest(t)
+
+	daemon, org, ctx := setup(t)
+
+	// find the most recent event for the resource
+	find := func(t *testing.T, id resource.TfeID, action resource.Action) *audit.Event {
+		t.Helper()
+
+		page, err := daemon.Audit.ListEvents(ctx, org.Name, audit.ListOptions{
+			PageOptions: resource.PageOptions{PageSize: resource.MaxPageSize},
+		})
+		require.NoError(t, err)
+		for _, event := range page.Items {
+			if event.ResourceID == id && event.Action == action {
+				return event
+			}
+		}
+		t.Fatalf("no %s event found for %s", action, id)
+		return nil
+	}
+
+	t.Run("workspace changes", func(t *testing.T) {
+		ws := daemon.createWorkspace(t, ctx, org)
+
+		created := find(t, ws.ID, resource.Create)
+		assert.Equal(t, org.Name.String(), created.Organization)
+		assert.Equal(t, "app-user", created.Subject)
+		assert.Equal(t, ws.Name, created.Changes["name"].After)
+
+		_, err := daemon.Workspaces.UpdateWorkspace(ctx, ws.ID, workspace.UpdateOptions{
+			Description: new("updated"),
+		})
+		require.NoError(t, err)
+
+		updated := find(t, ws.ID, resource.Update)
+		assert.Equal(t, audit.Change{Before: "", After: "updated"}, updated.Changes["description"])
+		// unchanged fields are omitted
+		assert.NotContains(t, updated.Changes, "name")
+	})
+
+	t.Run("sensitive variable value is redacted", func(t *testing.T) {
+		ws := daemon.createWorkspace(t, ctx, org)
+		v := daemon.createVariable(t, ctx, ws, &variable.CreateVariableOptions{
+			Key:       new("secret"),
+			Value:     new("top-secret"),
+			Category:  internal.Ptr(variable.CategoryEnv),
+			Sensitive: new(true),
+		})
+
+		// organization is resolved from the variable's workspace
+		created := find(t, v.ID, resource.Create)
+		assert.Equal(t, org.Name.String(), created.Organization)
+		assert.Equal(t, "*****", created.Changes["value"].After)
+	})
+
+	t.Run("run changes", func(t *testing.T) {
+		r := daemon.createRun(t, ctx, nil, nil, nil)
+
+		created := find(t, r.ID, resource.Create)
+		assert.Equal(t, org.Name.String(), created.Organization)
+		assert.Equal(t, r.WorkspaceID.String(), created.Changes["workspace_id"].After)
+
+		err := daemon.Runs.CancelRun(ctx, r.ID)
+		require.NoError(t, err)
+
+		canceled := find(t, r.ID, resource.Cancel)
+		assert.Equal(t, "canceled", canceled.Changes["status"].After)
+	})
+
+	t.Run("project changes", func(t *testing.T) {
+		prj, err := daemon.Projects.CreateProject(ctx, org.Name, project.CreateOptions{Name: "audited"})
+		require.NoError(t, err)
+		created := find(t, prj.ID, resource.Create)
+		assert.Equal(t, "audited", created.Changes["name"].After)
+
+		_, err = daemon.Projects.UpdateProject(ctx, prj.ID, project.UpdateOptions{Name: new("renamed")})
+		require.NoError(t, err)
+		updated := find(t, prj.ID, resource.Update)
+		assert.Equal(t, audit.Change{Before: "audited", After: "renamed"}, updated.Changes["name"])
+
+		_, err = daemon.Projects.DeleteProject(ctx, prj.ID)
+		require.NoError(t, err)
+		find(t, prj.ID, resource.Delete)
+	})
+
+	t.Run("ssh key private key is omitted", func(t *testing.T) {
+		key, err := daemon.SSHKeys.CreateSSHKey(ctx, sshkey.CreateOptions{
+			Organization: org.Name,
+			Name:         "deploy",
+			PrivateKey:   "top-secret",
+		})
+		require.NoError(t, err)
+
+		created := find(t, key.ID, resource.Create)
+		assert.Equal(t, "deploy", created.Changes["name"].After)
+		for _, change := range created.Changes {
+			assert.NotEqual(t, "top-secret", change.After)
+		}
+	})
+
+	t.Run("notification config url is omitted", func(t *testing.T) {
+		nc := daemon.createNotificationConfig(t, ctx, nil)
+
+		// organization is resolved from the config's workspace
+		created := find(t, nc.ID, resource.Create)
+		assert.Equal(t, org.Name.String(), created.Organization)
+		assert.NotContains(t, created.Changes, "url")
+
+		err := daemon.Notifications.DeleteNotificationConfig(ctx, nc.ID)
+		require.NoError(t, err)
+		find(t, nc.ID, resource.Delete)
+	})
+
+	t.Run("user token changes", func(t *testing.T) {
+		owners := daemon.getTeam(t, ctx, org.Name, "owners")
+		member, memberCtx := daemon.createUserCtx(t, otfuser.WithTeams(owners))
+
+		// events are recorded in the organization of which the user is a
+		// member
+		ut, _ := daemon.createToken(t, memberCtx, nil)
+		created := find(t, ut.ID, resource.Create)
+		assert.Equal(t, member.Username.String(), created.Subject)
+
+		err := daemon.Users.DeleteToken(memberCtx, ut.ID)
+		require.NoError(t, err)
+		find(t, ut.ID, resource.Delete)
+	})
+
+	t.Run("token revocation", func(t *testing.T) {
+		owners := daemon.getTeam(t, ctx, org.Name, "owners")
+		member := daemon.createUser(t, otfuser.WithTeams(owners))
+		ut, _ := daemon.createToken(t, ctx, member)
+
+		err := daemon.Tokens.RevokeTokens(ctx, []resource.TfeID{ut.ID})
+		require.NoError(t, err)
+
+		revoked := find(t, ut.ID, resource.Revoke)
+		assert.Equal(t, "app-user", revoked.Subject)
+	})
+
+	t.Run("filter by time range", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		page, err := daemon.Audit.ListEvents(ctx, org.Name, audit.ListOptions{
+			Since: &future,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+
+		past := time.Now().Add(-time.Hour)
+		page, err = daemon.Audit.ListEvents(ctx, org.Name, audit.ListOptions{
+			Until: &past,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	})
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/blob"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_BlobStore tests storing blobs outside of the database.
+func TestIntegration_BlobStore(t *testing.T) {
+	integrationTest(t)
+
+	t.Run("file store", func(t *testing.T) {
+		root := t.TempDir()
+		daemon, _, ctx := setup(t, withBlobStore(blob.Config{
+			Backend: blob.FileBackend,
+			Path:    root,
+		}, 100*time.Millisecond))
+
+		// Create two state versions, so that the first is no longer the
+		// current version and can be deleted.
+		ws := daemon.createWorkspace(t, ctx, nil)
+		sv := daemon.createStateVersion(t, ctx, ws)
+		_ = daemon.createStateVersion(t, ctx, ws)
+
+		path := filepath.Join(root, "state", sv.ID.String())
+		want, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		got, err := daemon.State.DownloadState(ctx, sv.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		// Deleting the state version should lead to its state file being
+		// deleted by the reaper.
+		err = daemon.State.DeleteStateVersion(ctx, sv.ID)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return os.IsNotExist(err)
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("migrate from database", func(t *testing.T) {
+		daemon, _, ctx := setup(t)
+		sv := daemon.createStateVersion(t, ctx, nil)
+
+		target, err := blob.NewFileStore(t.TempDir())
+		require.NoError(t, err)
+		n, err := blob.Migrate(ctx, logr.Discard(), daemon.DB, target)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		got, err := target.Get(ctx, blob.StateKey(sv.ID))
+		require.NoError(t, err)
+		assert.Equal(t, sv.State, got)
+
+		// Blob should no longer be in the database.
+		_, err = daemon.State.DownloadState(ctx, sv.ID)
+		assert.Error(t, err)
+	})
+}
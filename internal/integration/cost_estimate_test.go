@@ -0,0 +1,42 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leg100/otf/internal/costestimate"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_CostEstimate demonstrates the costs of a run being
+// estimated.
+func TestIntegration_CostEstimate(t *testing.T) {
+	integrationTest(t)
+
+	catalog := filepath.Join(t.TempDir(), "prices.csv")
+	err := os.WriteFile(catalog, []byte("resource_type,monthly_cost\nnull_resource,12.5\n"), 0o600)
+	require.NoError(t, err)
+
+	daemon, org, ctx := setup(t, withPriceCatalog(catalog))
+
+	_, err = daemon.Organizations.UpdateOrganization(ctx, org.Name, organization.UpdateOptions{
+		CostEstimationEnabled: new(true),
+	})
+	require.NoError(t, err)
+
+	ws := daemon.createWorkspace(t, ctx, org)
+	cv := daemon.createAndUploadConfigurationVersion(t, ctx, ws, nil)
+	r := daemon.createRun(t, ctx, ws, cv, nil)
+	daemon.waitRunStatus(t, ctx, r.ID, runstatus.CostEstimated)
+
+	estimate, err := daemon.CostEstimates.GetRunCostEstimate(ctx, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, costestimate.EstimateFinished, estimate.Status)
+	assert.Equal(t, 0.0, estimate.PriorMonthlyCost)
+	assert.Equal(t, 12.5, estimate.ProposedMonthlyCost)
+	assert.Equal(t, 1, estimate.MatchedResourcesCount())
+}
@@ -5,10 +5,13 @@ import (
 
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authenticator"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/daemon"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/engine"
 	"github.com/leg100/otf/internal/github/testserver"
 	"github.com/leg100/otf/internal/runner"
+	"github.com/leg100/otf/internal/secrets"
 )
 
 // configures the daemon for integration tests
@@ -76,6 +79,12 @@ func withSiteToken(token string) configOption {
 	}
 }
 
+func withSCIMToken(token string) configOption {
+	return func(cfg *config) {
+		cfg.SCIMToken = token
+	}
+}
+
 func withSiteAdmins(admins ...string) configOption {
 	return func(cfg *config) {
 		cfg.SiteAdmins = admins
@@ -104,6 +113,38 @@ func withDeleteConfigsAfter(deleteConfigsAfter, checkInterval time.Duration) con
 	}
 }
 
+func withBlobStore(cfg blob.Config, reaperInterval time.Duration) configOption {
+	return func(c *config) {
+		c.BlobStore = cfg
+		c.OverrideDeleterInterval = reaperInterval
+	}
+}
+
+func withEncryption(cfg encryption.Config) configOption {
+	return func(c *config) {
+		c.Encryption = cfg
+	}
+}
+
+func withSecrets(cfg secrets.Config) configOption {
+	return func(c *config) {
+		c.Secrets = cfg
+	}
+}
+
+func withAssessments(interval, checkInterval time.Duration) configOption {
+	return func(cfg *config) {
+		cfg.AssessmentInterval = interval
+		cfg.OverrideAssessorInterval = checkInterval
+	}
+}
+
+func withPriceCatalog(path string) configOption {
+	return func(cfg *config) {
+		cfg.PriceCatalog = path
+	}
+}
+
 func disableRunner() configOption {
 	return func(cfg *config) {
 		cfg.DisableRunner = true
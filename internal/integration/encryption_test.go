@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leg100/otf/internal/encryption"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/runtask"
+	"github.com/leg100/otf/internal/sql"
+	"github.com/leg100/otf/internal/variable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_Encryption tests encrypting secrets in the database and
+// rotating the encryption key.
+func TestIntegration_Encryption(t *testing.T) {
+	integrationTest(t)
+
+	dir := t.TempDir()
+	writeKey := func(t *testing.T, name string, b byte) string {
+		path := filepath.Join(dir, name)
+		key := hex.EncodeToString(bytes.Repeat([]byte{b}, 32))
+		require.NoError(t, os.WriteFile(path, []byte(key), 0o600))
+		return path
+	}
+	oldKey := writeKey(t, "old.key", 1)
+	newKey := writeKey(t, "new.key", 2)
+
+	daemon, _, ctx := setup(t, withEncryption(encryption.Config{KeyFile: oldKey}))
+
+	v := daemon.createVariable(t, ctx, nil, &variable.CreateVariableOptions{
+		Key:       new("password"),
+		Value:     new("hunter2"),
+		Category:  new(variable.CategoryTerraform),
+		Sensitive: new(true),
+	})
+	provider := daemon.createVCSProvider(t, ctx, nil, nil)
+	task, err := daemon.RunTasks.CreateTask(ctx, provider.Organization, runtask.CreateTaskOptions{
+		Name:    "my-task",
+		URL:     "https://example.com/task",
+		HMACKey: new("s3cr3t"),
+	})
+	require.NoError(t, err)
+
+	rawValue := func(t *testing.T) string {
+		value, err := sql.CollectOneType[string](daemon.DB.Query(ctx, `SELECT value FROM variables WHERE variable_id = $1`, v.ID))
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("secrets are encrypted in the database", func(t *testing.T) {
+		assert.True(t, encryption.IsEncrypted([]byte(rawValue(t))))
+
+		token, err := sql.CollectOneType[string](daemon.DB.Query(ctx, `SELECT token FROM vcs_providers WHERE vcs_provider_id = $1`, provider.ID))
+		require.NoError(t, err)
+		assert.True(t, encryption.IsEncrypted([]byte(token)))
+
+		hmacKey, err := sql.CollectOneType[string](daemon.DB.Query(ctx, `SELECT hmac_key FROM run_tasks WHERE run_task_id = $1`, task.ID))
+		require.NoError(t, err)
+		assert.True(t, encryption.IsEncrypted([]byte(hmacKey)))
+	})
+
+	t.Run("secrets are decrypted by services", func(t *testing.T) {
+		got, err := daemon.Variables.GetVariable(ctx, v.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", got.Value)
+
+		gotProvider, err := daemon.VCSProviders.GetVCSProvider(ctx, provider.ID)
+		require.NoError(t, err)
+		assert.Equal(t, provider.Token, gotProvider.Token)
+
+		gotTask, err := daemon.RunTasks.GetTask(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", *gotTask.HMACKey)
+	})
+
+	t.Run("rotate key", func(t *testing.T) {
+		cipher, err := encryption.New(encryption.Config{KeyFile: newKey, PreviousKeyFile: oldKey})
+		require.NoError(t, err)
+
+		n, err := encryption.Rotate(ctx, logr.Discard(), daemon.DB, cipher)
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		// Secrets can now be decrypted with the new key alone.
+		cipher, err = encryption.New(encryption.Config{KeyFile: newKey})
+		require.NoError(t, err)
+		got, err := cipher.DecryptString(ctx, rawValue(t))
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", got)
+
+		// Rotating again is a no-op.
+		n, err = encryption.Rotate(ctx, logr.Discard(), daemon.DB, cipher)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+}
@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal/github/testserver"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/leg100/otf/internal/vcs"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGithubPullRequestComment demonstrates a comment summarising the plan
+// being posted to a pull request, and the comment being updated in place when
+// further commits are pushed to the pull request.
+func TestGithubPullRequestComment(t *testing.T) {
+	integrationTest(t)
+
+	daemon, org, ctx := setup(t, withGithubOptions(
+		testserver.WithRepo(vcs.NewMustRepo("leg100", "otf-workspaces")),
+		testserver.WithArchive(testutils.ReadFile(t, "../testdata/github.tar.gz")),
+		testserver.WithPullRequest("2", "/foo/bar/match.tf"),
+	))
+
+	provider := daemon.createVCSProvider(t, ctx, org, nil)
+	_, err := daemon.Workspaces.CreateWorkspace(ctx, workspace.CreateOptions{
+		Name:         new("dev"),
+		Organization: &org.Name,
+		ConnectOptions: &workspace.ConnectOptions{
+			VCSProviderID:       &provider.ID,
+			RepoPath:            new(vcs.NewMustRepo("leg100", "otf-workspaces")),
+			PullRequestComments: new(true),
+		},
+	})
+	require.NoError(t, err)
+
+	// opening the pull request should result in a comment being created
+	daemon.SendEvent(t, testserver.PullRequest, testutils.ReadFile(t, "fixtures/github_pull_opened.json"))
+	created := daemon.GetComment(t, ctx)
+	assert.Contains(t, created.GetBody(), "#### OTF plan for workspace `dev`")
+	assert.Contains(t, created.GetBody(), "**Plan: 2 to add, 0 to change, 0 to destroy.**")
+	assert.Contains(t, created.GetBody(), "Commit `c560613`")
+
+	// pushing a further commit should update the existing comment
+	daemon.SendEvent(t, testserver.PullRequest, testutils.ReadFile(t, "fixtures/github_pull_update.json"))
+	updated := daemon.GetComment(t, ctx)
+	assert.Equal(t, created.GetID(), updated.GetID())
+	assert.Contains(t, updated.GetBody(), "Commit `067e2b4`")
+}
@@ -202,7 +202,7 @@ func doMain(m *testing.M) (int, error) {
 	// Download engines now rather than in individual tests because it would
 	// otherwise make the latter flaky.
 	{
-		downloader, err := engine.NewDownloader(logr.Discard(), engine.Terraform(), "")
+		downloader, err := engine.NewDownloader(logr.Discard(), engine.Terraform(), "", nil)
 		if err != nil {
 			return 0, fmt.Errorf("creating downloader: %w", err)
 		}
@@ -212,7 +212,7 @@ func doMain(m *testing.M) (int, error) {
 		}
 	}
 	{
-		downloader, err := engine.NewDownloader(logr.Discard(), engine.Tofu(), "")
+		downloader, err := engine.NewDownloader(logr.Discard(), engine.Tofu(), "", nil)
 		if err != nil {
 			return 0, fmt.Errorf("creating downloader: %w", err)
 		}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/project"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/user"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Project(t *testing.T) {
+	integrationTest(t)
+
+	daemon, org, ctx := setup(t)
+
+	t.Run("create", func(t *testing.T) {
+		prj, err := daemon.Projects.CreateProject(ctx, org.Name, project.CreateOptions{Name: "networking"})
+		require.NoError(t, err)
+
+		got, err := daemon.Projects.GetProject(ctx, prj.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "networking", got.Name)
+	})
+
+	t.Run("list workspaces in project", func(t *testing.T) {
+		prj, err := daemon.Projects.CreateProject(ctx, org.Name, project.CreateOptions{Name: "list-test"})
+		require.NoError(t, err)
+
+		inProject, err := daemon.Workspaces.CreateWorkspace(ctx, workspace.CreateOptions{
+			Name:         new("in-project"),
+			Organization: &org.Name,
+			ProjectID:    &prj.ID,
+		})
+		require.NoError(t, err)
+		_ = daemon.createWorkspace(t, ctx, org)
+
+		page, err := daemon.Workspaces.ListWorkspaces(ctx, workspace.ListOptions{
+			Organization: &org.Name,
+			ProjectID:    &prj.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, inProject.ID, page.Items[0].ID)
+	})
+
+	t.Run("cannot move workspace into project in another organization", func(t *testing.T) {
+		other := daemon.createOrganization(t, ctx)
+		prj, err := daemon.Projects.CreateProject(ctx, other.Name, project.CreateOptions{Name: "other"})
+		require.NoError(t, err)
+		ws := daemon.createWorkspace(t, ctx, org)
+
+		_, err = daemon.Workspaces.UpdateWorkspace(ctx, ws.ID, workspace.UpdateOptions{
+			UpdateProjectOptions: &workspace.UpdateProjectOptions{ProjectID: &prj.ID},
+		})
+		assert.ErrorIs(t, err, workspace.ErrProjectInDifferentOrganization)
+	})
+
+	t.Run("cannot delete project with workspaces", func(t *testing.T) {
+		prj, err := daemon.Projects.CreateProject(ctx, org.Name, project.CreateOptions{Name: "delete-test"})
+		require.NoError(t, err)
+		ws := daemon.createWorkspace(t, ctx, org)
+		_, err = daemon.Workspaces.UpdateWorkspace(ctx, ws.ID, workspace.UpdateOptions{
+			UpdateProjectOptions: &workspace.UpdateProjectOptions{ProjectID: &prj.ID},
+		})
+		require.NoError(t, err)
+
+		_, err = daemon.Projects.DeleteProject(ctx, prj.ID)
+		assert.ErrorIs(t, err, project.ErrProjectHasWorkspaces)
+
+		// remove workspace from project and try again
+		_, err = daemon.Workspaces.UpdateWorkspace(ctx, ws.ID, workspace.UpdateOptions{
+			UpdateProjectOptions: &workspace.UpdateProjectOptions{},
+		})
+		require.NoError(t, err)
+		_, err = daemon.Projects.DeleteProject(ctx, prj.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("project permission grants access to workspaces", func(t *testing.T) {
+		prj, err := daemon.Projects.CreateProject(ctx, org.Name, project.CreateOptions{Name: "perms-test"})
+		require.NoError(t, err)
+		ws, err := daemon.Workspaces.CreateWorkspace(ctx, workspace.CreateOptions{
+			Name:         new("perms-test"),
+			Organization: &org.Name,
+			ProjectID:    &prj.ID,
+		})
+		require.NoError(t, err)
+
+		// Create user and add as member of engineers team
+		engineer := daemon.createUser(t)
+		engineers := daemon.createTeam(t, ctx, org)
+		err = daemon.Users.AddTeamMembership(ctx, engineers.ID, []user.Username{engineer.Username})
+		require.NoError(t, err)
+		_, engineerCtx := daemon.getUserCtx(t, adminCtx, engineer.Username)
+
+		// Engineer cannot yet access workspace
+		_, err = daemon.Workspaces.GetWorkspace(engineerCtx, ws.ID)
+		require.True(t, errors.Is(err, internal.ErrAccessNotPermitted))
+
+		err = daemon.Projects.SetProjectPermission(ctx, prj.ID, engineers.ID, authz.WorkspaceReadRole)
+		require.NoError(t, err)
+
+		_, err = daemon.Workspaces.GetWorkspace(engineerCtx, ws.ID)
+		require.NoError(t, err)
+
+		// Engineer sees the workspace when listing workspaces
+		page, err := daemon.Workspaces.ListWorkspaces(engineerCtx, workspace.ListOptions{
+			Organization: &org.Name,
+		})
+		require.NoError(t, err)
+		ids := internal.Map(page.Items, func(ws *workspace.Workspace) resource.TfeID { return ws.ID })
+		assert.Contains(t, ids, ws.ID)
+	})
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/leg100/otf/internal/registryprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RegistryProvider(t *testing.T) {
+	integrationTest(t)
+
+	daemon, org, ctx := setup(t)
+
+	provider, err := daemon.RegistryProviders.CreateProvider(ctx, registryprovider.CreateOptions{
+		Organization: org.Name,
+		Name:         "acme",
+	})
+	require.NoError(t, err)
+
+	version, err := daemon.RegistryProviders.CreateVersion(ctx, provider.ID, registryprovider.CreateVersionOptions{
+		Version:    "1.0.0",
+		KeyID:      "51852D87348FFC4C",
+		ASCIIArmor: "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+	})
+	require.NoError(t, err)
+
+	zip := []byte("provider binary")
+	sum := sha256.Sum256(zip)
+	shasums := fmt.Appendf(nil, "%s  terraform-provider-acme_1.0.0_linux_amd64.zip\n", hex.EncodeToString(sum[:]))
+
+	t.Run("upload platform before shasums", func(t *testing.T) {
+		_, err := daemon.RegistryProviders.UploadPlatform(ctx, version.ID, "linux", "amd64", zip)
+		assert.ErrorIs(t, err, registryprovider.ErrMissingShasums)
+	})
+
+	err = daemon.RegistryProviders.UploadShasums(ctx, version.ID, shasums)
+	require.NoError(t, err)
+	err = daemon.RegistryProviders.UploadShasumsSignature(ctx, version.ID, []byte("signature"))
+	require.NoError(t, err)
+
+	t.Run("upload platform with mismatched checksum", func(t *testing.T) {
+		_, err := daemon.RegistryProviders.UploadPlatform(ctx, version.ID, "linux", "amd64", []byte("tampered"))
+		assert.ErrorIs(t, err, registryprovider.ErrShasumMismatch)
+	})
+
+	_, err = daemon.RegistryProviders.UploadPlatform(ctx, version.ID, "linux", "amd64", zip)
+	require.NoError(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		got, err := daemon.RegistryProviders.GetProvider(ctx, org.Name, "acme")
+		require.NoError(t, err)
+
+		require.Len(t, got.Versions, 1)
+		assert.Equal(t, "1.0.0", got.Versions[0].Version)
+		assert.True(t, got.Versions[0].ShasumsUploaded)
+		assert.True(t, got.Versions[0].ShasumsSignatureUploaded)
+		require.Len(t, got.Versions[0].Platforms, 1)
+		assert.Equal(t, "linux", got.Versions[0].Platforms[0].OS)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		got, err := daemon.RegistryProviders.ListProviders(ctx, org.Name)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("duplicate version", func(t *testing.T) {
+		_, err := daemon.RegistryProviders.CreateVersion(ctx, provider.ID, registryprovider.CreateVersionOptions{
+			Version:    "1.0.0",
+			KeyID:      "51852D87348FFC4C",
+			ASCIIArmor: "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := daemon.RegistryProviders.DeleteProvider(ctx, provider.ID)
+		require.NoError(t, err)
+	})
+}
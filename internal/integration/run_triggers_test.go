@@ -7,7 +7,9 @@ import (
 
 	"github.com/leg100/otf/internal/path"
 	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run/trigger"
 	"github.com/mxschmitt/playwright-go"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,8 +42,11 @@ func TestRunTriggers(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		// form should auto submit and redirect to same page with triggering
-		// workspace listed as connected workspace.
+		err = page.Locator(`//button[@id='connect-button']`).Click()
+		require.NoError(t, err)
+
+		// should redirect to same page with triggering workspace listed as
+		// connected workspace.
 		err = expect.Locator(page.Locator(`//table/tbody/tr/td[1]`)).ToHaveText(triggering.Name)
 		require.NoError(t, err)
 
@@ -115,7 +120,7 @@ func TestRunTriggers(t *testing.T) {
 		require.NoError(t, err)
 
 		// Now delete the connection to the triggering workspace.
-		err = page.Locator(`//table/tbody/tr/td[2]//button`).Click()
+		err = page.Locator(`//table/tbody/tr/td[3]//button`).Click()
 		require.NoError(t, err)
 
 		// Should redirect to same page and show alert.
@@ -123,3 +128,30 @@ func TestRunTriggers(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+// TestRunTriggers_Cycle tests that triggers forming a cycle are rejected.
+func TestRunTriggers_Cycle(t *testing.T) {
+	integrationTest(t)
+
+	daemon, org, ctx := setup(t)
+
+	ws1 := daemon.createWorkspace(t, ctx, org)
+	ws2 := daemon.createWorkspace(t, ctx, org)
+	ws3 := daemon.createWorkspace(t, ctx, org)
+
+	// ws1 -> ws2 -> ws3
+	_, err := daemon.RunTriggers.CreateRunTrigger(ctx, ws2.ID, ws1.ID, trigger.Conditions{})
+	require.NoError(t, err)
+	_, err = daemon.RunTriggers.CreateRunTrigger(ctx, ws3.ID, ws2.ID, trigger.Conditions{
+		RequireResourceChanges: true,
+	})
+	require.NoError(t, err)
+
+	// ws3 -> ws1 would complete a cycle
+	_, err = daemon.RunTriggers.CreateRunTrigger(ctx, ws1.ID, ws3.ID, trigger.Conditions{})
+	require.ErrorIs(t, err, trigger.ErrTriggerCycle)
+
+	graph, err := daemon.RunTriggers.GetRunTriggerGraph(ctx, org.Name)
+	require.NoError(t, err)
+	assert.Equal(t, [][]resource.TfeID{{ws1.ID}, {ws2.ID}, {ws3.ID}}, graph.Layers())
+}
@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/client"
+	"github.com/leg100/otf/internal/logr"
+	otfuser "github.com/leg100/otf/internal/user"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_SCIM demonstrates an identity provider provisioning users
+// and teams via the SCIM API.
+func TestIntegration_SCIM(t *testing.T) {
+	integrationTest(t)
+
+	daemon, org, ctx := setup(t, withSCIMToken("scim-token"))
+
+	// scim sends a request to the SCIM API and decodes the response into a
+	// map.
+	scim := func(t *testing.T, method, path, token, body string) (int, map[string]any) {
+		t.Helper()
+
+		r, err := http.NewRequest(method, daemon.System.URL("/scim/v2"+path), strings.NewReader(body))
+		require.NoError(t, err)
+		r.Header.Add("Authorization", "Bearer "+token)
+		r.Header.Add("Content-Type", "application/scim+json")
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var got map[string]any
+		if resp.StatusCode != http.StatusNoContent {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		}
+		return resp.StatusCode, got
+	}
+
+	t.Run("reject invalid token", func(t *testing.T) {
+		status, _ := scim(t, "GET", "/Users", "invalid-token", "")
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	// provision user
+	status, created := scim(t, "POST", "/Users", "scim-token", `{"userName":"bobby","active":true}`)
+	require.Equal(t, http.StatusCreated, status, created)
+	userID := created["id"].(string)
+
+	bobby := daemon.getUser(t, adminCtx, otfuser.MustUsername("bobby"))
+	assert.False(t, bobby.Disabled)
+
+	// provision team with user as member
+	status, group := scim(t, "POST", "/Groups", "scim-token", `{"displayName":"`+org.Name.String()+`/devs","members":[{"value":"`+userID+`"}]}`)
+	require.Equal(t, http.StatusCreated, status, group)
+
+	devs := daemon.getTeam(t, ctx, org.Name, "devs")
+	members, err := daemon.Users.ListTeamUsers(ctx, devs.ID)
+	require.NoError(t, err)
+	if assert.Equal(t, 1, len(members)) {
+		assert.Equal(t, "bobby", members[0].Username.String())
+	}
+
+	// user can authenticate with their API token
+	_, token := daemon.createToken(t, ctx, bobby)
+	client, err := client.New(logr.Discard(), daemon.System.URL("/"), string(token))
+	require.NoError(t, err)
+	_, err = client.ListWorkspaces(ctx, workspace.ListOptions{Organization: &org.Name})
+	require.NoError(t, err)
+
+	// deactivate user
+	status, patched := scim(t, "PATCH", "/Users/"+userID, "scim-token", `{"Operations":[{"op":"replace","path":"active","value":false}]}`)
+	require.Equal(t, http.StatusOK, status, patched)
+	assert.Equal(t, false, patched["active"])
+
+	// user can no longer authenticate
+	_, err = client.ListWorkspaces(ctx, workspace.ListOptions{Organization: &org.Name})
+	assert.Equal(t, internal.ErrUnauthorized, err)
+
+	// deprovision user
+	status, _ = scim(t, "DELETE", "/Users/"+userID, "scim-token", "")
+	require.Equal(t, http.StatusNoContent, status)
+
+	_, err = daemon.Users.GetUser(adminCtx, otfuser.UserSpec{Username: new(otfuser.MustUsername("bobby"))})
+	assert.ErrorIs(t, err, internal.ErrResourceNotFound)
+}
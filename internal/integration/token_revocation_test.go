@@ -0,0 +1,34 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeTokens(t *testing.T) {
+	integrationTest(t)
+
+	daemon, _, ctx := setup(t)
+
+	user, userCtx := daemon.createUserCtx(t)
+	deleted, _ := daemon.createToken(t, ctx, user)
+	remaining, _ := daemon.createToken(t, ctx, user)
+
+	// Delete one token before it is revoked, e.g. by its user whilst the site
+	// admin is viewing a stale list of tokens.
+	err := daemon.Users.DeleteToken(userCtx, deleted.ID)
+	require.NoError(t, err)
+
+	err = daemon.Tokens.RevokeTokens(ctx, []resource.TfeID{deleted.ID, remaining.ID})
+	require.NoError(t, err)
+
+	page, err := daemon.Tokens.ListTokens(ctx, tokens.ListOptions{})
+	require.NoError(t, err)
+	for _, token := range page.Items {
+		assert.NotEqual(t, remaining.ID, token.ID)
+	}
+}
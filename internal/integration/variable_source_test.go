@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leg100/otf/internal/secrets"
+	"github.com/leg100/otf/internal/variable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_VariableSource tests variables sourcing their values from an
+// external secret provider.
+func TestIntegration_VariableSource(t *testing.T) {
+	integrationTest(t)
+
+	dir := t.TempDir()
+
+	daemon, _, ctx := setup(t, withSecrets(secrets.Config{Dir: dir}))
+	ws := daemon.createWorkspace(t, ctx, nil)
+
+	// secrets reside in a subdirectory named after the organization
+	orgDir := filepath.Join(dir, ws.Organization.String())
+	require.NoError(t, os.Mkdir(orgDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(orgDir, "db-password"), []byte("hunter2\n"), 0o600))
+
+	v := daemon.createVariable(t, ctx, ws, &variable.CreateVariableOptions{
+		Key:      new("password"),
+		Category: new(variable.CategoryTerraform),
+		Source:   &variable.Source{Provider: secrets.FileProvider, Reference: "db-password"},
+	})
+
+	// the value is not stored in OTF
+	got, err := daemon.Variables.GetVariable(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "", got.Value)
+	// and a variable with a source is always sensitive
+	assert.True(t, got.Sensitive)
+
+	t.Run("resolve value for run", func(t *testing.T) {
+		run := daemon.createRun(t, ctx, ws, nil, nil)
+
+		vars, err := daemon.Variables.ListEffectiveVariables(ctx, run.ID)
+		require.NoError(t, err)
+		require.Len(t, vars, 1)
+		assert.Equal(t, "hunter2", vars[0].Value)
+	})
+
+	t.Run("secret belonging to another organization", func(t *testing.T) {
+		other := daemon.createWorkspace(t, ctx, nil)
+		daemon.createVariable(t, ctx, other, &variable.CreateVariableOptions{
+			Key:      new("password"),
+			Category: new(variable.CategoryTerraform),
+			Source:   &variable.Source{Provider: secrets.FileProvider, Reference: "db-password"},
+		})
+		run := daemon.createRun(t, ctx, other, nil, nil)
+
+		_, err := daemon.Variables.ListEffectiveVariables(ctx, run.ID)
+		assert.ErrorIs(t, err, secrets.ErrSecretNotFound)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := daemon.Variables.UpdateVariable(ctx, v.ID, variable.UpdateVariableOptions{
+			Source: &variable.Source{Provider: secrets.FileProvider, Reference: "missing"},
+		})
+		require.NoError(t, err)
+
+		run := daemon.createRun(t, ctx, ws, nil, nil)
+
+		_, err = daemon.Variables.ListEffectiveVariables(ctx, run.ID)
+		assert.ErrorIs(t, err, secrets.ErrSecretNotFound)
+		assert.ErrorContains(t, err, "retrieving value of variable password from file secret provider")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := daemon.Variables.CreateVariable(ctx, ws.ID, variable.CreateVariableOptions{
+			Key:      new("token"),
+			Category: new(variable.CategoryEnv),
+			Source:   &variable.Source{Provider: secrets.VaultProvider, Reference: "token#value"},
+		})
+		assert.ErrorIs(t, err, secrets.ErrUnknownProvider)
+	})
+}
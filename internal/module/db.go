@@ -5,6 +5,7 @@ import (
 	"slices"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/semver"
@@ -15,6 +16,8 @@ import (
 // pgdb is the registry database on postgres
 type pgdb struct {
 	*sql.DB // provides access to generated SQL queries
+
+	blobs blob.Store
 }
 
 func (db *pgdb) createModule(ctx context.Context, mod *Module) error {
@@ -255,24 +258,11 @@ WHERE module_version_id = $1
 }
 
 func (db *pgdb) saveTarball(ctx context.Context, versionID resource.TfeID, tarball []byte) error {
-	_, err := db.Exec(ctx, `
-INSERT INTO module_tarballs (
-    tarball,
-    module_version_id
-) VALUES (
-    $1,
-    $2
-)`, tarball, versionID)
-	return err
+	return db.blobs.Put(ctx, blob.ModuleTarballKey(versionID), tarball)
 }
 
 func (db *pgdb) getTarball(ctx context.Context, versionID resource.TfeID) ([]byte, error) {
-	rows := db.Query(ctx, `
-SELECT tarball
-FROM module_tarballs
-WHERE module_version_id = $1
-`, versionID)
-	return sql.CollectOneRow(rows, pgx.RowTo[[]byte])
+	return db.blobs.Get(ctx, blob.ModuleTarballKey(versionID))
 }
 
 func (db *pgdb) scanModule(row pgx.CollectableRow) (*Module, error) {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/connections"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
@@ -41,6 +42,7 @@ type (
 		VCSProviderService *vcs.Service
 		ConnectionsService *connections.Service
 		VCSEventSubscriber vcs.Subscriber
+		BlobStore          blob.Store
 	}
 )
 
@@ -49,7 +51,7 @@ func NewService(opts Options) *Service {
 		Logger:       opts.Logger,
 		Authorizer:   opts.Authorizer,
 		connections:  opts.ConnectionsService,
-		db:           &pgdb{opts.DB},
+		db:           &pgdb{opts.DB, opts.BlobStore},
 		vcsproviders: opts.VCSProviderService,
 	}
 	publisher := &publisher{
@@ -28,6 +28,9 @@ const (
 	TriggerApplying       Trigger = "run:applying"
 	TriggerCompleted      Trigger = "run:completed"
 	TriggerErrored        Trigger = "run:errored"
+
+	TriggerAssessmentDrifted Trigger = "assessment:drifted"
+	TriggerAssessmentFailed  Trigger = "assessment:failed"
 )
 
 var (
@@ -181,7 +184,7 @@ func (c *Config) matchTrigger(status runstatus.Status) (Trigger, bool) {
 		return TriggerCreated, c.hasTrigger(TriggerCreated)
 	case runstatus.Planning:
 		return TriggerPlanning, c.hasTrigger(TriggerPlanning)
-	case runstatus.Planned:
+	case runstatus.Planned, runstatus.PostPlanCompleted, runstatus.CostEstimated, runstatus.PolicyChecked, runstatus.PolicyOverride:
 		return TriggerNeedsAttention, c.hasTrigger(TriggerNeedsAttention)
 	case runstatus.Applying:
 		return TriggerApplying, c.hasTrigger(TriggerApplying)
@@ -194,6 +197,18 @@ func (c *Config) matchTrigger(status runstatus.Status) (Trigger, bool) {
 	return "", false
 }
 
+// matchAssessmentTrigger determines whether the config has a trigger that
+// matches the outcome of a health assessment run.
+func (c *Config) matchAssessmentTrigger(status runstatus.Status, drifted bool) (Trigger, bool) {
+	switch {
+	case status == runstatus.PlannedAndFinished && drifted:
+		return TriggerAssessmentDrifted, c.hasTrigger(TriggerAssessmentDrifted)
+	case status == runstatus.Errored, status == runstatus.Canceled, status == runstatus.ForceCanceled:
+		return TriggerAssessmentFailed, c.hasTrigger(TriggerAssessmentFailed)
+	}
+	return "", false
+}
+
 func (c *Config) hasTrigger(t Trigger) bool {
 	return slices.Contains(c.Triggers, t)
 }
@@ -206,7 +221,9 @@ func validTriggers(triggers []Trigger) error {
 			TriggerNeedsAttention,
 			TriggerApplying,
 			TriggerCompleted,
-			TriggerErrored:
+			TriggerErrored,
+			TriggerAssessmentDrifted,
+			TriggerAssessmentFailed:
 		default:
 			return ErrInvalidTrigger
 		}
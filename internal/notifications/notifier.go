@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/pubsub"
 	"github.com/leg100/otf/internal/resource"
@@ -130,6 +131,20 @@ func (s *Notifier) handleRunEvent(ctx context.Context, event pubsub.Event[*run.E
 		return nil
 	}
 
+	// Health assessment runs only trigger assessment notifications, which
+	// depend upon whether drift was detected.
+	var (
+		assessment = event.Payload.Source == source.Assessment
+		drifted    bool
+	)
+	if assessment && event.Payload.Status == runstatus.PlannedAndFinished {
+		run, err := s.runs.GetRun(ctx, event.Payload.ID)
+		if err != nil {
+			return err
+		}
+		drifted = run.HasChanges()
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -147,7 +162,15 @@ func (s *Notifier) handleRunEvent(ctx context.Context, event pubsub.Event[*run.E
 			// skip config with no triggers
 			continue
 		}
-		trigger, matches := cfg.matchTrigger(event.Payload.Status)
+		var (
+			trigger Trigger
+			matches bool
+		)
+		if assessment {
+			trigger, matches = cfg.matchAssessmentTrigger(event.Payload.Status, drifted)
+		} else {
+			trigger, matches = cfg.matchTrigger(event.Payload.Status)
+		}
 		if !matches {
 			// skip config with no matching trigger
 			continue
@@ -3,6 +3,7 @@ package notifications
 import (
 	"testing"
 
+	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/pubsub"
 	"github.com/leg100/otf/internal/resource"
@@ -142,6 +143,60 @@ func TestNotifier_handleRun_multiple(t *testing.T) {
 	assert.Contains(t, got, want)
 }
 
+func TestNotifier_handleAssessmentRun(t *testing.T) {
+	ws1 := testutils.ParseID(t, "ws-123")
+
+	finishedEvent := pubsub.Event[*run.Event]{Payload: &run.Event{
+		ID:          resource.NewTfeID(resource.RunKind),
+		Status:      runstatus.PlannedAndFinished,
+		WorkspaceID: ws1,
+		Source:      source.Assessment,
+	}}
+	erroredEvent := pubsub.Event[*run.Event]{Payload: &run.Event{
+		ID:          resource.NewTfeID(resource.RunKind),
+		Status:      runstatus.Errored,
+		WorkspaceID: ws1,
+		Source:      source.Assessment,
+	}}
+	driftedRun := &run.Run{Plan: run.Phase{ResourceReport: &run.Report{Changes: 1}}}
+	notDriftedRun := &run.Run{}
+	config := newTestConfig(t, ws1, DestinationGeneric, "", TriggerAssessmentDrifted, TriggerAssessmentFailed, TriggerCompleted, TriggerErrored)
+
+	tests := []struct {
+		name  string
+		event pubsub.Event[*run.Event]
+		run   *run.Run
+		want  Trigger
+	}{
+		{"drifted", finishedEvent, driftedRun, TriggerAssessmentDrifted},
+		{"not drifted", finishedEvent, notDriftedRun, ""},
+		{"failed", erroredEvent, nil, TriggerAssessmentFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			published := make(chan *notification, 100)
+			notifier := &Notifier{
+				Logger:     logr.Discard(),
+				workspaces: &fakeWorkspaceService{},
+				runs:       &fakeRunService{run: tt.run},
+				system:     &fakeHostnameService{},
+				cache:      newTestCache(t, &fakeFactory{published}, config),
+			}
+
+			err := notifier.handleRunEvent(t.Context(), tt.event)
+			require.NoError(t, err)
+			if tt.want != "" {
+				// Only the assessment trigger should match, not the run
+				// triggers.
+				require.Equal(t, 1, len(published))
+				assert.Equal(t, tt.want, (<-published).trigger)
+			} else {
+				assert.Equal(t, 0, len(published))
+			}
+		})
+	}
+}
+
 func TestNotifier_handleConfig(t *testing.T) {
 	ws1 := testutils.ParseID(t, "ws-123")
 
@@ -3,6 +3,7 @@ package notifications
 import (
 	"context"
 
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/pubsub"
@@ -21,14 +22,16 @@ type (
 
 		db     *pgdb
 		broker pubsub.SubscriptionService[*Config]
+		audit  audit.Recorder
 	}
 
 	Options struct {
-		DB         *sql.DB
-		Listener   *sql.Listener
-		Logger     logr.Logger
-		Authorizer *authz.Authorizer
-		Broker     pubsub.SubscriptionService[*Config]
+		DB            *sql.DB
+		Listener      *sql.Listener
+		Logger        logr.Logger
+		Authorizer    *authz.Authorizer
+		Broker        pubsub.SubscriptionService[*Config]
+		AuditRecorder audit.Recorder
 	}
 )
 
@@ -38,6 +41,7 @@ func NewService(opts Options) *Service {
 		Authorizer: opts.Authorizer,
 		db:         &pgdb{opts.DB},
 		broker:     opts.Broker,
+		audit:      opts.AuditRecorder,
 	}
 	return &svc
 }
@@ -61,16 +65,28 @@ func (s *Service) CreateNotificationConfig(ctx context.Context, workspaceID reso
 		return nil, err
 	}
 	s.Info("creating notification config", "config", nc, "subject", subject)
+	// The destination URL is omitted from the audit trail because it may
+	// embed credentials, e.g. a slack webhook URL.
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     nc.WorkspaceID,
+		Action:     resource.Create,
+		ResourceID: nc.ID,
+		After:      nc.LogValue(),
+	})
 	return nc, nil
 }
 
 func (s *Service) UpdateNotificationConfig(ctx context.Context, id resource.TfeID, opts UpdateConfigOptions) (*Config, error) {
-	var subject authz.Subject
+	var (
+		subject authz.Subject
+		before  Config
+	)
 	updated, err := s.db.update(ctx, id, func(ctx context.Context, nc *Config) (err error) {
 		subject, err = s.Authorize(ctx, resource.Update, resource.NotificationConfigurationKind, nc.WorkspaceID)
 		if err != nil {
 			return err
 		}
+		before = *nc
 		return nc.update(opts)
 	})
 	if err != nil {
@@ -78,6 +94,13 @@ func (s *Service) UpdateNotificationConfig(ctx context.Context, id resource.TfeI
 		return nil, err
 	}
 	s.Info("updated notification config", "updated", updated, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     updated.WorkspaceID,
+		Action:     resource.Update,
+		ResourceID: updated.ID,
+		Before:     before.LogValue(),
+		After:      updated.LogValue(),
+	})
 	return updated, nil
 }
 
@@ -124,5 +147,11 @@ func (s *Service) DeleteNotificationConfig(ctx context.Context, id resource.TfeI
 		return err
 	}
 	s.Info("deleted notification config", "config", nc, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     nc.WorkspaceID,
+		Action:     resource.Delete,
+		ResourceID: nc.ID,
+		Before:     nc.LogValue(),
+	})
 	return nil
 }
@@ -18,8 +18,10 @@ type (
 		configs []*Config
 	}
 	fakeWorkspaceService struct{}
-	fakeRunService       struct{}
-	fakeHostnameService  struct {
+	fakeRunService       struct {
+		run *run.Run
+	}
+	fakeHostnameService struct {
 		*internal.HostnameService
 	}
 	// fakeFactory makes fake clients
@@ -64,6 +66,9 @@ func (f *fakeWorkspaceService) GetWorkspace(context.Context, resource.TfeID) (*w
 }
 
 func (f *fakeRunService) GetRun(ctx context.Context, id resource.TfeID) (*run.Run, error) {
+	if f.run != nil {
+		return f.run, nil
+	}
 	return &run.Run{ID: id}, nil
 }
 
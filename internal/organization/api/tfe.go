@@ -158,6 +158,7 @@ func (a *tfe) updateOrganization(w http.ResponseWriter, r *http.Request) {
 		SessionTimeout:             tfeOpts.SessionTimeout,
 		AllowForceDeleteWorkspaces: tfeOpts.AllowForceDeleteWorkspaces,
 		DefaultExecutionMode:       tfeOpts.DefaultExecutionMode,
+		EngineVersionConstraint:    tfeOpts.EngineVersionConstraint,
 	}
 	if tfeOpts.DefaultAgentPool != nil {
 		opts.DefaultAgentPoolID = &tfeOpts.DefaultAgentPool.ID
@@ -322,8 +323,9 @@ func (a *tfe) toOrganization(from *organization.Organization) *organization.TFEO
 		AllowForceDeleteWorkspaces: from.AllowForceDeleteWorkspaces,
 		CostEstimationEnabled:      from.CostEstimationEnabled,
 		// go-tfe tests expect this attribute to be equal to 5
-		RemainingTestableCount: 5,
-		DefaultExecutionMode:   from.DefaultMode.Kind(),
+		RemainingTestableCount:  5,
+		DefaultExecutionMode:    from.DefaultMode.Kind(),
+		EngineVersionConstraint: from.EngineVersionConstraint,
 	}
 	if from.Email != nil {
 		to.Email = *from.Email
@@ -72,8 +72,9 @@ SET
 	allow_force_delete_workspaces = $7,
 	default_execution_kind = $8,
 	default_agent_pool_id = $9,
-	updated_at = $10
-WHERE name = $11`,
+	engine_version_constraint = $10,
+	updated_at = $11
+WHERE name = $12`,
 				org.Name,
 				org.Email,
 				org.CollaboratorAuthPolicy,
@@ -83,6 +84,7 @@ WHERE name = $11`,
 				org.AllowForceDeleteWorkspaces,
 				org.DefaultMode.Kind(),
 				org.DefaultMode.AgentPoolID(),
+				org.EngineVersionConstraint,
 				org.UpdatedAt,
 				name,
 			)
@@ -177,7 +179,11 @@ INSERT INTO organization_tokens (
 }
 
 func (db *pgdb) getOrganizationTokenByName(ctx context.Context, organization Name) (*OrganizationToken, error) {
-	row := db.Query(ctx, ` SELECT * FROM organization_tokens WHERE organization_name = $1 `, organization)
+	row := db.Query(ctx, `
+SELECT organization_token_id, created_at, organization_name, expiry
+FROM organization_tokens
+WHERE organization_name = $1
+`, organization)
 	return sql.CollectOneRow(row, db.scanToken)
 }
 
@@ -194,7 +200,7 @@ WHERE organization_name = $1
 
 func (db *pgdb) getOrganizationTokenByID(ctx context.Context, tokenID resource.TfeID) (*OrganizationToken, error) {
 	row := db.Query(ctx, `
-SELECT *
+SELECT organization_token_id, created_at, organization_name, expiry
 FROM organization_tokens
 WHERE organization_token_id = $1
 `,
@@ -230,6 +236,7 @@ func scan(row pgx.CollectableRow) (*Organization, error) {
 		CostEstimationEnabled      bool            `db:"cost_estimation_enabled"`
 		DefaultAgentPoolID         *resource.TfeID `db:"default_agent_pool_id"`
 		DefaultExecutionKind       execution.Kind  `db:"default_execution_kind"`
+		EngineVersionConstraint    *string         `db:"engine_version_constraint"`
 	}
 	m, err := pgx.RowToStructByName[model](row)
 	if err != nil {
@@ -246,6 +253,7 @@ func scan(row pgx.CollectableRow) (*Organization, error) {
 		SessionTimeout:             m.SessionTimeout,
 		AllowForceDeleteWorkspaces: m.AllowForceDeleteWorkspaces,
 		CostEstimationEnabled:      m.CostEstimationEnabled,
+		EngineVersionConstraint:    m.EngineVersionConstraint,
 	}
 	mode, err := execution.NewMode(m.DefaultExecutionKind, m.DefaultAgentPoolID)
 	if err != nil {
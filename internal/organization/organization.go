@@ -2,8 +2,12 @@
 package organization
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/hashicorp/go-version"
+
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/workspace/execution"
@@ -14,6 +18,10 @@ const (
 	DefaultSessionExpiration = 20160
 )
 
+// ErrEngineVersionNotAllowed is returned when an engine version is not
+// permitted by an organization's engine version constraint.
+var ErrEngineVersionNotAllowed = errors.New("engine version not allowed by organization")
+
 type (
 	// Organization is an OTF organization, comprising workspaces, users, etc.
 	Organization struct {
@@ -31,6 +39,10 @@ type (
 		AllowForceDeleteWorkspaces bool    `db:"allow_force_delete_workspaces"`
 		CostEstimationEnabled      bool    `db:"cost_estimation_enabled"`
 		DefaultMode                execution.Mode
+
+		// EngineVersionConstraint optionally restricts the engine versions
+		// that workspaces in the organization may use, e.g. ">= 1.6, < 2.0".
+		EngineVersionConstraint *string `db:"engine_version_constraint"`
 	}
 
 	// UpdateOptions represents the options for updating an organization.
@@ -47,6 +59,10 @@ type (
 		AllowForceDeleteWorkspaces *bool
 		DefaultExecutionMode       *execution.Kind
 		DefaultAgentPoolID         *resource.TfeID
+
+		// EngineVersionConstraint sets the organization's engine version
+		// constraint. An empty string removes the constraint.
+		EngineVersionConstraint *string
 	}
 
 	// CreateOptions represents the options for creating an organization. See
@@ -138,6 +154,37 @@ func (org *Organization) Update(opts UpdateOptions) error {
 	if err := org.DefaultMode.Update(opts.DefaultExecutionMode, opts.DefaultAgentPoolID); err != nil {
 		return err
 	}
+	if opts.EngineVersionConstraint != nil {
+		if *opts.EngineVersionConstraint == "" {
+			org.EngineVersionConstraint = nil
+		} else {
+			if _, err := version.NewConstraint(*opts.EngineVersionConstraint); err != nil {
+				return fmt.Errorf("invalid engine version constraint: %w", err)
+			}
+			org.EngineVersionConstraint = opts.EngineVersionConstraint
+		}
+	}
 	org.UpdatedAt = internal.CurrentTimestamp(nil)
 	return nil
 }
+
+// CheckEngineVersion checks whether the engine version is permitted by the
+// organization's engine version constraint. Any version is permitted if the
+// organization has no constraint.
+func (org *Organization) CheckEngineVersion(v string) error {
+	if org.EngineVersionConstraint == nil {
+		return nil
+	}
+	constraint, err := version.NewConstraint(*org.EngineVersionConstraint)
+	if err != nil {
+		return err
+	}
+	parsed, err := version.NewVersion(v)
+	if err != nil {
+		return err
+	}
+	if !constraint.Check(parsed) {
+		return fmt.Errorf("%w: %s does not satisfy %s", ErrEngineVersionNotAllowed, v, *org.EngineVersionConstraint)
+	}
+	return nil
+}
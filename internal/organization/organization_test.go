@@ -0,0 +1,47 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganization_CheckEngineVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint *string
+		version    string
+		want       error
+	}{
+		{"no constraint", nil, "1.9.0", nil},
+		{"satisfies constraint", new("~> 1.8.0"), "1.8.5", nil},
+		{"violates constraint", new("~> 1.8.0"), "1.9.0", ErrEngineVersionNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := &Organization{EngineVersionConstraint: tt.constraint}
+			err := org.CheckEngineVersion(tt.version)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOrganization_Update_EngineVersionConstraint(t *testing.T) {
+	org := &Organization{}
+
+	err := org.Update(UpdateOptions{EngineVersionConstraint: new(">= 1.6.0, < 2.0.0")})
+	require.NoError(t, err)
+	assert.Equal(t, ">= 1.6.0, < 2.0.0", *org.EngineVersionConstraint)
+
+	err = org.Update(UpdateOptions{EngineVersionConstraint: new("not a constraint")})
+	assert.Error(t, err)
+
+	err = org.Update(UpdateOptions{EngineVersionConstraint: new("")})
+	require.NoError(t, err)
+	assert.Nil(t, org.EngineVersionConstraint)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
@@ -26,6 +27,7 @@ type (
 
 		db           *pgdb
 		tokenFactory *tokenFactory
+		audit        audit.Recorder
 
 		afterCreateHooks  []func(context.Context, *Organization) error
 		beforeDeleteHooks []func(context.Context, *Organization) error
@@ -37,6 +39,7 @@ type (
 		Authorizer                   *authz.Authorizer
 		DB                           *sql.DB
 		Logger                       logr.Logger
+		AuditRecorder                audit.Recorder
 	}
 
 	// ListOptions represents the options for listing organizations.
@@ -52,6 +55,7 @@ func NewService(opts Options) *Service {
 		RestrictOrganizationCreation: opts.RestrictOrganizationCreation,
 		db:                           &pgdb{opts.DB},
 		tokenFactory:                 &tokenFactory{tokens: opts.TokensService},
+		audit:                        opts.AuditRecorder,
 	}
 	// Register with auth middleware the organization token and a means of
 	// retrieving organization corresponding to token.
@@ -118,7 +122,9 @@ func (s *Service) UpdateOrganization(ctx context.Context, name Name, opts Update
 	if err != nil {
 		return nil, err
 	}
+	var before *Organization
 	org, err := s.db.update(ctx, name, func(ctx context.Context, org *Organization) error {
+		before = new(*org)
 		return org.Update(opts)
 	})
 	if err != nil {
@@ -127,6 +133,13 @@ func (s *Service) UpdateOrganization(ctx context.Context, name Name, opts Update
 	}
 
 	s.V(2).Info("updated organization", "name", name, "id", org.ID, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: org.Name,
+		Action:       resource.Update,
+		ResourceID:   org.ID,
+		Before:       before,
+		After:        org,
+	})
 	return org, nil
 }
 
@@ -240,6 +253,12 @@ func (s *Service) CreateOrganizationToken(ctx context.Context, opts CreateOrgani
 	}
 
 	s.V(0).Info("created organization token", "organization", opts.Organization)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: ot.Organization,
+		Action:       resource.Create,
+		ResourceID:   ot.ID,
+		After:        ot,
+	})
 
 	return ot, token, nil
 }
@@ -280,12 +299,24 @@ func (s *Service) DeleteOrganizationToken(ctx context.Context, organization Name
 		return err
 	}
 
+	ot, err := s.db.getOrganizationTokenByName(ctx, organization)
+	if err != nil {
+		s.Error(err, "retrieving organization token", "organization", organization)
+		return err
+	}
+
 	if err := s.db.deleteOrganizationToken(ctx, organization); err != nil {
 		s.Error(err, "deleting organization token", "organization", organization)
 		return err
 	}
 
 	s.V(0).Info("deleted organization token", "organization", organization)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: organization,
+		Action:       resource.Delete,
+		ResourceID:   ot.ID,
+		Before:       ot,
+	})
 
 	return nil
 }
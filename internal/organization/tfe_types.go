@@ -37,6 +37,10 @@ type TFEOrganization struct {
 	// On those TFE versions, safe delete does not exist, so ALL deletes will be force deletes.
 	AllowForceDeleteWorkspaces bool `jsonapi:"attribute" json:"allow-force-delete-workspaces"`
 
+	// EngineVersionConstraint restricts the engine versions that workspaces
+	// may use. Not part of the TFE API.
+	EngineVersionConstraint *string `jsonapi:"attribute" json:"engine-version-constraint,omitempty"`
+
 	// Relations
 	// DefaultProject *Project `jsonapi:"relation,default-project"`
 	DefaultAgentPool *TFEAgentPool `jsonapi:"relationship" json:"default-agent-pool"`
@@ -138,6 +142,11 @@ type TFEOrganizationUpdateOptions struct {
 	// Optional: DefaultExecutionMode the default execution mode for workspaces
 	DefaultExecutionMode *execution.Kind `jsonapi:"attribute" json:"default-execution-mode,omitempty"`
 
+	// Optional: EngineVersionConstraint restricts the engine versions that
+	// workspaces may use; an empty string removes the restriction. Not part of
+	// the TFE API.
+	EngineVersionConstraint *string `jsonapi:"attribute" json:"engine-version-constraint,omitempty"`
+
 	// Optional: DefaultAgentPoolId default agent pool for workspaces, requires DefaultExecutionMode to be set to `agent`
 	DefaultAgentPool *TFEAgentPool `jsonapi:"relationship" json:"default-agent-pool,omitempty"`
 }
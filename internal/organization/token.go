@@ -51,6 +51,8 @@ func (u *OrganizationToken) String() string {
 	return u.ID.String()
 }
 
+func (u *OrganizationToken) GetID() resource.TfeID { return u.ID }
+
 func (u *OrganizationToken) CanAccess(action resource.Action, kind resource.Kind, req authz.Request) bool {
 	if req.ID == resource.SiteID {
 		// Organization token cannot take action on site-level resources
@@ -43,6 +43,8 @@ func (h *Handlers) AddHandlers(r *mux.Router) {
 	r.HandleFunc("/organizations/{name}", h.getOrganization).Methods("GET")
 	r.HandleFunc("/organizations/{name}/edit", h.editOrganization).Methods("GET")
 	r.HandleFunc("/organizations/{name}/update", h.updateOrganization).Methods("POST")
+	r.HandleFunc("/organizations/{name}/update-cost-estimation", h.updateCostEstimation).Methods("POST")
+	r.HandleFunc("/organizations/{name}/update-engine-version-constraint", h.updateEngineVersionConstraint).Methods("POST")
 	r.HandleFunc("/organizations/{name}/delete", h.deleteOrganization).Methods("POST")
 	r.HandleFunc("/organizations/{name}/edit-advanced", h.editAdvancedOrganization).Methods("GET")
 
@@ -181,6 +183,50 @@ func (h *Handlers) updateOrganization(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, path.Edit(org.Name), http.StatusFound)
 }
 
+func (h *Handlers) updateCostEstimation(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name    organization.Name `schema:"name,required"`
+		Enabled bool              `schema:"cost_estimation_enabled"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	org, err := h.Organizations.UpdateOrganization(r.Context(), params.Name, organization.UpdateOptions{
+		CostEstimationEnabled: &params.Enabled,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated cost estimation setting")
+	http.Redirect(w, r, path.Edit(org.Name), http.StatusFound)
+}
+
+func (h *Handlers) updateEngineVersionConstraint(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name       organization.Name `schema:"name,required"`
+		Constraint string            `schema:"engine_version_constraint"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	org, err := h.Organizations.UpdateOrganization(r.Context(), params.Name, organization.UpdateOptions{
+		EngineVersionConstraint: &params.Constraint,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated allowed engine versions")
+	http.Redirect(w, r, path.Edit(org.Name), http.StatusFound)
+}
+
 func (h *Handlers) deleteOrganization(w http.ResponseWriter, r *http.Request) {
 	var params struct {
 		Name organization.Name `schema:"name"`
@@ -125,3 +125,11 @@ func UpdateVariableSetVariable(id resource.ID) string {
 func DeleteVariableSetVariable(id resource.ID) string {
 	return fmt.Sprintf("/app/variable-set-variables/%v/delete", id)
 }
+
+func SiteTokens() string {
+	return "/app/admin/tokens"
+}
+
+func RevokeTokens() string {
+	return "/app/admin/tokens/revoke"
+}
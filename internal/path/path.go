@@ -97,13 +97,17 @@ func Create(kind resource.Kind, parentID resource.ID) string {
 }
 
 // Add an 's' to make a plural form of the string unless the string
-// already ends with an 's'.
+// already ends with an 's'. A trailing 'y' is replaced with 'ies'.
 func pluralise(s string) string {
 	if len(s) == 0 {
 		return ""
 	}
-	if s[len(s)-1] != 's' {
+	switch s[len(s)-1] {
+	case 's':
+		return s
+	case 'y':
+		return s[:len(s)-1] + "ies"
+	default:
 		return s + "s"
 	}
-	return s
 }
@@ -12,6 +12,7 @@ var (
 	orgID = resource.MustHardcodeTfeID(resource.OrganizationKind, "def456")
 	runID = resource.MustHardcodeTfeID(resource.RunKind, "ghi789")
 	iaID  = resource.MustHardcodeTfeID(resource.IngressAttributesKind, "jkl012")
+	polID = resource.MustHardcodeTfeID(resource.PolicyKind, "mno345")
 )
 
 func TestResource(t *testing.T) {
@@ -57,6 +58,12 @@ func TestResource(t *testing.T) {
 			id:     iaID,
 			want:   "/app/ingress-attributes/ia-jkl012",
 		},
+		{
+			name:   "kind with trailing y is pluralised with ies",
+			action: resource.Get,
+			id:     polID,
+			want:   "/app/policies/pol-mno345",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,501 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/policy"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tfeapi"
+	"github.com/leg100/otf/internal/tfeapi/types"
+	"github.com/leg100/otf/internal/vcs"
+)
+
+// opaKind is the kind of policy set and policy reported via the API.
+const opaKind = "opa"
+
+type TFEAPI struct {
+	*tfeapi.Responder
+	Client     tfeClient
+	Authorizer authz.Interface
+}
+
+type tfeClient interface {
+	CreatePolicySet(ctx context.Context, org organization.Name, opts policy.CreatePolicySetOptions) (*policy.PolicySet, error)
+	GetPolicySet(ctx context.Context, id resource.TfeID) (*policy.PolicySet, error)
+	ListPolicySets(ctx context.Context, org organization.Name) ([]*policy.PolicySet, error)
+	UpdatePolicySet(ctx context.Context, id resource.TfeID, opts policy.UpdatePolicySetOptions) (*policy.PolicySet, error)
+	DeletePolicySet(ctx context.Context, id resource.TfeID) (*policy.PolicySet, error)
+
+	CreatePolicy(ctx context.Context, setID resource.TfeID, opts policy.CreatePolicyOptions) (*policy.Policy, error)
+	GetPolicy(ctx context.Context, id resource.TfeID) (*policy.Policy, error)
+	ListPolicies(ctx context.Context, setID resource.TfeID) ([]*policy.Policy, error)
+	UpdatePolicy(ctx context.Context, id resource.TfeID, opts policy.UpdatePolicyOptions) (*policy.Policy, error)
+	DeletePolicy(ctx context.Context, id resource.TfeID) (*policy.Policy, error)
+
+	GetPolicyCheck(ctx context.Context, id resource.TfeID) (*policy.Check, error)
+	GetRunPolicyCheck(ctx context.Context, runID resource.TfeID) (*policy.Check, error)
+	OverridePolicyCheck(ctx context.Context, id resource.TfeID) (*policy.Check, error)
+}
+
+// tfePolicySetCreateOptions are the options for creating a new policy set via
+// the TFE API.
+type tfePolicySetCreateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,policy-sets"`
+
+	Name             *string                  `jsonapi:"attribute" json:"name"`
+	Description      *string                  `jsonapi:"attribute" json:"description,omitempty"`
+	Kind             *string                  `jsonapi:"attribute" json:"kind,omitempty"`
+	Overridable      *bool                    `jsonapi:"attribute" json:"overridable,omitempty"`
+	EnforcementLevel *policy.EnforcementLevel `jsonapi:"attribute" json:"enforcement-level,omitempty"`
+	PoliciesPath     *string                  `jsonapi:"attribute" json:"policies-path,omitempty"`
+	VCSRepo          *tfeVCSRepoOptions       `jsonapi:"attribute" json:"vcs-repo,omitempty"`
+}
+
+type tfeVCSRepoOptions struct {
+	Branch       *string         `json:"branch,omitempty"`
+	Identifier   *string         `json:"identifier,omitempty"`
+	OAuthTokenID *resource.TfeID `json:"oauth-token-id,omitempty"`
+}
+
+// tfePolicySetUpdateOptions are the options for updating a policy set via the
+// TFE API.
+type tfePolicySetUpdateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,policy-sets"`
+
+	Name             *string                  `jsonapi:"attribute" json:"name,omitempty"`
+	Description      *string                  `jsonapi:"attribute" json:"description,omitempty"`
+	EnforcementLevel *policy.EnforcementLevel `jsonapi:"attribute" json:"enforcement-level,omitempty"`
+}
+
+// tfePolicyCreateOptions are the options for creating a new policy via the
+// TFE API. The policy's source is uploaded separately.
+type tfePolicyCreateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,policies"`
+
+	Name *string `jsonapi:"attribute" json:"name"`
+	// Source is an OTF extension permitting the policy to be created and
+	// uploaded in one request.
+	Source *string `jsonapi:"attribute" json:"source,omitempty"`
+}
+
+func (a *TFEAPI) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/policy-sets", a.createPolicySet).Methods("POST")
+	r.HandleFunc("/organizations/{organization_name}/policy-sets", a.listPolicySets).Methods("GET")
+	r.HandleFunc("/policy-sets/{policy_set_id}", a.getPolicySet).Methods("GET")
+	r.HandleFunc("/policy-sets/{policy_set_id}", a.updatePolicySet).Methods("PATCH")
+	r.HandleFunc("/policy-sets/{policy_set_id}", a.deletePolicySet).Methods("DELETE")
+
+	r.HandleFunc("/policy-sets/{policy_set_id}/policies", a.createPolicy).Methods("POST")
+	r.HandleFunc("/policy-sets/{policy_set_id}/policies", a.listPolicies).Methods("GET")
+	r.HandleFunc("/policies/{policy_id}", a.getPolicy).Methods("GET")
+	r.HandleFunc("/policies/{policy_id}", a.deletePolicy).Methods("DELETE")
+	r.HandleFunc("/policies/{policy_id}/upload", a.uploadPolicy).Methods("PUT")
+	r.HandleFunc("/policies/{policy_id}/download", a.downloadPolicy).Methods("GET")
+
+	r.HandleFunc("/runs/{run_id}/policy-checks", a.listPolicyChecks).Methods("GET")
+	r.HandleFunc("/policy-checks/{policy_check_id}", a.getPolicyCheck).Methods("GET")
+	r.HandleFunc("/policy-checks/{policy_check_id}/actions/override", a.overridePolicyCheck).Methods("POST")
+}
+
+func (a *TFEAPI) createPolicySet(w http.ResponseWriter, r *http.Request) {
+	var pathParams struct {
+		Organization organization.Name `schema:"organization_name,required"`
+	}
+	if err := decode.Route(&pathParams, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfePolicySetCreateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if params.Kind != nil && *params.Kind != opaKind {
+		tfeapi.Error(w, errors.New("only opa policy sets are supported"), tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	opts := policy.CreatePolicySetOptions{}
+	if params.Name != nil {
+		opts.Name = *params.Name
+	}
+	if params.Description != nil {
+		opts.Description = *params.Description
+	}
+	if params.EnforcementLevel != nil {
+		opts.EnforcementLevel = *params.EnforcementLevel
+	} else if params.Overridable != nil && !*params.Overridable {
+		// OTF doesn't support non-overridable policy sets, so the nearest
+		// equivalent is mandatory.
+		opts.EnforcementLevel = policy.Mandatory
+	}
+	if params.VCSRepo != nil {
+		if params.VCSRepo.Identifier == nil || params.VCSRepo.OAuthTokenID == nil {
+			tfeapi.Error(w, errors.New("must specify both oauth-token-id and identifier attributes for vcs-repo"), tfeapi.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		repo, err := vcs.NewRepoFromString(*params.VCSRepo.Identifier)
+		if err != nil {
+			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		opts.VCS = &policy.VCSSource{
+			VCSProviderID: *params.VCSRepo.OAuthTokenID,
+			Repo:          repo,
+		}
+		if params.VCSRepo.Branch != nil {
+			opts.VCS.Branch = *params.VCSRepo.Branch
+		}
+		if params.PoliciesPath != nil {
+			opts.VCS.Path = *params.PoliciesPath
+		}
+	}
+	set, err := a.Client.CreatePolicySet(r.Context(), pathParams.Organization, opts)
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	to, err := a.toPolicySet(r.Context(), set)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, to, http.StatusCreated)
+}
+
+func (a *TFEAPI) listPolicySets(w http.ResponseWriter, r *http.Request) {
+	var pathParams struct {
+		Organization organization.Name `schema:"organization_name,required"`
+	}
+	if err := decode.Route(&pathParams, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	sets, err := a.Client.ListPolicySets(r.Context(), pathParams.Organization)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	to := make([]*policy.TFEPolicySet, len(sets))
+	for i, set := range sets {
+		to[i], err = a.toPolicySet(r.Context(), set)
+		if err != nil {
+			tfeapi.Error(w, err)
+			return
+		}
+	}
+	a.Respond(w, r, to, http.StatusOK)
+}
+
+func (a *TFEAPI) getPolicySet(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	set, err := a.Client.GetPolicySet(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	to, err := a.toPolicySet(r.Context(), set)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, to, http.StatusOK)
+}
+
+func (a *TFEAPI) updatePolicySet(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfePolicySetUpdateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	set, err := a.Client.UpdatePolicySet(r.Context(), id, policy.UpdatePolicySetOptions{
+		Name:             params.Name,
+		Description:      params.Description,
+		EnforcementLevel: params.EnforcementLevel,
+	})
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	to, err := a.toPolicySet(r.Context(), set)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, to, http.StatusOK)
+}
+
+func (a *TFEAPI) deletePolicySet(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if _, err := a.Client.DeletePolicySet(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *TFEAPI) createPolicy(w http.ResponseWriter, r *http.Request) {
+	setID, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfePolicyCreateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var opts policy.CreatePolicyOptions
+	if params.Name != nil {
+		opts.Name = *params.Name
+	}
+	if params.Source != nil {
+		opts.Source = *params.Source
+	}
+	pol, err := a.Client.CreatePolicy(r.Context(), setID, opts)
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toPolicy(pol), http.StatusCreated)
+}
+
+func (a *TFEAPI) listPolicies(w http.ResponseWriter, r *http.Request) {
+	setID, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	policies, err := a.Client.ListPolicies(r.Context(), setID)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	to := make([]*policy.TFEPolicy, len(policies))
+	for i, pol := range policies {
+		to[i] = a.toPolicy(pol)
+	}
+	a.Respond(w, r, to, http.StatusOK)
+}
+
+func (a *TFEAPI) getPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	pol, err := a.Client.GetPolicy(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toPolicy(pol), http.StatusOK)
+}
+
+func (a *TFEAPI) deletePolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if _, err := a.Client.DeletePolicy(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *TFEAPI) uploadPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	source, err := io.ReadAll(r.Body)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	_, err = a.Client.UpdatePolicy(r.Context(), id, policy.UpdatePolicyOptions{
+		Source: new(string(source)),
+	})
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *TFEAPI) downloadPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	pol, err := a.Client.GetPolicy(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.Write([]byte(pol.Source))
+}
+
+func (a *TFEAPI) listPolicyChecks(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		RunID resource.TfeID `schema:"run_id,required"`
+		resource.PageOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	// A run has at most one policy check.
+	items := []*types.PolicyCheck{}
+	check, err := a.Client.GetRunPolicyCheck(r.Context(), params.RunID)
+	if err == nil {
+		items = append(items, a.toPolicyCheck(r.Context(), check))
+	} else if !errors.Is(err, internal.ErrResourceNotFound) {
+		tfeapi.Error(w, err)
+		return
+	}
+	page := resource.NewPage(items, params.PageOptions, nil)
+	a.RespondWithPage(w, r, page.Items, page.Pagination)
+}
+
+func (a *TFEAPI) getPolicyCheck(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_check_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	check, err := a.Client.GetPolicyCheck(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toPolicyCheck(r.Context(), check), http.StatusOK)
+}
+
+func (a *TFEAPI) overridePolicyCheck(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_check_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	check, err := a.Client.OverridePolicyCheck(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, policy.ErrCheckOverrideNotAllowed) {
+			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusConflict))
+			return
+		}
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toPolicyCheck(r.Context(), check), http.StatusOK)
+}
+
+// error reports validation errors as 422s, and all other errors per
+// tfeapi.Error.
+func (a *TFEAPI) error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, policy.ErrInvalidPolicy),
+		errors.Is(err, policy.ErrInvalidEnforcementLevel),
+		errors.Is(err, policy.ErrVCSPolicySet):
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+	default:
+		tfeapi.Error(w, err)
+	}
+}
+
+func (a *TFEAPI) toPolicySet(ctx context.Context, from *policy.PolicySet) (*policy.TFEPolicySet, error) {
+	to := &policy.TFEPolicySet{
+		ID:               from.ID,
+		Name:             from.Name,
+		Description:      from.Description,
+		Kind:             opaKind,
+		Global:           true,
+		Overridable:      true,
+		EnforcementLevel: string(from.EnforcementLevel),
+		CreatedAt:        from.CreatedAt,
+		UpdatedAt:        from.UpdatedAt,
+	}
+	if from.VCS != nil {
+		to.VCSRepo = &policy.TFEVCSRepo{
+			Branch:       from.VCS.Branch,
+			Identifier:   from.VCS.Repo.String(),
+			OAuthTokenID: from.VCS.VCSProviderID,
+		}
+		to.PoliciesPath = from.VCS.Path
+	} else {
+		policies, err := a.Client.ListPolicies(ctx, from.ID)
+		if err != nil {
+			return nil, err
+		}
+		to.PolicyCount = len(policies)
+	}
+	return to, nil
+}
+
+func (a *TFEAPI) toPolicy(from *policy.Policy) *policy.TFEPolicy {
+	return &policy.TFEPolicy{
+		ID:        from.ID,
+		Name:      from.Name,
+		Kind:      opaKind,
+		UpdatedAt: from.UpdatedAt,
+		PolicySet: &policy.TFEPolicySet{ID: from.PolicySetID},
+	}
+}
+
+func (a *TFEAPI) toPolicyCheck(ctx context.Context, from *policy.Check) *types.PolicyCheck {
+	to := &types.PolicyCheck{
+		ID: from.ID,
+		Actions: &types.PolicyActions{
+			IsOverridable: from.Status == policy.CheckSoftFailed,
+		},
+		Permissions: &types.PolicyPermissions{
+			CanOverride: a.Authorizer.CanAccess(ctx, resource.Override, resource.PolicyCheckKind, from.ID),
+		},
+		Result: &types.PolicyResult{
+			AdvisoryFailed: from.AdvisoryFailed(),
+			Passed:         from.Passed(),
+			Result:         from.Status == policy.CheckPassed,
+			SoftFailed:     from.MandatoryFailed(),
+			TotalFailed:    from.AdvisoryFailed() + from.MandatoryFailed(),
+		},
+		Scope:            types.PolicyScopeOrganization,
+		Status:           types.PolicyStatus(from.Status),
+		StatusTimestamps: &types.PolicyStatusTimestamps{},
+	}
+	switch from.Status {
+	case policy.CheckPassed:
+		to.StatusTimestamps.PassedAt = &from.UpdatedAt
+	case policy.CheckSoftFailed, policy.CheckOverridden:
+		to.StatusTimestamps.SoftFailedAt = &from.UpdatedAt
+	case policy.CheckErrored:
+		to.StatusTimestamps.ErroredAt = &from.UpdatedAt
+	}
+	return to
+}
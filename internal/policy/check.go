@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/resource"
+)
+
+const (
+	// CheckPassed indicates every policy passed, or only advisory policies
+	// failed.
+	CheckPassed CheckStatus = "passed"
+	// CheckSoftFailed indicates at least one mandatory policy failed. The
+	// failure can be overridden.
+	CheckSoftFailed CheckStatus = "soft_failed"
+	// CheckOverridden indicates at least one mandatory policy failed but the
+	// failure has been overridden.
+	CheckOverridden CheckStatus = "overridden"
+	// CheckErrored indicates the policies could not be evaluated.
+	CheckErrored CheckStatus = "errored"
+)
+
+var ErrCheckOverrideNotAllowed = errors.New("only a soft failed policy check can be overridden")
+
+type (
+	// CheckStatus is the status of a policy check.
+	CheckStatus string
+
+	// Check is the outcome of evaluating the policies of a run's organization
+	// against the run's plan. There is at most one check per run, and its ID
+	// is derived from the run ID.
+	Check struct {
+		ID           resource.TfeID `db:"policy_check_id"`
+		CreatedAt    time.Time      `db:"created_at"`
+		UpdatedAt    time.Time      `db:"updated_at"`
+		RunID        resource.TfeID `db:"run_id"`
+		Status       CheckStatus    `db:"status"`
+		ErrorMessage *string        `db:"error_message"`
+		Results      []Result       `db:"results"`
+	}
+
+	// Result is the outcome of evaluating an individual policy.
+	Result struct {
+		PolicySetID      resource.TfeID   `json:"policy_set_id"`
+		PolicySetName    string           `json:"policy_set_name"`
+		PolicyName       string           `json:"policy_name"`
+		EnforcementLevel EnforcementLevel `json:"enforcement_level"`
+		Violations       []string         `json:"violations"`
+	}
+
+	// policySetWithPolicies is a policy set along with its policies, ready
+	// for evaluation.
+	policySetWithPolicies struct {
+		*PolicySet
+		policies []*Policy
+	}
+)
+
+// CheckID returns the ID of the policy check for the given run.
+func CheckID(runID resource.TfeID) resource.TfeID {
+	return resource.ConvertTfeID(runID, resource.PolicyCheckKind)
+}
+
+func newCheck(runID resource.TfeID) *Check {
+	now := internal.CurrentTimestamp(nil)
+	return &Check{
+		ID:        CheckID(runID),
+		CreatedAt: now,
+		UpdatedAt: now,
+		RunID:     runID,
+		Results:   []Result{},
+	}
+}
+
+// evaluate every policy in the policy sets against the plan, recording the
+// results.
+func (c *Check) evaluate(ctx context.Context, plan []byte, sets []policySetWithPolicies) error {
+	input, err := decodePlan(plan)
+	if err != nil {
+		return err
+	}
+	for _, set := range sets {
+		for _, pol := range set.policies {
+			violations, err := evaluate(ctx, pol.Name, pol.Source, input)
+			if err != nil {
+				return fmt.Errorf("policy set %s: policy %s: %w", set.Name, pol.Name, err)
+			}
+			c.Results = append(c.Results, Result{
+				PolicySetID:      set.ID,
+				PolicySetName:    set.Name,
+				PolicyName:       pol.Name,
+				EnforcementLevel: set.EnforcementLevel,
+				Violations:       violations,
+			})
+		}
+	}
+	return nil
+}
+
+// finish sets the status of the check. If err is non-nil then the check is
+// marked as errored.
+func (c *Check) finish(err error) {
+	switch {
+	case err != nil:
+		c.Status = CheckErrored
+		c.ErrorMessage = new(err.Error())
+	case c.MandatoryFailed() > 0:
+		c.Status = CheckSoftFailed
+	default:
+		c.Status = CheckPassed
+	}
+	c.UpdatedAt = internal.CurrentTimestamp(nil)
+}
+
+func (c *Check) override() error {
+	if c.Status != CheckSoftFailed {
+		return ErrCheckOverrideNotAllowed
+	}
+	c.Status = CheckOverridden
+	c.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// Passed returns the number of policies that passed.
+func (c *Check) Passed() (n int) {
+	for _, r := range c.Results {
+		if r.Passed() {
+			n++
+		}
+	}
+	return
+}
+
+// AdvisoryFailed returns the number of advisory policies that failed.
+func (c *Check) AdvisoryFailed() int {
+	return c.failed(Advisory)
+}
+
+// MandatoryFailed returns the number of mandatory policies that failed.
+func (c *Check) MandatoryFailed() int {
+	return c.failed(Mandatory)
+}
+
+func (c *Check) failed(level EnforcementLevel) (n int) {
+	for _, r := range c.Results {
+		if !r.Passed() && r.EnforcementLevel == level {
+			n++
+		}
+	}
+	return
+}
+
+// LogValue implements slog.LogValuer.
+func (c *Check) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", c.ID.String()),
+		slog.String("run_id", c.RunID.String()),
+		slog.String("status", string(c.Status)),
+		slog.Int("passed", c.Passed()),
+		slog.Int("advisory_failed", c.AdvisoryFailed()),
+		slog.Int("mandatory_failed", c.MandatoryFailed()),
+	)
+}
+
+// Passed determines whether the policy passed.
+func (r Result) Passed() bool {
+	return len(r.Violations) == 0
+}
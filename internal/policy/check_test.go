@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leg100/otf/internal/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	runID := resource.NewTfeID(resource.RunKind)
+	sets := []policySetWithPolicies{
+		{
+			PolicySet: &PolicySet{
+				ID:               resource.NewTfeID(resource.PolicySetKind),
+				Name:             "advisory",
+				EnforcementLevel: Advisory,
+			},
+			policies: []*Policy{
+				{Name: "buckets", Source: denyBuckets},
+				{Name: "instances", Source: denyInstances},
+			},
+		},
+		{
+			PolicySet: &PolicySet{
+				ID:               resource.NewTfeID(resource.PolicySetKind),
+				Name:             "mandatory",
+				EnforcementLevel: Mandatory,
+			},
+			policies: []*Policy{
+				{Name: "instances", Source: denyInstances},
+			},
+		},
+	}
+
+	t.Run("id derived from run", func(t *testing.T) {
+		check := newCheck(runID)
+
+		assert.Equal(t, resource.PolicyCheckKind, check.ID.Kind())
+		assert.Equal(t, CheckID(runID), check.ID)
+	})
+
+	t.Run("mandatory failure", func(t *testing.T) {
+		check := newCheck(runID)
+
+		check.finish(check.evaluate(t.Context(), []byte(testPlan), sets))
+
+		assert.Equal(t, CheckSoftFailed, check.Status)
+		assert.Equal(t, 1, check.Passed())
+		assert.Equal(t, 1, check.AdvisoryFailed())
+		assert.Equal(t, 1, check.MandatoryFailed())
+	})
+
+	t.Run("advisory failure only", func(t *testing.T) {
+		check := newCheck(runID)
+
+		check.finish(check.evaluate(t.Context(), []byte(testPlan), sets[:1]))
+
+		assert.Equal(t, CheckPassed, check.Status)
+		assert.Equal(t, 1, check.AdvisoryFailed())
+	})
+
+	t.Run("errored", func(t *testing.T) {
+		check := newCheck(runID)
+
+		check.finish(errors.New("plan not found"))
+
+		assert.Equal(t, CheckErrored, check.Status)
+		require.NotNil(t, check.ErrorMessage)
+		assert.Equal(t, "plan not found", *check.ErrorMessage)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		check := newCheck(runID)
+		check.Status = CheckSoftFailed
+
+		require.NoError(t, check.override())
+		assert.Equal(t, CheckOverridden, check.Status)
+	})
+
+	t.Run("cannot override passed check", func(t *testing.T) {
+		check := newCheck(runID)
+		check.Status = CheckPassed
+
+		assert.Equal(t, ErrCheckOverrideNotAllowed, check.override())
+	})
+}
@@ -0,0 +1,39 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/runstatus"
+)
+
+// Checker checks the policies of runs that have entered the policy checking
+// state.
+type Checker struct {
+	logr.Logger
+
+	Runs     checkerRunClient
+	Policies *Service
+}
+
+type checkerRunClient interface {
+	ListRuns(ctx context.Context, opts run.ListOptions) (*resource.Page[*run.Run], error)
+	WatchRuns(context.Context) (<-chan pubsub.Event[*run.Event], func(), error)
+}
+
+// Start the checker.
+func (c *Checker) Start(ctx context.Context) error {
+	watcher := &run.StatusWatcher{
+		Logger:   c.Logger,
+		Runs:     c.Runs,
+		Statuses: []runstatus.Status{runstatus.PolicyChecking},
+		Handler: func(ctx context.Context, runID resource.TfeID) error {
+			_, err := c.Policies.checkRun(ctx, runID)
+			return err
+		},
+	}
+	return watcher.Start(ctx)
+}
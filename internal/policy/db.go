@@ -0,0 +1,348 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+	"github.com/leg100/otf/internal/vcs"
+)
+
+type pgdb struct {
+	*sql.DB
+}
+
+type policySetModel struct {
+	ID               resource.TfeID    `db:"policy_set_id"`
+	CreatedAt        time.Time         `db:"created_at"`
+	UpdatedAt        time.Time         `db:"updated_at"`
+	Name             string            `db:"name"`
+	Description      string            `db:"description"`
+	EnforcementLevel EnforcementLevel  `db:"enforcement_level"`
+	Organization     organization.Name `db:"organization_name"`
+	VCSProviderID    *resource.TfeID   `db:"vcs_provider_id"`
+	Repo             *vcs.Repo         `db:"repo_path"`
+	Branch           *string           `db:"branch"`
+	Path             *string           `db:"path"`
+}
+
+func (m policySetModel) toPolicySet() *PolicySet {
+	set := &PolicySet{
+		ID:               m.ID,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+		Name:             m.Name,
+		Description:      m.Description,
+		EnforcementLevel: m.EnforcementLevel,
+		Organization:     m.Organization,
+	}
+	if m.VCSProviderID != nil && m.Repo != nil {
+		set.VCS = &VCSSource{
+			VCSProviderID: *m.VCSProviderID,
+			Repo:          *m.Repo,
+		}
+		if m.Branch != nil {
+			set.VCS.Branch = *m.Branch
+		}
+		if m.Path != nil {
+			set.VCS.Path = *m.Path
+		}
+	}
+	return set
+}
+
+func scanPolicySet(row pgx.CollectableRow) (*PolicySet, error) {
+	m, err := pgx.RowToStructByName[policySetModel](row)
+	if err != nil {
+		return nil, err
+	}
+	return m.toPolicySet(), nil
+}
+
+func (db *pgdb) createPolicySet(ctx context.Context, set *PolicySet) error {
+	args := pgx.NamedArgs{
+		"id":                set.ID,
+		"created_at":        set.CreatedAt,
+		"updated_at":        set.UpdatedAt,
+		"name":              set.Name,
+		"description":       set.Description,
+		"enforcement_level": set.EnforcementLevel,
+		"organization_name": set.Organization,
+		"vcs_provider_id":   nil,
+		"repo_path":         nil,
+		"branch":            nil,
+		"path":              nil,
+	}
+	if set.VCS != nil {
+		args["vcs_provider_id"] = set.VCS.VCSProviderID
+		args["repo_path"] = set.VCS.Repo.String()
+		args["branch"] = set.VCS.Branch
+		args["path"] = set.VCS.Path
+	}
+	_, err := db.Exec(ctx, `
+INSERT INTO policy_sets (
+    policy_set_id,
+    created_at,
+    updated_at,
+    name,
+    description,
+    enforcement_level,
+    organization_name,
+    vcs_provider_id,
+    repo_path,
+    branch,
+    path
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @name,
+    @description,
+    @enforcement_level,
+    @organization_name,
+    @vcs_provider_id,
+    @repo_path,
+    @branch,
+    @path
+)
+`, args)
+	return err
+}
+
+func (db *pgdb) updatePolicySet(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *PolicySet) error) (*PolicySet, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*PolicySet, error) {
+			rows := db.Query(ctx, `
+SELECT policy_set_id, created_at, updated_at, name, description, enforcement_level, organization_name, vcs_provider_id, repo_path, branch, path
+FROM policy_sets
+WHERE policy_set_id = $1
+FOR UPDATE
+`, id)
+			return sql.CollectOneRow(rows, scanPolicySet)
+		},
+		updateFunc,
+		func(ctx context.Context, set *PolicySet) error {
+			_, err := db.Exec(ctx, `
+UPDATE policy_sets
+SET name = @name,
+    description = @description,
+    enforcement_level = @enforcement_level,
+    updated_at = @updated_at
+WHERE policy_set_id = @id
+`,
+				pgx.NamedArgs{
+					"id":                set.ID,
+					"name":              set.Name,
+					"description":       set.Description,
+					"enforcement_level": set.EnforcementLevel,
+					"updated_at":        set.UpdatedAt,
+				},
+			)
+			return err
+		},
+	)
+}
+
+func (db *pgdb) getPolicySet(ctx context.Context, id resource.TfeID) (*PolicySet, error) {
+	rows := db.Query(ctx, `
+SELECT policy_set_id, created_at, updated_at, name, description, enforcement_level, organization_name, vcs_provider_id, repo_path, branch, path
+FROM policy_sets
+WHERE policy_set_id = $1
+`, id)
+	return sql.CollectOneRow(rows, scanPolicySet)
+}
+
+func (db *pgdb) listPolicySets(ctx context.Context, org organization.Name) ([]*PolicySet, error) {
+	rows := db.Query(ctx, `
+SELECT policy_set_id, created_at, updated_at, name, description, enforcement_level, organization_name, vcs_provider_id, repo_path, branch, path
+FROM policy_sets
+WHERE organization_name = $1
+ORDER BY name
+`, org)
+	return sql.CollectRows(rows, scanPolicySet)
+}
+
+func (db *pgdb) deletePolicySet(ctx context.Context, id resource.TfeID) (*PolicySet, error) {
+	rows := db.Query(ctx, `
+DELETE FROM policy_sets
+WHERE policy_set_id = $1
+RETURNING policy_set_id, created_at, updated_at, name, description, enforcement_level, organization_name, vcs_provider_id, repo_path, branch, path
+`, id)
+	return sql.CollectOneRow(rows, scanPolicySet)
+}
+
+func (db *pgdb) createPolicy(ctx context.Context, pol *Policy) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO policies (
+    policy_id,
+    created_at,
+    updated_at,
+    name,
+    source,
+    policy_set_id
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @name,
+    @source,
+    @policy_set_id
+)
+`,
+		pgx.NamedArgs{
+			"id":            pol.ID,
+			"created_at":    pol.CreatedAt,
+			"updated_at":    pol.UpdatedAt,
+			"name":          pol.Name,
+			"source":        pol.Source,
+			"policy_set_id": pol.PolicySetID,
+		},
+	)
+	return err
+}
+
+func (db *pgdb) updatePolicy(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *Policy) error) (*Policy, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*Policy, error) {
+			rows := db.Query(ctx, `
+SELECT policy_id, created_at, updated_at, name, source, policy_set_id
+FROM policies
+WHERE policy_id = $1
+FOR UPDATE
+`, id)
+			return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Policy])
+		},
+		updateFunc,
+		func(ctx context.Context, pol *Policy) error {
+			_, err := db.Exec(ctx, `
+UPDATE policies
+SET source = @source,
+    updated_at = @updated_at
+WHERE policy_id = @id
+`,
+				pgx.NamedArgs{
+					"id":         pol.ID,
+					"source":     pol.Source,
+					"updated_at": pol.UpdatedAt,
+				},
+			)
+			return err
+		},
+	)
+}
+
+func (db *pgdb) getPolicy(ctx context.Context, id resource.TfeID) (*Policy, error) {
+	rows := db.Query(ctx, `
+SELECT policy_id, created_at, updated_at, name, source, policy_set_id
+FROM policies
+WHERE policy_id = $1
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Policy])
+}
+
+func (db *pgdb) listPolicies(ctx context.Context, setID resource.TfeID) ([]*Policy, error) {
+	rows := db.Query(ctx, `
+SELECT policy_id, created_at, updated_at, name, source, policy_set_id
+FROM policies
+WHERE policy_set_id = $1
+ORDER BY name
+`, setID)
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Policy])
+}
+
+func (db *pgdb) deletePolicy(ctx context.Context, id resource.TfeID) (*Policy, error) {
+	rows := db.Query(ctx, `
+DELETE FROM policies
+WHERE policy_id = $1
+RETURNING policy_id, created_at, updated_at, name, source, policy_set_id
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Policy])
+}
+
+// upsertCheck persists a policy check, replacing any existing check for the
+// same run.
+func (db *pgdb) upsertCheck(ctx context.Context, check *Check) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO policy_checks (
+    policy_check_id,
+    created_at,
+    updated_at,
+    status,
+    error_message,
+    results,
+    run_id
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @status,
+    @error_message,
+    @results,
+    @run_id
+)
+ON CONFLICT (policy_check_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    status = EXCLUDED.status,
+    error_message = EXCLUDED.error_message,
+    results = EXCLUDED.results
+`,
+		pgx.NamedArgs{
+			"id":            check.ID,
+			"created_at":    check.CreatedAt,
+			"updated_at":    check.UpdatedAt,
+			"status":        check.Status,
+			"error_message": check.ErrorMessage,
+			"results":       check.Results,
+			"run_id":        check.RunID,
+		},
+	)
+	return err
+}
+
+func (db *pgdb) updateCheck(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *Check) error) (*Check, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*Check, error) {
+			rows := db.Query(ctx, `
+SELECT policy_check_id, created_at, updated_at, run_id, status, error_message, results
+FROM policy_checks
+WHERE policy_check_id = $1
+FOR UPDATE
+`, id)
+			return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Check])
+		},
+		updateFunc,
+		func(ctx context.Context, check *Check) error {
+			_, err := db.Exec(ctx, `
+UPDATE policy_checks
+SET status = @status,
+    updated_at = @updated_at
+WHERE policy_check_id = @id
+`,
+				pgx.NamedArgs{
+					"id":         check.ID,
+					"status":     check.Status,
+					"updated_at": check.UpdatedAt,
+				},
+			)
+			return err
+		},
+	)
+}
+
+func (db *pgdb) getCheck(ctx context.Context, id resource.TfeID) (*Check, error) {
+	rows := db.Query(ctx, `
+SELECT policy_check_id, created_at, updated_at, run_id, status, error_message, results
+FROM policy_checks
+WHERE policy_check_id = $1
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Check])
+}
@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// denyRule is the name of the rule in each policy that is queried for
+// violations. The rule is expected to be a set of messages, one for each
+// violation, e.g.
+//
+//	deny contains msg if {
+//		some rc in input.resource_changes
+//		rc.type == "aws_instance"
+//		msg := sprintf("%s: instances are forbidden", [rc.address])
+//	}
+//
+// If the rule is undefined then the policy passes.
+const denyRule = "deny"
+
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// parse parses the Rego source of a policy.
+func parse(name, source string) (*ast.Module, error) {
+	module, err := ast.ParseModule(name, source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPolicy, err)
+	}
+	if module == nil {
+		return nil, fmt.Errorf("%w: empty module", ErrInvalidPolicy)
+	}
+	return module, nil
+}
+
+// decodePlan decodes a plan in JSON format into a value suitable for use as
+// input to a policy.
+func decodePlan(plan []byte) (any, error) {
+	var input any
+	d := json.NewDecoder(bytes.NewReader(plan))
+	// Retain precision of numbers.
+	d.UseNumber()
+	if err := d.Decode(&input); err != nil {
+		return nil, fmt.Errorf("decoding plan: %w", err)
+	}
+	return input, nil
+}
+
+// evaluate a policy against the input, returning a message for each
+// violation.
+func evaluate(ctx context.Context, name, source string, input any) ([]string, error) {
+	module, err := parse(name, source)
+	if err != nil {
+		return nil, err
+	}
+	query := module.Package.Path.String() + "." + denyRule
+	rs, err := rego.New(
+		rego.Query(query),
+		rego.ParsedModule(module),
+		rego.Input(input),
+	).Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating policy: %w", err)
+	}
+	var violations []string
+	for _, result := range rs {
+		for _, expr := range result.Expressions {
+			switch v := expr.Value.(type) {
+			case []any:
+				for _, msg := range v {
+					violations = append(violations, message(msg))
+				}
+			case bool:
+				if v {
+					violations = append(violations, "denied by "+name)
+				}
+			default:
+				violations = append(violations, message(v))
+			}
+		}
+	}
+	return violations, nil
+}
+
+// message converts a violation into a human-readable message.
+func message(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
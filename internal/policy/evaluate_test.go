@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPlan = `{
+  "resource_changes": [
+    {"address": "aws_instance.web", "type": "aws_instance"},
+    {"address": "null_resource.foo", "type": "null_resource"}
+  ]
+}`
+
+	denyInstances = `package terraform
+
+deny contains msg if {
+	some rc in input.resource_changes
+	rc.type == "aws_instance"
+	msg := sprintf("%s: instances are forbidden", [rc.address])
+}
+`
+
+	denyBuckets = `package terraform
+
+deny contains msg if {
+	some rc in input.resource_changes
+	rc.type == "aws_s3_bucket"
+	msg := sprintf("%s: buckets are forbidden", [rc.address])
+}
+`
+)
+
+func TestEvaluate(t *testing.T) {
+	input, err := decodePlan([]byte(testPlan))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "violation",
+			source: denyInstances,
+			want:   []string{"aws_instance.web: instances are forbidden"},
+		},
+		{
+			name:   "no violations",
+			source: denyBuckets,
+			want:   nil,
+		},
+		{
+			name:   "undefined deny rule",
+			source: "package terraform\n\nallow := true\n",
+			want:   nil,
+		},
+		{
+			name:   "boolean deny rule",
+			source: "package terraform\n\ndeny if { count(input.resource_changes) > 1 }\n",
+			want:   []string{"denied by boolean deny rule"},
+		},
+		{
+			name:   "object messages",
+			source: "package terraform\n\ndeny contains {\"msg\": \"forbidden\"} if { true }\n",
+			want:   []string{"forbidden"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluate(t.Context(), tt.name, tt.source, input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluate_InvalidPolicy(t *testing.T) {
+	_, err := evaluate(t.Context(), "invalid", "package terraform\n\ndeny contains", nil)
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
@@ -0,0 +1,201 @@
+// Package policy provides policy-as-code checks, evaluating OPA policies
+// against the plans of runs.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/vcs"
+)
+
+const (
+	// Advisory policies report failures but do not prevent a run from being
+	// applied.
+	Advisory EnforcementLevel = "advisory"
+	// Mandatory policies prevent a run from being applied unless their
+	// failure is overridden.
+	Mandatory EnforcementLevel = "mandatory"
+)
+
+var (
+	ErrInvalidEnforcementLevel = errors.New("enforcement level must be either advisory or mandatory")
+	ErrVCSPolicySet            = errors.New("policies cannot be uploaded to a policy set sourced from a VCS repository")
+)
+
+type (
+	// EnforcementLevel determines the consequence of a policy failing.
+	EnforcementLevel string
+
+	// PolicySet is a collection of policies belonging to an organization,
+	// evaluated against the plan of every run in the organization. The
+	// policies are either uploaded or sourced from a VCS repository.
+	PolicySet struct {
+		ID               resource.TfeID
+		CreatedAt        time.Time
+		UpdatedAt        time.Time
+		Name             string
+		Description      string
+		Organization     organization.Name
+		EnforcementLevel EnforcementLevel
+		// VCS is non-nil if the policies are sourced from a VCS repository.
+		VCS *VCSSource
+	}
+
+	// VCSSource is a VCS repository from which the policies of a policy set
+	// are sourced.
+	VCSSource struct {
+		VCSProviderID resource.TfeID
+		Repo          vcs.Repo
+		// Branch from which to retrieve policies. Empty means the default
+		// branch.
+		Branch string
+		// Path to the directory within the repository containing policies.
+		// Empty means the root of the repository.
+		Path string
+	}
+
+	// Policy is an OPA policy written in Rego.
+	Policy struct {
+		ID          resource.TfeID `db:"policy_id"`
+		CreatedAt   time.Time      `db:"created_at"`
+		UpdatedAt   time.Time      `db:"updated_at"`
+		Name        string         `db:"name"`
+		Source      string         `db:"source"`
+		PolicySetID resource.TfeID `db:"policy_set_id"`
+	}
+
+	// CreatePolicySetOptions are the options for creating a new policy set.
+	CreatePolicySetOptions struct {
+		Name             string
+		Description      string
+		EnforcementLevel EnforcementLevel
+		VCS              *VCSSource
+	}
+
+	// UpdatePolicySetOptions are the options for updating a policy set.
+	UpdatePolicySetOptions struct {
+		Name             *string
+		Description      *string
+		EnforcementLevel *EnforcementLevel
+	}
+
+	// CreatePolicyOptions are the options for creating a new policy.
+	CreatePolicyOptions struct {
+		Name   string
+		Source string
+	}
+
+	// UpdatePolicyOptions are the options for updating a policy.
+	UpdatePolicyOptions struct {
+		Source *string
+	}
+)
+
+func newPolicySet(org organization.Name, opts CreatePolicySetOptions) (*PolicySet, error) {
+	if opts.Name == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "name"}
+	}
+	if opts.EnforcementLevel == "" {
+		opts.EnforcementLevel = Mandatory
+	}
+	if err := opts.EnforcementLevel.Validate(); err != nil {
+		return nil, err
+	}
+	now := internal.CurrentTimestamp(nil)
+	return &PolicySet{
+		ID:               resource.NewTfeID(resource.PolicySetKind),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		Name:             opts.Name,
+		Description:      opts.Description,
+		Organization:     org,
+		EnforcementLevel: opts.EnforcementLevel,
+		VCS:              opts.VCS,
+	}, nil
+}
+
+func (set *PolicySet) update(opts UpdatePolicySetOptions) error {
+	if opts.Name != nil {
+		if *opts.Name == "" {
+			return &internal.ErrMissingParameter{Parameter: "name"}
+		}
+		set.Name = *opts.Name
+	}
+	if opts.Description != nil {
+		set.Description = *opts.Description
+	}
+	if opts.EnforcementLevel != nil {
+		if err := opts.EnforcementLevel.Validate(); err != nil {
+			return err
+		}
+		set.EnforcementLevel = *opts.EnforcementLevel
+	}
+	set.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// LogValue implements slog.LogValuer.
+func (set *PolicySet) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", set.ID.String()),
+		slog.Any("organization", set.Organization),
+		slog.String("name", set.Name),
+		slog.String("enforcement_level", string(set.EnforcementLevel)),
+	)
+}
+
+func newPolicy(setID resource.TfeID, opts CreatePolicyOptions) (*Policy, error) {
+	if opts.Name == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "name"}
+	}
+	if opts.Source == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "source"}
+	}
+	if _, err := parse(opts.Name, opts.Source); err != nil {
+		return nil, err
+	}
+	now := internal.CurrentTimestamp(nil)
+	return &Policy{
+		ID:          resource.NewTfeID(resource.PolicyKind),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Name:        opts.Name,
+		Source:      opts.Source,
+		PolicySetID: setID,
+	}, nil
+}
+
+func (p *Policy) update(opts UpdatePolicyOptions) error {
+	if opts.Source != nil {
+		if _, err := parse(p.Name, *opts.Source); err != nil {
+			return err
+		}
+		p.Source = *opts.Source
+	}
+	p.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// LogValue implements slog.LogValuer.
+func (p *Policy) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", p.ID.String()),
+		slog.String("policy_set_id", p.PolicySetID.String()),
+		slog.String("name", p.Name),
+	)
+}
+
+func (l EnforcementLevel) Validate() error {
+	switch l {
+	case Advisory, Mandatory:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidEnforcementLevel, l)
+	}
+}
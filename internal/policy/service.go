@@ -0,0 +1,379 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/sql"
+	"github.com/leg100/otf/internal/vcs"
+)
+
+type (
+	// Alias service to permit embedding it with other services in a struct
+	// without a name clash.
+	PolicyService = Service
+
+	Service struct {
+		logr.Logger
+		*authz.Authorizer
+
+		db   *pgdb
+		runs runClient
+		vcs  vcsClient
+	}
+
+	Options struct {
+		DB                *sql.DB
+		Logger            logr.Logger
+		Authorizer        *authz.Authorizer
+		RunClient         runClient
+		VCSProviderClient vcsClient
+	}
+
+	runClient interface {
+		GetRun(ctx context.Context, id resource.TfeID) (*run.Run, error)
+		GetRunPlanFile(ctx context.Context, id resource.TfeID, format run.PlanFormat) ([]byte, error)
+		FinishPolicyCheck(ctx context.Context, id resource.TfeID, opts run.PolicyCheckFinishOptions) (*run.Run, error)
+		OverridePolicyCheck(ctx context.Context, id resource.TfeID) (*run.Run, error)
+	}
+
+	vcsClient interface {
+		GetVCSProvider(ctx context.Context, id resource.TfeID) (*vcs.Provider, error)
+	}
+)
+
+func NewService(opts Options) *Service {
+	svc := &Service{
+		Logger:     opts.Logger,
+		Authorizer: opts.Authorizer,
+		db:         &pgdb{opts.DB},
+		runs:       opts.RunClient,
+		vcs:        opts.VCSProviderClient,
+	}
+	// Register parent resolvers so the authorizer can resolve policy ->
+	// policy set -> org, and policy check -> run.
+	opts.Authorizer.RegisterParentResolver(resource.PolicySetKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			set, err := svc.db.getPolicySet(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return set.Organization, nil
+		},
+	)
+	opts.Authorizer.RegisterParentResolver(resource.PolicyKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			pol, err := svc.db.getPolicy(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return pol.PolicySetID, nil
+		},
+	)
+	opts.Authorizer.RegisterParentResolver(resource.PolicyCheckKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			return resource.ConvertTfeID(id.(resource.TfeID), resource.RunKind), nil
+		},
+	)
+	return svc
+}
+
+func (s *Service) CreatePolicySet(ctx context.Context, org organization.Name, opts CreatePolicySetOptions) (*PolicySet, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.PolicySetKind, org)
+	if err != nil {
+		return nil, err
+	}
+	set, err := func() (*PolicySet, error) {
+		set, err := newPolicySet(org, opts)
+		if err != nil {
+			return nil, err
+		}
+		if set.VCS != nil {
+			// Check the VCS provider exists.
+			if _, err := s.vcs.GetVCSProvider(ctx, set.VCS.VCSProviderID); err != nil {
+				return nil, fmt.Errorf("retrieving vcs provider: %w", err)
+			}
+		}
+		return set, s.db.createPolicySet(ctx, set)
+	}()
+	if err != nil {
+		s.Error(err, "creating policy set", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("created policy set", "policy_set", set, "subject", subject)
+	return set, nil
+}
+
+func (s *Service) GetPolicySet(ctx context.Context, id resource.TfeID) (*PolicySet, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.PolicySetKind, id)
+	if err != nil {
+		return nil, err
+	}
+	set, err := s.db.getPolicySet(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving policy set", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved policy set", "policy_set", set, "subject", subject)
+	return set, nil
+}
+
+func (s *Service) ListPolicySets(ctx context.Context, org organization.Name) ([]*PolicySet, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.PolicySetKind, org)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := s.db.listPolicySets(ctx, org)
+	if err != nil {
+		s.Error(err, "listing policy sets", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed policy sets", "organization", org, "total", len(sets), "subject", subject)
+	return sets, nil
+}
+
+func (s *Service) UpdatePolicySet(ctx context.Context, id resource.TfeID, opts UpdatePolicySetOptions) (*PolicySet, error) {
+	var subject authz.Subject
+	updated, err := s.db.updatePolicySet(ctx, id, func(ctx context.Context, set *PolicySet) (err error) {
+		subject, err = s.Authorize(ctx, resource.Update, resource.PolicySetKind, id)
+		if err != nil {
+			return err
+		}
+		return set.update(opts)
+	})
+	if err != nil {
+		s.Error(err, "updating policy set", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("updated policy set", "policy_set", updated, "subject", subject)
+	return updated, nil
+}
+
+func (s *Service) DeletePolicySet(ctx context.Context, id resource.TfeID) (*PolicySet, error) {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.PolicySetKind, id)
+	if err != nil {
+		return nil, err
+	}
+	set, err := s.db.deletePolicySet(ctx, id)
+	if err != nil {
+		s.Error(err, "deleting policy set", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("deleted policy set", "policy_set", set, "subject", subject)
+	return set, nil
+}
+
+func (s *Service) CreatePolicy(ctx context.Context, setID resource.TfeID, opts CreatePolicyOptions) (*Policy, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.PolicyKind, setID)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := func() (*Policy, error) {
+		set, err := s.db.getPolicySet(ctx, setID)
+		if err != nil {
+			return nil, err
+		}
+		if set.VCS != nil {
+			return nil, ErrVCSPolicySet
+		}
+		pol, err := newPolicy(setID, opts)
+		if err != nil {
+			return nil, err
+		}
+		return pol, s.db.createPolicy(ctx, pol)
+	}()
+	if err != nil {
+		s.Error(err, "creating policy", "policy_set_id", setID, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("created policy", "policy", pol, "subject", subject)
+	return pol, nil
+}
+
+func (s *Service) GetPolicy(ctx context.Context, id resource.TfeID) (*Policy, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.PolicyKind, id)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := s.db.getPolicy(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving policy", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved policy", "policy", pol, "subject", subject)
+	return pol, nil
+}
+
+// ListPolicies lists the policies uploaded to a policy set. Policy sets
+// sourced from a VCS repository have no uploaded policies.
+func (s *Service) ListPolicies(ctx context.Context, setID resource.TfeID) ([]*Policy, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.PolicyKind, setID)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.db.listPolicies(ctx, setID)
+	if err != nil {
+		s.Error(err, "listing policies", "policy_set_id", setID, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed policies", "policy_set_id", setID, "total", len(policies), "subject", subject)
+	return policies, nil
+}
+
+func (s *Service) UpdatePolicy(ctx context.Context, id resource.TfeID, opts UpdatePolicyOptions) (*Policy, error) {
+	var subject authz.Subject
+	updated, err := s.db.updatePolicy(ctx, id, func(ctx context.Context, pol *Policy) (err error) {
+		subject, err = s.Authorize(ctx, resource.Update, resource.PolicyKind, id)
+		if err != nil {
+			return err
+		}
+		return pol.update(opts)
+	})
+	if err != nil {
+		s.Error(err, "updating policy", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("updated policy", "policy", updated, "subject", subject)
+	return updated, nil
+}
+
+func (s *Service) DeletePolicy(ctx context.Context, id resource.TfeID) (*Policy, error) {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.PolicyKind, id)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := s.db.deletePolicy(ctx, id)
+	if err != nil {
+		s.Error(err, "deleting policy", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("deleted policy", "policy", pol, "subject", subject)
+	return pol, nil
+}
+
+// GetPolicyCheck retrieves a policy check.
+func (s *Service) GetPolicyCheck(ctx context.Context, id resource.TfeID) (*Check, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.PolicyCheckKind, id)
+	if err != nil {
+		return nil, err
+	}
+	check, err := s.db.getCheck(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving policy check", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved policy check", "check", check, "subject", subject)
+	return check, nil
+}
+
+// GetRunPolicyCheck retrieves the policy check for a run.
+func (s *Service) GetRunPolicyCheck(ctx context.Context, runID resource.TfeID) (*Check, error) {
+	return s.GetPolicyCheck(ctx, CheckID(runID))
+}
+
+// OverridePolicyCheck overrides a soft failed policy check, permitting its
+// run to be applied.
+func (s *Service) OverridePolicyCheck(ctx context.Context, id resource.TfeID) (*Check, error) {
+	subject, err := s.Authorize(ctx, resource.Override, resource.PolicyCheckKind, id)
+	if err != nil {
+		return nil, err
+	}
+	var check *Check
+	err = s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		check, err = s.db.updateCheck(ctx, id, func(ctx context.Context, check *Check) error {
+			return check.override()
+		})
+		if err != nil {
+			return err
+		}
+		_, err = s.runs.OverridePolicyCheck(ctx, check.RunID)
+		return err
+	})
+	if err != nil {
+		s.Error(err, "overriding policy check", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("overrode policy check", "check", check, "subject", subject)
+	return check, nil
+}
+
+// checkRun evaluates the policies of a run's organization against the run's
+// plan, persists the outcome, and then updates the run accordingly.
+func (s *Service) checkRun(ctx context.Context, runID resource.TfeID) (*Check, error) {
+	check := newCheck(runID)
+	check.finish(func() error {
+		r, err := s.runs.GetRun(ctx, runID)
+		if err != nil {
+			return fmt.Errorf("retrieving run: %w", err)
+		}
+		plan, err := s.runs.GetRunPlanFile(ctx, runID, run.PlanFormatJSON)
+		if err != nil {
+			return fmt.Errorf("retrieving plan: %w", err)
+		}
+		sets, err := s.loadPolicySets(ctx, r.Organization)
+		if err != nil {
+			return err
+		}
+		return check.evaluate(ctx, plan, sets)
+	}())
+	err := s.db.Tx(ctx, func(ctx context.Context) error {
+		if err := s.db.upsertCheck(ctx, check); err != nil {
+			return fmt.Errorf("saving policy check: %w", err)
+		}
+		_, err := s.runs.FinishPolicyCheck(ctx, runID, run.PolicyCheckFinishOptions{
+			Passed:  check.Status == CheckPassed,
+			Errored: check.Status == CheckErrored,
+		})
+		return err
+	})
+	if err != nil {
+		s.Error(err, "checking policies", "run_id", runID)
+		return nil, err
+	}
+	s.V(0).Info("checked policies", "check", check)
+	return check, nil
+}
+
+// loadPolicySets retrieves an organization's policy sets along with their
+// policies, retrieving policies from VCS repositories where necessary.
+func (s *Service) loadPolicySets(ctx context.Context, org organization.Name) ([]policySetWithPolicies, error) {
+	sets, err := s.db.listPolicySets(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("listing policy sets: %w", err)
+	}
+	loaded := make([]policySetWithPolicies, len(sets))
+	for i, set := range sets {
+		var policies []*Policy
+		if set.VCS != nil {
+			policies, err = s.getVCSPolicies(ctx, set.VCS)
+		} else {
+			policies, err = s.db.listPolicies(ctx, set.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("policy set %s: retrieving policies: %w", set.Name, err)
+		}
+		loaded[i] = policySetWithPolicies{PolicySet: set, policies: policies}
+	}
+	return loaded, nil
+}
+
+func (s *Service) getVCSPolicies(ctx context.Context, source *VCSSource) ([]*Policy, error) {
+	provider, err := s.vcs.GetVCSProvider(ctx, source.VCSProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving vcs provider: %w", err)
+	}
+	opts := vcs.GetRepoTarballOptions{Repo: source.Repo}
+	if source.Branch != "" {
+		opts.Ref = &source.Branch
+	}
+	tarball, _, err := provider.GetRepoTarball(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving repository tarball: %w", err)
+	}
+	return policiesFromTarball(tarball, source.Path)
+}
@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// policiesFromTarball extracts policies from a .tar.gz tarball of a VCS
+// repository. Every file with a .rego extension within dir (or any of its
+// subdirectories) is considered a policy, with the exception of test files.
+// An empty dir is the root of the repository.
+func policiesFromTarball(tarball []byte, dir string) ([]*Policy, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing tarball: %w", err)
+	}
+	tr := tar.NewReader(gr)
+
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+
+	var policies []*Policy
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tarball: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if path.Ext(name) != ".rego" || strings.HasSuffix(name, "_test.rego") {
+			continue
+		}
+		if dir != "" {
+			if !strings.HasPrefix(name, dir+"/") {
+				continue
+			}
+			name = strings.TrimPrefix(name, dir+"/")
+		}
+		source, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", header.Name, err)
+		}
+		if _, err := parse(name, string(source)); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		policies = append(policies, &Policy{
+			Name:   name,
+			Source: string(source),
+		})
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+	return policies, nil
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoliciesFromTarball(t *testing.T) {
+	tarball := newTestTarball(t, map[string]string{
+		"README.md":                     "# policies",
+		"main.tf":                       `resource "null_resource" "foo" {}`,
+		"policies/instances.rego":       denyInstances,
+		"policies/instances_test.rego":  "package terraform\n",
+		"policies/storage/buckets.rego": denyBuckets,
+		"other/buckets.rego":            denyBuckets,
+	})
+
+	t.Run("root of repository", func(t *testing.T) {
+		got, err := policiesFromTarball(tarball, "")
+		require.NoError(t, err)
+
+		names := make([]string, len(got))
+		for i, pol := range got {
+			names[i] = pol.Name
+		}
+		assert.Equal(t, []string{
+			"other/buckets.rego",
+			"policies/instances.rego",
+			"policies/storage/buckets.rego",
+		}, names)
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		got, err := policiesFromTarball(tarball, "policies/")
+		require.NoError(t, err)
+
+		require.Len(t, got, 2)
+		assert.Equal(t, "instances.rego", got[0].Name)
+		assert.Equal(t, denyInstances, got[0].Source)
+		assert.Equal(t, "storage/buckets.rego", got[1].Name)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		tarball := newTestTarball(t, map[string]string{
+			"invalid.rego": "package",
+		})
+		_, err := policiesFromTarball(tarball, "")
+		assert.ErrorIs(t, err, ErrInvalidPolicy)
+	})
+}
+
+func newTestTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		require.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,43 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+// TFEPolicySet represents a policy set in the TFE API.
+type TFEPolicySet struct {
+	ID               resource.TfeID `jsonapi:"primary,policy-sets"`
+	Name             string         `jsonapi:"attribute" json:"name"`
+	Description      string         `jsonapi:"attribute" json:"description"`
+	Kind             string         `jsonapi:"attribute" json:"kind"`
+	Global           bool           `jsonapi:"attribute" json:"global"`
+	Overridable      bool           `jsonapi:"attribute" json:"overridable"`
+	EnforcementLevel string         `jsonapi:"attribute" json:"enforcement-level"`
+	PolicyCount      int            `jsonapi:"attribute" json:"policy-count"`
+	VCSRepo          *TFEVCSRepo    `jsonapi:"attribute" json:"vcs-repo,omitempty"`
+	PoliciesPath     string         `jsonapi:"attribute" json:"policies-path,omitempty"`
+	CreatedAt        time.Time      `jsonapi:"attribute" json:"created-at"`
+	UpdatedAt        time.Time      `jsonapi:"attribute" json:"updated-at"`
+}
+
+// TFEVCSRepo is the VCS repository from which a policy set's policies are
+// sourced.
+type TFEVCSRepo struct {
+	Branch            string         `json:"branch"`
+	Identifier        string         `json:"identifier"`
+	OAuthTokenID      resource.TfeID `json:"oauth-token-id"`
+	IngressSubmodules bool           `json:"ingress-submodules"`
+}
+
+// TFEPolicy represents a policy in the TFE API.
+type TFEPolicy struct {
+	ID        resource.TfeID `jsonapi:"primary,policies"`
+	Name      string         `jsonapi:"attribute" json:"name"`
+	Kind      string         `jsonapi:"attribute" json:"kind"`
+	UpdatedAt time.Time      `jsonapi:"attribute" json:"updated-at"`
+
+	// Relations
+	PolicySet *TFEPolicySet `jsonapi:"relationship" json:"policy-set"`
+}
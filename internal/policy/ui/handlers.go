@@ -0,0 +1,312 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/policy"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/ui/helpers"
+)
+
+type Handlers struct {
+	Client     PolicyService
+	Authorizer authz.Interface
+}
+
+type PolicyService interface {
+	CreatePolicySet(ctx context.Context, org organization.Name, opts policy.CreatePolicySetOptions) (*policy.PolicySet, error)
+	GetPolicySet(ctx context.Context, id resource.TfeID) (*policy.PolicySet, error)
+	ListPolicySets(ctx context.Context, org organization.Name) ([]*policy.PolicySet, error)
+	DeletePolicySet(ctx context.Context, id resource.TfeID) (*policy.PolicySet, error)
+
+	CreatePolicy(ctx context.Context, setID resource.TfeID, opts policy.CreatePolicyOptions) (*policy.Policy, error)
+	GetPolicy(ctx context.Context, id resource.TfeID) (*policy.Policy, error)
+	ListPolicies(ctx context.Context, setID resource.TfeID) ([]*policy.Policy, error)
+	UpdatePolicy(ctx context.Context, id resource.TfeID, opts policy.UpdatePolicyOptions) (*policy.Policy, error)
+	DeletePolicy(ctx context.Context, id resource.TfeID) (*policy.Policy, error)
+
+	GetRunPolicyCheck(ctx context.Context, runID resource.TfeID) (*policy.Check, error)
+	OverridePolicyCheck(ctx context.Context, id resource.TfeID) (*policy.Check, error)
+}
+
+func (h *Handlers) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/policy-sets", h.listPolicySets).Methods("GET")
+	r.HandleFunc("/organizations/{organization_name}/policy-sets/create", h.createPolicySet).Methods("POST")
+	r.HandleFunc("/policy-sets/{policy_set_id}", h.getPolicySet).Methods("GET")
+	r.HandleFunc("/policy-sets/{policy_set_id}/delete", h.deletePolicySet).Methods("POST")
+
+	r.HandleFunc("/policy-sets/{policy_set_id}/policies/create", h.createPolicy).Methods("POST")
+	r.HandleFunc("/policies/{policy_id}", h.getPolicy).Methods("GET")
+	r.HandleFunc("/policies/{policy_id}/update", h.updatePolicy).Methods("POST")
+	r.HandleFunc("/policies/{policy_id}/delete", h.deletePolicy).Methods("POST")
+
+	r.HandleFunc("/runs/{run_id}/policy-check", h.getRunPolicyCheck).Methods("GET")
+	r.HandleFunc("/policy-checks/{policy_check_id}/override", h.overridePolicyCheck).Methods("POST")
+}
+
+func (h *Handlers) listPolicySets(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name organization.Name `schema:"organization_name"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	sets, err := h.Client.ListPolicySets(r.Context(), params.Name)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		listPolicySets(listPolicySetsProps{
+			organization: params.Name,
+			sets:         sets,
+			canCreate:    h.Authorizer.CanAccess(r.Context(), resource.Create, resource.PolicySetKind, params.Name),
+		}),
+		"policy sets",
+		w,
+		r,
+		helpers.WithOrganization(params.Name),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Policy Sets"},
+		),
+		helpers.WithSideMenu(helpers.OrganizationSettingsMenu(params.Name)),
+	)
+}
+
+func (h *Handlers) createPolicySet(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization     organization.Name       `schema:"organization_name,required"`
+		Name             string                  `schema:"name,required"`
+		Description      string                  `schema:"description"`
+		EnforcementLevel policy.EnforcementLevel `schema:"enforcement_level"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	set, err := h.Client.CreatePolicySet(r.Context(), params.Organization, policy.CreatePolicySetOptions{
+		Name:             params.Name,
+		Description:      params.Description,
+		EnforcementLevel: params.EnforcementLevel,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "created policy set: "+set.Name)
+	http.Redirect(w, r, path.Get(set.ID), http.StatusFound)
+}
+
+func (h *Handlers) getPolicySet(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	set, err := h.Client.GetPolicySet(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	// Policies belonging to a VCS sourced policy set are only retrieved from
+	// the repository when a check is performed.
+	var policies []*policy.Policy
+	if set.VCS == nil {
+		policies, err = h.Client.ListPolicies(r.Context(), id)
+		if err != nil {
+			helpers.Error(r, w, err.Error())
+			return
+		}
+	}
+
+	helpers.RenderPage(
+		getPolicySet(getPolicySetProps{
+			set:       set,
+			policies:  policies,
+			canUpdate: h.Authorizer.CanAccess(r.Context(), resource.Update, resource.PolicySetKind, id),
+		}),
+		set.Name,
+		w,
+		r,
+		helpers.WithOrganization(set.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Policy Sets", Link: path.List(resource.PolicySetKind, set.Organization)},
+			helpers.Breadcrumb{Name: set.Name},
+		),
+		helpers.WithSideMenu(helpers.OrganizationSettingsMenu(set.Organization)),
+	)
+}
+
+func (h *Handlers) deletePolicySet(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_set_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	set, err := h.Client.DeletePolicySet(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "deleted policy set: "+set.Name)
+	http.Redirect(w, r, path.List(resource.PolicySetKind, set.Organization), http.StatusFound)
+}
+
+func (h *Handlers) createPolicy(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		PolicySetID resource.TfeID `schema:"policy_set_id,required"`
+		Name        string         `schema:"name,required"`
+		Source      string         `schema:"source,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	pol, err := h.Client.CreatePolicy(r.Context(), params.PolicySetID, policy.CreatePolicyOptions{
+		Name: params.Name,
+		// Undo the browser's conversion of \n to \r\n in the textarea input.
+		Source: strings.ReplaceAll(params.Source, "\r\n", "\n"),
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "created policy: "+pol.Name)
+	http.Redirect(w, r, path.Get(params.PolicySetID), http.StatusFound)
+}
+
+func (h *Handlers) getPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	pol, err := h.Client.GetPolicy(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	set, err := h.Client.GetPolicySet(r.Context(), pol.PolicySetID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		getPolicy(getPolicyProps{
+			policy:    pol,
+			canUpdate: h.Authorizer.CanAccess(r.Context(), resource.Update, resource.PolicyKind, id),
+		}),
+		pol.Name,
+		w,
+		r,
+		helpers.WithOrganization(set.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Policy Sets", Link: path.List(resource.PolicySetKind, set.Organization)},
+			helpers.Breadcrumb{Name: set.Name, Link: path.Get(set.ID)},
+			helpers.Breadcrumb{Name: pol.Name},
+		),
+		helpers.WithSideMenu(helpers.OrganizationSettingsMenu(set.Organization)),
+	)
+}
+
+func (h *Handlers) updatePolicy(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ID     resource.TfeID `schema:"policy_id,required"`
+		Source string         `schema:"source,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	pol, err := h.Client.UpdatePolicy(r.Context(), params.ID, policy.UpdatePolicyOptions{
+		Source: new(strings.ReplaceAll(params.Source, "\r\n", "\n")),
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated policy: "+pol.Name)
+	http.Redirect(w, r, path.Get(pol.ID), http.StatusFound)
+}
+
+func (h *Handlers) deletePolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	pol, err := h.Client.DeletePolicy(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "deleted policy: "+pol.Name)
+	http.Redirect(w, r, path.Get(pol.PolicySetID), http.StatusFound)
+}
+
+// getRunPolicyCheck renders the policy check for a run, for embedding in the
+// run page.
+func (h *Handlers) getRunPolicyCheck(w http.ResponseWriter, r *http.Request) {
+	runID, err := decode.ID("run_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	check, err := h.Client.GetRunPolicyCheck(r.Context(), runID)
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		// Policies are yet to be checked.
+		check = nil
+	} else if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	props := policyCheckProps{check: check}
+	if check != nil {
+		props.canOverride = h.Authorizer.CanAccess(r.Context(), resource.Override, resource.PolicyCheckKind, check.ID)
+	}
+	helpers.Render(policyCheck(props), w, r)
+}
+
+func (h *Handlers) overridePolicyCheck(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("policy_check_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	check, err := h.Client.OverridePolicyCheck(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "overridden policy check")
+	http.Redirect(w, r, path.Get(check.RunID), http.StatusFound)
+}
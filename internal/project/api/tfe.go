@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/project"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tfeapi"
+)
+
+type TFEAPI struct {
+	*tfeapi.Responder
+	Client tfeClient
+}
+
+type tfeClient interface {
+	CreateProject(ctx context.Context, org organization.Name, opts project.CreateOptions) (*project.Project, error)
+	GetProject(ctx context.Context, id resource.TfeID) (*project.Project, error)
+	ListProjects(ctx context.Context, org organization.Name) ([]*project.Project, error)
+	UpdateProject(ctx context.Context, id resource.TfeID, opts project.UpdateOptions) (*project.Project, error)
+	DeleteProject(ctx context.Context, id resource.TfeID) (*project.Project, error)
+}
+
+// tfeProjectCreateOptions are the options for creating a new project via the
+// TFE API.
+type tfeProjectCreateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,projects"`
+
+	// The name of the project.
+	Name string `jsonapi:"attribute" json:"name"`
+
+	// An optional description of the project.
+	Description *string `jsonapi:"attribute" json:"description,omitempty"`
+}
+
+// tfeProjectUpdateOptions are the options for updating a project via the TFE
+// API.
+type tfeProjectUpdateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,projects"`
+
+	// The name of the project.
+	Name *string `jsonapi:"attribute" json:"name,omitempty"`
+
+	// The description of the project.
+	Description *string `jsonapi:"attribute" json:"description,omitempty"`
+}
+
+func (a *TFEAPI) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/projects", a.createProject).Methods("POST")
+	r.HandleFunc("/organizations/{organization_name}/projects", a.listProjects).Methods("GET")
+	r.HandleFunc("/projects/{project_id}", a.getProject).Methods("GET")
+	r.HandleFunc("/projects/{project_id}", a.updateProject).Methods("PATCH")
+	r.HandleFunc("/projects/{project_id}", a.deleteProject).Methods("DELETE")
+}
+
+func (a *TFEAPI) createProject(w http.ResponseWriter, r *http.Request) {
+	var pathParams struct {
+		Organization organization.Name `schema:"organization_name,required"`
+	}
+	if err := decode.Route(&pathParams, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeProjectCreateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	opts := project.CreateOptions{Name: params.Name}
+	if params.Description != nil {
+		opts.Description = *params.Description
+	}
+	prj, err := a.Client.CreateProject(r.Context(), pathParams.Organization, opts)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.convert(prj), http.StatusCreated)
+}
+
+func (a *TFEAPI) listProjects(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+		resource.PageOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	projects, err := a.Client.ListProjects(r.Context(), params.Organization)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+
+	// client expects a page, whereas the service returns the full result
+	// set, so convert to page first
+	page := resource.NewPage(projects, params.PageOptions, nil)
+
+	items := make([]*project.TFEProject, len(page.Items))
+	for i, from := range page.Items {
+		items[i] = a.convert(from)
+	}
+	a.RespondWithPage(w, r, items, page.Pagination)
+}
+
+func (a *TFEAPI) getProject(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("project_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	prj, err := a.Client.GetProject(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.convert(prj), http.StatusOK)
+}
+
+func (a *TFEAPI) updateProject(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("project_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeProjectUpdateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	prj, err := a.Client.UpdateProject(r.Context(), id, project.UpdateOptions{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.convert(prj), http.StatusOK)
+}
+
+func (a *TFEAPI) deleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("project_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if _, err := a.Client.DeleteProject(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *TFEAPI) convert(from *project.Project) *project.TFEProject {
+	return &project.TFEProject{
+		ID:           from.ID,
+		CreatedAt:    from.CreatedAt,
+		UpdatedAt:    from.UpdatedAt,
+		Name:         from.Name,
+		Description:  from.Description,
+		Organization: &organization.TFEOrganization{Name: from.Organization},
+	}
+}
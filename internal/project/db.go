@@ -0,0 +1,181 @@
+package project
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type pgdb struct {
+	*sql.DB
+}
+
+func (db *pgdb) create(ctx context.Context, project *Project) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO projects (
+    project_id,
+    created_at,
+    updated_at,
+    name,
+    description,
+    organization_name
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @name,
+    @description,
+    @organization_name
+)
+`, pgx.NamedArgs{
+		"id":                project.ID,
+		"created_at":        project.CreatedAt,
+		"updated_at":        project.UpdatedAt,
+		"name":              project.Name,
+		"description":       project.Description,
+		"organization_name": project.Organization,
+	})
+	return err
+}
+
+func (db *pgdb) update(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *Project) error) (*Project, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*Project, error) {
+			rows := db.Query(ctx, `
+SELECT *
+FROM projects
+WHERE project_id = $1
+FOR UPDATE
+`, id)
+			return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Project])
+		},
+		updateFunc,
+		func(ctx context.Context, project *Project) error {
+			_, err := db.Exec(ctx, `
+UPDATE projects
+SET name        = @name,
+    description = @description,
+    updated_at  = @updated_at
+WHERE project_id = @id
+`, pgx.NamedArgs{
+				"id":          project.ID,
+				"name":        project.Name,
+				"description": project.Description,
+				"updated_at":  project.UpdatedAt,
+			})
+			return err
+		},
+	)
+}
+
+func (db *pgdb) get(ctx context.Context, id resource.TfeID) (*Project, error) {
+	rows := db.Query(ctx, `
+SELECT *
+FROM projects
+WHERE project_id = $1
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Project])
+}
+
+func (db *pgdb) getByName(ctx context.Context, org organization.Name, name string) (*Project, error) {
+	rows := db.Query(ctx, `
+SELECT *
+FROM projects
+WHERE organization_name = $1
+AND   name              = $2
+`, org, name)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Project])
+}
+
+func (db *pgdb) list(ctx context.Context, org organization.Name) ([]*Project, error) {
+	rows := db.Query(ctx, `
+SELECT *
+FROM projects
+WHERE organization_name = $1
+ORDER BY name ASC
+`, org)
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Project])
+}
+
+func (db *pgdb) delete(ctx context.Context, id resource.TfeID) error {
+	_, err := db.Exec(ctx, `
+DELETE
+FROM projects
+WHERE project_id = $1
+`, id)
+	if err != nil {
+		var fkerr *internal.ForeignKeyError
+		if errors.As(err, &fkerr) {
+			if fkerr.ConstraintName == "project_id_fk" && fkerr.TableName == "workspaces" {
+				return ErrProjectHasWorkspaces
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (db *pgdb) setPermission(ctx context.Context, projectID, teamID resource.TfeID, role authz.Role) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO project_permissions (
+    project_id,
+    team_id,
+    role
+) VALUES (
+    $1,
+    $2,
+    $3
+) ON CONFLICT (project_id, team_id) DO UPDATE SET role = $3
+`,
+		projectID,
+		teamID,
+		role.String(),
+	)
+	return err
+}
+
+func (db *pgdb) unsetPermission(ctx context.Context, projectID, teamID resource.TfeID) error {
+	_, err := db.Exec(ctx, `
+DELETE
+FROM project_permissions
+WHERE project_id = $1
+AND   team_id    = $2
+`,
+		projectID,
+		teamID,
+	)
+	return err
+}
+
+func (db *pgdb) listPermissions(ctx context.Context, projectID resource.TfeID) ([]Permission, error) {
+	type model struct {
+		TeamID resource.TfeID `db:"team_id"`
+		Role   string         `db:"role"`
+	}
+	rows := db.Query(ctx, `
+SELECT team_id, role
+FROM project_permissions
+WHERE project_id = $1
+`, projectID)
+	models, err := sql.CollectRows(rows, pgx.RowToStructByName[model])
+	if err != nil {
+		return nil, err
+	}
+	perms := make([]Permission, len(models))
+	for i, m := range models {
+		role, err := authz.WorkspaceRoleFromString(m.Role)
+		if err != nil {
+			return nil, err
+		}
+		perms[i] = Permission{TeamID: m.TeamID, Role: role}
+	}
+	return perms, nil
+}
@@ -0,0 +1,87 @@
+// Package project manages projects, which group workspaces within an
+// organization.
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+)
+
+// ErrProjectHasWorkspaces is returned when attempting to delete a project that
+// still has workspaces.
+var ErrProjectHasWorkspaces = fmt.Errorf("project still has workspaces: %w", internal.ErrConflict)
+
+type (
+	// Project is a group of workspaces within an organization.
+	Project struct {
+		ID           resource.TfeID    `jsonapi:"primary,projects" db:"project_id"`
+		CreatedAt    time.Time         `jsonapi:"attribute" json:"created_at" db:"created_at"`
+		UpdatedAt    time.Time         `jsonapi:"attribute" json:"updated_at" db:"updated_at"`
+		Name         string            `jsonapi:"attribute" json:"name" db:"name"`
+		Description  string            `jsonapi:"attribute" json:"description" db:"description"`
+		Organization organization.Name `jsonapi:"attribute" json:"organization" db:"organization_name"`
+	}
+
+	// Permission binds a role to a team, granting the team the role on every
+	// workspace in the project.
+	Permission struct {
+		TeamID resource.TfeID
+		Role   authz.Role
+	}
+
+	CreateOptions struct {
+		Name        string
+		Description string
+	}
+
+	UpdateOptions struct {
+		Name        *string
+		Description *string
+	}
+)
+
+func newProject(org organization.Name, opts CreateOptions) (*Project, error) {
+	if opts.Name == "" {
+		return nil, internal.ErrRequiredName
+	}
+	now := internal.CurrentTimestamp(nil)
+	return &Project{
+		ID:           resource.NewTfeID(resource.ProjectKind),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Name:         opts.Name,
+		Description:  opts.Description,
+		Organization: org,
+	}, nil
+}
+
+func (p *Project) update(opts UpdateOptions) error {
+	if opts.Name != nil {
+		if *opts.Name == "" {
+			return internal.ErrRequiredName
+		}
+		p.Name = *opts.Name
+	}
+	if opts.Description != nil {
+		p.Description = *opts.Description
+	}
+	p.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+func (p *Project) String() string { return p.Name }
+
+// LogValue implements slog.LogValuer.
+func (p *Project) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", p.ID.String()),
+		slog.String("name", p.Name),
+		slog.Any("organization", p.Organization),
+	)
+}
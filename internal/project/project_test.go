@@ -0,0 +1,44 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProject(t *testing.T) {
+	org := organization.NewTestName(t)
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := newProject(org, CreateOptions{Name: "networking", Description: "core network"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "networking", got.Name)
+		assert.Equal(t, "core network", got.Description)
+		assert.Equal(t, org, got.Organization)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := newProject(org, CreateOptions{})
+		assert.Equal(t, internal.ErrRequiredName, err)
+	})
+}
+
+func TestProject_Update(t *testing.T) {
+	prj, err := newProject(organization.NewTestName(t), CreateOptions{Name: "networking"})
+	require.NoError(t, err)
+
+	t.Run("rename", func(t *testing.T) {
+		err := prj.update(UpdateOptions{Name: new("platform")})
+		require.NoError(t, err)
+		assert.Equal(t, "platform", prj.Name)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		err := prj.update(UpdateOptions{Name: new("")})
+		assert.Equal(t, internal.ErrRequiredName, err)
+	})
+}
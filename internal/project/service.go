@@ -0,0 +1,224 @@
+package project
+
+import (
+	"context"
+
+	"github.com/leg100/otf/internal/audit"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type (
+	// Alias service to permit embedding it with other services in a struct
+	// without a name clash.
+	ProjectService = Service
+
+	Service struct {
+		logr.Logger
+		*authz.Authorizer
+
+		db    *pgdb
+		audit audit.Recorder
+	}
+
+	Options struct {
+		DB            *sql.DB
+		Logger        logr.Logger
+		Authorizer    *authz.Authorizer
+		AuditRecorder audit.Recorder
+	}
+)
+
+func NewService(opts Options) *Service {
+	svc := &Service{
+		Logger:     opts.Logger,
+		Authorizer: opts.Authorizer,
+		db:         &pgdb{opts.DB},
+		audit:      opts.AuditRecorder,
+	}
+	// Provide a way for other components to find the parent organization of a
+	// project given its ID.
+	opts.Authorizer.RegisterParentResolver(resource.ProjectKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			project, err := svc.db.get(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return project.Organization, nil
+		},
+	)
+	return svc
+}
+
+func (s *Service) CreateProject(ctx context.Context, org organization.Name, opts CreateOptions) (*Project, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.ProjectKind, org)
+	if err != nil {
+		return nil, err
+	}
+	project, err := newProject(org, opts)
+	if err != nil {
+		s.Error(err, "constructing project", "organization", org, "subject", subject)
+		return nil, err
+	}
+	if err := s.db.create(ctx, project); err != nil {
+		s.Error(err, "creating project", "project", project, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("created project", "project", project, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: project.Organization,
+		Action:       resource.Create,
+		ResourceID:   project.ID,
+		After:        project,
+	})
+	return project, nil
+}
+
+func (s *Service) GetProject(ctx context.Context, id resource.TfeID) (*Project, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.ProjectKind, id)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.db.get(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving project", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved project", "project", project, "subject", subject)
+	return project, nil
+}
+
+func (s *Service) GetProjectByName(ctx context.Context, org organization.Name, name string) (*Project, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.ProjectKind, org)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.db.getByName(ctx, org, name)
+	if err != nil {
+		s.Error(err, "retrieving project", "organization", org, "name", name, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved project", "project", project, "subject", subject)
+	return project, nil
+}
+
+func (s *Service) ListProjects(ctx context.Context, org organization.Name) ([]*Project, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.ProjectKind, org)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.db.list(ctx, org)
+	if err != nil {
+		s.Error(err, "listing projects", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed projects", "organization", org, "total", len(projects), "subject", subject)
+	return projects, nil
+}
+
+func (s *Service) UpdateProject(ctx context.Context, id resource.TfeID, opts UpdateOptions) (*Project, error) {
+	subject, err := s.Authorize(ctx, resource.Update, resource.ProjectKind, id)
+	if err != nil {
+		return nil, err
+	}
+	var before Project
+	updated, err := s.db.update(ctx, id, func(ctx context.Context, project *Project) error {
+		before = *project
+		return project.update(opts)
+	})
+	if err != nil {
+		s.Error(err, "updating project", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("updated project", "project", updated, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: updated.Organization,
+		Action:       resource.Update,
+		ResourceID:   updated.ID,
+		Before:       &before,
+		After:        updated,
+	})
+	return updated, nil
+}
+
+// DeleteProject deletes a project. A project cannot be deleted whilst it still
+// has workspaces.
+func (s *Service) DeleteProject(ctx context.Context, id resource.TfeID) (*Project, error) {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.ProjectKind, id)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.db.get(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving project", "id", id, "subject", subject)
+		return nil, err
+	}
+	if err := s.db.delete(ctx, id); err != nil {
+		s.Error(err, "deleting project", "project", project, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("deleted project", "project", project, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: project.Organization,
+		Action:       resource.Delete,
+		ResourceID:   project.ID,
+		Before:       project,
+	})
+	return project, nil
+}
+
+// SetProjectPermission grants a team a role on every workspace in the project.
+func (s *Service) SetProjectPermission(ctx context.Context, projectID, teamID resource.TfeID, role authz.Role) error {
+	subject, err := s.Authorize(ctx, resource.SetPermission, resource.ProjectKind, projectID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.setPermission(ctx, projectID, teamID, role); err != nil {
+		s.Error(err, "setting project permission", "project", projectID, "team_id", teamID, "subject", subject)
+		return err
+	}
+	s.V(0).Info("set project permission", "project", projectID, "team_id", teamID, "role", role, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     projectID,
+		Action:     resource.SetPermission,
+		ResourceID: projectID,
+		After:      map[string]any{"team_id": teamID, "role": role.String()},
+	})
+	return nil
+}
+
+func (s *Service) UnsetProjectPermission(ctx context.Context, projectID, teamID resource.TfeID) error {
+	subject, err := s.Authorize(ctx, resource.UnsetPermission, resource.ProjectKind, projectID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.unsetPermission(ctx, projectID, teamID); err != nil {
+		s.Error(err, "unsetting project permission", "project", projectID, "team_id", teamID, "subject", subject)
+		return err
+	}
+	s.V(0).Info("unset project permission", "project", projectID, "team_id", teamID, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     projectID,
+		Action:     resource.UnsetPermission,
+		ResourceID: projectID,
+		Before:     map[string]any{"team_id": teamID},
+	})
+	return nil
+}
+
+func (s *Service) ListProjectPermissions(ctx context.Context, projectID resource.TfeID) ([]Permission, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.ProjectKind, projectID)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := s.db.listPermissions(ctx, projectID)
+	if err != nil {
+		s.Error(err, "listing project permissions", "project", projectID, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed project permissions", "project", projectID, "total", len(perms), "subject", subject)
+	return perms, nil
+}
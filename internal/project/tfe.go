@@ -0,0 +1,20 @@
+package project
+
+import (
+	"time"
+
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+)
+
+// TFEProject represents a project in the TFE API.
+type TFEProject struct {
+	ID          resource.TfeID `jsonapi:"primary,projects"`
+	CreatedAt   time.Time      `jsonapi:"attribute" json:"created-at"`
+	UpdatedAt   time.Time      `jsonapi:"attribute" json:"updated-at"`
+	Name        string         `jsonapi:"attribute" json:"name"`
+	Description string         `jsonapi:"attribute" json:"description"`
+
+	// Relations
+	Organization *organization.TFEOrganization `jsonapi:"relationship" json:"organization"`
+}
@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/project"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/team"
+	"github.com/leg100/otf/internal/ui/helpers"
+)
+
+type Handlers struct {
+	Client     ProjectService
+	Authorizer authz.Interface
+}
+
+type ProjectService interface {
+	CreateProject(ctx context.Context, org organization.Name, opts project.CreateOptions) (*project.Project, error)
+	GetProject(ctx context.Context, id resource.TfeID) (*project.Project, error)
+	ListProjects(ctx context.Context, org organization.Name) ([]*project.Project, error)
+	UpdateProject(ctx context.Context, id resource.TfeID, opts project.UpdateOptions) (*project.Project, error)
+	DeleteProject(ctx context.Context, id resource.TfeID) (*project.Project, error)
+
+	SetProjectPermission(ctx context.Context, projectID, teamID resource.TfeID, role authz.Role) error
+	UnsetProjectPermission(ctx context.Context, projectID, teamID resource.TfeID) error
+	ListProjectPermissions(ctx context.Context, projectID resource.TfeID) ([]project.Permission, error)
+
+	ListTeams(ctx context.Context, organization organization.Name) ([]*team.Team, error)
+}
+
+func (h *Handlers) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/projects", h.listProjects).Methods("GET")
+	r.HandleFunc("/organizations/{organization_name}/projects/create", h.createProject).Methods("POST")
+	r.HandleFunc("/projects/{project_id}", h.getProject).Methods("GET")
+	r.HandleFunc("/projects/{project_id}/update", h.updateProject).Methods("POST")
+	r.HandleFunc("/projects/{project_id}/delete", h.deleteProject).Methods("POST")
+	r.HandleFunc("/projects/{project_id}/set-permission", h.setPermission).Methods("POST")
+	r.HandleFunc("/projects/{project_id}/unset-permission", h.unsetPermission).Methods("POST")
+}
+
+func (h *Handlers) listProjects(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name organization.Name `schema:"organization_name"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	projects, err := h.Client.ListProjects(r.Context(), params.Name)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		listProjects(listProjectsProps{
+			organization: params.Name,
+			projects:     projects,
+			canCreate:    h.Authorizer.CanAccess(r.Context(), resource.Create, resource.ProjectKind, params.Name),
+		}),
+		"projects",
+		w,
+		r,
+		helpers.WithOrganization(params.Name),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Projects"},
+		),
+	)
+}
+
+func (h *Handlers) createProject(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+		Name         string            `schema:"name,required"`
+		Description  string            `schema:"description"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	prj, err := h.Client.CreateProject(r.Context(), params.Organization, project.CreateOptions{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "created project: "+prj.Name)
+	http.Redirect(w, r, path.Get(prj.ID), http.StatusFound)
+}
+
+func (h *Handlers) getProject(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("project_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	prj, err := h.Client.GetProject(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	perms, err := h.Client.ListProjectPermissions(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	// Get teams for populating team permissions
+	teams, err := h.Client.ListTeams(r.Context(), prj.Organization)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	// want permissions to include not only team ID but team name too for
+	// user's benefit
+	var (
+		assigned   []projectPerm
+		unassigned []*team.Team
+	)
+	for _, t := range teams {
+		var found bool
+		for _, perm := range perms {
+			if perm.TeamID == t.ID {
+				assigned = append(assigned, projectPerm{role: perm.Role, team: t})
+				found = true
+				break
+			}
+		}
+		if !found {
+			unassigned = append(unassigned, t)
+		}
+	}
+
+	helpers.RenderPage(
+		getProject(getProjectProps{
+			project:    prj,
+			assigned:   assigned,
+			unassigned: unassigned,
+			roles: []authz.Role{
+				authz.WorkspaceReadRole,
+				authz.WorkspacePlanRole,
+				authz.WorkspaceWriteRole,
+				authz.WorkspaceAdminRole,
+			},
+			workspacesURL: workspacesURL(prj),
+			canUpdate:     h.Authorizer.CanAccess(r.Context(), resource.Update, resource.ProjectKind, id),
+			canDelete:     h.Authorizer.CanAccess(r.Context(), resource.Delete, resource.ProjectKind, id),
+		}),
+		prj.Name,
+		w,
+		r,
+		helpers.WithOrganization(prj.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Projects", Link: path.List(resource.ProjectKind, prj.Organization)},
+			helpers.Breadcrumb{Name: prj.Name},
+		),
+	)
+}
+
+func (h *Handlers) updateProject(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ID          resource.TfeID `schema:"project_id,required"`
+		Name        *string        `schema:"name"`
+		Description *string        `schema:"description"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	prj, err := h.Client.UpdateProject(r.Context(), params.ID, project.UpdateOptions{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated project: "+prj.Name)
+	http.Redirect(w, r, path.Get(prj.ID), http.StatusFound)
+}
+
+func (h *Handlers) deleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("project_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	prj, err := h.Client.DeleteProject(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "deleted project: "+prj.Name)
+	http.Redirect(w, r, path.List(resource.ProjectKind, prj.Organization), http.StatusFound)
+}
+
+func (h *Handlers) setPermission(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ProjectID resource.TfeID `schema:"project_id,required"`
+		TeamID    resource.TfeID `schema:"team_id,required"`
+		Role      string         `schema:"role,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	role, err := authz.WorkspaceRoleFromString(params.Role)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	err = h.Client.SetProjectPermission(r.Context(), params.ProjectID, params.TeamID, role)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	helpers.FlashSuccess(w, "updated project permissions")
+	http.Redirect(w, r, path.Get(params.ProjectID), http.StatusFound)
+}
+
+func (h *Handlers) unsetPermission(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ProjectID resource.TfeID `schema:"project_id,required"`
+		TeamID    resource.TfeID `schema:"team_id,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	err := h.Client.UnsetProjectPermission(r.Context(), params.ProjectID, params.TeamID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	helpers.FlashSuccess(w, "deleted project permission")
+	http.Redirect(w, r, path.Get(params.ProjectID), http.StatusFound)
+}
+
+// workspacesURL returns the URL of the list of workspaces belonging to the
+// project.
+func workspacesURL(prj *project.Project) string {
+	q := url.Values{}
+	q.Set("filter[project][id]", prj.ID.String())
+	return path.List(resource.WorkspaceKind, prj.Organization) + "?" + q.Encode()
+}
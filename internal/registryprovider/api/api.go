@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/registryprovider"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tfeapi"
+)
+
+type API struct {
+	*tfeapi.Responder
+	Client apiClient
+}
+
+type apiClient interface {
+	CreateProvider(ctx context.Context, opts registryprovider.CreateOptions) (*registryprovider.Provider, error)
+	GetProvider(ctx context.Context, org organization.Name, name string) (*registryprovider.Provider, error)
+	ListProviders(ctx context.Context, org organization.Name) ([]*registryprovider.Provider, error)
+	DeleteProvider(ctx context.Context, id resource.TfeID) (*registryprovider.Provider, error)
+
+	CreateVersion(ctx context.Context, providerID resource.TfeID, opts registryprovider.CreateVersionOptions) (*registryprovider.Version, error)
+	DeleteVersion(ctx context.Context, versionID resource.TfeID) error
+	UploadShasums(ctx context.Context, versionID resource.TfeID, shasums []byte) error
+	UploadShasumsSignature(ctx context.Context, versionID resource.TfeID, sig []byte) error
+	UploadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string, zip []byte) (*registryprovider.Platform, error)
+}
+
+func (a *API) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization}/registry-providers", a.createProvider).Methods("POST")
+	r.HandleFunc("/organizations/{organization}/registry-providers", a.listProviders).Methods("GET")
+	r.HandleFunc("/organizations/{organization}/registry-providers/{name}", a.getProvider).Methods("GET")
+	r.HandleFunc("/registry-providers/{provider_id}", a.deleteProvider).Methods("DELETE")
+
+	r.HandleFunc("/registry-providers/{provider_id}/versions", a.createVersion).Methods("POST")
+	r.HandleFunc("/registry-provider-versions/{version_id}", a.deleteVersion).Methods("DELETE")
+	r.HandleFunc("/registry-provider-versions/{version_id}/shasums", a.uploadShasums).Methods("PUT")
+	r.HandleFunc("/registry-provider-versions/{version_id}/shasums-sig", a.uploadShasumsSignature).Methods("PUT")
+	r.HandleFunc("/registry-provider-versions/{version_id}/platforms/{os}/{arch}", a.uploadPlatform).Methods("PUT")
+}
+
+func (a *API) createProvider(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var opts registryprovider.CreateOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	opts.Organization = params.Organization
+	provider, err := a.Client.CreateProvider(r.Context(), opts)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, provider, http.StatusCreated)
+}
+
+func (a *API) listProviders(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	providers, err := a.Client.ListProviders(r.Context(), params.Organization)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, providers, http.StatusOK)
+}
+
+func (a *API) getProvider(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization,required"`
+		Name         string            `schema:"name,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	provider, err := a.Client.GetProvider(r.Context(), params.Organization, params.Name)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, provider, http.StatusOK)
+}
+
+func (a *API) deleteProvider(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("provider_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if _, err := a.Client.DeleteProvider(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) createVersion(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("provider_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var opts registryprovider.CreateVersionOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	version, err := a.Client.CreateVersion(r.Context(), id, opts)
+	if err != nil {
+		if errors.Is(err, registryprovider.ErrInvalidVersion) || errors.Is(err, registryprovider.ErrMissingSigningKey) {
+			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, version, http.StatusCreated)
+}
+
+func (a *API) deleteVersion(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("version_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if err := a.Client.DeleteVersion(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) uploadShasums(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("version_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, r.Body); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if err := a.Client.UploadShasums(r.Context(), id, buf.Bytes()); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) uploadShasumsSignature(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("version_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, r.Body); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if err := a.Client.UploadShasumsSignature(r.Context(), id, buf.Bytes()); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) uploadPlatform(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		VersionID resource.TfeID `schema:"version_id,required"`
+		OS        string         `schema:"os,required"`
+		Arch      string         `schema:"arch,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, r.Body); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	_, err := a.Client.UploadPlatform(r.Context(), params.VersionID, params.OS, params.Arch, buf.Bytes())
+	if err != nil {
+		if errors.Is(err, registryprovider.ErrMissingShasums) || errors.Is(err, registryprovider.ErrShasumMismatch) {
+			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/registryprovider"
+	"github.com/leg100/otf/internal/resource"
+)
+
+// Alias client to permit embedding it with other clients in a struct
+// without a name clash.
+type RegistryProviderClient = Client
+
+type Client struct {
+	*otfhttp.Client
+}
+
+func (c *Client) CreateProvider(ctx context.Context, opts registryprovider.CreateOptions) (*registryprovider.Provider, error) {
+	u := fmt.Sprintf("organizations/%s/registry-providers", url.QueryEscape(opts.Organization.String()))
+	req, err := c.NewRequest("POST", u, &opts)
+	if err != nil {
+		return nil, err
+	}
+	var provider registryprovider.Provider
+	if err := c.Do(ctx, req, &provider); err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (c *Client) GetProvider(ctx context.Context, org organization.Name, name string) (*registryprovider.Provider, error) {
+	u := fmt.Sprintf("organizations/%s/registry-providers/%s", url.QueryEscape(org.String()), url.QueryEscape(name))
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var provider registryprovider.Provider
+	if err := c.Do(ctx, req, &provider); err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (c *Client) DeleteProvider(ctx context.Context, id resource.TfeID) error {
+	u := fmt.Sprintf("registry-providers/%s", url.QueryEscape(id.String()))
+	req, err := c.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+func (c *Client) CreateVersion(ctx context.Context, providerID resource.TfeID, opts registryprovider.CreateVersionOptions) (*registryprovider.Version, error) {
+	u := fmt.Sprintf("registry-providers/%s/versions", url.QueryEscape(providerID.String()))
+	req, err := c.NewRequest("POST", u, &opts)
+	if err != nil {
+		return nil, err
+	}
+	var version registryprovider.Version
+	if err := c.Do(ctx, req, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (c *Client) UploadShasums(ctx context.Context, versionID resource.TfeID, shasums []byte) error {
+	u := fmt.Sprintf("registry-provider-versions/%s/shasums", url.QueryEscape(versionID.String()))
+	req, err := c.NewRequest("PUT", u, shasums)
+	if err != nil {
+		return err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+func (c *Client) UploadShasumsSignature(ctx context.Context, versionID resource.TfeID, sig []byte) error {
+	u := fmt.Sprintf("registry-provider-versions/%s/shasums-sig", url.QueryEscape(versionID.String()))
+	req, err := c.NewRequest("PUT", u, sig)
+	if err != nil {
+		return err
+	}
+	return c.Do(ctx, req, nil)
+}
+
+func (c *Client) UploadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string, zip []byte) error {
+	u := fmt.Sprintf("registry-provider-versions/%s/platforms/%s/%s",
+		url.QueryEscape(versionID.String()),
+		url.QueryEscape(os),
+		url.QueryEscape(arch),
+	)
+	req, err := c.NewRequest("PUT", u, zip)
+	if err != nil {
+		return err
+	}
+	return c.Do(ctx, req, nil)
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leg100/otf/internal"
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/registryprovider"
+	registryproviderapi "github.com/leg100/otf/internal/registryprovider/api"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/spf13/cobra"
+)
+
+type CLI struct {
+	client client
+}
+
+type client interface {
+	CreateProvider(ctx context.Context, opts registryprovider.CreateOptions) (*registryprovider.Provider, error)
+	GetProvider(ctx context.Context, org organization.Name, name string) (*registryprovider.Provider, error)
+	DeleteProvider(ctx context.Context, id resource.TfeID) error
+
+	CreateVersion(ctx context.Context, providerID resource.TfeID, opts registryprovider.CreateVersionOptions) (*registryprovider.Version, error)
+	UploadShasums(ctx context.Context, versionID resource.TfeID, shasums []byte) error
+	UploadShasumsSignature(ctx context.Context, versionID resource.TfeID, sig []byte) error
+	UploadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string, zip []byte) error
+}
+
+func NewCommand(apiClient *otfhttp.Client) *cobra.Command {
+	cli := &CLI{}
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Private provider registry management",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Parent().PersistentPreRunE(cmd.Parent(), args); err != nil {
+				return err
+			}
+			cli.client = &registryproviderapi.Client{Client: apiClient}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(cli.publishCommand())
+	cmd.AddCommand(cli.deleteCommand())
+
+	return cmd
+}
+
+func (a *CLI) publishCommand() *cobra.Command {
+	var (
+		org         organization.Name
+		opts        registryprovider.CreateVersionOptions
+		keyFile     string
+		shasumsFile string
+		sigFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "publish [name] [zip files...]",
+		Short: "Publish a provider version",
+		Long: `Publish a provider version to the organization's private registry, creating the provider if it does not exist.
+
+Each zip file must follow the naming convention terraform-provider-<name>_<version>_<os>_<arch>.zip and have a matching entry in the SHA256SUMS file.`,
+		Args:          cobra.MinimumNArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, zips := args[0], args[1:]
+
+			// Determine platform of each zip before uploading anything.
+			type platform struct {
+				os, arch, path string
+			}
+			platforms := make([]platform, len(zips))
+			for i, path := range zips {
+				goos, goarch, err := parseFilename(name, opts.Version, filepath.Base(path))
+				if err != nil {
+					return err
+				}
+				platforms[i] = platform{os: goos, arch: goarch, path: path}
+			}
+
+			armor, err := os.ReadFile(keyFile)
+			if err != nil {
+				return fmt.Errorf("reading GPG public key: %w", err)
+			}
+			opts.ASCIIArmor = string(armor)
+			shasums, err := os.ReadFile(shasumsFile)
+			if err != nil {
+				return fmt.Errorf("reading SHA256SUMS: %w", err)
+			}
+			sig, err := os.ReadFile(sigFile)
+			if err != nil {
+				return fmt.Errorf("reading SHA256SUMS signature: %w", err)
+			}
+
+			provider, err := a.client.GetProvider(cmd.Context(), org, name)
+			if errors.Is(err, internal.ErrResourceNotFound) {
+				provider, err = a.client.CreateProvider(cmd.Context(), registryprovider.CreateOptions{
+					Organization: org,
+					Name:         name,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			version, err := a.client.CreateVersion(cmd.Context(), provider.ID, opts)
+			if err != nil {
+				return err
+			}
+			if err := a.client.UploadShasums(cmd.Context(), version.ID, shasums); err != nil {
+				return err
+			}
+			if err := a.client.UploadShasumsSignature(cmd.Context(), version.ID, sig); err != nil {
+				return err
+			}
+			for _, p := range platforms {
+				zip, err := os.ReadFile(p.path)
+				if err != nil {
+					return err
+				}
+				if err := a.client.UploadPlatform(cmd.Context(), version.ID, p.os, p.arch, zip); err != nil {
+					return fmt.Errorf("uploading %s: %w", p.path, err)
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully published %s/%s %s\n", org, name, version.Version)
+			return nil
+		},
+	}
+
+	cmd.Flags().Var(&org, "organization", "Organization to publish the provider to")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Version of the provider")
+	cmd.Flags().StringSliceVar(&opts.Protocols, "protocols", registryprovider.DefaultProtocols, "Plugin protocol versions supported by the provider")
+	cmd.Flags().StringVar(&opts.KeyID, "key-id", "", "ID of the GPG key that signed the SHA256SUMS file")
+	cmd.Flags().StringVar(&keyFile, "gpg-key", "", "Path to the ASCII-armored GPG public key")
+	cmd.Flags().StringVar(&shasumsFile, "shasums", "", "Path to the SHA256SUMS file")
+	cmd.Flags().StringVar(&sigFile, "shasums-sig", "", "Path to the SHA256SUMS signature file")
+	cmd.MarkFlagRequired("organization")
+	cmd.MarkFlagRequired("version")
+	cmd.MarkFlagRequired("key-id")
+	cmd.MarkFlagRequired("gpg-key")
+	cmd.MarkFlagRequired("shasums")
+	cmd.MarkFlagRequired("shasums-sig")
+
+	return cmd
+}
+
+func (a *CLI) deleteCommand() *cobra.Command {
+	var org organization.Name
+
+	cmd := &cobra.Command{
+		Use:           "delete [name]",
+		Short:         "Delete a provider and all its versions",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			provider, err := a.client.GetProvider(cmd.Context(), org, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.client.DeleteProvider(cmd.Context(), provider.ID); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully deleted provider %s\n", provider.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().Var(&org, "organization", "Organization the provider belongs to")
+	cmd.MarkFlagRequired("organization")
+
+	return cmd
+}
+
+// parseFilename extracts the OS and architecture from a provider zip
+// filename, e.g. terraform-provider-acme_1.0.0_linux_amd64.zip.
+func parseFilename(name, version, filename string) (string, string, error) {
+	prefix := fmt.Sprintf("terraform-provider-%s_%s_", name, strings.TrimPrefix(version, "v"))
+	platform, ok := strings.CutPrefix(filename, prefix)
+	if !ok {
+		return "", "", fmt.Errorf("%s: filename must begin with %s", filename, prefix)
+	}
+	platform, ok = strings.CutSuffix(platform, ".zip")
+	if !ok {
+		return "", "", fmt.Errorf("%s: filename must end with .zip", filename)
+	}
+	goos, goarch, ok := strings.Cut(platform, "_")
+	if !ok || goos == "" || goarch == "" {
+		return "", "", fmt.Errorf("%s: filename must end with <os>_<arch>.zip", filename)
+	}
+	return goos, goarch, nil
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilename(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		goos, goarch, err := parseFilename("acme", "v1.0.0", "terraform-provider-acme_1.0.0_linux_amd64.zip")
+		require.NoError(t, err)
+		assert.Equal(t, "linux", goos)
+		assert.Equal(t, "amd64", goarch)
+	})
+
+	t.Run("wrong provider", func(t *testing.T) {
+		_, _, err := parseFilename("acme", "1.0.0", "terraform-provider-other_1.0.0_linux_amd64.zip")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing arch", func(t *testing.T) {
+		_, _, err := parseFilename("acme", "1.0.0", "terraform-provider-acme_1.0.0_linux.zip")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,291 @@
+package registryprovider
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type pgdb struct {
+	*sql.DB
+}
+
+func (db *pgdb) createProvider(ctx context.Context, provider *Provider) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO registry_providers (
+    provider_id,
+    created_at,
+    name,
+    organization_name
+) VALUES (
+    @id,
+    @created_at,
+    @name,
+    @organization_name
+)
+`, pgx.NamedArgs{
+		"id":                provider.ID,
+		"created_at":        provider.CreatedAt,
+		"name":              provider.Name,
+		"organization_name": provider.Organization,
+	})
+	return err
+}
+
+func (db *pgdb) getProvider(ctx context.Context, org organization.Name, name string) (*Provider, error) {
+	rows := db.Query(ctx, `
+SELECT *
+FROM registry_providers
+WHERE organization_name = $1
+AND   name              = $2
+`, org, name)
+	provider, err := sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Provider])
+	if err != nil {
+		return nil, err
+	}
+	if err := db.addVersions(ctx, provider); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (db *pgdb) getProviderByID(ctx context.Context, id resource.TfeID) (*Provider, error) {
+	rows := db.Query(ctx, `
+SELECT *
+FROM registry_providers
+WHERE provider_id = $1
+`, id)
+	provider, err := sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Provider])
+	if err != nil {
+		return nil, err
+	}
+	if err := db.addVersions(ctx, provider); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (db *pgdb) listProviders(ctx context.Context, org organization.Name) ([]*Provider, error) {
+	rows := db.Query(ctx, `
+SELECT *
+FROM registry_providers
+WHERE organization_name = $1
+ORDER BY name ASC
+`, org)
+	providers, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Provider])
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		if err := db.addVersions(ctx, provider); err != nil {
+			return nil, err
+		}
+	}
+	return providers, nil
+}
+
+func (db *pgdb) deleteProvider(ctx context.Context, id resource.TfeID) error {
+	_, err := db.Exec(ctx, `
+DELETE
+FROM registry_providers
+WHERE provider_id = $1
+`, id)
+	return err
+}
+
+// addVersions populates the provider with its versions and their platforms.
+func (db *pgdb) addVersions(ctx context.Context, provider *Provider) error {
+	rows := db.Query(ctx, `
+SELECT
+    provider_version_id,
+    created_at,
+    provider_id,
+    version,
+    protocols,
+    key_id,
+    ascii_armor,
+    shasums IS NOT NULL AS shasums_uploaded,
+    shasums_signature IS NOT NULL AS shasums_signature_uploaded
+FROM registry_provider_versions
+WHERE provider_id = $1
+`, provider.ID)
+	versions, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Version])
+	if err != nil {
+		return err
+	}
+	rows = db.Query(ctx, `
+SELECT p.provider_version_id, p.os, p.arch, p.filename, p.shasum
+FROM registry_provider_platforms p
+JOIN registry_provider_versions v USING (provider_version_id)
+WHERE v.provider_id = $1
+ORDER BY p.os, p.arch
+`, provider.ID)
+	platforms, err := sql.CollectRows(rows, pgx.RowToStructByName[Platform])
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		for _, p := range platforms {
+			if p.VersionID == v.ID {
+				v.Platforms = append(v.Platforms, p)
+			}
+		}
+	}
+	sortVersions(versions)
+	provider.Versions = versions
+	return nil
+}
+
+func (db *pgdb) createVersion(ctx context.Context, version *Version) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO registry_provider_versions (
+    provider_version_id,
+    created_at,
+    provider_id,
+    version,
+    protocols,
+    key_id,
+    ascii_armor
+) VALUES (
+    @id,
+    @created_at,
+    @provider_id,
+    @version,
+    @protocols,
+    @key_id,
+    @ascii_armor
+)
+`, pgx.NamedArgs{
+		"id":          version.ID,
+		"created_at":  version.CreatedAt,
+		"provider_id": version.ProviderID,
+		"version":     version.Version,
+		"protocols":   version.Protocols,
+		"key_id":      version.KeyID,
+		"ascii_armor": version.ASCIIArmor,
+	})
+	return err
+}
+
+func (db *pgdb) getProviderByVersionID(ctx context.Context, versionID resource.TfeID) (*Provider, error) {
+	providerID, err := db.getVersionProviderID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	return db.getProviderByID(ctx, providerID)
+}
+
+func (db *pgdb) getVersionProviderID(ctx context.Context, versionID resource.TfeID) (resource.TfeID, error) {
+	var providerID resource.TfeID
+	err := db.QueryRow(ctx, `
+SELECT provider_id
+FROM registry_provider_versions
+WHERE provider_version_id = $1
+`, versionID).Scan(&providerID)
+	return providerID, err
+}
+
+func (db *pgdb) getProviderOrganization(ctx context.Context, providerID resource.TfeID) (organization.Name, error) {
+	var org organization.Name
+	err := db.QueryRow(ctx, `
+SELECT organization_name
+FROM registry_providers
+WHERE provider_id = $1
+`, providerID).Scan(&org)
+	return org, err
+}
+
+func (db *pgdb) deleteVersion(ctx context.Context, versionID resource.TfeID) error {
+	_, err := db.Exec(ctx, `
+DELETE
+FROM registry_provider_versions
+WHERE provider_version_id = $1
+`, versionID)
+	return err
+}
+
+func (db *pgdb) uploadShasums(ctx context.Context, versionID resource.TfeID, shasums []byte) error {
+	_, err := db.Exec(ctx, `
+UPDATE registry_provider_versions
+SET shasums = $1
+WHERE provider_version_id = $2
+`, shasums, versionID)
+	return err
+}
+
+func (db *pgdb) uploadShasumsSignature(ctx context.Context, versionID resource.TfeID, sig []byte) error {
+	_, err := db.Exec(ctx, `
+UPDATE registry_provider_versions
+SET shasums_signature = $1
+WHERE provider_version_id = $2
+`, sig, versionID)
+	return err
+}
+
+func (db *pgdb) downloadShasums(ctx context.Context, versionID resource.TfeID) ([]byte, error) {
+	var shasums []byte
+	err := db.QueryRow(ctx, `
+SELECT shasums
+FROM registry_provider_versions
+WHERE provider_version_id = $1
+AND   shasums IS NOT NULL
+`, versionID).Scan(&shasums)
+	return shasums, err
+}
+
+func (db *pgdb) downloadShasumsSignature(ctx context.Context, versionID resource.TfeID) ([]byte, error) {
+	var sig []byte
+	err := db.QueryRow(ctx, `
+SELECT shasums_signature
+FROM registry_provider_versions
+WHERE provider_version_id = $1
+AND   shasums_signature IS NOT NULL
+`, versionID).Scan(&sig)
+	return sig, err
+}
+
+func (db *pgdb) uploadPlatform(ctx context.Context, platform *Platform, zip []byte) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO registry_provider_platforms (
+    provider_version_id,
+    os,
+    arch,
+    filename,
+    shasum,
+    zip
+) VALUES (
+    @version_id,
+    @os,
+    @arch,
+    @filename,
+    @shasum,
+    @zip
+) ON CONFLICT (provider_version_id, os, arch) DO UPDATE
+SET filename = @filename,
+    shasum   = @shasum,
+    zip      = @zip
+`, pgx.NamedArgs{
+		"version_id": platform.VersionID,
+		"os":         platform.OS,
+		"arch":       platform.Arch,
+		"filename":   platform.Filename,
+		"shasum":     platform.Shasum,
+		"zip":        zip,
+	})
+	return err
+}
+
+func (db *pgdb) downloadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string) ([]byte, error) {
+	var zip []byte
+	err := db.QueryRow(ctx, `
+SELECT zip
+FROM registry_provider_platforms
+WHERE provider_version_id = $1
+AND   os                  = $2
+AND   arch                = $3
+`, versionID, os, arch).Scan(&zip)
+	return zip, err
+}
@@ -0,0 +1,230 @@
+// Package registryprovider implements a private registry of terraform
+// providers.
+package registryprovider
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/semver"
+)
+
+var (
+	// ErrInvalidVersion is returned when a provider version is not a valid
+	// semantic version.
+	ErrInvalidVersion = errors.New("provider version must be a valid semantic version")
+	// ErrMissingSigningKey is returned when a provider version is created
+	// without a GPG public key with which to verify its signature.
+	ErrMissingSigningKey = errors.New("provider version requires a GPG key ID and ASCII-armored public key")
+	// ErrMissingShasums is returned when attempting to upload a platform
+	// binary before the provider version's SHA256SUMS file has been uploaded.
+	ErrMissingShasums = errors.New("SHA256SUMS must be uploaded before platform binaries")
+	// ErrShasumMismatch is returned when an uploaded platform binary does not
+	// match its entry in the provider version's SHA256SUMS file.
+	ErrShasumMismatch = errors.New("platform binary checksum does not match SHA256SUMS")
+)
+
+// DefaultProtocols are the plugin protocol versions a provider version
+// supports when none are specified.
+var DefaultProtocols = []string{"5.0"}
+
+type (
+	// Provider is a terraform provider in an organization's private
+	// registry. The organization doubles as the provider's namespace.
+	Provider struct {
+		ID           resource.TfeID    `jsonapi:"primary,registry-providers" db:"provider_id"`
+		CreatedAt    time.Time         `jsonapi:"attribute" json:"created_at" db:"created_at"`
+		Name         string            `jsonapi:"attribute" json:"name" db:"name"`
+		Organization organization.Name `jsonapi:"attribute" json:"organization" db:"organization_name"`
+		Versions     []*Version        `jsonapi:"attribute" json:"versions" db:"-"`
+	}
+
+	// Version is a release of a provider.
+	Version struct {
+		ID         resource.TfeID `jsonapi:"primary,registry-provider-versions" json:"id" db:"provider_version_id"`
+		CreatedAt  time.Time      `jsonapi:"attribute" json:"created_at" db:"created_at"`
+		ProviderID resource.TfeID `jsonapi:"attribute" json:"provider_id" db:"provider_id"`
+		Version    string         `jsonapi:"attribute" json:"version" db:"version"`
+		// Protocols are the plugin protocol versions supported by the
+		// provider version.
+		Protocols []string `jsonapi:"attribute" json:"protocols" db:"protocols"`
+		// KeyID and ASCIIArmor identify the GPG public key that signed the
+		// version's SHA256SUMS file.
+		KeyID      string `jsonapi:"attribute" json:"key_id" db:"key_id"`
+		ASCIIArmor string `jsonapi:"attribute" json:"ascii_armor" db:"ascii_armor"`
+		// ShasumsUploaded is true once the SHA256SUMS file has been uploaded.
+		ShasumsUploaded bool `jsonapi:"attribute" json:"shasums_uploaded" db:"shasums_uploaded"`
+		// ShasumsSignatureUploaded is true once the SHA256SUMS.sig file has
+		// been uploaded.
+		ShasumsSignatureUploaded bool       `jsonapi:"attribute" json:"shasums_signature_uploaded" db:"shasums_signature_uploaded"`
+		Platforms                []Platform `jsonapi:"attribute" json:"platforms" db:"-"`
+	}
+
+	// Platform is a provider version binary built for a specific operating
+	// system and architecture.
+	Platform struct {
+		VersionID resource.TfeID `json:"version_id" db:"provider_version_id"`
+		OS        string         `json:"os" db:"os"`
+		Arch      string         `json:"arch" db:"arch"`
+		Filename  string         `json:"filename" db:"filename"`
+		Shasum    string         `json:"shasum" db:"shasum"`
+	}
+
+	CreateOptions struct {
+		Organization organization.Name `json:"organization"`
+		Name         string            `json:"name"`
+	}
+
+	CreateVersionOptions struct {
+		Version    string   `json:"version"`
+		Protocols  []string `json:"protocols"`
+		KeyID      string   `json:"key_id"`
+		ASCIIArmor string   `json:"ascii_armor"`
+	}
+)
+
+func newProvider(opts CreateOptions) (*Provider, error) {
+	if err := resource.ValidateName(&opts.Name); err != nil {
+		return nil, err
+	}
+	return &Provider{
+		ID:           resource.NewTfeID(resource.RegistryProviderKind),
+		CreatedAt:    internal.CurrentTimestamp(nil),
+		Name:         opts.Name,
+		Organization: opts.Organization,
+	}, nil
+}
+
+func newVersion(providerID resource.TfeID, opts CreateVersionOptions) (*Version, error) {
+	// strip off v prefix if it has one
+	version := strings.TrimPrefix(opts.Version, "v")
+	if !semver.IsValid(version) {
+		return nil, ErrInvalidVersion
+	}
+	if opts.KeyID == "" || opts.ASCIIArmor == "" {
+		return nil, ErrMissingSigningKey
+	}
+	protocols := opts.Protocols
+	if len(protocols) == 0 {
+		protocols = DefaultProtocols
+	}
+	return &Version{
+		ID:         resource.NewTfeID(resource.RegistryProviderVersionKind),
+		CreatedAt:  internal.CurrentTimestamp(nil),
+		ProviderID: providerID,
+		Version:    version,
+		Protocols:  protocols,
+		KeyID:      opts.KeyID,
+		ASCIIArmor: opts.ASCIIArmor,
+	}, nil
+}
+
+func (p *Provider) String() string { return p.Name }
+
+// LogValue implements slog.LogValuer.
+func (p *Provider) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", p.ID.String()),
+		slog.Any("organization", p.Organization),
+		slog.String("name", p.Name),
+	)
+}
+
+// Version retrieves the provider version with the given version number,
+// returning nil if it does not exist.
+func (p *Provider) Version(version string) *Version {
+	version = strings.TrimPrefix(version, "v")
+	for _, v := range p.Versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// Platform retrieves the version's platform for the given OS and
+// architecture, returning nil if it does not exist.
+func (v *Version) Platform(os, arch string) *Platform {
+	for _, p := range v.Platforms {
+		if p.OS == os && p.Arch == arch {
+			return &p
+		}
+	}
+	return nil
+}
+
+// published reports whether the version is ready to be served by the
+// registry, i.e. its checksums are signed and it has at least one platform.
+func (v *Version) published() bool {
+	return v.ShasumsUploaded && v.ShasumsSignatureUploaded && len(v.Platforms) > 0
+}
+
+// LogValue implements slog.LogValuer.
+func (v *Version) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", v.ID.String()),
+		slog.String("provider_id", v.ProviderID.String()),
+		slog.String("version", v.Version),
+	)
+}
+
+// Filename returns the conventional filename of a provider's binary for the
+// given version, OS and architecture.
+func Filename(name, version, os, arch string) string {
+	return fmt.Sprintf("terraform-provider-%s_%s_%s_%s.zip", name, version, os, arch)
+}
+
+// newPlatform constructs a platform for a provider version, checking the
+// binary's checksum against the version's SHA256SUMS file.
+func newPlatform(provider *Provider, version *Version, shasums []byte, os, arch string, zip []byte) (*Platform, error) {
+	filename := Filename(provider.Name, version.Version, os, arch)
+	sum := sha256.Sum256(zip)
+	shasum := hex.EncodeToString(sum[:])
+	expected, ok := parseShasums(shasums)[filename]
+	if !ok {
+		return nil, fmt.Errorf("%w: no entry for %s", ErrShasumMismatch, filename)
+	}
+	if expected != shasum {
+		return nil, fmt.Errorf("%w: %s", ErrShasumMismatch, filename)
+	}
+	return &Platform{
+		VersionID: version.ID,
+		OS:        os,
+		Arch:      arch,
+		Filename:  filename,
+		Shasum:    shasum,
+	}, nil
+}
+
+// parseShasums parses a SHA256SUMS file, returning a map of filename to
+// checksum.
+func parseShasums(shasums []byte) map[string]string {
+	m := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(shasums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		m[fields[1]] = fields[0]
+	}
+	return m
+}
+
+// sortVersions sorts versions in descending order of semantic version.
+func sortVersions(versions []*Version) {
+	slices.SortFunc(versions, func(a, b *Version) int {
+		return semver.Compare(b.Version, a.Version)
+	})
+}
@@ -0,0 +1,80 @@
+package registryprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVersion(t *testing.T) {
+	providerID := testutils.ParseID(t, "prov-123")
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := newVersion(providerID, CreateVersionOptions{
+			Version:    "v1.2.3",
+			KeyID:      "51852D87348FFC4C",
+			ASCIIArmor: "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.2.3", got.Version)
+		assert.Equal(t, DefaultProtocols, got.Protocols)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := newVersion(providerID, CreateVersionOptions{
+			Version:    "latest",
+			KeyID:      "51852D87348FFC4C",
+			ASCIIArmor: "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+		})
+		assert.Equal(t, ErrInvalidVersion, err)
+	})
+
+	t.Run("missing signing key", func(t *testing.T) {
+		_, err := newVersion(providerID, CreateVersionOptions{Version: "1.2.3"})
+		assert.Equal(t, ErrMissingSigningKey, err)
+	})
+}
+
+func TestNewPlatform(t *testing.T) {
+	provider, err := newProvider(CreateOptions{
+		Organization: organization.NewTestName(t),
+		Name:         "acme",
+	})
+	require.NoError(t, err)
+	version, err := newVersion(provider.ID, CreateVersionOptions{
+		Version:    "1.0.0",
+		KeyID:      "51852D87348FFC4C",
+		ASCIIArmor: "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+	})
+	require.NoError(t, err)
+
+	zip := []byte("provider binary")
+	sum := sha256.Sum256(zip)
+	shasum := hex.EncodeToString(sum[:])
+	shasums := fmt.Appendf(nil, "%s  terraform-provider-acme_1.0.0_linux_amd64.zip\n", shasum)
+
+	t.Run("matching checksum", func(t *testing.T) {
+		got, err := newPlatform(provider, version, shasums, "linux", "amd64", zip)
+		require.NoError(t, err)
+
+		assert.Equal(t, "terraform-provider-acme_1.0.0_linux_amd64.zip", got.Filename)
+		assert.Equal(t, shasum, got.Shasum)
+	})
+
+	t.Run("mismatched checksum", func(t *testing.T) {
+		_, err := newPlatform(provider, version, shasums, "linux", "amd64", []byte("tampered"))
+		assert.ErrorIs(t, err, ErrShasumMismatch)
+	})
+
+	t.Run("missing entry", func(t *testing.T) {
+		_, err := newPlatform(provider, version, shasums, "darwin", "arm64", zip)
+		assert.ErrorIs(t, err, ErrShasumMismatch)
+	})
+}
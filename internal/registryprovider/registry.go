@@ -0,0 +1,256 @@
+package registryprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tfeapi"
+)
+
+type Registry struct {
+	Client registryClient
+	Signer tfeapi.Signer
+}
+
+type registryClient interface {
+	GetProvider(ctx context.Context, org organization.Name, name string) (*Provider, error)
+	downloadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string) ([]byte, error)
+	downloadShasums(ctx context.Context, versionID resource.TfeID) ([]byte, error)
+	downloadShasumsSignature(ctx context.Context, versionID resource.TfeID) ([]byte, error)
+}
+
+// AddHandlers registers handlers for the provider registry. It implements
+// the Provider Registry Protocol:
+//
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol
+func (h *Registry) AddHandlers(r *mux.Router) {
+	registry := r.PathPrefix(tfeapi.ProviderV1Prefix).Subrouter()
+	registry.HandleFunc("/{namespace}/{type}/versions", h.listAvailableVersions).Methods("GET")
+	registry.HandleFunc("/{namespace}/{type}/{version}/download/{os}/{arch}", h.findPackage).Methods("GET")
+
+	// Signed paths
+	signed := r.PathPrefix(tfeapi.SignedPrefixWithSignature).Subrouter()
+	signed.HandleFunc("/providers/download/{version_id}/{os}/{arch}.zip", h.downloadPlatform).Methods("GET")
+	signed.HandleFunc("/providers/download/{version_id}/SHA256SUMS", h.downloadShasums).Methods("GET")
+	signed.HandleFunc("/providers/download/{version_id}/SHA256SUMS.sig", h.downloadShasumsSignature).Methods("GET")
+}
+
+type (
+	listAvailableVersionsResponse struct {
+		Versions []listAvailableVersionsVersion `json:"versions"`
+	}
+	listAvailableVersionsVersion struct {
+		Version   string                          `json:"version"`
+		Protocols []string                        `json:"protocols"`
+		Platforms []listAvailableVersionsPlatform `json:"platforms"`
+	}
+	listAvailableVersionsPlatform struct {
+		OS   string `json:"os"`
+		Arch string `json:"arch"`
+	}
+
+	findPackageResponse struct {
+		Protocols           []string           `json:"protocols"`
+		OS                  string             `json:"os"`
+		Arch                string             `json:"arch"`
+		Filename            string             `json:"filename"`
+		DownloadURL         string             `json:"download_url"`
+		ShasumsURL          string             `json:"shasums_url"`
+		ShasumsSignatureURL string             `json:"shasums_signature_url"`
+		Shasum              string             `json:"shasum"`
+		SigningKeys         findPackageSigning `json:"signing_keys"`
+	}
+	findPackageSigning struct {
+		GPGPublicKeys []findPackageGPGKey `json:"gpg_public_keys"`
+	}
+	findPackageGPGKey struct {
+		KeyID      string `json:"key_id"`
+		ASCIIArmor string `json:"ascii_armor"`
+	}
+)
+
+// List Available Versions for a provider. Only versions that have been fully
+// published, i.e. with signed checksums and at least one platform, are
+// listed.
+//
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol#list-available-versions
+func (h *Registry) listAvailableVersions(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Namespace organization.Name `schema:"namespace,required"`
+		Type      string            `schema:"type,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	provider, err := h.Client.GetProvider(r.Context(), params.Namespace, params.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := listAvailableVersionsResponse{
+		Versions: []listAvailableVersionsVersion{},
+	}
+	for _, ver := range provider.Versions {
+		if !ver.published() {
+			continue
+		}
+		version := listAvailableVersionsVersion{
+			Version:   ver.Version,
+			Protocols: ver.Protocols,
+		}
+		for _, platform := range ver.Platforms {
+			version.Platforms = append(version.Platforms, listAvailableVersionsPlatform{
+				OS:   platform.OS,
+				Arch: platform.Arch,
+			})
+		}
+		response.Versions = append(response.Versions, version)
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Find a Provider Package for a specific version and platform. The URLs in
+// the response are signed and expire after an hour.
+//
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol#find-a-provider-package
+func (h *Registry) findPackage(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Namespace organization.Name `schema:"namespace,required"`
+		Type      string            `schema:"type,required"`
+		Version   string            `schema:"version,required"`
+		OS        string            `schema:"os,required"`
+		Arch      string            `schema:"arch,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	provider, err := h.Client.GetProvider(r.Context(), params.Namespace, params.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	version := provider.Version(params.Version)
+	if version == nil || !version.published() {
+		http.Error(w, "version not found", http.StatusNotFound)
+		return
+	}
+	platform := version.Platform(params.OS, params.Arch)
+	if platform == nil {
+		http.Error(w, "platform not found", http.StatusNotFound)
+		return
+	}
+
+	sign := func(path string) (string, error) {
+		signed, err := h.Signer.Sign(path, time.Now().Add(time.Hour))
+		if err != nil {
+			return "", err
+		}
+		return otfhttp.Absolute(r, signed), nil
+	}
+	prefix := fmt.Sprintf("/providers/download/%s", version.ID)
+	downloadURL, err := sign(fmt.Sprintf("%s/%s/%s.zip", prefix, platform.OS, platform.Arch))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	shasumsURL, err := sign(prefix + "/SHA256SUMS")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	shasumsSignatureURL, err := sign(prefix + "/SHA256SUMS.sig")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := findPackageResponse{
+		Protocols:           version.Protocols,
+		OS:                  platform.OS,
+		Arch:                platform.Arch,
+		Filename:            platform.Filename,
+		DownloadURL:         downloadURL,
+		ShasumsURL:          shasumsURL,
+		ShasumsSignatureURL: shasumsSignatureURL,
+		Shasum:              platform.Shasum,
+		SigningKeys: findPackageSigning{
+			GPGPublicKeys: []findPackageGPGKey{
+				{KeyID: version.KeyID, ASCIIArmor: version.ASCIIArmor},
+			},
+		},
+	}
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Registry) downloadPlatform(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		VersionID resource.TfeID `schema:"version_id,required"`
+		OS        string         `schema:"os,required"`
+		Arch      string         `schema:"arch,required"`
+	}
+	if err := decode.Route(&params, r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	zip, err := h.Client.downloadPlatform(r.Context(), params.VersionID, params.OS, params.Arch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/zip")
+	w.Write(zip)
+}
+
+func (h *Registry) downloadShasums(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("version_id", r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	shasums, err := h.Client.downloadShasums(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write(shasums)
+}
+
+func (h *Registry) downloadShasumsSignature(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("version_id", r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	sig, err := h.Client.downloadShasumsSignature(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Write(sig)
+}
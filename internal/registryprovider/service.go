@@ -0,0 +1,236 @@
+package registryprovider
+
+import (
+	"context"
+
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type (
+	// Alias service to permit embedding it with other services in a struct
+	// without a name clash.
+	RegistryProviderService = Service
+
+	Service struct {
+		logr.Logger
+		*authz.Authorizer
+
+		db *pgdb
+	}
+
+	Options struct {
+		DB         *sql.DB
+		Logger     logr.Logger
+		Authorizer *authz.Authorizer
+	}
+)
+
+func NewService(opts Options) *Service {
+	svc := &Service{
+		Logger:     opts.Logger,
+		Authorizer: opts.Authorizer,
+		db:         &pgdb{opts.DB},
+	}
+	// Provide a way for other components to find the parent organization of a
+	// provider, and the parent provider of a provider version.
+	opts.Authorizer.RegisterParentResolver(resource.RegistryProviderKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			return svc.db.getProviderOrganization(ctx, id.(resource.TfeID))
+		},
+	)
+	opts.Authorizer.RegisterParentResolver(resource.RegistryProviderVersionKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			return svc.db.getVersionProviderID(ctx, id.(resource.TfeID))
+		},
+	)
+	return svc
+}
+
+func (s *Service) CreateProvider(ctx context.Context, opts CreateOptions) (*Provider, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.RegistryProviderKind, opts.Organization)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := newProvider(opts)
+	if err != nil {
+		s.Error(err, "constructing registry provider", "organization", opts.Organization, "subject", subject)
+		return nil, err
+	}
+	if err := s.db.createProvider(ctx, provider); err != nil {
+		s.Error(err, "creating registry provider", "provider", provider, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("created registry provider", "provider", provider, "subject", subject)
+	return provider, nil
+}
+
+func (s *Service) GetProvider(ctx context.Context, org organization.Name, name string) (*Provider, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.RegistryProviderKind, org)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.db.getProvider(ctx, org, name)
+	if err != nil {
+		s.Error(err, "retrieving registry provider", "organization", org, "name", name, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved registry provider", "provider", provider, "subject", subject)
+	return provider, nil
+}
+
+func (s *Service) ListProviders(ctx context.Context, org organization.Name) ([]*Provider, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.RegistryProviderKind, org)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := s.db.listProviders(ctx, org)
+	if err != nil {
+		s.Error(err, "listing registry providers", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed registry providers", "organization", org, "total", len(providers), "subject", subject)
+	return providers, nil
+}
+
+func (s *Service) DeleteProvider(ctx context.Context, id resource.TfeID) (*Provider, error) {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.RegistryProviderKind, id)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.db.getProviderByID(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving registry provider", "id", id, "subject", subject)
+		return nil, err
+	}
+	if err := s.db.deleteProvider(ctx, id); err != nil {
+		s.Error(err, "deleting registry provider", "provider", provider, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("deleted registry provider", "provider", provider, "subject", subject)
+	return provider, nil
+}
+
+// CreateVersion creates a provider version. The version's SHA256SUMS file, its
+// signature, and its platform binaries are then uploaded separately.
+func (s *Service) CreateVersion(ctx context.Context, providerID resource.TfeID, opts CreateVersionOptions) (*Version, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.RegistryProviderVersionKind, providerID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := newVersion(providerID, opts)
+	if err != nil {
+		s.Error(err, "constructing registry provider version", "provider_id", providerID, "subject", subject)
+		return nil, err
+	}
+	if err := s.db.createVersion(ctx, version); err != nil {
+		s.Error(err, "creating registry provider version", "version", version, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("created registry provider version", "version", version, "subject", subject)
+	return version, nil
+}
+
+func (s *Service) DeleteVersion(ctx context.Context, versionID resource.TfeID) error {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.RegistryProviderVersionKind, versionID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.deleteVersion(ctx, versionID); err != nil {
+		s.Error(err, "deleting registry provider version", "id", versionID, "subject", subject)
+		return err
+	}
+	s.V(0).Info("deleted registry provider version", "id", versionID, "subject", subject)
+	return nil
+}
+
+// UploadShasums uploads a provider version's SHA256SUMS file.
+func (s *Service) UploadShasums(ctx context.Context, versionID resource.TfeID, shasums []byte) error {
+	subject, err := s.Authorize(ctx, resource.Update, resource.RegistryProviderVersionKind, versionID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.uploadShasums(ctx, versionID, shasums); err != nil {
+		s.Error(err, "uploading registry provider shasums", "id", versionID, "subject", subject)
+		return err
+	}
+	s.V(1).Info("uploaded registry provider shasums", "id", versionID, "bytes", len(shasums), "subject", subject)
+	return nil
+}
+
+// UploadShasumsSignature uploads the GPG signature of a provider version's
+// SHA256SUMS file.
+func (s *Service) UploadShasumsSignature(ctx context.Context, versionID resource.TfeID, sig []byte) error {
+	subject, err := s.Authorize(ctx, resource.Update, resource.RegistryProviderVersionKind, versionID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.uploadShasumsSignature(ctx, versionID, sig); err != nil {
+		s.Error(err, "uploading registry provider shasums signature", "id", versionID, "subject", subject)
+		return err
+	}
+	s.V(1).Info("uploaded registry provider shasums signature", "id", versionID, "bytes", len(sig), "subject", subject)
+	return nil
+}
+
+// UploadPlatform uploads a provider version's zipped binary for the given OS
+// and architecture. The version's SHA256SUMS file must have already been
+// uploaded and must contain a matching checksum for the binary.
+func (s *Service) UploadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string, zip []byte) (*Platform, error) {
+	subject, err := s.Authorize(ctx, resource.Update, resource.RegistryProviderVersionKind, versionID)
+	if err != nil {
+		return nil, err
+	}
+	platform, err := func() (*Platform, error) {
+		provider, err := s.db.getProviderByVersionID(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+		var version *Version
+		for _, v := range provider.Versions {
+			if v.ID == versionID {
+				version = v
+			}
+		}
+		if !version.ShasumsUploaded {
+			return nil, ErrMissingShasums
+		}
+		shasums, err := s.db.downloadShasums(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+		platform, err := newPlatform(provider, version, shasums, os, arch, zip)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.uploadPlatform(ctx, platform, zip); err != nil {
+			return nil, err
+		}
+		return platform, nil
+	}()
+	if err != nil {
+		s.Error(err, "uploading registry provider platform", "id", versionID, "os", os, "arch", arch, "subject", subject)
+		return nil, err
+	}
+	s.V(1).Info("uploaded registry provider platform", "id", versionID, "os", os, "arch", arch, "bytes", len(zip), "subject", subject)
+	return platform, nil
+}
+
+// downloadPlatform, downloadShasums and downloadShasumsSignature are
+// deliberately unauthorized: they are only invoked via signed URLs handed out
+// by the registry.
+
+func (s *Service) downloadPlatform(ctx context.Context, versionID resource.TfeID, os, arch string) ([]byte, error) {
+	return s.db.downloadPlatform(ctx, versionID, os, arch)
+}
+
+func (s *Service) downloadShasums(ctx context.Context, versionID resource.TfeID) ([]byte, error) {
+	return s.db.downloadShasums(ctx, versionID)
+}
+
+func (s *Service) downloadShasumsSignature(ctx context.Context, versionID resource.TfeID) ([]byte, error) {
+	return s.db.downloadShasumsSignature(ctx, versionID)
+}
@@ -2,9 +2,11 @@ package repohooks
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
 	"github.com/leg100/otf/internal/vcs"
@@ -14,6 +16,8 @@ type db struct {
 	*sql.DB
 
 	urls urlClient
+	// cipher encrypts the secrets of hooks
+	cipher *encryption.Cipher
 }
 
 // getOrCreateHook gets a hook if it exists or creates it if it does not. Should be
@@ -32,7 +36,7 @@ JOIN vcs_providers v USING (vcs_provider_id)
 WHERE repo_path = $1
 AND   w.vcs_provider_id = $2
 `, hook.repoPath, hook.vcsProviderID)
-	result, err := sql.CollectRows(rows, db.scan)
+	result, err := sql.CollectRows(rows, db.scan(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +46,10 @@ AND   w.vcs_provider_id = $2
 
 	// not found; create instead
 
+	secret, err := db.cipher.EncryptString(ctx, hook.secret)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting secret: %w", err)
+	}
 	rows = db.Query(ctx, `
 WITH inserted AS (
     INSERT INTO repohooks (
@@ -72,10 +80,10 @@ JOIN vcs_providers v USING (vcs_provider_id)
 		hook.id,
 		hook.cloudID,
 		hook.vcsProviderID,
-		hook.secret,
+		secret,
 		hook.repoPath,
 	)
-	return sql.CollectOneRow(rows, db.scan)
+	return sql.CollectOneRow(rows, db.scan(ctx))
 }
 
 func (db *db) getHookByID(ctx context.Context, id uuid.UUID) (*hook, error) {
@@ -91,7 +99,7 @@ FROM repohooks w
 JOIN vcs_providers v USING (vcs_provider_id)
 WHERE w.repohook_id = $1
 `, id)
-	return sql.CollectOneRow(rows, db.scan)
+	return sql.CollectOneRow(rows, db.scan(ctx))
 }
 
 func (db *db) listHooks(ctx context.Context) ([]*hook, error) {
@@ -106,7 +114,7 @@ SELECT
 FROM repohooks w
 JOIN vcs_providers v USING (vcs_provider_id)
 `)
-	return sql.CollectRows(rows, db.scan)
+	return sql.CollectRows(rows, db.scan(ctx))
 }
 
 func (db *db) listUnreferencedRepohooks(ctx context.Context) ([]*hook, error) {
@@ -125,7 +133,7 @@ WHERE NOT EXISTS (
     WHERE rc.vcs_provider_id = w.vcs_provider_id
     AND   rc.repo_path = w.repo_path
 )`)
-	return sql.CollectRows(rows, db.scan)
+	return sql.CollectRows(rows, db.scan(ctx))
 }
 
 func (db *db) updateHookCloudID(ctx context.Context, id uuid.UUID, cloudID string) error {
@@ -161,20 +169,27 @@ type hookModel struct {
 	VCSKind       vcs.KindID     `db:"vcs_kind"`
 }
 
-// fromRow creates a hook from a database row
-func (db *db) scan(row pgx.CollectableRow) (*hook, error) {
-	model, err := pgx.RowToStructByName[hookModel](row)
-	if err != nil {
-		return nil, err
-	}
-	opts := newRepohookOptions{
-		id:            &model.RepohookID,
-		vcsProviderID: model.VCSProviderID,
-		secret:        &model.Secret,
-		repoPath:      model.RepoPath,
-		vcsKindID:     model.VCSKind,
-		urls:          db.urls,
-		cloudID:       model.VCSID,
+// scan returns a function that creates a hook from a database row, decrypting
+// its secret.
+func (db *db) scan(ctx context.Context) pgx.RowToFunc[*hook] {
+	return func(row pgx.CollectableRow) (*hook, error) {
+		model, err := pgx.RowToStructByName[hookModel](row)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := db.cipher.DecryptString(ctx, model.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting secret of hook %s: %w", model.RepohookID, err)
+		}
+		opts := newRepohookOptions{
+			id:            &model.RepohookID,
+			vcsProviderID: model.VCSProviderID,
+			secret:        &secret,
+			repoPath:      model.RepoPath,
+			vcsKindID:     model.VCSKind,
+			urls:          db.urls,
+			cloudID:       model.VCSID,
+		}
+		return newRepohook(opts)
 	}
-	return newRepohook(opts)
 }
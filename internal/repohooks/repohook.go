@@ -15,6 +15,7 @@ import (
 var defaultEvents = []vcs.EventType{
 	vcs.EventTypePush,
 	vcs.EventTypePull,
+	vcs.EventTypeComment,
 }
 
 type (
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
@@ -33,6 +34,8 @@ type (
 		Client         serviceClient
 		URLs           urlClient
 		DB             *sql.DB
+		// Cipher encrypts the secrets of hooks.
+		Cipher *encryption.Cipher
 	}
 
 	serviceClient interface {
@@ -50,7 +53,7 @@ type (
 )
 
 func NewService(opts Options) *Service {
-	db := &db{opts.DB, opts.URLs}
+	db := &db{DB: opts.DB, urls: opts.URLs, cipher: opts.Cipher}
 	svc := &Service{
 		Logger: opts.Logger,
 		client: opts.Client,
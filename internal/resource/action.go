@@ -1,5 +1,10 @@
 package resource
 
+import (
+	"fmt"
+	"slices"
+)
+
 // Action is a verb that describes the thing being done to a resource.
 type Action string
 
@@ -34,4 +39,45 @@ const (
 	EnqueuePlan     Action = "enqueue-plan"
 	Rollback        Action = "rollback"
 	Tail            Action = "tail"
+	Override        Action = "override"
+	Revoke          Action = "revoke"
 )
+
+// actions is the standard set of actions.
+var actions = []Action{
+	Get,
+	List,
+	Create,
+	New,
+	Edit,
+	Update,
+	Delete,
+	Watch,
+	Upload,
+	Download,
+	Apply,
+	Cancel,
+	ForceCancel,
+	Discard,
+	Retry,
+	Lock,
+	ForceUnlock,
+	Unlock,
+	Add,
+	Remove,
+	SetPermission,
+	UnsetPermission,
+	EnqueuePlan,
+	Rollback,
+	Tail,
+	Override,
+	Revoke,
+}
+
+// ParseAction parses a string into one of the standard set of actions.
+func ParseAction(s string) (Action, error) {
+	if !slices.Contains(actions, Action(s)) {
+		return "", fmt.Errorf("unknown action: %s", s)
+	}
+	return Action(s), nil
+}
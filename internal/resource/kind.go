@@ -1,5 +1,10 @@
 package resource
 
+import (
+	"fmt"
+	"slices"
+)
+
 type Kind string
 
 func (k Kind) String() string {
@@ -39,8 +44,77 @@ const (
 	TagKind                       Kind = "tag"
 	EntitlementKind               Kind = "ent"
 	LockFileKind                  Kind = "lock"
+	PolicySetKind                 Kind = "polset"
+	PolicyKind                    Kind = "pol"
+	PolicyCheckKind               Kind = "polchk"
+	ProjectKind                   Kind = "prj"
+	RegistryProviderKind          Kind = "prov"
+	RegistryProviderVersionKind   Kind = "provver"
+	AuditEventKind                Kind = "audit"
+	CostEstimateKind              Kind = "ce"
+	RunTaskKind                   Kind = "task"
+	WorkspaceRunTaskKind          Kind = "wstask"
+	TaskStageKind                 Kind = "ts"
+	TaskResultKind                Kind = "taskrs"
+	// TokenKind refers to tokens of any kind, e.g. user tokens, team tokens.
+	TokenKind Kind = "token"
+	// EngineKind refers to engine archives hosted by the engine mirror.
+	EngineKind Kind = "engine"
+	// PullRequestKind refers to a pull request holding a workspace lock.
+	PullRequestKind Kind = "pr"
 )
 
+// kinds is the set of kinds of resource.
+var kinds = []Kind{
+	SiteKind,
+	OrganizationKind,
+	WorkspaceKind,
+	RunKind,
+	ConfigVersionKind,
+	IngressAttributesKind,
+	JobKind,
+	ChunkKind,
+	UserKind,
+	TeamKind,
+	ModuleKind,
+	ModuleVersionKind,
+	NotificationConfigurationKind,
+	AgentPoolKind,
+	RunnerKind,
+	StateVersionKind,
+	StateVersionOutputKind,
+	VariableSetKind,
+	VariableKind,
+	VCSProviderKind,
+	OrganizationTokenKind,
+	UserTokenKind,
+	TeamTokenKind,
+	AgentTokenKind,
+	SSHKeyKind,
+	PrivateKeyKind,
+	RunTriggerKind,
+	GithubAppKind,
+	PlanFileKind,
+	TagKind,
+	EntitlementKind,
+	LockFileKind,
+	PolicySetKind,
+	PolicyKind,
+	PolicyCheckKind,
+	ProjectKind,
+	RegistryProviderKind,
+	RegistryProviderVersionKind,
+	AuditEventKind,
+	CostEstimateKind,
+	RunTaskKind,
+	WorkspaceRunTaskKind,
+	TaskStageKind,
+	TaskResultKind,
+	TokenKind,
+	EngineKind,
+	PullRequestKind,
+}
+
 var fullKinds = map[Kind]string{
 	OrganizationKind:              "organization",
 	WorkspaceKind:                 "workspace",
@@ -70,6 +144,19 @@ var fullKinds = map[Kind]string{
 	TagKind:                       "tag",
 	EntitlementKind:               "entitlement",
 	LockFileKind:                  "lock-file",
+	PolicySetKind:                 "policy-set",
+	PolicyKind:                    "policy",
+	PolicyCheckKind:               "policy-check",
+	ProjectKind:                   "project",
+	RegistryProviderKind:          "registry-provider",
+	RegistryProviderVersionKind:   "registry-provider-version",
+	AuditEventKind:                "audit-event",
+	CostEstimateKind:              "cost-estimate",
+	RunTaskKind:                   "run-task",
+	WorkspaceRunTaskKind:          "workspace-run-task",
+	TaskStageKind:                 "task-stage",
+	TaskResultKind:                "task-result",
+	TokenKind:                     "token",
 }
 
 // Full returns the unabbreviated name for the kind.
@@ -79,3 +166,11 @@ func (k Kind) Full() string {
 	}
 	return k.String()
 }
+
+// ParseKind parses a string into a kind of resource.
+func ParseKind(s string) (Kind, error) {
+	if !slices.Contains(kinds, Kind(s)) {
+		return "", fmt.Errorf("unknown kind: %s", s)
+	}
+	return Kind(s), nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 
@@ -29,6 +30,7 @@ type apiClient interface {
 
 	GetRunPlanFile(ctx context.Context, id resource.TfeID, format run.PlanFormat) ([]byte, error)
 	UploadRunPlanFile(ctx context.Context, id resource.TfeID, plan []byte, format run.PlanFormat) error
+	GetPlanDiff(ctx context.Context, id resource.TfeID, opts run.PlanDiffOptions) (*run.PlanDiff, error)
 
 	GetLockFile(ctx context.Context, id resource.TfeID) ([]byte, error)
 	UploadLockFile(ctx context.Context, id resource.TfeID, lockFile []byte) error
@@ -41,6 +43,7 @@ func (a *API) AddHandlers(r *mux.Router) {
 	r.HandleFunc("/runs/{id}", a.get).Methods("GET")
 	r.HandleFunc("/runs/{id}/planfile", a.getPlanFile).Methods("GET")
 	r.HandleFunc("/runs/{id}/planfile", a.uploadPlanFile).Methods("PUT")
+	r.HandleFunc("/runs/{id}/plan-diff", a.getPlanDiff).Methods("GET")
 	r.HandleFunc("/runs/{id}/lockfile", a.getLockFile).Methods("GET")
 	r.HandleFunc("/runs/{id}/lockfile", a.uploadLockFile).Methods("PUT")
 	r.HandleFunc("/runs/{run_id}/logs/{phase}", a.putLogs).Methods("PUT")
@@ -119,6 +122,26 @@ func (a *API) uploadPlanFile(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+func (a *API) getPlanDiff(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	opts := run.PlanDiffOptions{}
+	if err := decode.Query(&opts, r.URL.Query()); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	diff, err := a.Client.GetPlanDiff(r.Context(), id, opts)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
 func (a *API) getLockFile(w http.ResponseWriter, r *http.Request) {
 	id, err := decode.ID("id", r)
 	if err != nil {
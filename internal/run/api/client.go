@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -57,6 +58,23 @@ func (c *Client) UploadRunPlanFile(ctx context.Context, runID resource.TfeID, pl
 	return nil
 }
 
+func (c *Client) GetPlanDiff(ctx context.Context, runID resource.TfeID, opts run.PlanDiffOptions) (*run.PlanDiff, error) {
+	u := fmt.Sprintf("runs/%s/plan-diff", url.QueryEscape(runID.String()))
+	req, err := c.NewRequest("GET", u, &opts)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, err
+	}
+	var diff run.PlanDiff
+	if err := json.Unmarshal(buf.Bytes(), &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
 func (c *Client) GetLockFile(ctx context.Context, runID resource.TfeID) ([]byte, error) {
 	u := fmt.Sprintf("runs/%s/lockfile", url.QueryEscape(runID.String()))
 	req, err := c.NewRequest("GET", u, nil)
@@ -450,6 +450,9 @@ func (a *tfe) toRun(from *run.Run, ctx context.Context) (*run.TFERun, error) {
 		CanCancel:       a.authorizer.CanAccess(ctx, resource.Cancel, resource.RunKind, accessRequest),
 		CanApply:        a.authorizer.CanAccess(ctx, resource.Apply, resource.RunKind, accessRequest),
 	}
+	if from.PolicyChecksEnabled {
+		perms.CanOverridePolicyCheck = a.authorizer.CanAccess(ctx, resource.Override, resource.PolicyCheckKind, from.ID)
+	}
 
 	var timestamps run.TFERunStatusTimestamps
 	for _, rst := range from.StatusTimestamps {
@@ -464,6 +467,14 @@ func (a *tfe) toRun(from *run.Run, ctx context.Context) (*run.TFERun, error) {
 			timestamps.PlannedAt = &rst.Timestamp
 		case runstatus.PlannedAndFinished:
 			timestamps.PlannedAndFinishedAt = &rst.Timestamp
+		case runstatus.CostEstimating:
+			timestamps.CostEstimatingAt = &rst.Timestamp
+		case runstatus.CostEstimated:
+			timestamps.CostEstimatedAt = &rst.Timestamp
+		case runstatus.PolicyChecked:
+			timestamps.PolicyCheckedAt = &rst.Timestamp
+		case runstatus.PolicyOverride:
+			timestamps.PolicySoftFailedAt = &rst.Timestamp
 		case runstatus.ApplyQueued:
 			timestamps.ApplyQueuedAt = &rst.Timestamp
 		case runstatus.Applying:
@@ -525,7 +536,12 @@ func (a *tfe) toRun(from *run.Run, ctx context.Context) (*run.TFERun, error) {
 		to.Variables[i] = run.TFERunVariable(from)
 	}
 	if from.CostEstimationEnabled {
-		to.CostEstimate = &types.CostEstimate{ID: resource.ConvertTfeID(from.ID, "ce")}
+		to.CostEstimate = &types.CostEstimate{ID: resource.ConvertTfeID(from.ID, resource.CostEstimateKind)}
+	}
+	if from.PolicyChecksEnabled {
+		to.PolicyChecks = []*types.PolicyCheck{
+			{ID: resource.ConvertTfeID(from.ID, resource.PolicyCheckKind)},
+		}
 	}
 	//
 	// go-tfe integration tests expect the ForceCancelAvailableAt parameter to
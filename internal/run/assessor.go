@@ -0,0 +1,172 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leg100/otf/internal/configversion/source"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/leg100/otf/internal/workspace/execution"
+)
+
+var (
+	// By default assess each workspace once a day
+	DefaultAssessmentInterval = 24 * time.Hour
+	// By default check for workspaces due an assessment every minute
+	defaultAssessorCheckInterval = time.Minute
+)
+
+type (
+	// Assessor is a daemon that periodically assesses the health of
+	// workspaces that have opted in to health assessments. An assessment is a
+	// refresh-only plan, the result of which determines whether the real
+	// infrastructure has drifted from the workspace's state.
+	Assessor struct {
+		logr.Logger
+
+		// Interval between assessments of a workspace
+		Interval              time.Duration
+		OverrideCheckInterval time.Duration
+		Runs                  assessorRunClient
+		Workspaces            assessorWorkspaceClient
+
+		// lastQueued records when an assessment was last queued for a
+		// workspace, to prevent queuing another before the first has
+		// finished.
+		lastQueued map[resource.TfeID]time.Time
+	}
+
+	assessorRunClient interface {
+		CreateRun(ctx context.Context, workspaceID resource.TfeID, opts CreateOptions) (*Run, error)
+		GetRun(ctx context.Context, runID resource.TfeID) (*Run, error)
+		WatchRuns(ctx context.Context) (<-chan pubsub.Event[*Event], func(), error)
+	}
+
+	assessorWorkspaceClient interface {
+		ListWorkspaces(ctx context.Context, opts workspace.ListOptions) (*resource.Page[*workspace.Workspace], error)
+		SetWorkspaceAssessment(ctx context.Context, workspaceID resource.TfeID, assessment workspace.Assessment) (*workspace.Workspace, error)
+	}
+)
+
+// Start the assessor daemon.
+func (a *Assessor) Start(ctx context.Context) error {
+	if a.Interval == 0 {
+		a.Interval = DefaultAssessmentInterval
+	}
+	interval := defaultAssessorCheckInterval
+	if a.OverrideCheckInterval != 0 {
+		interval = a.OverrideCheckInterval
+	}
+	a.lastQueued = make(map[resource.TfeID]time.Time)
+
+	// Subscribe to run events in order to record the outcome of assessments.
+	sub, unsub, err := a.Runs.WatchRuns(ctx)
+	if err != nil {
+		return err
+	}
+	defer unsub()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := a.queue(ctx); err != nil {
+				return err
+			}
+		case event, ok := <-sub:
+			if !ok {
+				return pubsub.ErrSubscriptionTerminated
+			}
+			if err := a.record(ctx, event.Payload); err != nil {
+				a.Error(err, "recording assessment", "run_id", event.Payload.ID)
+			}
+		}
+	}
+}
+
+// queue an assessment run for each workspace that is due one.
+func (a *Assessor) queue(ctx context.Context) error {
+	workspaces, err := resource.ListAll(func(opts resource.PageOptions) (*resource.Page[*workspace.Workspace], error) {
+		return a.Workspaces.ListWorkspaces(ctx, workspace.ListOptions{PageOptions: opts})
+	})
+	if err != nil {
+		return fmt.Errorf("listing workspaces: %w", err)
+	}
+	for _, ws := range workspaces {
+		if !a.due(ws) {
+			continue
+		}
+		run, err := a.Runs.CreateRun(ctx, ws.ID, CreateOptions{
+			RefreshOnly: new(true),
+			PlanOnly:    new(true),
+			Source:      source.Assessment,
+		})
+		if err != nil {
+			// Don't let one workspace prevent the assessment of others.
+			a.Error(err, "queuing assessment", "workspace_id", ws.ID)
+			continue
+		}
+		a.lastQueued[ws.ID] = time.Now()
+		a.V(1).Info("queued assessment", "workspace_id", ws.ID, "run_id", run.ID)
+	}
+	return nil
+}
+
+// due determines whether a workspace is due an assessment.
+func (a *Assessor) due(ws *workspace.Workspace) bool {
+	if !ws.AssessmentsEnabled {
+		return false
+	}
+	// Runs for local workspaces are not executed by OTF.
+	if ws.Mode.Kind() == execution.LocalKind {
+		return false
+	}
+	// Only assess workspaces that have had at least one run, i.e. ones with
+	// configuration to assess.
+	if ws.LatestRun == nil {
+		return false
+	}
+	if queued, ok := a.lastQueued[ws.ID]; ok && time.Since(queued) < a.Interval {
+		return false
+	}
+	if ws.Assessment != nil && time.Since(ws.Assessment.AssessedAt) < a.Interval {
+		return false
+	}
+	return true
+}
+
+// record the outcome of an assessment run once it has finished.
+func (a *Assessor) record(ctx context.Context, event *Event) error {
+	if event.Source != source.Assessment || !runstatus.Done(event.Status) {
+		return nil
+	}
+	run, err := a.Runs.GetRun(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	assessment := workspace.Assessment{
+		RunID:      run.ID,
+		AssessedAt: time.Now(),
+	}
+	switch {
+	case run.Status != runstatus.PlannedAndFinished:
+		assessment.Status = workspace.AssessmentFailed
+	case run.HasChanges():
+		assessment.Status = workspace.AssessmentDrifted
+	default:
+		assessment.Status = workspace.AssessmentNoDrift
+	}
+	if _, err := a.Workspaces.SetWorkspaceAssessment(ctx, run.WorkspaceID, assessment); err != nil {
+		return err
+	}
+	delete(a.lastQueued, run.WorkspaceID)
+	return nil
+}
@@ -0,0 +1,210 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/configversion/source"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/leg100/otf/internal/workspace/execution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssessor_Queue(t *testing.T) {
+	wsID := resource.NewTfeID(resource.WorkspaceKind)
+	latestRun := &workspace.LatestRun{ID: resource.NewTfeID(resource.RunKind)}
+
+	tests := []struct {
+		name      string
+		workspace *workspace.Workspace
+		// expect assessment to be queued or not
+		queued bool
+	}{
+		{
+			name: "never assessed",
+			workspace: &workspace.Workspace{
+				ID:                 wsID,
+				AssessmentsEnabled: true,
+				LatestRun:          latestRun,
+			},
+			queued: true,
+		},
+		{
+			name: "assessment due",
+			workspace: &workspace.Workspace{
+				ID:                 wsID,
+				AssessmentsEnabled: true,
+				LatestRun:          latestRun,
+				Assessment:         &workspace.Assessment{AssessedAt: time.Now().Add(-2 * time.Hour)},
+			},
+			queued: true,
+		},
+		{
+			name: "assessment not due",
+			workspace: &workspace.Workspace{
+				ID:                 wsID,
+				AssessmentsEnabled: true,
+				LatestRun:          latestRun,
+				Assessment:         &workspace.Assessment{AssessedAt: time.Now().Add(-time.Minute)},
+			},
+			queued: false,
+		},
+		{
+			name: "assessments disabled",
+			workspace: &workspace.Workspace{
+				ID:        wsID,
+				LatestRun: latestRun,
+			},
+			queued: false,
+		},
+		{
+			name: "no runs",
+			workspace: &workspace.Workspace{
+				ID:                 wsID,
+				AssessmentsEnabled: true,
+			},
+			queued: false,
+		},
+		{
+			name: "local execution mode",
+			workspace: &workspace.Workspace{
+				ID:                 wsID,
+				AssessmentsEnabled: true,
+				LatestRun:          latestRun,
+				Mode:               execution.LocalMode(),
+			},
+			queued: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := &fakeAssessorRunClient{}
+			assessor := &Assessor{
+				Interval:   time.Hour,
+				Runs:       runs,
+				Workspaces: &fakeAssessorWorkspaceClient{workspace: tt.workspace},
+				lastQueued: make(map[resource.TfeID]time.Time),
+			}
+			err := assessor.queue(t.Context())
+			require.NoError(t, err)
+			if tt.queued {
+				require.NotNil(t, runs.created)
+				assert.True(t, *runs.created.RefreshOnly)
+				assert.True(t, *runs.created.PlanOnly)
+				assert.Equal(t, source.Assessment, runs.created.Source)
+
+				// Don't queue another assessment until the first has
+				// finished.
+				runs.created = nil
+				err := assessor.queue(t.Context())
+				require.NoError(t, err)
+				assert.Nil(t, runs.created)
+			} else {
+				assert.Nil(t, runs.created)
+			}
+		})
+	}
+}
+
+func TestAssessor_Record(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  *Event
+		run    *Run
+		want   workspace.AssessmentStatus
+		record bool
+	}{
+		{
+			name:  "drifted",
+			event: &Event{Source: source.Assessment, Status: runstatus.PlannedAndFinished},
+			run: &Run{
+				Status: runstatus.PlannedAndFinished,
+				Plan:   Phase{ResourceReport: &Report{Changes: 1}},
+			},
+			want:   workspace.AssessmentDrifted,
+			record: true,
+		},
+		{
+			name:  "no drift",
+			event: &Event{Source: source.Assessment, Status: runstatus.PlannedAndFinished},
+			run: &Run{
+				Status: runstatus.PlannedAndFinished,
+				Plan:   Phase{ResourceReport: &Report{}},
+			},
+			want:   workspace.AssessmentNoDrift,
+			record: true,
+		},
+		{
+			name:   "failed",
+			event:  &Event{Source: source.Assessment, Status: runstatus.Errored},
+			run:    &Run{Status: runstatus.Errored},
+			want:   workspace.AssessmentFailed,
+			record: true,
+		},
+		{
+			name:   "assessment not finished",
+			event:  &Event{Source: source.Assessment, Status: runstatus.Planning},
+			record: false,
+		},
+		{
+			name:   "not an assessment",
+			event:  &Event{Source: source.UI, Status: runstatus.PlannedAndFinished},
+			record: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaces := &fakeAssessorWorkspaceClient{}
+			assessor := &Assessor{
+				Runs:       &fakeAssessorRunClient{run: tt.run},
+				Workspaces: workspaces,
+				lastQueued: make(map[resource.TfeID]time.Time),
+			}
+			err := assessor.record(t.Context(), tt.event)
+			require.NoError(t, err)
+			if tt.record {
+				require.NotNil(t, workspaces.assessment)
+				assert.Equal(t, tt.want, workspaces.assessment.Status)
+			} else {
+				assert.Nil(t, workspaces.assessment)
+			}
+		})
+	}
+}
+
+type fakeAssessorRunClient struct {
+	run     *Run
+	created *CreateOptions
+}
+
+func (f *fakeAssessorRunClient) CreateRun(ctx context.Context, workspaceID resource.TfeID, opts CreateOptions) (*Run, error) {
+	f.created = &opts
+	return &Run{ID: resource.NewTfeID(resource.RunKind), WorkspaceID: workspaceID}, nil
+}
+
+func (f *fakeAssessorRunClient) GetRun(ctx context.Context, runID resource.TfeID) (*Run, error) {
+	return f.run, nil
+}
+
+func (f *fakeAssessorRunClient) WatchRuns(ctx context.Context) (<-chan pubsub.Event[*Event], func(), error) {
+	return nil, func() {}, nil
+}
+
+type fakeAssessorWorkspaceClient struct {
+	workspace  *workspace.Workspace
+	assessment *workspace.Assessment
+}
+
+func (f *fakeAssessorWorkspaceClient) ListWorkspaces(ctx context.Context, opts workspace.ListOptions) (*resource.Page[*workspace.Workspace], error) {
+	return resource.NewPage([]*workspace.Workspace{f.workspace}, opts.PageOptions, nil), nil
+}
+
+func (f *fakeAssessorWorkspaceClient) SetWorkspaceAssessment(ctx context.Context, workspaceID resource.TfeID, assessment workspace.Assessment) (*workspace.Workspace, error) {
+	f.assessment = &assessment
+	return nil, nil
+}
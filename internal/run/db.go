@@ -9,6 +9,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/engine"
@@ -17,6 +18,7 @@ import (
 	"github.com/leg100/otf/internal/runstatus"
 	"github.com/leg100/otf/internal/sql"
 	"github.com/leg100/otf/internal/user"
+	"github.com/leg100/otf/internal/vcs"
 	"github.com/leg100/otf/internal/workspace/execution"
 	"github.com/pkg/errors"
 )
@@ -26,6 +28,8 @@ const Table sql.Table = "runs"
 // pgdb is a database of runs on postgres
 type pgdb struct {
 	*sql.DB // provides access to generated SQL queries
+
+	blobs blob.Store
 }
 
 // CreateRun persists a Run to the DB.
@@ -51,7 +55,8 @@ INSERT INTO runs (
     engine,
     engine_version,
     allow_empty_apply,
-	triggering_run_id
+	triggering_run_id,
+    structured_output
 ) VALUES (
     $1,
     $2,
@@ -71,7 +76,8 @@ INSERT INTO runs (
     $16,
     $17,
 	$18,
-	$19
+	$19,
+    $20
 )`,
 
 			run.ID,
@@ -93,6 +99,7 @@ INSERT INTO runs (
 			run.EngineVersion,
 			run.AllowEmptyApply,
 			run.TriggeringRunID,
+			run.StructuredOutput,
 		)
 		for _, v := range run.Variables {
 			_, err := db.Exec(ctx, `INSERT INTO run_variables ( run_id, key, value) VALUES ( $1, $2, $3)`,
@@ -157,6 +164,17 @@ SELECT
     workspaces.organization_name,
     workspaces.execution_kind,
     organizations.cost_estimation_enabled,
+    EXISTS (
+        SELECT FROM policy_sets ps
+        WHERE ps.organization_name = workspaces.organization_name
+    ) AS policy_checks_enabled,
+    ARRAY(
+        SELECT DISTINCT wrt.stage
+        FROM workspace_run_tasks wrt
+        JOIN run_tasks rt USING (run_task_id)
+        WHERE wrt.workspace_id = runs.workspace_id
+        AND rt.enabled
+    ) AS task_stages,
     rst.run_status_timestamps,
     pst.plan_status_timestamps,
     ast.apply_status_timestamps,
@@ -386,6 +404,17 @@ SELECT
     workspaces.organization_name,
     workspaces.execution_kind,
     organizations.cost_estimation_enabled,
+    EXISTS (
+        SELECT FROM policy_sets ps
+        WHERE ps.organization_name = workspaces.organization_name
+    ) AS policy_checks_enabled,
+    ARRAY(
+        SELECT DISTINCT wrt.stage
+        FROM workspace_run_tasks wrt
+        JOIN run_tasks rt USING (run_task_id)
+        WHERE wrt.workspace_id = runs.workspace_id
+        AND rt.enabled
+    ) AS task_stages,
     rst.run_status_timestamps,
     pst.plan_status_timestamps,
     ast.apply_status_timestamps,
@@ -519,6 +548,17 @@ SELECT
     workspaces.organization_name,
     workspaces.execution_kind,
     organizations.cost_estimation_enabled,
+    EXISTS (
+        SELECT FROM policy_sets ps
+        WHERE ps.organization_name = workspaces.organization_name
+    ) AS policy_checks_enabled,
+    ARRAY(
+        SELECT DISTINCT wrt.stage
+        FROM workspace_run_tasks wrt
+        JOIN run_tasks rt USING (run_task_id)
+        WHERE wrt.workspace_id = runs.workspace_id
+        AND rt.enabled
+    ) AS task_stages,
     rst.run_status_timestamps,
     pst.plan_status_timestamps,
     ast.apply_status_timestamps,
@@ -580,15 +620,11 @@ WHERE runs.triggering_run_id = $1
 	return sql.CollectRows(rows, pgx.RowTo[resource.TfeID])
 }
 
-// SetPlanFile writes a plan file to the db
+// SetPlanFile writes a plan file to the blob store
 func (db *pgdb) SetPlanFile(ctx context.Context, runID resource.TfeID, file []byte, format PlanFormat) error {
 	switch format {
-	case PlanFormatBinary:
-		_, err := db.Exec(ctx, `UPDATE plans SET plan_bin = $1 WHERE run_id = $2`, file, runID)
-		return err
-	case PlanFormatJSON:
-		_, err := db.Exec(ctx, `UPDATE plans SET plan_json = $1 WHERE run_id = $2`, file, runID)
-		return err
+	case PlanFormatBinary, PlanFormatJSON:
+		return db.blobs.Put(ctx, blob.PlanFileKey(runID, string(format)), file)
 	default:
 		return fmt.Errorf("unknown plan format: %s", string(format))
 	}
@@ -596,16 +632,12 @@ func (db *pgdb) SetPlanFile(ctx context.Context, runID resource.TfeID, file []by
 
 // GetPlanFile retrieves a plan file for the run
 func (db *pgdb) GetPlanFile(ctx context.Context, runID resource.TfeID, format PlanFormat) ([]byte, error) {
-	var row pgx.Rows
 	switch format {
-	case PlanFormatBinary:
-		row = db.Query(ctx, `SELECT plan_bin FROM plans WHERE run_id = $1`, runID)
-	case PlanFormatJSON:
-		row = db.Query(ctx, `SELECT plan_json FROM plans WHERE run_id = $1`, runID)
+	case PlanFormatBinary, PlanFormatJSON:
+		return db.blobs.Get(ctx, blob.PlanFileKey(runID, string(format)))
 	default:
 		return nil, fmt.Errorf("unknown plan format: %s", string(format))
 	}
-	return sql.CollectOneType[[]byte](row)
 }
 
 // GetLockFile retrieves the lock file for the run
@@ -620,6 +652,40 @@ func (db *pgdb) SetLockFile(ctx context.Context, runID resource.TfeID, lockFile
 	return err
 }
 
+// getPullRequestComment retrieves the ID of the comment posted to a pull
+// request on behalf of a workspace.
+func (db *pgdb) getPullRequestComment(ctx context.Context, workspaceID resource.TfeID, repo vcs.Repo, pull int) (string, error) {
+	row := db.Query(ctx, `
+SELECT comment_id
+FROM pull_request_comments
+WHERE workspace_id = $1
+AND repo_path = $2
+AND pull_request_number = $3
+`, workspaceID, repo.String(), pull)
+	return sql.CollectOneType[string](row)
+}
+
+// setPullRequestComment records the ID of the comment posted to a pull request
+// on behalf of a workspace.
+func (db *pgdb) setPullRequestComment(ctx context.Context, workspaceID resource.TfeID, repo vcs.Repo, pull int, commentID string) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO pull_request_comments (
+    workspace_id,
+    repo_path,
+    pull_request_number,
+    comment_id
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (workspace_id, repo_path, pull_request_number) DO UPDATE
+SET comment_id = EXCLUDED.comment_id
+`, workspaceID, repo.String(), pull, commentID)
+	return err
+}
+
 // DeleteRun deletes a run from the DB
 func (db *pgdb) DeleteRun(ctx context.Context, id resource.TfeID) error {
 	_, err := db.Exec(ctx, `DELETE FROM runs WHERE run_id = $1`, id)
@@ -703,33 +769,23 @@ INSERT INTO chunks (
 		}
 		// Now that the last chunk of logs for a run phase has been inserted into the
 		// chunks table, all the chunks for the run phase can be coalesced into
-		// a single row in the *logs* table, and the chunks can be deleted.
-		_, err = db.Exec(ctx, `
-WITH chunks AS (
-	SELECT
-		string_agg(chunk, '') AS coalesced
-	FROM (
-		SELECT run_id, phase, chunk
-		FROM chunks
-		WHERE run_id = @run_id
-		AND   phase  = @phase
-		ORDER BY _offset
-	) AS chunks
-	GROUP BY run_id, phase
-)
-INSERT INTO logs (run_id, phase, logs)
-SELECT
-    @run_id,
-    @phase,
-	coalesced
+		// a single blob, and the chunks can be deleted.
+		rows := db.Query(ctx, `
+SELECT string_agg(chunk, '' ORDER BY _offset)
 FROM chunks
+WHERE run_id = @run_id
+AND   phase  = @phase
 `, pgx.NamedArgs{
 			"run_id": chunk.RunID,
 			"phase":  chunk.Phase,
 		})
+		logs, err := sql.CollectOneType[[]byte](rows)
 		if err != nil {
 			return err
 		}
+		if err := db.blobs.Put(ctx, blob.LogsKey(chunk.RunID, string(chunk.Phase)), logs); err != nil {
+			return err
+		}
 		_, err = db.Exec(ctx, `
 DELETE
 FROM chunks
@@ -753,23 +809,19 @@ func (db *pgdb) getChunk(ctx context.Context, opts GetChunkOptions) (Chunk, erro
 	if opts.Limit == 0 {
 		opts.Limit = 2_147_483_647
 	}
+	chunk := Chunk{
+		RunID:  opts.RunID,
+		Phase:  opts.Phase,
+		Offset: opts.Offset,
+	}
+	// Logs for an incomplete run phase are found in the chunks table.
 	rows := db.Query(ctx, `
 SELECT
-    substring(string_agg(chunk, '') from @offset + 1 for @limit)
-FROM (
-    SELECT run_id, phase, chunk
-    FROM chunks
-    WHERE run_id = @run_id
-    AND   phase  = @phase
-    ORDER BY _offset
-) AS chunks
-GROUP BY run_id, phase
-UNION
-SELECT
-    substring(logs from @offset + 1 for @limit)
-FROM logs
+    substring(string_agg(chunk, '' ORDER BY _offset) from @offset + 1 for @limit)
+FROM chunks
 WHERE run_id = @run_id
 AND   phase  = @phase
+GROUP BY run_id, phase
 `, pgx.NamedArgs{
 		"run_id": opts.RunID,
 		"phase":  opts.Phase,
@@ -777,26 +829,29 @@ AND   phase  = @phase
 		"offset": opts.Offset,
 	})
 	logs, err := sql.CollectOneRow(rows, pgx.RowTo[[]byte])
+	if err == nil {
+		chunk.Data = logs
+		return chunk, nil
+	} else if !errors.Is(err, internal.ErrResourceNotFound) {
+		return Chunk{}, err
+	}
+	// Otherwise the run phase is complete and its logs are found in the blob
+	// store.
+	logs, err = db.blobs.Get(ctx, blob.LogsKey(opts.RunID, string(opts.Phase)))
 	if err != nil {
 		// Don't consider no logs an error because logs may not have been
 		// uploaded yet.
 		if errors.Is(err, internal.ErrResourceNotFound) {
-			chunk := Chunk{
-				RunID:  opts.RunID,
-				Phase:  opts.Phase,
-				Offset: opts.Offset,
-			}
 			return chunk, nil
 		}
 		return Chunk{}, err
 	}
-	chunk := Chunk{
-		RunID:  opts.RunID,
-		Phase:  opts.Phase,
-		Offset: opts.Offset,
-		Data:   logs,
+	if opts.Offset >= len(logs) {
+		return chunk, nil
 	}
-	return chunk, nil
+	chunk.Offset = 0
+	chunk.Data = logs
+	return chunk.Cut(opts), nil
 }
 
 func (db *pgdb) scan(row pgx.CollectableRow) (*Run, error) {
@@ -833,6 +888,7 @@ func (db *pgdb) scan(row pgx.CollectableRow) (*Run, error) {
 			AllowEmptyApply        bool                                  `db:"allow_empty_apply"`
 			AutoApply              bool                                  `db:"auto_apply"`
 			PlanOnly               bool                                  `db:"plan_only"`
+			StructuredOutput       bool                                  `db:"structured_output"`
 			Source                 source.Source                         `db:"source"`
 			Status                 runstatus.Status                      `db:"status"`
 			PlanStatus             PhaseStatus                           `db:"plan_status"`
@@ -851,6 +907,8 @@ func (db *pgdb) scan(row pgx.CollectableRow) (*Run, error) {
 			IngressAttributes      *configversion.IngressAttributesModel `db:"ingress_attributes"`
 			CreatedBy              *user.Username                        `db:"created_by"`
 			CostEstimationEnabled  bool                                  `db:"cost_estimation_enabled"`
+			PolicyChecksEnabled    bool                                  `db:"policy_checks_enabled"`
+			TaskStages             []TaskStage                           `db:"task_stages"`
 			LockFile               []byte                                `db:"lock_file"`
 			TriggeringRunID        *resource.TfeID                       `db:"triggering_run_id"`
 		}
@@ -875,6 +933,7 @@ func (db *pgdb) scan(row pgx.CollectableRow) (*Run, error) {
 		AllowEmptyApply:        m.AllowEmptyApply,
 		AutoApply:              m.AutoApply,
 		PlanOnly:               m.PlanOnly,
+		StructuredOutput:       m.StructuredOutput,
 		Source:                 m.Source,
 		Status:                 m.Status,
 		WorkspaceID:            m.WorkspaceID,
@@ -898,6 +957,8 @@ func (db *pgdb) scan(row pgx.CollectableRow) (*Run, error) {
 		StatusTimestamps:      make([]StatusTimestamp, len(m.RunStatusTimestamps)),
 		Latest:                m.Latest,
 		CostEstimationEnabled: m.CostEstimationEnabled,
+		PolicyChecksEnabled:   m.PolicyChecksEnabled,
+		TaskStages:            m.TaskStages,
 		CreatedBy:             m.CreatedBy,
 		TriggeringRunID:       m.TriggeringRunID,
 	}
@@ -6,6 +6,7 @@ var (
 	ErrRunDiscardNotAllowed     = errors.New("run was not paused for confirmation or priority; discard not allowed")
 	ErrRunCancelNotAllowed      = errors.New("run was not planning or applying; cancel not allowed")
 	ErrRunForceCancelNotAllowed = errors.New("run was not planning or applying, has not been canceled non-forcefully, or the cool-off period has not yet passed")
+	ErrRunOverrideNotAllowed    = errors.New("run did not fail a mandatory policy check; override not allowed")
 	//
 	ErrPhaseAlreadyStarted = errors.New("phase already started")
 )
@@ -55,6 +55,9 @@ func (f *factory) NewRun(ctx context.Context, workspaceID resource.TfeID, opts C
 	} else {
 		opts.EngineVersion = ws.EngineVersion.String()
 	}
+	if err := org.CheckEngineVersion(opts.EngineVersion); err != nil {
+		return nil, err
+	}
 
 	if creator, _ := user.UserFromContext(ctx); creator != nil {
 		opts.CreatedBy = &creator.Username
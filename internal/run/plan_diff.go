@@ -0,0 +1,364 @@
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	PlanCreate  PlanAction = "create"
+	PlanUpdate  PlanAction = "update"
+	PlanReplace PlanAction = "replace"
+	PlanDelete  PlanAction = "delete"
+	PlanRead    PlanAction = "read"
+)
+
+// identifierRegex matches attribute names that can be written in a path
+// without quoting.
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+type (
+	// PlanDiff lists the changes a plan proposes to make to resources, grouped
+	// by module.
+	PlanDiff struct {
+		// Modules in order, the root module first, followed by child modules
+		// sorted by address.
+		Modules []*ModuleDiff `json:"modules"`
+	}
+
+	// ModuleDiff lists the changes a plan proposes to make to resources in a
+	// module.
+	ModuleDiff struct {
+		// Address of the module. Empty for the root module.
+		Address   string          `json:"address"`
+		Resources []*ResourceDiff `json:"resources"`
+	}
+
+	// ResourceDiff is a proposed change to a resource.
+	ResourceDiff struct {
+		Address       string     `json:"address"`
+		ModuleAddress string     `json:"module_address,omitempty"`
+		Type          string     `json:"type"`
+		Name          string     `json:"name"`
+		ProviderName  string     `json:"provider_name"`
+		Action        PlanAction `json:"action"`
+		// ActionReason is the reason for the action, e.g. the resource is
+		// tainted.
+		ActionReason string `json:"action_reason,omitempty"`
+		// Attributes that are changing.
+		Attributes []AttributeDiff `json:"attributes"`
+		// Unchanged is the number of attributes that are not changing.
+		Unchanged int `json:"unchanged"`
+	}
+
+	// AttributeDiff is a change to a single attribute of a resource. Nested
+	// attributes are flattened into a path, e.g. tags.Name or ingress[0].port.
+	// Values of sensitive attributes are omitted.
+	AttributeDiff struct {
+		Path              string `json:"path"`
+		Before            any    `json:"before,omitempty"`
+		After             any    `json:"after,omitempty"`
+		BeforeSensitive   bool   `json:"before_sensitive,omitempty"`
+		AfterSensitive    bool   `json:"after_sensitive,omitempty"`
+		AfterUnknown      bool   `json:"after_unknown,omitempty"`
+		ForcesReplacement bool   `json:"forces_replacement,omitempty"`
+	}
+
+	// PlanAction is the action a plan proposes to take on a resource.
+	PlanAction string
+
+	// PlanDiffOptions filter the changes in a plan diff.
+	PlanDiffOptions struct {
+		// Actions only includes changes with one of the given actions. If
+		// empty, all changes are included.
+		Actions []PlanAction `schema:"action"`
+	}
+
+	// planResourceChange is the schema of a resource change in the JSON
+	// representation of a plan file:
+	//
+	// https://developer.hashicorp.com/terraform/internals/json-format#change-representation
+	planResourceChange struct {
+		Address       string `json:"address"`
+		ModuleAddress string `json:"module_address"`
+		Type          string `json:"type"`
+		Name          string `json:"name"`
+		ProviderName  string `json:"provider_name"`
+		ActionReason  string `json:"action_reason"`
+		Change        struct {
+			Actions         []ChangeAction `json:"actions"`
+			Before          any            `json:"before"`
+			After           any            `json:"after"`
+			AfterUnknown    any            `json:"after_unknown"`
+			BeforeSensitive any            `json:"before_sensitive"`
+			AfterSensitive  any            `json:"after_sensitive"`
+			ReplacePaths    [][]any        `json:"replace_paths"`
+		} `json:"change"`
+	}
+)
+
+// NewPlanDiff constructs a plan diff from the JSON representation of a plan
+// file. Resources with no proposed change are omitted.
+func NewPlanDiff(planJSON []byte, opts PlanDiffOptions) (*PlanDiff, error) {
+	var plan struct {
+		ResourceChanges []planResourceChange `json:"resource_changes"`
+	}
+	// Decode numbers as json.Number to retain the precision of large
+	// integers.
+	dec := json.NewDecoder(bytes.NewReader(planJSON))
+	dec.UseNumber()
+	if err := dec.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("decoding plan file: %w", err)
+	}
+
+	modules := make(map[string]*ModuleDiff)
+	for _, rc := range plan.ResourceChanges {
+		action, ok := planAction(rc.Change.Actions)
+		if !ok {
+			continue
+		}
+		if len(opts.Actions) > 0 && !slices.Contains(opts.Actions, action) {
+			continue
+		}
+		mod, ok := modules[rc.ModuleAddress]
+		if !ok {
+			mod = &ModuleDiff{Address: rc.ModuleAddress}
+			modules[rc.ModuleAddress] = mod
+		}
+		mod.Resources = append(mod.Resources, newResourceDiff(rc, action))
+	}
+
+	diff := &PlanDiff{Modules: []*ModuleDiff{}}
+	// The root module has an empty address and is therefore sorted first.
+	for _, addr := range slices.Sorted(maps.Keys(modules)) {
+		diff.Modules = append(diff.Modules, modules[addr])
+	}
+	return diff, nil
+}
+
+// Len returns the number of resource changes in the diff.
+func (d *PlanDiff) Len() (n int) {
+	for _, mod := range d.Modules {
+		n += len(mod.Resources)
+	}
+	return
+}
+
+// planAction maps the actions of a resource change to a single action. False
+// is returned if no change is proposed.
+func planAction(actions []ChangeAction) (PlanAction, bool) {
+	switch len(actions) {
+	case 1:
+		switch actions[0] {
+		case "no-op":
+			return "", false
+		default:
+			return PlanAction(actions[0]), true
+		}
+	case 2:
+		// A replacement is either delete-then-create or, if
+		// create_before_destroy is set, create-then-delete.
+		if slices.Contains(actions, CreateAction) && slices.Contains(actions, DeleteAction) {
+			return PlanReplace, true
+		}
+	}
+	return "", false
+}
+
+func newResourceDiff(rc planResourceChange, action PlanAction) *ResourceDiff {
+	res := &ResourceDiff{
+		Address:       rc.Address,
+		ModuleAddress: rc.ModuleAddress,
+		Type:          rc.Type,
+		Name:          rc.Name,
+		ProviderName:  rc.ProviderName,
+		Action:        action,
+		ActionReason:  rc.ActionReason,
+		Attributes:    []AttributeDiff{},
+	}
+	replacePaths := make([]string, len(rc.Change.ReplacePaths))
+	for i, steps := range rc.Change.ReplacePaths {
+		var path string
+		for _, step := range steps {
+			path = appendPathStep(path, step)
+		}
+		replacePaths[i] = path
+	}
+	res.walk("", rc.Change.Before, rc.Change.After, rc.Change.BeforeSensitive, rc.Change.AfterSensitive, rc.Change.AfterUnknown, replacePaths)
+	return res
+}
+
+// walk descends the before and after values of a resource in tandem, adding
+// the attributes that differ. The sensitive and unknown values mirror the
+// structure of the values, with true marking a sensitive or unknown subtree.
+func (r *ResourceDiff) walk(path string, before, after, beforeSensitive, afterSensitive, afterUnknown any, replacePaths []string) {
+	if afterUnknown == true {
+		r.add(path, AttributeDiff{
+			Before:          before,
+			BeforeSensitive: beforeSensitive == true,
+			AfterUnknown:    true,
+		}, replacePaths)
+		return
+	}
+	if beforeSensitive == true || afterSensitive == true {
+		if reflect.DeepEqual(before, after) && (beforeSensitive == true) == (afterSensitive == true) {
+			r.Unchanged++
+			return
+		}
+		r.add(path, AttributeDiff{
+			Before:          before,
+			After:           after,
+			BeforeSensitive: beforeSensitive == true,
+			AfterSensitive:  afterSensitive == true,
+		}, replacePaths)
+		return
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) && (beforeIsMap || afterIsMap) {
+		keys := make(map[string]struct{})
+		for _, m := range []any{beforeMap, afterMap, afterUnknown} {
+			if m, ok := m.(map[string]any); ok {
+				for k := range m {
+					keys[k] = struct{}{}
+				}
+			}
+		}
+		if len(keys) > 0 {
+			for _, k := range slices.Sorted(maps.Keys(keys)) {
+				r.walk(
+					appendPathStep(path, k),
+					mapChild(before, k),
+					mapChild(after, k),
+					mapChild(beforeSensitive, k),
+					mapChild(afterSensitive, k),
+					mapChild(afterUnknown, k),
+					replacePaths,
+				)
+			}
+			return
+		}
+	}
+
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if (beforeIsList || before == nil) && (afterIsList || after == nil) && (beforeIsList || afterIsList) {
+		n := max(len(beforeList), len(afterList))
+		if unknownList, ok := afterUnknown.([]any); ok {
+			n = max(n, len(unknownList))
+		}
+		if n > 0 {
+			for i := range n {
+				r.walk(
+					appendPathStep(path, i),
+					listChild(before, i),
+					listChild(after, i),
+					listChild(beforeSensitive, i),
+					listChild(afterSensitive, i),
+					listChild(afterUnknown, i),
+					replacePaths,
+				)
+			}
+			return
+		}
+	}
+
+	if reflect.DeepEqual(before, after) {
+		if before != nil {
+			r.Unchanged++
+		}
+		return
+	}
+	r.add(path, AttributeDiff{Before: before, After: after}, replacePaths)
+}
+
+func (r *ResourceDiff) add(path string, attr AttributeDiff, replacePaths []string) {
+	attr.Path = path
+	// Never disclose the values of sensitive attributes.
+	if attr.BeforeSensitive {
+		attr.Before = nil
+	}
+	if attr.AfterSensitive {
+		attr.After = nil
+	}
+	for _, rp := range replacePaths {
+		if path == rp || strings.HasPrefix(path, rp+".") || strings.HasPrefix(path, rp+"[") {
+			attr.ForcesReplacement = true
+			break
+		}
+	}
+	r.Attributes = append(r.Attributes, attr)
+}
+
+// FormatBefore formats the value of the attribute before the change.
+func (a AttributeDiff) FormatBefore() string {
+	if a.BeforeSensitive {
+		return "(sensitive value)"
+	}
+	return formatAttributeValue(a.Before)
+}
+
+// FormatAfter formats the value of the attribute after the change.
+func (a AttributeDiff) FormatAfter() string {
+	switch {
+	case a.AfterUnknown:
+		return "(known after apply)"
+	case a.AfterSensitive:
+		return "(sensitive value)"
+	}
+	return formatAttributeValue(a.After)
+}
+
+func formatAttributeValue(v any) string {
+	if v == nil {
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// appendPathStep appends a map key or list index to an attribute path.
+func appendPathStep(path string, step any) string {
+	switch step := step.(type) {
+	case string:
+		switch {
+		case !identifierRegex.MatchString(step):
+			return path + "[" + strconv.Quote(step) + "]"
+		case path == "":
+			return step
+		default:
+			return path + "." + step
+		}
+	case int:
+		return path + "[" + strconv.Itoa(step) + "]"
+	case json.Number:
+		return path + "[" + step.String() + "]"
+	default:
+		return path + "[" + fmt.Sprint(step) + "]"
+	}
+}
+
+func mapChild(v any, key string) any {
+	if m, ok := v.(map[string]any); ok {
+		return m[key]
+	}
+	return nil
+}
+
+func listChild(v any, i int) any {
+	if l, ok := v.([]any); ok && i < len(l) {
+		return l[i]
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package run
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPlanDiff(t *testing.T) {
+	data, err := os.ReadFile("testdata/plan_diff.json")
+	require.NoError(t, err)
+
+	t.Run("all changes", func(t *testing.T) {
+		got, err := NewPlanDiff(data, PlanDiffOptions{})
+		require.NoError(t, err)
+
+		// no-op resource is omitted
+		assert.Equal(t, 4, got.Len())
+		require.Len(t, got.Modules, 3)
+		assert.Equal(t, "", got.Modules[0].Address)
+		assert.Equal(t, "module.dns", got.Modules[1].Address)
+		assert.Equal(t, "module.network", got.Modules[2].Address)
+
+		require.Len(t, got.Modules[0].Resources, 2)
+		web := got.Modules[0].Resources[0]
+		assert.Equal(t, PlanUpdate, web.Action)
+		assert.Equal(t, []AttributeDiff{
+			{Path: "instance_type", Before: "t3.micro", After: "t3.small"},
+			{Path: "public_ip", AfterUnknown: true},
+			{Path: "tags.Env", After: "dev"},
+			{Path: "tags.Name", Before: "web", After: "web-server"},
+			{Path: "user_data", BeforeSensitive: true, AfterSensitive: true},
+		}, web.Attributes)
+		assert.Equal(t, 3, web.Unchanged)
+
+		db := got.Modules[0].Resources[1]
+		assert.Equal(t, PlanReplace, db.Action)
+		assert.Equal(t, "replace_because_cannot_update", db.ActionReason)
+		assert.Equal(t, []AttributeDiff{
+			{Path: "ami", Before: "ami-123", After: "ami-456", ForcesReplacement: true},
+			{Path: "ebs_block_device[0].size", Before: json.Number("8"), After: json.Number("16"), ForcesReplacement: true},
+			{Path: "id", Before: "i-abc", AfterUnknown: true},
+		}, db.Attributes)
+
+		record := got.Modules[1].Resources[0]
+		assert.Equal(t, PlanDelete, record.Action)
+		assert.Equal(t, []AttributeDiff{
+			{Path: "name", Before: "www"},
+			{Path: "records[0]", Before: "10.0.0.1"},
+		}, record.Attributes)
+
+		vpc := got.Modules[2].Resources[0]
+		assert.Equal(t, PlanCreate, vpc.Action)
+		assert.Equal(t, []AttributeDiff{
+			{Path: "cidr_block", After: "10.0.0.0/16"},
+			{Path: "id", AfterUnknown: true},
+		}, vpc.Attributes)
+	})
+
+	t.Run("filter by action", func(t *testing.T) {
+		got, err := NewPlanDiff(data, PlanDiffOptions{Actions: []PlanAction{PlanCreate, PlanDelete}})
+		require.NoError(t, err)
+
+		require.Len(t, got.Modules, 2)
+		assert.Equal(t, "module.dns.aws_route53_record.www", got.Modules[0].Resources[0].Address)
+		assert.Equal(t, "module.network.aws_vpc.main", got.Modules[1].Resources[0].Address)
+	})
+
+	t.Run("sensitive values are not disclosed", func(t *testing.T) {
+		got, err := NewPlanDiff(data, PlanDiffOptions{})
+		require.NoError(t, err)
+
+		encoded, err := json.Marshal(got)
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), "secret-v")
+	})
+
+	t.Run("large numbers retain precision", func(t *testing.T) {
+		got, err := NewPlanDiff([]byte(`{"resource_changes": [{"change": {"actions": ["update"], "before": {"n": 10000000000000001}, "after": {"n": 10000000000000002}}}]}`), PlanDiffOptions{})
+		require.NoError(t, err)
+
+		attr := got.Modules[0].Resources[0].Attributes[0]
+		assert.Equal(t, "10000000000000001", attr.FormatBefore())
+		assert.Equal(t, "10000000000000002", attr.FormatAfter())
+	})
+}
+
+func TestAttributeDiff_Format(t *testing.T) {
+	assert.Equal(t, "null", AttributeDiff{}.FormatBefore())
+	assert.Equal(t, `"web"`, AttributeDiff{After: "web"}.FormatAfter())
+	assert.Equal(t, "(known after apply)", AttributeDiff{AfterUnknown: true}.FormatAfter())
+	assert.Equal(t, "(sensitive value)", AttributeDiff{BeforeSensitive: true}.FormatBefore())
+	assert.Equal(t, "(sensitive value)", AttributeDiff{AfterSensitive: true}.FormatAfter())
+}
@@ -15,6 +15,8 @@ type (
 	PlanFile struct {
 		ResourceChanges []ResourceChange  `json:"resource_changes"`
 		OutputChanges   map[string]Change `json:"output_changes"`
+		// ResourceDrift are changes made to resources outside of terraform.
+		ResourceDrift []ResourceChange `json:"resource_drift"`
 	}
 
 	// PlanFileOptions are options for the plan file API
@@ -65,15 +67,38 @@ func (pf *PlanFile) Summarize() (resource, output Report) {
 	return
 }
 
+// SummarizeDrift provides a tally of the types of changes made to resources
+// outside of terraform.
+func (pf *PlanFile) SummarizeDrift() (drift Report) {
+	for _, rc := range pf.ResourceDrift {
+		for _, action := range rc.Change.Actions {
+			switch action {
+			case CreateAction:
+				drift.Additions++
+			case UpdateAction:
+				drift.Changes++
+			case DeleteAction:
+				drift.Destructions++
+			}
+		}
+	}
+	return
+}
+
 // CompilePlanReports compiles reports of planned changes from a JSON
 // representation of a plan file: one report for planned *resources*, and
-// another for planned *outputs*.
-func CompilePlanReports(planJSON []byte) (resources Report, outputs Report, err error) {
+// another for planned *outputs*. A refresh-only plan only proposes updating
+// the state to match the resources that have drifted, so the report for
+// resources is instead a report of the drift.
+func CompilePlanReports(planJSON []byte, refreshOnly bool) (resources Report, outputs Report, err error) {
 	planFile := PlanFile{}
 	if err := json.Unmarshal(planJSON, &planFile); err != nil {
 		return Report{}, Report{}, err
 	}
 
 	resources, outputs = planFile.Summarize()
+	if refreshOnly {
+		resources = planFile.SummarizeDrift()
+	}
 	return resources, outputs, nil
 }
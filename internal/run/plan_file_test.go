@@ -61,3 +61,17 @@ func TestPlanFile_Changes(t *testing.T) {
 	assert.Equal(t, 0, outputReport.Changes)
 	assert.Equal(t, 0, outputReport.Destructions)
 }
+
+func TestCompilePlanReports_RefreshOnly(t *testing.T) {
+	planJSON := []byte(`{
+		"resource_drift": [
+			{"change": {"actions": ["update"]}},
+			{"change": {"actions": ["delete"]}}
+		]
+	}`)
+
+	resourceReport, _, err := CompilePlanReports(planJSON, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, Report{Changes: 1, Destructions: 1}, resourceReport)
+}
@@ -0,0 +1,381 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leg100/otf/internal/configversion"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/vcs"
+	"github.com/leg100/otf/internal/workspace"
+)
+
+const (
+	planCommand   = "plan"
+	applyCommand  = "apply"
+	unlockCommand = "unlock"
+
+	pullRequestCommandUsage = "Usage: `otf <plan|apply|unlock> [-w <workspace>]`"
+)
+
+// pullRequestCommand is a command issued by commenting on a pull request, e.g.
+// `otf plan -w dev`.
+type pullRequestCommand struct {
+	Name string
+	// Workspace restricts the command to the named workspace. If empty then
+	// the command applies to all workspaces connected to the repository.
+	Workspace string
+}
+
+// parsePullRequestCommand parses the first line of a comment as a pull request
+// command. Nil is returned if the comment is not addressed to otf.
+func parsePullRequestCommand(comment string) (*pullRequestCommand, error) {
+	line, _, _ := strings.Cut(strings.TrimSpace(comment), "\n")
+	args := strings.Fields(line)
+	if len(args) == 0 || args[0] != "otf" {
+		return nil, nil
+	}
+	if len(args) == 1 {
+		return nil, errors.New("missing command")
+	}
+	cmd := pullRequestCommand{Name: args[1]}
+	switch cmd.Name {
+	case planCommand, applyCommand, unlockCommand:
+	default:
+		return nil, fmt.Errorf("unknown command: %s", cmd.Name)
+	}
+	for args = args[2:]; len(args) > 0; args = args[1:] {
+		flag, value, hasValue := strings.Cut(args[0], "=")
+		if flag != "-w" && flag != "--workspace" {
+			return nil, fmt.Errorf("unknown flag: %s", flag)
+		}
+		if !hasValue {
+			if len(args) == 1 {
+				return nil, fmt.Errorf("flag needs an argument: %s", flag)
+			}
+			args = args[1:]
+			value = args[0]
+		}
+		cmd.Workspace = value
+	}
+	return &cmd, nil
+}
+
+// handleCommand handles a comment on a pull request, carrying out the command
+// it contains on each workspace that permits pull request commands.
+func (s *Spawner) handleCommand(ctx context.Context, logger logr.Logger, event vcs.Event) error {
+	cmd, parseErr := parsePullRequestCommand(event.Comment)
+	if cmd == nil && parseErr == nil {
+		logger.V(4).Info("ignoring vcs event: comment is not a command")
+		return nil
+	}
+
+	workspaces, err := s.client.ListConnectedWorkspaces(ctx, event.VCSProviderID, event.Repo)
+	if err != nil {
+		return err
+	}
+	var n int
+	for _, ws := range workspaces {
+		if !ws.Connection.PullRequestCommands {
+			continue
+		}
+		if cmd != nil && cmd.Workspace != "" && cmd.Workspace != ws.Name {
+			continue
+		}
+		workspaces[n] = ws
+		n++
+	}
+	workspaces = workspaces[:n]
+	if len(workspaces) == 0 && (cmd == nil || cmd.Workspace == "") {
+		// Don't respond to commands on repositories where commands have not
+		// been enabled: the comment may well be intended for another otf
+		// installation.
+		logger.V(4).Info("ignoring vcs event: no workspaces permit pull request commands")
+		return nil
+	}
+
+	client, err := s.client.GetVCSProvider(ctx, event.VCSProviderID)
+	if err != nil {
+		return err
+	}
+	// reply posts a comment in response to the command.
+	reply := func(lines ...string) error {
+		_, err := client.CreatePullRequestComment(ctx, vcs.CreatePullRequestCommentOptions{
+			Repo:              event.Repo,
+			PullRequestNumber: event.PullRequestNumber,
+			Body:              strings.Join(lines, "\n\n"),
+		})
+		return err
+	}
+	if parseErr != nil {
+		return reply(fmt.Sprintf("**Invalid command:** %s.", parseErr), pullRequestCommandUsage)
+	}
+	if len(workspaces) == 0 {
+		return reply(fmt.Sprintf("**Unknown workspace:** no workspace named `%s` permits pull request commands.", cmd.Workspace))
+	}
+
+	pr, err := client.GetPullRequest(ctx, event.Repo, event.PullRequestNumber)
+	if err != nil {
+		return fmt.Errorf("retrieving pull request: %w", err)
+	}
+	if denied, err := checkPermitted(ctx, client, event, cmd, pr); err != nil {
+		return err
+	} else if denied != "" {
+		return reply(denied)
+	}
+	var replies []string
+	switch cmd.Name {
+	case unlockCommand:
+		replies, err = s.unlock(ctx, event, workspaces)
+	case planCommand:
+		replies, err = s.plan(ctx, client, event, pr, workspaces, cmd.Workspace != "")
+	case applyCommand:
+		replies, err = s.apply(ctx, event, pr, workspaces, cmd.Workspace != "")
+	}
+	if err != nil {
+		return err
+	}
+	if len(replies) > 0 {
+		return reply(replies...)
+	}
+	return nil
+}
+
+// checkPermitted checks whether the commenter is permitted to carry out the
+// command on the pull request, returning a message explaining why not if they
+// are not permitted. Applying requires the commenter to have write access to
+// the repo, and the pull request to be approved and mergeable. Unlocking, and
+// planning a pull request from a fork, which could have been opened by anyone,
+// also require the commenter to have write access.
+func checkPermitted(ctx context.Context, client *vcs.Provider, event vcs.Event, cmd *pullRequestCommand, pr vcs.PullRequest) (string, error) {
+	if cmd.Name == applyCommand || cmd.Name == unlockCommand || (cmd.Name == planCommand && pr.Fork) {
+		canWrite, err := client.HasWriteAccess(ctx, event.Repo, event.SenderID)
+		if err != nil {
+			return "", fmt.Errorf("checking commenter's access to repo: %w", err)
+		}
+		if !canWrite {
+			return fmt.Sprintf("**Permission denied:** `%s` does not have write access to this repository.", event.SenderUsername), nil
+		}
+	}
+	if cmd.Name == applyCommand {
+		if !pr.Approved {
+			return "**Pull request not approved:** plans can only be applied once the pull request has been approved.", nil
+		}
+		if !pr.Mergeable {
+			return "**Pull request not mergeable:** resolve any conflicts with the base branch before applying plans.", nil
+		}
+	}
+	return "", nil
+}
+
+// unlock releases any lock held by the pull request on each workspace.
+func (s *Spawner) unlock(ctx context.Context, event vcs.Event, workspaces []*workspace.Workspace) ([]string, error) {
+	lock := workspace.PullRequestLock{Repo: event.Repo, Number: event.PullRequestNumber}
+	replies := make([]string, 0, len(workspaces))
+	for _, ws := range workspaces {
+		if _, err := s.client.UnlockPullRequest(ctx, ws.ID, lock); err != nil {
+			return nil, err
+		}
+		replies = append(replies, fmt.Sprintf("Released any lock held by this pull request on workspace `%s`.", ws.Name))
+	}
+	return replies, nil
+}
+
+// plan spawns a plan on each workspace using the head commit of the pull
+// request, locking each workspace to the pull request. Workspaces locked by
+// anything else are skipped. Unless
+// explicit is true, workspaces with trigger patterns are skipped if the pull
+// request doesn't change any matching files.
+func (s *Spawner) plan(ctx context.Context, client *vcs.Provider, event vcs.Event, pr vcs.PullRequest, workspaces []*workspace.Workspace, explicit bool) ([]string, error) {
+	if !explicit {
+		var err error
+		workspaces, err = filterByPullRequestFiles(ctx, client, event, workspaces)
+		if err != nil {
+			return nil, err
+		}
+		if len(workspaces) == 0 {
+			return []string{"No workspaces are affected by the changes in this pull request."}, nil
+		}
+	}
+	tarball, _, err := client.GetRepoTarball(ctx, vcs.GetRepoTarballOptions{
+		Repo: event.Repo,
+		Ref:  &pr.CommitSHA,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieving repo tarball: %w", err)
+	}
+
+	lock := workspace.PullRequestLock{Repo: event.Repo, Number: event.PullRequestNumber}
+	var replies []string
+	for _, ws := range workspaces {
+		if _, err := s.client.LockPullRequest(ctx, ws.ID, lock); errors.Is(err, workspace.ErrWorkspaceAlreadyLocked) {
+			replies = append(replies, lockedMessage(ws))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		// discard any plan previously created by the pull request that is
+		// awaiting an apply, which would otherwise block the new run.
+		if run, err := s.findPlanned(ctx, ws, pr.Number, nil); err != nil {
+			return nil, err
+		} else if run != nil {
+			if err := s.client.DiscardRun(ctx, run.ID); err != nil {
+				return nil, err
+			}
+		}
+		cv, err := s.client.CreateConfigVersion(ctx, ws.ID, configversion.CreateOptions{
+			Speculative: new(false),
+			IngressAttributes: &configversion.IngressAttributes{
+				Branch:            pr.Branch,
+				CommitSHA:         pr.CommitSHA,
+				CommitURL:         pr.CommitURL,
+				Repo:              ws.Connection.Repo,
+				IsPullRequest:     true,
+				PullRequestNumber: pr.Number,
+				PullRequestTitle:  pr.Title,
+				PullRequestURL:    pr.URL,
+				SenderUsername:    event.SenderUsername,
+				SenderAvatarURL:   event.SenderAvatarURL,
+				SenderHTMLURL:     event.SenderHTMLURL,
+			},
+			Source: event.EventHeader.Source,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := s.client.UploadConfig(ctx, cv.ID, tarball); err != nil {
+			return nil, err
+		}
+		_, err = s.client.CreateRun(ctx, ws.ID, CreateOptions{
+			ConfigurationVersionID: &cv.ID,
+			Source:                 event.EventHeader.Source,
+			// the run is only applied upon an apply command
+			AutoApply: new(false),
+			Message:   new(fmt.Sprintf("Triggered by %s commenting on pull request #%d", event.SenderUsername, pr.Number)),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return replies, nil
+}
+
+// apply applies the plans created by plan commands on each workspace. A plan
+// must be of the head commit of the pull request. Unless explicit is true,
+// workspaces without a plan are skipped.
+func (s *Spawner) apply(ctx context.Context, event vcs.Event, pr vcs.PullRequest, workspaces []*workspace.Workspace, explicit bool) ([]string, error) {
+	lock := workspace.PullRequestLock{Repo: event.Repo, Number: event.PullRequestNumber}
+	var (
+		replies []string
+		applied int
+	)
+	for _, ws := range workspaces {
+		planned, err := s.findPlanned(ctx, ws, pr.Number, &pr.CommitSHA)
+		if err != nil {
+			return nil, err
+		}
+		if planned == nil {
+			if explicit {
+				replies = append(replies, fmt.Sprintf("**No plan to apply** for workspace `%s` at commit `%s`. Comment `otf plan -w %s` to create one.", ws.Name, abbreviate(pr.CommitSHA), ws.Name))
+			}
+			continue
+		}
+		if _, err := s.client.LockPullRequest(ctx, ws.ID, lock); errors.Is(err, workspace.ErrWorkspaceAlreadyLocked) {
+			replies = append(replies, lockedMessage(ws))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if err := s.client.ApplyRun(ctx, planned.ID); err != nil {
+			return nil, err
+		}
+		applied++
+	}
+	if !explicit && applied == 0 && len(replies) == 0 {
+		replies = append(replies, fmt.Sprintf("**No plans to apply** at commit `%s`. Comment `otf plan` to create them.", abbreviate(pr.CommitSHA)))
+	}
+	return replies, nil
+}
+
+// findPlanned finds a run created by a plan command on a pull request that is
+// awaiting an apply, i.e. that can be confirmed, optionally filtering by commit
+// SHA. Nil is returned if no such run is found.
+func (s *Spawner) findPlanned(ctx context.Context, ws *workspace.Workspace, pull int, sha *string) (*Run, error) {
+	runs, err := s.client.ListRuns(ctx, ListOptions{
+		WorkspaceID: &ws.ID,
+		Statuses:    confirmableStatuses,
+		CommitSHA:   sha,
+		PlanOnly:    new(false),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs.Items {
+		if run.IngressAttributes != nil && run.IngressAttributes.IsPullRequest && run.IngressAttributes.PullRequestNumber == pull {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+// releasePullRequestLocks releases locks held by a pull request that has been
+// merged or closed.
+func (s *Spawner) releasePullRequestLocks(ctx context.Context, event vcs.Event) error {
+	workspaces, err := s.client.ListConnectedWorkspaces(ctx, event.VCSProviderID, event.Repo)
+	if err != nil {
+		return err
+	}
+	lock := workspace.PullRequestLock{Repo: event.Repo, Number: event.PullRequestNumber}
+	for _, ws := range workspaces {
+		if _, err := s.client.UnlockPullRequest(ctx, ws.ID, lock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterByPullRequestFiles filters out workspaces with trigger patterns that
+// don't match any of the files changed by the pull request.
+func filterByPullRequestFiles(ctx context.Context, client *vcs.Provider, event vcs.Event, workspaces []*workspace.Workspace) ([]*workspace.Workspace, error) {
+	// only perform API call if at least one workspace has file triggers
+	// enabled.
+	var listFiles bool
+	for _, ws := range workspaces {
+		if ws.TriggerPatterns != nil {
+			listFiles = true
+			break
+		}
+	}
+	if !listFiles {
+		return workspaces, nil
+	}
+	paths, err := client.ListPullRequestFiles(ctx, event.Repo, event.PullRequestNumber)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving list of files in pull request from cloud provider: %w", err)
+	}
+	n := 0
+	for _, ws := range workspaces {
+		if ws.TriggerPatterns != nil && !globMatch(paths, ws.TriggerPatterns) {
+			// skip workspace
+			continue
+		}
+		workspaces[n] = ws
+		n++
+	}
+	return workspaces[:n], nil
+}
+
+// lockedMessage explains why a command cannot be carried out on a workspace
+// that is locked by something other than the pull request.
+func lockedMessage(ws *workspace.Workspace) string {
+	switch lock := ws.Lock.(type) {
+	case workspace.PullRequestLock:
+		return fmt.Sprintf("**Workspace `%s` is locked** by pull request #%d. Merge or close that pull request, or comment `otf unlock -w %s` on it, to release the lock.", ws.Name, lock.Number, ws.Name)
+	case nil:
+		return fmt.Sprintf("**Workspace `%s` is locked.** Try again once the lock has been released.", ws.Name)
+	default:
+		return fmt.Sprintf("**Workspace `%s` is locked** by `%s`. Try again once the lock has been released.", ws.Name, lock)
+	}
+}
@@ -0,0 +1,444 @@
+package run
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/configversion"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/leg100/otf/internal/user"
+	"github.com/leg100/otf/internal/vcs"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRequestCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    *pullRequestCommand
+		wantErr string
+	}{
+		{"plan", "otf plan", &pullRequestCommand{Name: "plan"}, ""},
+		{"apply with workspace", "otf apply -w dev", &pullRequestCommand{Name: "apply", Workspace: "dev"}, ""},
+		{"long workspace flag", "otf plan --workspace=dev", &pullRequestCommand{Name: "plan", Workspace: "dev"}, ""},
+		{"only first line is parsed", "  otf unlock\nplease", &pullRequestCommand{Name: "unlock"}, ""},
+		{"not a command", "looks good to me", nil, ""},
+		{"mentions otf", "we should run otf plan", nil, ""},
+		{"missing command", "otf", nil, "missing command"},
+		{"unknown command", "otf destroy", nil, "unknown command: destroy"},
+		{"unknown flag", "otf plan -target=foo", nil, "unknown flag: -target"},
+		{"missing workspace", "otf plan -w", nil, "flag needs an argument: -w"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePullRequestCommand(tt.comment)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpawner_PullRequestCommand(t *testing.T) {
+	newWorkspace := func(name string, commands bool) *workspace.Workspace {
+		return &workspace.Workspace{
+			ID:   resource.NewTfeID(resource.WorkspaceKind),
+			Name: name,
+			Connection: &workspace.Connection{
+				Repo:                vcs.NewMustRepo("leg100", "otf"),
+				PullRequestCommands: commands,
+			},
+		}
+	}
+	newEvent := func(comment string, pull int) vcs.Event {
+		return vcs.Event{
+			EventPayload: vcs.EventPayload{
+				Type:              vcs.EventTypeComment,
+				Action:            vcs.ActionCreated,
+				Repo:              vcs.NewMustRepo("leg100", "otf"),
+				PullRequestNumber: pull,
+				Comment:           comment,
+				SenderUsername:    "alice",
+				SenderID:          "alice",
+			},
+		}
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true), newWorkspace("prod", false))
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf plan", 2))
+		require.NoError(t, err)
+
+		// only workspace with commands enabled should be planned
+		require.Len(t, client.created, 1)
+		assert.False(t, *client.created[0].AutoApply)
+		assert.Equal(t, 2, client.locks[client.workspaces[0].ID].Number)
+		assert.Empty(t, client.comments)
+	})
+
+	t.Run("plan without write access", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.writers = nil
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf plan", 2))
+		require.NoError(t, err)
+
+		// write access is only required to plan pull requests from forks
+		require.Len(t, client.created, 1)
+		assert.Empty(t, client.comments)
+	})
+
+	t.Run("plan fork without write access", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.pull.Fork = true
+		client.writers = nil
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf plan", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.created)
+		assert.Empty(t, client.locks)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Permission denied:** `alice` does not have write access")
+	})
+
+	t.Run("plan workspace locked by another pull request", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.lock(client.workspaces[0], workspace.PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 3})
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf plan -w dev", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.created)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Workspace `dev` is locked** by pull request #3")
+	})
+
+	t.Run("plan workspace locked by user", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.lock(client.workspaces[0], user.MustUsername("bobby"))
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf plan -w dev", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.created)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Workspace `dev` is locked** by `bobby`")
+	})
+
+	t.Run("unlock", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true), newWorkspace("prod", true))
+		client.lock(client.workspaces[0], workspace.PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 2})
+		client.lock(client.workspaces[1], workspace.PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 3})
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf unlock", 2))
+		require.NoError(t, err)
+
+		// only the lock held by the pull request is released
+		assert.Equal(t, map[resource.TfeID]workspace.PullRequestLock{
+			client.workspaces[1].ID: {Repo: vcs.NewMustRepo("leg100", "otf"), Number: 3},
+		}, client.locks)
+	})
+
+	t.Run("unlock without write access", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.lock(client.workspaces[0], workspace.PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 2})
+		client.writers = nil
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf unlock", 2))
+		require.NoError(t, err)
+
+		// the lock is retained
+		assert.Len(t, client.locks, 1)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Permission denied:** `alice` does not have write access")
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		planned := &Run{
+			ID:     testutils.ParseID(t, "run-123"),
+			Status: runstatus.Planned,
+			IngressAttributes: &configversion.IngressAttributes{
+				IsPullRequest:     true,
+				PullRequestNumber: 2,
+			},
+		}
+		client.runs = []*Run{planned}
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf apply", 2))
+		require.NoError(t, err)
+
+		assert.Equal(t, []resource.TfeID{planned.ID}, client.applied)
+		assert.Empty(t, client.comments)
+	})
+
+	t.Run("apply policy checked plan", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		checked := &Run{
+			ID:     testutils.ParseID(t, "run-123"),
+			Status: runstatus.PolicyChecked,
+			IngressAttributes: &configversion.IngressAttributes{
+				IsPullRequest:     true,
+				PullRequestNumber: 2,
+			},
+		}
+		client.runs = []*Run{checked}
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf apply", 2))
+		require.NoError(t, err)
+
+		assert.Equal(t, []resource.TfeID{checked.ID}, client.applied)
+		assert.Empty(t, client.comments)
+	})
+
+	t.Run("apply without write access", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.runs = []*Run{{
+			ID:     testutils.ParseID(t, "run-123"),
+			Status: runstatus.Planned,
+			IngressAttributes: &configversion.IngressAttributes{
+				IsPullRequest:     true,
+				PullRequestNumber: 2,
+			},
+		}}
+		client.writers = nil
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf apply", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.applied)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Permission denied:** `alice` does not have write access")
+	})
+
+	t.Run("apply unapproved pull request", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.runs = []*Run{{
+			ID:     testutils.ParseID(t, "run-123"),
+			Status: runstatus.Planned,
+			IngressAttributes: &configversion.IngressAttributes{
+				IsPullRequest:     true,
+				PullRequestNumber: 2,
+			},
+		}}
+		client.pull.Approved = false
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf apply", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.applied)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Pull request not approved:**")
+	})
+
+	t.Run("apply unmergeable pull request", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.pull.Mergeable = false
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf apply", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.applied)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Pull request not mergeable:**")
+	})
+
+	t.Run("apply without plan", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf apply -w dev", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.applied)
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**No plan to apply** for workspace `dev`")
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf destroy", 2))
+		require.NoError(t, err)
+
+		require.Len(t, client.comments, 1)
+		assert.Contains(t, client.comments[0], "**Invalid command:** unknown command: destroy.")
+	})
+
+	t.Run("ignore commands when not enabled", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", false))
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), newEvent("otf plan", 2))
+		require.NoError(t, err)
+
+		assert.Empty(t, client.created)
+		assert.Empty(t, client.comments)
+	})
+
+	t.Run("release lock when pull request merged", func(t *testing.T) {
+		client := newFakeCommandClient(newWorkspace("dev", true))
+		client.lock(client.workspaces[0], workspace.PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 2})
+		spawner := Spawner{client: client}
+
+		err := spawner.handleWithError(logr.Discard(), vcs.Event{
+			EventPayload: vcs.EventPayload{
+				Type:              vcs.EventTypePull,
+				Action:            vcs.ActionMerged,
+				Repo:              vcs.NewMustRepo("leg100", "otf"),
+				PullRequestNumber: 2,
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, client.locks)
+	})
+}
+
+type fakeCommandClient struct {
+	spawnerClient
+
+	workspaces []*workspace.Workspace
+	runs       []*Run
+	// pull request holding lock on each workspace
+	locks map[resource.TfeID]workspace.PullRequestLock
+	// pull request stub
+	pull vcs.PullRequest
+	// IDs of users with write access to the repo
+	writers []string
+
+	// runs created
+	created []CreateOptions
+	// IDs of runs applied
+	applied []resource.TfeID
+	// comments posted in reply
+	comments []string
+}
+
+func newFakeCommandClient(workspaces ...*workspace.Workspace) *fakeCommandClient {
+	return &fakeCommandClient{
+		workspaces: workspaces,
+		locks:      make(map[resource.TfeID]workspace.PullRequestLock),
+		pull: vcs.PullRequest{
+			CommitSHA: "abc123",
+			Approved:  true,
+			Mergeable: true,
+		},
+		writers: []string{"alice"},
+	}
+}
+
+// lock locks the workspace with the given holder.
+func (f *fakeCommandClient) lock(ws *workspace.Workspace, holder resource.ID) {
+	ws.Lock = holder
+	if lock, ok := holder.(workspace.PullRequestLock); ok {
+		f.locks[ws.ID] = lock
+	}
+}
+
+func (f *fakeCommandClient) ListConnectedWorkspaces(context.Context, resource.TfeID, vcs.Repo) ([]*workspace.Workspace, error) {
+	// return a copy because the caller filters the slice in place
+	return append([]*workspace.Workspace(nil), f.workspaces...), nil
+}
+
+func (f *fakeCommandClient) GetVCSProvider(context.Context, resource.TfeID) (*vcs.Provider, error) {
+	return &vcs.Provider{Client: &fakeCommandCloudClient{client: f}}, nil
+}
+
+func (f *fakeCommandClient) CreateConfigVersion(context.Context, resource.TfeID, configversion.CreateOptions) (*configversion.ConfigurationVersion, error) {
+	return &configversion.ConfigurationVersion{}, nil
+}
+
+func (f *fakeCommandClient) UploadConfig(context.Context, resource.TfeID, []byte) error {
+	return nil
+}
+
+func (f *fakeCommandClient) CreateRun(ctx context.Context, workspaceID resource.TfeID, opts CreateOptions) (*Run, error) {
+	f.created = append(f.created, opts)
+	return &Run{}, nil
+}
+
+func (f *fakeCommandClient) ListRuns(ctx context.Context, opts ListOptions) (*resource.Page[*Run], error) {
+	var runs []*Run
+	for _, run := range f.runs {
+		if slices.Contains(opts.Statuses, run.Status) {
+			runs = append(runs, run)
+		}
+	}
+	return resource.NewPage(runs, resource.PageOptions{}, nil), nil
+}
+
+func (f *fakeCommandClient) ApplyRun(ctx context.Context, runID resource.TfeID) error {
+	f.applied = append(f.applied, runID)
+	return nil
+}
+
+func (f *fakeCommandClient) LockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock workspace.PullRequestLock) (*workspace.Workspace, error) {
+	for _, ws := range f.workspaces {
+		if ws.ID == workspaceID {
+			if err := ws.Enlock(lock); err != nil {
+				return nil, err
+			}
+			f.locks[workspaceID] = lock
+			return ws, nil
+		}
+	}
+	return nil, internal.ErrResourceNotFound
+}
+
+func (f *fakeCommandClient) UnlockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock workspace.PullRequestLock) (*workspace.Workspace, error) {
+	if f.locks[workspaceID] == lock {
+		delete(f.locks, workspaceID)
+	}
+	return nil, nil
+}
+
+type fakeCommandCloudClient struct {
+	vcs.Client
+
+	client *fakeCommandClient
+}
+
+func (f *fakeCommandCloudClient) GetPullRequest(ctx context.Context, repo vcs.Repo, pull int) (vcs.PullRequest, error) {
+	pr := f.client.pull
+	pr.Number = pull
+	return pr, nil
+}
+
+func (f *fakeCommandCloudClient) HasWriteAccess(ctx context.Context, repo vcs.Repo, user string) (bool, error) {
+	return slices.Contains(f.client.writers, user), nil
+}
+
+func (f *fakeCommandCloudClient) GetRepoTarball(context.Context, vcs.GetRepoTarballOptions) ([]byte, string, error) {
+	return nil, "", nil
+}
+
+func (f *fakeCommandCloudClient) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	f.client.comments = append(f.client.comments, opts.Body)
+	return "1", nil
+}
@@ -0,0 +1,163 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leg100/otf/internal/runstatus"
+)
+
+const (
+	// maxCommentResources is the maximum number of resources listed in a
+	// pull request comment.
+	maxCommentResources = 100
+	// maxCommentLength is the maximum length of a pull request comment.
+	// GitHub rejects comments longer than 65536 characters, and the attribute
+	// changes are omitted from comments that would exceed this limit.
+	maxCommentLength = 60000
+)
+
+// pullRequestComment is a comment posted to a pull request summarising the
+// plan of a run triggered by the pull request, or the apply of a run triggered
+// by a pull request command.
+type pullRequestComment struct {
+	Workspace string
+	CommitSHA string
+	RunURL    string
+	Status    runstatus.Status
+	// Report of resource changes, nil if the plan did not finish.
+	Report *Report
+	// ApplyReport of resource changes, nil if the apply did not finish.
+	ApplyReport *Report
+	// Diff of resource changes, nil if unavailable.
+	Diff *PlanDiff
+	// ApplyCommand is the command with which to apply the plan, or empty if
+	// the plan cannot be applied from the pull request.
+	ApplyCommand string
+}
+
+// String renders the comment as markdown.
+func (c pullRequestComment) String() string {
+	var b strings.Builder
+	if c.Status == runstatus.Applied {
+		fmt.Fprintf(&b, "#### OTF apply for workspace `%s`\n\n", c.Workspace)
+	} else {
+		fmt.Fprintf(&b, "#### OTF plan for workspace `%s`\n\n", c.Workspace)
+	}
+	switch {
+	case c.Status == runstatus.Applied && c.ApplyReport != nil:
+		fmt.Fprintf(&b, "**Apply complete: %d added, %d changed, %d destroyed.**\n\n",
+			c.ApplyReport.Additions, c.ApplyReport.Changes, c.ApplyReport.Destructions)
+	case c.Status != runstatus.PlannedAndFinished && c.Status != runstatus.Planned:
+		fmt.Fprintf(&b, "**Run %s.**\n\n", strings.ReplaceAll(c.Status.String(), "_", " "))
+	case c.Report == nil || !c.Report.HasChanges():
+		b.WriteString("**No changes.** Your infrastructure matches the configuration.\n\n")
+	default:
+		fmt.Fprintf(&b, "**Plan: %d to add, %d to change, %d to destroy.**\n\n",
+			c.Report.Additions, c.Report.Changes, c.Report.Destructions)
+	}
+	fmt.Fprintf(&b, "Commit `%s` · [View run](%s)\n", abbreviate(c.CommitSHA), c.RunURL)
+	if c.Status == runstatus.Planned && c.ApplyCommand != "" {
+		fmt.Fprintf(&b, "\nTo apply this plan, comment `%s`.\n", c.ApplyCommand)
+	}
+
+	if c.Diff == nil || c.Diff.Len() == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n| | Resource | Action |\n|---|---|---|\n")
+	var listed int
+	for _, mod := range c.Diff.Modules {
+		for _, res := range mod.Resources {
+			if listed == maxCommentResources {
+				break
+			}
+			fmt.Fprintf(&b, "| `%s` | `%s` | %s |\n",
+				actionSymbol(res.Action),
+				strings.ReplaceAll(res.Address, "|", `\|`),
+				res.Action,
+			)
+			listed++
+		}
+	}
+	if remaining := c.Diff.Len() - listed; remaining > 0 {
+		fmt.Fprintf(&b, "\n_...and %d more; see the run for the full plan._\n", remaining)
+	}
+
+	details := c.attributeChanges()
+	if b.Len()+len(details) > maxCommentLength {
+		b.WriteString("\n_Attribute changes are too large to include; see the run for details._\n")
+		return b.String()
+	}
+	b.WriteString(details)
+	return b.String()
+}
+
+// attributeChanges renders the changes to the attributes of each resource as
+// a collapsible diff.
+func (c pullRequestComment) attributeChanges() string {
+	var b strings.Builder
+	b.WriteString("\n<details><summary>Show attribute changes</summary>\n\n```diff\n")
+	for _, mod := range c.Diff.Modules {
+		for _, res := range mod.Resources {
+			fmt.Fprintf(&b, "# %s will be %s\n", res.Address, actionVerb(res.Action))
+			for _, attr := range res.Attributes {
+				var suffix string
+				if attr.ForcesReplacement {
+					suffix = " # forces replacement"
+				}
+				if res.Action != PlanCreate {
+					fmt.Fprintf(&b, "- %s = %s%s\n", attr.Path, attr.FormatBefore(), suffix)
+				}
+				if res.Action != PlanDelete {
+					fmt.Fprintf(&b, "+ %s = %s%s\n", attr.Path, attr.FormatAfter(), suffix)
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("```\n\n</details>\n")
+	return b.String()
+}
+
+func actionSymbol(action PlanAction) string {
+	switch action {
+	case PlanCreate:
+		return "+"
+	case PlanUpdate:
+		return "~"
+	case PlanReplace:
+		return "-/+"
+	case PlanDelete:
+		return "-"
+	case PlanRead:
+		return "<="
+	default:
+		return "?"
+	}
+}
+
+func actionVerb(action PlanAction) string {
+	switch action {
+	case PlanCreate:
+		return "created"
+	case PlanUpdate:
+		return "updated in-place"
+	case PlanReplace:
+		return "replaced"
+	case PlanDelete:
+		return "destroyed"
+	case PlanRead:
+		return "read"
+	default:
+		return string(action)
+	}
+}
+
+// abbreviate shortens a commit SHA.
+func abbreviate(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,82 @@
+package run
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestComment(t *testing.T) {
+	data, err := os.ReadFile("testdata/plan_diff.json")
+	require.NoError(t, err)
+	diff, err := NewPlanDiff(data, PlanDiffOptions{})
+	require.NoError(t, err)
+
+	t.Run("changes", func(t *testing.T) {
+		got := pullRequestComment{
+			Workspace: "dev",
+			CommitSHA: "c560613b228f5e189520fbab4d6a0d3b8e1d6d8f",
+			RunURL:    "https://otf.ninja/app/runs/run-123",
+			Status:    runstatus.PlannedAndFinished,
+			Report:    &Report{Additions: 1, Changes: 1, Destructions: 2},
+			Diff:      diff,
+		}.String()
+
+		assert.Contains(t, got, "#### OTF plan for workspace `dev`")
+		assert.Contains(t, got, "**Plan: 1 to add, 1 to change, 2 to destroy.**")
+		assert.Contains(t, got, "Commit `c560613` · [View run](https://otf.ninja/app/runs/run-123)")
+		assert.Contains(t, got, "| `~` | `aws_instance.web` | update |")
+		assert.Contains(t, got, "- instance_type = \"t3.micro\"\n+ instance_type = \"t3.small\"\n")
+		assert.Contains(t, got, "+ public_ip = (known after apply)")
+		// sensitive values are never disclosed
+		assert.Contains(t, got, "+ user_data = (sensitive value)")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		got := pullRequestComment{
+			Workspace: "dev",
+			Status:    runstatus.PlannedAndFinished,
+			Report:    &Report{},
+		}.String()
+
+		assert.Contains(t, got, "**No changes.**")
+		assert.NotContains(t, got, "| Resource |")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		got := pullRequestComment{
+			Workspace: "dev",
+			Status:    runstatus.ForceCanceled,
+		}.String()
+
+		assert.Contains(t, got, "**Run force canceled.**")
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		large := &PlanDiff{Modules: []*ModuleDiff{{}}}
+		for range 2 * maxCommentResources {
+			large.Modules[0].Resources = append(large.Modules[0].Resources, &ResourceDiff{
+				Address: "random_pet.pet",
+				Action:  PlanCreate,
+				Attributes: []AttributeDiff{
+					{Path: "prefix", After: strings.Repeat("x", 1000)},
+				},
+			})
+		}
+		got := pullRequestComment{
+			Workspace: "dev",
+			Status:    runstatus.PlannedAndFinished,
+			Report:    &Report{Additions: 2 * maxCommentResources},
+			Diff:      large,
+		}.String()
+
+		assert.Equal(t, maxCommentResources, strings.Count(got, "| `random_pet.pet` |"))
+		assert.Contains(t, got, "...and 100 more")
+		assert.Contains(t, got, "Attribute changes are too large to include")
+		assert.Less(t, len(got), maxCommentLength)
+	})
+}
@@ -2,15 +2,18 @@ package run
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/path"
 	"github.com/leg100/otf/internal/pubsub"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/runstatus"
-	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/sql"
 	"github.com/leg100/otf/internal/vcs"
 	"github.com/leg100/otf/internal/workspace"
 )
@@ -26,6 +29,7 @@ type (
 		vcs        reporterVCSClient
 		runs       reporterRunClient
 		urls       reporterURLClient
+		comments   reporterCommentStore
 
 		// cache most recently set status for each incomplete run to ensure the
 		// same status is not set more than once on an upstream VCS provider.
@@ -53,6 +57,14 @@ type (
 	reporterRunClient interface {
 		WatchRuns(context.Context) (<-chan pubsub.Event[*Event], func(), error)
 		GetRun(context.Context, resource.TfeID) (*Run, error)
+		GetPlanDiff(context.Context, resource.TfeID, PlanDiffOptions) (*PlanDiff, error)
+	}
+
+	// reporterCommentStore persists the IDs of comments posted to pull
+	// requests, permitting them to be updated in place.
+	reporterCommentStore interface {
+		getPullRequestComment(ctx context.Context, workspaceID resource.TfeID, repo vcs.Repo, pull int) (string, error)
+		setPullRequestComment(ctx context.Context, workspaceID resource.TfeID, repo vcs.Repo, pull int, commentID string) error
 	}
 
 	reporterURLClient interface {
@@ -67,6 +79,7 @@ func NewReporter(
 	runs reporterRunClient,
 	configs reporterConfigClient,
 	urls reporterURLClient,
+	db *sql.DB,
 ) *Reporter {
 	return &Reporter{
 		Logger:     logger.WithValues("component", "reporter"),
@@ -75,6 +88,7 @@ func NewReporter(
 		runs:       runs,
 		configs:    configs,
 		urls:       urls,
+		comments:   &pgdb{DB: db},
 		cache:      make(map[resource.TfeID]vcs.Status),
 	}
 }
@@ -140,8 +154,11 @@ func (r *Reporter) handleRun(ctx context.Context, event *Event) error {
 		description string
 	)
 	switch event.Status {
-	case runstatus.Pending, runstatus.PlanQueued, runstatus.ApplyQueued, runstatus.Planning, runstatus.Applying, runstatus.Planned, runstatus.Confirmed:
+	case runstatus.Pending, runstatus.PlanQueued, runstatus.ApplyQueued, runstatus.Planning, runstatus.Applying, runstatus.Planned, runstatus.Confirmed, runstatus.CostEstimating, runstatus.CostEstimated, runstatus.PolicyChecking, runstatus.PolicyChecked, runstatus.PrePlanRunning, runstatus.PrePlanCompleted, runstatus.PostPlanRunning, runstatus.PostPlanCompleted, runstatus.PreApplyRunning, runstatus.PreApplyCompleted:
 		status = vcs.PendingStatus
+	case runstatus.PolicyOverride:
+		status = vcs.PendingStatus
+		description = "policy override required"
 	case runstatus.PlannedAndFinished:
 		status = vcs.SuccessStatus
 		if run.Plan.ResourceReport != nil {
@@ -159,6 +176,27 @@ func (r *Reporter) handleRun(ctx context.Context, event *Event) error {
 		return fmt.Errorf("unknown run status: %s", event.Status)
 	}
 
+	// Post a comment to the pull request that triggered the run. Failing to
+	// post the comment is not fatal. This is performed before checking the
+	// status cache because a run awaiting an apply reports the same status as
+	// a run that is still planning.
+	switch {
+	case !cv.IngressAttributes.IsPullRequest:
+	case !run.PlanOnly:
+		// Run triggered by a pull request command: post a comment once the
+		// plan is awaiting an apply and again when the run has finished.
+		if run.Status == runstatus.Planned || (run.Done() && run.Status != runstatus.Discarded) {
+			if err := r.comment(ctx, client, ws, cv, run, true); err != nil {
+				r.Error(err, "posting pull request comment", "run_id", run.ID, "pull_request", cv.IngressAttributes.PullRequestNumber)
+			}
+		}
+	case run.Done() && ws.Connection.PullRequestComments:
+		// Speculative run: summarise the plan once the run has finished.
+		if err := r.comment(ctx, client, ws, cv, run, false); err != nil {
+			r.Error(err, "posting pull request comment", "run_id", run.ID, "pull_request", cv.IngressAttributes.PullRequestNumber)
+		}
+	}
+
 	// Check status cache. If there is a hit for the same run and status then
 	// skip setting the status again.
 	if lastStatus, ok := r.cache[event.ID]; ok && lastStatus == status {
@@ -192,3 +230,73 @@ func (r *Reporter) handleRun(ctx context.Context, event *Event) error {
 
 	return nil
 }
+
+// comment posts a comment summarising the plan of a run to the pull request
+// that triggered it. If a comment has already been posted to the pull request
+// on behalf of the workspace then it is updated in place, unless the run was
+// triggered by a pull request command, in which case a new comment is always
+// posted in reply.
+func (r *Reporter) comment(ctx context.Context, client *vcs.Provider, ws *workspace.Workspace, cv *configversion.ConfigurationVersion, run *Run, command bool) error {
+	var (
+		repo = cv.IngressAttributes.Repo
+		pull = cv.IngressAttributes.PullRequestNumber
+	)
+	comment := pullRequestComment{
+		Workspace:   ws.Name,
+		CommitSHA:   cv.IngressAttributes.CommitSHA,
+		RunURL:      r.urls.URL(path.Get(run.ID)),
+		Status:      run.Status,
+		Report:      run.Plan.ResourceReport,
+		ApplyReport: run.Apply.ResourceReport,
+	}
+	planned := run.Status == runstatus.PlannedAndFinished || run.Status == runstatus.Planned
+	if planned && run.Plan.ResourceReport != nil && run.Plan.ResourceReport.HasChanges() {
+		diff, err := r.runs.GetPlanDiff(ctx, run.ID, PlanDiffOptions{})
+		if err != nil {
+			// The comment is still useful without the resource changes.
+			r.Error(err, "retrieving plan diff for pull request comment", "run_id", run.ID)
+		} else {
+			comment.Diff = diff
+		}
+	}
+	if command {
+		comment.ApplyCommand = "otf apply -w " + ws.Name
+		commentID, err := client.CreatePullRequestComment(ctx, vcs.CreatePullRequestCommentOptions{
+			Repo:              repo,
+			PullRequestNumber: pull,
+			Body:              comment.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("creating comment: %w", err)
+		}
+		r.V(2).Info("created pull request comment", "run_id", run.ID, "pull_request", pull, "comment_id", commentID)
+		return nil
+	}
+
+	commentID, err := r.comments.getPullRequestComment(ctx, ws.ID, repo, pull)
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		commentID, err = client.CreatePullRequestComment(ctx, vcs.CreatePullRequestCommentOptions{
+			Repo:              repo,
+			PullRequestNumber: pull,
+			Body:              comment.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("creating comment: %w", err)
+		}
+		r.V(2).Info("created pull request comment", "run_id", run.ID, "pull_request", pull, "comment_id", commentID)
+		return r.comments.setPullRequestComment(ctx, ws.ID, repo, pull, commentID)
+	} else if err != nil {
+		return fmt.Errorf("retrieving comment: %w", err)
+	}
+	err = client.UpdatePullRequestComment(ctx, vcs.UpdatePullRequestCommentOptions{
+		Repo:              repo,
+		PullRequestNumber: pull,
+		ID:                commentID,
+		Body:              comment.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("updating comment: %w", err)
+	}
+	r.V(2).Info("updated pull request comment", "run_id", run.ID, "pull_request", pull, "comment_id", commentID)
+	return nil
+}
@@ -2,8 +2,11 @@ package run
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"testing"
 
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/resource"
@@ -133,6 +136,101 @@ func TestReporter_DontSetStatusTwice(t *testing.T) {
 	assert.Equal(t, 0, len(got))
 }
 
+// TestReporter_PullRequestComment tests that a comment is posted to a pull
+// request once a run triggered by the pull request has finished, and that the
+// comment is updated in place by subsequent runs.
+func TestReporter_PullRequestComment(t *testing.T) {
+	ws := &workspace.Workspace{
+		ID:   testutils.ParseID(t, "ws-123"),
+		Name: "dev",
+		Connection: &workspace.Connection{
+			PullRequestComments: true,
+		},
+	}
+	cv := &configversion.ConfigurationVersion{
+		IngressAttributes: &configversion.IngressAttributes{
+			CommitSHA:         "abc123",
+			Repo:              vcs.NewMustRepo("leg100", "otf"),
+			IsPullRequest:     true,
+			PullRequestNumber: 2,
+		},
+	}
+	comments := make(map[string]string)
+	reporter := &Reporter{
+		workspaces: &fakeReporterWorkspaceClient{ws: ws},
+		configs:    &fakeReporterConfigurationVersionService{cv: cv},
+		vcs:        &fakeReporterVCSProviderService{got: make(chan vcs.SetStatusOptions, 3), comments: comments},
+		urls:       &fakeReporterURLClient{},
+		comments:   &fakeReporterCommentStore{ids: make(map[string]string)},
+		cache:      make(map[resource.TfeID]vcs.Status),
+	}
+
+	// a run in progress should not result in a comment
+	event := &Event{ID: testutils.ParseID(t, "run-1"), Status: runstatus.Planning}
+	reporter.runs = &fakeReporterRunClient{event: event, planOnly: true}
+	err := reporter.handleRun(t.Context(), event)
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+
+	// finished run should create a comment
+	event = &Event{ID: testutils.ParseID(t, "run-1"), Status: runstatus.PlannedAndFinished}
+	reporter.runs = &fakeReporterRunClient{event: event, planOnly: true}
+	err = reporter.handleRun(t.Context(), event)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Contains(t, comments["1"], "/app/runs/run-1")
+
+	// run triggered by a subsequent push should update the comment
+	event = &Event{ID: testutils.ParseID(t, "run-2"), Status: runstatus.Errored}
+	reporter.runs = &fakeReporterRunClient{event: event, planOnly: true}
+	err = reporter.handleRun(t.Context(), event)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Contains(t, comments["1"], "/app/runs/run-2")
+	assert.Contains(t, comments["1"], "**Run errored.**")
+}
+
+// TestReporter_PullRequestCommand tests that comments are posted in reply to
+// a run triggered by a pull request command, once the plan is awaiting an
+// apply and again once the run is applied.
+func TestReporter_PullRequestCommand(t *testing.T) {
+	ws := &workspace.Workspace{
+		ID:         testutils.ParseID(t, "ws-123"),
+		Name:       "dev",
+		Connection: &workspace.Connection{PullRequestCommands: true},
+	}
+	cv := &configversion.ConfigurationVersion{
+		IngressAttributes: &configversion.IngressAttributes{
+			CommitSHA:         "abc123",
+			Repo:              vcs.NewMustRepo("leg100", "otf"),
+			IsPullRequest:     true,
+			PullRequestNumber: 2,
+		},
+	}
+	comments := make(map[string]string)
+	reporter := &Reporter{
+		workspaces: &fakeReporterWorkspaceClient{ws: ws},
+		configs:    &fakeReporterConfigurationVersionService{cv: cv},
+		vcs:        &fakeReporterVCSProviderService{got: make(chan vcs.SetStatusOptions, 3), comments: comments},
+		urls:       &fakeReporterURLClient{},
+		comments:   &fakeReporterCommentStore{ids: make(map[string]string)},
+		cache:      make(map[resource.TfeID]vcs.Status),
+	}
+	runID := testutils.ParseID(t, "run-1")
+
+	for _, status := range []runstatus.Status{runstatus.Planning, runstatus.Planned, runstatus.Applying, runstatus.Applied} {
+		event := &Event{ID: runID, Status: status}
+		reporter.runs = &fakeReporterRunClient{event: event}
+		err := reporter.handleRun(t.Context(), event)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, comments, 2)
+	assert.Contains(t, comments["1"], "#### OTF plan for workspace `dev`")
+	assert.Contains(t, comments["1"], "To apply this plan, comment `otf apply -w dev`.")
+	assert.Contains(t, comments["2"], "#### OTF apply for workspace `dev`")
+}
+
 type fakeReporterConfigurationVersionService struct {
 	cv *configversion.ConfigurationVersion
 }
@@ -152,27 +250,31 @@ func (f *fakeReporterWorkspaceClient) GetWorkspace(context.Context, resource.Tfe
 type fakeReporterRunClient struct {
 	reporterRunClient
 
-	event *Event
+	event    *Event
+	planOnly bool
 }
 
 func (f *fakeReporterRunClient) GetRun(context.Context, resource.TfeID) (*Run, error) {
-	return &Run{ID: f.event.ID, Status: f.event.Status}, nil
+	return &Run{ID: f.event.ID, Status: f.event.Status, PlanOnly: f.planOnly}, nil
 }
 
 type fakeReporterVCSProviderService struct {
 	got chan vcs.SetStatusOptions
+	// pull request comments, keyed by comment ID
+	comments map[string]string
 }
 
 func (f *fakeReporterVCSProviderService) GetVCSProvider(context.Context, resource.TfeID) (*vcs.Provider, error) {
 	return &vcs.Provider{
-		Client: &fakeReporterCloudClient{got: f.got},
+		Client: &fakeReporterCloudClient{got: f.got, comments: f.comments},
 	}, nil
 }
 
 type fakeReporterCloudClient struct {
 	vcs.Client
 
-	got chan vcs.SetStatusOptions
+	got      chan vcs.SetStatusOptions
+	comments map[string]string
 }
 
 func (f *fakeReporterCloudClient) SetStatus(ctx context.Context, opts vcs.SetStatusOptions) error {
@@ -180,6 +282,37 @@ func (f *fakeReporterCloudClient) SetStatus(ctx context.Context, opts vcs.SetSta
 	return nil
 }
 
+func (f *fakeReporterCloudClient) CreatePullRequestComment(ctx context.Context, opts vcs.CreatePullRequestCommentOptions) (string, error) {
+	id := strconv.Itoa(len(f.comments) + 1)
+	f.comments[id] = opts.Body
+	return id, nil
+}
+
+func (f *fakeReporterCloudClient) UpdatePullRequestComment(ctx context.Context, opts vcs.UpdatePullRequestCommentOptions) error {
+	if _, ok := f.comments[opts.ID]; !ok {
+		return internal.ErrResourceNotFound
+	}
+	f.comments[opts.ID] = opts.Body
+	return nil
+}
+
+type fakeReporterCommentStore struct {
+	ids map[string]string
+}
+
+func (f *fakeReporterCommentStore) getPullRequestComment(ctx context.Context, workspaceID resource.TfeID, repo vcs.Repo, pull int) (string, error) {
+	id, ok := f.ids[fmt.Sprintf("%s/%s/%d", workspaceID, repo, pull)]
+	if !ok {
+		return "", internal.ErrResourceNotFound
+	}
+	return id, nil
+}
+
+func (f *fakeReporterCommentStore) setPullRequestComment(ctx context.Context, workspaceID resource.TfeID, repo vcs.Repo, pull int, commentID string) error {
+	f.ids[fmt.Sprintf("%s/%s/%d", workspaceID, repo, pull)] = commentID
+	return nil
+}
+
 type fakeReporterURLClient struct{}
 
 func (f *fakeReporterURLClient) URL(path string) string { return path }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/leg100/otf/internal"
@@ -28,6 +29,10 @@ const (
 	PlanAndApplyOperation Operation = "plan-and-apply"
 	DestroyAllOperation   Operation = "destroy-all"
 
+	PrePlanStage  TaskStage = "pre_plan"
+	PostPlanStage TaskStage = "post_plan"
+	PreApplyStage TaskStage = "pre_apply"
+
 	// defaultRefresh specifies that the state be refreshed prior to running a
 	// plan
 	defaultRefresh = true
@@ -41,6 +46,9 @@ type (
 	// Run operation specifies the terraform execution mode.
 	Operation string
 
+	// TaskStage is a stage of a run at which run tasks are invoked.
+	TaskStage string
+
 	// Run is a terraform run.
 	Run struct {
 		ID                     resource.TfeID    `jsonapi:"primary,runs"`
@@ -60,6 +68,7 @@ type (
 		AllowEmptyApply        bool              `jsonapi:"attribute" json:"allow_empty_apply"`
 		AutoApply              bool              `jsonapi:"attribute" json:"auto_apply"`
 		PlanOnly               bool              `jsonapi:"attribute" json:"plan_only"`
+		StructuredOutput       bool              `jsonapi:"attribute" json:"structured_output"`
 		Source                 source.Source     `jsonapi:"attribute" json:"source"`
 		Status                 runstatus.Status  `jsonapi:"attribute" json:"status"`
 		WorkspaceID            resource.TfeID    `jsonapi:"attribute" json:"workspace_id"`
@@ -90,6 +99,33 @@ type (
 		// a run to enter the RunCostEstimated state, and this boolean
 		// determines whether to enter that state upon finishing a plan.
 		CostEstimationEnabled bool
+
+		// PolicyChecksEnabled determines whether the run enters the policy
+		// checking state upon finishing a plan. It is true if the run's
+		// organization has at least one policy set.
+		PolicyChecksEnabled bool
+
+		// TaskStages are the stages at which run tasks are attached to the
+		// run's workspace. The run pauses at each of these stages until its
+		// tasks have completed.
+		TaskStages []TaskStage
+	}
+
+	// PolicyCheckFinishOptions are options for finishing the policy check of
+	// a run.
+	PolicyCheckFinishOptions struct {
+		// Passed is true if the run passed all policy checks or only failed
+		// advisory checks.
+		Passed bool
+		// Errored is true if the policy checks could not be performed.
+		Errored bool
+	}
+
+	// TaskStageFinishOptions are options for finishing a task stage of a run.
+	TaskStageFinishOptions struct {
+		// Passed is true if every mandatory task passed. The outcome of
+		// advisory tasks has no bearing on whether the stage passed.
+		Passed bool
 	}
 
 	Variable struct {
@@ -187,6 +223,7 @@ func NewRun(
 		ConfigurationVersionID: cv.ID,
 		WorkspaceID:            ws.ID,
 		PlanOnly:               cv.Speculative,
+		StructuredOutput:       ws.StructuredRunOutputEnabled,
 		ReplaceAddrs:           opts.ReplaceAddrs,
 		TargetAddrs:            opts.TargetAddrs,
 		ExecutionKind:          ws.Mode.Kind(),
@@ -314,9 +351,9 @@ func (r *Run) Phase() PhaseType {
 	switch r.Status {
 	case runstatus.Pending:
 		return PendingPhase
-	case runstatus.PlanQueued, runstatus.Planning, runstatus.Planned:
+	case runstatus.PrePlanRunning, runstatus.PrePlanCompleted, runstatus.PlanQueued, runstatus.Planning, runstatus.Planned, runstatus.PostPlanRunning, runstatus.PostPlanCompleted, runstatus.CostEstimating, runstatus.CostEstimated, runstatus.PolicyChecking, runstatus.PolicyChecked, runstatus.PolicyOverride:
 		return PlanPhase
-	case runstatus.ApplyQueued, runstatus.Applying, runstatus.Applied:
+	case runstatus.PreApplyRunning, runstatus.PreApplyCompleted, runstatus.ApplyQueued, runstatus.Applying, runstatus.Applied:
 		return ApplyPhase
 	default:
 		return UnknownPhase
@@ -376,7 +413,7 @@ func (r *Run) Cancel(isUser, force bool) error {
 	}
 	var signal bool
 	switch r.Status {
-	case runstatus.Pending:
+	case runstatus.Pending, runstatus.PrePlanRunning:
 		r.Plan.UpdateStatus(PhaseUnreachable)
 		r.Apply.UpdateStatus(PhaseUnreachable)
 	case runstatus.PlanQueued:
@@ -391,7 +428,7 @@ func (r *Run) Cancel(isUser, force bool) error {
 			r.Plan.UpdateStatus(PhaseCanceled)
 			r.Apply.UpdateStatus(PhaseUnreachable)
 		}
-	case runstatus.Planned:
+	case runstatus.Planned, runstatus.PostPlanRunning, runstatus.PostPlanCompleted, runstatus.CostEstimating, runstatus.CostEstimated, runstatus.PolicyChecking, runstatus.PolicyChecked, runstatus.PolicyOverride, runstatus.PreApplyRunning:
 		r.Apply.UpdateStatus(PhaseUnreachable)
 	case runstatus.Applying:
 		if isUser && !force {
@@ -425,7 +462,7 @@ func (r *Run) Cancelable() bool {
 		return false
 	}
 	switch r.Status {
-	case runstatus.Pending, runstatus.PlanQueued, runstatus.Planning, runstatus.ApplyQueued, runstatus.Applying:
+	case runstatus.Pending, runstatus.PrePlanRunning, runstatus.PlanQueued, runstatus.Planning, runstatus.PostPlanRunning, runstatus.CostEstimating, runstatus.PolicyChecking, runstatus.PreApplyRunning, runstatus.ApplyQueued, runstatus.Applying:
 		return true
 	default:
 		return false
@@ -475,30 +512,48 @@ func (r *Run) Done() bool {
 	return runstatus.Done(r.Status)
 }
 
-// EnqueuePlan enqueues a plan for the run. It also sets the run as the latest
-// run for its workspace (speculative runs are ignored).
+// EnqueuePlan enqueues a plan for the run. If run tasks are attached to the
+// pre-plan stage then the plan is instead enqueued once the tasks have
+// completed.
 func (r *Run) EnqueuePlan() error {
 	if r.Status != runstatus.Pending {
 		return fmt.Errorf("cannot enqueue run with status %s", r.Status)
 	}
+	if r.HasTaskStage(PrePlanStage) {
+		r.updateStatus(runstatus.PrePlanRunning, nil)
+		return nil
+	}
 	r.updateStatus(runstatus.PlanQueued, nil)
 	r.Plan.UpdateStatus(PhaseQueued)
 
 	return nil
 }
 
+// EnqueueApply enqueues an apply for the run. If run tasks are attached to the
+// pre-apply stage then the apply is instead enqueued once the tasks have
+// completed.
 func (r *Run) EnqueueApply() error {
 	switch r.Status {
-	case runstatus.Planned, runstatus.CostEstimated:
+	case runstatus.Planned, runstatus.PostPlanCompleted, runstatus.CostEstimated, runstatus.PolicyChecked:
 		// applyable statuses
 	default:
 		return fmt.Errorf("cannot apply run with status %s", r.Status)
 	}
+	if r.HasTaskStage(PreApplyStage) {
+		r.updateStatus(runstatus.PreApplyRunning, nil)
+		return nil
+	}
 	r.updateStatus(runstatus.ApplyQueued, nil)
 	r.Apply.UpdateStatus(PhaseQueued)
 	return nil
 }
 
+// HasTaskStage determines whether run tasks are attached to the given stage of
+// the run.
+func (r *Run) HasTaskStage(stage TaskStage) bool {
+	return slices.Contains(r.TaskStages, stage)
+}
+
 func (r *Run) StatusTimestamp(status runstatus.Status) (time.Time, error) {
 	for _, rst := range r.StatusTimestamps {
 		if rst.Status == status {
@@ -544,22 +599,22 @@ func (r *Run) Finish(phase PhaseType, opts PhaseFinishOptions) (autoapply bool,
 			r.Apply.UpdateStatus(PhaseUnreachable)
 			return false, nil
 		}
-		// Enter RunCostEstimated state if cost estimation is enabled. OTF does
-		// not support cost estimation but enter this state only in order to
-		// satisfy the go-tfe tests.
-		if r.CostEstimationEnabled {
-			r.updateStatus(runstatus.CostEstimated, nil)
-		} else {
-			r.updateStatus(runstatus.Planned, nil)
-		}
 		r.Plan.UpdateStatus(PhaseFinished)
 
-		if !r.HasChanges() || r.PlanOnly {
-			r.updateStatus(runstatus.PlannedAndFinished, nil)
-			r.Apply.UpdateStatus(PhaseUnreachable)
+		// Defer deciding whether the run can be applied until its post-plan
+		// tasks have completed.
+		if r.HasTaskStage(PostPlanStage) {
+			r.updateStatus(runstatus.PostPlanRunning, nil)
+			return false, nil
+		}
+		// Defer deciding whether the run can be applied until its costs have
+		// been estimated.
+		if r.CostEstimationEnabled {
+			r.updateStatus(runstatus.CostEstimating, nil)
 			return false, nil
 		}
-		return r.AutoApply, nil
+		r.updateStatus(runstatus.Planned, nil)
+		return r.afterPlanned(), nil
 	case ApplyPhase:
 		if r.Status != runstatus.Applying {
 			return false, ErrInvalidRunStateTransition
@@ -577,6 +632,140 @@ func (r *Run) Finish(phase PhaseType, opts PhaseFinishOptions) (autoapply bool,
 	}
 }
 
+// FinishTaskStage updates the run to reflect the run tasks of a stage having
+// completed. If the tasks passed then the run proceeds to the next step, and if
+// an apply should be automatically enqueued then autoapply will be set to true.
+// If the tasks failed then the run is errored.
+func (r *Run) FinishTaskStage(stage TaskStage, opts TaskStageFinishOptions) (autoapply bool, err error) {
+	if r.Status == runstatus.Canceled {
+		// run was canceled before its tasks completed so nothing more to do.
+		return false, nil
+	}
+	var running, completed runstatus.Status
+	switch stage {
+	case PrePlanStage:
+		running, completed = runstatus.PrePlanRunning, runstatus.PrePlanCompleted
+	case PostPlanStage:
+		running, completed = runstatus.PostPlanRunning, runstatus.PostPlanCompleted
+	case PreApplyStage:
+		running, completed = runstatus.PreApplyRunning, runstatus.PreApplyCompleted
+	default:
+		return false, fmt.Errorf("unknown task stage: %s", stage)
+	}
+	if r.Status != running {
+		return false, ErrInvalidRunStateTransition
+	}
+	if !opts.Passed {
+		r.updateStatus(runstatus.Errored, nil)
+		if stage == PrePlanStage {
+			r.Plan.UpdateStatus(PhaseUnreachable)
+		}
+		r.Apply.UpdateStatus(PhaseUnreachable)
+		return false, nil
+	}
+	r.updateStatus(completed, nil)
+	switch stage {
+	case PrePlanStage:
+		r.updateStatus(runstatus.PlanQueued, nil)
+		r.Plan.UpdateStatus(PhaseQueued)
+		return false, nil
+	case PostPlanStage:
+		if r.CostEstimationEnabled {
+			r.updateStatus(runstatus.CostEstimating, nil)
+			return false, nil
+		}
+		return r.afterPlanned(), nil
+	default:
+		r.updateStatus(runstatus.ApplyQueued, nil)
+		r.Apply.UpdateStatus(PhaseQueued)
+		return false, nil
+	}
+}
+
+// FinishCostEstimate updates the run to reflect its costs having been
+// estimated. If an apply should be automatically enqueued then autoapply will
+// be set to true. The outcome of the estimate has no bearing on whether the run
+// can be applied.
+func (r *Run) FinishCostEstimate() (autoapply bool, err error) {
+	if r.Status == runstatus.Canceled {
+		// run was canceled before its costs were estimated so nothing more to
+		// do.
+		return false, nil
+	}
+	if r.Status != runstatus.CostEstimating {
+		return false, ErrInvalidRunStateTransition
+	}
+	r.updateStatus(runstatus.CostEstimated, nil)
+	return r.afterPlanned(), nil
+}
+
+// afterPlanned determines what happens to a run once it has been planned and
+// its costs estimated: either its policies are checked, or it is finished
+// because there is nothing to apply, or it awaits an apply. Returns true if an
+// apply should be automatically enqueued.
+func (r *Run) afterPlanned() (autoapply bool) {
+	// Defer deciding whether the run can be applied until its policies have
+	// been checked.
+	if r.PolicyChecksEnabled {
+		r.updateStatus(runstatus.PolicyChecking, nil)
+		return false
+	}
+	if !r.HasChanges() || r.PlanOnly {
+		r.updateStatus(runstatus.PlannedAndFinished, nil)
+		r.Apply.UpdateStatus(PhaseUnreachable)
+		return false
+	}
+	return r.AutoApply
+}
+
+// FinishPolicyCheck updates the run to reflect its policies having been checked.
+// If the run passed its checks and an apply should be automatically enqueued
+// then autoapply will be set to true.
+func (r *Run) FinishPolicyCheck(opts PolicyCheckFinishOptions) (autoapply bool, err error) {
+	if r.Status == runstatus.Canceled {
+		// run was canceled before its policies were checked so nothing more
+		// to do.
+		return false, nil
+	}
+	if r.Status != runstatus.PolicyChecking {
+		return false, ErrInvalidRunStateTransition
+	}
+	if opts.Errored {
+		r.updateStatus(runstatus.Errored, nil)
+		r.Apply.UpdateStatus(PhaseUnreachable)
+		return false, nil
+	}
+	applyable := r.HasChanges() && !r.PlanOnly
+	if !opts.Passed {
+		if !applyable {
+			// Nothing to override.
+			r.updateStatus(runstatus.Errored, nil)
+			r.Apply.UpdateStatus(PhaseUnreachable)
+			return false, nil
+		}
+		// Await override by a user with the permission to do so.
+		r.updateStatus(runstatus.PolicyOverride, nil)
+		return false, nil
+	}
+	r.updateStatus(runstatus.PolicyChecked, nil)
+	if !applyable {
+		r.updateStatus(runstatus.PlannedAndFinished, nil)
+		r.Apply.UpdateStatus(PhaseUnreachable)
+		return false, nil
+	}
+	return r.AutoApply, nil
+}
+
+// OverridePolicyCheck overrides a failed mandatory policy check, permitting
+// the run to be confirmed and applied.
+func (r *Run) OverridePolicyCheck() error {
+	if r.Status != runstatus.PolicyOverride {
+		return ErrRunOverrideNotAllowed
+	}
+	r.updateStatus(runstatus.PolicyChecked, nil)
+	return nil
+}
+
 func (r *Run) updateStatus(status runstatus.Status, now *time.Time) *Run {
 	r.Status = status
 	r.StatusTimestamps = append(r.StatusTimestamps, StatusTimestamp{
@@ -589,21 +778,24 @@ func (r *Run) updateStatus(status runstatus.Status, now *time.Time) *Run {
 // Discardable determines whether run can be discarded.
 func (r *Run) Discardable() bool {
 	switch r.Status {
-	case runstatus.Pending, runstatus.Planned, runstatus.CostEstimated:
+	case runstatus.Pending, runstatus.Planned, runstatus.PostPlanCompleted, runstatus.CostEstimated, runstatus.PolicyChecked, runstatus.PolicyOverride:
 		return true
 	default:
 		return false
 	}
 }
 
+// confirmableStatuses are the statuses of a run that can be confirmed.
+var confirmableStatuses = []runstatus.Status{
+	runstatus.Planned,
+	runstatus.PostPlanCompleted,
+	runstatus.CostEstimated,
+	runstatus.PolicyChecked,
+}
+
 // Confirmable determines whether run can be confirmed.
 func (r *Run) Confirmable() bool {
-	switch r.Status {
-	case runstatus.Planned:
-		return true
-	default:
-		return false
-	}
+	return slices.Contains(confirmableStatuses, r.Status)
 }
 
 // LogValue implements slog.LogValuer.
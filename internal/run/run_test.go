@@ -155,11 +155,262 @@ func TestRun_States(t *testing.T) {
 		_, err := run.Finish(PlanPhase, PhaseFinishOptions{})
 		require.NoError(t, err)
 
-		require.Equal(t, runstatus.CostEstimated, run.Status)
+		require.Equal(t, runstatus.CostEstimating, run.Status)
 		require.Equal(t, PhaseFinished, run.Plan.Status)
 		require.Equal(t, PhasePending, run.Apply.Status)
 	})
 
+	t.Run("finish cost estimate", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.Status = runstatus.CostEstimating
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishCostEstimate()
+		require.NoError(t, err)
+
+		assert.True(t, autoapply)
+		assert.Equal(t, runstatus.CostEstimated, run.Status)
+		assert.True(t, run.Confirmable())
+	})
+
+	t.Run("finish cost estimate with policy checks enabled", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.PolicyChecksEnabled = true
+		run.Status = runstatus.CostEstimating
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishCostEstimate()
+		require.NoError(t, err)
+
+		assert.False(t, autoapply)
+		assert.Equal(t, runstatus.PolicyChecking, run.Status)
+	})
+
+	t.Run("finish cost estimate without changes", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.CostEstimating
+
+		_, err := run.FinishCostEstimate()
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.PlannedAndFinished, run.Status)
+		assert.Equal(t, PhaseUnreachable, run.Apply.Status)
+	})
+
+	t.Run("finish plan with policy checks enabled", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.PolicyChecksEnabled = true
+		run.Status = runstatus.Planning
+
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.Finish(PlanPhase, PhaseFinishOptions{})
+		require.NoError(t, err)
+
+		assert.False(t, autoapply)
+		assert.Equal(t, runstatus.PolicyChecking, run.Status)
+		assert.Equal(t, PhaseFinished, run.Plan.Status)
+		assert.Equal(t, PhasePending, run.Apply.Status)
+	})
+
+	t.Run("finish passed policy check", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.Status = runstatus.PolicyChecking
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishPolicyCheck(PolicyCheckFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.True(t, autoapply)
+		assert.Equal(t, runstatus.PolicyChecked, run.Status)
+		assert.True(t, run.Confirmable())
+	})
+
+	t.Run("finish passed policy check without changes", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PolicyChecking
+
+		_, err := run.FinishPolicyCheck(PolicyCheckFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.PlannedAndFinished, run.Status)
+		assert.Equal(t, PhaseUnreachable, run.Apply.Status)
+	})
+
+	t.Run("finish failed policy check", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.Status = runstatus.PolicyChecking
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishPolicyCheck(PolicyCheckFinishOptions{})
+		require.NoError(t, err)
+
+		assert.False(t, autoapply)
+		assert.Equal(t, runstatus.PolicyOverride, run.Status)
+		assert.False(t, run.Confirmable())
+		assert.True(t, run.Discardable())
+	})
+
+	t.Run("finish errored policy check", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PolicyChecking
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		_, err := run.FinishPolicyCheck(PolicyCheckFinishOptions{Errored: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.Errored, run.Status)
+		assert.Equal(t, PhaseUnreachable, run.Apply.Status)
+	})
+
+	t.Run("finish policy check of canceled run", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.Status = runstatus.Canceled
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishPolicyCheck(PolicyCheckFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.False(t, autoapply)
+		assert.Equal(t, runstatus.Canceled, run.Status)
+	})
+
+	t.Run("override policy check", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PolicyOverride
+
+		require.NoError(t, run.OverridePolicyCheck())
+
+		assert.Equal(t, runstatus.PolicyChecked, run.Status)
+		require.NoError(t, run.EnqueueApply())
+		assert.Equal(t, runstatus.ApplyQueued, run.Status)
+	})
+
+	t.Run("cannot override policy check that has not failed", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PolicyChecked
+
+		assert.Equal(t, ErrRunOverrideNotAllowed, run.OverridePolicyCheck())
+	})
+
+	t.Run("enqueue plan with pre-plan tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.TaskStages = []TaskStage{PrePlanStage}
+
+		require.NoError(t, run.EnqueuePlan())
+
+		assert.Equal(t, runstatus.PrePlanRunning, run.Status)
+		assert.Equal(t, PhasePending, run.Plan.Status)
+		assert.True(t, run.Cancelable())
+	})
+
+	t.Run("finish passed pre-plan tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PrePlanRunning
+
+		_, err := run.FinishTaskStage(PrePlanStage, TaskStageFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.PlanQueued, run.Status)
+		assert.Equal(t, PhaseQueued, run.Plan.Status)
+		_, err = run.StatusTimestamp(runstatus.PrePlanCompleted)
+		assert.NoError(t, err)
+	})
+
+	t.Run("finish failed pre-plan tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PrePlanRunning
+
+		_, err := run.FinishTaskStage(PrePlanStage, TaskStageFinishOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.Errored, run.Status)
+		assert.Equal(t, PhaseUnreachable, run.Plan.Status)
+		assert.Equal(t, PhaseUnreachable, run.Apply.Status)
+	})
+
+	t.Run("finish plan with post-plan tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.TaskStages = []TaskStage{PostPlanStage}
+		run.Status = runstatus.Planning
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.Finish(PlanPhase, PhaseFinishOptions{})
+		require.NoError(t, err)
+
+		assert.False(t, autoapply)
+		assert.Equal(t, runstatus.PostPlanRunning, run.Status)
+		assert.False(t, run.Confirmable())
+	})
+
+	t.Run("finish passed post-plan tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.Status = runstatus.PostPlanRunning
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishTaskStage(PostPlanStage, TaskStageFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.True(t, autoapply)
+		assert.Equal(t, runstatus.PostPlanCompleted, run.Status)
+		assert.True(t, run.Confirmable())
+	})
+
+	t.Run("finish passed post-plan tasks with policy checks enabled", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{AutoApply: new(true)})
+		run.PolicyChecksEnabled = true
+		run.Status = runstatus.PostPlanRunning
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		autoapply, err := run.FinishTaskStage(PostPlanStage, TaskStageFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.False(t, autoapply)
+		assert.Equal(t, runstatus.PolicyChecking, run.Status)
+	})
+
+	t.Run("finish failed post-plan tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PostPlanRunning
+		run.Plan.ResourceReport = &Report{Additions: 1}
+
+		_, err := run.FinishTaskStage(PostPlanStage, TaskStageFinishOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.Errored, run.Status)
+		assert.Equal(t, PhaseUnreachable, run.Apply.Status)
+	})
+
+	t.Run("enqueue apply with pre-apply tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.TaskStages = []TaskStage{PreApplyStage}
+		run.Status = runstatus.Planned
+
+		require.NoError(t, run.EnqueueApply())
+
+		assert.Equal(t, runstatus.PreApplyRunning, run.Status)
+		assert.Equal(t, PhasePending, run.Apply.Status)
+	})
+
+	t.Run("finish passed pre-apply tasks", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PreApplyRunning
+
+		_, err := run.FinishTaskStage(PreApplyStage, TaskStageFinishOptions{Passed: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, runstatus.ApplyQueued, run.Status)
+		assert.Equal(t, PhaseQueued, run.Apply.Status)
+	})
+
+	t.Run("cannot finish task stage that is not running", func(t *testing.T) {
+		run := newTestRun(t, t.Context(), CreateOptions{})
+		run.Status = runstatus.PreApplyRunning
+
+		_, err := run.FinishTaskStage(PostPlanStage, TaskStageFinishOptions{Passed: true})
+		assert.Equal(t, ErrInvalidRunStateTransition, err)
+	})
+
 	t.Run("enqueue apply", func(t *testing.T) {
 		run := newTestRun(t, t.Context(), CreateOptions{})
 		run.Status = runstatus.Planned
@@ -170,9 +170,9 @@ func (s *scheduler) schedule(ctx context.Context, workspaceID resource.TfeID, ru
 		_, err := s.runs.EnqueuePlan(ctx, *q.current)
 		if err != nil {
 			if errors.Is(err, workspace.ErrWorkspaceAlreadyLocked) {
-				s.V(0).Info("workspace locked by user; cannot schedule run", "run", *q.current)
+				s.V(0).Info("workspace locked by user or pull request; cannot schedule run", "run", *q.current)
 				// Place current run back onto front of backlog and wait til
-				// user unlocks workspace
+				// user or pull request unlocks workspace
 				q.backlog = append([]resource.TfeID{*q.current}, q.backlog...)
 				q.current = nil
 			} else {
@@ -182,6 +182,12 @@ func (s *scheduler) schedule(ctx context.Context, workspaceID resource.TfeID, ru
 	}
 	if unlock {
 		_, err := s.workspaces.Unlock(ctx, workspaceID, &runEvent.id, false)
+		if internal.ErrorIs(err, workspace.ErrWorkspaceLockedByPullRequest, workspace.ErrWorkspaceAlreadyUnlocked) {
+			// The run does not hold the lock, either because it ran beneath
+			// the lock of the pull request that triggered it, or because the
+			// lock has since been released, so there is nothing to unlock.
+			err = nil
+		}
 		if errors.Is(err, internal.ErrResourceNotFound) {
 			// Workspace not found error can occur when a workspace is deleted
 			// very soon after a run has completed (a quite possible scenario
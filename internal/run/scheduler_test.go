@@ -104,6 +104,28 @@ func TestScheduler_schedule(t *testing.T) {
 		assert.Nil(t, s.queues[wsID].current)
 		assert.True(t, workspaces.unlocked)
 	})
+
+	t.Run("remove finished current run from workspace locked by pull request", func(t *testing.T) {
+		runID := resource.NewTfeID(resource.RunKind)
+		nextID := resource.NewTfeID(resource.RunKind)
+		runs := &fakeSchedulerRunClient{}
+		s := &scheduler{
+			runs: runs,
+			workspaces: &fakeSchedulerWorkspaceClient{
+				unlockError: workspace.ErrWorkspaceLockedByPullRequest,
+			},
+			queues: map[resource.TfeID]queue{
+				wsID: {current: &runID, backlog: []resource.TfeID{nextID}},
+			},
+		}
+
+		// Should not propagate error
+		err := s.schedule(t.Context(), wsID, &schedulerRun{status: runstatus.Applied, id: runID})
+		require.NoError(t, err)
+
+		assert.Equal(t, nextID, *s.queues[wsID].current)
+		assert.Equal(t, nextID, *runs.enqueuedRunID)
+	})
 }
 
 func TestScheduler_process(t *testing.T) {
@@ -205,10 +227,11 @@ func (f *fakeSchedulerRunClient) EnqueuePlan(ctx context.Context, runID resource
 type fakeSchedulerWorkspaceClient struct {
 	schedulerWorkspaceClient
 
-	unlocked bool
+	unlocked    bool
+	unlockError error
 }
 
 func (f *fakeSchedulerWorkspaceClient) Unlock(ctx context.Context, workspaceID resource.TfeID, runID *resource.TfeID, force bool) (*workspace.Workspace, error) {
 	f.unlocked = true
-	return nil, nil
+	return nil, f.unlockError
 }
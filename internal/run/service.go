@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/a-h/templ"
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/engine"
@@ -41,6 +43,7 @@ type (
 		broker                 pubsub.SubscriptionService[*Event]
 		tailer                 *tailer
 		daemonCtx              context.Context
+		audit                  audit.Recorder
 
 		*factory
 	}
@@ -55,6 +58,8 @@ type (
 		Listener           *sql.Listener
 		Broker             pubsub.SubscriptionService[*Event]
 		ChunkBroker        pubsub.SubscriptionService[Chunk]
+		BlobStore          blob.Store
+		AuditRecorder      audit.Recorder
 	}
 
 	serviceClient interface {
@@ -63,6 +68,8 @@ type (
 		GetWorkspaceByName(ctx context.Context, organization organization.Name, workspace string) (*workspace.Workspace, error)
 		SetWorkspaceLatestRun(ctx context.Context, workspaceID, runID resource.TfeID) (*workspace.Workspace, error)
 		Lock(ctx context.Context, workspaceID resource.TfeID, runID *resource.TfeID) (*workspace.Workspace, error)
+		LockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock workspace.PullRequestLock) (*workspace.Workspace, error)
+		UnlockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock workspace.PullRequestLock) (*workspace.Workspace, error)
 		ListConnectedWorkspaces(ctx context.Context, vcsProviderID resource.TfeID, repoPath vcs.Repo) ([]*workspace.Workspace, error)
 		AfterCreateWorkspace(hook func(context.Context, *workspace.Workspace) error)
 		CreateConfigVersion(ctx context.Context, workspaceID resource.TfeID, opts configversion.CreateOptions) (*configversion.ConfigurationVersion, error)
@@ -76,7 +83,7 @@ type (
 )
 
 func NewService(opts Options) *Service {
-	db := &pgdb{opts.DB}
+	db := &pgdb{opts.DB, opts.BlobStore}
 	svc := Service{
 		Logger:    opts.Logger,
 		client:    opts.Client,
@@ -84,6 +91,7 @@ func NewService(opts Options) *Service {
 		Interface: opts.Authorizer,
 		daemonCtx: opts.DaemonCtx,
 		broker:    opts.Broker,
+		audit:     opts.AuditRecorder,
 	}
 	svc.MetricsCollector = &MetricsCollector{
 		service: &svc,
@@ -146,6 +154,18 @@ func (s *Service) CreateRun(ctx context.Context, workspaceID resource.TfeID, opt
 		return nil, err
 	}
 	s.V(1).Info("created run", "id", run.ID, "workspace_id", run.WorkspaceID, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: run.Organization,
+		Action:       resource.Create,
+		ResourceID:   run.ID,
+		After: map[string]any{
+			"workspace_id": run.WorkspaceID,
+			"source":       run.Source,
+			"plan_only":    run.PlanOnly,
+			"is_destroy":   run.IsDestroy,
+			"refresh_only": run.RefreshOnly,
+		},
+	})
 
 	return run, nil
 }
@@ -234,8 +254,7 @@ func (s *Service) EnqueuePlan(ctx context.Context, runID resource.TfeID) (run *R
 			return err
 		}
 		if !run.PlanOnly {
-			_, err := s.client.Lock(ctx, run.WorkspaceID, &run.ID)
-			if err != nil {
+			if err := s.lockWorkspace(ctx, run); err != nil {
 				return err
 			}
 			_, err = s.client.SetWorkspaceLatestRun(ctx, run.WorkspaceID, run.ID)
@@ -243,21 +262,46 @@ func (s *Service) EnqueuePlan(ctx context.Context, runID resource.TfeID) (run *R
 				return err
 			}
 		}
-		for _, hook := range s.afterEnqueuePlanHooks {
-			if err := hook(ctx, run); err != nil {
-				return err
-			}
+		// Defer invoking the hooks until any pre-plan tasks have completed.
+		if run.Status != runstatus.PlanQueued {
+			return nil
 		}
-		return nil
+		return s.invokeAfterEnqueuePlanHooks(ctx, run)
 	})
 	if err != nil {
 		s.Error(err, "enqueuing plan", "id", runID)
 		return nil, err
 	}
-	s.V(0).Info("enqueued plan", "id", runID)
+	s.V(0).Info("enqueued plan", "id", runID, "run_status", run.Status)
 	return run, err
 }
 
+// lockWorkspace locks the run's workspace on behalf of the run, unless the run
+// was triggered by a pull request holding the workspace lock, in which case the
+// run proceeds beneath the pull request's lock.
+func (s *Service) lockWorkspace(ctx context.Context, run *Run) error {
+	if ia := run.IngressAttributes; ia != nil && ia.IsPullRequest {
+		ws, err := s.client.GetWorkspace(ctx, run.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if ws.LockedByPullRequest(ia.Repo, ia.PullRequestNumber) {
+			return nil
+		}
+	}
+	_, err := s.client.Lock(ctx, run.WorkspaceID, &run.ID)
+	return err
+}
+
+func (s *Service) invokeAfterEnqueuePlanHooks(ctx context.Context, run *Run) error {
+	for _, hook := range s.afterEnqueuePlanHooks {
+		if err := hook(ctx, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Service) AfterEnqueuePlan(hook func(context.Context, *Run) error) {
 	// add hook to list of hooks to be triggered after plan is enqueued
 	s.afterEnqueuePlanHooks = append(s.afterEnqueuePlanHooks, hook)
@@ -332,6 +376,114 @@ func (s *Service) FinishPhase(ctx context.Context, runID resource.TfeID, phase P
 	return run, nil
 }
 
+// FinishTaskStage updates the run to reflect the run tasks of a stage having
+// completed. Once pre-plan or pre-apply tasks have passed, the plan or apply
+// respectively is enqueued; and once post-plan tasks have passed an apply is
+// enqueued if the run is set to auto-apply and there are no further checks.
+func (s *Service) FinishTaskStage(ctx context.Context, runID resource.TfeID, stage TaskStage, opts TaskStageFinishOptions) (*Run, error) {
+	var run *Run
+	err := s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		var autoapply bool
+		run, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) (err error) {
+			autoapply, err = run.FinishTaskStage(stage, opts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		switch {
+		case autoapply:
+			return s.ApplyRun(ctx, runID)
+		case run.Status == runstatus.PlanQueued:
+			return s.invokeAfterEnqueuePlanHooks(ctx, run)
+		case run.Status == runstatus.ApplyQueued:
+			return s.invokeAfterEnqueueApplyHooks(ctx, run)
+		}
+		return nil
+	})
+	if err != nil {
+		s.Error(err, "finishing task stage", "id", runID, "stage", stage)
+		return nil, err
+	}
+	s.V(0).Info("finished task stage", "id", runID, "stage", stage, "passed", opts.Passed, "run_status", run.Status)
+	return run, nil
+}
+
+// FinishCostEstimate updates the run to reflect its costs having been
+// estimated. An apply is enqueued if the run is set to auto-apply and there
+// are no policies to check.
+func (s *Service) FinishCostEstimate(ctx context.Context, runID resource.TfeID) (*Run, error) {
+	var run *Run
+	err := s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		var autoapply bool
+		run, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) (err error) {
+			autoapply, err = run.FinishCostEstimate()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if autoapply {
+			return s.ApplyRun(ctx, runID)
+		}
+		return nil
+	})
+	if err != nil {
+		s.Error(err, "finishing cost estimate", "id", runID)
+		return nil, err
+	}
+	s.V(0).Info("finished cost estimate", "id", runID, "run_status", run.Status)
+	return run, nil
+}
+
+// FinishPolicyCheck updates the run to reflect its policies having been
+// checked. An apply is enqueued if the checks passed and the run is set to
+// auto-apply.
+func (s *Service) FinishPolicyCheck(ctx context.Context, runID resource.TfeID, opts PolicyCheckFinishOptions) (*Run, error) {
+	var run *Run
+	err := s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		var autoapply bool
+		run, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) (err error) {
+			autoapply, err = run.FinishPolicyCheck(opts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if autoapply {
+			return s.ApplyRun(ctx, runID)
+		}
+		return nil
+	})
+	if err != nil {
+		s.Error(err, "finishing policy check", "id", runID)
+		return nil, err
+	}
+	s.V(0).Info("finished policy check", "id", runID, "passed", opts.Passed, "run_status", run.Status)
+	return run, nil
+}
+
+// OverridePolicyCheck overrides a run's failed mandatory policy check,
+// permitting the run to be applied.
+func (s *Service) OverridePolicyCheck(ctx context.Context, runID resource.TfeID) (*Run, error) {
+	subject, err := s.Authorize(ctx, resource.Override, resource.PolicyCheckKind, runID)
+	if err != nil {
+		return nil, err
+	}
+	var before runstatus.Status
+	run, err := s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) error {
+		before = run.Status
+		return run.OverridePolicyCheck()
+	})
+	if err != nil {
+		s.Error(err, "overriding policy check", "id", runID, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("overrode policy check", "id", runID, "subject", subject)
+	s.recordStatusChange(ctx, resource.Override, run, before)
+	return run, nil
+}
+
 func (s *Service) WatchRuns(ctx context.Context) (<-chan pubsub.Event[*Event], func(), error) {
 	return s.broker.Subscribe(ctx)
 }
@@ -342,8 +494,9 @@ func (s *Service) ApplyRun(ctx context.Context, runID resource.TfeID) error {
 	if err != nil {
 		return err
 	}
-	return s.db.Tx(ctx, func(ctx context.Context) error {
-		run, err := s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) error {
+	var run *Run
+	err = s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		run, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) error {
 			return run.EnqueueApply()
 		})
 		if err != nil {
@@ -351,15 +504,22 @@ func (s *Service) ApplyRun(ctx context.Context, runID resource.TfeID) error {
 			return err
 		}
 
-		s.V(0).Info("enqueued apply", "id", runID, "subject", subject)
-		// invoke AfterEnqueueApply hooks
-		for _, hook := range s.afterEnqueueApplyHooks {
-			if err := hook(ctx, run); err != nil {
-				return err
-			}
+		s.V(0).Info("enqueued apply", "id", runID, "run_status", run.Status, "subject", subject)
+		// Defer invoking the hooks until any pre-apply tasks have completed.
+		if run.Status != runstatus.ApplyQueued {
+			return nil
 		}
-		return nil
+		return s.invokeAfterEnqueueApplyHooks(ctx, run)
 	})
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: run.Organization,
+		Action:       resource.Apply,
+		ResourceID:   run.ID,
+	})
+	return nil
 }
 
 func (s *Service) AfterEnqueueApply(hook func(context.Context, *Run) error) {
@@ -367,6 +527,15 @@ func (s *Service) AfterEnqueueApply(hook func(context.Context, *Run) error) {
 	s.afterEnqueueApplyHooks = append(s.afterEnqueueApplyHooks, hook)
 }
 
+func (s *Service) invokeAfterEnqueueApplyHooks(ctx context.Context, run *Run) error {
+	for _, hook := range s.afterEnqueueApplyHooks {
+		if err := hook(ctx, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DiscardRun discards the run.
 func (s *Service) DiscardRun(ctx context.Context, runID resource.TfeID) error {
 	subject, err := s.Authorize(ctx, resource.Discard, resource.RunKind, runID)
@@ -374,7 +543,9 @@ func (s *Service) DiscardRun(ctx context.Context, runID resource.TfeID) error {
 		return err
 	}
 
-	_, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) error {
+	var before runstatus.Status
+	run, err := s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) error {
+		before = run.Status
 		return run.Discard()
 	})
 	if err != nil {
@@ -383,8 +554,9 @@ func (s *Service) DiscardRun(ctx context.Context, runID resource.TfeID) error {
 	}
 
 	s.V(0).Info("discarded run", "id", runID, "subject", subject)
+	s.recordStatusChange(ctx, resource.Discard, run, before)
 
-	return err
+	return nil
 }
 
 func (s *Service) CancelRun(ctx context.Context, runID resource.TfeID) error {
@@ -392,12 +564,16 @@ func (s *Service) CancelRun(ctx context.Context, runID resource.TfeID) error {
 	if err != nil {
 		return err
 	}
-	var run *Run
+	var (
+		run    *Run
+		before runstatus.Status
+	)
 	err = s.db.Tx(ctx, func(ctx context.Context) error {
 		_, isUser := subject.(*user.User)
 
 		var err error
 		run, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) (err error) {
+			before = run.Status
 			return run.Cancel(isUser, false)
 		})
 		if err != nil {
@@ -413,7 +589,9 @@ func (s *Service) CancelRun(ctx context.Context, runID resource.TfeID) error {
 	})
 	if err != nil {
 		s.Error(err, "canceling run", "id", runID, "subject", subject)
+		return err
 	}
+	s.recordStatusChange(ctx, resource.Cancel, run, before)
 	if run.Status != runstatus.Canceled && run.CancelSignaledAt != nil {
 		s.V(0).Info("signaled cancelation", "id", runID, "subject", subject)
 
@@ -446,8 +624,13 @@ func (s *Service) ForceCancelRun(ctx context.Context, runID resource.TfeID) erro
 	if err != nil {
 		return err
 	}
-	return s.db.Tx(ctx, func(ctx context.Context) error {
-		run, err := s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) (err error) {
+	var (
+		run    *Run
+		before runstatus.Status
+	)
+	err = s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		run, err = s.db.UpdateStatus(ctx, runID, func(ctx context.Context, run *Run) (err error) {
+			before = run.Status
 			return run.Cancel(true, true)
 		})
 		if err != nil {
@@ -463,6 +646,23 @@ func (s *Service) ForceCancelRun(ctx context.Context, runID resource.TfeID) erro
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	s.recordStatusChange(ctx, resource.ForceCancel, run, before)
+	return nil
+}
+
+// recordStatusChange records an audit event for an action that changed the
+// status of a run.
+func (s *Service) recordStatusChange(ctx context.Context, action resource.Action, run *Run, before runstatus.Status) {
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: run.Organization,
+		Action:       action,
+		ResourceID:   run.ID,
+		Before:       map[string]any{"status": before},
+		After:        map[string]any{"status": run.Status},
+	})
 }
 
 func (s *Service) AfterForceCancelRun(hook func(context.Context, *Run) error) {
@@ -485,6 +685,28 @@ func (s *Service) GetRunPlanFile(ctx context.Context, runID resource.TfeID, form
 	return file, nil
 }
 
+// GetPlanDiff retrieves the resource changes proposed by a run's plan, along
+// with the changes to their attributes.
+func (s *Service) GetPlanDiff(ctx context.Context, runID resource.TfeID, opts PlanDiffOptions) (*PlanDiff, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.PlanFileKind, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.db.GetPlanFile(ctx, runID, PlanFormatJSON)
+	if err != nil {
+		s.Error(err, "retrieving plan diff", "id", runID, "subject", subject)
+		return nil, err
+	}
+	diff, err := NewPlanDiff(file, opts)
+	if err != nil {
+		s.Error(err, "retrieving plan diff", "id", runID, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved plan diff", "id", runID, "changes", diff.Len(), "subject", subject)
+	return diff, nil
+}
+
 // UploadRunPlanFile persists a run's plan file. The plan format should be either
 // be binary or json.
 func (s *Service) UploadRunPlanFile(ctx context.Context, runID resource.TfeID, plan []byte, format PlanFormat) error {
@@ -517,11 +739,15 @@ func (s *Service) createReports(ctx context.Context, runID resource.TfeID, phase
 }
 
 func (s *Service) createPlanReports(ctx context.Context, runID resource.TfeID) (resources Report, outputs Report, err error) {
+	run, err := s.db.get(ctx, runID)
+	if err != nil {
+		return Report{}, Report{}, err
+	}
 	plan, err := s.GetRunPlanFile(ctx, runID, PlanFormatJSON)
 	if err != nil {
 		return Report{}, Report{}, err
 	}
-	resourceReport, outputReport, err := CompilePlanReports(plan)
+	resourceReport, outputReport, err := CompilePlanReports(plan, run.RefreshOnly)
 	if err != nil {
 		return Report{}, Report{}, err
 	}
@@ -532,6 +758,10 @@ func (s *Service) createPlanReports(ctx context.Context, runID resource.TfeID) (
 }
 
 func (s *Service) createApplyReport(ctx context.Context, runID resource.TfeID) (Report, error) {
+	run, err := s.db.get(ctx, runID)
+	if err != nil {
+		return Report{}, err
+	}
 	logs, err := s.GetChunk(ctx, GetChunkOptions{
 		RunID: runID,
 		Phase: ApplyPhase,
@@ -539,7 +769,12 @@ func (s *Service) createApplyReport(ctx context.Context, runID resource.TfeID) (
 	if err != nil {
 		return Report{}, err
 	}
-	report, err := ParseApplyOutput(string(logs.Data))
+	var report Report
+	if run.StructuredOutput {
+		report, err = ParseStructuredLog(logs.Data).Report()
+	} else {
+		report, err = ParseApplyOutput(string(logs.Data))
+	}
 	if err != nil {
 		return Report{}, err
 	}
@@ -31,6 +31,11 @@ type (
 		UploadConfig(ctx context.Context, id resource.TfeID, config []byte) error
 		GetVCSProvider(ctx context.Context, providerID resource.TfeID) (*vcs.Provider, error)
 		CreateRun(ctx context.Context, workspaceID resource.TfeID, opts CreateOptions) (*Run, error)
+		ListRuns(ctx context.Context, opts ListOptions) (*resource.Page[*Run], error)
+		ApplyRun(ctx context.Context, runID resource.TfeID) error
+		DiscardRun(ctx context.Context, runID resource.TfeID) error
+		LockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock workspace.PullRequestLock) (*workspace.Workspace, error)
+		UnlockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock workspace.PullRequestLock) (*workspace.Workspace, error)
 	}
 )
 
@@ -55,6 +60,14 @@ func (s *Spawner) handleWithError(logger logr.Logger, event vcs.Event) error {
 	// give spawner unlimited powers
 	ctx = authz.AddSubjectToContext(ctx, &authz.Superuser{Username: "run-spawner"})
 
+	switch {
+	case event.Type == vcs.EventTypeComment:
+		return s.handleCommand(ctx, logger, event)
+	case event.Type == vcs.EventTypePull && (event.Action == vcs.ActionMerged || event.Action == vcs.ActionDeleted):
+		// release any workspaces locked by the pull request
+		return s.releasePullRequestLocks(ctx, event)
+	}
+
 	// skip events other than those that create or update a ref or pull request
 	switch event.Action {
 	case vcs.ActionCreated, vcs.ActionUpdated:
@@ -71,6 +84,9 @@ func (s *Spawner) handleWithError(logger logr.Logger, event vcs.Event) error {
 		logger.V(4).Info("ignoring vcs event: no connected workspaces found")
 		return nil
 	}
+	if err := s.completePushEvent(ctx, &event, workspaces); err != nil {
+		return err
+	}
 
 	// filter out workspaces based on info contained in the event
 	var n int
@@ -148,30 +164,9 @@ func (s *Spawner) handleWithError(logger logr.Logger, event vcs.Event) error {
 	// pull request events don't contain a list of changed files; instead an API
 	// call is necessary to retrieve the list of changed files
 	if event.Type == vcs.EventTypePull {
-		// only perform API call if at least one workspace has file triggers
-		// enabled.
-		var listFiles bool
-		for _, ws := range workspaces {
-			if ws.TriggerPatterns != nil {
-				listFiles = true
-				break
-			}
-		}
-		if listFiles {
-			paths, err := client.ListPullRequestFiles(ctx, event.Repo, event.PullRequestNumber)
-			if err != nil {
-				return fmt.Errorf("retrieving list of files in pull request from cloud provider: %w", err)
-			}
-			n := 0
-			for _, ws := range workspaces {
-				if ws.TriggerPatterns != nil && !globMatch(paths, ws.TriggerPatterns) {
-					// skip workspace
-					continue
-				}
-				workspaces[n] = ws
-				n++
-			}
-			workspaces = workspaces[:n]
+		workspaces, err = filterByPullRequestFiles(ctx, client, event, workspaces)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -220,6 +215,42 @@ func (s *Spawner) handleWithError(logger logr.Logger, event vcs.Event) error {
 	return nil
 }
 
+// completePushEvent retrieves from the provider the default branch and the
+// paths of changed files for a push or tag event, which some kinds omit from
+// their events. They're only retrieved if missing and needed to filter the
+// workspaces.
+func (s *Spawner) completePushEvent(ctx context.Context, event *vcs.Event, workspaces []*workspace.Workspace) error {
+	if event.Type != vcs.EventTypePush && event.Type != vcs.EventTypeTag {
+		return nil
+	}
+	// the default branch is needed for workspaces that don't specify a branch
+	needBranch := event.Type == vcs.EventTypePush && event.DefaultBranch == "" &&
+		slices.ContainsFunc(workspaces, func(ws *workspace.Workspace) bool { return ws.Connection.Branch == "" })
+	// paths are needed for workspaces with file triggers
+	needPaths := event.Paths == nil && event.PreviousCommitSHA != "" &&
+		slices.ContainsFunc(workspaces, func(ws *workspace.Workspace) bool { return ws.TriggerPatterns != nil })
+	if !needBranch && !needPaths {
+		return nil
+	}
+	client, err := s.client.GetVCSProvider(ctx, event.VCSProviderID)
+	if err != nil {
+		return err
+	}
+	if needBranch {
+		event.DefaultBranch, err = client.GetDefaultBranch(ctx, event.Repo.String())
+		if err != nil {
+			return fmt.Errorf("retrieving default branch from cloud provider: %w", err)
+		}
+	}
+	if lister, ok := client.Client.(vcs.ChangedFilesLister); ok && needPaths {
+		event.Paths, err = lister.ListChangedFiles(ctx, event.Repo, event.PreviousCommitSHA, event.CommitSHA)
+		if err != nil {
+			return fmt.Errorf("retrieving list of changed files from cloud provider: %w", err)
+		}
+	}
+	return nil
+}
+
 // globMatch returns true if any of the paths match any of the glob patterns.
 func globMatch(paths []string, patterns []string) bool {
 	if len(paths) == 0 || len(patterns) == 0 {
@@ -21,6 +21,10 @@ func TestSpawner(t *testing.T) {
 		event vcs.Event
 		// file paths to return from stubbed client.ListPullRequestFiles
 		pullFiles []string
+		// default branch to return from stubbed client.GetDefaultBranch
+		defaultBranch string
+		// file paths to return from stubbed client.ListChangedFiles
+		changedFiles []string
 		// want spawned run
 		spawn bool
 	}{
@@ -181,6 +185,66 @@ func TestSpawner(t *testing.T) {
 			},
 			spawn: false,
 		},
+		{
+			name: "spawn run for push to default branch retrieved from provider",
+			ws:   &workspace.Workspace{Connection: &workspace.Connection{}},
+			event: vcs.Event{
+				EventPayload: vcs.EventPayload{
+					Type:   vcs.EventTypePush,
+					Action: vcs.ActionCreated,
+					Branch: "main",
+				},
+			},
+			defaultBranch: "main",
+			spawn:         true,
+		},
+		{
+			name: "skip run for push to non-default branch retrieved from provider",
+			ws:   &workspace.Workspace{Connection: &workspace.Connection{}},
+			event: vcs.Event{
+				EventPayload: vcs.EventPayload{
+					Type:   vcs.EventTypePush,
+					Action: vcs.ActionCreated,
+					Branch: "dev",
+				},
+			},
+			defaultBranch: "main",
+			spawn:         false,
+		},
+		{
+			name: "spawn run for push event for workspace with file trigger pattern matching changed files retrieved from provider",
+			ws: &workspace.Workspace{
+				TriggerPatterns: []string{"/foo/*.tf"},
+				Connection:      &workspace.Connection{Branch: "main"},
+			},
+			event: vcs.Event{
+				EventPayload: vcs.EventPayload{
+					Type:              vcs.EventTypePush,
+					Action:            vcs.ActionCreated,
+					Branch:            "main",
+					PreviousCommitSHA: "abc123",
+				},
+			},
+			changedFiles: []string{"/foo/bar.tf"},
+			spawn:        true,
+		},
+		{
+			name: "skip run for push event for workspace with file trigger pattern not matching changed files retrieved from provider",
+			ws: &workspace.Workspace{
+				TriggerPatterns: []string{"/foo/*.tf"},
+				Connection:      &workspace.Connection{Branch: "main"},
+			},
+			event: vcs.Event{
+				EventPayload: vcs.EventPayload{
+					Type:              vcs.EventTypePush,
+					Action:            vcs.ActionCreated,
+					Branch:            "main",
+					PreviousCommitSHA: "abc123",
+				},
+			},
+			changedFiles: []string{"README.md"},
+			spawn:        false,
+		},
 		{
 			name: "spawn run for pull event for workspace with matching file trigger pattern",
 			ws: &workspace.Workspace{
@@ -218,9 +282,11 @@ func TestSpawner(t *testing.T) {
 			spawned := new(bool)
 			spawner := Spawner{
 				client: &fakeSpawnerClient{
-					ws:        tt.ws,
-					pullFiles: tt.pullFiles,
-					spawned:   spawned,
+					ws:            tt.ws,
+					pullFiles:     tt.pullFiles,
+					defaultBranch: tt.defaultBranch,
+					changedFiles:  tt.changedFiles,
+					spawned:       spawned,
 				},
 			}
 			err := spawner.handleWithError(logr.Discard(), tt.event)
@@ -238,7 +304,11 @@ type fakeSpawnerClient struct {
 	spawned *bool
 	// list of file paths to return from stubbed ListPullRequestFiles()
 	pullFiles []string
-	ws        *workspace.Workspace
+	// default branch to return from stubbed GetDefaultBranch()
+	defaultBranch string
+	// list of file paths to return from stubbed ListChangedFiles()
+	changedFiles []string
+	ws           *workspace.Workspace
 }
 
 func (f *fakeSpawnerClient) CreateRun(context.Context, resource.TfeID, CreateOptions) (*Run, error) {
@@ -248,7 +318,11 @@ func (f *fakeSpawnerClient) CreateRun(context.Context, resource.TfeID, CreateOpt
 
 func (f *fakeSpawnerClient) GetVCSProvider(context.Context, resource.TfeID) (*vcs.Provider, error) {
 	return &vcs.Provider{
-		Client: &fakeSpawnerCloudClient{pullFiles: f.pullFiles},
+		Client: &fakeSpawnerCloudClient{
+			pullFiles:     f.pullFiles,
+			defaultBranch: f.defaultBranch,
+			changedFiles:  f.changedFiles,
+		},
 	}, nil
 }
 
@@ -266,7 +340,17 @@ func (f *fakeSpawnerClient) UploadConfig(ctx context.Context, id resource.TfeID,
 
 type fakeSpawnerCloudClient struct {
 	vcs.Client
-	pullFiles []string
+	pullFiles     []string
+	defaultBranch string
+	changedFiles  []string
+}
+
+func (f *fakeSpawnerCloudClient) GetDefaultBranch(context.Context, string) (string, error) {
+	return f.defaultBranch, nil
+}
+
+func (f *fakeSpawnerCloudClient) ListChangedFiles(context.Context, vcs.Repo, string, string) ([]string, error) {
+	return f.changedFiles, nil
 }
 
 func (f *fakeSpawnerCloudClient) GetRepoTarball(context.Context, vcs.GetRepoTarballOptions) ([]byte, string, error) {
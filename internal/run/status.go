@@ -31,6 +31,17 @@ var (
 		runstatus.PlanQueued,
 		runstatus.Planned,
 		runstatus.Planning,
+		runstatus.CostEstimating,
+		runstatus.CostEstimated,
+		runstatus.PolicyChecking,
+		runstatus.PolicyChecked,
+		runstatus.PolicyOverride,
+		runstatus.PrePlanRunning,
+		runstatus.PrePlanCompleted,
+		runstatus.PostPlanRunning,
+		runstatus.PostPlanCompleted,
+		runstatus.PreApplyRunning,
+		runstatus.PreApplyCompleted,
 	}
 	IncompleteRun = append(ActiveRun, runstatus.Pending)
 )
@@ -0,0 +1,109 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/runstatus"
+)
+
+// By default retry runs remaining in a watched status every minute
+var defaultStatusWatcherInterval = time.Minute
+
+type (
+	// StatusWatcher is a daemon that handles runs in any of a set of
+	// statuses. A run is handled when it enters one of the statuses, and runs
+	// already in one of the statuses are handled when the watcher starts. If
+	// handling a run fails, e.g. due to a transient error, then the run remains
+	// in the status, so the watcher periodically lists such runs and handles
+	// them again. Handlers must therefore be idempotent.
+	StatusWatcher struct {
+		logr.Logger
+
+		OverrideCheckInterval time.Duration
+		Runs                  statusWatcherRunClient
+		Statuses              []runstatus.Status
+		// Handler handles a run in one of the statuses.
+		Handler func(ctx context.Context, runID resource.TfeID) error
+	}
+
+	statusWatcherRunClient interface {
+		ListRuns(ctx context.Context, opts ListOptions) (*resource.Page[*Run], error)
+		WatchRuns(context.Context) (<-chan pubsub.Event[*Event], func(), error)
+	}
+)
+
+// Start the status watcher daemon.
+func (w *StatusWatcher) Start(ctx context.Context) error {
+	interval := defaultStatusWatcherInterval
+	if w.OverrideCheckInterval != 0 {
+		interval = w.OverrideCheckInterval
+	}
+
+	// Subscribe to run events before listing runs so that no run entering a
+	// status is missed in between.
+	sub, unsub, err := w.Runs.WatchRuns(ctx)
+	if err != nil {
+		return fmt.Errorf("watching runs: %w", err)
+	}
+	defer unsub()
+
+	// Handle runs that entered a status before the watcher started.
+	if err := w.handleAll(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub:
+			if !ok {
+				return pubsub.ErrSubscriptionTerminated
+			}
+			if event.Type != pubsub.UpdatedEvent {
+				continue
+			}
+			if !slices.Contains(w.Statuses, event.Payload.Status) {
+				continue
+			}
+			w.handle(ctx, event.Payload.ID)
+		case <-ticker.C:
+			// Retry runs whose handling previously failed. A failure to list
+			// runs is itself retried on the next tick.
+			if err := w.handleAll(ctx); err != nil {
+				w.Error(err, "retrying runs", "statuses", w.Statuses)
+			}
+		}
+	}
+}
+
+// handleAll handles all runs currently in one of the statuses.
+func (w *StatusWatcher) handleAll(ctx context.Context) error {
+	runs, err := resource.ListAll(func(opts resource.PageOptions) (*resource.Page[*Run], error) {
+		return w.Runs.ListRuns(ctx, ListOptions{
+			Statuses:    w.Statuses,
+			PageOptions: opts,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("listing runs: %w", err)
+	}
+	for _, r := range runs {
+		w.handle(ctx, r.ID)
+	}
+	return nil
+}
+
+func (w *StatusWatcher) handle(ctx context.Context, runID resource.TfeID) {
+	if err := w.Handler(ctx, runID); err != nil {
+		w.Error(err, "handling run, will retry", "run_id", runID)
+	}
+}
@@ -0,0 +1,87 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusWatcher(t *testing.T) {
+	existing := &Run{ID: resource.NewTfeID(resource.RunKind), Status: runstatus.PolicyChecking}
+	entered := resource.NewTfeID(resource.RunKind)
+
+	client := &fakeStatusWatcherRunClient{
+		runs: []*Run{existing},
+		sub:  make(chan pubsub.Event[*Event]),
+	}
+	handled := make(chan resource.TfeID, 10)
+	// fail handling of the existing run the first time around
+	failed := false
+	watcher := &StatusWatcher{
+		Logger:                logr.Discard(),
+		OverrideCheckInterval: 10 * time.Millisecond,
+		Runs:                  client,
+		Statuses:              []runstatus.Status{runstatus.PolicyChecking},
+		Handler: func(ctx context.Context, runID resource.TfeID) error {
+			if runID == existing.ID && !failed {
+				failed = true
+				return errors.New("transient error")
+			}
+			// handled run leaves the status
+			client.setRuns(nil)
+			handled <- runID
+			return nil
+		},
+	}
+	errch := make(chan error)
+	go func() { errch <- watcher.Start(t.Context()) }()
+
+	// existing run is retried after failing
+	assert.Equal(t, existing.ID, <-handled)
+
+	// runs entering other statuses are ignored
+	client.sub <- pubsub.Event[*Event]{
+		Type:    pubsub.UpdatedEvent,
+		Payload: &Event{ID: resource.NewTfeID(resource.RunKind), Status: runstatus.Planned},
+	}
+	client.sub <- pubsub.Event[*Event]{
+		Type:    pubsub.UpdatedEvent,
+		Payload: &Event{ID: entered, Status: runstatus.PolicyChecking},
+	}
+	assert.Equal(t, entered, <-handled)
+
+	// watcher returns an error once the subscription is terminated
+	close(client.sub)
+	require.ErrorIs(t, <-errch, pubsub.ErrSubscriptionTerminated)
+}
+
+type fakeStatusWatcherRunClient struct {
+	runs []*Run
+	sub  chan pubsub.Event[*Event]
+	mu   sync.Mutex
+}
+
+func (f *fakeStatusWatcherRunClient) setRuns(runs []*Run) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs = runs
+}
+
+func (f *fakeStatusWatcherRunClient) ListRuns(context.Context, ListOptions) (*resource.Page[*Run], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return resource.NewPage(f.runs, resource.PageOptions{}, nil), nil
+}
+
+func (f *fakeStatusWatcherRunClient) WatchRuns(context.Context) (<-chan pubsub.Event[*Event], func(), error) {
+	return f.sub, func() {}, nil
+}
@@ -0,0 +1,311 @@
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"strings"
+	"time"
+
+	term2html "github.com/buildkite/terminal-to-html"
+)
+
+const (
+	ResourcePlanned    ResourceStatus = "planned"
+	ResourceRefreshing ResourceStatus = "refreshing"
+	ResourceRefreshed  ResourceStatus = "refreshed"
+	ResourceApplying   ResourceStatus = "applying"
+	ResourceApplied    ResourceStatus = "applied"
+	ResourceErrored    ResourceStatus = "errored"
+)
+
+var ErrMissingChangeSummary = errors.New("structured output is missing a change summary")
+
+type (
+	// StructuredLog is the machine-readable output of a phase, parsed into a
+	// timeline of the resources the phase touched. The output is documented
+	// here:
+	//
+	// https://developer.hashicorp.com/terraform/internals/machine-readable-ui
+	StructuredLog struct {
+		// Messages in the order they were emitted. Lines that are not JSON
+		// messages, e.g. the output of init, are included as messages with
+		// only the Text field set.
+		Messages []*LogMessage
+		// Resources in the order they first appear in the output.
+		Resources []*ResourceTimeline
+		// Diagnostics are warnings and errors, including those belonging to
+		// resources.
+		Diagnostics []*Diagnostic
+		// Summary is the count of changes, emitted at the end of a plan or
+		// apply. It is nil if the phase has not finished or it errored.
+		Summary *ChangeSummary
+		// Outputs are the root module outputs.
+		Outputs map[string]OutputValue
+	}
+
+	// LogMessage is a single message in the output.
+	LogMessage struct {
+		Level      string                 `json:"@level"`
+		Text       string                 `json:"@message"`
+		Module     string                 `json:"@module"`
+		Timestamp  time.Time              `json:"@timestamp"`
+		Type       string                 `json:"type"`
+		Hook       *logHook               `json:"hook"`
+		Change     *logChange             `json:"change"`
+		Changes    *ChangeSummary         `json:"changes"`
+		Diagnostic *Diagnostic            `json:"diagnostic"`
+		Outputs    map[string]OutputValue `json:"outputs"`
+
+		// Plain is true if the message is a line of plain text rather than a
+		// JSON message.
+		Plain bool `json:"-"`
+	}
+
+	logResource struct {
+		Addr         string `json:"addr"`
+		ResourceType string `json:"resource_type"`
+	}
+
+	// logHook is the payload of messages that report the progress of an
+	// operation on a resource.
+	logHook struct {
+		Resource       logResource `json:"resource"`
+		Action         string      `json:"action"`
+		ElapsedSeconds int         `json:"elapsed_seconds"`
+	}
+
+	// logChange is the payload of messages that describe a planned change to a
+	// resource.
+	logChange struct {
+		Resource logResource `json:"resource"`
+		Action   string      `json:"action"`
+		Reason   string      `json:"reason"`
+	}
+
+	// ChangeSummary is the count of resource changes in a plan or apply.
+	ChangeSummary struct {
+		Add       int    `json:"add"`
+		Change    int    `json:"change"`
+		Import    int    `json:"import"`
+		Remove    int    `json:"remove"`
+		Operation string `json:"operation"`
+	}
+
+	// Diagnostic is a warning or error.
+	Diagnostic struct {
+		Severity string             `json:"severity"`
+		Summary  string             `json:"summary"`
+		Detail   string             `json:"detail"`
+		Address  string             `json:"address"`
+		Range    *DiagnosticRange   `json:"range"`
+		Snippet  *DiagnosticSnippet `json:"snippet"`
+	}
+
+	// DiagnosticRange is the location in the configuration to which a
+	// diagnostic refers.
+	DiagnosticRange struct {
+		Filename string `json:"filename"`
+		Start    struct {
+			Line   int `json:"line"`
+			Column int `json:"column"`
+		} `json:"start"`
+	}
+
+	// DiagnosticSnippet is an excerpt of the configuration to which a
+	// diagnostic refers.
+	DiagnosticSnippet struct {
+		Context   *string `json:"context"`
+		Code      string  `json:"code"`
+		StartLine int     `json:"start_line"`
+	}
+
+	// OutputValue is a root module output.
+	OutputValue struct {
+		Sensitive bool            `json:"sensitive"`
+		Action    string          `json:"action"`
+		Value     json.RawMessage `json:"value"`
+	}
+
+	// ResourceTimeline is the sequence of messages concerning a resource.
+	ResourceTimeline struct {
+		Addr         string
+		ResourceType string
+		// Action is the planned action, e.g. create, update, delete. It is
+		// empty if no change is planned.
+		Action string
+		// Reason is the reason for the action, e.g. the resource is tainted.
+		Reason string
+		// Drifted is true if the resource was changed outside of the engine.
+		Drifted bool
+		Status  ResourceStatus
+		// StartedAt is when the first operation on the resource started.
+		StartedAt time.Time
+		// FinishedAt is when the most recent operation on the resource
+		// finished. It is zero if an operation is in progress.
+		FinishedAt  time.Time
+		Messages    []*LogMessage
+		Diagnostics []*Diagnostic
+	}
+
+	// ResourceStatus is the status of a resource within a phase.
+	ResourceStatus string
+)
+
+// ParseStructuredLog parses the logs of a phase emitting structured output.
+// Incomplete trailing lines, which occur whilst logs are streamed, are
+// ignored.
+func ParseStructuredLog(data []byte) *StructuredLog {
+	data = bytes.TrimPrefix(data, []byte{STX})
+	if bytes.HasSuffix(data, []byte{ETX}) {
+		data = bytes.TrimSuffix(data, []byte{ETX})
+	} else if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+	} else {
+		data = nil
+	}
+
+	log := &StructuredLog{}
+	resources := make(map[string]*ResourceTimeline)
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimRight(line, "\r\n")
+		msg := &LogMessage{}
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), msg) != nil || msg.Type == "" {
+			log.Messages = append(log.Messages, &LogMessage{Text: line, Plain: true})
+			continue
+		}
+		log.Messages = append(log.Messages, msg)
+
+		switch msg.Type {
+		case "change_summary":
+			log.Summary = msg.Changes
+		case "outputs":
+			log.Outputs = msg.Outputs
+		case "diagnostic":
+			if msg.Diagnostic != nil {
+				log.Diagnostics = append(log.Diagnostics, msg.Diagnostic)
+			}
+		}
+
+		// Add message to the timeline of the resource it concerns, if any.
+		var res logResource
+		switch {
+		case msg.Hook != nil && msg.Hook.Resource.Addr != "":
+			res = msg.Hook.Resource
+		case msg.Change != nil && msg.Change.Resource.Addr != "":
+			res = msg.Change.Resource
+		case msg.Diagnostic != nil && msg.Diagnostic.Address != "":
+			res = logResource{Addr: msg.Diagnostic.Address}
+		default:
+			continue
+		}
+		timeline, ok := resources[res.Addr]
+		if !ok {
+			timeline = &ResourceTimeline{Addr: res.Addr, ResourceType: res.ResourceType}
+			resources[res.Addr] = timeline
+			log.Resources = append(log.Resources, timeline)
+		}
+		timeline.add(msg)
+	}
+	return log
+}
+
+// Report returns a report of the changes made by the phase.
+func (l *StructuredLog) Report() (Report, error) {
+	if l.Summary == nil {
+		return Report{}, ErrMissingChangeSummary
+	}
+	return Report{
+		Additions:    l.Summary.Add,
+		Changes:      l.Summary.Change,
+		Destructions: l.Summary.Remove,
+	}, nil
+}
+
+// Errors returns the number of error diagnostics.
+func (l *StructuredLog) Errors() (n int) {
+	for _, diag := range l.Diagnostics {
+		if diag.Severity == "error" {
+			n++
+		}
+	}
+	return
+}
+
+// Warnings returns the number of warning diagnostics.
+func (l *StructuredLog) Warnings() (n int) {
+	for _, diag := range l.Diagnostics {
+		if diag.Severity == "warning" {
+			n++
+		}
+	}
+	return
+}
+
+// ToHTML renders the message as HTML. ANSI escape sequences in plain text
+// lines are converted to HTML.
+func (m *LogMessage) ToHTML() string {
+	if m.Plain {
+		return string(term2html.Render([]byte(m.Text)))
+	}
+	return html.EscapeString(m.Text)
+}
+
+func (t *ResourceTimeline) add(msg *LogMessage) {
+	t.Messages = append(t.Messages, msg)
+	if t.ResourceType == "" && msg.Hook != nil {
+		t.ResourceType = msg.Hook.Resource.ResourceType
+	}
+	switch msg.Type {
+	case "planned_change":
+		t.Action = msg.Change.Action
+		t.Reason = msg.Change.Reason
+		t.Status = ResourcePlanned
+	case "resource_drift":
+		t.Drifted = true
+	case "refresh_start":
+		t.start(msg.Timestamp, ResourceRefreshing)
+	case "refresh_complete":
+		t.finish(msg.Timestamp, ResourceRefreshed)
+	case "apply_start":
+		t.start(msg.Timestamp, ResourceApplying)
+		if t.Action == "" {
+			t.Action = msg.Hook.Action
+		}
+	case "apply_complete":
+		t.finish(msg.Timestamp, ResourceApplied)
+	case "apply_errored":
+		t.finish(msg.Timestamp, ResourceErrored)
+	case "diagnostic":
+		t.Diagnostics = append(t.Diagnostics, msg.Diagnostic)
+		if msg.Diagnostic.Severity == "error" {
+			t.Status = ResourceErrored
+		}
+	}
+}
+
+func (t *ResourceTimeline) start(at time.Time, status ResourceStatus) {
+	if t.StartedAt.IsZero() {
+		t.StartedAt = at
+	}
+	t.FinishedAt = time.Time{}
+	t.Status = status
+}
+
+func (t *ResourceTimeline) finish(at time.Time, status ResourceStatus) {
+	t.FinishedAt = at
+	t.Status = status
+}
+
+// Elapsed returns the time spent operating on the resource. If an operation
+// is in progress then the time elapsed until now is returned.
+func (t *ResourceTimeline) Elapsed(now time.Time) time.Duration {
+	if t.StartedAt.IsZero() {
+		return 0
+	}
+	if t.FinishedAt.IsZero() {
+		return now.Sub(t.StartedAt)
+	}
+	return t.FinishedAt.Sub(t.StartedAt)
+}
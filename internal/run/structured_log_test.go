@@ -0,0 +1,94 @@
+package run
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStructuredLog(t *testing.T) {
+	t.Run("plan", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/plan_structured.txt")
+		require.NoError(t, err)
+
+		got := ParseStructuredLog(data)
+
+		// init output is retained as plain text
+		require.Len(t, got.Messages, 16)
+		assert.True(t, got.Messages[1].Plain)
+		assert.Equal(t, "Initializing the backend...", got.Messages[1].Text)
+		assert.False(t, got.Messages[8].Plain)
+		assert.Equal(t, "Terraform 1.9.0", got.Messages[8].Text)
+
+		require.Len(t, got.Resources, 2)
+
+		existing := got.Resources[0]
+		assert.Equal(t, "null_resource.existing", existing.Addr)
+		assert.Equal(t, "null_resource", existing.ResourceType)
+		assert.Equal(t, "replace", existing.Action)
+		assert.Equal(t, "tainted", existing.Reason)
+		assert.Equal(t, ResourcePlanned, existing.Status)
+		assert.Equal(t, 2*time.Second, existing.Elapsed(time.Now()))
+		assert.Len(t, existing.Messages, 3)
+
+		created := got.Resources[1]
+		assert.Equal(t, "null_resource.new", created.Addr)
+		assert.Equal(t, "create", created.Action)
+		require.Len(t, created.Diagnostics, 1)
+		assert.Equal(t, "Deprecated attribute", created.Diagnostics[0].Summary)
+		assert.Equal(t, "main.tf", created.Diagnostics[0].Range.Filename)
+		assert.Equal(t, 3, created.Diagnostics[0].Range.Start.Line)
+
+		assert.Equal(t, 1, got.Warnings())
+		assert.Equal(t, 0, got.Errors())
+		assert.Equal(t, &ChangeSummary{Add: 2, Remove: 1, Operation: "plan"}, got.Summary)
+		assert.Contains(t, got.Outputs, "id")
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/apply_structured.txt")
+		require.NoError(t, err)
+
+		got := ParseStructuredLog(data)
+
+		require.Len(t, got.Resources, 2)
+		assert.Equal(t, "delete", got.Resources[0].Action)
+		assert.Equal(t, ResourceApplied, got.Resources[0].Status)
+		assert.Equal(t, 2*time.Second, got.Resources[0].Elapsed(time.Now()))
+		assert.Equal(t, "create", got.Resources[1].Action)
+		assert.Equal(t, ResourceApplied, got.Resources[1].Status)
+		assert.Equal(t, 15*time.Second, got.Resources[1].Elapsed(time.Now()))
+
+		report, err := got.Report()
+		require.NoError(t, err)
+		assert.Equal(t, Report{Additions: 1, Destructions: 1}, report)
+	})
+
+	t.Run("apply in progress", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/apply_structured.txt")
+		require.NoError(t, err)
+
+		// Truncate logs midway through the apply of the second resource,
+		// leaving an incomplete trailing line.
+		data = append([]byte{STX}, data[:1800]...)
+
+		got := ParseStructuredLog(data)
+
+		require.Len(t, got.Resources, 2)
+		assert.Equal(t, ResourceApplying, got.Resources[1].Status)
+		assert.True(t, got.Resources[1].FinishedAt.IsZero())
+
+		_, err = got.Report()
+		assert.ErrorIs(t, err, ErrMissingChangeSummary)
+	})
+
+	t.Run("finished", func(t *testing.T) {
+		got := ParseStructuredLog([]byte{STX, 'e', 'r', 'r', 'o', 'r', ETX})
+
+		require.Len(t, got.Messages, 1)
+		assert.Equal(t, "error", got.Messages[0].Text)
+	})
+}
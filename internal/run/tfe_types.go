@@ -46,6 +46,7 @@ type TFERun struct {
 	CostEstimate         *types.CostEstimate                    `jsonapi:"relationship" json:"cost-estimate"`
 	CreatedBy            *user.TFEUser                          `jsonapi:"relationship" json:"created-by"`
 	Plan                 *TFEPlan                               `jsonapi:"relationship" json:"plan"`
+	PolicyChecks         []*types.PolicyCheck                   `jsonapi:"relationship" json:"policy-checks"`
 	Workspace            *TFEWorkspace                          `jsonapi:"relationship" json:"workspace"`
 }
 
@@ -96,6 +97,8 @@ type TFERunPermissions struct {
 	CanDiscard      bool `json:"can-discard"`
 	CanForceCancel  bool `json:"can-force-cancel"`
 	CanForceExecute bool `json:"can-force-execute"`
+
+	CanOverridePolicyCheck bool `json:"can-override-policy-check"`
 }
 
 // TFERunCreateOptions represents the options for creating a new run.
@@ -26,7 +26,7 @@ type TFEAPI struct {
 }
 
 type tfeClient interface {
-	CreateRunTrigger(ctx context.Context, workspaceID, sourceableWorkspaceID resource.TfeID) (*trigger.Trigger, error)
+	CreateRunTrigger(ctx context.Context, workspaceID, sourceableWorkspaceID resource.TfeID, conditions trigger.Conditions) (*trigger.Trigger, error)
 	ListRunTriggers(ctx context.Context, opts trigger.ListOptions) ([]*trigger.Trigger, error)
 	GetRunTrigger(ctx context.Context, triggerID resource.TfeID) (*trigger.Trigger, error)
 	DeleteRunTrigger(ctx context.Context, triggerID resource.TfeID) error
@@ -35,10 +35,14 @@ type tfeClient interface {
 
 // TFERunTrigger represents a run trigger in the TFE API.
 type TFERunTrigger struct {
-	ID         resource.TfeID          `jsonapi:"primary,run-triggers"`
-	CreatedAt  time.Time               `jsonapi:"attribute" json:"created-at"`
-	Workspace  *workspace.TFEWorkspace `jsonapi:"relationship" json:"workspace"`
-	Sourceable *workspace.TFEWorkspace `jsonapi:"relationship" json:"sourceable"`
+	ID        resource.TfeID `jsonapi:"primary,run-triggers"`
+	CreatedAt time.Time      `jsonapi:"attribute" json:"created-at"`
+	// Conditions are an OTF extension to the TFE API.
+	Outputs                []string                `jsonapi:"attribute" json:"outputs,omitempty"`
+	Branches               []string                `jsonapi:"attribute" json:"branches,omitempty"`
+	RequireResourceChanges bool                    `jsonapi:"attribute" json:"require-resource-changes"`
+	Workspace              *workspace.TFEWorkspace `jsonapi:"relationship" json:"workspace"`
+	Sourceable             *workspace.TFEWorkspace `jsonapi:"relationship" json:"sourceable"`
 }
 
 func NewTFEAPI(
@@ -75,6 +79,11 @@ func (a *TFEAPI) createRunTrigger(w http.ResponseWriter, r *http.Request) {
 
 		// The source workspace that triggers runs in the target workspace.
 		Sourceable *workspace.TFEWorkspace `jsonapi:"relationship" json:"sourceable"`
+
+		// Optional conditions, an OTF extension to the TFE API.
+		Outputs                []string `jsonapi:"attribute" json:"outputs,omitempty"`
+		Branches               []string `jsonapi:"attribute" json:"branches,omitempty"`
+		RequireResourceChanges bool     `jsonapi:"attribute" json:"require-resource-changes"`
 	}
 	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
 		tfeapi.Error(w, err)
@@ -85,9 +94,13 @@ func (a *TFEAPI) createRunTrigger(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	t, err := a.Client.CreateRunTrigger(r.Context(), workspaceID, params.Sourceable.ID)
+	t, err := a.Client.CreateRunTrigger(r.Context(), workspaceID, params.Sourceable.ID, trigger.Conditions{
+		Outputs:                params.Outputs,
+		Branches:               params.Branches,
+		RequireResourceChanges: params.RequireResourceChanges,
+	})
 	if err != nil {
-		if errors.Is(err, trigger.ErrTriggerLoop) {
+		if errors.Is(err, trigger.ErrTriggerLoop) || errors.Is(err, trigger.ErrTriggerCycle) {
 			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
 		} else {
 			tfeapi.Error(w, err)
@@ -151,8 +164,11 @@ func (a *TFEAPI) deleteRunTrigger(w http.ResponseWriter, r *http.Request) {
 
 func (a *TFEAPI) convert(from *trigger.Trigger) *TFERunTrigger {
 	return &TFERunTrigger{
-		ID:        from.ID,
-		CreatedAt: from.CreatedAt,
+		ID:                     from.ID,
+		CreatedAt:              from.CreatedAt,
+		Outputs:                from.Outputs,
+		Branches:               from.Branches,
+		RequireResourceChanges: from.RequireResourceChanges,
 		Workspace: &workspace.TFEWorkspace{
 			ID: from.WorkspaceID,
 		},
@@ -0,0 +1,92 @@
+package trigger
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+
+	"github.com/leg100/otf/internal/run"
+)
+
+// Conditions restrict the applied runs that trigger a run. A run is triggered
+// only if every condition is met. The zero value has no conditions, i.e. every
+// applied run triggers a run.
+type Conditions struct {
+	// Outputs restricts triggering to applies that changed at least one of
+	// the named outputs of the triggering workspace.
+	Outputs []string `db:"outputs"`
+	// Branches restricts triggering to runs whose configuration originates
+	// from a VCS branch matching at least one of the patterns, e.g. main or
+	// release/*. See path.Match for the pattern syntax.
+	Branches []string `db:"branches"`
+	// RequireResourceChanges restricts triggering to applies that changed at
+	// least one resource.
+	RequireResourceChanges bool `db:"require_resource_changes"`
+}
+
+// IsZero reports whether there are no conditions.
+func (c Conditions) IsZero() bool {
+	return len(c.Outputs) == 0 && len(c.Branches) == 0 && !c.RequireResourceChanges
+}
+
+func (c Conditions) validate() error {
+	for _, pattern := range c.Branches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern: %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// match determines whether an applied run meets the conditions. If not then
+// the reason is returned. The run's plan file is only retrieved if there is an
+// output condition.
+func (c Conditions) match(r *run.Run, planFile func() (*run.PlanFile, error)) (bool, string, error) {
+	if c.RequireResourceChanges {
+		if r.Apply.ResourceReport == nil || !r.Apply.ResourceReport.HasChanges() {
+			return false, "no resources changed", nil
+		}
+	}
+	if len(c.Branches) > 0 {
+		if r.IngressAttributes == nil {
+			return false, "run did not originate from a VCS branch", nil
+		}
+		if !slices.ContainsFunc(c.Branches, func(pattern string) bool {
+			matched, _ := path.Match(pattern, r.IngressAttributes.Branch)
+			return matched
+		}) {
+			return false, "branch does not match: " + r.IngressAttributes.Branch, nil
+		}
+	}
+	if len(c.Outputs) > 0 {
+		pf, err := planFile()
+		if err != nil {
+			return false, "", fmt.Errorf("retrieving plan file: %w", err)
+		}
+		if !slices.ContainsFunc(c.Outputs, func(name string) bool {
+			change, ok := pf.OutputChanges[name]
+			return ok && slices.ContainsFunc(change.Actions, func(action run.ChangeAction) bool {
+				return action == run.CreateAction || action == run.UpdateAction || action == run.DeleteAction
+			})
+		}) {
+			return false, "no specified outputs changed", nil
+		}
+	}
+	return true, "", nil
+}
+
+// LogValue implements slog.LogValuer.
+func (c Conditions) LogValue() slog.Value {
+	var attrs []slog.Attr
+	if len(c.Outputs) > 0 {
+		attrs = append(attrs, slog.Any("outputs", c.Outputs))
+	}
+	if len(c.Branches) > 0 {
+		attrs = append(attrs, slog.Any("branches", c.Branches))
+	}
+	if c.RequireResourceChanges {
+		attrs = append(attrs, slog.Bool("require_resource_changes", true))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -18,17 +18,26 @@ INSERT INTO run_triggers (
     run_trigger_id,
     created_at,
     workspace_id,
-    triggering_workspace_id
+    triggering_workspace_id,
+    outputs,
+    branches,
+    require_resource_changes
 ) VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    COALESCE($5::text[], '{}'),
+    COALESCE($6::text[], '{}'),
+    $7
 )`,
 		trigger.ID,
 		trigger.CreatedAt,
 		trigger.WorkspaceID,
 		trigger.TriggeringWorkspaceID,
+		trigger.Outputs,
+		trigger.Branches,
+		trigger.RequireResourceChanges,
 	)
 	if err != nil {
 		return err
@@ -36,6 +45,22 @@ INSERT INTO run_triggers (
 	return nil
 }
 
+func (db *pgdb) updateConditions(ctx context.Context, triggerID resource.ID, conditions Conditions) error {
+	_, err := db.Exec(ctx, `
+UPDATE run_triggers
+SET outputs = COALESCE($2::text[], '{}'),
+    branches = COALESCE($3::text[], '{}'),
+    require_resource_changes = $4
+WHERE run_trigger_id = $1
+`,
+		triggerID,
+		conditions.Outputs,
+		conditions.Branches,
+		conditions.RequireResourceChanges,
+	)
+	return err
+}
+
 func (db *pgdb) listByWorkspaceID(ctx context.Context, workspaceID resource.TfeID) ([]*Trigger, error) {
 	rows := db.Query(ctx, `
 SELECT *
@@ -54,6 +79,19 @@ WHERE run_triggers.triggering_workspace_id = $1
 	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Trigger])
 }
 
+// listByOrganization lists the triggers between workspaces in an
+// organization.
+func (db *pgdb) listByOrganization(ctx context.Context, organization resource.ID) ([]*Trigger, error) {
+	rows := db.Query(ctx, `
+SELECT rt.*
+FROM run_triggers rt
+JOIN workspaces w USING (workspace_id)
+WHERE w.organization_name = $1
+ORDER BY rt.created_at
+`, organization.String())
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Trigger])
+}
+
 func (db *pgdb) get(ctx context.Context, triggerID resource.ID) (*Trigger, error) {
 	row := db.Query(ctx, `
 SELECT *
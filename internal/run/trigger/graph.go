@@ -0,0 +1,106 @@
+package trigger
+
+import (
+	"slices"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+// Graph is a directed graph of the workspaces in an organization connected by
+// run triggers. Each edge points from the triggering workspace to the
+// triggered workspace.
+type Graph struct {
+	// Triggers are the edges of the graph.
+	Triggers []*Trigger
+
+	downstream map[resource.TfeID][]resource.TfeID
+	upstream   map[resource.TfeID][]resource.TfeID
+	// nodes in order of first appearance
+	nodes []resource.TfeID
+}
+
+func NewGraph(triggers []*Trigger) *Graph {
+	g := &Graph{
+		Triggers:   triggers,
+		downstream: make(map[resource.TfeID][]resource.TfeID),
+		upstream:   make(map[resource.TfeID][]resource.TfeID),
+	}
+	for _, t := range triggers {
+		g.addNode(t.TriggeringWorkspaceID)
+		g.addNode(t.WorkspaceID)
+		g.downstream[t.TriggeringWorkspaceID] = append(g.downstream[t.TriggeringWorkspaceID], t.WorkspaceID)
+		g.upstream[t.WorkspaceID] = append(g.upstream[t.WorkspaceID], t.TriggeringWorkspaceID)
+	}
+	return g
+}
+
+func (g *Graph) addNode(id resource.TfeID) {
+	if !slices.Contains(g.nodes, id) {
+		g.nodes = append(g.nodes, id)
+	}
+}
+
+// Path returns the workspaces on a path from one workspace to another,
+// following the direction of the triggers, including both the from and to
+// workspaces. Nil is returned if there is no such path.
+func (g *Graph) Path(from, to resource.TfeID) []resource.TfeID {
+	visited := make(map[resource.TfeID]bool)
+	var walk func(id resource.TfeID) []resource.TfeID
+	walk = func(id resource.TfeID) []resource.TfeID {
+		if id == to {
+			return []resource.TfeID{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		for _, next := range g.downstream[id] {
+			if path := walk(next); path != nil {
+				return append([]resource.TfeID{id}, path...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// Layers arranges the workspaces into layers, such that each workspace is
+// triggered only by workspaces in earlier layers. The first layer contains the
+// workspaces that are not triggered by any other workspace.
+//
+// Cycles are prevented when triggers are created, but should the graph
+// nonetheless contain a cycle then its workspaces are placed in a final layer.
+func (g *Graph) Layers() [][]resource.TfeID {
+	var (
+		layers   [][]resource.TfeID
+		inDegree = make(map[resource.TfeID]int, len(g.nodes))
+		placed   = make(map[resource.TfeID]bool, len(g.nodes))
+	)
+	for _, id := range g.nodes {
+		inDegree[id] = len(g.upstream[id])
+	}
+	for len(placed) < len(g.nodes) {
+		var layer []resource.TfeID
+		for _, id := range g.nodes {
+			if !placed[id] && inDegree[id] == 0 {
+				layer = append(layer, id)
+			}
+		}
+		if len(layer) == 0 {
+			// remaining workspaces are in a cycle
+			for _, id := range g.nodes {
+				if !placed[id] {
+					layer = append(layer, id)
+				}
+			}
+		}
+		for _, id := range layer {
+			placed[id] = true
+			for _, next := range g.downstream[id] {
+				inDegree[next]--
+			}
+		}
+		layers = append(layers, layer)
+	}
+	return layers
+}
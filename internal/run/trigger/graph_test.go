@@ -0,0 +1,43 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	var (
+		ws1 = resource.NewTfeID(resource.WorkspaceKind)
+		ws2 = resource.NewTfeID(resource.WorkspaceKind)
+		ws3 = resource.NewTfeID(resource.WorkspaceKind)
+		ws4 = resource.NewTfeID(resource.WorkspaceKind)
+	)
+	// ws1 -> ws2 -> ws3
+	//    \-------------> ws4
+	g := NewGraph([]*Trigger{
+		{TriggeringWorkspaceID: ws1, WorkspaceID: ws2},
+		{TriggeringWorkspaceID: ws2, WorkspaceID: ws3},
+		{TriggeringWorkspaceID: ws1, WorkspaceID: ws4},
+	})
+
+	t.Run("path", func(t *testing.T) {
+		assert.Equal(t, []resource.TfeID{ws1, ws2, ws3}, g.Path(ws1, ws3))
+		assert.Nil(t, g.Path(ws3, ws1))
+		assert.Nil(t, g.Path(ws4, ws3))
+	})
+
+	t.Run("layers", func(t *testing.T) {
+		assert.Equal(t, [][]resource.TfeID{{ws1}, {ws2, ws4}, {ws3}}, g.Layers())
+	})
+
+	t.Run("layers with cycle", func(t *testing.T) {
+		g := NewGraph([]*Trigger{
+			{TriggeringWorkspaceID: ws1, WorkspaceID: ws2},
+			{TriggeringWorkspaceID: ws2, WorkspaceID: ws3},
+			{TriggeringWorkspaceID: ws3, WorkspaceID: ws2},
+		})
+		assert.Equal(t, [][]resource.TfeID{{ws1}, {ws2, ws3}}, g.Layers())
+	})
+}
@@ -3,6 +3,7 @@ package trigger
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/logr"
@@ -67,7 +68,7 @@ func NewService(opts Options) *Service {
 	return svc
 }
 
-func (s *Service) CreateRunTrigger(ctx context.Context, workspaceID, triggeringWorkspaceID resource.TfeID) (*Trigger, error) {
+func (s *Service) CreateRunTrigger(ctx context.Context, workspaceID, triggeringWorkspaceID resource.TfeID, conditions Conditions) (*Trigger, error) {
 	// User must have appropriate perm on the specified workspace and permission
 	// to read runs for the triggering workspace.
 	subject, err := s.authorizer.Authorize(ctx, resource.Create, resource.RunTriggerKind, workspaceID)
@@ -79,19 +80,88 @@ func (s *Service) CreateRunTrigger(ctx context.Context, workspaceID, triggeringW
 		return nil, err
 	}
 
-	t, err := newTrigger(workspaceID, triggeringWorkspaceID)
+	t, err := newTrigger(workspaceID, triggeringWorkspaceID, conditions)
 	if err != nil {
 		s.logger.Error(err, "constructing run trigger", "subject", subject)
 		return nil, err
 	}
-	if err := s.db.create(ctx, t); err != nil {
+	// Lock table to prevent a concurrent insert from creating a cycle.
+	err = s.db.Lock(ctx, "run_triggers", func(ctx context.Context) error {
+		if err := s.checkCycle(ctx, t); err != nil {
+			return err
+		}
+		return s.db.create(ctx, t)
+	})
+	if err != nil {
 		s.logger.Error(err, "creating run trigger", "subject", subject)
 		return nil, err
 	}
-	s.logger.V(0).Info("created run trigger", "trigger", t.ID, "workspace_id", workspaceID, "triggering_workspace_id", triggeringWorkspaceID, "subject", subject)
+	s.logger.V(0).Info("created run trigger", "trigger", t, "subject", subject)
 	return t, nil
 }
 
+// checkCycle returns an error if the trigger would create a cycle, i.e. the
+// triggering workspace is itself triggered, directly or indirectly, by the
+// workspace.
+func (s *Service) checkCycle(ctx context.Context, t *Trigger) error {
+	org, err := s.authorizer.ResolveOrganization(ctx, t.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	triggers, err := s.db.listByOrganization(ctx, org)
+	if err != nil {
+		return err
+	}
+	path := NewGraph(triggers).Path(t.WorkspaceID, t.TriggeringWorkspaceID)
+	if path == nil {
+		return nil
+	}
+	// complete the cycle with the new trigger
+	path = append(path, t.WorkspaceID)
+	ids := make([]string, len(path))
+	for i, id := range path {
+		ids[i] = id.String()
+	}
+	return fmt.Errorf("%w: %s", ErrTriggerCycle, strings.Join(ids, " -> "))
+}
+
+// UpdateRunTrigger updates the conditions of a run trigger.
+func (s *Service) UpdateRunTrigger(ctx context.Context, triggerID resource.TfeID, conditions Conditions) (*Trigger, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.Update, resource.RunTriggerKind, triggerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := conditions.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.db.updateConditions(ctx, triggerID, conditions); err != nil {
+		s.logger.Error(err, "updating run trigger", "trigger", triggerID, "subject", subject)
+		return nil, err
+	}
+	t, err := s.db.get(ctx, triggerID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.V(0).Info("updated run trigger", "trigger", t, "subject", subject)
+	return t, nil
+}
+
+// GetRunTriggerGraph retrieves the graph of run triggers between the
+// workspaces in an organization.
+func (s *Service) GetRunTriggerGraph(ctx context.Context, organization resource.ID) (*Graph, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.List, resource.RunTriggerKind, organization)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := s.db.listByOrganization(ctx, organization)
+	if err != nil {
+		s.logger.Error(err, "retrieving run trigger graph", "organization", organization, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(9).Info("retrieved run trigger graph", "organization", organization, "triggers", len(triggers), "subject", subject)
+	return NewGraph(triggers), nil
+}
+
 func (s *Service) ListRunTriggers(ctx context.Context, opts ListOptions) ([]*Trigger, error) {
 	subject, err := s.authorizer.Authorize(ctx, resource.List, resource.RunTriggerKind, opts.WorkspaceID)
 	if err != nil {
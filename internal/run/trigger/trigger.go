@@ -10,24 +10,33 @@ import (
 	"github.com/leg100/otf/internal/resource"
 )
 
-var ErrTriggerLoop = errors.New("workspace cannot trigger itself")
+var (
+	ErrTriggerLoop  = errors.New("workspace cannot trigger itself")
+	ErrTriggerCycle = errors.New("run trigger would create a cycle")
+)
 
 type Trigger struct {
 	ID                    resource.TfeID `db:"run_trigger_id"`
 	CreatedAt             time.Time      `db:"created_at"`
 	WorkspaceID           resource.TfeID `db:"workspace_id"`
 	TriggeringWorkspaceID resource.TfeID `db:"triggering_workspace_id"`
+
+	Conditions
 }
 
-func newTrigger(workspaceID, triggeringWorkspaceID resource.TfeID) (*Trigger, error) {
+func newTrigger(workspaceID, triggeringWorkspaceID resource.TfeID, conditions Conditions) (*Trigger, error) {
 	if workspaceID == triggeringWorkspaceID {
 		return nil, fmt.Errorf("%w: %s", ErrTriggerLoop, workspaceID)
 	}
+	if err := conditions.validate(); err != nil {
+		return nil, err
+	}
 	return &Trigger{
 		ID:                    resource.NewTfeID(resource.RunTriggerKind),
 		CreatedAt:             internal.CurrentTimestamp(nil),
 		WorkspaceID:           workspaceID,
 		TriggeringWorkspaceID: triggeringWorkspaceID,
+		Conditions:            conditions,
 	}, nil
 }
 
@@ -37,5 +46,6 @@ func (t *Trigger) LogValue() slog.Value {
 		slog.String("id", t.ID.String()),
 		slog.Any("workspace_id", t.WorkspaceID),
 		slog.Any("triggering_workspace_id", t.TriggeringWorkspaceID),
+		slog.Any("conditions", t.Conditions),
 	)
 }
@@ -2,6 +2,7 @@ package trigger
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/leg100/otf/internal/configversion/source"
@@ -22,6 +23,8 @@ type client interface {
 	ListRunTriggers(ctx context.Context, opts ListOptions) ([]*Trigger, error)
 	WatchRuns(context.Context) (<-chan pubsub.Event[*run.Event], func(), error)
 	CreateRun(context.Context, resource.TfeID, run.CreateOptions) (*run.Run, error)
+	GetRun(context.Context, resource.TfeID) (*run.Run, error)
+	GetRunPlanFile(ctx context.Context, runID resource.TfeID, format run.PlanFormat) ([]byte, error)
 }
 
 func (t *Triggerer) Start(ctx context.Context) error {
@@ -64,7 +67,52 @@ func (t *Triggerer) process(ctx context.Context, runEvent pubsub.Event[*run.Even
 		return fmt.Errorf("listing triggers for finished run: %w", err)
 	}
 
+	// The finished run and its plan file are only retrieved if needed to check
+	// trigger conditions, and then only once.
+	var (
+		finished *run.Run
+		planFile *run.PlanFile
+	)
+	getPlanFile := func() (*run.PlanFile, error) {
+		if planFile != nil {
+			return planFile, nil
+		}
+		b, err := t.Client.GetRunPlanFile(ctx, runEvent.Payload.ID, run.PlanFormatJSON)
+		if err != nil {
+			return nil, err
+		}
+		planFile = &run.PlanFile{}
+		if err := json.Unmarshal(b, planFile); err != nil {
+			return nil, err
+		}
+		return planFile, nil
+	}
+
 	for _, trigger := range triggers {
+		if !trigger.Conditions.IsZero() {
+			if finished == nil {
+				finished, err = t.Client.GetRun(ctx, runEvent.Payload.ID)
+				if err != nil {
+					return fmt.Errorf("retrieving finished run: %w", err)
+				}
+			}
+			matched, reason, err := trigger.Conditions.match(finished, getPlanFile)
+			if err != nil {
+				// Skip trigger rather than stop triggering altogether.
+				t.Logger.Error(err, "checking trigger conditions", "trigger", trigger, "triggering_run_id", runEvent.Payload.ID)
+				continue
+			}
+			if !matched {
+				t.Logger.V(1).Info(
+					"skipping run trigger: conditions not met",
+					"trigger", trigger,
+					"triggering_run_id", runEvent.Payload.ID,
+					"reason", reason,
+				)
+				continue
+			}
+		}
+
 		t.Logger.Info(
 			"triggering run in connected workspace",
 			"trigger", trigger,
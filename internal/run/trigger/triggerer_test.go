@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/pubsub"
 	"github.com/leg100/otf/internal/resource"
@@ -21,6 +22,8 @@ func TestTriggerer_process(t *testing.T) {
 		name      string
 		event     pubsub.Event[*run.Event]
 		triggers  []*Trigger
+		run       *run.Run
+		planFile  string
 		wantRuns  int
 		wantError bool
 	}{
@@ -112,11 +115,74 @@ func TestTriggerer_process(t *testing.T) {
 			},
 			wantRuns: 2,
 		},
+		{
+			name: "skips trigger requiring resource changes when there are none",
+			event: pubsub.Event[*run.Event]{
+				Type:    pubsub.UpdatedEvent,
+				Payload: &run.Event{Status: runstatus.Applied, WorkspaceID: ws1},
+			},
+			triggers: []*Trigger{
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{RequireResourceChanges: true}},
+			},
+			run:      &run.Run{Apply: run.Phase{ResourceReport: &run.Report{}}},
+			wantRuns: 0,
+		},
+		{
+			name: "creates run when resources changed",
+			event: pubsub.Event[*run.Event]{
+				Type:    pubsub.UpdatedEvent,
+				Payload: &run.Event{Status: runstatus.Applied, WorkspaceID: ws1},
+			},
+			triggers: []*Trigger{
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{RequireResourceChanges: true}},
+			},
+			run:      &run.Run{Apply: run.Phase{ResourceReport: &run.Report{Additions: 1}}},
+			wantRuns: 1,
+		},
+		{
+			name: "creates run only for triggers with matching branch",
+			event: pubsub.Event[*run.Event]{
+				Type:    pubsub.UpdatedEvent,
+				Payload: &run.Event{Status: runstatus.Applied, WorkspaceID: ws1},
+			},
+			triggers: []*Trigger{
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{Branches: []string{"release/*"}}},
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{Branches: []string{"main"}}},
+			},
+			run:      &run.Run{IngressAttributes: &configversion.IngressAttributes{Branch: "release/v1"}},
+			wantRuns: 1,
+		},
+		{
+			name: "skips trigger with branch condition for non-vcs run",
+			event: pubsub.Event[*run.Event]{
+				Type:    pubsub.UpdatedEvent,
+				Payload: &run.Event{Status: runstatus.Applied, WorkspaceID: ws1},
+			},
+			triggers: []*Trigger{
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{Branches: []string{"main"}}},
+			},
+			run:      &run.Run{},
+			wantRuns: 0,
+		},
+		{
+			name: "creates run only for triggers with changed outputs",
+			event: pubsub.Event[*run.Event]{
+				Type:    pubsub.UpdatedEvent,
+				Payload: &run.Event{Status: runstatus.Applied, WorkspaceID: ws1},
+			},
+			triggers: []*Trigger{
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{Outputs: []string{"vpc_id"}}},
+				{WorkspaceID: ws2, TriggeringWorkspaceID: ws1, Conditions: Conditions{Outputs: []string{"subnet_id"}}},
+			},
+			run:      &run.Run{},
+			planFile: `{"output_changes":{"vpc_id":{"actions":["update"]},"subnet_id":{"actions":["no-op"]}}}`,
+			wantRuns: 1,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fake := &fakeTriggererClient{triggers: tt.triggers}
+			fake := &fakeTriggererClient{triggers: tt.triggers, run: tt.run, planFile: tt.planFile}
 			triggerer := &Triggerer{
 				Client: fake,
 				Logger: logr.Discard(),
@@ -169,6 +235,8 @@ func TestTriggerer_Start(t *testing.T) {
 type fakeTriggererClient struct {
 	events   <-chan pubsub.Event[*run.Event]
 	triggers []*Trigger
+	run      *run.Run
+	planFile string
 	// createdRuns records workspace IDs for which CreateRun was called.
 	createdRuns []resource.TfeID
 }
@@ -185,3 +253,11 @@ func (f *fakeTriggererClient) CreateRun(ctx context.Context, workspaceID resourc
 	f.createdRuns = append(f.createdRuns, workspaceID)
 	return &run.Run{ID: resource.NewTfeID(resource.RunKind)}, nil
 }
+
+func (f *fakeTriggererClient) GetRun(ctx context.Context, runID resource.TfeID) (*run.Run, error) {
+	return f.run, nil
+}
+
+func (f *fakeTriggererClient) GetRunPlanFile(ctx context.Context, runID resource.TfeID, format run.PlanFormat) ([]byte, error) {
+	return []byte(f.planFile), nil
+}
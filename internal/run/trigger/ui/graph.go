@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run/trigger"
+)
+
+// Dimensions of the rendered graph, in pixels.
+const (
+	nodeWidth    = 180
+	nodeHeight   = 36
+	columnGap    = 80
+	rowGap       = 24
+	graphPadding = 16
+
+	maxLabelLength = 22
+)
+
+// graphLayout positions the workspaces of a run trigger graph for rendering as
+// an SVG, with a column for each layer of the graph, flowing left to right.
+type graphLayout struct {
+	Width, Height int
+	Nodes         []graphNode
+	Edges         []graphEdge
+}
+
+type graphNode struct {
+	X, Y int
+	Name string
+	// Label is the name truncated to fit the node.
+	Label string
+	URL   string
+}
+
+type graphEdge struct {
+	X1, Y1, X2, Y2 int
+	// Conditional is true if the trigger has conditions.
+	Conditional bool
+}
+
+func newGraphLayout(graph *trigger.Graph, names map[resource.TfeID]string) graphLayout {
+	var (
+		layout    graphLayout
+		positions = make(map[resource.TfeID]graphNode)
+	)
+	for i, layer := range graph.Layers() {
+		for j, id := range layer {
+			node := graphNode{
+				X:   graphPadding + i*(nodeWidth+columnGap),
+				Y:   graphPadding + j*(nodeHeight+rowGap),
+				URL: path.Resource(resource.Action("edit-triggers"), id),
+			}
+			node.Name = names[id]
+			if node.Name == "" {
+				node.Name = id.String()
+			}
+			node.Label = node.Name
+			if len(node.Label) > maxLabelLength {
+				node.Label = node.Label[:maxLabelLength-1] + "…"
+			}
+			positions[id] = node
+			layout.Nodes = append(layout.Nodes, node)
+			layout.Width = max(layout.Width, node.X+nodeWidth+graphPadding)
+			layout.Height = max(layout.Height, node.Y+nodeHeight+graphPadding)
+		}
+	}
+	for _, t := range graph.Triggers {
+		from, to := positions[t.TriggeringWorkspaceID], positions[t.WorkspaceID]
+		layout.Edges = append(layout.Edges, graphEdge{
+			X1:          from.X + nodeWidth,
+			Y1:          from.Y + nodeHeight/2,
+			X2:          to.X,
+			Y2:          to.Y + nodeHeight/2,
+			Conditional: !t.Conditions.IsZero(),
+		})
+	}
+	return layout
+}
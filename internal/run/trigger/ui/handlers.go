@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/http/decode"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/path"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/run/trigger"
@@ -23,7 +25,9 @@ type Handlers struct {
 }
 
 type Client interface {
-	CreateRunTrigger(ctx context.Context, workspaceID, triggeringWorkspaceID resource.TfeID) (*trigger.Trigger, error)
+	CreateRunTrigger(ctx context.Context, workspaceID, triggeringWorkspaceID resource.TfeID, conditions trigger.Conditions) (*trigger.Trigger, error)
+	UpdateRunTrigger(ctx context.Context, triggerID resource.TfeID, conditions trigger.Conditions) (*trigger.Trigger, error)
+	GetRunTriggerGraph(ctx context.Context, organization resource.ID) (*trigger.Graph, error)
 	ListRunTriggers(ctx context.Context, opts trigger.ListOptions) ([]*trigger.Trigger, error)
 	GetRunTrigger(ctx context.Context, triggerID resource.TfeID) (*trigger.Trigger, error)
 	DeleteRunTrigger(ctx context.Context, triggerID resource.TfeID) error
@@ -47,7 +51,9 @@ func NewHandlers(
 func (h *Handlers) AddHandlers(r *mux.Router) {
 	r.HandleFunc("/workspaces/{workspace_id}/edit-triggers", h.editTriggers).Methods("GET")
 	r.HandleFunc("/workspaces/{workspace_id}/triggers/create", h.createTrigger).Methods("POST")
+	r.HandleFunc("/triggers/{trigger_id}/update", h.updateTrigger).Methods("POST")
 	r.HandleFunc("/triggers/{trigger_id}/delete", h.deleteTrigger).Methods("POST")
+	r.HandleFunc("/organizations/{organization_name}/triggers", h.graph).Methods("GET")
 	r.HandleFunc("/workspaces/{workspace_id}/update-auto-apply-run-trigger", h.updateAutoApply).Methods("POST")
 }
 
@@ -134,17 +140,43 @@ func (h *Handlers) editTriggers(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+// conditionsParams are the form parameters for trigger conditions.
+type conditionsParams struct {
+	// Outputs and Branches are comma-separated lists.
+	Outputs                string `schema:"outputs"`
+	Branches               string `schema:"branches"`
+	RequireResourceChanges bool   `schema:"require_resource_changes"`
+}
+
+func (p conditionsParams) conditions() trigger.Conditions {
+	return trigger.Conditions{
+		Outputs:                splitList(p.Outputs),
+		Branches:               splitList(p.Branches),
+		RequireResourceChanges: p.RequireResourceChanges,
+	}
+}
+
+func splitList(s string) (list []string) {
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
 func (h *Handlers) createTrigger(w http.ResponseWriter, r *http.Request) {
 	var params struct {
 		WorkspaceID           resource.TfeID `schema:"workspace_id,required"`
 		TriggeringWorkspaceID resource.TfeID `schema:"triggering_workspace_id,required"`
+		conditionsParams
 	}
 	if err := decode.All(&params, r); err != nil {
 		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
 		return
 	}
 
-	trigger, err := h.client.CreateRunTrigger(r.Context(), params.WorkspaceID, params.TriggeringWorkspaceID)
+	trigger, err := h.client.CreateRunTrigger(r.Context(), params.WorkspaceID, params.TriggeringWorkspaceID, params.conditions())
 	if err != nil {
 		helpers.Error(r, w, err.Error())
 		return
@@ -154,6 +186,69 @@ func (h *Handlers) createTrigger(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, path.Resource(resource.Action("edit-triggers"), params.WorkspaceID), http.StatusFound)
 }
 
+func (h *Handlers) updateTrigger(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		TriggerID resource.TfeID `schema:"trigger_id,required"`
+		conditionsParams
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	trigger, err := h.client.UpdateRunTrigger(r.Context(), params.TriggerID, params.conditions())
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated trigger conditions: "+trigger.ID.String())
+	http.Redirect(w, r, path.Resource(resource.Action("edit-triggers"), trigger.WorkspaceID), http.StatusFound)
+}
+
+func (h *Handlers) graph(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	graph, err := h.client.GetRunTriggerGraph(r.Context(), params.Organization)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	// Retrieve workspaces in order to label the graph with workspace names.
+	workspaces, err := resource.ListAll(func(opts resource.PageOptions) (*resource.Page[*workspace.Workspace], error) {
+		return h.client.ListWorkspaces(r.Context(), workspace.ListOptions{
+			PageOptions:  opts,
+			Organization: &params.Organization,
+		})
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	names := make(map[resource.TfeID]string, len(workspaces))
+	for _, ws := range workspaces {
+		names[ws.ID] = ws.Name
+	}
+
+	helpers.RenderPage(
+		triggerGraph(newGraphLayout(graph, names)),
+		"run triggers",
+		w,
+		r,
+		helpers.WithOrganization(params.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Run Triggers"},
+		),
+	)
+}
+
 func (h *Handlers) deleteTrigger(w http.ResponseWriter, r *http.Request) {
 	triggerID, err := decode.ID("trigger_id", r)
 	if err != nil {
@@ -3,10 +3,13 @@ package ui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/configversion/source"
 	"github.com/leg100/otf/internal/http/decode"
@@ -23,6 +26,10 @@ import (
 
 const LatestRunUpdate sseEvent = "LatestRunUpdate"
 
+// stopPolling is the status code with which a response instructs htmx to stop
+// polling.
+const stopPolling = 286
+
 type Handlers struct {
 	authorizer authz.Interface
 	logger     logr.Logger
@@ -35,6 +42,7 @@ type Client interface {
 	ListRuns(_ context.Context, opts runpkg.ListOptions) (*resource.Page[*runpkg.Run], error)
 	GetRun(ctx context.Context, id resource.TfeID) (*runpkg.Run, error)
 	GetChunk(ctx context.Context, opts runpkg.GetChunkOptions) (runpkg.Chunk, error)
+	GetPlanDiff(ctx context.Context, id resource.TfeID, opts runpkg.PlanDiffOptions) (*runpkg.PlanDiff, error)
 	CancelRun(ctx context.Context, id resource.TfeID) error
 	ForceCancelRun(ctx context.Context, id resource.TfeID) error
 	DiscardRun(ctx context.Context, id resource.TfeID) error
@@ -88,6 +96,8 @@ func (h *Handlers) AddHandlers(r *mux.Router) {
 	r.HandleFunc("/runs/{run_id}/retry", h.retryRun).Methods("POST")
 	r.HandleFunc("/runs/{run_id}/watch", h.watchRun).Methods("GET")
 	r.HandleFunc("/runs/{run_id}/tail", h.tailRun)
+	r.HandleFunc("/runs/{run_id}/structured-log", h.getStructuredLog).Methods("GET")
+	r.HandleFunc("/runs/{run_id}/plan-diff", h.getPlanDiff).Methods("GET")
 	// this handles the link the terraform CLI shows during a plan/apply.
 	r.HandleFunc("/{organization_name}/{workspace_id}/runs/{run_id}", h.getRun).Methods("GET")
 }
@@ -536,6 +546,70 @@ func (h *Handlers) watchLatestRun(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getStructuredLog renders the structured output of a run phase, either as a
+// timeline or as plain text, for embedding in the run page. The page polls for
+// the output until the phase has finished.
+func (h *Handlers) getStructuredLog(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		RunID resource.TfeID   `schema:"run_id,required"`
+		Phase runpkg.PhaseType `schema:"phase,required"`
+		Plain bool             `schema:"plain"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	run, err := h.client.GetRun(r.Context(), params.RunID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	chunk, err := h.client.GetChunk(r.Context(), runpkg.GetChunkOptions{
+		RunID: params.RunID,
+		Phase: params.Phase,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	log := runpkg.ParseStructuredLog(chunk.Data)
+
+	comp := structuredLog(log, time.Now())
+	if params.Plain {
+		comp = plainLog(log)
+	}
+	if chunk.IsEnd() || run.Done() {
+		w.WriteHeader(stopPolling)
+	}
+	if err := helpers.RenderSnippet(comp, w, r); err != nil {
+		h.logger.Error(err, "rendering structured log", "run_id", params.RunID)
+	}
+}
+
+func (h *Handlers) getPlanDiff(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		RunID resource.TfeID `schema:"run_id,required"`
+		runpkg.PlanDiffOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	diff, err := h.client.GetPlanDiff(r.Context(), params.RunID, params.PlanDiffOptions)
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		// The plan has yet to finish and upload the plan file.
+		diff = nil
+	} else if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	if err := helpers.RenderSnippet(planDiff(diff), w, r); err != nil {
+		h.logger.Error(err, "rendering plan diff", "run_id", params.RunID)
+	}
+}
+
 const (
 	EventLogChunk    sseEvent = "log_update"
 	EventLogFinished sseEvent = "log_finished"
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/a-h/templ"
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/configversion/source"
@@ -151,11 +152,81 @@ func TestTailLogs(t *testing.T) {
 	<-done
 }
 
+func TestGetStructuredLog(t *testing.T) {
+	msg := `{"@level":"info","@message":"null_resource.new: Creating...","@timestamp":"2024-06-26T10:01:03.000000Z","hook":{"resource":{"addr":"null_resource.new","resource_type":"null_resource"},"action":"create"},"type":"apply_start"}` + "\n"
+
+	t.Run("in progress", func(t *testing.T) {
+		h := &Handlers{
+			client: &fakeRunClient{
+				run:   &run.Run{ID: testutils.ParseID(t, "run-1")},
+				chunk: run.Chunk{Data: append([]byte{run.STX}, msg...)},
+			},
+		}
+
+		r := httptest.NewRequest("GET", "/?phase=apply&run_id=run-1", nil)
+		w := httptest.NewRecorder()
+		h.getStructuredLog(w, r)
+
+		assert.Equal(t, 200, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "null_resource.new")
+		assert.Contains(t, w.Body.String(), "applying")
+	})
+
+	t.Run("finished", func(t *testing.T) {
+		h := &Handlers{
+			client: &fakeRunClient{
+				run:   &run.Run{ID: testutils.ParseID(t, "run-1")},
+				chunk: run.Chunk{Data: append(append([]byte{run.STX}, msg...), run.ETX)},
+			},
+		}
+
+		r := httptest.NewRequest("GET", "/?phase=apply&run_id=run-1&plain=true", nil)
+		w := httptest.NewRecorder()
+		h.getStructuredLog(w, r)
+
+		assert.Equal(t, stopPolling, w.Code, w.Body.String())
+		assert.Equal(t, "null_resource.new: Creating...<br>", w.Body.String())
+	})
+}
+
+func TestGetPlanDiff(t *testing.T) {
+	planJSON := []byte(`{"resource_changes": [
+		{"address": "null_resource.new", "change": {"actions": ["create"], "after": {"triggers": {"id": "new"}}}},
+		{"address": "null_resource.old", "change": {"actions": ["delete"], "before": {"triggers": {"id": "old"}}}}
+	]}`)
+
+	t.Run("filter by action", func(t *testing.T) {
+		h := &Handlers{client: &fakeRunClient{planJSON: planJSON}}
+
+		r := httptest.NewRequest("GET", "/?run_id=run-1&action=create", nil)
+		w := httptest.NewRecorder()
+		h.getPlanDiff(w, r)
+
+		assert.Equal(t, 200, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "null_resource.new")
+		assert.Contains(t, w.Body.String(), "triggers.id")
+		assert.NotContains(t, w.Body.String(), "null_resource.old")
+	})
+
+	t.Run("plan not finished", func(t *testing.T) {
+		h := &Handlers{client: &fakeRunClient{}}
+
+		r := httptest.NewRequest("GET", "/?run_id=run-1", nil)
+		w := httptest.NewRecorder()
+		h.getPlanDiff(w, r)
+
+		assert.Equal(t, 200, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "The plan has not yet finished.")
+	})
+}
+
 type fakeRunClient struct {
 	Client
 	run    *run.Run
 	ws     *workspace.Workspace
-	chunks chan run.Chunk
+	chunk    run.Chunk
+	chunks   chan run.Chunk
+	planJSON []byte
 }
 
 func (f *fakeRunClient) ListRuns(_ context.Context, opts run.ListOptions) (*resource.Page[*run.Run], error) {
@@ -167,7 +238,14 @@ func (f *fakeRunClient) GetRun(ctx context.Context, id resource.TfeID) (*run.Run
 }
 
 func (f *fakeRunClient) GetChunk(ctx context.Context, opts run.GetChunkOptions) (run.Chunk, error) {
-	return run.Chunk{}, nil
+	return f.chunk, nil
+}
+
+func (f *fakeRunClient) GetPlanDiff(ctx context.Context, id resource.TfeID, opts run.PlanDiffOptions) (*run.PlanDiff, error) {
+	if f.planJSON == nil {
+		return nil, internal.ErrResourceNotFound
+	}
+	return run.NewPlanDiff(f.planJSON, opts)
 }
 
 func (f *fakeRunClient) CancelRun(ctx context.Context, id resource.TfeID) error {
@@ -1,11 +1,15 @@
 package runner
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/pubsub"
 	"github.com/leg100/otf/internal/resource"
 )
@@ -23,6 +27,17 @@ type allocator struct {
 	jobs map[resource.TfeID]*Job
 	// total current jobs allocated to each runner keyed by runner ID
 	currentJobs map[resource.TfeID]int
+	// organizationQuota is the maximum number of jobs belonging to an
+	// organization that server runners may execute concurrently. Zero means
+	// there is no quota.
+	organizationQuota int
+	// total current jobs allocated to runners keyed by organization, for all
+	// runners, and for server runners only.
+	organizationJobs       map[organization.Name]int
+	organizationServerJobs map[organization.Name]int
+	// queue of unallocated jobs last persisted; nil until persisted for the
+	// first time.
+	queue []QueuePosition
 }
 
 type allocatorClient interface {
@@ -36,6 +51,8 @@ type allocatorClient interface {
 
 	allocateJob(ctx context.Context, jobID, runnerID resource.TfeID) (*Job, error)
 	reallocateJob(ctx context.Context, jobID, runnerID resource.TfeID) (*Job, error)
+	deallocateJob(ctx context.Context, jobID resource.TfeID) (*Job, error)
+	setJobQueue(ctx context.Context, queue []QueuePosition) error
 }
 
 // Start the allocator. Should be invoked in a go routine.
@@ -63,10 +80,8 @@ func (a *allocator) Start(ctx context.Context) error {
 	a.seed(runners, jobs)
 
 	// allocate jobs to runners
-	for _, job := range a.jobs {
-		if err := a.allocate(ctx, job); err != nil {
-			return err
-		}
+	if err := a.schedule(ctx); err != nil {
+		return err
 	}
 
 	// consume events until a subscriber is closed, and allocate jobs.
@@ -108,7 +123,7 @@ func (a *allocator) Start(ctx context.Context) error {
 				// runner then decrement its current run tally.
 				if job, ok := a.jobs[event.Payload.ID]; ok {
 					if job.RunnerID != nil {
-						a.decrementCurrentJobs(*job.RunnerID)
+						a.releaseJob(job)
 					}
 				}
 				delete(a.jobs, event.Payload.ID)
@@ -130,10 +145,8 @@ func (a *allocator) Start(ctx context.Context) error {
 				a.jobs[event.Payload.ID] = job
 			}
 		}
-		for _, job := range a.jobs {
-			if err := a.allocate(ctx, job); err != nil {
-				return err
-			}
+		if err := a.schedule(ctx); err != nil {
+			return err
 		}
 	}
 }
@@ -153,10 +166,93 @@ func (a *allocator) seed(runners []*RunnerMeta, jobs []*Job) {
 		}
 		a.jobs[job.ID] = job
 	}
+	a.organizationJobs = make(map[organization.Name]int)
+	a.organizationServerJobs = make(map[organization.Name]int)
+	for _, job := range a.jobs {
+		if job.RunnerID != nil {
+			a.incrementOrganizationJobs(job)
+		}
+	}
+}
+
+// schedule allocates jobs to runners in order of priority, and records the
+// position in the queue of those jobs that remain unallocated.
+func (a *allocator) schedule(ctx context.Context) error {
+	// First handle jobs that are no longer awaiting allocation, freeing up the
+	// capacity of runners with completed jobs and re-allocating jobs away from
+	// unhealthy runners.
+	var pending []*Job
+	for _, job := range a.jobs {
+		if job.Status == JobUnallocated {
+			pending = append(pending, job)
+			continue
+		}
+		if _, err := a.allocate(ctx, job); err != nil {
+			return err
+		}
+	}
+	// Then allocate unallocated jobs, highest priority first. The order is
+	// determined afresh after each allocation because the fair share of each
+	// organization changes as its jobs are allocated.
+	var (
+		queue     []QueuePosition
+		positions = make(map[queueKey]int)
+	)
+	for len(pending) > 0 {
+		next := slices.MinFunc(pending, a.compareJobs)
+		pending = slices.DeleteFunc(pending, func(job *Job) bool { return job == next })
+
+		reason, err := a.allocate(ctx, next)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			key := queueKeyForJob(next)
+			positions[key]++
+			queue = append(queue, QueuePosition{
+				JobID:    next.ID,
+				Position: positions[key],
+				Reason:   reason,
+			})
+		}
+	}
+	// Persist queue only if it has changed since it was last persisted.
+	if a.queue != nil && slices.Equal(a.queue, queue) {
+		return nil
+	}
+	if err := a.client.setJobQueue(ctx, queue); err != nil {
+		return fmt.Errorf("updating job queue: %w", err)
+	}
+	a.queue = append([]QueuePosition{}, queue...)
+	return nil
+}
+
+// compareJobs determines the order in which unallocated jobs are allocated:
+//
+// 1. Applies, then plans, and then speculative plans.
+// 2. Jobs belonging to workspaces with a higher priority.
+// 3. Jobs belonging to organizations with fewer jobs currently allocated to
+// runners, to share runners fairly between organizations.
+// 4. First come, first served.
+func (a *allocator) compareJobs(x, y *Job) int {
+	if c := cmp.Compare(x.priorityClass(), y.priorityClass()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(y.Priority, x.Priority); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.organizationJobs[x.Organization], a.organizationJobs[y.Organization]); c != 0 {
+		return c
+	}
+	if c := x.CreatedAt.Compare(y.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(x.ID.String(), y.ID.String())
 }
 
-// allocate jobs to runners.
-func (a *allocator) allocate(ctx context.Context, job *Job) error {
+// allocate jobs to runners. If the job remains unallocated then the reason is
+// returned.
+func (a *allocator) allocate(ctx context.Context, job *Job) (QueueReason, error) {
 	var reallocate bool
 	switch job.Status {
 	case JobAllocated:
@@ -164,12 +260,12 @@ func (a *allocator) allocate(ctx context.Context, job *Job) error {
 		// a fit state then try to allocate job to another runner
 		runner, ok := a.runners[*job.RunnerID]
 		if !ok {
-			return fmt.Errorf("runner %s not found in cache", *job.RunnerID)
+			return "", fmt.Errorf("runner %s not found in cache", *job.RunnerID)
 		}
 		switch runner.Status {
 		case RunnerIdle, RunnerBusy:
 			// runner still healthy, wait for runner to start job
-			return nil
+			return "", nil
 		default:
 			// no longer healthy, try reallocating job to another another
 			a.Info("reallocating job away from unhealthy runner", "job", job, "runner", runner)
@@ -181,22 +277,24 @@ func (a *allocator) allocate(ctx context.Context, job *Job) error {
 		// adjust current jobs of job's runner if allocated (an unallocated job
 		// could have been canceled).
 		if job.RunnerID != nil {
-			a.decrementCurrentJobs(*job.RunnerID)
+			a.releaseJob(job)
 		}
-		return nil
+		return "", nil
 	case JobRunning:
-		return nil
+		return "", nil
 	case JobUnallocated:
+		// skip server jobs belonging to an organization that has reached its
+		// quota.
+		if job.AgentPoolID == nil && a.organizationQuota > 0 && a.organizationServerJobs[job.Organization] >= a.organizationQuota {
+			return QueueOrganizationQuota, nil
+		}
 		// proceed to allocate job below
 	default:
 		a.Error(nil, "unknown job status", "job", job)
-		return nil
+		return "", nil
 	}
 	// allocate job to available runner
-	var (
-		available            []*RunnerMeta
-		insufficientCapacity bool
-	)
+	var available, full []*RunnerMeta
 	for _, runner := range a.runners {
 		// skip runners that are not ready for jobs
 		if runner.Status != RunnerIdle && runner.Status != RunnerBusy {
@@ -215,12 +313,12 @@ func (a *allocator) allocate(ctx context.Context, job *Job) error {
 		if runner.AgentPool != nil && job.AgentPoolID != nil && runner.AgentPool.ID != *job.AgentPoolID {
 			continue
 		}
-		// skip runners with insufficient capacity (only applicable to runners
-		// with a 'fork' executor kind - an infinite number of jobs can be
+		// skip runners with insufficient capacity (not applicable to runners
+		// with a 'kubernetes' executor kind - an infinite number of jobs can be
 		// allocated to the 'kubernetes' executor kind, where kubernetes itself
 		// is then responsible for allocation of resources.
-		if runner.ExecutorKind == ForkExecutorKind && runner.MaxJobs == a.currentJobs[runner.ID] {
-			insufficientCapacity = true
+		if runner.ExecutorKind != KubeExecutorKind && runner.MaxJobs == a.currentJobs[runner.ID] {
+			full = append(full, runner)
 			continue
 		}
 		available = append(available, runner)
@@ -229,10 +327,23 @@ func (a *allocator) allocate(ctx context.Context, job *Job) error {
 		// If there is at least one appropriate runner but it has
 		// insufficient capacity then it is a normal and temporary issue and
 		// not worthy of reporting as an error.
-		if !insufficientCapacity {
+		if len(full) == 0 {
 			a.Error(nil, "no available runners found for job", "job", job)
+			return QueueNoRunners, nil
 		}
-		return nil
+		if reallocate {
+			// Leave job allocated to unhealthy runner until a healthy runner
+			// has spare capacity.
+			return "", nil
+		}
+		runner, err := a.preempt(ctx, job, full)
+		if err != nil {
+			return "", err
+		}
+		if runner == nil {
+			return QueueAwaitingCapacity, nil
+		}
+		available = append(available, runner)
 	}
 	// select runner that has most recently sent a ping
 	slices.SortFunc(available, func(a, b *RunnerMeta) int {
@@ -252,18 +363,55 @@ func (a *allocator) allocate(ctx context.Context, job *Job) error {
 		from := *job.RunnerID
 		updatedJob, err = a.client.reallocateJob(ctx, job.ID, runner.ID)
 		if err != nil {
-			return err
+			return "", err
 		}
 		a.decrementCurrentJobs(from)
 	} else {
 		updatedJob, err = a.client.allocateJob(ctx, job.ID, runner.ID)
 		if err != nil {
-			return err
+			return "", err
 		}
+		a.incrementOrganizationJobs(updatedJob)
 	}
 	a.jobs[job.ID] = updatedJob
 	a.incrementCurrentJobs(runner.ID)
-	return nil
+	return "", nil
+}
+
+// preempt makes way for a job on one of the given runners, which are at
+// capacity, by returning a job with a lower class of priority to the queue. The
+// runner is returned, or nil if there is no job to preempt. Only jobs that
+// have been allocated but not yet started are preempted: a running job is
+// never interrupted.
+func (a *allocator) preempt(ctx context.Context, job *Job, runners []*RunnerMeta) (*RunnerMeta, error) {
+	var victim *Job
+	for _, other := range a.jobs {
+		if other.Status != JobAllocated || other.priorityClass() <= job.priorityClass() {
+			continue
+		}
+		if !slices.ContainsFunc(runners, func(runner *RunnerMeta) bool { return runner.ID == *other.RunnerID }) {
+			continue
+		}
+		// preempt the job with the lowest priority
+		if victim == nil || a.compareJobs(other, victim) > 0 {
+			victim = other
+		}
+	}
+	if victim == nil {
+		return nil, nil
+	}
+	deallocated, err := a.client.deallocateJob(ctx, victim.ID)
+	if errors.Is(err, ErrInvalidJobStateTransition) {
+		// The runner has since started the job.
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	runner := a.runners[*victim.RunnerID]
+	a.Info("preempted job", "job", victim, "runner", runner, "preempted_by", job)
+	a.releaseJob(victim)
+	a.jobs[victim.ID] = deallocated
+	return runner, nil
 }
 
 func (a *allocator) addRunner(runner *RunnerMeta) {
@@ -287,3 +435,20 @@ func (a *allocator) decrementCurrentJobs(runnerID resource.TfeID) {
 	a.currentJobs[runnerID]--
 	currentJobsMetric.WithLabelValues(runnerID.String()).Dec()
 }
+
+// releaseJob adjusts the tallies of current jobs when a job is no longer
+// allocated to a runner.
+func (a *allocator) releaseJob(job *Job) {
+	a.decrementCurrentJobs(*job.RunnerID)
+	a.organizationJobs[job.Organization]--
+	if job.AgentPoolID == nil {
+		a.organizationServerJobs[job.Organization]--
+	}
+}
+
+func (a *allocator) incrementOrganizationJobs(job *Job) {
+	a.organizationJobs[job.Organization]++
+	if job.AgentPoolID == nil {
+		a.organizationServerJobs[job.Organization]++
+	}
+}
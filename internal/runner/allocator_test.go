@@ -1,11 +1,13 @@
 package runner
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/run"
 	"github.com/leg100/otf/internal/testutils"
@@ -202,7 +204,7 @@ func TestAllocator_allocate(t *testing.T) {
 				},
 			}
 			a.seed(tt.runners, []*Job{tt.job})
-			err := a.allocate(t.Context(), tt.job)
+			_, err := a.allocate(t.Context(), tt.job)
 			require.NoError(t, err)
 			// check agents
 			if assert.Equal(t, len(tt.wantRunners), len(a.runners)) {
@@ -217,3 +219,161 @@ func TestAllocator_allocate(t *testing.T) {
 		})
 	}
 }
+
+func TestAllocator_schedule(t *testing.T) {
+	now := internal.CurrentTimestamp(nil)
+	runnerID := resource.NewTfeID(resource.RunnerKind)
+
+	newRunner := func(maxJobs int) *RunnerMeta {
+		return &RunnerMeta{ID: runnerID, Status: RunnerIdle, MaxJobs: maxJobs, ExecutorKind: ForkExecutorKind}
+	}
+	newJob := func(org organization.Name, phase run.PhaseType, speculative bool, priority int, age time.Duration) *Job {
+		return &Job{
+			ID:           resource.NewTfeID(resource.JobKind),
+			Phase:        phase,
+			Speculative:  speculative,
+			Priority:     priority,
+			Status:       JobUnallocated,
+			Organization: org,
+			CreatedAt:    now.Add(-age),
+		}
+	}
+	acme := organization.NewTestName(t)
+	initech := organization.NewTestName(t)
+
+	t.Run("applies before plans before speculative plans", func(t *testing.T) {
+		speculative := newJob(acme, run.PlanPhase, true, 0, 3*time.Second)
+		plan := newJob(acme, run.PlanPhase, false, 0, 2*time.Second)
+		apply := newJob(acme, run.ApplyPhase, false, 0, time.Second)
+
+		client := newFakeAllocatorClient(speculative, plan, apply)
+		a := &allocator{Logger: logr.Discard(), client: client}
+		a.seed([]*RunnerMeta{newRunner(1)}, []*Job{speculative, plan, apply})
+		require.NoError(t, a.schedule(t.Context()))
+
+		assert.Equal(t, []resource.TfeID{apply.ID}, client.allocated)
+		assert.Equal(t, []QueuePosition{
+			{JobID: plan.ID, Position: 1, Reason: QueueAwaitingCapacity},
+			{JobID: speculative.ID, Position: 2, Reason: QueueAwaitingCapacity},
+		}, client.queue)
+	})
+
+	t.Run("higher workspace priority first", func(t *testing.T) {
+		low := newJob(acme, run.PlanPhase, false, 0, 2*time.Second)
+		high := newJob(acme, run.PlanPhase, false, 10, time.Second)
+
+		client := newFakeAllocatorClient(low, high)
+		a := &allocator{Logger: logr.Discard(), client: client}
+		a.seed([]*RunnerMeta{newRunner(1)}, []*Job{low, high})
+		require.NoError(t, a.schedule(t.Context()))
+
+		assert.Equal(t, []resource.TfeID{high.ID}, client.allocated)
+	})
+
+	t.Run("fair share between organizations", func(t *testing.T) {
+		acme1 := newJob(acme, run.PlanPhase, false, 0, 3*time.Second)
+		acme2 := newJob(acme, run.PlanPhase, false, 0, 2*time.Second)
+		initech1 := newJob(initech, run.PlanPhase, false, 0, time.Second)
+
+		client := newFakeAllocatorClient(acme1, acme2, initech1)
+		a := &allocator{Logger: logr.Discard(), client: client}
+		a.seed([]*RunnerMeta{newRunner(2)}, []*Job{acme1, acme2, initech1})
+		require.NoError(t, a.schedule(t.Context()))
+
+		assert.Equal(t, []resource.TfeID{acme1.ID, initech1.ID}, client.allocated)
+	})
+
+	t.Run("organization quota", func(t *testing.T) {
+		acme1 := newJob(acme, run.PlanPhase, false, 0, 3*time.Second)
+		acme2 := newJob(acme, run.PlanPhase, false, 0, 2*time.Second)
+
+		client := newFakeAllocatorClient(acme1, acme2)
+		a := &allocator{Logger: logr.Discard(), client: client, organizationQuota: 1}
+		a.seed([]*RunnerMeta{newRunner(5)}, []*Job{acme1, acme2})
+		require.NoError(t, a.schedule(t.Context()))
+
+		assert.Equal(t, []resource.TfeID{acme1.ID}, client.allocated)
+		assert.Equal(t, []QueuePosition{
+			{JobID: acme2.ID, Position: 1, Reason: QueueOrganizationQuota},
+		}, client.queue)
+	})
+
+	t.Run("preempt allocated speculative plan", func(t *testing.T) {
+		speculative := newJob(acme, run.PlanPhase, true, 0, 2*time.Second)
+		speculative.Status = JobAllocated
+		speculative.RunnerID = &runnerID
+		apply := newJob(acme, run.ApplyPhase, false, 0, time.Second)
+
+		client := newFakeAllocatorClient(speculative, apply)
+		a := &allocator{Logger: logr.Discard(), client: client}
+		runner := newRunner(1)
+		runner.CurrentJobs = 1
+		a.seed([]*RunnerMeta{runner}, []*Job{speculative, apply})
+		require.NoError(t, a.schedule(t.Context()))
+
+		assert.Equal(t, []resource.TfeID{speculative.ID}, client.deallocated)
+		assert.Equal(t, []resource.TfeID{apply.ID}, client.allocated)
+		assert.Equal(t, JobUnallocated, a.jobs[speculative.ID].Status)
+		assert.Equal(t, 1, a.currentJobs[runnerID])
+	})
+
+	t.Run("do not preempt running job", func(t *testing.T) {
+		speculative := newJob(acme, run.PlanPhase, true, 0, 2*time.Second)
+		speculative.Status = JobRunning
+		speculative.RunnerID = &runnerID
+		apply := newJob(acme, run.ApplyPhase, false, 0, time.Second)
+
+		client := newFakeAllocatorClient(speculative, apply)
+		a := &allocator{Logger: logr.Discard(), client: client}
+		runner := newRunner(1)
+		runner.CurrentJobs = 1
+		a.seed([]*RunnerMeta{runner}, []*Job{speculative, apply})
+		require.NoError(t, a.schedule(t.Context()))
+
+		assert.Empty(t, client.deallocated)
+		assert.Empty(t, client.allocated)
+		assert.Equal(t, []QueuePosition{
+			{JobID: apply.ID, Position: 1, Reason: QueueAwaitingCapacity},
+		}, client.queue)
+	})
+}
+
+type fakeAllocatorClient struct {
+	allocatorClient
+
+	jobs        map[resource.TfeID]*Job
+	allocated   []resource.TfeID
+	deallocated []resource.TfeID
+	queue       []QueuePosition
+}
+
+func newFakeAllocatorClient(jobs ...*Job) *fakeAllocatorClient {
+	client := &fakeAllocatorClient{jobs: make(map[resource.TfeID]*Job, len(jobs))}
+	for _, job := range jobs {
+		client.jobs[job.ID] = job
+	}
+	return client
+}
+
+func (f *fakeAllocatorClient) allocateJob(ctx context.Context, jobID, runnerID resource.TfeID) (*Job, error) {
+	job := *f.jobs[jobID]
+	if err := job.allocate(runnerID); err != nil {
+		return nil, err
+	}
+	f.allocated = append(f.allocated, jobID)
+	return &job, nil
+}
+
+func (f *fakeAllocatorClient) deallocateJob(ctx context.Context, jobID resource.TfeID) (*Job, error) {
+	job := *f.jobs[jobID]
+	if err := job.deallocate(); err != nil {
+		return nil, err
+	}
+	f.deallocated = append(f.deallocated, jobID)
+	return &job, nil
+}
+
+func (f *fakeAllocatorClient) setJobQueue(ctx context.Context, queue []QueuePosition) error {
+	f.queue = queue
+	return nil
+}
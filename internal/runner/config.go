@@ -8,9 +8,10 @@ type Config struct {
 	OperationConfig
 
 	Name         string       // descriptive name given to runner
-	MaxJobs      int          // number of jobs the runner can execute at any one time. Only applicable to the 'fork' excecutor.
-	ExecutorKind ExecutorKind // how jobs are launched: forked processes or kubernetes jobs
+	MaxJobs      int          // number of jobs the runner can execute at any one time. Not applicable to the 'kubernetes' excecutor.
+	ExecutorKind ExecutorKind // how jobs are launched: forked processes, kubernetes jobs or docker containers
 	KubeConfig   kubeConfig
+	DockerConfig dockerConfig
 }
 
 func NewDefaultConfig() *Config {
@@ -18,13 +19,15 @@ func NewDefaultConfig() *Config {
 		MaxJobs:         DefaultMaxJobs,
 		ExecutorKind:    ForkExecutorKind,
 		KubeConfig:      defaultKubeConfig,
+		DockerConfig:    defaultDockerConfig,
 		OperationConfig: defaultOperationConfig(),
 	}
 }
 
 func RegisterFlags(flags *pflag.FlagSet, cfg *Config) {
-	flags.IntVar(&cfg.MaxJobs, "concurrency", cfg.MaxJobs, "Number of runs that can be processed concurrently. Only applicable to the fork executor.")
-	flags.Var(&cfg.ExecutorKind, "executor", "Executor for executing jobs: 'fork' or 'kubernetes'")
+	flags.IntVar(&cfg.MaxJobs, "concurrency", cfg.MaxJobs, "Number of runs that can be processed concurrently. Not applicable to the kubernetes executor.")
+	flags.Var(&cfg.ExecutorKind, "executor", "Executor for executing jobs: 'fork', 'kubernetes' or 'docker'")
 	RegisterOperationFlags(flags, &cfg.OperationConfig)
 	registerKubeFlags(flags, &cfg.KubeConfig)
+	registerDockerFlags(flags, &cfg.DockerConfig)
 }
@@ -2,11 +2,13 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/netip"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/run"
@@ -206,17 +208,20 @@ INSERT INTO jobs (
     job_id,
     run_id,
     phase,
-    status
+    status,
+    created_at
 ) VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5
 )`,
 		job.ID,
 		job.RunID,
 		job.Phase,
 		job.Status,
+		job.CreatedAt,
 	)
 	return err
 }
@@ -226,8 +231,10 @@ func (db *db) listAllocatedJobs(ctx context.Context, runnerID resource.TfeID) ([
 SELECT
     j.*,
     w.agent_pool_id,
-	w.workspace_id,
-    w.organization_name
+    w.workspace_id,
+    w.organization_name,
+    w.priority,
+    r.plan_only AS speculative
 FROM jobs j
 JOIN runs r USING (run_id)
 JOIN workspaces w USING (workspace_id)
@@ -242,8 +249,10 @@ func (db *db) getJob(ctx context.Context, jobID resource.TfeID) (*Job, error) {
 SELECT
     j.*,
     w.agent_pool_id,
-	w.workspace_id,
-    w.organization_name
+    w.workspace_id,
+    w.organization_name,
+    w.priority,
+    r.plan_only AS speculative
 FROM jobs j
 JOIN runs r USING (run_id)
 JOIN workspaces w USING (workspace_id)
@@ -258,7 +267,9 @@ SELECT
     j.*,
     w.agent_pool_id,
     w.workspace_id,
-    w.organization_name
+    w.organization_name,
+    w.priority,
+    r.plan_only AS speculative
 FROM jobs j
 JOIN runs r USING (run_id)
 JOIN workspaces w USING (workspace_id)
@@ -278,7 +289,9 @@ SELECT
     j.*,
     w.agent_pool_id,
     w.workspace_id,
-    w.organization_name
+    w.organization_name,
+    w.priority,
+    r.plan_only AS speculative
 FROM jobs j
 JOIN runs r USING (run_id)
 JOIN workspaces w USING (workspace_id)
@@ -318,6 +331,9 @@ func scanJob(row pgx.CollectableRow) (*Job, error) {
 		Organization organization.Name `db:"organization_name"`
 		WorkspaceID  resource.TfeID    `db:"workspace_id"`
 		RunnerID     *resource.TfeID   `db:"runner_id"`
+		Speculative  bool
+		Priority     int
+		CreatedAt    time.Time `db:"created_at"`
 	}
 	m, err := pgx.RowToAddrOfStructByName[model](row)
 	if err != nil {
@@ -332,10 +348,51 @@ func scanJob(row pgx.CollectableRow) (*Job, error) {
 		Organization: m.Organization,
 		WorkspaceID:  m.WorkspaceID,
 		RunnerID:     m.RunnerID,
+		Speculative:  m.Speculative,
+		Priority:     m.Priority,
+		CreatedAt:    m.CreatedAt,
 	}
 	return meta, nil
 }
 
+// setJobQueue replaces the queue of unallocated jobs.
+func (db *db) setJobQueue(ctx context.Context, queue []QueuePosition) error {
+	return db.Tx(ctx, func(ctx context.Context) error {
+		if _, err := db.Exec(ctx, `DELETE FROM job_queue`); err != nil && !errors.Is(err, internal.ErrResourceNotFound) {
+			return err
+		}
+		for _, pos := range queue {
+			_, err := db.Exec(ctx, `
+INSERT INTO job_queue (
+    job_id,
+    position,
+    reason
+) VALUES (
+    $1,
+    $2,
+    $3
+)`, pos.JobID, pos.Position, pos.Reason)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getRunQueuePosition retrieves the position in the queue of the unallocated
+// job belonging to the run.
+func (db *db) getRunQueuePosition(ctx context.Context, runID resource.TfeID) (*QueuePosition, error) {
+	rows := db.Query(ctx, `
+SELECT q.job_id, q.position, q.reason
+FROM job_queue q
+JOIN jobs j USING (job_id)
+WHERE j.run_id = $1
+AND j.status = 'unallocated'
+`, runID)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[QueuePosition])
+}
+
 // agent tokens
 
 func (db *db) createAgentToken(ctx context.Context, token *AgentToken) error {
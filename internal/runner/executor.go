@@ -19,9 +19,9 @@ func (e *ExecutorKind) String() string     { return string(*e) }
 
 func (e *ExecutorKind) set(v string) error {
 	switch ExecutorKind(v) {
-	case ForkExecutorKind, KubeExecutorKind:
+	case ForkExecutorKind, KubeExecutorKind, DockerExecutorKind:
 	default:
-		return fmt.Errorf("no executor named %s: must be %s or %s", v, ForkExecutorKind, KubeExecutorKind)
+		return fmt.Errorf("no executor named %s: must be %s, %s or %s", v, ForkExecutorKind, KubeExecutorKind, DockerExecutorKind)
 	}
 	*e = ExecutorKind(v)
 	return nil
@@ -0,0 +1,388 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const DockerExecutorKind = "docker"
+
+// dockerAPIVersion is the version of the Docker Engine API used by the docker
+// executor. Podman's docker-compatible API supports this version too.
+const dockerAPIVersion = "v1.41"
+
+func init() {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = "unix:///var/run/docker.sock"
+	}
+	defaultDockerConfig = dockerConfig{
+		Host: host,
+		// Default to using the same version of the job image as the current
+		// version of otfd.
+		Image:      fmt.Sprintf("leg100/otf-job:%s", internal.Version),
+		AutoRemove: true,
+	}
+}
+
+var defaultDockerConfig dockerConfig
+
+type dockerConfig struct {
+	// Host is the address of the Docker Engine API, e.g.
+	// unix:///var/run/docker.sock or tcp://127.0.0.1:2375.
+	Host       string
+	Image      string
+	ServerURL  string
+	Network    string
+	Volumes    []string
+	AutoRemove bool
+
+	nanoCPUs int64
+	memory   int64
+	labels   map[string]string
+
+	flags dockerConfigFlags
+}
+
+// dockerConfigFlags are CLI flags that need to be parsed first and should not
+// be used directly by the docker executor.
+type dockerConfigFlags struct {
+	Labels []string
+	CPUs   string
+	Memory string
+}
+
+func registerDockerFlags(flags *pflag.FlagSet, cfg *dockerConfig) {
+	flags.StringVar(&cfg.Host, "docker-host", cfg.Host, "Address of the Docker Engine API used by the docker executor. Podman's docker-compatible socket is also supported.")
+	flags.StringVar(&cfg.Image, "docker-job-image", cfg.Image, "Image to use for docker jobs.")
+	flags.StringVar(&cfg.ServerURL, "docker-server-url", cfg.ServerURL, "URL of otfd with which docker jobs communicate.")
+	flags.StringVar(&cfg.flags.CPUs, "docker-limit-cpu", cfg.flags.CPUs, "CPU limit for docker jobs, e.g. `1.5` or `1500m`.")
+	flags.StringVar(&cfg.flags.Memory, "docker-limit-memory", cfg.flags.Memory, "Memory limit for docker jobs, e.g. `512Mi`.")
+	flags.StringVar(&cfg.Network, "docker-network", cfg.Network, "Network to connect docker jobs to, e.g. `bridge`, `host` or the name of a user-defined network.")
+	flags.StringSliceVar(&cfg.Volumes, "docker-volumes", cfg.Volumes, "Mount volumes in docker jobs. Each volume is specified in the form `source:destination[:options]`.")
+	flags.BoolVar(&cfg.AutoRemove, "docker-auto-remove", cfg.AutoRemove, "Remove docker job containers once they have finished.")
+	flags.StringSliceVar(&cfg.flags.Labels, "docker-labels", cfg.flags.Labels, "Set additional labels on docker job containers. Name and value are separated by an equals sign, e.g. `foo=bar`.")
+}
+
+// dockerExecutor executes each job in its own container via the Docker Engine
+// API.
+type dockerExecutor struct {
+	Logger          logr.Logger
+	Config          dockerConfig
+	OperationConfig OperationConfig
+	client          dockerClient
+}
+
+type dockerClient interface {
+	createContainer(ctx context.Context, name string, spec dockerContainerSpec) (string, error)
+	startContainer(ctx context.Context, id string) error
+	pullImage(ctx context.Context, image string) error
+	countContainers(ctx context.Context, label string) (int, error)
+}
+
+// dockerContainerSpec is the request body for creating a container.
+type dockerContainerSpec struct {
+	Image      string
+	Env        []string
+	Labels     map[string]string
+	HostConfig dockerHostConfig
+}
+
+type dockerHostConfig struct {
+	NanoCpus    int64    `json:",omitempty"`
+	Memory      int64    `json:",omitempty"`
+	NetworkMode string   `json:",omitempty"`
+	Binds       []string `json:",omitempty"`
+	AutoRemove  bool
+	SecurityOpt []string `json:",omitempty"`
+}
+
+// errDockerImageNotFound is returned when creating a container using an image
+// that is not present on the docker host.
+var errDockerImageNotFound = errors.New("docker image not found")
+
+func newDockerExecutor(
+	logger logr.Logger,
+	operationConfig OperationConfig,
+	dockerConfig dockerConfig,
+) (*dockerExecutor, error) {
+	executor := &dockerExecutor{
+		Logger:          logger,
+		OperationConfig: operationConfig,
+		Config:          dockerConfig,
+	}
+
+	if dockerConfig.ServerURL == "" {
+		return nil, errors.New("docker executor requires the URL of otfd to be set with --docker-server-url")
+	}
+
+	if dockerConfig.flags.CPUs != "" {
+		cpus, err := k8sresource.ParseQuantity(dockerConfig.flags.CPUs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu limit quantity: %s: %w", dockerConfig.flags.CPUs, err)
+		}
+		executor.Config.nanoCPUs = cpus.MilliValue() * 1e6
+	}
+
+	if dockerConfig.flags.Memory != "" {
+		memory, err := k8sresource.ParseQuantity(dockerConfig.flags.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory limit quantity: %s: %w", dockerConfig.flags.Memory, err)
+		}
+		executor.Config.memory = memory.Value()
+	}
+
+	executor.Config.labels = make(map[string]string)
+	for _, label := range dockerConfig.flags.Labels {
+		k, v, ok := strings.Cut(label, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label: must be in format name=value")
+		}
+		executor.Config.labels[k] = v
+	}
+
+	client, err := newDockerAPIClient(dockerConfig.Host)
+	if err != nil {
+		return nil, fmt.Errorf("creating docker client: %w", err)
+	}
+	executor.client = client
+
+	return executor, nil
+}
+
+func (s *dockerExecutor) SpawnOperation(ctx context.Context, _ *errgroup.Group, job *Job, jobToken []byte) error {
+	labels := map[string]string{
+		"otf.ninja/job-id":       job.ID.String(),
+		"otf.ninja/run-id":       job.RunID.String(),
+		"otf.ninja/runner-id":    job.RunnerID.String(),
+		"otf.ninja/workspace-id": job.WorkspaceID.String(),
+		"otf.ninja/organization": job.Organization.String(),
+		"otf.ninja/version":      internal.Version,
+	}
+	maps.Copy(labels, s.Config.labels)
+
+	spec := dockerContainerSpec{
+		Image: s.Config.Image,
+		// Unlike kubernetes, docker has no secrets for standalone containers,
+		// so the job token is passed directly as an environment variable.
+		Env: []string{
+			"OTF_URL=" + s.Config.ServerURL,
+			"OTF_JOB_ID=" + job.ID.String(),
+			"OTF_JOB_TOKEN=" + string(jobToken),
+			"OTF_V=" + strconv.Itoa(s.Logger.Verbosity),
+			"OTF_LOG_FORMAT=" + string(s.Logger.Format),
+			"OTF_ENGINE_BINS_DIR=" + s.OperationConfig.EngineBinDir,
+			"OTF_PLUGIN_CACHE=" + strconv.FormatBool(s.OperationConfig.PluginCache),
+			"OTF_PLUGIN_CACHE_DIR=" + s.OperationConfig.PluginCacheDir,
+			"OTF_DEBUG=" + strconv.FormatBool(s.OperationConfig.Debug),
+			"OTF_ENGINE_MIRROR=" + strconv.FormatBool(s.OperationConfig.EngineMirror),
+		},
+		Labels: labels,
+		HostConfig: dockerHostConfig{
+			NanoCpus:    s.Config.nanoCPUs,
+			Memory:      s.Config.memory,
+			NetworkMode: s.Config.Network,
+			// Only those volumes the user explicitly specifies are mounted;
+			// the host filesystem is otherwise inaccessible to the job.
+			Binds:      s.Config.Volumes,
+			AutoRemove: s.Config.AutoRemove,
+			// Prevent processes in the container from gaining additional
+			// privileges, e.g. via setuid binaries.
+			SecurityOpt: []string{"no-new-privileges"},
+		},
+	}
+
+	// Add a random suffix to the container name to avoid a clash with the
+	// container of a previous attempt at the same job that has not been
+	// removed.
+	lowerCaseAndNumbers := "abcdefghijkmnopqrstuvwxyz0123456789"
+	suffix := internal.GenerateRandomStringFromAlphabet(4, lowerCaseAndNumbers)
+	name := fmt.Sprintf("otf-%s-%s", job.ID, suffix)
+
+	id, err := s.client.createContainer(ctx, name, spec)
+	if errors.Is(err, errDockerImageNotFound) {
+		s.Logger.V(1).Info("pulling docker image", "image", s.Config.Image)
+		if err := s.client.pullImage(ctx, s.Config.Image); err != nil {
+			return fmt.Errorf("pulling docker image: %w", err)
+		}
+		id, err = s.client.createContainer(ctx, name, spec)
+	}
+	if err != nil {
+		return fmt.Errorf("creating docker container: %w", err)
+	}
+	if err := s.client.startContainer(ctx, id); err != nil {
+		return fmt.Errorf("starting docker container: %w", err)
+	}
+	s.Logger.V(1).Info("started docker container", "name", name, "id", id, "otf-job", job)
+	return nil
+}
+
+func (s *dockerExecutor) currentJobs(ctx context.Context, runnerID resource.TfeID) int {
+	n, err := s.client.countContainers(ctx, fmt.Sprintf("otf.ninja/runner-id=%s", runnerID))
+	if err != nil {
+		s.Logger.Error(err, "listing current number of docker containers")
+	}
+	return n
+}
+
+// dockerAPIClient is a minimal client for the Docker Engine API.
+type dockerAPIClient struct {
+	client *http.Client
+	// base URL of the API, including the API version.
+	base string
+}
+
+func newDockerAPIClient(host string) (*dockerAPIClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host: %s: %w", host, err)
+	}
+	var (
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		base      string
+	)
+	switch u.Scheme {
+	case "unix":
+		// The host portion of the URL is irrelevant when dialing a unix
+		// socket but it must be valid.
+		base = "http://docker"
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", u.Path)
+		}
+	case "tcp":
+		base = "http://" + u.Host
+	case "http", "https":
+		base = u.Scheme + "://" + u.Host
+	default:
+		return nil, fmt.Errorf("invalid docker host: %s: unsupported scheme: %s", host, u.Scheme)
+	}
+	return &dockerAPIClient{
+		client: &http.Client{Transport: transport},
+		base:   base + "/" + dockerAPIVersion,
+	}, nil
+}
+
+func (c *dockerAPIClient) createContainer(ctx context.Context, name string, spec dockerContainerSpec) (string, error) {
+	body, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, "POST", "/containers/create?name="+url.QueryEscape(name), bytes.NewReader(body))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("%w: %w", errDockerImageNotFound, err)
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+	return created.ID, nil
+}
+
+func (c *dockerAPIClient) startContainer(ctx context.Context, id string) error {
+	resp, err := c.do(ctx, "POST", "/containers/"+url.PathEscape(id)+"/start", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *dockerAPIClient) pullImage(ctx context.Context, image string) error {
+	resp, err := c.do(ctx, "POST", "/images/create?fromImage="+url.QueryEscape(image), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The response is a stream of progress messages, which must be consumed
+	// for the pull to complete. A failure part way through is reported in a
+	// message rather than via the status code.
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
+
+func (c *dockerAPIClient) countContainers(ctx context.Context, label string) (int, error) {
+	filters, err := json.Marshal(map[string][]string{
+		"label":  {label},
+		"status": {"created", "running"},
+	})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.do(ctx, "GET", "/containers/json?filters="+url.QueryEscape(string(filters)), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var containers []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return 0, fmt.Errorf("decoding response: %w", err)
+	}
+	return len(containers), nil
+}
+
+// do sends a request to the API. An error is returned if the response status
+// is not 2xx, along with the response, the body of which is closed.
+func (c *dockerAPIClient) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil || msg.Message == "" {
+			return resp, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		return resp, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, msg.Message)
+	}
+	return resp, nil
+}
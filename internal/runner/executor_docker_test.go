@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDockerExecutor(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg := defaultDockerConfig
+		cfg.ServerURL = "https://otf.example.com"
+
+		_, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), cfg)
+		require.NoError(t, err)
+	})
+
+	t.Run("missing server url", func(t *testing.T) {
+		_, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), defaultDockerConfig)
+		assert.Error(t, err)
+	})
+
+	t.Run("with resource limits", func(t *testing.T) {
+		cfg := defaultDockerConfig
+		cfg.ServerURL = "https://otf.example.com"
+		cfg.flags.CPUs = "1.5"
+		cfg.flags.Memory = "512Mi"
+
+		executor, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), cfg)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1_500_000_000), executor.Config.nanoCPUs)
+		assert.Equal(t, int64(512*1024*1024), executor.Config.memory)
+	})
+
+	t.Run("with invalid resource limits", func(t *testing.T) {
+		cfg := defaultDockerConfig
+		cfg.ServerURL = "https://otf.example.com"
+		cfg.flags.CPUs = "foo"
+
+		_, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("with invalid labels", func(t *testing.T) {
+		cfg := defaultDockerConfig
+		cfg.ServerURL = "https://otf.example.com"
+		cfg.flags.Labels = []string{"foobar"}
+
+		_, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("with invalid host", func(t *testing.T) {
+		cfg := defaultDockerConfig
+		cfg.ServerURL = "https://otf.example.com"
+		cfg.Host = "ftp://docker"
+
+		_, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), cfg)
+		assert.Error(t, err)
+	})
+}
+
+func TestDockerExecutor_SpawnOperation(t *testing.T) {
+	var (
+		spec    dockerContainerSpec
+		pulled  string
+		started string
+		// the first attempt to create the container fails because the image
+		// is missing.
+		imageMissing = true
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.41/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		if imageMissing {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such image"}`))
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&spec))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"container-123"}`))
+	})
+	mux.HandleFunc("POST /v1.41/images/create", func(w http.ResponseWriter, r *http.Request) {
+		pulled = r.URL.Query().Get("fromImage")
+		imageMissing = false
+		w.Write([]byte(`{"status":"Pulling"}` + "\n" + `{"status":"Downloaded"}`))
+	})
+	mux.HandleFunc("POST /v1.41/containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		started = r.PathValue("id")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /v1.41/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		var filters map[string][]string
+		require.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters))
+		assert.Len(t, filters["label"], 1)
+		w.Write([]byte(`[{"Id":"container-123"}]`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := defaultDockerConfig
+	cfg.Host = srv.URL
+	cfg.ServerURL = "https://otf.example.com"
+	cfg.Network = "host"
+	cfg.Volumes = []string{"otf-cache:/cache"}
+	cfg.flags.Labels = []string{"foo=bar"}
+	cfg.flags.CPUs = "2"
+	cfg.flags.Memory = "1Gi"
+
+	executor, err := newDockerExecutor(logr.Discard(), defaultOperationConfig(), cfg)
+	require.NoError(t, err)
+
+	job := &Job{
+		ID:           resource.NewTfeID(resource.JobKind),
+		RunID:        resource.NewTfeID(resource.RunKind),
+		Phase:        run.PlanPhase,
+		Status:       JobAllocated,
+		Organization: organization.NewTestName(t),
+		WorkspaceID:  resource.NewTfeID(resource.WorkspaceKind),
+		RunnerID:     new(resource.NewTfeID(resource.RunnerKind)),
+	}
+
+	err = executor.SpawnOperation(t.Context(), nil, job, []byte("token"))
+	require.NoError(t, err)
+
+	assert.Equal(t, cfg.Image, pulled)
+	assert.Equal(t, "container-123", started)
+	assert.Equal(t, cfg.Image, spec.Image)
+	assert.Equal(t, map[string]string{
+		"otf.ninja/job-id":       job.ID.String(),
+		"otf.ninja/organization": job.Organization.String(),
+		"otf.ninja/run-id":       job.RunID.String(),
+		"otf.ninja/runner-id":    job.RunnerID.String(),
+		"otf.ninja/workspace-id": job.WorkspaceID.String(),
+		"otf.ninja/version":      "unknown",
+		"foo":                    "bar",
+	}, spec.Labels)
+	assert.Contains(t, spec.Env, "OTF_URL=https://otf.example.com")
+	assert.Contains(t, spec.Env, "OTF_JOB_ID="+job.ID.String())
+	assert.Contains(t, spec.Env, "OTF_JOB_TOKEN=token")
+	assert.Equal(t, dockerHostConfig{
+		NanoCpus:    2_000_000_000,
+		Memory:      1024 * 1024 * 1024,
+		NetworkMode: "host",
+		Binds:       []string{"otf-cache:/cache"},
+		AutoRemove:  true,
+		SecurityOpt: []string{"no-new-privileges"},
+	}, spec.HostConfig)
+
+	assert.Equal(t, 1, executor.currentJobs(t.Context(), *job.RunnerID))
+}
@@ -319,6 +319,10 @@ func (s *kubeExecutor) SpawnOperation(ctx context.Context, _ *errgroup.Group, jo
 									Name:  "OTF_DEBUG",
 									Value: strconv.FormatBool(s.OperationConfig.Debug),
 								},
+								{
+									Name:  "OTF_ENGINE_MIRROR",
+									Value: strconv.FormatBool(s.OperationConfig.EngineMirror),
+								},
 							},
 							VolumeMounts: []corev1.VolumeMount{
 								{
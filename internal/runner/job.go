@@ -3,7 +3,9 @@ package runner
 import (
 	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
@@ -48,6 +50,12 @@ type Job struct {
 	// ID of runner that this job is allocated to. Only set once job enters
 	// JobAllocated state.
 	RunnerID *resource.TfeID `jsonapi:"attribute" json:"runner_id" db:"runner_id"`
+	// Speculative is true if the job is for a plan-only run.
+	Speculative bool `jsonapi:"attribute" json:"speculative" db:"speculative"`
+	// Priority of the job's workspace.
+	Priority int `jsonapi:"attribute" json:"priority" db:"priority"`
+	// CreatedAt is when the job was created.
+	CreatedAt time.Time `jsonapi:"attribute" json:"created_at" db:"created_at"`
 }
 
 type FinishJobOptions struct {
@@ -63,6 +71,23 @@ func newJob(run *otfrun.Run) *Job {
 		Status:       JobUnallocated,
 		Organization: run.Organization,
 		WorkspaceID:  run.WorkspaceID,
+		Speculative:  run.PlanOnly,
+		CreatedAt:    internal.CurrentTimestamp(nil),
+	}
+}
+
+// priorityClass returns the class of priority of the job. Jobs in a lower class
+// are allocated before jobs in a higher class, regardless of the priority of
+// their workspaces: applies are allocated first, then plans, and then
+// speculative plans.
+func (j *Job) priorityClass() int {
+	switch {
+	case j.Phase == otfrun.ApplyPhase:
+		return 0
+	case !j.Speculative:
+		return 1
+	default:
+		return 2
 	}
 }
 
@@ -198,6 +223,16 @@ func (j *Job) allocate(runnerID resource.TfeID) error {
 	return nil
 }
 
+// deallocate returns an allocated job to the queue of unallocated jobs, making
+// way for a job with a higher priority.
+func (j *Job) deallocate() error {
+	if err := j.updateStatus(JobUnallocated); err != nil {
+		return err
+	}
+	j.RunnerID = nil
+	return nil
+}
+
 func (j *Job) reallocate(runnerID resource.TfeID) error {
 	if j.Status != JobAllocated {
 		return errors.New("job can only be re-allocated when it is in the allocated state")
@@ -251,7 +286,7 @@ func (j *Job) updateStatus(to JobStatus) error {
 		}
 	case JobAllocated:
 		switch to {
-		case JobRunning, JobCanceled:
+		case JobUnallocated, JobRunning, JobCanceled:
 			isValid = true
 		}
 	case JobRunning:
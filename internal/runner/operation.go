@@ -92,6 +92,8 @@ type (
 		GetSSHKeyPrivateKey(ctx context.Context, id resource.TfeID) ([]byte, error)
 		AwaitJobSignal(ctx context.Context, jobID resource.TfeID) func() (JobSignal, error)
 		FinishJob(ctx context.Context, jobID resource.TfeID, opts FinishJobOptions) error
+		DownloadEngine(ctx context.Context, engine *engine.Engine, version, os, arch string) ([]byte, error)
+		GetEngine(ctx context.Context, name string) (*engine.Engine, error)
 	}
 
 	OperationConfig struct {
@@ -99,6 +101,7 @@ type (
 		PluginCache    bool   // toggle use of engine's shared plugin cache
 		PluginCacheDir string // directory for shared plugin cache.
 		EngineBinDir   string // destination directory for engine binaries
+		EngineMirror   bool   // download engine binaries from otfd's engine mirror
 		IsAgent        bool   // set to true if operation is running on an agent
 	}
 
@@ -129,6 +132,7 @@ func RegisterOperationFlags(flags *pflag.FlagSet, cfg *OperationConfig) {
 	flags.BoolVar(&cfg.PluginCache, "plugin-cache", cfg.PluginCache, "Enable shared plugin cache for provider plugins.")
 	flags.StringVar(&cfg.PluginCacheDir, "plugin-cache-dir", cfg.PluginCacheDir, "Directory for shared plugin cache.")
 	flags.StringVar(&cfg.EngineBinDir, "engine-bins-dir", cfg.EngineBinDir, "Destination directory for engine binary downloads.")
+	flags.BoolVar(&cfg.EngineMirror, "engine-mirror", cfg.EngineMirror, "Download engine binaries from the otfd engine mirror rather than from upstream.")
 }
 
 func DoOperation(runnerCtx context.Context, g *errgroup.Group, opts OperationOptions) {
@@ -237,7 +241,17 @@ func (o *operation) do() error {
 		return err
 	}
 	o.run = run
-	o.downloader, err = engine.NewDownloader(o.Logger, o.run.Engine, o.cfg.EngineBinDir)
+	// Retrieve the engine's details, which for a custom engine are
+	// maintained by otfd.
+	eng, err := o.client.GetEngine(o.ctx, o.run.Engine.String())
+	if err != nil {
+		return fmt.Errorf("retrieving engine: %w", err)
+	}
+	var mirror engine.Mirror
+	if o.cfg.EngineMirror {
+		mirror = o.client
+	}
+	o.downloader, err = engine.NewDownloader(o.Logger, eng, o.cfg.EngineBinDir, mirror)
 	if err != nil {
 		return err
 	}
@@ -606,6 +620,9 @@ func (o *operation) plan(ctx context.Context) error {
 	for _, addr := range o.run.TargetAddrs {
 		args = append(args, "-target="+addr)
 	}
+	if o.run.StructuredOutput {
+		args = append(args, "-json")
+	}
 	args = append(args, "-out="+planFilename)
 	if err := o.execute(append([]string{o.enginePath}, args...)); err != nil {
 		return fmt.Errorf("executing plan: %w", err)
@@ -645,6 +662,9 @@ func (o *operation) apply(ctx context.Context) (err error) {
 	if o.run.IsDestroy {
 		args = append(args, "-destroy")
 	}
+	if o.run.StructuredOutput {
+		args = append(args, "-json")
+	}
 	args = append(args, planFilename)
 	return o.execute(append([]string{o.enginePath}, args...))
 }
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+// QueueReason is the reason a job is waiting to be allocated to a runner.
+type QueueReason string
+
+const (
+	// QueueAwaitingCapacity means all runners eligible to execute the job are
+	// at capacity, or are executing jobs of equal or higher priority.
+	QueueAwaitingCapacity QueueReason = "awaiting_capacity"
+	// QueueOrganizationQuota means server runners are already executing the
+	// maximum number of jobs permitted for the job's organization.
+	QueueOrganizationQuota QueueReason = "organization_quota"
+	// QueueNoRunners means there are no healthy runners eligible to execute
+	// the job.
+	QueueNoRunners QueueReason = "no_runners"
+)
+
+// Description returns a human-readable description of the reason.
+func (r QueueReason) Description() string {
+	switch r {
+	case QueueAwaitingCapacity:
+		return "waiting for a runner with spare capacity"
+	case QueueOrganizationQuota:
+		return "waiting for other jobs in the organization to finish"
+	case QueueNoRunners:
+		return "waiting for a runner to become available"
+	default:
+		return string(r)
+	}
+}
+
+// QueuePosition is the position of an unallocated job in the queue of jobs
+// awaiting allocation to runners. Jobs for server runners and jobs for each
+// agent pool are queued separately.
+type QueuePosition struct {
+	JobID resource.TfeID `db:"job_id"`
+	// Position is the 1-based position of the job in the queue.
+	Position int
+	// Reason the job is waiting.
+	Reason QueueReason
+}
+
+func (p QueuePosition) String() string {
+	return fmt.Sprintf("#%d in queue: %s", p.Position, p.Reason.Description())
+}
+
+// queueKey identifies a queue: the zero value identifies the queue for server
+// runners, and otherwise an agent pool ID identifies the queue for the pool.
+type queueKey struct {
+	agentPoolID resource.TfeID
+}
+
+func queueKeyForJob(job *Job) queueKey {
+	if job.AgentPoolID == nil {
+		return queueKey{}
+	}
+	return queueKey{agentPoolID: *job.AgentPoolID}
+}
@@ -85,6 +85,12 @@ func New(
 			return nil, fmt.Errorf("constructing kubernetes executor: %w", err)
 		}
 		r.executor = executor
+	case DockerExecutorKind:
+		executor, err := newDockerExecutor(logger, cfg.OperationConfig, cfg.DockerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("constructing docker executor: %w", err)
+		}
+		r.executor = executor
 	default:
 		return nil, fmt.Errorf("invalid executor kind: '%s'", cfg.ExecutorKind)
 	}
@@ -134,10 +134,14 @@ func NewService(opts ServiceOptions) *Service {
 	return svc
 }
 
-func (s *Service) NewAllocator(logger logr.Logger) *allocator {
+// NewAllocator constructs a job allocator. The organization quota is the
+// maximum number of jobs belonging to an organization that server runners may
+// execute concurrently; zero means there is no quota.
+func (s *Service) NewAllocator(logger logr.Logger, organizationQuota int) *allocator {
 	return &allocator{
-		Logger: logger,
-		client: s,
+		Logger:            logger,
+		client:            s,
+		organizationQuota: organizationQuota,
 	}
 }
 
@@ -411,6 +415,47 @@ func (s *Service) reallocateJob(ctx context.Context, jobID resource.TfeID, runne
 	return reallocated, nil
 }
 
+// deallocateJob returns an allocated job to the queue, making way for a job
+// with a higher priority.
+func (s *Service) deallocateJob(ctx context.Context, jobID resource.TfeID) (*Job, error) {
+	var from resource.TfeID // ID of runner that job *was* allocated to
+	deallocated, err := s.db.updateJob(ctx, jobID, func(ctx context.Context, job *Job) error {
+		if job.RunnerID != nil {
+			from = *job.RunnerID
+		}
+		return job.deallocate()
+	})
+	if err != nil {
+		s.Error(err, "de-allocating job", "job_id", jobID, "from", from)
+		return nil, err
+	}
+	s.V(0).Info("de-allocated job", "job", deallocated, "from", from)
+	return deallocated, nil
+}
+
+func (s *Service) setJobQueue(ctx context.Context, queue []QueuePosition) error {
+	return s.db.setJobQueue(ctx, queue)
+}
+
+// GetRunQueuePosition retrieves the position in the queue of the job for a
+// run that is waiting to be allocated to a runner. If the run has no such job
+// then internal.ErrResourceNotFound is returned.
+func (s *Service) GetRunQueuePosition(ctx context.Context, runID resource.TfeID) (*QueuePosition, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.RunKind, runID)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := s.db.getRunQueuePosition(ctx, runID)
+	if err != nil {
+		if !errors.Is(err, internal.ErrResourceNotFound) {
+			s.Error(err, "retrieving run queue position", "run_id", runID, "subject", subject)
+		}
+		return nil, err
+	}
+	s.V(9).Info("retrieved run queue position", "run_id", runID, "position", pos, "subject", subject)
+	return pos, nil
+}
+
 // StartJob starts a job and returns a job token with permissions to
 // carry out the job. Only a runner that has been allocated the job can
 // call this method.
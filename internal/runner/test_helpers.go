@@ -72,6 +72,17 @@ func (f *fakeService) reallocateJob(ctx context.Context, jobID resource.TfeID, a
 	return f.job, nil
 }
 
+func (f *fakeService) deallocateJob(ctx context.Context, jobID resource.TfeID) (*Job, error) {
+	if err := f.job.deallocate(); err != nil {
+		return nil, err
+	}
+	return f.job, nil
+}
+
+func (f *fakeService) setJobQueue(context.Context, []QueuePosition) error {
+	return nil
+}
+
 func (f *fakeService) GetJob(ctx context.Context, jobID resource.TfeID) (*Job, error) {
 	return f.job, nil
 }
@@ -3,11 +3,13 @@ package ui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
 	"slices"
 
 	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/http/decode"
 	"github.com/leg100/otf/internal/organization"
@@ -18,6 +20,10 @@ import (
 	"github.com/leg100/otf/internal/workspace"
 )
 
+// stopPollingStatus is the HTTP status code that instructs htmx to stop
+// polling.
+const stopPollingStatus = 286
+
 type Handlers struct {
 	Client     Client
 	Authorizer authz.Interface
@@ -33,6 +39,7 @@ type Client interface {
 	CreateAgentToken(ctx context.Context, poolID resource.TfeID, opts runner.CreateAgentTokenOptions) (*runner.AgentToken, []byte, error)
 	ListAgentTokens(ctx context.Context, poolID resource.TfeID) ([]*runner.AgentToken, error)
 	DeleteAgentToken(ctx context.Context, tokenID resource.TfeID) (*runner.AgentToken, error)
+	GetRunQueuePosition(ctx context.Context, runID resource.TfeID) (*runner.QueuePosition, error)
 	GetWorkspace(context.Context, resource.TfeID) (*workspace.Workspace, error)
 	ListWorkspaces(ctx context.Context, opts workspace.ListOptions) (*resource.Page[*workspace.Workspace], error)
 }
@@ -52,6 +59,9 @@ func (h *Handlers) AddHandlers(r *mux.Router) {
 	// agent tokens
 	r.HandleFunc("/agent-pools/{pool_id}/agent-tokens/create", h.createAgentToken).Methods("POST")
 	r.HandleFunc("/agent-tokens/{token_id}/delete", h.deleteAgentToken).Methods("POST")
+
+	// job queue
+	r.HandleFunc("/runs/{run_id}/queue", h.getRunQueuePosition).Methods("GET")
 }
 
 type (
@@ -375,3 +385,25 @@ func toJSON(v any) string {
 	b, _ := json.Marshal(v)
 	return string(b)
 }
+
+// getRunQueuePosition renders the position of a run's job in the queue, for
+// embedding in the run page.
+func (h *Handlers) getRunQueuePosition(w http.ResponseWriter, r *http.Request) {
+	runID, err := decode.ID("run_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	pos, err := h.Client.GetRunQueuePosition(r.Context(), runID)
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		// Run has no job awaiting allocation. Render nothing and tell htmx to
+		// stop polling.
+		w.WriteHeader(stopPollingStatus)
+		return
+	} else if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	helpers.Render(queuePosition(pos), w, r)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/runner"
@@ -83,12 +84,51 @@ func TestRunnerHandlers_deleteAgentToken(t *testing.T) {
 	testutils.AssertRedirect(t, w, path.Get(agentPoolID))
 }
 
+func TestRunnerHandlers_getRunQueuePosition(t *testing.T) {
+	runID := resource.NewTfeID(resource.RunKind)
+
+	t.Run("queued", func(t *testing.T) {
+		h := &Handlers{
+			Client: &fakeClient{
+				queuePosition: &runner.QueuePosition{Position: 3, Reason: runner.QueueAwaitingCapacity},
+			},
+		}
+		r := httptest.NewRequest("GET", "/?run_id="+runID.String(), nil)
+		w := httptest.NewRecorder()
+
+		h.getRunQueuePosition(w, r)
+
+		assert.Equal(t, 200, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "#3 in queue")
+		assert.Contains(t, w.Body.String(), runner.QueueAwaitingCapacity.Description())
+	})
+
+	t.Run("not queued", func(t *testing.T) {
+		h := &Handlers{Client: &fakeClient{}}
+		r := httptest.NewRequest("GET", "/?run_id="+runID.String(), nil)
+		w := httptest.NewRecorder()
+
+		h.getRunQueuePosition(w, r)
+
+		assert.Equal(t, stopPollingStatus, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
 type fakeClient struct {
 	Client
 	pool                   *runner.Pool
 	createAgentPoolOptions runner.CreateAgentPoolOptions
 	at                     *runner.AgentToken
 	token                  []byte
+	queuePosition          *runner.QueuePosition
+}
+
+func (f *fakeClient) GetRunQueuePosition(context.Context, resource.TfeID) (*runner.QueuePosition, error) {
+	if f.queuePosition == nil {
+		return nil, internal.ErrResourceNotFound
+	}
+	return f.queuePosition, nil
 }
 
 func (f *fakeClient) CreateAgentPool(ctx context.Context, opts runner.CreateAgentPoolOptions) (*runner.Pool, error) {
@@ -19,6 +19,8 @@ var themeMappings = map[runstatus.Status]string{
 	runstatus.Applying:           "accent",
 	runstatus.Canceled:           "warning",
 	runstatus.Confirmed:          "info",
+	runstatus.CostEstimating:     "primary",
+	runstatus.CostEstimated:      "info",
 	runstatus.Discarded:          "warning",
 	runstatus.Errored:            "error",
 	runstatus.ForceCanceled:      "warning",
@@ -27,6 +29,15 @@ var themeMappings = map[runstatus.Status]string{
 	runstatus.Planned:            "info",
 	runstatus.PlannedAndFinished: "success",
 	runstatus.Planning:           "primary",
+	runstatus.PolicyChecking:     "primary",
+	runstatus.PolicyChecked:      "info",
+	runstatus.PolicyOverride:     "warning",
+	runstatus.PrePlanRunning:     "primary",
+	runstatus.PrePlanCompleted:   "info",
+	runstatus.PostPlanRunning:    "primary",
+	runstatus.PostPlanCompleted:  "info",
+	runstatus.PreApplyRunning:    "primary",
+	runstatus.PreApplyCompleted:  "info",
 }
 
 var components = []string{
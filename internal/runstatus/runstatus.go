@@ -13,6 +13,8 @@ const (
 	Applying           Status = "applying"
 	Canceled           Status = "canceled"
 	Confirmed          Status = "confirmed"
+	CostEstimating     Status = "cost_estimating"
+	CostEstimated      Status = "cost_estimated"
 	Discarded          Status = "discarded"
 	Errored            Status = "errored"
 	ForceCanceled      Status = "force_canceled"
@@ -21,10 +23,15 @@ const (
 	Planned            Status = "planned"
 	PlannedAndFinished Status = "planned_and_finished"
 	Planning           Status = "planning"
-
-	// OTF doesn't support cost estimation but go-tfe API tests expect this
-	// status so it is included expressly to pass the tests.
-	CostEstimated Status = "cost_estimated"
+	PolicyChecking     Status = "policy_checking"
+	PolicyChecked      Status = "policy_checked"
+	PolicyOverride     Status = "policy_override"
+	PrePlanRunning     Status = "pre_plan_running"
+	PrePlanCompleted   Status = "pre_plan_completed"
+	PostPlanRunning    Status = "post_plan_running"
+	PostPlanCompleted  Status = "post_plan_completed"
+	PreApplyRunning    Status = "pre_apply_running"
+	PreApplyCompleted  Status = "pre_apply_completed"
 )
 
 func (s Status) String() string { return string(s) }
@@ -36,6 +43,8 @@ func All() []Status {
 		Applying,
 		Canceled,
 		Confirmed,
+		CostEstimating,
+		CostEstimated,
 		Discarded,
 		Errored,
 		ForceCanceled,
@@ -44,6 +53,15 @@ func All() []Status {
 		Planned,
 		PlannedAndFinished,
 		Planning,
+		PolicyChecking,
+		PolicyChecked,
+		PolicyOverride,
+		PrePlanRunning,
+		PrePlanCompleted,
+		PostPlanRunning,
+		PostPlanCompleted,
+		PreApplyRunning,
+		PreApplyCompleted,
 	}
 }
 
@@ -0,0 +1,502 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/runtask"
+	"github.com/leg100/otf/internal/tfeapi"
+	"github.com/leg100/otf/internal/workspace"
+)
+
+// taskCategory is the category of run tasks reported via the API.
+const taskCategory = "task"
+
+type TFEAPI struct {
+	*tfeapi.Responder
+	Client tfeClient
+}
+
+type tfeClient interface {
+	CreateTask(ctx context.Context, org organization.Name, opts runtask.CreateTaskOptions) (*runtask.Task, error)
+	GetTask(ctx context.Context, id resource.TfeID) (*runtask.Task, error)
+	ListTasks(ctx context.Context, org organization.Name) ([]*runtask.Task, error)
+	UpdateTask(ctx context.Context, id resource.TfeID, opts runtask.UpdateTaskOptions) (*runtask.Task, error)
+	DeleteTask(ctx context.Context, id resource.TfeID) (*runtask.Task, error)
+
+	CreateWorkspaceTask(ctx context.Context, workspaceID resource.TfeID, opts runtask.CreateWorkspaceTaskOptions) (*runtask.WorkspaceTask, error)
+	GetWorkspaceTask(ctx context.Context, id resource.TfeID) (*runtask.WorkspaceTask, error)
+	ListWorkspaceTasks(ctx context.Context, workspaceID resource.TfeID) ([]*runtask.WorkspaceTask, error)
+	UpdateWorkspaceTask(ctx context.Context, id resource.TfeID, opts runtask.UpdateWorkspaceTaskOptions) (*runtask.WorkspaceTask, error)
+	DeleteWorkspaceTask(ctx context.Context, id resource.TfeID) (*runtask.WorkspaceTask, error)
+
+	ListRunStages(ctx context.Context, runID resource.TfeID) ([]*runtask.Stage, error)
+	GetStage(ctx context.Context, id resource.TfeID) (*runtask.Stage, error)
+	GetResult(ctx context.Context, id resource.TfeID) (*runtask.Result, error)
+	Callback(ctx context.Context, resultID resource.TfeID, opts runtask.CallbackOptions) (*runtask.Result, error)
+}
+
+// tfeTaskCreateOptions are the options for creating a new run task via the
+// TFE API.
+type tfeTaskCreateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,tasks"`
+
+	Name        *string `jsonapi:"attribute" json:"name"`
+	URL         *string `jsonapi:"attribute" json:"url"`
+	Description *string `jsonapi:"attribute" json:"description,omitempty"`
+	Category    *string `jsonapi:"attribute" json:"category,omitempty"`
+	HMACKey     *string `jsonapi:"attribute" json:"hmac-key,omitempty"`
+	Enabled     *bool   `jsonapi:"attribute" json:"enabled,omitempty"`
+}
+
+// tfeTaskUpdateOptions are the options for updating a run task via the TFE
+// API.
+type tfeTaskUpdateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,tasks"`
+
+	Name        *string `jsonapi:"attribute" json:"name,omitempty"`
+	URL         *string `jsonapi:"attribute" json:"url,omitempty"`
+	Description *string `jsonapi:"attribute" json:"description,omitempty"`
+	Category    *string `jsonapi:"attribute" json:"category,omitempty"`
+	HMACKey     *string `jsonapi:"attribute" json:"hmac-key,omitempty"`
+	Enabled     *bool   `jsonapi:"attribute" json:"enabled,omitempty"`
+}
+
+// tfeWorkspaceTaskCreateOptions are the options for attaching a run task to a
+// workspace via the TFE API.
+type tfeWorkspaceTaskCreateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,workspace-tasks"`
+
+	EnforcementLevel runtask.EnforcementLevel `jsonapi:"attribute" json:"enforcement-level"`
+	Stage            *run.TaskStage           `jsonapi:"attribute" json:"stage,omitempty"`
+
+	// Relations
+	Task *runtask.TFETask `jsonapi:"relationship" json:"task"`
+}
+
+// tfeWorkspaceTaskUpdateOptions are the options for updating the attachment
+// of a run task to a workspace via the TFE API.
+type tfeWorkspaceTaskUpdateOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,workspace-tasks"`
+
+	EnforcementLevel *runtask.EnforcementLevel `jsonapi:"attribute" json:"enforcement-level,omitempty"`
+	Stage            *run.TaskStage            `jsonapi:"attribute" json:"stage,omitempty"`
+}
+
+// tfeTaskResultCallbackOptions are the options with which an external service
+// reports the outcome of a task via the TFE API.
+type tfeTaskResultCallbackOptions struct {
+	// Type is used by JSON:API to set the resource type.
+	Type string `jsonapi:"primary,task-results"`
+
+	Status  runtask.ResultStatus `jsonapi:"attribute" json:"status"`
+	Message string               `jsonapi:"attribute" json:"message,omitempty"`
+	URL     *string              `jsonapi:"attribute" json:"url,omitempty"`
+}
+
+func (a *TFEAPI) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/tasks", a.createTask).Methods("POST")
+	r.HandleFunc("/organizations/{organization_name}/tasks", a.listTasks).Methods("GET")
+	r.HandleFunc("/tasks/{task_id}", a.getTask).Methods("GET")
+	r.HandleFunc("/tasks/{task_id}", a.updateTask).Methods("PATCH")
+	r.HandleFunc("/tasks/{task_id}", a.deleteTask).Methods("DELETE")
+
+	r.HandleFunc("/workspaces/{workspace_id}/tasks", a.createWorkspaceTask).Methods("POST")
+	r.HandleFunc("/workspaces/{workspace_id}/tasks", a.listWorkspaceTasks).Methods("GET")
+	r.HandleFunc("/workspaces/{workspace_id}/tasks/{workspace_task_id}", a.getWorkspaceTask).Methods("GET")
+	r.HandleFunc("/workspaces/{workspace_id}/tasks/{workspace_task_id}", a.updateWorkspaceTask).Methods("PATCH")
+	r.HandleFunc("/workspaces/{workspace_id}/tasks/{workspace_task_id}", a.deleteWorkspaceTask).Methods("DELETE")
+
+	r.HandleFunc("/runs/{run_id}/task-stages", a.listTaskStages).Methods("GET")
+	r.HandleFunc("/task-stages/{task_stage_id}", a.getTaskStage).Methods("GET")
+	r.HandleFunc("/task-results/{task_result_id}", a.getTaskResult).Methods("GET")
+	r.HandleFunc("/task-results/{task_result_id}/callback", a.callback).Methods("PATCH")
+}
+
+func (a *TFEAPI) createTask(w http.ResponseWriter, r *http.Request) {
+	var pathParams struct {
+		Organization organization.Name `schema:"organization_name,required"`
+	}
+	if err := decode.Route(&pathParams, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeTaskCreateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if params.Category != nil && *params.Category != taskCategory {
+		tfeapi.Error(w, errors.New("category must be task"), tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	opts := runtask.CreateTaskOptions{
+		HMACKey: params.HMACKey,
+		Enabled: params.Enabled,
+	}
+	if params.Name != nil {
+		opts.Name = *params.Name
+	}
+	if params.URL != nil {
+		opts.URL = *params.URL
+	}
+	if params.Description != nil {
+		opts.Description = *params.Description
+	}
+	task, err := a.Client.CreateTask(r.Context(), pathParams.Organization, opts)
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toTask(task), http.StatusCreated)
+}
+
+func (a *TFEAPI) listTasks(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+		resource.PageOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	tasks, err := a.Client.ListTasks(r.Context(), params.Organization)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	items := make([]*runtask.TFETask, len(tasks))
+	for i, task := range tasks {
+		items[i] = a.toTask(task)
+	}
+	page := resource.NewPage(items, params.PageOptions, nil)
+	a.RespondWithPage(w, r, page.Items, page.Pagination)
+}
+
+func (a *TFEAPI) getTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	task, err := a.Client.GetTask(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toTask(task), http.StatusOK)
+}
+
+func (a *TFEAPI) updateTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeTaskUpdateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if params.Category != nil && *params.Category != taskCategory {
+		tfeapi.Error(w, errors.New("category must be task"), tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	task, err := a.Client.UpdateTask(r.Context(), id, runtask.UpdateTaskOptions{
+		Name:        params.Name,
+		URL:         params.URL,
+		Description: params.Description,
+		HMACKey:     params.HMACKey,
+		Enabled:     params.Enabled,
+	})
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toTask(task), http.StatusOK)
+}
+
+func (a *TFEAPI) deleteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if _, err := a.Client.DeleteTask(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *TFEAPI) createWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	workspaceID, err := decode.ID("workspace_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeWorkspaceTaskCreateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if params.Task == nil {
+		tfeapi.Error(w, errors.New("must specify task relationship"), tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	opts := runtask.CreateWorkspaceTaskOptions{
+		TaskID:           params.Task.ID,
+		EnforcementLevel: params.EnforcementLevel,
+	}
+	if params.Stage != nil {
+		opts.Stage = *params.Stage
+	}
+	wt, err := a.Client.CreateWorkspaceTask(r.Context(), workspaceID, opts)
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toWorkspaceTask(wt), http.StatusCreated)
+}
+
+func (a *TFEAPI) listWorkspaceTasks(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		WorkspaceID resource.TfeID `schema:"workspace_id,required"`
+		resource.PageOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	wts, err := a.Client.ListWorkspaceTasks(r.Context(), params.WorkspaceID)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	items := make([]*runtask.TFEWorkspaceTask, len(wts))
+	for i, wt := range wts {
+		items[i] = a.toWorkspaceTask(wt)
+	}
+	page := resource.NewPage(items, params.PageOptions, nil)
+	a.RespondWithPage(w, r, page.Items, page.Pagination)
+}
+
+func (a *TFEAPI) getWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("workspace_task_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	wt, err := a.Client.GetWorkspaceTask(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toWorkspaceTask(wt), http.StatusOK)
+}
+
+func (a *TFEAPI) updateWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("workspace_task_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeWorkspaceTaskUpdateOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	wt, err := a.Client.UpdateWorkspaceTask(r.Context(), id, runtask.UpdateWorkspaceTaskOptions{
+		EnforcementLevel: params.EnforcementLevel,
+		Stage:            params.Stage,
+	})
+	if err != nil {
+		a.error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toWorkspaceTask(wt), http.StatusOK)
+}
+
+func (a *TFEAPI) deleteWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("workspace_task_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	if _, err := a.Client.DeleteWorkspaceTask(r.Context(), id); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *TFEAPI) listTaskStages(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		RunID resource.TfeID `schema:"run_id,required"`
+		resource.PageOptions
+	}
+	if err := decode.All(&params, r); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	stages, err := a.Client.ListRunStages(r.Context(), params.RunID)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	items := make([]*runtask.TFETaskStage, len(stages))
+	for i, stage := range stages {
+		items[i] = a.toTaskStage(stage)
+	}
+	page := resource.NewPage(items, params.PageOptions, nil)
+	a.RespondWithPage(w, r, page.Items, page.Pagination)
+}
+
+func (a *TFEAPI) getTaskStage(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_stage_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	stage, err := a.Client.GetStage(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toTaskStage(stage), http.StatusOK)
+}
+
+func (a *TFEAPI) getTaskResult(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_result_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	result, err := a.Client.GetResult(r.Context(), id)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	a.Respond(w, r, a.toTaskResult(result), http.StatusOK)
+}
+
+// callback is called by an external service to report the outcome of a task,
+// authenticating with the access token sent to the service when the task was
+// invoked.
+func (a *TFEAPI) callback(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_result_id", r)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	var params tfeTaskResultCallbackOptions
+	if err := tfeapi.Unmarshal(r.Body, &params); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	_, err = a.Client.Callback(r.Context(), id, runtask.CallbackOptions{
+		Status:  params.Status,
+		Message: params.Message,
+		URL:     params.URL,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, runtask.ErrInvalidCallbackStatus):
+			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+		case errors.Is(err, runtask.ErrResultFinished):
+			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusConflict))
+		default:
+			tfeapi.Error(w, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// error reports validation errors as 422s, and all other errors per
+// tfeapi.Error.
+func (a *TFEAPI) error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, runtask.ErrInvalidEnforcementLevel),
+		errors.Is(err, runtask.ErrInvalidStage),
+		errors.Is(err, runtask.ErrInvalidURL),
+		errors.Is(err, runtask.ErrTaskOrganizationMismatch):
+		tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusUnprocessableEntity))
+	default:
+		tfeapi.Error(w, err)
+	}
+}
+
+func (a *TFEAPI) toTask(from *runtask.Task) *runtask.TFETask {
+	// The HMAC key is write-only and is never returned.
+	return &runtask.TFETask{
+		ID:           from.ID,
+		Name:         from.Name,
+		URL:          from.URL,
+		Description:  from.Description,
+		Category:     taskCategory,
+		Enabled:      from.Enabled,
+		Organization: &organization.TFEOrganization{Name: from.Organization},
+	}
+}
+
+func (a *TFEAPI) toWorkspaceTask(from *runtask.WorkspaceTask) *runtask.TFEWorkspaceTask {
+	return &runtask.TFEWorkspaceTask{
+		ID:               from.ID,
+		EnforcementLevel: from.EnforcementLevel,
+		Stage:            from.Stage,
+		Task:             &runtask.TFETask{ID: from.TaskID},
+		Workspace:        &workspace.TFEWorkspace{ID: from.WorkspaceID},
+	}
+}
+
+func (a *TFEAPI) toTaskStage(from *runtask.Stage) *runtask.TFETaskStage {
+	to := &runtask.TFETaskStage{
+		ID:     from.ID,
+		Stage:  from.Stage,
+		Status: from.Status,
+		StatusTimestamps: &runtask.TFETaskStageStatusTimestamps{
+			RunningAt: &from.CreatedAt,
+		},
+		CreatedAt:   from.CreatedAt,
+		UpdatedAt:   from.UpdatedAt,
+		Run:         &run.TFERun{ID: from.RunID},
+		TaskResults: make([]*runtask.TFETaskResult, len(from.Results)),
+	}
+	switch from.Status {
+	case runtask.StagePassed:
+		to.StatusTimestamps.PassedAt = &from.UpdatedAt
+	case runtask.StageFailed:
+		to.StatusTimestamps.FailedAt = &from.UpdatedAt
+	}
+	for i, result := range from.Results {
+		to.TaskResults[i] = &runtask.TFETaskResult{ID: result.ID}
+	}
+	return to
+}
+
+func (a *TFEAPI) toTaskResult(from *runtask.Result) *runtask.TFETaskResult {
+	return &runtask.TFETaskResult{
+		ID:                            from.ID,
+		Status:                        from.Status,
+		Message:                       from.Message,
+		URL:                           from.URL,
+		CreatedAt:                     from.CreatedAt,
+		UpdatedAt:                     from.UpdatedAt,
+		TaskID:                        from.TaskID,
+		TaskName:                      from.TaskName,
+		TaskURL:                       from.TaskURL,
+		WorkspaceTaskEnforcementLevel: from.EnforcementLevel,
+		TaskStage:                     &runtask.TFETaskStage{ID: from.StageID},
+	}
+}
@@ -0,0 +1,553 @@
+package runtask
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/encryption"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type pgdb struct {
+	*sql.DB
+
+	// cipher encrypts the HMAC keys of tasks
+	cipher *encryption.Cipher
+}
+
+func (db *pgdb) createTask(ctx context.Context, task *Task) error {
+	hmacKey, err := db.encryptHMACKey(ctx, task)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, `
+INSERT INTO run_tasks (
+    run_task_id,
+    created_at,
+    updated_at,
+    organization_name,
+    name,
+    description,
+    url,
+    hmac_key,
+    enabled
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @organization_name,
+    @name,
+    @description,
+    @url,
+    @hmac_key,
+    @enabled
+)
+`,
+		pgx.NamedArgs{
+			"id":                task.ID,
+			"created_at":        task.CreatedAt,
+			"updated_at":        task.UpdatedAt,
+			"organization_name": task.Organization,
+			"name":              task.Name,
+			"description":       task.Description,
+			"url":               task.URL,
+			"hmac_key":          hmacKey,
+			"enabled":           task.Enabled,
+		},
+	)
+	return err
+}
+
+func (db *pgdb) updateTask(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *Task) error) (*Task, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*Task, error) {
+			rows := db.Query(ctx, `
+SELECT run_task_id, created_at, updated_at, organization_name, name, description, url, hmac_key, enabled
+FROM run_tasks
+WHERE run_task_id = $1
+FOR UPDATE
+`, id)
+			return db.collectTask(ctx, rows)
+		},
+		updateFunc,
+		func(ctx context.Context, task *Task) error {
+			hmacKey, err := db.encryptHMACKey(ctx, task)
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec(ctx, `
+UPDATE run_tasks
+SET name = @name,
+    description = @description,
+    url = @url,
+    hmac_key = @hmac_key,
+    enabled = @enabled,
+    updated_at = @updated_at
+WHERE run_task_id = @id
+`,
+				pgx.NamedArgs{
+					"id":          task.ID,
+					"name":        task.Name,
+					"description": task.Description,
+					"url":         task.URL,
+					"hmac_key":    hmacKey,
+					"enabled":     task.Enabled,
+					"updated_at":  task.UpdatedAt,
+				},
+			)
+			return err
+		},
+	)
+}
+
+func (db *pgdb) getTask(ctx context.Context, id resource.TfeID) (*Task, error) {
+	rows := db.Query(ctx, `
+SELECT run_task_id, created_at, updated_at, organization_name, name, description, url, hmac_key, enabled
+FROM run_tasks
+WHERE run_task_id = $1
+`, id)
+	return db.collectTask(ctx, rows)
+}
+
+func (db *pgdb) listTasks(ctx context.Context, org organization.Name) ([]*Task, error) {
+	rows := db.Query(ctx, `
+SELECT run_task_id, created_at, updated_at, organization_name, name, description, url, hmac_key, enabled
+FROM run_tasks
+WHERE organization_name = $1
+ORDER BY name
+`, org)
+	tasks, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Task])
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if err := db.decryptHMACKey(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+func (db *pgdb) deleteTask(ctx context.Context, id resource.TfeID) (*Task, error) {
+	rows := db.Query(ctx, `
+DELETE FROM run_tasks
+WHERE run_task_id = $1
+RETURNING run_task_id, created_at, updated_at, organization_name, name, description, url, hmac_key, enabled
+`, id)
+	return db.collectTask(ctx, rows)
+}
+
+func (db *pgdb) collectTask(ctx context.Context, rows pgx.Rows) (*Task, error) {
+	task, err := sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Task])
+	if err != nil {
+		return nil, err
+	}
+	if err := db.decryptHMACKey(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// encryptHMACKey returns the HMAC key of the task to be written to the
+// database, encrypting it if the task has a key.
+func (db *pgdb) encryptHMACKey(ctx context.Context, task *Task) (*string, error) {
+	if task.HMACKey == nil {
+		return nil, nil
+	}
+	key, err := db.cipher.EncryptString(ctx, *task.HMACKey)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting hmac key of task %s: %w", task.ID, err)
+	}
+	return &key, nil
+}
+
+// decryptHMACKey decrypts the HMAC key of a task read from the database.
+func (db *pgdb) decryptHMACKey(ctx context.Context, task *Task) error {
+	if task.HMACKey == nil {
+		return nil
+	}
+	key, err := db.cipher.DecryptString(ctx, *task.HMACKey)
+	if err != nil {
+		return fmt.Errorf("decrypting hmac key of task %s: %w", task.ID, err)
+	}
+	task.HMACKey = &key
+	return nil
+}
+
+func (db *pgdb) createWorkspaceTask(ctx context.Context, wt *WorkspaceTask) error {
+	_, err := db.Exec(ctx, `
+INSERT INTO workspace_run_tasks (
+    workspace_run_task_id,
+    created_at,
+    updated_at,
+    workspace_id,
+    run_task_id,
+    stage,
+    enforcement_level
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @workspace_id,
+    @run_task_id,
+    @stage,
+    @enforcement_level
+)
+`,
+		pgx.NamedArgs{
+			"id":                wt.ID,
+			"created_at":        wt.CreatedAt,
+			"updated_at":        wt.UpdatedAt,
+			"workspace_id":      wt.WorkspaceID,
+			"run_task_id":       wt.TaskID,
+			"stage":             wt.Stage,
+			"enforcement_level": wt.EnforcementLevel,
+		},
+	)
+	return err
+}
+
+func (db *pgdb) updateWorkspaceTask(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *WorkspaceTask) error) (*WorkspaceTask, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*WorkspaceTask, error) {
+			rows := db.Query(ctx, `
+SELECT workspace_run_task_id, created_at, updated_at, workspace_id, run_task_id, stage, enforcement_level
+FROM workspace_run_tasks
+WHERE workspace_run_task_id = $1
+FOR UPDATE
+`, id)
+			return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[WorkspaceTask])
+		},
+		updateFunc,
+		func(ctx context.Context, wt *WorkspaceTask) error {
+			_, err := db.Exec(ctx, `
+UPDATE workspace_run_tasks
+SET stage = @stage,
+    enforcement_level = @enforcement_level,
+    updated_at = @updated_at
+WHERE workspace_run_task_id = @id
+`,
+				pgx.NamedArgs{
+					"id":                wt.ID,
+					"stage":             wt.Stage,
+					"enforcement_level": wt.EnforcementLevel,
+					"updated_at":        wt.UpdatedAt,
+				},
+			)
+			return err
+		},
+	)
+}
+
+func (db *pgdb) getWorkspaceTask(ctx context.Context, id resource.TfeID) (*WorkspaceTask, error) {
+	rows := db.Query(ctx, `
+SELECT workspace_run_task_id, created_at, updated_at, workspace_id, run_task_id, stage, enforcement_level
+FROM workspace_run_tasks
+WHERE workspace_run_task_id = $1
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[WorkspaceTask])
+}
+
+func (db *pgdb) listWorkspaceTasks(ctx context.Context, workspaceID resource.TfeID) ([]*WorkspaceTask, error) {
+	rows := db.Query(ctx, `
+SELECT wrt.workspace_run_task_id, wrt.created_at, wrt.updated_at, wrt.workspace_id, wrt.run_task_id, wrt.stage, wrt.enforcement_level
+FROM workspace_run_tasks wrt
+JOIN run_tasks rt USING (run_task_id)
+WHERE wrt.workspace_id = $1
+ORDER BY rt.name
+`, workspaceID)
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[WorkspaceTask])
+}
+
+func (db *pgdb) deleteWorkspaceTask(ctx context.Context, id resource.TfeID) (*WorkspaceTask, error) {
+	rows := db.Query(ctx, `
+DELETE FROM workspace_run_tasks
+WHERE workspace_run_task_id = $1
+RETURNING workspace_run_task_id, created_at, updated_at, workspace_id, run_task_id, stage, enforcement_level
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[WorkspaceTask])
+}
+
+// listStageTasks lists the enabled tasks attached to a workspace at a stage.
+func (db *pgdb) listStageTasks(ctx context.Context, workspaceID resource.TfeID, stage run.TaskStage) ([]stageTask, error) {
+	rows := db.Query(ctx, `
+SELECT rt.run_task_id, rt.created_at, rt.updated_at, rt.organization_name, rt.name, rt.description, rt.url, rt.hmac_key, rt.enabled, wrt.enforcement_level
+FROM workspace_run_tasks wrt
+JOIN run_tasks rt USING (run_task_id)
+WHERE wrt.workspace_id = $1
+AND wrt.stage = $2
+AND rt.enabled
+ORDER BY rt.name
+`, workspaceID, stage)
+	tasks, err := sql.CollectRows(rows, func(row pgx.CollectableRow) (stageTask, error) {
+		var (
+			task  Task
+			level EnforcementLevel
+		)
+		err := row.Scan(
+			&task.ID,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&task.Organization,
+			&task.Name,
+			&task.Description,
+			&task.URL,
+			&task.HMACKey,
+			&task.Enabled,
+			&level,
+		)
+		return stageTask{Task: &task, EnforcementLevel: level}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if err := db.decryptHMACKey(ctx, task.Task); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// createStage persists a stage along with its results.
+func (db *pgdb) createStage(ctx context.Context, stage *Stage) error {
+	return db.Tx(ctx, func(ctx context.Context) error {
+		_, err := db.Exec(ctx, `
+INSERT INTO task_stages (
+    task_stage_id,
+    created_at,
+    updated_at,
+    run_id,
+    stage,
+    status
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @run_id,
+    @stage,
+    @status
+)
+`,
+			pgx.NamedArgs{
+				"id":         stage.ID,
+				"created_at": stage.CreatedAt,
+				"updated_at": stage.UpdatedAt,
+				"run_id":     stage.RunID,
+				"stage":      stage.Stage,
+				"status":     stage.Status,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		for _, result := range stage.Results {
+			_, err := db.Exec(ctx, `
+INSERT INTO task_results (
+    task_result_id,
+    created_at,
+    updated_at,
+    task_stage_id,
+    run_task_id,
+    task_name,
+    task_url,
+    enforcement_level,
+    status,
+    message,
+    url
+) VALUES (
+    @id,
+    @created_at,
+    @updated_at,
+    @task_stage_id,
+    @run_task_id,
+    @task_name,
+    @task_url,
+    @enforcement_level,
+    @status,
+    @message,
+    @url
+)
+`,
+				pgx.NamedArgs{
+					"id":                result.ID,
+					"created_at":        result.CreatedAt,
+					"updated_at":        result.UpdatedAt,
+					"task_stage_id":     result.StageID,
+					"run_task_id":       result.TaskID,
+					"task_name":         result.TaskName,
+					"task_url":          result.TaskURL,
+					"enforcement_level": result.EnforcementLevel,
+					"status":            result.Status,
+					"message":           result.Message,
+					"url":               result.URL,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *pgdb) updateStage(ctx context.Context, id resource.TfeID, updateFunc func(context.Context, *Stage) error) (*Stage, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*Stage, error) {
+			// Lock the stage row, serializing concurrent updates to the
+			// stage's results.
+			rows := db.Query(ctx, `
+SELECT task_stage_id, created_at, updated_at, run_id, stage, status
+FROM task_stages
+WHERE task_stage_id = $1
+FOR UPDATE
+`, id)
+			stage, err := sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Stage])
+			if err != nil {
+				return nil, err
+			}
+			stage.Results, err = db.listResults(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return stage, nil
+		},
+		updateFunc,
+		func(ctx context.Context, stage *Stage) error {
+			_, err := db.Exec(ctx, `
+UPDATE task_stages
+SET status = @status,
+    updated_at = @updated_at
+WHERE task_stage_id = @id
+`,
+				pgx.NamedArgs{
+					"id":         stage.ID,
+					"status":     stage.Status,
+					"updated_at": stage.UpdatedAt,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			for _, result := range stage.Results {
+				_, err := db.Exec(ctx, `
+UPDATE task_results
+SET status = @status,
+    message = @message,
+    url = @url,
+    updated_at = @updated_at
+WHERE task_result_id = @id
+`,
+					pgx.NamedArgs{
+						"id":         result.ID,
+						"status":     result.Status,
+						"message":    result.Message,
+						"url":        result.URL,
+						"updated_at": result.UpdatedAt,
+					},
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func (db *pgdb) getStage(ctx context.Context, id resource.TfeID) (*Stage, error) {
+	rows := db.Query(ctx, `
+SELECT task_stage_id, created_at, updated_at, run_id, stage, status
+FROM task_stages
+WHERE task_stage_id = $1
+`, id)
+	stage, err := sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Stage])
+	if err != nil {
+		return nil, err
+	}
+	stage.Results, err = db.listResults(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return stage, nil
+}
+
+// listStages lists the stages of a run, in the order in which they occur.
+func (db *pgdb) listStages(ctx context.Context, runID resource.TfeID) ([]*Stage, error) {
+	rows := db.Query(ctx, `
+SELECT task_stage_id, created_at, updated_at, run_id, stage, status
+FROM task_stages
+WHERE run_id = $1
+ORDER BY created_at
+`, runID)
+	stages, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Stage])
+	if err != nil {
+		return nil, err
+	}
+	for _, stage := range stages {
+		stage.Results, err = db.listResults(ctx, stage.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stages, nil
+}
+
+// listTimedOutStages lists running stages that were created before the given
+// time.
+func (db *pgdb) listTimedOutStages(ctx context.Context, before time.Time) ([]resource.TfeID, error) {
+	rows := db.Query(ctx, `
+SELECT task_stage_id
+FROM task_stages
+WHERE status = $1
+AND created_at < $2
+`, StageRunning, before)
+	return sql.CollectRows(rows, pgx.RowTo[resource.TfeID])
+}
+
+func (db *pgdb) listResults(ctx context.Context, stageID resource.TfeID) ([]*Result, error) {
+	rows := db.Query(ctx, `
+SELECT task_result_id, created_at, updated_at, task_stage_id, run_task_id, task_name, task_url, enforcement_level, status, message, url
+FROM task_results
+WHERE task_stage_id = $1
+ORDER BY task_name
+`, stageID)
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Result])
+}
+
+func (db *pgdb) getResult(ctx context.Context, id resource.TfeID) (*Result, error) {
+	rows := db.Query(ctx, `
+SELECT task_result_id, created_at, updated_at, task_stage_id, run_task_id, task_name, task_url, enforcement_level, status, message, url
+FROM task_results
+WHERE task_result_id = $1
+`, id)
+	return sql.CollectOneRow(rows, pgx.RowToAddrOfStructByName[Result])
+}
+
+// getCallbackSubject retrieves the subject of the access token sent to an
+// external service, identified by the ID of the task result.
+func (db *pgdb) getCallbackSubject(ctx context.Context, resultID resource.TfeID) (*callbackSubject, error) {
+	var subject callbackSubject
+	err := db.QueryRow(ctx, `
+SELECT tr.task_result_id, r.run_id, r.workspace_id
+FROM task_results tr
+JOIN task_stages ts USING (task_stage_id)
+JOIN runs r USING (run_id)
+WHERE tr.task_result_id = $1
+`, resultID).Scan(&subject.resultID, &subject.runID, &subject.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &subject, nil
+}
@@ -0,0 +1,67 @@
+package runtask
+
+import (
+	"context"
+	"time"
+
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/pubsub"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"golang.org/x/exp/maps"
+	"golang.org/x/sync/errgroup"
+)
+
+// timeoutInterval is the interval between checks for task stages that have
+// run out of time.
+const timeoutInterval = time.Minute
+
+// Dispatcher invokes the tasks of runs that have entered a task stage, and
+// errors tasks that fail to report their outcome in time.
+type Dispatcher struct {
+	logr.Logger
+
+	Runs  dispatcherRunClient
+	Tasks *Service
+}
+
+type dispatcherRunClient interface {
+	ListRuns(ctx context.Context, opts run.ListOptions) (*resource.Page[*run.Run], error)
+	WatchRuns(context.Context) (<-chan pubsub.Event[*run.Event], func(), error)
+}
+
+// Start the dispatcher.
+func (d *Dispatcher) Start(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	// Start the stages of runs awaiting tasks. Stages that have already been
+	// started are skipped.
+	watcher := &run.StatusWatcher{
+		Logger:   d.Logger,
+		Runs:     d.Runs,
+		Statuses: maps.Keys(runningStages),
+		Handler: func(ctx context.Context, runID resource.TfeID) error {
+			_, err := d.Tasks.startStage(ctx, runID)
+			return err
+		},
+	}
+	g.Go(func() error {
+		return watcher.Start(ctx)
+	})
+
+	g.Go(func() error {
+		ticker := time.NewTicker(timeoutInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				// Errors are logged by the service and are not fatal to the
+				// dispatcher.
+				_ = d.Tasks.timeoutStages(ctx)
+			}
+		}
+	})
+	return g.Wait()
+}
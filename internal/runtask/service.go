@@ -0,0 +1,513 @@
+package runtask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/encryption"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/runstatus"
+	"github.com/leg100/otf/internal/sql"
+	"github.com/leg100/otf/internal/tokens"
+	"github.com/leg100/otf/internal/workspace"
+)
+
+const (
+	// defaultTimeout is the time an external service has to report the
+	// outcome of a task before the task is marked as errored.
+	defaultTimeout = 10 * time.Minute
+	// invokeTimeout is the time an external service has to acknowledge the
+	// invocation of a task.
+	invokeTimeout = 10 * time.Second
+)
+
+// runningStages maps the statuses of runs awaiting tasks to the stage of the
+// tasks.
+var runningStages = map[runstatus.Status]run.TaskStage{
+	runstatus.PrePlanRunning:  run.PrePlanStage,
+	runstatus.PostPlanRunning: run.PostPlanStage,
+	runstatus.PreApplyRunning: run.PreApplyStage,
+}
+
+type (
+	// Alias service to permit embedding it with other services in a struct
+	// without a name clash.
+	RunTaskService = Service
+
+	Service struct {
+		logr.Logger
+		*authz.Authorizer
+
+		db         *pgdb
+		runs       runClient
+		workspaces workspaceClient
+		tokens     tokenGenerator
+		hostnames  hostnameClient
+		invoker    *invoker
+		timeout    time.Duration
+	}
+
+	Options struct {
+		DB              *sql.DB
+		Logger          logr.Logger
+		Authorizer      *authz.Authorizer
+		RunClient       runClient
+		WorkspaceClient workspaceClient
+		TokensService   *tokens.Service
+		HostnameService hostnameClient
+		// Cipher encrypts the HMAC keys of tasks.
+		Cipher *encryption.Cipher
+	}
+
+	runClient interface {
+		GetRun(ctx context.Context, id resource.TfeID) (*run.Run, error)
+		FinishTaskStage(ctx context.Context, id resource.TfeID, stage run.TaskStage, opts run.TaskStageFinishOptions) (*run.Run, error)
+	}
+
+	workspaceClient interface {
+		GetWorkspace(ctx context.Context, id resource.TfeID) (*workspace.Workspace, error)
+	}
+
+	tokenGenerator interface {
+		NewToken(subjectID resource.TfeID, expiry *time.Time, opts ...tokens.NewTokenOption) ([]byte, error)
+	}
+)
+
+func NewService(opts Options) *Service {
+	svc := &Service{
+		Logger:     opts.Logger,
+		Authorizer: opts.Authorizer,
+		db:         &pgdb{DB: opts.DB, cipher: opts.Cipher},
+		runs:       opts.RunClient,
+		workspaces: opts.WorkspaceClient,
+		tokens:     opts.TokensService,
+		hostnames:  opts.HostnameService,
+		invoker:    &invoker{client: &http.Client{Timeout: invokeTimeout}},
+		timeout:    defaultTimeout,
+	}
+	// Register parent resolvers so the authorizer can resolve task -> org,
+	// workspace task -> workspace, and task result -> task stage -> run.
+	opts.Authorizer.RegisterParentResolver(resource.RunTaskKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			task, err := svc.db.getTask(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return task.Organization, nil
+		},
+	)
+	opts.Authorizer.RegisterParentResolver(resource.WorkspaceRunTaskKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			wt, err := svc.db.getWorkspaceTask(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return wt.WorkspaceID, nil
+		},
+	)
+	opts.Authorizer.RegisterParentResolver(resource.TaskStageKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			stage, err := svc.db.getStage(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return stage.RunID, nil
+		},
+	)
+	opts.Authorizer.RegisterParentResolver(resource.TaskResultKind,
+		func(ctx context.Context, id resource.ID) (resource.ID, error) {
+			result, err := svc.db.getResult(ctx, id.(resource.TfeID))
+			if err != nil {
+				return nil, err
+			}
+			return result.StageID, nil
+		},
+	)
+	// Register with auth middleware the access token sent to external
+	// services, the subject of which is the task result.
+	opts.TokensService.RegisterKind(resource.TaskResultKind, func(ctx context.Context, resultID resource.TfeID) (authz.Subject, error) {
+		subject, err := svc.db.getCallbackSubject(ctx, resultID)
+		if err != nil {
+			return nil, err
+		}
+		return subject, nil
+	})
+	return svc
+}
+
+func (s *Service) CreateTask(ctx context.Context, org organization.Name, opts CreateTaskOptions) (*Task, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.RunTaskKind, org)
+	if err != nil {
+		return nil, err
+	}
+	task, err := func() (*Task, error) {
+		task, err := newTask(org, opts)
+		if err != nil {
+			return nil, err
+		}
+		return task, s.db.createTask(ctx, task)
+	}()
+	if err != nil {
+		s.Error(err, "creating run task", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("created run task", "task", task, "subject", subject)
+	return task, nil
+}
+
+func (s *Service) GetTask(ctx context.Context, id resource.TfeID) (*Task, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.RunTaskKind, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.db.getTask(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving run task", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved run task", "task", task, "subject", subject)
+	return task, nil
+}
+
+func (s *Service) ListTasks(ctx context.Context, org organization.Name) ([]*Task, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.RunTaskKind, org)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.db.listTasks(ctx, org)
+	if err != nil {
+		s.Error(err, "listing run tasks", "organization", org, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed run tasks", "organization", org, "total", len(tasks), "subject", subject)
+	return tasks, nil
+}
+
+func (s *Service) UpdateTask(ctx context.Context, id resource.TfeID, opts UpdateTaskOptions) (*Task, error) {
+	var subject authz.Subject
+	updated, err := s.db.updateTask(ctx, id, func(ctx context.Context, task *Task) (err error) {
+		subject, err = s.Authorize(ctx, resource.Update, resource.RunTaskKind, id)
+		if err != nil {
+			return err
+		}
+		return task.update(opts)
+	})
+	if err != nil {
+		s.Error(err, "updating run task", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("updated run task", "task", updated, "subject", subject)
+	return updated, nil
+}
+
+// DeleteTask deletes a task, detaching it from any workspaces. The results of
+// previous invocations of the task are retained.
+func (s *Service) DeleteTask(ctx context.Context, id resource.TfeID) (*Task, error) {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.RunTaskKind, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.db.deleteTask(ctx, id)
+	if err != nil {
+		s.Error(err, "deleting run task", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("deleted run task", "task", task, "subject", subject)
+	return task, nil
+}
+
+// CreateWorkspaceTask attaches a task to a workspace. The task must belong to
+// the workspace's organization.
+func (s *Service) CreateWorkspaceTask(ctx context.Context, workspaceID resource.TfeID, opts CreateWorkspaceTaskOptions) (*WorkspaceTask, error) {
+	subject, err := s.Authorize(ctx, resource.Create, resource.WorkspaceRunTaskKind, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := func() (*WorkspaceTask, error) {
+		ws, err := s.workspaces.GetWorkspace(ctx, workspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving workspace: %w", err)
+		}
+		task, err := s.db.getTask(ctx, opts.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving run task: %w", err)
+		}
+		if task.Organization != ws.Organization {
+			return nil, ErrTaskOrganizationMismatch
+		}
+		wt, err := newWorkspaceTask(workspaceID, opts)
+		if err != nil {
+			return nil, err
+		}
+		return wt, s.db.createWorkspaceTask(ctx, wt)
+	}()
+	if err != nil {
+		s.Error(err, "attaching run task to workspace", "workspace_id", workspaceID, "task_id", opts.TaskID, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("attached run task to workspace", "workspace_task", wt, "subject", subject)
+	return wt, nil
+}
+
+func (s *Service) GetWorkspaceTask(ctx context.Context, id resource.TfeID) (*WorkspaceTask, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.WorkspaceRunTaskKind, id)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := s.db.getWorkspaceTask(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving workspace run task", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved workspace run task", "workspace_task", wt, "subject", subject)
+	return wt, nil
+}
+
+func (s *Service) ListWorkspaceTasks(ctx context.Context, workspaceID resource.TfeID) ([]*WorkspaceTask, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.WorkspaceRunTaskKind, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	wts, err := s.db.listWorkspaceTasks(ctx, workspaceID)
+	if err != nil {
+		s.Error(err, "listing workspace run tasks", "workspace_id", workspaceID, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed workspace run tasks", "workspace_id", workspaceID, "total", len(wts), "subject", subject)
+	return wts, nil
+}
+
+func (s *Service) UpdateWorkspaceTask(ctx context.Context, id resource.TfeID, opts UpdateWorkspaceTaskOptions) (*WorkspaceTask, error) {
+	var subject authz.Subject
+	updated, err := s.db.updateWorkspaceTask(ctx, id, func(ctx context.Context, wt *WorkspaceTask) (err error) {
+		subject, err = s.Authorize(ctx, resource.Update, resource.WorkspaceRunTaskKind, id)
+		if err != nil {
+			return err
+		}
+		return wt.update(opts)
+	})
+	if err != nil {
+		s.Error(err, "updating workspace run task", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("updated workspace run task", "workspace_task", updated, "subject", subject)
+	return updated, nil
+}
+
+// DeleteWorkspaceTask detaches a task from a workspace.
+func (s *Service) DeleteWorkspaceTask(ctx context.Context, id resource.TfeID) (*WorkspaceTask, error) {
+	subject, err := s.Authorize(ctx, resource.Delete, resource.WorkspaceRunTaskKind, id)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := s.db.deleteWorkspaceTask(ctx, id)
+	if err != nil {
+		s.Error(err, "detaching run task from workspace", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("detached run task from workspace", "workspace_task", wt, "subject", subject)
+	return wt, nil
+}
+
+// ListRunStages lists the task stages of a run, along with their results.
+func (s *Service) ListRunStages(ctx context.Context, runID resource.TfeID) ([]*Stage, error) {
+	subject, err := s.Authorize(ctx, resource.List, resource.TaskStageKind, runID)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := s.db.listStages(ctx, runID)
+	if err != nil {
+		s.Error(err, "listing task stages", "run_id", runID, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("listed task stages", "run_id", runID, "total", len(stages), "subject", subject)
+	return stages, nil
+}
+
+func (s *Service) GetStage(ctx context.Context, id resource.TfeID) (*Stage, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.TaskStageKind, id)
+	if err != nil {
+		return nil, err
+	}
+	stage, err := s.db.getStage(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving task stage", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved task stage", "stage", stage, "subject", subject)
+	return stage, nil
+}
+
+func (s *Service) GetResult(ctx context.Context, id resource.TfeID) (*Result, error) {
+	subject, err := s.Authorize(ctx, resource.Get, resource.TaskResultKind, id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.getResult(ctx, id)
+	if err != nil {
+		s.Error(err, "retrieving task result", "id", id, "subject", subject)
+		return nil, err
+	}
+	s.V(9).Info("retrieved task result", "result", result, "subject", subject)
+	return result, nil
+}
+
+// Callback is called by an external service to report the outcome of a task.
+// Once every task of the stage has reported its outcome the run proceeds, or
+// errors if a mandatory task failed.
+func (s *Service) Callback(ctx context.Context, resultID resource.TfeID, opts CallbackOptions) (*Result, error) {
+	subject, err := s.Authorize(ctx, resource.Update, resource.TaskResultKind, resultID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := func() (*Result, error) {
+		result, err := s.db.getResult(ctx, resultID)
+		if err != nil {
+			return nil, err
+		}
+		stage, err := s.updateStage(ctx, result.StageID, func(stage *Stage) error {
+			return stage.callback(resultID, opts)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return stage.result(resultID)
+	}()
+	if err != nil {
+		s.Error(err, "reporting task result", "id", resultID, "subject", subject)
+		return nil, err
+	}
+	s.V(0).Info("reported task result", "result", result, "subject", subject)
+	return result, nil
+}
+
+// startStage invokes the tasks attached to the workspace of a run at the
+// stage the run has entered. If the stage has already been started then this
+// is a no-op.
+func (s *Service) startStage(ctx context.Context, runID resource.TfeID) (*Stage, error) {
+	stage, err := func() (*Stage, error) {
+		r, err := s.runs.GetRun(ctx, runID)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving run: %w", err)
+		}
+		taskStage, ok := runningStages[r.Status]
+		if !ok {
+			return nil, fmt.Errorf("run is not awaiting tasks: %s", r.Status)
+		}
+		ws, err := s.workspaces.GetWorkspace(ctx, r.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving workspace: %w", err)
+		}
+		tasks, err := s.db.listStageTasks(ctx, r.WorkspaceID, taskStage)
+		if err != nil {
+			return nil, fmt.Errorf("listing tasks: %w", err)
+		}
+		stage := newStage(runID, taskStage, tasks)
+		if err := s.db.createStage(ctx, stage); err != nil {
+			if errors.Is(err, internal.ErrResourceAlreadyExists) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("saving task stage: %w", err)
+		}
+		// Invoke each task, erroring the results of those that could not be
+		// invoked. If there are no tasks, e.g. because they have since been
+		// disabled, then the stage passes immediately.
+		invokeErrors := make(map[resource.TfeID]error)
+		for i, result := range stage.Results {
+			if err := s.invokeTask(ctx, r, ws, stage, result, tasks[i].Task); err != nil {
+				invokeErrors[result.ID] = err
+			}
+		}
+		return s.updateStage(ctx, stage.ID, func(stage *Stage) error {
+			for id, err := range invokeErrors {
+				if err := stage.errorResult(id, err.Error()); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}()
+	if err != nil {
+		s.Error(err, "starting task stage", "run_id", runID)
+		return nil, err
+	}
+	if stage != nil {
+		s.V(0).Info("started task stage", "stage", stage)
+	}
+	return stage, nil
+}
+
+func (s *Service) invokeTask(ctx context.Context, r *run.Run, ws *workspace.Workspace, stage *Stage, result *Result, task *Task) error {
+	// The token expires once the external service has run out of time to
+	// report the outcome.
+	expiry := internal.CurrentTimestamp(nil).Add(s.timeout)
+	token, err := s.tokens.NewToken(result.ID, &expiry)
+	if err != nil {
+		return fmt.Errorf("generating access token: %w", err)
+	}
+	payload := newPayload(s.hostnames, r, ws, stage, result, token)
+	if err := s.invoker.invoke(ctx, task, payload); err != nil {
+		s.Error(err, "invoking run task", "result", result)
+		return err
+	}
+	s.V(1).Info("invoked run task", "result", result)
+	return nil
+}
+
+// timeoutStages errors the unfinished results of stages that have run out of
+// time.
+func (s *Service) timeoutStages(ctx context.Context) error {
+	before := internal.CurrentTimestamp(nil).Add(-s.timeout)
+	ids, err := s.db.listTimedOutStages(ctx, before)
+	if err != nil {
+		s.Error(err, "listing timed out task stages")
+		return err
+	}
+	for _, id := range ids {
+		stage, err := s.updateStage(ctx, id, func(stage *Stage) error {
+			stage.timeout()
+			return nil
+		})
+		if err != nil {
+			s.Error(err, "timing out task stage", "id", id)
+			continue
+		}
+		s.V(0).Info("timed out task stage", "stage", stage)
+	}
+	return nil
+}
+
+// updateStage updates a stage, and if the update finishes the stage then the
+// stage's run is updated accordingly.
+func (s *Service) updateStage(ctx context.Context, id resource.TfeID, fn func(*Stage) error) (*Stage, error) {
+	var stage *Stage
+	err := s.db.Tx(ctx, func(ctx context.Context) (err error) {
+		var finished bool
+		stage, err = s.db.updateStage(ctx, id, func(ctx context.Context, stage *Stage) error {
+			if err := fn(stage); err != nil {
+				return err
+			}
+			finished = stage.finish()
+			return nil
+		})
+		if err != nil || !finished {
+			return err
+		}
+		// The subject reporting the outcome of a task is not permitted to
+		// update the run, so elevate privileges.
+		ctx = authz.AddSubjectToContext(ctx, &authz.Superuser{Username: "run-tasks"})
+		_, err = s.runs.FinishTaskStage(ctx, stage.RunID, stage.Stage, run.TaskStageFinishOptions{
+			Passed: stage.Status == StagePassed,
+		})
+		return err
+	})
+	return stage, err
+}
@@ -0,0 +1,263 @@
+package runtask
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+)
+
+const (
+	// StageRunning indicates the stage's tasks have been invoked and the stage
+	// is awaiting their results.
+	StageRunning StageStatus = "running"
+	// StagePassed indicates every mandatory task passed.
+	StagePassed StageStatus = "passed"
+	// StageFailed indicates at least one mandatory task failed or errored.
+	StageFailed StageStatus = "failed"
+
+	// ResultPending indicates the task is yet to be invoked or yet to respond.
+	ResultPending ResultStatus = "pending"
+	// ResultRunning indicates the external service has acknowledged the task
+	// but is yet to report its outcome.
+	ResultRunning ResultStatus = "running"
+	// ResultPassed indicates the external service reported the task passed.
+	ResultPassed ResultStatus = "passed"
+	// ResultFailed indicates the external service reported the task failed.
+	ResultFailed ResultStatus = "failed"
+	// ResultErrored indicates the task could not be invoked, or the external
+	// service did not report its outcome in time.
+	ResultErrored ResultStatus = "errored"
+)
+
+var (
+	ErrInvalidCallbackStatus = errors.New("status must be one of running, passed or failed")
+	ErrResultFinished        = errors.New("task result has already finished")
+)
+
+type (
+	// StageStatus is the status of a task stage.
+	StageStatus string
+
+	// ResultStatus is the status of a task result.
+	ResultStatus string
+
+	// Stage is the invocation of the tasks attached to a workspace at a stage
+	// of one of its runs. There is at most one stage of each kind per run.
+	Stage struct {
+		ID        resource.TfeID `db:"task_stage_id"`
+		CreatedAt time.Time      `db:"created_at"`
+		UpdatedAt time.Time      `db:"updated_at"`
+		RunID     resource.TfeID `db:"run_id"`
+		Stage     run.TaskStage  `db:"stage"`
+		Status    StageStatus    `db:"status"`
+		Results   []*Result      `db:"-"`
+	}
+
+	// Result is the outcome of a task invoked at a stage of a run. The name
+	// and URL of the task are recorded at the time of invocation.
+	Result struct {
+		ID               resource.TfeID   `db:"task_result_id"`
+		CreatedAt        time.Time        `db:"created_at"`
+		UpdatedAt        time.Time        `db:"updated_at"`
+		StageID          resource.TfeID   `db:"task_stage_id"`
+		TaskID           resource.TfeID   `db:"run_task_id"`
+		TaskName         string           `db:"task_name"`
+		TaskURL          string           `db:"task_url"`
+		EnforcementLevel EnforcementLevel `db:"enforcement_level"`
+		Status           ResultStatus     `db:"status"`
+		Message          string           `db:"message"`
+		// URL is an optional link to further details of the outcome, provided
+		// by the external service.
+		URL *string `db:"url"`
+	}
+
+	// CallbackOptions are the options with which an external service reports
+	// the outcome of a task.
+	CallbackOptions struct {
+		Status  ResultStatus
+		Message string
+		URL     *string
+	}
+
+	// stageTask is a task attached to a workspace at a stage.
+	stageTask struct {
+		*Task
+		EnforcementLevel EnforcementLevel
+	}
+)
+
+func newStage(runID resource.TfeID, stage run.TaskStage, tasks []stageTask) *Stage {
+	now := internal.CurrentTimestamp(nil)
+	s := &Stage{
+		ID:        resource.NewTfeID(resource.TaskStageKind),
+		CreatedAt: now,
+		UpdatedAt: now,
+		RunID:     runID,
+		Stage:     stage,
+		Status:    StageRunning,
+		Results:   make([]*Result, len(tasks)),
+	}
+	for i, task := range tasks {
+		s.Results[i] = &Result{
+			ID:               resource.NewTfeID(resource.TaskResultKind),
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			StageID:          s.ID,
+			TaskID:           task.ID,
+			TaskName:         task.Name,
+			TaskURL:          task.URL,
+			EnforcementLevel: task.EnforcementLevel,
+			Status:           ResultPending,
+		}
+	}
+	return s
+}
+
+// callback updates a result with the outcome reported by an external service.
+func (s *Stage) callback(resultID resource.TfeID, opts CallbackOptions) error {
+	switch opts.Status {
+	case ResultRunning, ResultPassed, ResultFailed:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidCallbackStatus, opts.Status)
+	}
+	result, err := s.result(resultID)
+	if err != nil {
+		return err
+	}
+	if result.Finished() {
+		return ErrResultFinished
+	}
+	result.Status = opts.Status
+	result.Message = opts.Message
+	if opts.URL != nil {
+		result.URL = opts.URL
+	}
+	result.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// errorResult marks a result as errored, e.g. because its task could not be
+// invoked.
+func (s *Stage) errorResult(resultID resource.TfeID, msg string) error {
+	result, err := s.result(resultID)
+	if err != nil {
+		return err
+	}
+	if result.Finished() {
+		return ErrResultFinished
+	}
+	result.Status = ResultErrored
+	result.Message = msg
+	result.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// timeout marks every unfinished result as errored.
+func (s *Stage) timeout() {
+	for _, result := range s.Results {
+		if !result.Finished() {
+			_ = s.errorResult(result.ID, "timed out waiting for result")
+		}
+	}
+}
+
+// finish sets the status of the stage once every result has finished,
+// returning true if the stage has finished.
+func (s *Stage) finish() bool {
+	if s.Status != StageRunning {
+		return false
+	}
+	for _, result := range s.Results {
+		if !result.Finished() {
+			return false
+		}
+	}
+	s.Status = StagePassed
+	if s.MandatoryFailed() > 0 {
+		s.Status = StageFailed
+	}
+	s.UpdatedAt = internal.CurrentTimestamp(nil)
+	return true
+}
+
+func (s *Stage) result(id resource.TfeID) (*Result, error) {
+	for _, result := range s.Results {
+		if result.ID == id {
+			return result, nil
+		}
+	}
+	return nil, internal.ErrResourceNotFound
+}
+
+// Passed returns the number of tasks that passed.
+func (s *Stage) Passed() (n int) {
+	for _, r := range s.Results {
+		if r.Status == ResultPassed {
+			n++
+		}
+	}
+	return
+}
+
+// AdvisoryFailed returns the number of advisory tasks that failed or errored.
+func (s *Stage) AdvisoryFailed() int {
+	return s.failed(Advisory)
+}
+
+// MandatoryFailed returns the number of mandatory tasks that failed or
+// errored.
+func (s *Stage) MandatoryFailed() int {
+	return s.failed(Mandatory)
+}
+
+func (s *Stage) failed(level EnforcementLevel) (n int) {
+	for _, r := range s.Results {
+		if r.Failed() && r.EnforcementLevel == level {
+			n++
+		}
+	}
+	return
+}
+
+// LogValue implements slog.LogValuer.
+func (s *Stage) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", s.ID.String()),
+		slog.String("run_id", s.RunID.String()),
+		slog.String("stage", string(s.Stage)),
+		slog.String("status", string(s.Status)),
+		slog.Int("passed", s.Passed()),
+		slog.Int("advisory_failed", s.AdvisoryFailed()),
+		slog.Int("mandatory_failed", s.MandatoryFailed()),
+	)
+}
+
+// Finished determines whether the result is in a final state.
+func (r *Result) Finished() bool {
+	switch r.Status {
+	case ResultPassed, ResultFailed, ResultErrored:
+		return true
+	default:
+		return false
+	}
+}
+
+// Failed determines whether the task failed or errored.
+func (r *Result) Failed() bool {
+	return r.Status == ResultFailed || r.Status == ResultErrored
+}
+
+// LogValue implements slog.LogValuer.
+func (r *Result) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", r.ID.String()),
+		slog.String("stage_id", r.StageID.String()),
+		slog.String("task", r.TaskName),
+		slog.String("status", string(r.Status)),
+	)
+}
@@ -0,0 +1,135 @@
+package runtask
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStage(t *testing.T) {
+	newTestStage := func() *Stage {
+		return newStage(resource.NewTfeID(resource.RunKind), run.PostPlanStage, []stageTask{
+			{Task: &Task{ID: resource.NewTfeID(resource.RunTaskKind), Name: "advisory"}, EnforcementLevel: Advisory},
+			{Task: &Task{ID: resource.NewTfeID(resource.RunTaskKind), Name: "mandatory"}, EnforcementLevel: Mandatory},
+		})
+	}
+
+	t.Run("new stage", func(t *testing.T) {
+		stage := newTestStage()
+
+		assert.Equal(t, StageRunning, stage.Status)
+		require.Len(t, stage.Results, 2)
+		for _, result := range stage.Results {
+			assert.Equal(t, ResultPending, result.Status)
+			assert.Equal(t, stage.ID, result.StageID)
+		}
+	})
+
+	t.Run("passed", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		assert.False(t, stage.finish())
+
+		err = stage.callback(stage.Results[1].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		assert.True(t, stage.finish())
+
+		assert.Equal(t, StagePassed, stage.Status)
+		assert.Equal(t, 2, stage.Passed())
+	})
+
+	t.Run("advisory failure passes stage", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultFailed, Message: "vulnerable"})
+		require.NoError(t, err)
+		err = stage.callback(stage.Results[1].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		assert.True(t, stage.finish())
+
+		assert.Equal(t, StagePassed, stage.Status)
+		assert.Equal(t, 1, stage.AdvisoryFailed())
+		assert.Equal(t, "vulnerable", stage.Results[0].Message)
+	})
+
+	t.Run("mandatory failure fails stage", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		err = stage.callback(stage.Results[1].ID, CallbackOptions{Status: ResultFailed})
+		require.NoError(t, err)
+		assert.True(t, stage.finish())
+
+		assert.Equal(t, StageFailed, stage.Status)
+		assert.Equal(t, 1, stage.MandatoryFailed())
+	})
+
+	t.Run("running result does not finish stage", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		err = stage.callback(stage.Results[1].ID, CallbackOptions{Status: ResultRunning})
+		require.NoError(t, err)
+
+		assert.False(t, stage.finish())
+	})
+
+	t.Run("timeout errors unfinished results", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		stage.timeout()
+		assert.True(t, stage.finish())
+
+		assert.Equal(t, ResultPassed, stage.Results[0].Status)
+		assert.Equal(t, ResultErrored, stage.Results[1].Status)
+		assert.Equal(t, StageFailed, stage.Status)
+	})
+
+	t.Run("finished stage cannot finish again", func(t *testing.T) {
+		stage := newTestStage()
+		stage.timeout()
+
+		assert.True(t, stage.finish())
+		assert.False(t, stage.finish())
+	})
+
+	t.Run("no tasks", func(t *testing.T) {
+		stage := newStage(resource.NewTfeID(resource.RunKind), run.PrePlanStage, nil)
+
+		assert.True(t, stage.finish())
+		assert.Equal(t, StagePassed, stage.Status)
+	})
+
+	t.Run("invalid callback status", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultErrored})
+		assert.ErrorIs(t, err, ErrInvalidCallbackStatus)
+	})
+
+	t.Run("callback after result finished", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultPassed})
+		require.NoError(t, err)
+		err = stage.callback(stage.Results[0].ID, CallbackOptions{Status: ResultFailed})
+		assert.ErrorIs(t, err, ErrResultFinished)
+	})
+
+	t.Run("unknown result", func(t *testing.T) {
+		stage := newTestStage()
+
+		err := stage.callback(resource.NewTfeID(resource.TaskResultKind), CallbackOptions{Status: ResultPassed})
+		assert.ErrorIs(t, err, internal.ErrResourceNotFound)
+	})
+}
@@ -0,0 +1,29 @@
+package runtask
+
+import (
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/resource"
+)
+
+// callbackSubject is the subject of the access token sent to an external
+// service when a task is invoked. It permits the service to report the outcome
+// of the task, and to retrieve the configuration and plan of the run.
+type callbackSubject struct {
+	resultID    resource.TfeID
+	runID       resource.TfeID
+	workspaceID resource.TfeID
+}
+
+func (s *callbackSubject) String() string { return s.resultID.String() }
+
+func (s *callbackSubject) CanAccess(action resource.Action, kind resource.Kind, req authz.Request) bool {
+	switch kind {
+	case resource.TaskResultKind:
+		return action == resource.Update && req.ID == s.resultID
+	case resource.ConfigVersionKind:
+		return action == resource.Download && req.Workspace() == s.workspaceID
+	case resource.PlanFileKind:
+		return action == resource.Get && req.ID == s.runID
+	}
+	return false
+}
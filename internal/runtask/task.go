@@ -0,0 +1,252 @@
+// Package runtask provides run tasks, which invoke external services at
+// stages of a run, pausing the run until the services report their results.
+package runtask
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+)
+
+const (
+	// Advisory tasks report failures but do not prevent a run from
+	// proceeding.
+	Advisory EnforcementLevel = "advisory"
+	// Mandatory tasks prevent a run from proceeding if they fail.
+	Mandatory EnforcementLevel = "mandatory"
+)
+
+var (
+	ErrInvalidEnforcementLevel = errors.New("enforcement level must be either advisory or mandatory")
+	ErrInvalidStage            = errors.New("stage must be one of pre_plan, post_plan or pre_apply")
+	ErrInvalidURL              = errors.New("URL must be a http(s) URL")
+	// ErrTaskOrganizationMismatch is returned when attaching a task to a
+	// workspace in a different organization.
+	ErrTaskOrganizationMismatch = errors.New("task belongs to a different organization to the workspace")
+
+	// nameRegex validates a task name.
+	nameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+type (
+	// EnforcementLevel determines the consequence of a task failing.
+	EnforcementLevel string
+
+	// Task is an organization's definition of an external service that is
+	// invoked at a stage of a run. The task only takes effect once it is
+	// attached to a workspace.
+	Task struct {
+		ID           resource.TfeID    `db:"run_task_id"`
+		CreatedAt    time.Time         `db:"created_at"`
+		UpdatedAt    time.Time         `db:"updated_at"`
+		Organization organization.Name `db:"organization_name"`
+		Name         string            `db:"name"`
+		Description  string            `db:"description"`
+		// URL to which requests are sent to invoke the task.
+		URL string `db:"url"`
+		// HMACKey is the optional key with which requests sent to the URL are
+		// signed.
+		HMACKey *string `db:"hmac_key"`
+		// Enabled determines whether the task is invoked. A disabled task
+		// remains attached to workspaces but runs do not wait for it.
+		Enabled bool `db:"enabled"`
+	}
+
+	// WorkspaceTask is the attachment of a task to a workspace at a stage of
+	// the workspace's runs.
+	WorkspaceTask struct {
+		ID               resource.TfeID   `db:"workspace_run_task_id"`
+		CreatedAt        time.Time        `db:"created_at"`
+		UpdatedAt        time.Time        `db:"updated_at"`
+		WorkspaceID      resource.TfeID   `db:"workspace_id"`
+		TaskID           resource.TfeID   `db:"run_task_id"`
+		Stage            run.TaskStage    `db:"stage"`
+		EnforcementLevel EnforcementLevel `db:"enforcement_level"`
+	}
+
+	// CreateTaskOptions are the options for creating a new task.
+	CreateTaskOptions struct {
+		Name        string
+		Description string
+		URL         string
+		HMACKey     *string
+		// Enabled defaults to true.
+		Enabled *bool
+	}
+
+	// UpdateTaskOptions are the options for updating a task.
+	UpdateTaskOptions struct {
+		Name        *string
+		Description *string
+		URL         *string
+		// HMACKey set to an empty string removes the key.
+		HMACKey *string
+		Enabled *bool
+	}
+
+	// CreateWorkspaceTaskOptions are the options for attaching a task to a
+	// workspace.
+	CreateWorkspaceTaskOptions struct {
+		TaskID resource.TfeID
+		// Stage defaults to post_plan.
+		Stage run.TaskStage
+		// EnforcementLevel defaults to advisory.
+		EnforcementLevel EnforcementLevel
+	}
+
+	// UpdateWorkspaceTaskOptions are the options for updating the attachment
+	// of a task to a workspace.
+	UpdateWorkspaceTaskOptions struct {
+		Stage            *run.TaskStage
+		EnforcementLevel *EnforcementLevel
+	}
+)
+
+func newTask(org organization.Name, opts CreateTaskOptions) (*Task, error) {
+	now := internal.CurrentTimestamp(nil)
+	task := &Task{
+		ID:           resource.NewTfeID(resource.RunTaskKind),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Organization: org,
+		Enabled:      true,
+	}
+	if opts.Name == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "name"}
+	}
+	if opts.URL == "" {
+		return nil, &internal.ErrMissingParameter{Parameter: "url"}
+	}
+	err := task.update(UpdateTaskOptions{
+		Name:        &opts.Name,
+		Description: &opts.Description,
+		URL:         &opts.URL,
+		HMACKey:     opts.HMACKey,
+		Enabled:     opts.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (t *Task) update(opts UpdateTaskOptions) error {
+	if opts.Name != nil {
+		if !nameRegex.MatchString(*opts.Name) {
+			return fmt.Errorf("%w: name may only contain letters, numbers, dashes and underscores", internal.ErrInvalidName)
+		}
+		t.Name = *opts.Name
+	}
+	if opts.Description != nil {
+		t.Description = *opts.Description
+	}
+	if opts.URL != nil {
+		u, err := url.Parse(*opts.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidURL
+		}
+		t.URL = *opts.URL
+	}
+	if opts.HMACKey != nil {
+		if *opts.HMACKey == "" {
+			t.HMACKey = nil
+		} else {
+			t.HMACKey = opts.HMACKey
+		}
+	}
+	if opts.Enabled != nil {
+		t.Enabled = *opts.Enabled
+	}
+	t.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// LogValue implements slog.LogValuer.
+func (t *Task) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", t.ID.String()),
+		slog.Any("organization", t.Organization),
+		slog.String("name", t.Name),
+		slog.String("url", t.URL),
+		slog.Bool("enabled", t.Enabled),
+	)
+}
+
+func newWorkspaceTask(workspaceID resource.TfeID, opts CreateWorkspaceTaskOptions) (*WorkspaceTask, error) {
+	now := internal.CurrentTimestamp(nil)
+	wt := &WorkspaceTask{
+		ID:          resource.NewTfeID(resource.WorkspaceRunTaskKind),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		WorkspaceID: workspaceID,
+		TaskID:      opts.TaskID,
+	}
+	if opts.Stage == "" {
+		opts.Stage = run.PostPlanStage
+	}
+	if opts.EnforcementLevel == "" {
+		opts.EnforcementLevel = Advisory
+	}
+	err := wt.update(UpdateWorkspaceTaskOptions{
+		Stage:            &opts.Stage,
+		EnforcementLevel: &opts.EnforcementLevel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wt, nil
+}
+
+func (wt *WorkspaceTask) update(opts UpdateWorkspaceTaskOptions) error {
+	if opts.Stage != nil {
+		if err := validateStage(*opts.Stage); err != nil {
+			return err
+		}
+		wt.Stage = *opts.Stage
+	}
+	if opts.EnforcementLevel != nil {
+		if err := opts.EnforcementLevel.Validate(); err != nil {
+			return err
+		}
+		wt.EnforcementLevel = *opts.EnforcementLevel
+	}
+	wt.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+// LogValue implements slog.LogValuer.
+func (wt *WorkspaceTask) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", wt.ID.String()),
+		slog.String("workspace_id", wt.WorkspaceID.String()),
+		slog.String("task_id", wt.TaskID.String()),
+		slog.String("stage", string(wt.Stage)),
+		slog.String("enforcement_level", string(wt.EnforcementLevel)),
+	)
+}
+
+func (l EnforcementLevel) Validate() error {
+	switch l {
+	case Advisory, Mandatory:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidEnforcementLevel, l)
+	}
+}
+
+func validateStage(stage run.TaskStage) error {
+	switch stage {
+	case run.PrePlanStage, run.PostPlanStage, run.PreApplyStage:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidStage, stage)
+	}
+}
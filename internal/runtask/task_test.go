@@ -0,0 +1,102 @@
+package runtask
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTask(t *testing.T) {
+	org := organization.NewTestName(t)
+
+	t.Run("defaults", func(t *testing.T) {
+		task, err := newTask(org, CreateTaskOptions{
+			Name: "scanner",
+			URL:  "https://scanner.example.com",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "scanner", task.Name)
+		assert.True(t, task.Enabled)
+		assert.Nil(t, task.HMACKey)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := newTask(org, CreateTaskOptions{URL: "https://scanner.example.com"})
+		assert.Equal(t, &internal.ErrMissingParameter{Parameter: "name"}, err)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := newTask(org, CreateTaskOptions{
+			Name: "my scanner",
+			URL:  "https://scanner.example.com",
+		})
+		assert.ErrorIs(t, err, internal.ErrInvalidName)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := newTask(org, CreateTaskOptions{
+			Name: "scanner",
+			URL:  "ftp://scanner.example.com",
+		})
+		assert.ErrorIs(t, err, ErrInvalidURL)
+	})
+}
+
+func TestTask_Update(t *testing.T) {
+	task, err := newTask(organization.NewTestName(t), CreateTaskOptions{
+		Name:    "scanner",
+		URL:     "https://scanner.example.com",
+		HMACKey: new("secret"),
+	})
+	require.NoError(t, err)
+
+	t.Run("keep hmac key", func(t *testing.T) {
+		err := task.update(UpdateTaskOptions{Name: new("scanner-v2")})
+		require.NoError(t, err)
+
+		assert.Equal(t, "scanner-v2", task.Name)
+		assert.Equal(t, new("secret"), task.HMACKey)
+	})
+
+	t.Run("remove hmac key", func(t *testing.T) {
+		err := task.update(UpdateTaskOptions{HMACKey: new("")})
+		require.NoError(t, err)
+
+		assert.Nil(t, task.HMACKey)
+	})
+}
+
+func TestNewWorkspaceTask(t *testing.T) {
+	wsID := resource.NewTfeID(resource.WorkspaceKind)
+	taskID := resource.NewTfeID(resource.RunTaskKind)
+
+	t.Run("defaults", func(t *testing.T) {
+		wt, err := newWorkspaceTask(wsID, CreateWorkspaceTaskOptions{TaskID: taskID})
+		require.NoError(t, err)
+
+		assert.Equal(t, run.PostPlanStage, wt.Stage)
+		assert.Equal(t, Advisory, wt.EnforcementLevel)
+	})
+
+	t.Run("invalid stage", func(t *testing.T) {
+		_, err := newWorkspaceTask(wsID, CreateWorkspaceTaskOptions{
+			TaskID: taskID,
+			Stage:  "post_apply",
+		})
+		assert.ErrorIs(t, err, ErrInvalidStage)
+	})
+
+	t.Run("invalid enforcement level", func(t *testing.T) {
+		_, err := newWorkspaceTask(wsID, CreateWorkspaceTaskOptions{
+			TaskID:           taskID,
+			EnforcementLevel: "soft-mandatory",
+		})
+		assert.ErrorIs(t, err, ErrInvalidEnforcementLevel)
+	})
+}
@@ -0,0 +1,75 @@
+package runtask
+
+import (
+	"time"
+
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/workspace"
+)
+
+// TFETask represents a run task in the TFE API.
+type TFETask struct {
+	ID          resource.TfeID `jsonapi:"primary,tasks"`
+	Name        string         `jsonapi:"attribute" json:"name"`
+	URL         string         `jsonapi:"attribute" json:"url"`
+	Description string         `jsonapi:"attribute" json:"description"`
+	Category    string         `jsonapi:"attribute" json:"category"`
+	HMACKey     *string        `jsonapi:"attribute" json:"hmac-key,omitempty"`
+	Enabled     bool           `jsonapi:"attribute" json:"enabled"`
+
+	// Relations
+	Organization *organization.TFEOrganization `jsonapi:"relationship" json:"organization"`
+}
+
+// TFEWorkspaceTask represents the attachment of a run task to a workspace in
+// the TFE API.
+type TFEWorkspaceTask struct {
+	ID               resource.TfeID   `jsonapi:"primary,workspace-tasks"`
+	EnforcementLevel EnforcementLevel `jsonapi:"attribute" json:"enforcement-level"`
+	Stage            run.TaskStage    `jsonapi:"attribute" json:"stage"`
+
+	// Relations
+	Task      *TFETask                `jsonapi:"relationship" json:"task"`
+	Workspace *workspace.TFEWorkspace `jsonapi:"relationship" json:"workspace"`
+}
+
+// TFETaskStage represents a task stage in the TFE API.
+type TFETaskStage struct {
+	ID               resource.TfeID                `jsonapi:"primary,task-stages"`
+	Stage            run.TaskStage                 `jsonapi:"attribute" json:"stage"`
+	Status           StageStatus                   `jsonapi:"attribute" json:"status"`
+	StatusTimestamps *TFETaskStageStatusTimestamps `jsonapi:"attribute" json:"status-timestamps"`
+	CreatedAt        time.Time                     `jsonapi:"attribute" json:"created-at"`
+	UpdatedAt        time.Time                     `jsonapi:"attribute" json:"updated-at"`
+
+	// Relations
+	Run         *run.TFERun      `jsonapi:"relationship" json:"run"`
+	TaskResults []*TFETaskResult `jsonapi:"relationship" json:"task-results"`
+}
+
+// TFETaskStageStatusTimestamps are the timestamps of a task stage's status
+// transitions.
+type TFETaskStageStatusTimestamps struct {
+	RunningAt *time.Time `json:"running-at,omitempty"`
+	PassedAt  *time.Time `json:"passed-at,omitempty"`
+	FailedAt  *time.Time `json:"failed-at,omitempty"`
+}
+
+// TFETaskResult represents a task result in the TFE API.
+type TFETaskResult struct {
+	ID                            resource.TfeID   `jsonapi:"primary,task-results"`
+	Status                        ResultStatus     `jsonapi:"attribute" json:"status"`
+	Message                       string           `jsonapi:"attribute" json:"message"`
+	URL                           *string          `jsonapi:"attribute" json:"url"`
+	CreatedAt                     time.Time        `jsonapi:"attribute" json:"created-at"`
+	UpdatedAt                     time.Time        `jsonapi:"attribute" json:"updated-at"`
+	TaskID                        resource.TfeID   `jsonapi:"attribute" json:"task-id"`
+	TaskName                      string           `jsonapi:"attribute" json:"task-name"`
+	TaskURL                       string           `jsonapi:"attribute" json:"task-url"`
+	WorkspaceTaskEnforcementLevel EnforcementLevel `jsonapi:"attribute" json:"workspace-task-enforcement-level"`
+
+	// Relations
+	TaskStage *TFETaskStage `jsonapi:"relationship" json:"task-stage"`
+}
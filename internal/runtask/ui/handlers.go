@@ -0,0 +1,343 @@
+package ui
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/runtask"
+	"github.com/leg100/otf/internal/ui/helpers"
+	"github.com/leg100/otf/internal/workspace"
+)
+
+type Handlers struct {
+	Client     Client
+	Authorizer authz.Interface
+}
+
+type Client interface {
+	CreateTask(ctx context.Context, org organization.Name, opts runtask.CreateTaskOptions) (*runtask.Task, error)
+	GetTask(ctx context.Context, id resource.TfeID) (*runtask.Task, error)
+	ListTasks(ctx context.Context, org organization.Name) ([]*runtask.Task, error)
+	UpdateTask(ctx context.Context, id resource.TfeID, opts runtask.UpdateTaskOptions) (*runtask.Task, error)
+	DeleteTask(ctx context.Context, id resource.TfeID) (*runtask.Task, error)
+
+	CreateWorkspaceTask(ctx context.Context, workspaceID resource.TfeID, opts runtask.CreateWorkspaceTaskOptions) (*runtask.WorkspaceTask, error)
+	ListWorkspaceTasks(ctx context.Context, workspaceID resource.TfeID) ([]*runtask.WorkspaceTask, error)
+	UpdateWorkspaceTask(ctx context.Context, id resource.TfeID, opts runtask.UpdateWorkspaceTaskOptions) (*runtask.WorkspaceTask, error)
+	DeleteWorkspaceTask(ctx context.Context, id resource.TfeID) (*runtask.WorkspaceTask, error)
+
+	ListRunStages(ctx context.Context, runID resource.TfeID) ([]*runtask.Stage, error)
+
+	GetWorkspace(ctx context.Context, id resource.TfeID) (*workspace.Workspace, error)
+}
+
+func (h *Handlers) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/organizations/{organization_name}/run-tasks", h.listTasks).Methods("GET")
+	r.HandleFunc("/organizations/{organization_name}/run-tasks/create", h.createTask).Methods("POST")
+	r.HandleFunc("/run-tasks/{task_id}", h.getTask).Methods("GET")
+	r.HandleFunc("/run-tasks/{task_id}/update", h.updateTask).Methods("POST")
+	r.HandleFunc("/run-tasks/{task_id}/delete", h.deleteTask).Methods("POST")
+
+	r.HandleFunc("/workspaces/{workspace_id}/edit-run-tasks", h.editWorkspaceTasks).Methods("GET")
+	r.HandleFunc("/workspaces/{workspace_id}/workspace-run-tasks/create", h.createWorkspaceTask).Methods("POST")
+	r.HandleFunc("/workspace-run-tasks/{workspace_task_id}/update", h.updateWorkspaceTask).Methods("POST")
+	r.HandleFunc("/workspace-run-tasks/{workspace_task_id}/delete", h.deleteWorkspaceTask).Methods("POST")
+
+	r.HandleFunc("/runs/{run_id}/task-stages", h.listRunStages).Methods("GET")
+}
+
+func (h *Handlers) listTasks(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Name organization.Name `schema:"organization_name"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	tasks, err := h.Client.ListTasks(r.Context(), params.Name)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		listTasks(listTasksProps{
+			organization: params.Name,
+			tasks:        tasks,
+			canCreate:    h.Authorizer.CanAccess(r.Context(), resource.Create, resource.RunTaskKind, params.Name),
+		}),
+		"run tasks",
+		w,
+		r,
+		helpers.WithOrganization(params.Name),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Run Tasks"},
+		),
+		helpers.WithSideMenu(helpers.OrganizationSettingsMenu(params.Name)),
+	)
+}
+
+func (h *Handlers) createTask(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+		Name         string            `schema:"name,required"`
+		Description  string            `schema:"description"`
+		URL          string            `schema:"url,required"`
+		HMACKey      string            `schema:"hmac_key"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	opts := runtask.CreateTaskOptions{
+		Name:        params.Name,
+		Description: params.Description,
+		URL:         params.URL,
+	}
+	if params.HMACKey != "" {
+		opts.HMACKey = &params.HMACKey
+	}
+	task, err := h.Client.CreateTask(r.Context(), params.Organization, opts)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "created run task: "+task.Name)
+	http.Redirect(w, r, path.List(resource.RunTaskKind, task.Organization), http.StatusFound)
+}
+
+func (h *Handlers) getTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	task, err := h.Client.GetTask(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		getTask(getTaskProps{
+			task:      task,
+			canUpdate: h.Authorizer.CanAccess(r.Context(), resource.Update, resource.RunTaskKind, id),
+		}),
+		task.Name,
+		w,
+		r,
+		helpers.WithOrganization(task.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Run Tasks", Link: path.List(resource.RunTaskKind, task.Organization)},
+			helpers.Breadcrumb{Name: task.Name},
+		),
+		helpers.WithSideMenu(helpers.OrganizationSettingsMenu(task.Organization)),
+	)
+}
+
+func (h *Handlers) updateTask(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ID            resource.TfeID `schema:"task_id,required"`
+		Name          string         `schema:"name,required"`
+		Description   string         `schema:"description"`
+		URL           string         `schema:"url,required"`
+		HMACKey       string         `schema:"hmac_key"`
+		RemoveHMACKey bool           `schema:"remove_hmac_key"`
+		Enabled       bool           `schema:"enabled"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	opts := runtask.UpdateTaskOptions{
+		Name:        &params.Name,
+		Description: &params.Description,
+		URL:         &params.URL,
+		Enabled:     &params.Enabled,
+	}
+	// An empty key leaves the existing key unchanged, unless the user has
+	// chosen to remove it.
+	if params.RemoveHMACKey {
+		opts.HMACKey = new("")
+	} else if params.HMACKey != "" {
+		opts.HMACKey = &params.HMACKey
+	}
+	task, err := h.Client.UpdateTask(r.Context(), params.ID, opts)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated run task: "+task.Name)
+	http.Redirect(w, r, path.Get(task.ID), http.StatusFound)
+}
+
+func (h *Handlers) deleteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("task_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	task, err := h.Client.DeleteTask(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "deleted run task: "+task.Name)
+	http.Redirect(w, r, path.List(resource.RunTaskKind, task.Organization), http.StatusFound)
+}
+
+func (h *Handlers) editWorkspaceTasks(w http.ResponseWriter, r *http.Request) {
+	workspaceID, err := decode.ID("workspace_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	ws, err := h.Client.GetWorkspace(r.Context(), workspaceID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	tasks, err := h.Client.ListTasks(r.Context(), ws.Organization)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	wts, err := h.Client.ListWorkspaceTasks(r.Context(), workspaceID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	// Build list of attached and unattached tasks
+	props := editWorkspaceTasksProps{
+		ws:        ws,
+		canUpdate: h.Authorizer.CanAccess(r.Context(), resource.Create, resource.WorkspaceRunTaskKind, workspaceID),
+	}
+	for _, task := range tasks {
+		var attachment *runtask.WorkspaceTask
+		for _, wt := range wts {
+			if wt.TaskID == task.ID {
+				attachment = wt
+				break
+			}
+		}
+		if attachment != nil {
+			props.attached = append(props.attached, attachedTask{task: task, wt: attachment})
+		} else {
+			props.unattached = append(props.unattached, task)
+		}
+	}
+
+	helpers.RenderPage(
+		editWorkspaceTasks(props),
+		"edit run tasks | "+ws.ID.String(),
+		w,
+		r,
+		helpers.WithWorkspace(ws, h.Authorizer),
+		helpers.WithSideMenu(helpers.WorkspaceSettingsMenu(ws.ID)),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Run Tasks"},
+		),
+	)
+}
+
+func (h *Handlers) createWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		WorkspaceID      resource.TfeID           `schema:"workspace_id,required"`
+		TaskID           resource.TfeID           `schema:"task_id,required"`
+		Stage            run.TaskStage            `schema:"stage"`
+		EnforcementLevel runtask.EnforcementLevel `schema:"enforcement_level"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	_, err := h.Client.CreateWorkspaceTask(r.Context(), params.WorkspaceID, runtask.CreateWorkspaceTaskOptions{
+		TaskID:           params.TaskID,
+		Stage:            params.Stage,
+		EnforcementLevel: params.EnforcementLevel,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "attached run task")
+	http.Redirect(w, r, path.Resource(resource.Action("edit-run-tasks"), params.WorkspaceID), http.StatusFound)
+}
+
+func (h *Handlers) updateWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ID               resource.TfeID           `schema:"workspace_task_id,required"`
+		Stage            run.TaskStage            `schema:"stage,required"`
+		EnforcementLevel runtask.EnforcementLevel `schema:"enforcement_level,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	wt, err := h.Client.UpdateWorkspaceTask(r.Context(), params.ID, runtask.UpdateWorkspaceTaskOptions{
+		Stage:            &params.Stage,
+		EnforcementLevel: &params.EnforcementLevel,
+	})
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "updated run task")
+	http.Redirect(w, r, path.Resource(resource.Action("edit-run-tasks"), wt.WorkspaceID), http.StatusFound)
+}
+
+func (h *Handlers) deleteWorkspaceTask(w http.ResponseWriter, r *http.Request) {
+	id, err := decode.ID("workspace_task_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	wt, err := h.Client.DeleteWorkspaceTask(r.Context(), id)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.FlashSuccess(w, "detached run task")
+	http.Redirect(w, r, path.Resource(resource.Action("edit-run-tasks"), wt.WorkspaceID), http.StatusFound)
+}
+
+// listRunStages renders the task stages of a run, for embedding in the run
+// page.
+func (h *Handlers) listRunStages(w http.ResponseWriter, r *http.Request) {
+	runID, err := decode.ID("run_id", r)
+	if err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	stages, err := h.Client.ListRunStages(r.Context(), runID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.Render(taskStages(stages), w, r)
+}
@@ -0,0 +1,141 @@
+package runtask
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/tfeapi"
+	"github.com/leg100/otf/internal/workspace"
+)
+
+// SignatureHeader is the header containing the hex-encoded HMAC-SHA512
+// signature of the body of a request sent to a task's URL. It is only set if
+// the task has an HMAC key.
+const SignatureHeader = "X-TFE-Task-Signature"
+
+type (
+	// Payload is the body of the request sent to a task's URL, as documented
+	// here:
+	//
+	// https://developer.hashicorp.com/terraform/cloud-docs/api-docs/run-tasks/run-tasks-integration#request-body
+	Payload struct {
+		PayloadVersion int           `json:"payload_version"`
+		Stage          run.TaskStage `json:"stage"`
+		// AccessToken permits the external service to report the task's
+		// outcome, and to retrieve the run's configuration and plan.
+		AccessToken                     string            `json:"access_token"`
+		ConfigurationVersionDownloadURL string            `json:"configuration_version_download_url"`
+		ConfigurationVersionID          resource.TfeID    `json:"configuration_version_id"`
+		IsSpeculative                   bool              `json:"is_speculative"`
+		OrganizationName                organization.Name `json:"organization_name"`
+		// PlanJSONAPIURL is only set for post-plan and pre-apply tasks.
+		PlanJSONAPIURL             *string          `json:"plan_json_api_url"`
+		RunAppURL                  string           `json:"run_app_url"`
+		RunCreatedAt               time.Time        `json:"run_created_at"`
+		RunCreatedBy               *string          `json:"run_created_by"`
+		RunID                      resource.TfeID   `json:"run_id"`
+		RunMessage                 string           `json:"run_message"`
+		TaskResultCallbackURL      string           `json:"task_result_callback_url"`
+		TaskResultEnforcementLevel EnforcementLevel `json:"task_result_enforcement_level"`
+		TaskResultID               resource.TfeID   `json:"task_result_id"`
+		VCSBranch                  *string          `json:"vcs_branch"`
+		VCSCommitURL               *string          `json:"vcs_commit_url"`
+		VCSPullRequestURL          *string          `json:"vcs_pull_request_url"`
+		WorkspaceAppURL            string           `json:"workspace_app_url"`
+		WorkspaceID                resource.TfeID   `json:"workspace_id"`
+		WorkspaceName              string           `json:"workspace_name"`
+		WorkspaceWorkingDirectory  string           `json:"workspace_working_directory"`
+	}
+
+	hostnameClient interface {
+		URL(path string) string
+	}
+
+	// invoker sends requests to invoke tasks.
+	invoker struct {
+		client *http.Client
+	}
+)
+
+func newPayload(hostnames hostnameClient, r *run.Run, ws *workspace.Workspace, stage *Stage, result *Result, token []byte) *Payload {
+	payload := &Payload{
+		PayloadVersion:                  1,
+		Stage:                           stage.Stage,
+		AccessToken:                     string(token),
+		ConfigurationVersionDownloadURL: hostnames.URL(fmt.Sprintf("%sconfiguration-versions/%s/download", tfeapi.APIPrefixV2, r.ConfigurationVersionID)),
+		ConfigurationVersionID:          r.ConfigurationVersionID,
+		IsSpeculative:                   r.PlanOnly,
+		OrganizationName:                r.Organization,
+		RunAppURL:                       hostnames.URL(path.Get(r.ID)),
+		RunCreatedAt:                    r.CreatedAt,
+		RunID:                           r.ID,
+		RunMessage:                      r.Message,
+		TaskResultCallbackURL:           hostnames.URL(fmt.Sprintf("%stask-results/%s/callback", tfeapi.APIPrefixV2, result.ID)),
+		TaskResultEnforcementLevel:      result.EnforcementLevel,
+		TaskResultID:                    result.ID,
+		WorkspaceAppURL:                 hostnames.URL(path.Get(ws.ID)),
+		WorkspaceID:                     ws.ID,
+		WorkspaceName:                   ws.Name,
+		WorkspaceWorkingDirectory:       ws.WorkingDirectory,
+	}
+	if stage.Stage != run.PrePlanStage {
+		planID := resource.ConvertTfeID(r.ID, "plan")
+		payload.PlanJSONAPIURL = new(hostnames.URL(fmt.Sprintf("%splans/%s/json-output", tfeapi.APIPrefixV2, planID)))
+	}
+	if r.CreatedBy != nil {
+		payload.RunCreatedBy = new(r.CreatedBy.String())
+	}
+	if attrs := r.IngressAttributes; attrs != nil {
+		payload.VCSBranch = &attrs.Branch
+		payload.VCSCommitURL = &attrs.CommitURL
+		if attrs.IsPullRequest {
+			payload.VCSPullRequestURL = &attrs.PullRequestURL
+		}
+	}
+	return payload
+}
+
+// invoke sends the payload to the task's URL, signing the request if the task
+// has an HMAC key. The external service is expected to respond with 200 OK
+// and to subsequently report the outcome via the callback URL.
+func (i *invoker) invoke(ctx context.Context, task *Task, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", task.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-type", "application/json")
+	if task.HMACKey != nil {
+		req.Header.Set(SignatureHeader, sign(body, *task.HMACKey))
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", task.URL, resp.Status)
+	}
+	return nil
+}
+
+// sign returns the hex-encoded HMAC-SHA512 signature of the body.
+func sign(body []byte, key string) string {
+	mac := hmac.New(sha512.New, []byte(key))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
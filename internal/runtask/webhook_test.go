@@ -0,0 +1,136 @@
+package runtask
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leg100/otf/internal/configversion"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHostnameClient struct{}
+
+func (fakeHostnameClient) URL(path string) string { return "https://otf.example.com" + path }
+
+func TestNewPayload(t *testing.T) {
+	ws := &workspace.Workspace{
+		ID:               resource.NewTfeID(resource.WorkspaceKind),
+		Name:             "dev",
+		WorkingDirectory: "infra",
+	}
+	r := &run.Run{
+		ID:                     resource.NewTfeID(resource.RunKind),
+		Organization:           organization.NewTestName(t),
+		ConfigurationVersionID: resource.NewTfeID(resource.ConfigVersionKind),
+		Message:                "triggered via ui",
+	}
+	task := stageTask{
+		Task:             &Task{ID: resource.NewTfeID(resource.RunTaskKind), Name: "scanner"},
+		EnforcementLevel: Mandatory,
+	}
+
+	t.Run("pre-plan", func(t *testing.T) {
+		stage := newStage(r.ID, run.PrePlanStage, []stageTask{task})
+		got := newPayload(fakeHostnameClient{}, r, ws, stage, stage.Results[0], []byte("token"))
+
+		assert.Equal(t, 1, got.PayloadVersion)
+		assert.Equal(t, run.PrePlanStage, got.Stage)
+		assert.Equal(t, "token", got.AccessToken)
+		assert.Equal(t, Mandatory, got.TaskResultEnforcementLevel)
+		assert.Equal(t, "https://otf.example.com/api/v2/task-results/"+stage.Results[0].ID.String()+"/callback", got.TaskResultCallbackURL)
+		assert.Equal(t, "https://otf.example.com/api/v2/configuration-versions/"+r.ConfigurationVersionID.String()+"/download", got.ConfigurationVersionDownloadURL)
+		assert.Equal(t, "infra", got.WorkspaceWorkingDirectory)
+		assert.Nil(t, got.PlanJSONAPIURL)
+		assert.Nil(t, got.VCSBranch)
+	})
+
+	t.Run("post-plan", func(t *testing.T) {
+		stage := newStage(r.ID, run.PostPlanStage, []stageTask{task})
+		got := newPayload(fakeHostnameClient{}, r, ws, stage, stage.Results[0], []byte("token"))
+
+		planID := resource.ConvertTfeID(r.ID, "plan")
+		assert.Equal(t, new("https://otf.example.com/api/v2/plans/"+planID.String()+"/json-output"), got.PlanJSONAPIURL)
+	})
+
+	t.Run("pull request", func(t *testing.T) {
+		vcsRun := *r
+		vcsRun.IngressAttributes = &configversion.IngressAttributes{
+			Branch:         "feature",
+			CommitURL:      "https://github.com/acme/infra/commit/abc123",
+			IsPullRequest:  true,
+			PullRequestURL: "https://github.com/acme/infra/pull/1",
+		}
+		stage := newStage(r.ID, run.PostPlanStage, []stageTask{task})
+		got := newPayload(fakeHostnameClient{}, &vcsRun, ws, stage, stage.Results[0], []byte("token"))
+
+		assert.Equal(t, new("feature"), got.VCSBranch)
+		assert.Equal(t, new("https://github.com/acme/infra/commit/abc123"), got.VCSCommitURL)
+		assert.Equal(t, new("https://github.com/acme/infra/pull/1"), got.VCSPullRequestURL)
+	})
+}
+
+func TestInvoker(t *testing.T) {
+	payload := &Payload{
+		PayloadVersion:         1,
+		Stage:                  run.PostPlanStage,
+		ConfigurationVersionID: resource.NewTfeID(resource.ConfigVersionKind),
+		RunID:                  resource.NewTfeID(resource.RunKind),
+		TaskResultID:           resource.NewTfeID(resource.TaskResultKind),
+		WorkspaceID:            resource.NewTfeID(resource.WorkspaceKind),
+	}
+
+	t.Run("signed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var got Payload
+			require.NoError(t, json.Unmarshal(body, &got))
+			assert.Equal(t, *payload, got)
+
+			sig, err := hex.DecodeString(r.Header.Get(SignatureHeader))
+			require.NoError(t, err)
+			want, err := hex.DecodeString(sign(body, "secret"))
+			require.NoError(t, err)
+			assert.True(t, hmac.Equal(want, sig))
+		}))
+		t.Cleanup(srv.Close)
+
+		inv := &invoker{client: srv.Client()}
+		err := inv.invoke(context.Background(), &Task{URL: srv.URL, HMACKey: new("secret")}, payload)
+		require.NoError(t, err)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+		}))
+		t.Cleanup(srv.Close)
+
+		inv := &invoker{client: srv.Client()}
+		err := inv.invoke(context.Background(), &Task{URL: srv.URL}, payload)
+		require.NoError(t, err)
+	})
+
+	t.Run("unexpected response", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+
+		inv := &invoker{client: srv.Client()}
+		err := inv.invoke(context.Background(), &Task{URL: srv.URL}, payload)
+		assert.Error(t, err)
+	})
+}
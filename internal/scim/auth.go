@@ -0,0 +1,48 @@
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authn"
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/tokens"
+)
+
+var _ authn.AuthenticationRoute = (*Route)(nil)
+
+// Route is the authentication route for the SCIM API.
+type Route struct{}
+
+func (a *Route) IsPath(path string) bool {
+	return strings.HasPrefix(path, Prefix)
+}
+
+func (a *Route) HandleError(err error, w http.ResponseWriter, r *http.Request) {
+	writeError(w, errors.Join(internal.ErrUnauthorized, err))
+}
+
+// Authenticator authenticates requests from an identity provider using the
+// dedicated SCIM token.
+type Authenticator struct {
+	Token string
+}
+
+func (a *Authenticator) Authenticate(w http.ResponseWriter, r *http.Request) (authz.Subject, error) {
+	if a.Token == "" {
+		// No SCIM token configured.
+		return nil, nil
+	}
+	token, err := tokens.ParseBearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if a.Token != token {
+		return nil, internal.ErrUnauthorized
+	}
+	// The identity provider is granted the privileges necessary to manage
+	// users and teams across all organizations.
+	return &authz.Superuser{Username: "scim"}, nil
+}
@@ -0,0 +1,210 @@
+// Package scim implements a SCIM 2.0 server, permitting an identity provider to
+// provision users and teams.
+//
+// https://datatracker.ietf.org/doc/html/rfc7644
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/user"
+)
+
+const (
+	// Prefix is the path prefix for all SCIM endpoints.
+	Prefix = "/scim/v2"
+
+	contentType = "application/scim+json"
+
+	userSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	listSchema         = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	patchSchema        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	errorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	spConfigSchema     = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	resourceTypeSchema = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+var errInvalidFilter = errors.New("only filters of the form '<attribute> eq \"<value>\"' are supported")
+
+type (
+	meta struct {
+		ResourceType string     `json:"resourceType"`
+		Created      *time.Time `json:"created,omitempty"`
+		LastModified *time.Time `json:"lastModified,omitempty"`
+		Location     string     `json:"location"`
+	}
+
+	// reference is a reference from one resource to another, e.g. a group
+	// member.
+	reference struct {
+		Value   string `json:"value"`
+		Display string `json:"display,omitempty"`
+		Ref     string `json:"$ref,omitempty"`
+	}
+
+	userResource struct {
+		Schemas  []string    `json:"schemas"`
+		ID       string      `json:"id,omitempty"`
+		UserName string      `json:"userName"`
+		Active   *bool       `json:"active,omitempty"`
+		Groups   []reference `json:"groups,omitempty"`
+		Meta     *meta       `json:"meta,omitempty"`
+	}
+
+	groupResource struct {
+		Schemas     []string    `json:"schemas"`
+		ID          string      `json:"id,omitempty"`
+		DisplayName string      `json:"displayName"`
+		Members     []reference `json:"members,omitempty"`
+		Meta        *meta       `json:"meta,omitempty"`
+	}
+
+	listResponse[T any] struct {
+		Schemas      []string `json:"schemas"`
+		TotalResults int      `json:"totalResults"`
+		StartIndex   int      `json:"startIndex"`
+		ItemsPerPage int      `json:"itemsPerPage"`
+		Resources    []T      `json:"Resources"`
+	}
+
+	patchRequest struct {
+		Schemas    []string         `json:"schemas"`
+		Operations []patchOperation `json:"Operations"`
+	}
+
+	patchOperation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	errorResponse struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}
+
+	// filter is a parsed SCIM filter. Only equality filters on a single
+	// attribute are supported, which is all identity providers use to look up
+	// resources.
+	filter struct {
+		attribute string
+		value     string
+	}
+)
+
+// parseFilter parses a filter of the form '<attribute> eq "<value>"'. An empty
+// filter returns nil.
+func parseFilter(s string) (*filter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	attribute, rest, ok := strings.Cut(s, " ")
+	if !ok {
+		return nil, errInvalidFilter
+	}
+	op, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(op, "eq") {
+		return nil, errInvalidFilter
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(value))
+	if err != nil {
+		return nil, errInvalidFilter
+	}
+	return &filter{attribute: strings.ToLower(attribute), value: value}, nil
+}
+
+// paginate returns the page of items specified by the 1-based startIndex and
+// count query parameters.
+func paginate[T any](r *http.Request, items []T) listResponse[T] {
+	start, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if err != nil || start < 1 {
+		start = 1
+	}
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 {
+		count = len(items)
+	}
+	page := make([]T, 0)
+	if from := start - 1; from < len(items) {
+		page = items[from:min(from+count, len(items))]
+	}
+	return listResponse[T]{
+		Schemas:      []string{listSchema},
+		TotalResults: len(items),
+		StartIndex:   start,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// parseBool parses a boolean value, which some identity providers send as a
+// string.
+func parseBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, fmt.Errorf("invalid boolean value: %s", raw)
+	}
+	return strconv.ParseBool(s)
+}
+
+func writeResponse(w http.ResponseWriter, v any, status int) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a SCIM error response, deriving the status from the error.
+func writeError(w http.ResponseWriter, err error) {
+	var (
+		status   = http.StatusInternalServerError
+		scimType string
+	)
+	switch {
+	case errors.Is(err, internal.ErrResourceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, internal.ErrResourceAlreadyExists):
+		status = http.StatusConflict
+		scimType = "uniqueness"
+	case errors.Is(err, errInvalidFilter):
+		status = http.StatusBadRequest
+		scimType = "invalidFilter"
+	case errors.Is(err, internal.ErrAccessNotPermitted):
+		status = http.StatusForbidden
+	case errors.Is(err, internal.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, internal.ErrInvalidName),
+		errors.Is(err, internal.ErrRequiredName),
+		errors.Is(err, user.ErrCannotDeleteOnlyOwner),
+		errors.As(err, new(*json.SyntaxError)),
+		errors.As(err, new(*json.UnmarshalTypeError)),
+		errors.As(err, new(invalidValueError)):
+		status = http.StatusBadRequest
+		scimType = "invalidValue"
+	}
+	writeResponse(w, errorResponse{
+		Schemas:  []string{errorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   err.Error(),
+	}, status)
+}
+
+// invalidValueError is an error with a value in a request.
+type invalidValueError string
+
+func (e invalidValueError) Error() string { return string(e) }
@@ -0,0 +1,684 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/team"
+	"github.com/leg100/otf/internal/user"
+)
+
+// Server serves the SCIM API, mapping SCIM users to OTF users, and SCIM groups
+// to OTF teams. Because teams belong to an organization, a group's display
+// name is in the format <organization>/<team>.
+type Server struct {
+	logr.Logger
+
+	Users         userClient
+	Teams         teamClient
+	Organizations organizationClient
+	URLs          urlClient
+}
+
+type (
+	userClient interface {
+		Create(ctx context.Context, username string, opts ...user.NewUserOption) (*user.User, error)
+		GetUser(ctx context.Context, spec user.UserSpec) (*user.User, error)
+		List(ctx context.Context) ([]*user.User, error)
+		UpdateUser(ctx context.Context, userID resource.TfeID, opts user.UpdateUserOptions) (*user.User, error)
+		Delete(ctx context.Context, username user.Username) error
+		ListTeamUsers(ctx context.Context, teamID resource.TfeID) ([]*user.User, error)
+		AddTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error
+		RemoveTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error
+	}
+
+	teamClient interface {
+		CreateTeam(ctx context.Context, organization organization.Name, opts team.CreateTeamOptions) (*team.Team, error)
+		GetTeam(ctx context.Context, organization organization.Name, name string) (*team.Team, error)
+		GetTeamByID(ctx context.Context, teamID resource.TfeID) (*team.Team, error)
+		ListTeams(ctx context.Context, organization organization.Name) ([]*team.Team, error)
+		UpdateTeam(ctx context.Context, teamID resource.TfeID, opts team.UpdateTeamOptions) (*team.Team, error)
+		DeleteTeam(ctx context.Context, teamID resource.TfeID) error
+	}
+
+	organizationClient interface {
+		ListOrganizations(ctx context.Context, opts organization.ListOptions) (*resource.Page[*organization.Organization], error)
+	}
+
+	urlClient interface {
+		URL(path string) string
+	}
+)
+
+func (s *Server) AddHandlers(r *mux.Router) {
+	r = r.PathPrefix(Prefix).Subrouter()
+
+	r.HandleFunc("/ServiceProviderConfig", s.getServiceProviderConfig).Methods("GET")
+	r.HandleFunc("/ResourceTypes", s.listResourceTypes).Methods("GET")
+
+	r.HandleFunc("/Users", s.listUsers).Methods("GET")
+	r.HandleFunc("/Users", s.createUser).Methods("POST")
+	r.HandleFunc("/Users/{id}", s.getUser).Methods("GET")
+	r.HandleFunc("/Users/{id}", s.replaceUser).Methods("PUT")
+	r.HandleFunc("/Users/{id}", s.patchUser).Methods("PATCH")
+	r.HandleFunc("/Users/{id}", s.deleteUser).Methods("DELETE")
+
+	r.HandleFunc("/Groups", s.listGroups).Methods("GET")
+	r.HandleFunc("/Groups", s.createGroup).Methods("POST")
+	r.HandleFunc("/Groups/{id}", s.getGroup).Methods("GET")
+	r.HandleFunc("/Groups/{id}", s.replaceGroup).Methods("PUT")
+	r.HandleFunc("/Groups/{id}", s.patchGroup).Methods("PATCH")
+	r.HandleFunc("/Groups/{id}", s.deleteGroup).Methods("DELETE")
+}
+
+func (s *Server) getServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	writeResponse(w, map[string]any{
+		"schemas":        []string{spConfigSchema},
+		"patch":          supported{true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": 0},
+		"changePassword": supported{false},
+		"sort":           supported{false},
+		"etag":           supported{false},
+		"authenticationSchemes": []map[string]any{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "OAuth Bearer Token",
+				"description": "Authentication using the SCIM token configured on otfd",
+			},
+		},
+	}, http.StatusOK)
+}
+
+func (s *Server) listResourceTypes(w http.ResponseWriter, r *http.Request) {
+	resourceTypes := []map[string]any{
+		{
+			"schemas":  []string{resourceTypeSchema},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   userSchema,
+		},
+		{
+			"schemas":  []string{resourceTypeSchema},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   groupSchema,
+		},
+	}
+	writeResponse(w, paginate(r, resourceTypes), http.StatusOK)
+}
+
+//
+// Users
+//
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var users []*user.User
+	if f != nil {
+		if f.attribute != "username" {
+			writeError(w, errInvalidFilter)
+			return
+		}
+		username, err := user.NewUsername(f.value)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		u, err := s.Users.GetUser(r.Context(), user.UserSpec{Username: &username})
+		if err != nil && !errors.Is(err, internal.ErrResourceNotFound) {
+			writeError(w, err)
+			return
+		}
+		if u != nil {
+			users = append(users, u)
+		}
+	} else {
+		users, err = s.Users.List(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		// Exclude the site admin, which is managed by otfd rather than by an
+		// identity provider.
+		users = slices.DeleteFunc(users, func(u *user.User) bool {
+			return u.ID == user.SiteAdminID
+		})
+	}
+	resources := make([]*userResource, len(users))
+	for i, u := range users {
+		resources[i] = s.toUser(u)
+	}
+	writeResponse(w, paginate(r, resources), http.StatusOK)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var params userResource
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, err)
+		return
+	}
+	created, err := s.Users.Create(r.Context(), params.UserName)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if params.Active != nil && !*params.Active {
+		created, err = s.Users.UpdateUser(r.Context(), created.ID, user.UpdateUserOptions{
+			Disabled: new(true),
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	writeResponse(w, s.toUser(created), http.StatusCreated)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	u, err := s.userFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, s.toUser(u), http.StatusOK)
+}
+
+func (s *Server) replaceUser(w http.ResponseWriter, r *http.Request) {
+	u, err := s.userFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var params userResource
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, err)
+		return
+	}
+	// A replaced user without the active attribute is active.
+	opts := user.UpdateUserOptions{
+		Username: &params.UserName,
+		Disabled: new(params.Active != nil && !*params.Active),
+	}
+	updated, err := s.Users.UpdateUser(r.Context(), u.ID, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, s.toUser(updated), http.StatusOK)
+}
+
+func (s *Server) patchUser(w http.ResponseWriter, r *http.Request) {
+	u, err := s.userFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var params patchRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, err)
+		return
+	}
+	var opts user.UpdateUserOptions
+	for _, op := range params.Operations {
+		if err := patchUserOptions(&opts, op); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	updated, err := s.Users.UpdateUser(r.Context(), u.ID, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, s.toUser(updated), http.StatusOK)
+}
+
+// patchUserOptions applies a patch operation to user update options. Only the
+// userName and active attributes can be patched; patches to other attributes
+// are ignored because OTF does not store them.
+func patchUserOptions(opts *user.UpdateUserOptions, op patchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+	default:
+		return invalidValueError(fmt.Sprintf("unsupported user patch operation: %s", op.Op))
+	}
+	// Without a path, the value is a map of attributes to values.
+	values := map[string]json.RawMessage{op.Path: op.Value}
+	if op.Path == "" {
+		values = nil
+		if err := json.Unmarshal(op.Value, &values); err != nil {
+			return err
+		}
+	}
+	for attribute, value := range values {
+		switch strings.ToLower(attribute) {
+		case "active":
+			active, err := parseBool(value)
+			if err != nil {
+				return invalidValueError(err.Error())
+			}
+			opts.Disabled = new(!active)
+		case "username":
+			var username string
+			if err := json.Unmarshal(value, &username); err != nil {
+				return err
+			}
+			opts.Username = &username
+		}
+	}
+	return nil
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	u, err := s.userFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.Users.Delete(r.Context(), u.Username); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) userFromPath(r *http.Request) (*user.User, error) {
+	id, err := resource.ParseTfeID(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, internal.ErrResourceNotFound
+	}
+	return s.Users.GetUser(r.Context(), user.UserSpec{UserID: &id})
+}
+
+func (s *Server) toUser(from *user.User) *userResource {
+	to := &userResource{
+		Schemas:  []string{userSchema},
+		ID:       from.ID.String(),
+		UserName: from.Username.String(),
+		Active:   new(!from.Disabled),
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      &from.CreatedAt,
+			LastModified: &from.UpdatedAt,
+			Location:     s.URLs.URL(Prefix + "/Users/" + from.ID.String()),
+		},
+	}
+	for _, t := range from.Teams {
+		to.Groups = append(to.Groups, s.groupReference(t))
+	}
+	return to
+}
+
+//
+// Groups
+//
+
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var teams []*team.Team
+	if f != nil {
+		if f.attribute != "displayname" {
+			writeError(w, errInvalidFilter)
+			return
+		}
+		org, name, err := parseDisplayName(f.value)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		t, err := s.Teams.GetTeam(r.Context(), org, name)
+		if err != nil && !errors.Is(err, internal.ErrResourceNotFound) {
+			writeError(w, err)
+			return
+		}
+		if t != nil {
+			teams = append(teams, t)
+		}
+	} else {
+		orgs, err := resource.ListAll(func(opts resource.PageOptions) (*resource.Page[*organization.Organization], error) {
+			return s.Organizations.ListOrganizations(r.Context(), organization.ListOptions{PageOptions: opts})
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, org := range orgs {
+			orgTeams, err := s.Teams.ListTeams(r.Context(), org.Name)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			teams = append(teams, orgTeams...)
+		}
+	}
+	// Members are omitted from the listing, to avoid retrieving the members
+	// of every team.
+	resources := make([]*groupResource, len(teams))
+	for i, t := range teams {
+		resources[i] = s.toGroup(t, nil)
+	}
+	writeResponse(w, paginate(r, resources), http.StatusOK)
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	var params groupResource
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, err)
+		return
+	}
+	org, name, err := parseDisplayName(params.DisplayName)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	created, err := s.Teams.CreateTeam(r.Context(), org, team.CreateTeamOptions{Name: &name})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.setMembers(r.Context(), created, params.Members); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeGroup(w, r.Context(), created, http.StatusCreated)
+}
+
+func (s *Server) getGroup(w http.ResponseWriter, r *http.Request) {
+	t, err := s.teamFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeGroup(w, r.Context(), t, http.StatusOK)
+}
+
+func (s *Server) replaceGroup(w http.ResponseWriter, r *http.Request) {
+	t, err := s.teamFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var params groupResource
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, err)
+		return
+	}
+	t, err = s.renameTeam(r.Context(), t, params.DisplayName)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.setMembers(r.Context(), t, params.Members); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeGroup(w, r.Context(), t, http.StatusOK)
+}
+
+func (s *Server) patchGroup(w http.ResponseWriter, r *http.Request) {
+	t, err := s.teamFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var params patchRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, op := range params.Operations {
+		t, err = s.patchTeam(r.Context(), t, op)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	s.writeGroup(w, r.Context(), t, http.StatusOK)
+}
+
+// patchTeam applies a patch operation to a team, either renaming the team, or
+// adding, removing, or replacing its members.
+func (s *Server) patchTeam(ctx context.Context, t *team.Team, op patchOperation) (*team.Team, error) {
+	path := strings.ToLower(op.Path)
+	switch {
+	case path == "displayname":
+		var displayName string
+		if err := json.Unmarshal(op.Value, &displayName); err != nil {
+			return nil, err
+		}
+		return s.renameTeam(ctx, t, displayName)
+	case path == "" && strings.EqualFold(op.Op, "replace"):
+		// Without a path, the value is a map of attributes to values.
+		var values struct {
+			DisplayName *string      `json:"displayName"`
+			Members     *[]reference `json:"members"`
+		}
+		if err := json.Unmarshal(op.Value, &values); err != nil {
+			return nil, err
+		}
+		if values.DisplayName != nil {
+			var err error
+			if t, err = s.renameTeam(ctx, t, *values.DisplayName); err != nil {
+				return nil, err
+			}
+		}
+		if values.Members != nil {
+			return t, s.setMembers(ctx, t, *values.Members)
+		}
+		return t, nil
+	case path == "members":
+		var members []reference
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return nil, err
+			}
+		}
+		switch strings.ToLower(op.Op) {
+		case "add":
+			return t, s.addMembers(ctx, t, members)
+		case "remove":
+			if len(members) == 0 {
+				// Remove all members
+				return t, s.setMembers(ctx, t, nil)
+			}
+			return t, s.removeMembers(ctx, t, members)
+		case "replace":
+			return t, s.setMembers(ctx, t, members)
+		}
+	case strings.HasPrefix(path, "members[") && strings.EqualFold(op.Op, "remove"):
+		// Remove a single member specified with a filter, e.g.
+		// members[value eq "user-123"]
+		f, err := parseFilter(strings.TrimSuffix(op.Path[len("members["):], "]"))
+		if err != nil {
+			return nil, err
+		}
+		if f == nil || f.attribute != "value" {
+			return nil, errInvalidFilter
+		}
+		return t, s.removeMembers(ctx, t, []reference{{Value: f.value}})
+	}
+	return nil, invalidValueError(fmt.Sprintf("unsupported group patch operation: %s %s", op.Op, op.Path))
+}
+
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	t, err := s.teamFromPath(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.Teams.DeleteTeam(r.Context(), t.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) teamFromPath(r *http.Request) (*team.Team, error) {
+	id, err := resource.ParseTfeID(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, internal.ErrResourceNotFound
+	}
+	return s.Teams.GetTeamByID(r.Context(), id)
+}
+
+// renameTeam renames a team. A team cannot be moved to another organization.
+func (s *Server) renameTeam(ctx context.Context, t *team.Team, displayName string) (*team.Team, error) {
+	org, name, err := parseDisplayName(displayName)
+	if err != nil {
+		return nil, err
+	}
+	if org != t.Organization {
+		return nil, invalidValueError("a group cannot be moved to another organization")
+	}
+	if name == t.Name {
+		return t, nil
+	}
+	return s.Teams.UpdateTeam(ctx, t.ID, team.UpdateTeamOptions{Name: &name})
+}
+
+// setMembers authoritatively sets the members of a team.
+func (s *Server) setMembers(ctx context.Context, t *team.Team, members []reference) error {
+	want, err := s.usernames(ctx, members)
+	if err != nil {
+		return err
+	}
+	current, err := s.Users.ListTeamUsers(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	var have []user.Username
+	for _, u := range current {
+		have = append(have, u.Username)
+	}
+	if add := internal.Diff(want, have); len(add) > 0 {
+		if err := s.Users.AddTeamMembership(ctx, t.ID, add); err != nil {
+			return err
+		}
+	}
+	if remove := internal.Diff(have, want); len(remove) > 0 {
+		if err := s.Users.RemoveTeamMembership(ctx, t.ID, remove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) addMembers(ctx context.Context, t *team.Team, members []reference) error {
+	usernames, err := s.usernames(ctx, members)
+	if err != nil {
+		return err
+	}
+	if len(usernames) == 0 {
+		return nil
+	}
+	return s.Users.AddTeamMembership(ctx, t.ID, usernames)
+}
+
+func (s *Server) removeMembers(ctx context.Context, t *team.Team, members []reference) error {
+	usernames, err := s.usernames(ctx, members)
+	if err != nil {
+		return err
+	}
+	if len(usernames) == 0 {
+		return nil
+	}
+	return s.Users.RemoveTeamMembership(ctx, t.ID, usernames)
+}
+
+// usernames retrieves the usernames of the referenced users.
+func (s *Server) usernames(ctx context.Context, members []reference) ([]user.Username, error) {
+	usernames := make([]user.Username, len(members))
+	for i, member := range members {
+		id, err := resource.ParseTfeID(member.Value)
+		if err != nil {
+			return nil, invalidValueError(fmt.Sprintf("invalid member: %s", member.Value))
+		}
+		u, err := s.Users.GetUser(ctx, user.UserSpec{UserID: &id})
+		if err != nil {
+			return nil, fmt.Errorf("retrieving member: %w", err)
+		}
+		usernames[i] = u.Username
+	}
+	return usernames, nil
+}
+
+func (s *Server) writeGroup(w http.ResponseWriter, ctx context.Context, t *team.Team, status int) {
+	members, err := s.Users.ListTeamUsers(ctx, t.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, s.toGroup(t, members), status)
+}
+
+func (s *Server) toGroup(from *team.Team, members []*user.User) *groupResource {
+	to := &groupResource{
+		Schemas:     []string{groupSchema},
+		ID:          from.ID.String(),
+		DisplayName: displayName(from),
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      &from.CreatedAt,
+			Location:     s.URLs.URL(Prefix + "/Groups/" + from.ID.String()),
+		},
+	}
+	for _, u := range members {
+		to.Members = append(to.Members, reference{
+			Value:   u.ID.String(),
+			Display: u.Username.String(),
+			Ref:     s.URLs.URL(Prefix + "/Users/" + u.ID.String()),
+		})
+	}
+	return to
+}
+
+func (s *Server) groupReference(t *team.Team) reference {
+	return reference{
+		Value:   t.ID.String(),
+		Display: displayName(t),
+		Ref:     s.URLs.URL(Prefix + "/Groups/" + t.ID.String()),
+	}
+}
+
+// displayName returns the SCIM display name for a team.
+func displayName(t *team.Team) string {
+	return t.Organization.String() + "/" + t.Name
+}
+
+// parseDisplayName parses a SCIM display name in the format
+// <organization>/<team>.
+func parseDisplayName(displayName string) (organization.Name, string, error) {
+	org, name, ok := strings.Cut(displayName, "/")
+	if !ok || name == "" {
+		return organization.Name{}, "", invalidValueError("group display name must be in the format <organization>/<team>")
+	}
+	orgName, err := organization.NewName(org)
+	if err != nil {
+		return organization.Name{}, "", err
+	}
+	return orgName, name, nil
+}
@@ -0,0 +1,351 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/team"
+	"github.com/leg100/otf/internal/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Users(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	// create user
+	w := srv.do(t, "POST", "/Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"bobby","active":true}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created userResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "bobby", created.UserName)
+	assert.True(t, *created.Active)
+
+	// creating the same user again is a conflict
+	w = srv.do(t, "POST", "/Users", `{"userName":"bobby"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// find user by username
+	w = srv.do(t, "GET", `/Users?filter=userName+eq+"bobby"`, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list listResponse[userResource]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.TotalResults)
+
+	// find non-existent user
+	w = srv.do(t, "GET", `/Users?filter=userName+eq+"alice"`, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 0, list.TotalResults)
+
+	// deactivate user
+	w = srv.do(t, "PATCH", "/Users/"+created.ID, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","path":"active","value":"False"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched userResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.False(t, *patched.Active)
+
+	// reactivate and rename user
+	w = srv.do(t, "PUT", "/Users/"+created.ID, `{"userName":"robert","active":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var replaced userResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replaced))
+	assert.Equal(t, "robert", replaced.UserName)
+	assert.True(t, *replaced.Active)
+
+	// delete user
+	w = srv.do(t, "DELETE", "/Users/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = srv.do(t, "GET", "/Users/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServer_Groups(t *testing.T) {
+	srv, org := newTestServer(t)
+	bobby := srv.createUser(t, "bobby")
+	alice := srv.createUser(t, "alice")
+
+	// create group with a member
+	w := srv.do(t, "POST", "/Groups", `{"displayName":"`+org.String()+`/devs","members":[{"value":"`+bobby+`"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created groupResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, org.String()+"/devs", created.DisplayName)
+	assert.Equal(t, []string{bobby}, memberIDs(created))
+
+	// add member
+	w = srv.do(t, "PATCH", "/Groups/"+created.ID, `{"Operations":[{"op":"add","path":"members","value":[{"value":"`+alice+`"}]}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched groupResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.ElementsMatch(t, []string{bobby, alice}, memberIDs(patched))
+
+	// remove member using a filter
+	w = srv.do(t, "PATCH", "/Groups/"+created.ID, `{"Operations":[{"op":"remove","path":"members[value eq \"`+bobby+`\"]"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Equal(t, []string{alice}, memberIDs(patched))
+
+	// rename group and replace members
+	w = srv.do(t, "PUT", "/Groups/"+created.ID, `{"displayName":"`+org.String()+`/engineers","members":[{"value":"`+bobby+`"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var replaced groupResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replaced))
+	assert.Equal(t, org.String()+"/engineers", replaced.DisplayName)
+	assert.Equal(t, []string{bobby}, memberIDs(replaced))
+
+	// moving group to another organization is not permitted
+	w = srv.do(t, "PATCH", "/Groups/"+created.ID, `{"Operations":[{"op":"replace","path":"displayName","value":"acme/engineers"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// find group by display name
+	w = srv.do(t, "GET", `/Groups?filter=displayName+eq+"`+org.String()+`/engineers"`, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list listResponse[groupResource]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.TotalResults)
+
+	// list all groups
+	w = srv.do(t, "GET", "/Groups", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.TotalResults)
+
+	// delete group
+	w = srv.do(t, "DELETE", "/Groups/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    *filter
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"equality", `userName eq "bobby"`, &filter{attribute: "username", value: "bobby"}, false},
+		{"case insensitive operator", `displayName EQ "acme/devs"`, &filter{attribute: "displayname", value: "acme/devs"}, false},
+		{"unsupported operator", `userName co "bob"`, nil, true},
+		{"unquoted value", `userName eq bobby`, nil, true},
+		{"missing value", `userName eq`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.filter)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errInvalidFilter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func memberIDs(group groupResource) (ids []string) {
+	for _, m := range group.Members {
+		ids = append(ids, m.Value)
+	}
+	return ids
+}
+
+type testServer struct {
+	router *mux.Router
+}
+
+func newTestServer(t *testing.T) (*testServer, organization.Name) {
+	org := organization.NewTestName(t)
+	srv := &Server{
+		Logger:        logr.Discard(),
+		Users:         &fakeUserClient{},
+		Teams:         &fakeTeamClient{},
+		Organizations: &fakeOrganizationClient{org: org},
+		URLs:          &fakeURLClient{},
+	}
+	router := mux.NewRouter()
+	srv.AddHandlers(router)
+	return &testServer{router: router}, org
+}
+
+func (s *testServer) do(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, Prefix+path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+	return w
+}
+
+func (s *testServer) createUser(t *testing.T, username string) string {
+	t.Helper()
+
+	w := s.do(t, "POST", "/Users", `{"userName":"`+username+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created userResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	return created.ID
+}
+
+type fakeUserClient struct {
+	users []*user.User
+	// team memberships keyed by team ID
+	members map[resource.TfeID][]user.Username
+}
+
+func (f *fakeUserClient) Create(ctx context.Context, username string, opts ...user.NewUserOption) (*user.User, error) {
+	u, err := user.NewUser(username, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(f.users, func(existing *user.User) bool { return existing.Username == u.Username }) {
+		return nil, internal.ErrResourceAlreadyExists
+	}
+	f.users = append(f.users, u)
+	return u, nil
+}
+
+func (f *fakeUserClient) GetUser(ctx context.Context, spec user.UserSpec) (*user.User, error) {
+	for _, u := range f.users {
+		if spec.UserID != nil && u.ID == *spec.UserID {
+			return u, nil
+		}
+		if spec.Username != nil && u.Username == *spec.Username {
+			return u, nil
+		}
+	}
+	return nil, internal.ErrResourceNotFound
+}
+
+func (f *fakeUserClient) List(ctx context.Context) ([]*user.User, error) {
+	return f.users, nil
+}
+
+func (f *fakeUserClient) UpdateUser(ctx context.Context, userID resource.TfeID, opts user.UpdateUserOptions) (*user.User, error) {
+	u, err := f.GetUser(ctx, user.UserSpec{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Username != nil {
+		username, err := user.NewUsername(*opts.Username)
+		if err != nil {
+			return nil, err
+		}
+		u.Username = username
+	}
+	if opts.Disabled != nil {
+		u.Disabled = *opts.Disabled
+	}
+	return u, nil
+}
+
+func (f *fakeUserClient) Delete(ctx context.Context, username user.Username) error {
+	f.users = slices.DeleteFunc(f.users, func(u *user.User) bool { return u.Username == username })
+	return nil
+}
+
+func (f *fakeUserClient) ListTeamUsers(ctx context.Context, teamID resource.TfeID) (users []*user.User, err error) {
+	for _, username := range f.members[teamID] {
+		u, err := f.GetUser(ctx, user.UserSpec{Username: &username})
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func (f *fakeUserClient) AddTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error {
+	if f.members == nil {
+		f.members = make(map[resource.TfeID][]user.Username)
+	}
+	f.members[teamID] = append(f.members[teamID], usernames...)
+	return nil
+}
+
+func (f *fakeUserClient) RemoveTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error {
+	f.members[teamID] = slices.DeleteFunc(f.members[teamID], func(username user.Username) bool {
+		return slices.Contains(usernames, username)
+	})
+	return nil
+}
+
+type fakeTeamClient struct {
+	teams []*team.Team
+}
+
+func (f *fakeTeamClient) CreateTeam(ctx context.Context, org organization.Name, opts team.CreateTeamOptions) (*team.Team, error) {
+	t, err := team.NewTeam(org, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.teams = append(f.teams, t)
+	return t, nil
+}
+
+func (f *fakeTeamClient) GetTeam(ctx context.Context, org organization.Name, name string) (*team.Team, error) {
+	for _, t := range f.teams {
+		if t.Organization == org && t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, internal.ErrResourceNotFound
+}
+
+func (f *fakeTeamClient) GetTeamByID(ctx context.Context, teamID resource.TfeID) (*team.Team, error) {
+	for _, t := range f.teams {
+		if t.ID == teamID {
+			return t, nil
+		}
+	}
+	return nil, internal.ErrResourceNotFound
+}
+
+func (f *fakeTeamClient) ListTeams(ctx context.Context, org organization.Name) (teams []*team.Team, err error) {
+	for _, t := range f.teams {
+		if t.Organization == org {
+			teams = append(teams, t)
+		}
+	}
+	return teams, nil
+}
+
+func (f *fakeTeamClient) UpdateTeam(ctx context.Context, teamID resource.TfeID, opts team.UpdateTeamOptions) (*team.Team, error) {
+	t, err := f.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Name != nil {
+		t.Name = *opts.Name
+	}
+	return t, nil
+}
+
+func (f *fakeTeamClient) DeleteTeam(ctx context.Context, teamID resource.TfeID) error {
+	f.teams = slices.DeleteFunc(f.teams, func(t *team.Team) bool { return t.ID == teamID })
+	return nil
+}
+
+type fakeOrganizationClient struct {
+	org organization.Name
+}
+
+func (f *fakeOrganizationClient) ListOrganizations(ctx context.Context, opts organization.ListOptions) (*resource.Page[*organization.Organization], error) {
+	orgs := []*organization.Organization{{Name: f.org}}
+	return resource.NewPage(orgs, opts.PageOptions, nil), nil
+}
+
+type fakeURLClient struct{}
+
+func (f *fakeURLClient) URL(path string) string { return "https://otf.example.com" + path }
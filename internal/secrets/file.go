@@ -0,0 +1,35 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File retrieves secrets from files beneath a directory, such as a directory
+// into which a Kubernetes secret is mounted. Each organization's secrets are
+// in a subdirectory named after the organization.
+//
+// A reference is the path of the file relative to the organization's
+// subdirectory, e.g. prod/db-password. Trailing newlines are removed from the
+// value.
+type File struct {
+	Dir string
+}
+
+func (f *File) Resolve(ctx context.Context, scope Scope, reference string) (string, error) {
+	if !filepath.IsLocal(reference) {
+		return "", fmt.Errorf("invalid file reference: %q: must be a relative path within the organization's secrets directory", reference)
+	}
+	contents, err := os.ReadFile(filepath.Join(f.Dir, scope.Organization.String(), reference))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: file %s", ErrSecretNotFound, reference)
+	} else if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
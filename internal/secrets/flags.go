@@ -0,0 +1,16 @@
+package secrets
+
+import "github.com/spf13/pflag"
+
+// RegisterFlags adds flags for configuring secret providers to the given flag
+// set.
+func RegisterFlags(flags *pflag.FlagSet, cfg *Config) {
+	flags.StringVar(&cfg.Vault.Address, "vault-address", "", "Address of the HashiCorp Vault server from which variables can source secrets, e.g. https://vault.example.com:8200")
+	flags.StringVar(&cfg.Vault.Token, "vault-token", "", "Token with which to authenticate with the HashiCorp Vault server")
+	flags.StringVar(&cfg.Vault.Namespace, "vault-namespace", "", "HashiCorp Vault enterprise namespace")
+	flags.StringVar(&cfg.Vault.PathPrefix, "vault-path-prefix", "", "HashiCorp Vault path beneath which each organization's secrets reside, e.g. secret/data/otf")
+	flags.StringVar(&cfg.Dir, "secrets-dir", "", "Directory containing a subdirectory for each organization, containing files from which variables can source secrets")
+	flags.StringVar(&cfg.HTTP.URL, "http-secret-provider-url", "", "URL of an HTTP service from which variables can source secrets")
+	flags.StringVar(&cfg.HTTP.Token, "http-secret-provider-token", "", "Bearer token with which to authenticate with the HTTP secret provider")
+	flags.DurationVar(&cfg.CacheTTL, "secret-cache-ttl", DefaultCacheTTL, "Duration for which secrets sourced from external providers are cached. Specifying 0 disables caching.")
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HTTPConfig configures the http provider.
+type HTTPConfig struct {
+	// URL is the URL of the secret provider service.
+	URL string
+	// Token, if set, is sent as a bearer token with each request.
+	Token string
+}
+
+// HTTP retrieves secrets from a service implementing a simple HTTP protocol,
+// permitting integration with secret stores for which there is no built-in
+// provider.
+//
+// To retrieve a secret, a GET request is sent to the configured URL with the
+// reference in the reference query parameter, and the organization and
+// workspace on behalf of which the secret is retrieved in the organization and
+// workspace_id query parameters. The service is responsible for only returning
+// secrets belonging to the organization. It responds with 200
+// and a JSON object containing the secret in the value field, e.g.
+// {"value":"hunter2"}, or with 404 if the secret does not exist.
+type HTTP struct {
+	HTTPConfig
+
+	url    *url.URL
+	client *http.Client
+}
+
+// NewHTTP constructs an http provider.
+func NewHTTP(cfg HTTPConfig) (*HTTP, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing http secret provider url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid http secret provider url: %s", cfg.URL)
+	}
+	return &HTTP{
+		HTTPConfig: cfg,
+		url:        u,
+		client:     &http.Client{},
+	}, nil
+}
+
+func (h *HTTP) Resolve(ctx context.Context, scope Scope, reference string) (string, error) {
+	u := *h.url
+	q := u.Query()
+	q.Set("reference", reference)
+	q.Set("organization", scope.Organization.String())
+	q.Set("workspace_id", scope.WorkspaceID.String())
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("retrieving secret from http secret provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("reading http secret provider response: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, reference)
+	default:
+		return "", fmt.Errorf("retrieving secret from http secret provider: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var secret struct {
+		Value any `json:"value"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("decoding http secret provider response: %w", err)
+	}
+	return stringValue(secret.Value)
+}
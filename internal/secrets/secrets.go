@@ -0,0 +1,157 @@
+// Package secrets resolves references to secrets held in external secret
+// stores, such as HashiCorp Vault, permitting variables to source their values
+// from those stores rather than duplicating them in OTF.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+)
+
+const (
+	VaultProvider = "vault"
+	FileProvider  = "file"
+	HTTPProvider  = "http"
+
+	// DefaultCacheTTL is the default duration for which resolved secrets are
+	// cached.
+	DefaultCacheTTL = 5 * time.Minute
+)
+
+var (
+	// ErrSecretNotFound is returned when a secret does not exist in the
+	// secret store.
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrUnknownProvider is returned when a secret provider has not been
+	// configured.
+	ErrUnknownProvider = errors.New("unknown secret provider")
+)
+
+// Provider retrieves secrets from a secret store.
+type Provider interface {
+	// Resolve retrieves the value of the secret identified by the reference.
+	// The format of the reference is specific to the provider. A provider
+	// must only retrieve secrets belonging to the scope's organization.
+	Resolve(ctx context.Context, scope Scope, reference string) (string, error)
+}
+
+// Scope identifies the organization and workspace on behalf of which a secret
+// is retrieved. Secrets are partitioned by organization, so that one
+// organization cannot retrieve another organization's secrets.
+type Scope struct {
+	Organization organization.Name
+	WorkspaceID  resource.TfeID
+}
+
+// Config configures secret providers. A provider is only enabled if it is
+// configured.
+type Config struct {
+	Vault    VaultConfig
+	Dir      string
+	HTTP     HTTPConfig
+	CacheTTL time.Duration
+}
+
+// Resolver resolves references to secrets using the configured providers,
+// caching resolved secrets.
+type Resolver struct {
+	providers map[string]Provider
+	cache     *internal.SafeMap[cacheKey, cacheEntry]
+	ttl       time.Duration
+
+	// overridable for testing purposes
+	now func() time.Time
+}
+
+type (
+	cacheKey struct {
+		provider  string
+		scope     Scope
+		reference string
+	}
+	cacheEntry struct {
+		value   string
+		expires time.Time
+	}
+)
+
+// New constructs a resolver with the providers enabled in the config.
+func New(cfg Config) (*Resolver, error) {
+	providers := make(map[string]Provider)
+	if cfg.Vault.Address != "" {
+		vault, err := NewVault(cfg.Vault)
+		if err != nil {
+			return nil, err
+		}
+		providers[VaultProvider] = vault
+	}
+	if cfg.Dir != "" {
+		providers[FileProvider] = &File{Dir: cfg.Dir}
+	}
+	if cfg.HTTP.URL != "" {
+		http, err := NewHTTP(cfg.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		providers[HTTPProvider] = http
+	}
+	return NewResolver(providers, cfg.CacheTTL), nil
+}
+
+// NewResolver constructs a resolver with the given providers, keyed by name.
+// Resolved secrets are cached for the given duration; zero disables caching.
+func NewResolver(providers map[string]Provider, ttl time.Duration) *Resolver {
+	return &Resolver{
+		providers: providers,
+		cache:     internal.NewSafeMap[cacheKey, cacheEntry](),
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// Providers lists the names of the configured providers.
+func (r *Resolver) Providers() []string {
+	if r == nil {
+		return nil
+	}
+	var names []string
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// HasProvider returns true if the named provider is configured.
+func (r *Resolver) HasProvider(name string) bool {
+	return slices.Contains(r.Providers(), name)
+}
+
+// Resolve retrieves the value of a secret from the named provider on behalf of
+// the given scope.
+func (r *Resolver) Resolve(ctx context.Context, provider string, scope Scope, reference string) (string, error) {
+	if !r.HasProvider(provider) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+	}
+	// The scope is part of the key so that a secret resolved on behalf of one
+	// organization or workspace is never returned to another.
+	key := cacheKey{provider: provider, scope: scope, reference: reference}
+	if entry, ok := r.cache.Get(key); ok && r.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+	value, err := r.providers[provider].Resolve(ctx, scope, reference)
+	if err != nil {
+		return "", err
+	}
+	if r.ttl > 0 {
+		r.cache.Set(key, cacheEntry{value: value, expires: r.now().Add(r.ttl)})
+	}
+	return value, nil
+}
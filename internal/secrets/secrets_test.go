@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVault(t *testing.T) {
+	scope := Scope{Organization: organization.NewTestName(t)}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/otf/" + scope.Organization.String() + "/db":
+			// kv version 2
+			w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432},"metadata":{"version":1}}}`))
+		case "/v1/kv/otf/" + scope.Organization.String() + "/db":
+			// kv version 1
+			w.Write([]byte(`{"data":{"password":"hunter3"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	vault, err := NewVault(VaultConfig{Address: srv.URL, Token: "root", PathPrefix: "secret/data/otf"})
+	require.NoError(t, err)
+	kv1, err := NewVault(VaultConfig{Address: srv.URL, Token: "root", PathPrefix: "kv/otf"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		vault     *Vault
+		reference string
+		want      string
+		wantErr   string
+	}{
+		{"kv v2", vault, "db#password", "hunter2", ""},
+		{"kv v2 non-string value", vault, "db#port", "5432", ""},
+		{"kv v1", kv1, "db#password", "hunter3", ""},
+		{"missing key", vault, "db#username", "", "secret not found: key username not found in vault path db"},
+		{"missing path", vault, "missing#password", "", "secret not found: vault path missing"},
+		{"invalid reference", vault, "db", "", "must take the form <path>#<key>"},
+		{"path outside organization", vault, "../other/db#password", "", "path must be relative to the organization's path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.vault.Resolve(context.Background(), scope, tt.reference)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("permission denied", func(t *testing.T) {
+		vault, err := NewVault(VaultConfig{Address: srv.URL, Token: "invalid", PathPrefix: "secret/data/otf"})
+		require.NoError(t, err)
+
+		_, err = vault.Resolve(context.Background(), scope, "db#password")
+		assert.ErrorContains(t, err, "403 Forbidden: permission denied")
+	})
+
+	t.Run("another organization", func(t *testing.T) {
+		other := Scope{Organization: organization.NewTestName(t)}
+
+		_, err := vault.Resolve(context.Background(), other, "db#password")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("missing path prefix", func(t *testing.T) {
+		_, err := NewVault(VaultConfig{Address: srv.URL, Token: "root"})
+		assert.ErrorContains(t, err, "vault path prefix is required")
+	})
+}
+
+func TestFile(t *testing.T) {
+	scope := Scope{Organization: organization.NewTestName(t)}
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, scope.Organization.String(), "prod"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, scope.Organization.String(), "prod", "password"), []byte("hunter2\n"), 0o600))
+
+	file := &File{Dir: dir}
+
+	got, err := file.Resolve(context.Background(), scope, "prod/password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got)
+
+	_, err = file.Resolve(context.Background(), scope, "prod/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = file.Resolve(context.Background(), scope, "../etc/passwd")
+	assert.ErrorContains(t, err, "invalid file reference")
+
+	// another organization cannot retrieve the secret
+	other := Scope{Organization: organization.NewTestName(t)}
+	_, err = file.Resolve(context.Background(), other, "prod/password")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestHTTP(t *testing.T) {
+	scope := Scope{
+		Organization: organization.NewTestName(t),
+		WorkspaceID:  resource.NewTfeID(resource.WorkspaceKind),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("organization") != scope.Organization.String() || q.Get("workspace_id") != scope.WorkspaceID.String() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch q.Get("reference") {
+		case "prod/db":
+			w.Write([]byte(`{"value":"hunter2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := NewHTTP(HTTPConfig{URL: srv.URL + "/secrets", Token: "token"})
+	require.NoError(t, err)
+
+	got, err := provider.Resolve(context.Background(), scope, "prod/db")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got)
+
+	_, err = provider.Resolve(context.Background(), scope, "prod/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+type fakeProvider struct {
+	calls int
+}
+
+func (f *fakeProvider) Resolve(_ context.Context, scope Scope, _ string) (string, error) {
+	f.calls++
+	return "secret-of-" + scope.Organization.String(), nil
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{}
+	scope := Scope{Organization: organization.NewTestName(t)}
+	resolver := NewResolver(map[string]Provider{"fake": provider}, time.Minute)
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+
+	assert.Equal(t, []string{"fake"}, resolver.Providers())
+
+	got, err := resolver.Resolve(ctx, "fake", scope, "ref")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-of-"+scope.Organization.String(), got)
+
+	// cached
+	_, err = resolver.Resolve(ctx, "fake", scope, "ref")
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.calls)
+
+	// not cached for another organization
+	other := Scope{Organization: organization.NewTestName(t)}
+	got, err = resolver.Resolve(ctx, "fake", other, "ref")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-of-"+other.Organization.String(), got)
+	assert.Equal(t, 2, provider.calls)
+
+	// cache expired
+	now = now.Add(2 * time.Minute)
+	_, err = resolver.Resolve(ctx, "fake", scope, "ref")
+	require.NoError(t, err)
+	assert.Equal(t, 3, provider.calls)
+
+	_, err = resolver.Resolve(ctx, "vault", scope, "ref")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
@@ -0,0 +1,146 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VaultConfig configures the vault provider.
+type VaultConfig struct {
+	// Address is the URL of the vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token authenticates with the vault server.
+	Token string
+	// Namespace is the vault enterprise namespace, if any.
+	Namespace string
+	// PathPrefix is the API path, excluding the /v1/ prefix, beneath which
+	// each organization's secrets reside, in a path named after the
+	// organization, e.g. secret/data/otf.
+	PathPrefix string
+}
+
+// Vault retrieves secrets from the key/value secrets engine of a HashiCorp
+// Vault server. Both versions 1 and 2 of the engine are supported.
+//
+// A reference takes the form <path>#<key>, where path is the path of the
+// secret relative to the organization's path, and key is the key within the
+// secret, e.g. prod/db#password retrieves the password key from the secret at
+// secret/data/otf/<organization>/prod/db, given a path prefix of
+// secret/data/otf.
+type Vault struct {
+	VaultConfig
+
+	address *url.URL
+	client  *http.Client
+}
+
+// NewVault constructs a vault provider.
+func NewVault(cfg VaultConfig) (*Vault, error) {
+	address, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing vault address: %w", err)
+	}
+	if address.Scheme == "" || address.Host == "" {
+		return nil, fmt.Errorf("invalid vault address: %s", cfg.Address)
+	}
+	if cfg.PathPrefix == "" {
+		return nil, errors.New("vault path prefix is required")
+	}
+	return &Vault{
+		VaultConfig: cfg,
+		address:     address,
+		client:      &http.Client{},
+	}, nil
+}
+
+func (v *Vault) Resolve(ctx context.Context, scope Scope, reference string) (string, error) {
+	path, key, ok := strings.Cut(reference, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference: %q: must take the form <path>#<key>", reference)
+	}
+	// Prevent the path from escaping the organization's path
+	if !fs.ValidPath(path) {
+		return "", fmt.Errorf("invalid vault reference: %q: path must be relative to the organization's path", reference)
+	}
+	u := v.address.JoinPath("v1", v.PathPrefix, scope.Organization.String(), path)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("retrieving secret from vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading vault response: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%w: vault path %s", ErrSecretNotFound, path)
+	default:
+		return "", fmt.Errorf("retrieving secret from vault: %s: %s", resp.Status, vaultErrors(body))
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("decoding vault response: %w", err)
+	}
+	data := secret.Data
+	// Version 2 of the key/value engine nests the secret's data beneath a
+	// data key, alongside its metadata.
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %s not found in vault path %s", ErrSecretNotFound, key, path)
+	}
+	return stringValue(value)
+}
+
+// vaultErrors extracts the error messages from a vault error response.
+func vaultErrors(body []byte) string {
+	var resp struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Errors) == 0 {
+		return strings.TrimSpace(string(body))
+	}
+	return strings.Join(resp.Errors, "; ")
+}
+
+// stringValue converts a secret value to a string. Non-string values are
+// JSON-encoded.
+func stringValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case nil:
+		return "", errors.New("secret value is null")
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
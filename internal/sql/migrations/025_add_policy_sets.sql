@@ -0,0 +1,48 @@
+-- Add support for policy-as-code checks.
+CREATE TABLE policy_sets (
+    policy_set_id     TEXT PRIMARY KEY,
+    created_at        TIMESTAMPTZ NOT NULL,
+    updated_at        TIMESTAMPTZ NOT NULL,
+    name              TEXT NOT NULL,
+    description       TEXT NOT NULL,
+    enforcement_level TEXT NOT NULL,
+    organization_name TEXT NOT NULL REFERENCES organizations(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    vcs_provider_id   TEXT REFERENCES vcs_providers(vcs_provider_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    repo_path         TEXT,
+    branch            TEXT,
+    path              TEXT,
+    UNIQUE (organization_name, name)
+);
+
+CREATE TABLE policies (
+    policy_id     TEXT PRIMARY KEY,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    name          TEXT NOT NULL,
+    source        TEXT NOT NULL,
+    policy_set_id TEXT NOT NULL REFERENCES policy_sets(policy_set_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    UNIQUE (policy_set_id, name)
+);
+
+CREATE TABLE policy_checks (
+    policy_check_id TEXT PRIMARY KEY,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    status          TEXT NOT NULL,
+    error_message   TEXT,
+    results         JSONB NOT NULL,
+    run_id          TEXT NOT NULL UNIQUE REFERENCES runs(run_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+INSERT INTO run_statuses (status) VALUES
+    ('policy_checking'),
+    ('policy_checked'),
+    ('policy_override');
+
+---- create above / drop below ----
+
+DELETE FROM run_statuses WHERE status IN ('policy_checking', 'policy_checked', 'policy_override');
+
+DROP TABLE policy_checks;
+DROP TABLE policies;
+DROP TABLE policy_sets;
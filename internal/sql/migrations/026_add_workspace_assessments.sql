@@ -0,0 +1,19 @@
+-- Add columns to workspaces table for health assessments: whether assessments
+-- are enabled, and the outcome of the most recent assessment.
+
+ALTER TABLE workspaces
+    ADD COLUMN assessments_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN assessment_status TEXT,
+    ADD COLUMN assessment_run_id TEXT,
+    ADD COLUMN assessed_at TIMESTAMPTZ;
+
+ALTER TABLE workspaces
+	ADD CONSTRAINT assessment_run_id_fk FOREIGN KEY (assessment_run_id) REFERENCES runs(run_id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+---- create above / drop below ----
+
+ALTER TABLE workspaces
+    DROP COLUMN assessments_enabled,
+    DROP COLUMN assessment_status,
+    DROP COLUMN assessment_run_id,
+    DROP COLUMN assessed_at;
@@ -0,0 +1,37 @@
+-- Add projects, which group workspaces within an organization.
+CREATE TABLE projects (
+    project_id        TEXT PRIMARY KEY,
+    created_at        TIMESTAMPTZ NOT NULL,
+    updated_at        TIMESTAMPTZ NOT NULL,
+    name              TEXT NOT NULL,
+    description       TEXT NOT NULL,
+    organization_name TEXT NOT NULL REFERENCES organizations(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    UNIQUE (organization_name, name)
+);
+
+-- Project permissions grant a team a role on every workspace in a project.
+CREATE TABLE project_permissions (
+    project_id TEXT NOT NULL REFERENCES projects(project_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    team_id    TEXT NOT NULL REFERENCES teams(team_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    role       TEXT NOT NULL REFERENCES workspace_roles(role) ON UPDATE CASCADE ON DELETE CASCADE,
+    UNIQUE (project_id, team_id)
+);
+
+-- A project cannot be deleted whilst it still has workspaces.
+ALTER TABLE workspaces
+    ADD COLUMN project_id TEXT,
+    ADD CONSTRAINT project_id_fk FOREIGN KEY (project_id) REFERENCES projects(project_id) ON UPDATE CASCADE;
+
+ALTER TABLE teams
+    ADD COLUMN permission_manage_projects BOOLEAN NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE teams
+    DROP COLUMN permission_manage_projects;
+
+ALTER TABLE workspaces
+    DROP COLUMN project_id;
+
+DROP TABLE project_permissions;
+DROP TABLE projects;
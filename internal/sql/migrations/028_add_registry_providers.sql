@@ -0,0 +1,37 @@
+-- Add private provider registry.
+CREATE TABLE registry_providers (
+    provider_id       TEXT PRIMARY KEY,
+    created_at        TIMESTAMPTZ NOT NULL,
+    name              TEXT NOT NULL,
+    organization_name TEXT NOT NULL REFERENCES organizations(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    UNIQUE (organization_name, name)
+);
+
+CREATE TABLE registry_provider_versions (
+    provider_version_id TEXT PRIMARY KEY,
+    created_at          TIMESTAMPTZ NOT NULL,
+    version             TEXT NOT NULL,
+    protocols           TEXT[] NOT NULL,
+    key_id              TEXT NOT NULL,
+    ascii_armor         TEXT NOT NULL,
+    shasums             BYTEA,
+    shasums_signature   BYTEA,
+    provider_id         TEXT NOT NULL REFERENCES registry_providers(provider_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    UNIQUE (provider_id, version)
+);
+
+CREATE TABLE registry_provider_platforms (
+    provider_version_id TEXT NOT NULL REFERENCES registry_provider_versions(provider_version_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    os                  TEXT NOT NULL,
+    arch                TEXT NOT NULL,
+    filename            TEXT NOT NULL,
+    shasum              TEXT NOT NULL,
+    zip                 BYTEA NOT NULL,
+    PRIMARY KEY (provider_version_id, os, arch)
+);
+
+---- create above / drop below ----
+
+DROP TABLE registry_provider_platforms;
+DROP TABLE registry_provider_versions;
+DROP TABLE registry_providers;
@@ -0,0 +1,142 @@
+-- Move blobs - state files, configuration tarballs, plan files, logs and module
+-- tarballs - out of their respective tables and into a dedicated blobs table,
+-- keyed by a path. The blobs table is used by the database blob store backend;
+-- blobs can subsequently be moved to an alternative backend with `otfd
+-- migrate-blobs`.
+CREATE TABLE blobs (
+    key text PRIMARY KEY,
+    data bytea NOT NULL
+);
+
+INSERT INTO blobs (key, data)
+SELECT 'state/' || state_version_id, state
+FROM state_versions
+WHERE state IS NOT NULL;
+ALTER TABLE state_versions DROP COLUMN state;
+
+INSERT INTO blobs (key, data)
+SELECT 'configs/' || configuration_version_id, config
+FROM configuration_versions
+WHERE config IS NOT NULL;
+ALTER TABLE configuration_versions DROP COLUMN config;
+
+INSERT INTO blobs (key, data)
+SELECT 'runs/' || run_id || '/plan.bin', plan_bin
+FROM plans
+WHERE plan_bin IS NOT NULL;
+INSERT INTO blobs (key, data)
+SELECT 'runs/' || run_id || '/plan.json', plan_json
+FROM plans
+WHERE plan_json IS NOT NULL;
+ALTER TABLE plans DROP COLUMN plan_bin, DROP COLUMN plan_json;
+
+-- Complete logs are moved to blobs; incomplete logs remain in the chunks
+-- table until the last chunk is received.
+INSERT INTO blobs (key, data)
+SELECT 'runs/' || run_id || '/logs/' || phase, logs
+FROM logs;
+DROP TABLE logs;
+
+INSERT INTO blobs (key, data)
+SELECT 'modules/' || module_version_id || '.tar.gz', tarball
+FROM module_tarballs;
+DROP TABLE module_tarballs;
+
+-- Blobs no longer cascade upon deletion of their parent resource. Instead,
+-- triggers record the keys of the blobs of deleted resources, which are
+-- subsequently deleted from the blob store by the blob reaper.
+CREATE TABLE blob_deletions (
+    key text NOT NULL
+);
+
+CREATE FUNCTION delete_state_version_blobs() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    INSERT INTO blob_deletions (key) VALUES ('state/' || OLD.state_version_id);
+    RETURN NULL;
+END;
+$$;
+CREATE TRIGGER delete_blobs AFTER DELETE ON state_versions FOR EACH ROW EXECUTE FUNCTION delete_state_version_blobs();
+
+CREATE FUNCTION delete_configuration_version_blobs() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    INSERT INTO blob_deletions (key) VALUES ('configs/' || OLD.configuration_version_id);
+    RETURN NULL;
+END;
+$$;
+CREATE TRIGGER delete_blobs AFTER DELETE ON configuration_versions FOR EACH ROW EXECUTE FUNCTION delete_configuration_version_blobs();
+
+CREATE FUNCTION delete_run_blobs() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    INSERT INTO blob_deletions (key) VALUES
+        ('runs/' || OLD.run_id || '/plan.bin'),
+        ('runs/' || OLD.run_id || '/plan.json'),
+        ('runs/' || OLD.run_id || '/logs/plan'),
+        ('runs/' || OLD.run_id || '/logs/apply');
+    RETURN NULL;
+END;
+$$;
+CREATE TRIGGER delete_blobs AFTER DELETE ON runs FOR EACH ROW EXECUTE FUNCTION delete_run_blobs();
+
+CREATE FUNCTION delete_module_version_blobs() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    INSERT INTO blob_deletions (key) VALUES ('modules/' || OLD.module_version_id || '.tar.gz');
+    RETURN NULL;
+END;
+$$;
+CREATE TRIGGER delete_blobs AFTER DELETE ON module_versions FOR EACH ROW EXECUTE FUNCTION delete_module_version_blobs();
+---- create above / drop below ----
+-- NOTE: only blobs stored in the database are restored. Blobs that have been
+-- migrated to an alternative backend must first be moved back to the database.
+DROP TRIGGER delete_blobs ON module_versions;
+DROP FUNCTION delete_module_version_blobs;
+DROP TRIGGER delete_blobs ON runs;
+DROP FUNCTION delete_run_blobs;
+DROP TRIGGER delete_blobs ON configuration_versions;
+DROP FUNCTION delete_configuration_version_blobs;
+DROP TRIGGER delete_blobs ON state_versions;
+DROP FUNCTION delete_state_version_blobs;
+DROP TABLE blob_deletions;
+
+CREATE TABLE module_tarballs (
+    tarball bytea NOT NULL,
+    module_version_id text NOT NULL UNIQUE,
+    FOREIGN KEY (module_version_id) REFERENCES module_versions(module_version_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+INSERT INTO module_tarballs (tarball, module_version_id)
+SELECT b.data, mv.module_version_id
+FROM module_versions mv
+JOIN blobs b ON b.key = 'modules/' || mv.module_version_id || '.tar.gz';
+
+CREATE TABLE logs (
+    run_id text NOT NULL,
+    phase text NOT NULL,
+    logs bytea NOT NULL,
+	CONSTRAINT run_id_phase PRIMARY KEY(run_id, phase),
+	FOREIGN KEY (run_id) REFERENCES runs(run_id) ON UPDATE CASCADE ON DELETE CASCADE,
+	FOREIGN KEY (phase) REFERENCES phases(phase) ON UPDATE CASCADE ON DELETE CASCADE
+);
+INSERT INTO logs (run_id, phase, logs)
+SELECT r.run_id, p.phase, b.data
+FROM runs r
+CROSS JOIN (VALUES ('plan'), ('apply')) AS p (phase)
+JOIN blobs b ON b.key = 'runs/' || r.run_id || '/logs/' || p.phase;
+
+ALTER TABLE plans ADD COLUMN plan_bin bytea, ADD COLUMN plan_json bytea;
+UPDATE plans p SET plan_bin = b.data FROM blobs b WHERE b.key = 'runs/' || p.run_id || '/plan.bin';
+UPDATE plans p SET plan_json = b.data FROM blobs b WHERE b.key = 'runs/' || p.run_id || '/plan.json';
+
+ALTER TABLE configuration_versions ADD COLUMN config bytea;
+UPDATE configuration_versions cv SET config = b.data FROM blobs b WHERE b.key = 'configs/' || cv.configuration_version_id;
+
+ALTER TABLE state_versions ADD COLUMN state bytea;
+UPDATE state_versions sv SET state = b.data FROM blobs b WHERE b.key = 'state/' || sv.state_version_id;
+
+DROP TABLE blobs;
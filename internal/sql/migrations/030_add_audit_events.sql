@@ -0,0 +1,19 @@
+-- Add audit events, recording changes made to resources within an
+-- organization.
+CREATE TABLE audit_events (
+    audit_event_id    TEXT PRIMARY KEY,
+    timestamp         TIMESTAMPTZ NOT NULL,
+    organization_name TEXT NOT NULL REFERENCES organizations(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    subject           TEXT NOT NULL,
+    subject_id        TEXT,
+    action            TEXT NOT NULL,
+    resource_id       TEXT NOT NULL,
+    ip_address        TEXT,
+    changes           JSONB
+);
+
+CREATE INDEX audit_events_organization_name_timestamp_idx ON audit_events (organization_name, timestamp DESC);
+
+---- create above / drop below ----
+
+DROP TABLE audit_events;
@@ -0,0 +1,9 @@
+ALTER TABLE run_triggers
+    ADD COLUMN outputs text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN branches text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN require_resource_changes boolean NOT NULL DEFAULT false;
+---- create above / drop below ----
+ALTER TABLE run_triggers
+    DROP COLUMN outputs,
+    DROP COLUMN branches,
+    DROP COLUMN require_resource_changes;
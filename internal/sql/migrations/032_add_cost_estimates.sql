@@ -0,0 +1,19 @@
+CREATE TABLE cost_estimates (
+    cost_estimate_id      TEXT PRIMARY KEY,
+    created_at            TIMESTAMPTZ NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL,
+    status                TEXT NOT NULL,
+    error_message         TEXT,
+    prior_monthly_cost    NUMERIC NOT NULL,
+    proposed_monthly_cost NUMERIC NOT NULL,
+    resources             JSONB NOT NULL,
+    run_id                TEXT NOT NULL UNIQUE REFERENCES runs(run_id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+INSERT INTO run_statuses (status) VALUES ('cost_estimating');
+
+---- create above / drop below ----
+
+DELETE FROM run_statuses WHERE status = 'cost_estimating';
+
+DROP TABLE cost_estimates;
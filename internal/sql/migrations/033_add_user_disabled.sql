@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN disabled BOOLEAN DEFAULT false NOT NULL;
+---- create above / drop below ----
+ALTER TABLE users DROP COLUMN disabled;
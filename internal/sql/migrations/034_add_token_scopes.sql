@@ -0,0 +1,9 @@
+-- Add scopes to user tokens, restricting the access they grant, and record
+-- when they were last used.
+ALTER TABLE tokens
+    ADD COLUMN scope JSONB DEFAULT '{}' NOT NULL,
+    ADD COLUMN last_used_at TIMESTAMPTZ;
+---- create above / drop below ----
+ALTER TABLE tokens
+    DROP COLUMN scope,
+    DROP COLUMN last_used_at;
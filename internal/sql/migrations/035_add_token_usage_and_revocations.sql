@@ -0,0 +1,29 @@
+-- Record when and from where each kind of token was last used, and add a list
+-- of revoked tokens.
+ALTER TABLE tokens ADD COLUMN last_used_ip TEXT;
+ALTER TABLE team_tokens
+    ADD COLUMN last_used_at TIMESTAMPTZ,
+    ADD COLUMN last_used_ip TEXT;
+ALTER TABLE organization_tokens
+    ADD COLUMN last_used_at TIMESTAMPTZ,
+    ADD COLUMN last_used_ip TEXT;
+ALTER TABLE agent_tokens
+    ADD COLUMN last_used_at TIMESTAMPTZ,
+    ADD COLUMN last_used_ip TEXT;
+
+CREATE TABLE revoked_tokens (
+    token_id   TEXT PRIMARY KEY,
+    revoked_at TIMESTAMPTZ NOT NULL
+);
+---- create above / drop below ----
+DROP TABLE revoked_tokens;
+ALTER TABLE agent_tokens
+    DROP COLUMN last_used_at,
+    DROP COLUMN last_used_ip;
+ALTER TABLE organization_tokens
+    DROP COLUMN last_used_at,
+    DROP COLUMN last_used_ip;
+ALTER TABLE team_tokens
+    DROP COLUMN last_used_at,
+    DROP COLUMN last_used_ip;
+ALTER TABLE tokens DROP COLUMN last_used_ip;
@@ -0,0 +1,16 @@
+-- Add a priority to workspaces, record when jobs are created so that jobs of
+-- equal priority are allocated first come first served, and add a table
+-- recording the position of each unallocated job in the queue along with the
+-- reason it is waiting.
+ALTER TABLE workspaces ADD COLUMN priority INT NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE TABLE job_queue (
+    job_id   TEXT PRIMARY KEY REFERENCES jobs(job_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    position INT NOT NULL,
+    reason   TEXT NOT NULL
+);
+---- create above / drop below ----
+DROP TABLE job_queue;
+ALTER TABLE jobs DROP COLUMN created_at;
+ALTER TABLE workspaces DROP COLUMN priority;
@@ -0,0 +1,15 @@
+-- Add a mirror of engine archives hosted by otfd, and an optional constraint
+-- restricting the engine versions an organization's workspaces may use.
+CREATE TABLE engine_mirror_versions (
+    engine     TEXT NOT NULL,
+    version    TEXT NOT NULL,
+    os         TEXT NOT NULL,
+    arch       TEXT NOT NULL,
+    shasum     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (engine, version, os, arch)
+);
+ALTER TABLE organizations ADD COLUMN engine_version_constraint TEXT;
+---- create above / drop below ----
+ALTER TABLE organizations DROP COLUMN engine_version_constraint;
+DROP TABLE engine_mirror_versions;
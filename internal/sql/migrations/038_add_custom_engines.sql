@@ -0,0 +1,20 @@
+-- Permit the site admin to register custom engines alongside the built-in
+-- engines, terraform and tofu. The url_template column is only set for custom
+-- engines.
+ALTER TABLE engines
+    ADD COLUMN versions TEXT[],
+    ADD COLUMN url_template TEXT,
+    ADD COLUMN shasums_url_template TEXT,
+    ADD COLUMN created_at TIMESTAMPTZ,
+    ADD COLUMN updated_at TIMESTAMPTZ;
+---- create above / drop below ----
+UPDATE workspaces SET engine = 'terraform' WHERE engine IN (SELECT name FROM engines WHERE url_template IS NOT NULL);
+UPDATE runs SET engine = 'terraform' WHERE engine IN (SELECT name FROM engines WHERE url_template IS NOT NULL);
+DELETE FROM latest_engine_version WHERE engine IN (SELECT name FROM engines WHERE url_template IS NOT NULL);
+DELETE FROM engines WHERE url_template IS NOT NULL;
+ALTER TABLE engines
+    DROP COLUMN versions,
+    DROP COLUMN url_template,
+    DROP COLUMN shasums_url_template,
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at;
@@ -0,0 +1,69 @@
+-- Add support for run tasks, which invoke external services at stages of a
+-- run.
+CREATE TABLE run_tasks (
+    run_task_id       TEXT PRIMARY KEY,
+    created_at        TIMESTAMPTZ NOT NULL,
+    updated_at        TIMESTAMPTZ NOT NULL,
+    organization_name TEXT NOT NULL REFERENCES organizations(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    name              TEXT NOT NULL,
+    description       TEXT NOT NULL,
+    url               TEXT NOT NULL,
+    hmac_key          TEXT,
+    enabled           BOOLEAN NOT NULL,
+    UNIQUE (organization_name, name)
+);
+
+CREATE TABLE workspace_run_tasks (
+    workspace_run_task_id TEXT PRIMARY KEY,
+    created_at            TIMESTAMPTZ NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL,
+    workspace_id          TEXT NOT NULL REFERENCES workspaces(workspace_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    run_task_id           TEXT NOT NULL REFERENCES run_tasks(run_task_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    stage                 TEXT NOT NULL,
+    enforcement_level     TEXT NOT NULL,
+    UNIQUE (workspace_id, run_task_id)
+);
+
+CREATE TABLE task_stages (
+    task_stage_id TEXT PRIMARY KEY,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    run_id        TEXT NOT NULL REFERENCES runs(run_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    stage         TEXT NOT NULL,
+    status        TEXT NOT NULL,
+    UNIQUE (run_id, stage)
+);
+
+-- Results record the name and URL of their task at the time of invocation and
+-- deliberately do not reference run_tasks, so that deleting a task does not
+-- delete the history of its results.
+CREATE TABLE task_results (
+    task_result_id    TEXT PRIMARY KEY,
+    created_at        TIMESTAMPTZ NOT NULL,
+    updated_at        TIMESTAMPTZ NOT NULL,
+    task_stage_id     TEXT NOT NULL REFERENCES task_stages(task_stage_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    run_task_id       TEXT NOT NULL,
+    task_name         TEXT NOT NULL,
+    task_url          TEXT NOT NULL,
+    enforcement_level TEXT NOT NULL,
+    status            TEXT NOT NULL,
+    message           TEXT NOT NULL,
+    url               TEXT
+);
+
+INSERT INTO run_statuses (status) VALUES
+    ('pre_plan_running'),
+    ('pre_plan_completed'),
+    ('post_plan_running'),
+    ('post_plan_completed'),
+    ('pre_apply_running'),
+    ('pre_apply_completed');
+
+---- create above / drop below ----
+
+DELETE FROM run_statuses WHERE status IN ('pre_plan_running', 'pre_plan_completed', 'post_plan_running', 'post_plan_completed', 'pre_apply_running', 'pre_apply_completed');
+
+DROP TABLE task_results;
+DROP TABLE task_stages;
+DROP TABLE workspace_run_tasks;
+DROP TABLE run_tasks;
@@ -0,0 +1,5 @@
+-- Record whether a run's engine emits machine-readable output, which is
+-- determined by its workspace's setting at the time the run is created.
+ALTER TABLE runs ADD COLUMN structured_output BOOLEAN DEFAULT false NOT NULL;
+---- create above / drop below ----
+ALTER TABLE runs DROP COLUMN structured_output;
@@ -0,0 +1,25 @@
+-- Index the resource instances in each state version, permitting resources to
+-- be searched and their history to be traced across state versions.
+CREATE TABLE state_resource_instances (
+    state_version_id TEXT NOT NULL REFERENCES state_versions(state_version_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    address          TEXT NOT NULL,
+    module           TEXT NOT NULL,
+    mode             TEXT NOT NULL,
+    type             TEXT NOT NULL,
+    name             TEXT NOT NULL,
+    provider         TEXT NOT NULL,
+    attributes       JSONB NOT NULL,
+    attribute_values TEXT[] NOT NULL,
+    PRIMARY KEY (state_version_id, address)
+);
+
+CREATE INDEX state_resource_instances_address_idx ON state_resource_instances (address);
+
+-- The run that created the state version, if any.
+ALTER TABLE state_versions ADD COLUMN run_id TEXT REFERENCES runs(run_id) ON UPDATE CASCADE ON DELETE SET NULL;
+-- True once the resource instances in the state version have been indexed.
+ALTER TABLE state_versions ADD COLUMN resources_processed BOOLEAN DEFAULT false NOT NULL;
+---- create above / drop below ----
+ALTER TABLE state_versions DROP COLUMN resources_processed;
+ALTER TABLE state_versions DROP COLUMN run_id;
+DROP TABLE state_resource_instances;
@@ -0,0 +1,4 @@
+-- The external source of a variable's value, e.g. a secret in vault.
+ALTER TABLE variables ADD COLUMN source JSONB;
+---- create above / drop below ----
+ALTER TABLE variables DROP COLUMN source;
@@ -0,0 +1,16 @@
+-- Whether to post a comment summarising the plan of speculative runs to the
+-- pull request that triggered them.
+ALTER TABLE workspaces ADD COLUMN pull_request_comments BOOLEAN DEFAULT false NOT NULL;
+
+-- The comment posted to a pull request on behalf of a workspace, which is
+-- updated in place by subsequent runs triggered by the same pull request.
+CREATE TABLE pull_request_comments (
+    workspace_id        TEXT NOT NULL REFERENCES workspaces(workspace_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    repo_path           TEXT NOT NULL,
+    pull_request_number INT NOT NULL,
+    comment_id          TEXT NOT NULL,
+    PRIMARY KEY (workspace_id, repo_path, pull_request_number)
+);
+---- create above / drop below ----
+DROP TABLE pull_request_comments;
+ALTER TABLE workspaces DROP COLUMN pull_request_comments;
@@ -0,0 +1,12 @@
+-- Whether plans and applies can be triggered by commenting on pull requests.
+ALTER TABLE workspaces ADD COLUMN pull_request_commands BOOLEAN DEFAULT false NOT NULL;
+
+-- The pull request holding the workspace lock, following a plan triggered by
+-- a pull request comment. The lock is released when the pull request is merged
+-- or closed.
+ALTER TABLE workspaces ADD COLUMN lock_pull_request_repo TEXT;
+ALTER TABLE workspaces ADD COLUMN lock_pull_request_number INT;
+---- create above / drop below ----
+ALTER TABLE workspaces DROP COLUMN lock_pull_request_number;
+ALTER TABLE workspaces DROP COLUMN lock_pull_request_repo;
+ALTER TABLE workspaces DROP COLUMN pull_request_commands;
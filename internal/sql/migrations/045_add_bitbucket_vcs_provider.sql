@@ -0,0 +1,3 @@
+INSERT INTO vcs_kinds VALUES ('bitbucket') ON CONFLICT DO NOTHING;
+---- create above / drop below ----
+DELETE FROM vcs_kinds WHERE name = 'bitbucket';
@@ -0,0 +1,6 @@
+-- The value of a variable with an external source is a secret, so such a
+-- variable is always sensitive.
+UPDATE variables SET sensitive = true WHERE source IS NOT NULL;
+---- create above / drop below ----
+-- A sensitive variable cannot be made non-sensitive, so there is nothing to
+-- undo.
@@ -7,6 +7,8 @@ var tableTypes = []string{
 	"ingress_attributes[]",
 	"workspace_permissions",
 	"workspace_permissions[]",
+	"project_permissions",
+	"project_permissions[]",
 	"report",
 	"report[]",
 	"run_variables",
@@ -2,8 +2,10 @@ package sshkey
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
@@ -11,10 +13,17 @@ import (
 
 type pgdb struct {
 	*sql.DB
+
+	// cipher encrypts private keys
+	cipher *encryption.Cipher
 }
 
 func (db *pgdb) create(ctx context.Context, key *SSHKey, privateKey []byte) error {
-	_, err := db.Exec(ctx, `
+	encrypted, err := db.cipher.Encrypt(ctx, privateKey)
+	if err != nil {
+		return fmt.Errorf("encrypting private key: %w", err)
+	}
+	_, err = db.Exec(ctx, `
 INSERT INTO ssh_keys (
     ssh_key_id,
     name,
@@ -30,7 +39,7 @@ INSERT INTO ssh_keys (
 		pgx.NamedArgs{
 			"id":                key.ID,
 			"name":              key.Name,
-			"private_key":       privateKey,
+			"private_key":       encrypted,
 			"organization_name": key.Organization,
 		},
 	)
@@ -82,7 +91,15 @@ SELECT private_key
 FROM ssh_keys
 WHERE ssh_key_id = $1
 `, id)
-	return sql.CollectOneType[[]byte](row)
+	privateKey, err := sql.CollectOneType[[]byte](row)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := db.cipher.Decrypt(ctx, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting private key: %w", err)
+	}
+	return decrypted, nil
 }
 
 func (db *pgdb) list(ctx context.Context, org organization.Name) ([]*SSHKey, error) {
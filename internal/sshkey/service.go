@@ -3,7 +3,9 @@ package sshkey
 import (
 	"context"
 
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
@@ -19,13 +21,17 @@ type (
 		logr.Logger
 		*authz.Authorizer
 
-		db *pgdb
+		db    *pgdb
+		audit audit.Recorder
 	}
 
 	Options struct {
-		DB         *sql.DB
-		Logger     logr.Logger
-		Authorizer *authz.Authorizer
+		DB            *sql.DB
+		Logger        logr.Logger
+		Authorizer    *authz.Authorizer
+		AuditRecorder audit.Recorder
+		// Cipher encrypts private keys.
+		Cipher *encryption.Cipher
 	}
 )
 
@@ -33,7 +39,8 @@ func NewService(opts Options) *Service {
 	svc := &Service{
 		Logger:     opts.Logger,
 		Authorizer: opts.Authorizer,
-		db:         &pgdb{opts.DB},
+		db:         &pgdb{DB: opts.DB, cipher: opts.Cipher},
+		audit:      opts.AuditRecorder,
 	}
 	// Register parent resolver so the authorizer can resolve ssh key -> org
 	opts.Authorizer.RegisterParentResolver(resource.SSHKeyKind,
@@ -65,6 +72,12 @@ func (s *Service) CreateSSHKey(ctx context.Context, opts CreateOptions) (*SSHKey
 		return nil, err
 	}
 	s.V(0).Info("created ssh key", "key", key, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: key.Organization,
+		Action:       resource.Create,
+		ResourceID:   key.ID,
+		After:        key.LogValue(),
+	})
 	return key, nil
 }
 
@@ -111,12 +124,16 @@ func (s *Service) ListSSHKeys(ctx context.Context, org organization.Name) ([]*SS
 }
 
 func (s *Service) UpdateSSHKey(ctx context.Context, id resource.TfeID, opts UpdateOptions) (*SSHKey, error) {
-	var subject authz.Subject
+	var (
+		subject authz.Subject
+		before  SSHKey
+	)
 	updated, err := s.db.update(ctx, id, func(ctx context.Context, key *SSHKey) (err error) {
 		subject, err = s.Authorize(ctx, resource.Update, resource.SSHKeyKind, id)
 		if err != nil {
 			return err
 		}
+		before = *key
 		if opts.Name != nil {
 			key.Name = *opts.Name
 		}
@@ -127,6 +144,13 @@ func (s *Service) UpdateSSHKey(ctx context.Context, id resource.TfeID, opts Upda
 		return nil, err
 	}
 	s.V(0).Info("updated ssh key", "key", updated, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: updated.Organization,
+		Action:       resource.Update,
+		ResourceID:   updated.ID,
+		Before:       before.LogValue(),
+		After:        updated.LogValue(),
+	})
 	return updated, nil
 }
 
@@ -141,5 +165,11 @@ func (s *Service) DeleteSSHKey(ctx context.Context, id resource.TfeID) (*SSHKey,
 		return nil, err
 	}
 	s.V(0).Info("deleted ssh key", "key", key, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: key.Organization,
+		Action:       resource.Delete,
+		ResourceID:   key.ID,
+		Before:       key.LogValue(),
+	})
 	return key, nil
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
 )
@@ -15,48 +16,59 @@ type (
 	// pgdb is a state/state-version database on postgres
 	pgdb struct {
 		*sql.DB // provides access to generated SQL queries
+
+		blobs blob.Store
 	}
 
 	// versionModel is the database model for a state version row.
 	versionModel struct {
-		StateVersionID      resource.TfeID `db:"state_version_id"`
-		CreatedAt           time.Time      `db:"created_at"`
-		Serial              int64          `db:"serial"`
-		State               []byte         `db:"state"`
-		WorkspaceID         resource.TfeID `db:"workspace_id"`
-		Status              Status         `db:"status"`
-		StateVersionOutputs []outputModel  `db:"state_version_outputs"`
+		StateVersionID      resource.TfeID  `db:"state_version_id"`
+		CreatedAt           time.Time       `db:"created_at"`
+		Serial              int64           `db:"serial"`
+		WorkspaceID         resource.TfeID  `db:"workspace_id"`
+		Status              Status          `db:"status"`
+		RunID               *resource.TfeID `db:"run_id"`
+		ResourcesProcessed  bool            `db:"resources_processed"`
+		StateVersionOutputs []outputModel   `db:"state_version_outputs"`
 	}
 )
 
 func (db *pgdb) createVersion(ctx context.Context, v *Version) error {
 	return db.Tx(ctx, func(ctx context.Context) error {
-		_, err := db.Exec(ctx, `
+		// A state version is created by a run if the run holds the lock on
+		// the workspace.
+		rows := db.Query(ctx, `
 INSERT INTO state_versions (
     state_version_id,
     created_at,
     serial,
-    state,
     status,
-    workspace_id
+    workspace_id,
+    run_id
 ) VALUES (
     @id,
     @created_at,
     @serial,
-    @state,
 	@status,
-	@workspace_id
-)`, pgx.NamedArgs{
+	@workspace_id,
+	(SELECT lock_run_id FROM workspaces WHERE workspace_id = @workspace_id)
+)
+RETURNING run_id
+`, pgx.NamedArgs{
 			"id":           v.ID,
 			"created_at":   v.CreatedAt,
 			"serial":       v.Serial,
-			"state":        v.State,
 			"status":       v.Status,
 			"workspace_id": v.WorkspaceID,
 		})
+		runID, err := sql.CollectOneType[*resource.TfeID](rows)
 		if err != nil {
 			return err
 		}
+		v.RunID = runID
+		if v.State != nil {
+			return db.blobs.Put(ctx, blob.StateKey(v.ID), v.State)
+		}
 		return nil
 	})
 }
@@ -96,18 +108,21 @@ INSERT INTO state_version_outputs (
 }
 
 func (db *pgdb) uploadStateAndFinalize(ctx context.Context, svID resource.TfeID, state []byte) error {
+	if err := db.blobs.Put(ctx, blob.StateKey(svID), state); err != nil {
+		return err
+	}
 	_, err := db.Exec(ctx, `
 UPDATE state_versions
-SET state = $1, status = 'finalized'
-WHERE state_version_id = $2
-`, state, svID)
+SET status = 'finalized'
+WHERE state_version_id = $1
+`, svID)
 	return err
 }
 
 func (db *pgdb) listVersions(ctx context.Context, workspaceID resource.TfeID, opts resource.PageOptions) (*resource.Page[*Version], error) {
 	rows := db.Query(ctx, `
 SELECT
-    sv.state_version_id, sv.created_at, sv.serial, sv.state, sv.workspace_id, sv.status,
+    sv.state_version_id, sv.created_at, sv.serial, sv.workspace_id, sv.status, sv.run_id, sv.resources_processed,
     (
         SELECT array_agg(svo.*)::state_version_outputs[]
         FROM state_version_outputs svo
@@ -125,6 +140,11 @@ OFFSET $3::int
 	if err != nil {
 		return nil, err
 	}
+	for _, sv := range items {
+		if err := db.loadState(ctx, sv); err != nil {
+			return nil, err
+		}
+	}
 
 	count, err := db.Int(ctx, `
 SELECT count(*)
@@ -145,13 +165,14 @@ func scanVersion(row pgx.CollectableRow) (*Version, error) {
 		return nil, err
 	}
 	sv := Version{
-		ID:          model.StateVersionID,
-		CreatedAt:   model.CreatedAt,
-		Serial:      model.Serial,
-		State:       model.State,
-		Status:      model.Status,
-		WorkspaceID: model.WorkspaceID,
-		Outputs:     make(map[string]*Output, len(model.StateVersionOutputs)),
+		ID:                 model.StateVersionID,
+		CreatedAt:          model.CreatedAt,
+		Serial:             model.Serial,
+		Status:             model.Status,
+		WorkspaceID:        model.WorkspaceID,
+		RunID:              model.RunID,
+		ResourcesProcessed: model.ResourcesProcessed,
+		Outputs:            make(map[string]*Output, len(model.StateVersionOutputs)),
 	}
 	for _, output := range model.StateVersionOutputs {
 		sv.Outputs[output.Name] = output.toOutput()
@@ -160,10 +181,37 @@ func scanVersion(row pgx.CollectableRow) (*Version, error) {
 
 }
 
+// collectVersion collects a single state version from rows, along with its
+// state from the blob store.
+func (db *pgdb) collectVersion(ctx context.Context, rows pgx.Rows) (*Version, error) {
+	sv, err := sql.CollectOneRow(rows, scanVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.loadState(ctx, sv); err != nil {
+		return nil, err
+	}
+	return sv, nil
+}
+
+// loadState populates the state version with its state from the blob store.
+// A pending state version has yet to have its state uploaded, in which case
+// its state is left empty.
+func (db *pgdb) loadState(ctx context.Context, sv *Version) error {
+	state, err := db.blobs.Get(ctx, blob.StateKey(sv.ID))
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	sv.State = state
+	return nil
+}
+
 func (db *pgdb) getVersion(ctx context.Context, svID resource.ID) (*Version, error) {
 	rows := db.Query(ctx, `
 SELECT
-    sv.state_version_id, sv.created_at, sv.serial, sv.state, sv.workspace_id, sv.status,
+    sv.state_version_id, sv.created_at, sv.serial, sv.workspace_id, sv.status, sv.run_id, sv.resources_processed,
     (
         SELECT array_agg(svo.*)::state_version_outputs[]
         FROM state_version_outputs svo
@@ -173,13 +221,22 @@ SELECT
 FROM state_versions sv
 WHERE sv.state_version_id = $1
 `, svID)
-	return sql.CollectOneRow(rows, scanVersion)
+	return db.collectVersion(ctx, rows)
+}
+
+func (db *pgdb) getVersionWorkspaceID(ctx context.Context, svID resource.ID) (resource.ID, error) {
+	rows := db.Query(ctx, `
+SELECT workspace_id
+FROM state_versions
+WHERE state_version_id = $1
+`, svID)
+	return sql.CollectOneType[resource.TfeID](rows)
 }
 
 func (db *pgdb) getVersionForUpdate(ctx context.Context, svID resource.TfeID) (*Version, error) {
 	rows := db.Query(ctx, `
 SELECT
-    sv.state_version_id, sv.created_at, sv.serial, sv.state, sv.workspace_id, sv.status,
+    sv.state_version_id, sv.created_at, sv.serial, sv.workspace_id, sv.status, sv.run_id, sv.resources_processed,
     (
         SELECT array_agg(svo.*)::state_version_outputs[]
         FROM state_version_outputs svo
@@ -190,13 +247,13 @@ FROM state_versions sv
 WHERE sv.state_version_id = $1
 FOR UPDATE OF sv
 `, svID)
-	return sql.CollectOneRow(rows, scanVersion)
+	return db.collectVersion(ctx, rows)
 }
 
 func (db *pgdb) getCurrentVersion(ctx context.Context, workspaceID resource.TfeID) (*Version, error) {
 	rows := db.Query(ctx, `
 SELECT
-    sv.state_version_id, sv.created_at, sv.serial, sv.state, sv.workspace_id, sv.status,
+    sv.state_version_id, sv.created_at, sv.serial, sv.workspace_id, sv.status, sv.run_id, sv.resources_processed,
     (
         SELECT array_agg(svo.*)::state_version_outputs[]
         FROM state_version_outputs svo
@@ -207,16 +264,11 @@ FROM state_versions sv
 JOIN workspaces w ON w.current_state_version_id = sv.state_version_id
 WHERE w.workspace_id = $1
 `, workspaceID)
-	return sql.CollectOneRow(rows, scanVersion)
+	return db.collectVersion(ctx, rows)
 }
 
 func (db *pgdb) getState(ctx context.Context, id resource.TfeID) ([]byte, error) {
-	rows := db.Query(ctx, `
-SELECT state
-FROM state_versions
-WHERE state_version_id = $1
-`, id)
-	return sql.CollectOneRow(rows, pgx.RowTo[[]byte])
+	return db.blobs.Get(ctx, blob.StateKey(id))
 }
 
 // getPreviousVersion returns the finalized state version with the highest
@@ -224,7 +276,7 @@ WHERE state_version_id = $1
 func (db *pgdb) getPreviousVersion(ctx context.Context, sv *Version) (*Version, error) {
 	rows := db.Query(ctx, `
 SELECT
-    sv.state_version_id, sv.created_at, sv.serial, sv.state, sv.workspace_id, sv.status,
+    sv.state_version_id, sv.created_at, sv.serial, sv.workspace_id, sv.status, sv.run_id, sv.resources_processed,
     (
         SELECT array_agg(svo.*)::state_version_outputs[]
         FROM state_version_outputs svo
@@ -238,7 +290,7 @@ AND   sv.status = 'finalized'
 ORDER BY sv.serial DESC
 LIMIT 1
 `, sv.WorkspaceID, sv.Serial)
-	return sql.CollectOneRow(rows, scanVersion)
+	return db.collectVersion(ctx, rows)
 }
 
 // deleteVersion deletes a state version from the DB
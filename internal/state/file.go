@@ -2,7 +2,9 @@ package state
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -30,9 +32,48 @@ type (
 		ProviderURI string `json:"provider"`
 		Type        string
 		Module      string
+		// Mode is either managed or data.
+		Mode      string
+		Instances []ResourceInstance
+	}
+
+	// ResourceInstance is an instance of a resource in the terraform state
+	// file. A resource has more than one instance if it uses count or
+	// for_each.
+	ResourceInstance struct {
+		// IndexKey is the count index or for_each key of the instance. Nil if
+		// the resource uses neither.
+		IndexKey   any             `json:"index_key"`
+		Attributes json.RawMessage `json:"attributes"`
+		// SensitiveAttributes are the paths to sensitive attributes.
+		SensitiveAttributes json.RawMessage `json:"sensitive_attributes"`
 	}
 )
 
+// Address returns the address of an instance of the resource, e.g.
+// module.vpc.aws_subnet.private[0]
+func (r Resource) Address(inst ResourceInstance) string {
+	var b strings.Builder
+	if r.Module != "" {
+		b.WriteString(r.Module)
+		b.WriteRune('.')
+	}
+	if r.Mode == "data" {
+		b.WriteString("data.")
+	}
+	b.WriteString(r.Type)
+	b.WriteRune('.')
+	b.WriteString(r.Name)
+	switch key := inst.IndexKey.(type) {
+	case nil:
+	case string:
+		b.WriteString("[" + strconv.Quote(key) + "]")
+	default:
+		b.WriteString(fmt.Sprintf("[%v]", key))
+	}
+	return b.String()
+}
+
 // Provider extracts the provider from the provider URI
 func (r Resource) Provider() string {
 	matches := providerPathRegex.FindStringSubmatch(r.ProviderURI)
@@ -0,0 +1,393 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+// SensitiveValue replaces the value of a sensitive attribute.
+const SensitiveValue = "(sensitive value)"
+
+// MaxSearchResults is the maximum number of resource instances returned by a
+// search.
+const MaxSearchResults = 100
+
+// identifierRegex matches attribute names that can be written in a path
+// without quoting.
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+type (
+	// Instance is a resource instance in a state version. The values of
+	// sensitive attributes are replaced with SensitiveValue.
+	Instance struct {
+		StateVersionID resource.TfeID
+		Address        string
+		Module         string
+		Mode           string
+		Type           string
+		Name           string
+		ProviderURI    string
+		Attributes     map[string]any
+	}
+
+	// SearchResult is a resource instance in the current state of a workspace
+	// that matches a search query.
+	SearchResult struct {
+		*Instance
+
+		WorkspaceID   resource.TfeID
+		WorkspaceName string
+		// MatchingAttributes are the paths of attributes with values that
+		// match the query.
+		MatchingAttributes []string
+	}
+
+	// HistoryEntry is a change to a resource instance made by a state
+	// version.
+	HistoryEntry struct {
+		StateVersionID resource.TfeID
+		Serial         int64
+		CreatedAt      time.Time
+		// RunID is the ID of the run that created the state version. Nil if
+		// the state version was not created by a run.
+		RunID *resource.TfeID
+		// Action is add if the instance was added, remove if it was removed,
+		// or change if its attributes changed.
+		Action  ChangeAction
+		Changes []AttributeChange
+	}
+
+	// AttributeChange is a change to the value of an attribute. Nested
+	// attributes are flattened into a path, e.g. tags.Name or ingress[0].port.
+	AttributeChange struct {
+		Path string
+		// Before is the JSON-encoded value before the change. Nil if the
+		// attribute was absent.
+		Before *string
+		// After is the JSON-encoded value after the change. Nil if the
+		// attribute is absent.
+		After *string
+	}
+
+	// instanceVersion is a resource instance in a state version. Attributes
+	// are nil if the instance is absent from the state version.
+	instanceVersion struct {
+		StateVersionID resource.TfeID
+		Serial         int64
+		CreatedAt      time.Time
+		RunID          *resource.TfeID
+		Attributes     map[string]any
+	}
+
+	// sensitivePathStep is a step in the path to a sensitive attribute.
+	sensitivePathStep struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+)
+
+// Instances returns the resource instances in the state file, masking the
+// values of sensitive attributes.
+func (f *File) Instances(svID resource.TfeID) ([]*Instance, error) {
+	var instances []*Instance
+	for _, res := range f.Resources {
+		for _, inst := range res.Instances {
+			attrs, err := maskedAttributes(inst)
+			if err != nil {
+				return nil, fmt.Errorf("parsing attributes of %s: %w", res.Address(inst), err)
+			}
+			instances = append(instances, &Instance{
+				StateVersionID: svID,
+				Address:        res.Address(inst),
+				Module:         res.Module,
+				Mode:           res.Mode,
+				Type:           res.Type,
+				Name:           res.Name,
+				ProviderURI:    res.ProviderURI,
+				Attributes:     attrs,
+			})
+		}
+	}
+	return instances, nil
+}
+
+// Provider extracts the provider from the provider URI
+func (i *Instance) Provider() string {
+	return Resource{ProviderURI: i.ProviderURI}.Provider()
+}
+
+// ModuleName returns the name of the module containing the instance.
+func (i *Instance) ModuleName() string {
+	return Resource{Module: i.Module}.ModuleName()
+}
+
+// InstanceName returns the name of the resource along with the index key of
+// the instance, if any, e.g. private[0]
+func (i *Instance) InstanceName() string {
+	name := i.Address
+	if i.Module != "" {
+		name = strings.TrimPrefix(name, i.Module+".")
+	}
+	name = strings.TrimPrefix(name, "data.")
+	return strings.TrimPrefix(name, i.Type+".")
+}
+
+// FlatAttributes returns the attributes of the instance, flattened into a map
+// of paths to JSON-encoded values.
+func (i *Instance) FlatAttributes() map[string]string {
+	flat := make(map[string]string)
+	flattenAttributes("", i.Attributes, flat)
+	return flat
+}
+
+// searchValues returns the values of attributes for matching against a search
+// query. Masked sensitive values are excluded.
+func (i *Instance) searchValues() []string {
+	values := []string{}
+	for _, v := range i.FlatAttributes() {
+		if v == strconv.Quote(SensitiveValue) {
+			continue
+		}
+		// Strings are searched without their quotes.
+		if s, err := strconv.Unquote(v); err == nil {
+			v = s
+		}
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	return values
+}
+
+// matchingAttributes returns the paths of attributes with values containing
+// the query, ignoring case.
+func (i *Instance) matchingAttributes(query string) []string {
+	query = strings.ToLower(query)
+	var paths []string
+	for path, v := range i.FlatAttributes() {
+		if v == strconv.Quote(SensitiveValue) {
+			continue
+		}
+		if s, err := strconv.Unquote(v); err == nil {
+			v = s
+		}
+		if strings.Contains(strings.ToLower(v), query) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// maskedAttributes decodes the attributes of a resource instance, replacing
+// the values of sensitive attributes with SensitiveValue. If the paths to
+// sensitive attributes cannot be parsed then every attribute is masked, to
+// err on the side of caution.
+func maskedAttributes(inst ResourceInstance) (map[string]any, error) {
+	attrs := make(map[string]any)
+	if len(inst.Attributes) > 0 {
+		var err error
+		attrs, err = decodeAttributes(inst.Attributes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(inst.SensitiveAttributes) == 0 {
+		return attrs, nil
+	}
+	var paths [][]sensitivePathStep
+	if err := json.Unmarshal(inst.SensitiveAttributes, &paths); err != nil {
+		for k := range attrs {
+			attrs[k] = SensitiveValue
+		}
+		return attrs, nil
+	}
+	for _, path := range paths {
+		maskPath(attrs, path)
+	}
+	return attrs, nil
+}
+
+// decodeAttributes decodes the attributes of a resource instance, decoding
+// numbers as json.Number to retain the precision of large integers.
+func decodeAttributes(data []byte) (map[string]any, error) {
+	var attrs map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&attrs); err != nil {
+		return nil, err
+	}
+	if attrs == nil {
+		attrs = make(map[string]any)
+	}
+	return attrs, nil
+}
+
+// maskPath replaces the value at the path with SensitiveValue. Paths that do
+// not exist are ignored.
+func maskPath(v any, path []sensitivePathStep) {
+	if len(path) == 0 {
+		return
+	}
+	step, rest := path[0], path[1:]
+	switch step.Type {
+	case "get_attr":
+		var key string
+		if err := json.Unmarshal(step.Value, &key); err != nil {
+			return
+		}
+		maskMapKey(v, key, rest)
+	case "index":
+		// The index is a value along with its type, e.g.
+		// {"value":0,"type":"number"}
+		var index struct {
+			Value any `json:"value"`
+		}
+		if err := json.Unmarshal(step.Value, &index); err != nil {
+			return
+		}
+		switch key := index.Value.(type) {
+		case string:
+			maskMapKey(v, key, rest)
+		case float64:
+			l, ok := v.([]any)
+			if !ok || key < 0 || int(key) >= len(l) {
+				return
+			}
+			if len(rest) == 0 {
+				l[int(key)] = SensitiveValue
+				return
+			}
+			maskPath(l[int(key)], rest)
+		}
+	}
+}
+
+func maskMapKey(v any, key string, rest []sensitivePathStep) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	if _, ok := m[key]; !ok {
+		return
+	}
+	if len(rest) == 0 {
+		m[key] = SensitiveValue
+		return
+	}
+	maskPath(m[key], rest)
+}
+
+// flattenAttributes flattens nested attributes into a map of paths to
+// JSON-encoded values. Empty maps and lists are retained as values.
+func flattenAttributes(path string, v any, flat map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 && path != "" {
+			flat[path] = "{}"
+			return
+		}
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			flattenAttributes(appendAttributePath(path, k), v[k], flat)
+		}
+	case []any:
+		if len(v) == 0 {
+			flat[path] = "[]"
+			return
+		}
+		for i, elem := range v {
+			flattenAttributes(path+"["+strconv.Itoa(i)+"]", elem, flat)
+		}
+	case nil:
+		// Null attributes are omitted.
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(v))
+		}
+		flat[path] = string(encoded)
+	}
+}
+
+// appendAttributePath appends a map key to an attribute path, quoting the key
+// if necessary.
+func appendAttributePath(path, key string) string {
+	switch {
+	case !identifierRegex.MatchString(key):
+		return path + "[" + strconv.Quote(key) + "]"
+	case path == "":
+		return key
+	default:
+		return path + "." + key
+	}
+}
+
+// newHistory compiles the history of a resource instance from its versions,
+// which are ordered oldest first. Entries are returned newest first, and only
+// for versions that changed the instance.
+func newHistory(versions []instanceVersion) []*HistoryEntry {
+	var (
+		entries []*HistoryEntry
+		prev    map[string]string
+	)
+	for _, v := range versions {
+		var current map[string]string
+		if v.Attributes != nil {
+			current = make(map[string]string)
+			flattenAttributes("", v.Attributes, current)
+		}
+		entry := &HistoryEntry{
+			StateVersionID: v.StateVersionID,
+			Serial:         v.Serial,
+			CreatedAt:      v.CreatedAt,
+			RunID:          v.RunID,
+		}
+		switch {
+		case prev == nil && current == nil:
+			continue
+		case prev == nil:
+			entry.Action = ActionAdd
+		case current == nil:
+			entry.Action = ActionRemove
+		default:
+			entry.Action = ActionChange
+		}
+		paths := make(map[string]struct{})
+		for k := range prev {
+			paths[k] = struct{}{}
+		}
+		for k := range current {
+			paths[k] = struct{}{}
+		}
+		for _, path := range slices.Sorted(maps.Keys(paths)) {
+			before, hadBefore := prev[path]
+			after, hasAfter := current[path]
+			if hadBefore && hasAfter && before == after {
+				continue
+			}
+			change := AttributeChange{Path: path}
+			if hadBefore {
+				change.Before = &before
+			}
+			if hasAfter {
+				change.After = &after
+			}
+			entry.Changes = append(entry.Changes, change)
+		}
+		prev = current
+		if entry.Action == ActionChange && len(entry.Changes) == 0 {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	slices.Reverse(entries)
+	return entries
+}
@@ -0,0 +1,173 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+type (
+	// searchResultModel is the database model for a resource instance
+	// matching a search.
+	searchResultModel struct {
+		StateVersionID resource.TfeID `db:"state_version_id"`
+		WorkspaceID    resource.TfeID `db:"workspace_id"`
+		WorkspaceName  string         `db:"workspace_name"`
+		Address        string         `db:"address"`
+		Module         string         `db:"module"`
+		Mode           string         `db:"mode"`
+		Type           string         `db:"type"`
+		Name           string         `db:"name"`
+		Provider       string         `db:"provider"`
+		Attributes     []byte         `db:"attributes"`
+	}
+
+	// instanceVersionModel is the database model for a resource instance in
+	// a state version.
+	instanceVersionModel struct {
+		StateVersionID resource.TfeID  `db:"state_version_id"`
+		Serial         int64           `db:"serial"`
+		CreatedAt      time.Time       `db:"created_at"`
+		RunID          *resource.TfeID `db:"run_id"`
+		Attributes     []byte          `db:"attributes"`
+	}
+)
+
+// createInstances indexes the resource instances in a state version.
+func (db *pgdb) createInstances(ctx context.Context, svID resource.TfeID, instances []*Instance) error {
+	return db.Tx(ctx, func(ctx context.Context) error {
+		for _, inst := range instances {
+			attrs, err := json.Marshal(inst.Attributes)
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec(ctx, `
+INSERT INTO state_resource_instances (
+    state_version_id,
+    address,
+    module,
+    mode,
+    type,
+    name,
+    provider,
+    attributes,
+    attribute_values
+) VALUES (
+    @state_version_id,
+    @address,
+    @module,
+    @mode,
+    @type,
+    @name,
+    @provider,
+    @attributes,
+    @attribute_values
+)`, pgx.NamedArgs{
+				"state_version_id": inst.StateVersionID,
+				"address":          inst.Address,
+				"module":           inst.Module,
+				"mode":             inst.Mode,
+				"type":             inst.Type,
+				"name":             inst.Name,
+				"provider":         inst.ProviderURI,
+				"attributes":       attrs,
+				"attribute_values": inst.searchValues(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		_, err := db.Exec(ctx, `
+UPDATE state_versions
+SET resources_processed = true
+WHERE state_version_id = $1
+`, svID)
+		return err
+	})
+}
+
+// searchInstances searches the current state of every workspace in an
+// organization for resource instances with an address or attribute value
+// containing the query, ignoring case.
+func (db *pgdb) searchInstances(ctx context.Context, org organization.Name, query string) ([]*SearchResult, error) {
+	rows := db.Query(ctx, `
+SELECT
+    r.state_version_id, w.workspace_id, w.name AS workspace_name,
+    r.address, r.module, r.mode, r.type, r.name, r.provider, r.attributes
+FROM state_resource_instances r
+JOIN workspaces w ON w.current_state_version_id = r.state_version_id
+WHERE w.organization_name = $1
+AND (
+    strpos(lower(r.address), lower($2)) > 0
+    OR EXISTS (
+        SELECT FROM unnest(r.attribute_values) AS v
+        WHERE strpos(lower(v), lower($2)) > 0
+    )
+)
+ORDER BY w.name, r.address
+LIMIT $3::int
+`, org, query, MaxSearchResults)
+	return sql.CollectRows(rows, func(row pgx.CollectableRow) (*SearchResult, error) {
+		model, err := pgx.RowToStructByName[searchResultModel](row)
+		if err != nil {
+			return nil, err
+		}
+		attrs, err := decodeAttributes(model.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		return &SearchResult{
+			Instance: &Instance{
+				StateVersionID: model.StateVersionID,
+				Address:        model.Address,
+				Module:         model.Module,
+				Mode:           model.Mode,
+				Type:           model.Type,
+				Name:           model.Name,
+				ProviderURI:    model.Provider,
+				Attributes:     attrs,
+			},
+			WorkspaceID:   model.WorkspaceID,
+			WorkspaceName: model.WorkspaceName,
+		}, nil
+	})
+}
+
+// listInstanceVersions lists a resource instance in every indexed state
+// version of a workspace, oldest first. Attributes are nil for state versions
+// from which the instance is absent.
+func (db *pgdb) listInstanceVersions(ctx context.Context, workspaceID resource.TfeID, address string) ([]instanceVersion, error) {
+	rows := db.Query(ctx, `
+SELECT sv.state_version_id, sv.serial, sv.created_at, sv.run_id, r.attributes
+FROM state_versions sv
+LEFT JOIN state_resource_instances r ON r.state_version_id = sv.state_version_id AND r.address = $2
+WHERE sv.workspace_id = $1
+AND   sv.status = 'finalized'
+AND   sv.resources_processed
+ORDER BY sv.serial ASC, sv.created_at ASC
+`, workspaceID, address)
+	return sql.CollectRows(rows, func(row pgx.CollectableRow) (instanceVersion, error) {
+		model, err := pgx.RowToStructByName[instanceVersionModel](row)
+		if err != nil {
+			return instanceVersion{}, err
+		}
+		v := instanceVersion{
+			StateVersionID: model.StateVersionID,
+			Serial:         model.Serial,
+			CreatedAt:      model.CreatedAt,
+			RunID:          model.RunID,
+		}
+		if model.Attributes != nil {
+			v.Attributes, err = decodeAttributes(model.Attributes)
+			if err != nil {
+				return instanceVersion{}, err
+			}
+		}
+		return v, nil
+	})
+}
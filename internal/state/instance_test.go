@@ -0,0 +1,107 @@
+package state
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_Instances(t *testing.T) {
+	var file File
+	require.NoError(t, json.Unmarshal(testutils.ReadFile(t, "testdata/resources.tfstate"), &file))
+
+	svID := resource.NewTfeID(resource.StateVersionKind)
+	got, err := file.Instances(svID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	web := got[0]
+	assert.Equal(t, svID, web.StateVersionID)
+	assert.Equal(t, "aws_instance.web[0]", web.Address)
+	assert.Equal(t, "web[0]", web.InstanceName())
+	assert.Equal(t, "root", web.ModuleName())
+	assert.Equal(t, "hashicorp/aws", web.Provider())
+	assert.Equal(t, map[string]string{
+		"arn":                            `"arn:aws:ec2:eu-west-2:123456789012:instance/i-0abc"`,
+		"cpu_core_count":                 `2`,
+		"ebs_block_device[0].kms_key_id": `"(sensitive value)"`,
+		"ebs_block_device[0].size":       `8`,
+		"private_ip":                     `"10.0.1.23"`,
+		`tags.Name`:                      `"web-0"`,
+		`tags["kubernetes.io/role"]`:     `"node"`,
+		"user_data":                      `"(sensitive value)"`,
+	}, web.FlatAttributes())
+
+	secret := got[1]
+	assert.Equal(t, `module.db.data.aws_secretsmanager_secret_version.password["primary"]`, secret.Address)
+	assert.Equal(t, `password["primary"]`, secret.InstanceName())
+	assert.Equal(t, "db", secret.ModuleName())
+	assert.Equal(t, SensitiveValue, secret.Attributes["secret_string"])
+
+	t.Run("sensitive values are not searchable", func(t *testing.T) {
+		assert.NotContains(t, web.searchValues(), "secret-script")
+		assert.NotContains(t, web.searchValues(), "secret-key")
+		assert.NotContains(t, web.searchValues(), SensitiveValue)
+		assert.Contains(t, web.searchValues(), "10.0.1.23")
+	})
+
+	t.Run("matching attributes", func(t *testing.T) {
+		assert.Equal(t, []string{"arn"}, web.matchingAttributes("I-0ABC"))
+		assert.Equal(t, []string{"private_ip"}, web.matchingAttributes("10.0.1"))
+		assert.Empty(t, web.matchingAttributes("sensitive"))
+	})
+
+	t.Run("unparseable sensitive attributes mask everything", func(t *testing.T) {
+		attrs, err := maskedAttributes(ResourceInstance{
+			Attributes:          json.RawMessage(`{"id": "abc", "password": "hunter2"}`),
+			SensitiveAttributes: json.RawMessage(`{"unexpected": "format"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": SensitiveValue, "password": SensitiveValue}, attrs)
+	})
+}
+
+func TestNewHistory(t *testing.T) {
+	runID := resource.NewTfeID(resource.RunKind)
+	versions := []instanceVersion{
+		{Serial: 1},
+		{Serial: 2, RunID: &runID, Attributes: map[string]any{"ip": "10.0.0.1", "size": json.Number("8")}},
+		{Serial: 3, Attributes: map[string]any{"ip": "10.0.0.1", "size": json.Number("8")}},
+		{Serial: 4, Attributes: map[string]any{"ip": "10.0.0.2", "size": json.Number("8"), "name": "web"}},
+		{Serial: 5},
+	}
+	for i := range versions {
+		versions[i].StateVersionID = resource.NewTfeID(resource.StateVersionKind)
+		versions[i].CreatedAt = time.Now()
+	}
+
+	got := newHistory(versions)
+
+	// versions that did not change the instance are omitted, and the newest
+	// change is first.
+	require.Len(t, got, 3)
+
+	assert.Equal(t, int64(5), got[0].Serial)
+	assert.Equal(t, ActionRemove, got[0].Action)
+	assert.Len(t, got[0].Changes, 3)
+
+	assert.Equal(t, int64(4), got[1].Serial)
+	assert.Equal(t, ActionChange, got[1].Action)
+	assert.Equal(t, []AttributeChange{
+		{Path: "ip", Before: new(`"10.0.0.1"`), After: new(`"10.0.0.2"`)},
+		{Path: "name", After: new(`"web"`)},
+	}, got[1].Changes)
+
+	assert.Equal(t, int64(2), got[2].Serial)
+	assert.Equal(t, ActionAdd, got[2].Action)
+	assert.Equal(t, &runID, got[2].RunID)
+	assert.Equal(t, []AttributeChange{
+		{Path: "ip", After: new(`"10.0.0.1"`)},
+		{Path: "size", After: new(`8`)},
+	}, got[2].Changes)
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/blob"
 	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
 )
@@ -22,26 +25,30 @@ type (
 	Service struct {
 		logr.Logger
 
-		db *pgdb
+		db    *pgdb
+		audit audit.Recorder
 
 		*factory // for creating state versions
 		*authz.Authorizer
 	}
 
 	Options struct {
-		Logger     logr.Logger
-		DB         *sql.DB
-		Authorizer *authz.Authorizer
+		Logger        logr.Logger
+		DB            *sql.DB
+		Authorizer    *authz.Authorizer
+		BlobStore     blob.Store
+		AuditRecorder audit.Recorder
 	}
 )
 
 func NewService(opts Options) *Service {
-	db := &pgdb{opts.DB}
+	db := &pgdb{opts.DB, opts.BlobStore}
 	svc := Service{
 		Logger:     opts.Logger,
 		Authorizer: opts.Authorizer,
 		db:         db,
 		factory:    &factory{db},
+		audit:      opts.AuditRecorder,
 	}
 
 	// Provide a means of looking up a state versions's parent workspace.
@@ -49,11 +56,7 @@ func NewService(opts Options) *Service {
 		func(ctx context.Context, svID resource.ID) (resource.ID, error) {
 			// NOTE: we look up directly in the database rather than via
 			// service call to avoid a recursion loop.
-			sv, err := db.getVersion(ctx, svID)
-			if err != nil {
-				return nil, err
-			}
-			return sv.WorkspaceID, nil
+			return db.getVersionWorkspaceID(ctx, svID)
 		},
 	)
 	return &svc
@@ -174,6 +177,12 @@ func (a *Service) RollbackStateVersion(ctx context.Context, versionID resource.T
 		return nil, err
 	}
 	a.V(0).Info("rolled back state version", "state_version", sv, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Parent:     sv.WorkspaceID,
+		Action:     resource.Rollback,
+		ResourceID: versionID,
+		After:      map[string]any{"state_version_id": sv.ID, "serial": sv.Serial},
+	})
 	return sv, nil
 }
 
@@ -228,3 +237,53 @@ func (a *Service) GetStateOutput(ctx context.Context, outputID resource.TfeID) (
 	a.V(9).Info("retrieved state version output", "id", outputID, "subject", subject)
 	return out, nil
 }
+
+// SearchResources searches the current state of every workspace in an
+// organization for resource instances with an address or attribute value
+// containing the query. Only instances in workspaces the subject is permitted
+// to access are returned.
+func (a *Service) SearchResources(ctx context.Context, org organization.Name, query string) ([]*SearchResult, error) {
+	subject, err := a.Authorize(ctx, resource.Get, resource.OrganizationKind, org)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := a.db.searchInstances(ctx, org, query)
+	if err != nil {
+		a.Error(err, "searching resources", "organization", org, "subject", subject)
+		return nil, err
+	}
+	permitted := make(map[resource.TfeID]bool)
+	filtered := make([]*SearchResult, 0, len(results))
+	for _, result := range results {
+		ok, checked := permitted[result.WorkspaceID]
+		if !checked {
+			ok = a.CanAccess(ctx, resource.Get, resource.StateVersionKind, result.WorkspaceID)
+			permitted[result.WorkspaceID] = ok
+		}
+		if !ok {
+			continue
+		}
+		result.MatchingAttributes = result.matchingAttributes(query)
+		filtered = append(filtered, result)
+	}
+	a.V(9).Info("searched resources", "organization", org, "results", len(filtered), "subject", subject)
+	return filtered, nil
+}
+
+// GetResourceHistory retrieves the history of changes to a resource instance
+// in a workspace's state, newest first.
+func (a *Service) GetResourceHistory(ctx context.Context, workspaceID resource.TfeID, address string) ([]*HistoryEntry, error) {
+	subject, err := a.Authorize(ctx, resource.Get, resource.StateVersionKind, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := a.db.listInstanceVersions(ctx, workspaceID, address)
+	if err != nil {
+		a.Error(err, "retrieving resource history", "workspace_id", workspaceID, "address", address, "subject", subject)
+		return nil, err
+	}
+	a.V(9).Info("retrieved resource history", "workspace_id", workspaceID, "address", address, "subject", subject)
+	return newHistory(versions), nil
+}
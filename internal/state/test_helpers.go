@@ -24,6 +24,10 @@ func (f *fakeDB) createOutputs(context.Context, []*Output) error {
 	return nil
 }
 
+func (f *fakeDB) createInstances(context.Context, resource.TfeID, []*Instance) error {
+	return nil
+}
+
 func (f *fakeDB) getVersion(ctx context.Context, svID resource.ID) (*Version, error) {
 	if f.version == nil {
 		return nil, internal.ErrResourceNotFound
@@ -12,6 +12,7 @@ import (
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/state"
 	"github.com/leg100/otf/internal/ui/helpers"
@@ -31,6 +32,8 @@ type Client interface {
 	RollbackStateVersion(ctx context.Context, id resource.TfeID) (*state.Version, error)
 	DeleteStateVersion(ctx context.Context, id resource.TfeID) error
 	GetPreviousStateVersion(ctx context.Context, sv *state.Version) (*state.Version, error)
+	SearchResources(ctx context.Context, org organization.Name, query string) ([]*state.SearchResult, error)
+	GetResourceHistory(ctx context.Context, workspaceID resource.TfeID, address string) ([]*state.HistoryEntry, error)
 	GetWorkspace(context.Context, resource.TfeID) (*workspace.Workspace, error)
 }
 
@@ -48,6 +51,8 @@ func (h *Handlers) AddHandlers(r *mux.Router) {
 	r.HandleFunc("/state-versions/{state_version_id}/rollback", h.rollbackStateVersion).Methods("POST")
 	r.HandleFunc("/state-versions/{state_version_id}/delete", h.deleteStateVersion).Methods("POST")
 	r.HandleFunc("/state-versions/{state_version_id}/diff", h.diffStateVersion).Methods("GET")
+	r.HandleFunc("/workspaces/{workspace_id}/resource-history", h.getResourceHistory).Methods("GET")
+	r.HandleFunc("/organizations/{organization_name}/state-search", h.searchResources).Methods("GET")
 }
 
 func (h *Handlers) getState(w http.ResponseWriter, r *http.Request) {
@@ -58,13 +63,16 @@ func (h *Handlers) getState(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// ignore errors and instead render unpopulated template
-	f := &state.File{}
+	props := getStateProps{workspaceID: id, file: &state.File{}}
 	sv, err := h.client.GetCurrentStateVersion(r.Context(), id)
 	if err == nil {
-		f, _ = sv.File()
+		if f, err := sv.File(); err == nil {
+			props.file = f
+			props.instances, _ = f.Instances(sv.ID)
+		}
 	}
 
-	helpers.Render(getState(f), w, r)
+	helpers.Render(getState(props), w, r)
 }
 
 func (h *Handlers) listStateVersions(w http.ResponseWriter, r *http.Request) {
@@ -246,3 +254,68 @@ func (h *Handlers) diffStateVersion(w http.ResponseWriter, r *http.Request) {
 		),
 	)
 }
+
+func (h *Handlers) getResourceHistory(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		WorkspaceID resource.TfeID `schema:"workspace_id,required"`
+		Address     string         `schema:"address,required"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	ws, err := h.client.GetWorkspace(r.Context(), params.WorkspaceID)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	entries, err := h.client.GetResourceHistory(r.Context(), params.WorkspaceID, params.Address)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		resourceHistory(entries),
+		params.Address,
+		w, r,
+		helpers.WithWorkspace(ws, h.authorizer),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "State versions", Link: path.List(resource.StateVersionKind, ws.ID)},
+			helpers.Breadcrumb{Name: params.Address},
+		),
+	)
+}
+
+func (h *Handlers) searchResources(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Organization organization.Name `schema:"organization_name,required"`
+		Query        string            `schema:"search"`
+	}
+	if err := decode.All(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	props := searchResourcesProps{query: params.Query}
+	if params.Query != "" {
+		results, err := h.client.SearchResources(r.Context(), params.Organization, params.Query)
+		if err != nil {
+			helpers.Error(r, w, err.Error())
+			return
+		}
+		props.results = results
+	}
+
+	helpers.RenderPage(
+		searchResources(props),
+		"resources",
+		w, r,
+		helpers.WithOrganization(params.Organization),
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "Resources"},
+		),
+	)
+}
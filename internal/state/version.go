@@ -41,6 +41,12 @@ type (
 		Status      Status             `jsonapi:"attribute" json:"status"`
 		Outputs     map[string]*Output `jsonapi:"attribute" json:"outputs"`
 		WorkspaceID resource.TfeID     `jsonapi:"attribute" json:"workspace-id"`
+		// RunID is the ID of the run that created the state version. Nil if
+		// the state version was not created by a run.
+		RunID *resource.TfeID `jsonapi:"attribute" json:"run-id"`
+		// ResourcesProcessed is true once the resource instances in the state
+		// have been indexed.
+		ResourcesProcessed bool `jsonapi:"attribute" json:"resources-processed"`
 	}
 
 	Output struct {
@@ -70,6 +76,7 @@ type (
 
 		createVersion(context.Context, *Version) error
 		createOutputs(context.Context, []*Output) error
+		createInstances(context.Context, resource.TfeID, []*Instance) error
 		getVersion(ctx context.Context, svID resource.ID) (*Version, error)
 		getCurrentVersion(ctx context.Context, workspaceID resource.TfeID) (*Version, error)
 		updateCurrentVersion(context.Context, resource.TfeID, resource.TfeID) error
@@ -144,6 +151,10 @@ func (f *factory) uploadStateAndOutputs(ctx context.Context, sv *Version, state
 	if err := json.Unmarshal(state, &file); err != nil {
 		return nil, err
 	}
+	instances, err := file.Instances(sv.ID)
+	if err != nil {
+		return nil, err
+	}
 	outputs := make(map[string]*Output, len(file.Outputs))
 	for k, v := range file.Outputs {
 		typ, err := v.Type()
@@ -160,13 +171,16 @@ func (f *factory) uploadStateAndOutputs(ctx context.Context, sv *Version, state
 		}
 	}
 	// now perform database updates
-	err := f.db.Tx(ctx, func(ctx context.Context) (err error) {
+	err = f.db.Tx(ctx, func(ctx context.Context) (err error) {
 		if sv.Status != Pending {
 			return ErrUploadNonPending
 		}
 		if err := f.db.createOutputs(ctx, maps.Values(outputs)); err != nil {
 			return fmt.Errorf("creating outputs: %w", err)
 		}
+		if err := f.db.createInstances(ctx, sv.ID, instances); err != nil {
+			return fmt.Errorf("creating resource instances: %w", err)
+		}
 		if err := f.db.uploadStateAndFinalize(ctx, sv.ID, state); err != nil {
 			return fmt.Errorf("uploading state: %w", err)
 		}
@@ -181,6 +195,7 @@ func (f *factory) uploadStateAndOutputs(ctx context.Context, sv *Version, state
 	// ensure state version reflects changes made via database.
 	sv.Status = Finalized
 	sv.Outputs = outputs
+	sv.ResourcesProcessed = true
 	return sv, err
 }
 
@@ -73,6 +73,7 @@ func (a *TFEAPI) createTeam(w http.ResponseWriter, r *http.Request) {
 			ManageProviders:       params.OrganizationAccess.ManageProviders,
 			ManagePolicies:        params.OrganizationAccess.ManagePolicies,
 			ManagePolicyOverrides: params.OrganizationAccess.ManagePolicyOverrides,
+			ManageProjects:        params.OrganizationAccess.ManageProjects,
 		}
 	}
 
@@ -111,6 +112,7 @@ func (a *TFEAPI) updateTeam(w http.ResponseWriter, r *http.Request) {
 			ManageProviders:       params.OrganizationAccess.ManageProviders,
 			ManagePolicies:        params.OrganizationAccess.ManagePolicies,
 			ManagePolicyOverrides: params.OrganizationAccess.ManagePolicyOverrides,
+			ManageProjects:        params.OrganizationAccess.ManageProjects,
 		}
 	}
 
@@ -207,6 +209,7 @@ func (a *TFEAPI) convertTeam(from *team.Team) *team.TFETeam {
 			ManageProviders:       from.ManageProviders,
 			ManagePolicies:        from.ManagePolicies,
 			ManagePolicyOverrides: from.ManagePolicyOverrides,
+			ManageProjects:        from.ManageProjects,
 		},
 		// Hardcode these values until proper support is added
 		Permissions: &team.TFETeamPermissions{
@@ -32,6 +32,7 @@ INSERT INTO teams (
     permission_manage_vcs,
     permission_manage_modules,
     permission_manage_providers,
+    permission_manage_projects,
     permission_manage_policies,
     permission_manage_policy_overrides
 ) VALUES (
@@ -45,6 +46,7 @@ INSERT INTO teams (
     @permission_manage_vcs,
     @permission_manage_modules,
     @permission_manage_providers,
+    @permission_manage_projects,
     @permission_manage_policies,
     @permission_manage_policy_overrides
 )
@@ -62,6 +64,7 @@ INSERT INTO teams (
 			"permission_manage_providers":        team.ManageProviders,
 			"permission_manage_policies":         team.ManagePolicies,
 			"permission_manage_policy_overrides": team.ManagePolicyOverrides,
+			"permission_manage_projects":         team.ManageProjects,
 		},
 	)
 	return err
@@ -73,7 +76,7 @@ func (db *pgdb) UpdateTeam(ctx context.Context, teamID resource.ID, fn func(cont
 		db.DB,
 		func(ctx context.Context) (*Team, error) {
 			rows := db.Query(ctx, `
-SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers
+SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers, permission_manage_projects
 FROM teams t
 WHERE team_id = $1
 FOR UPDATE OF t
@@ -93,7 +96,8 @@ SET
     permission_manage_modules = @permission_manage_modules,
     permission_manage_providers = @permission_manage_providers,
     permission_manage_policies = @permission_manage_policies,
-    permission_manage_policy_overrides = @permission_manage_policy_overrides
+    permission_manage_policy_overrides = @permission_manage_policy_overrides,
+    permission_manage_projects = @permission_manage_projects
 WHERE team_id = @id
 RETURNING team_id
 `,
@@ -108,6 +112,7 @@ RETURNING team_id
 					"permission_manage_providers":        team.ManageProviders,
 					"permission_manage_policies":         team.ManagePolicies,
 					"permission_manage_policy_overrides": team.ManagePolicyOverrides,
+					"permission_manage_projects":         team.ManageProjects,
 				},
 			)
 			return err
@@ -117,7 +122,7 @@ RETURNING team_id
 
 func (db *pgdb) getTeam(ctx context.Context, name string, organization organization.Name) (*Team, error) {
 	rows := db.Query(ctx, `
-SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers
+SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers, permission_manage_projects
 FROM teams
 WHERE name              = $1
 AND   organization_name = $2
@@ -127,7 +132,7 @@ AND   organization_name = $2
 
 func (db *pgdb) getTeamByID(ctx context.Context, id resource.ID) (*Team, error) {
 	rows := db.Query(ctx, `
-SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers
+SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers, permission_manage_projects
 FROM teams
 WHERE team_id = $1
 `, id)
@@ -136,7 +141,7 @@ WHERE team_id = $1
 
 func (db *pgdb) getTeamByTokenID(ctx context.Context, tokenID resource.TfeID) (*Team, error) {
 	rows := db.Query(ctx, `
-SELECT t.team_id, t.name, t.created_at, t.permission_manage_workspaces, t.permission_manage_vcs, t.permission_manage_modules, t.organization_name, t.sso_team_id, t.visibility, t.permission_manage_policies, t.permission_manage_policy_overrides, t.permission_manage_providers
+SELECT t.team_id, t.name, t.created_at, t.permission_manage_workspaces, t.permission_manage_vcs, t.permission_manage_modules, t.organization_name, t.sso_team_id, t.visibility, t.permission_manage_policies, t.permission_manage_policy_overrides, t.permission_manage_providers, t.permission_manage_projects
 FROM teams t
 JOIN team_tokens tt USING (team_id)
 WHERE tt.team_token_id = $1
@@ -146,7 +151,7 @@ WHERE tt.team_token_id = $1
 
 func (db *pgdb) listTeams(ctx context.Context, organization organization.Name) ([]*Team, error) {
 	rows := db.Query(ctx, `
-SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers
+SELECT team_id, name, created_at, permission_manage_workspaces, permission_manage_vcs, permission_manage_modules, organization_name, sso_team_id, visibility, permission_manage_policies, permission_manage_policy_overrides, permission_manage_providers, permission_manage_projects
 FROM teams
 WHERE organization_name = $1
 `, organization)
@@ -196,7 +201,7 @@ INSERT INTO team_tokens (
 func (db *pgdb) getTeamTokenByTeamID(ctx context.Context, teamID resource.TfeID) (*Token, error) {
 	// query only returns 0 or 1 tokens
 	rows := db.Query(ctx, `
-SELECT *
+SELECT team_token_id, description, created_at, team_id, expiry
 FROM team_tokens
 WHERE team_id = $1
 `, teamID)
@@ -232,6 +237,7 @@ type Model struct {
 	ManagePolicies        bool `db:"permission_manage_policies"`
 	ManagePolicyOverrides bool `db:"permission_manage_policy_overrides"`
 	ManageProviders       bool `db:"permission_manage_providers"`
+	ManageProjects        bool `db:"permission_manage_projects"`
 }
 
 func (m Model) ToTeam() *Team {
@@ -247,6 +253,7 @@ func (m Model) ToTeam() *Team {
 		ManagePolicies:        m.ManagePolicies,
 		ManagePolicyOverrides: m.ManagePolicyOverrides,
 		ManageProviders:       m.ManageProviders,
+		ManageProjects:        m.ManageProjects,
 		Visibility:            m.Visibility,
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
@@ -25,6 +26,7 @@ type (
 		*authz.Authorizer
 
 		db               *pgdb
+		audit            audit.Recorder
 		afterCreateHooks []func(context.Context, *Team) error
 
 		*teamTokenFactory
@@ -37,6 +39,7 @@ type (
 		OrganizationService *organization.Service
 		TokensService       *tokens.Service
 		Authorizer          *authz.Authorizer
+		AuditRecorder       audit.Recorder
 	}
 )
 
@@ -45,6 +48,7 @@ func NewService(opts Options) *Service {
 		Logger:     opts.Logger,
 		Authorizer: opts.Authorizer,
 		db:         &pgdb{opts.DB, opts.Logger},
+		audit:      opts.AuditRecorder,
 		teamTokenFactory: &teamTokenFactory{
 			tokens: opts.TokensService,
 		},
@@ -112,6 +116,12 @@ func (a *Service) CreateTeam(ctx context.Context, organization organization.Name
 		return nil, err
 	}
 	a.V(0).Info("created team", "name", team.Name, "organization", organization, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: organization,
+		Action:       resource.Create,
+		ResourceID:   team.ID,
+		After:        team,
+	})
 
 	return team, nil
 }
@@ -131,7 +141,9 @@ func (a *Service) UpdateTeam(ctx context.Context, teamID resource.TfeID, opts Up
 		return nil, err
 	}
 
+	var before *Team
 	team, err = a.db.UpdateTeam(ctx, teamID, func(ctx context.Context, team *Team) error {
+		before = new(*team)
 		return team.Update(opts)
 	})
 	if err != nil {
@@ -140,6 +152,13 @@ func (a *Service) UpdateTeam(ctx context.Context, teamID resource.TfeID, opts Up
 	}
 
 	a.V(2).Info("updated team", "name", team.Name, "organization", team.Organization, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: team.Organization,
+		Action:       resource.Update,
+		ResourceID:   team.ID,
+		Before:       before,
+		After:        team,
+	})
 
 	return team, nil
 }
@@ -218,6 +237,12 @@ func (a *Service) DeleteTeam(ctx context.Context, teamID resource.TfeID) error {
 	}
 
 	a.V(2).Info("deleted team", "team", team.Name, "organization", team.Organization, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: team.Organization,
+		Action:       resource.Delete,
+		ResourceID:   team.ID,
+		Before:       team,
+	})
 
 	return nil
 }
@@ -17,9 +17,12 @@ type (
 		Name      string         `jsonapi:"attribute" json:"name" db:"name"`
 		CreatedAt time.Time      `jsonapi:"attribute" json:"created-at" db:"created_at"`
 
-		ManageWorkspaces bool `db:"permission_manage_workspaces"` // admin access on all workspaces
-		ManageVCS        bool `db:"permission_manage_vcs"`        // manage VCS providers
-		ManageModules    bool `db:"permission_manage_modules"`    // manage module registry
+		ManageWorkspaces      bool `db:"permission_manage_workspaces"`       // admin access on all workspaces
+		ManageVCS             bool `db:"permission_manage_vcs"`              // manage VCS providers
+		ManageModules         bool `db:"permission_manage_modules"`          // manage module registry
+		ManagePolicies        bool `db:"permission_manage_policies"`         // manage policy sets
+		ManagePolicyOverrides bool `db:"permission_manage_policy_overrides"` // override failed policy checks
+		ManageProjects        bool `db:"permission_manage_projects"`         // manage projects
 
 		Organization organization.Name `jsonapi:"attribute" json:"organization" db:"organization_name"`
 
@@ -30,9 +33,7 @@ type (
 
 		// TFE fields that OTF does not support but persists merely to pass the
 		// go-tfe integration tests
-		ManagePolicies        bool `db:"permission_manage_policies"`
-		ManagePolicyOverrides bool `db:"permission_manage_policy_overrides"`
-		ManageProviders       bool `db:"permission_manage_providers"`
+		ManageProviders bool `db:"permission_manage_providers"`
 	}
 
 	CreateTeamOptions struct {
@@ -66,15 +67,16 @@ type (
 	// OrganizationAccessOptions defines access to be granted upon team creation
 	// or to grant/rescind to/from an existing team.
 	OrganizationAccessOptions struct {
-		ManageWorkspaces *bool
-		ManageVCS        *bool
-		ManageModules    *bool
+		ManageWorkspaces      *bool
+		ManageVCS             *bool
+		ManageModules         *bool
+		ManagePolicies        *bool
+		ManagePolicyOverrides *bool
+		ManageProjects        *bool
 
 		// TFE fields that OTF does not support but persists merely to pass the
 		// go-tfe integration tests
-		ManageProviders       *bool
-		ManagePolicies        *bool
-		ManagePolicyOverrides *bool
+		ManageProviders *bool
 	}
 )
 
@@ -114,10 +116,14 @@ func NewTeam(organization organization.Name, opts CreateTeamOptions) (*Team, err
 	if opts.ManagePolicyOverrides != nil {
 		team.ManagePolicyOverrides = *opts.ManagePolicyOverrides
 	}
+	if opts.ManageProjects != nil {
+		team.ManageProjects = *opts.ManageProjects
+	}
 	return team, nil
 }
 
-func (t *Team) String() string { return t.Name }
+func (t *Team) String() string        { return t.Name }
+func (t *Team) GetID() resource.TfeID { return t.ID }
 
 func (t *Team) IsOwners() bool {
 	return t.Name == "owners"
@@ -158,6 +164,21 @@ func (t *Team) CanAccess(action resource.Action, kind resource.Kind, req authz.R
 			return true
 		}
 	}
+	if t.ManagePolicies {
+		if authz.PolicyManagerRole.IsAllowed(action, kind) {
+			return true
+		}
+	}
+	if t.ManagePolicyOverrides {
+		if authz.PolicyOverrideRole.IsAllowed(action, kind) {
+			return true
+		}
+	}
+	if t.ManageProjects {
+		if authz.ProjectManagerRole.IsAllowed(action, kind) {
+			return true
+		}
+	}
 	if req.ID != nil && req.ID.Kind() == resource.TeamKind {
 		// team can access self
 		return t.ID == req.ID
@@ -196,5 +217,8 @@ func (t *Team) Update(opts UpdateTeamOptions) error {
 	if opts.ManagePolicyOverrides != nil {
 		t.ManagePolicyOverrides = *opts.ManagePolicyOverrides
 	}
+	if opts.ManageProjects != nil {
+		t.ManageProjects = *opts.ManageProjects
+	}
 	return nil
 }
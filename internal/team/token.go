@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/tokens"
 )
@@ -82,6 +83,12 @@ func (a *Service) CreateTeamToken(ctx context.Context, opts CreateTokenOptions)
 	}
 
 	a.V(0).Info("created team token", "token", tt)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Parent:     tt.TeamID,
+		Action:     resource.Create,
+		ResourceID: tt.ID,
+		After:      tt.LogValue(),
+	})
 
 	return tt, token, nil
 }
@@ -105,12 +112,23 @@ func (a *Service) DeleteTeamToken(ctx context.Context, teamID resource.TfeID) er
 		return err
 	}
 
+	tt, err := a.db.getTeamTokenByTeamID(ctx, teamID)
+	if err != nil {
+		a.Error(err, "retrieving team token", "team_id", teamID)
+		return err
+	}
 	if err := a.db.deleteTeamToken(ctx, teamID); err != nil {
 		a.Error(err, "deleting team token", "team", teamID)
 		return err
 	}
 
 	a.V(0).Info("deleted team token", "team", teamID)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Parent:     teamID,
+		Action:     resource.Delete,
+		ResourceID: tt.ID,
+		Before:     tt.LogValue(),
+	})
 
 	return nil
 }
@@ -87,10 +87,13 @@ func (h *Handlers) createTeam(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handlers) updateTeam(w http.ResponseWriter, r *http.Request) {
 	var params struct {
-		TeamID           resource.TfeID `schema:"team_id,required"`
-		ManageWorkspaces bool           `schema:"manage_workspaces"`
-		ManageVCS        bool           `schema:"manage_vcs"`
-		ManageModules    bool           `schema:"manage_modules"`
+		TeamID                resource.TfeID `schema:"team_id,required"`
+		ManageWorkspaces      bool           `schema:"manage_workspaces"`
+		ManageVCS             bool           `schema:"manage_vcs"`
+		ManageModules         bool           `schema:"manage_modules"`
+		ManagePolicies        bool           `schema:"manage_policies"`
+		ManagePolicyOverrides bool           `schema:"manage_policy_overrides"`
+		ManageProjects        bool           `schema:"manage_projects"`
 	}
 	if err := decode.All(&params, r); err != nil {
 		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
@@ -99,9 +102,12 @@ func (h *Handlers) updateTeam(w http.ResponseWriter, r *http.Request) {
 
 	updatedTeam, err := h.Client.UpdateTeam(r.Context(), params.TeamID, team.UpdateTeamOptions{
 		OrganizationAccessOptions: team.OrganizationAccessOptions{
-			ManageWorkspaces: &params.ManageWorkspaces,
-			ManageVCS:        &params.ManageVCS,
-			ManageModules:    &params.ManageModules,
+			ManageWorkspaces:      &params.ManageWorkspaces,
+			ManageVCS:             &params.ManageVCS,
+			ManageModules:         &params.ManageModules,
+			ManagePolicies:        &params.ManagePolicies,
+			ManagePolicyOverrides: &params.ManagePolicyOverrides,
+			ManageProjects:        &params.ManageProjects,
 		},
 	})
 	if err != nil {
@@ -19,6 +19,8 @@ const (
 	APIPrefixV2 = "/api/v2/"
 	// ModuleV1Prefix is the URL path prefix for module registry endpoints
 	ModuleV1Prefix = "/v1/modules/"
+	// ProviderV1Prefix is the URL path prefix for provider registry endpoints
+	ProviderV1Prefix = "/v1/providers/"
 )
 
 // errUnmarshal wraps errors resulting from a failure to unmarshal request
@@ -0,0 +1,189 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
+)
+
+// usageInterval is the minimum interval between recording usage of a token, to
+// avoid writing to the database on every request.
+const usageInterval = time.Minute
+
+// allTokens is a common table expression combining all kinds of persisted
+// tokens into one table.
+const allTokens = `
+WITH all_tokens AS (
+    SELECT token_id, created_at, description, username AS owner, NULL::timestamptz AS expiry, last_used_at, last_used_ip
+    FROM tokens
+    UNION ALL
+    SELECT tt.team_token_id, tt.created_at, '', t.organization_name || '/' || t.name, tt.expiry, tt.last_used_at, tt.last_used_ip
+    FROM team_tokens tt
+    JOIN teams t USING (team_id)
+    UNION ALL
+    SELECT organization_token_id, created_at, '', organization_name, expiry, last_used_at, last_used_ip
+    FROM organization_tokens
+    UNION ALL
+    SELECT at.agent_token_id, at.created_at, at.description, ap.organization_name || '/' || ap.name, NULL, at.last_used_at, at.last_used_ip
+    FROM agent_tokens at
+    JOIN agent_pools ap USING (agent_pool_id)
+)
+`
+
+type pgdb struct {
+	*sql.DB
+}
+
+func (db *pgdb) list(ctx context.Context, opts ListOptions) (*resource.Page[*Token], error) {
+	var kind *string
+	if opts.Kind != nil {
+		kind = new(opts.Kind.String())
+	}
+	args := pgx.NamedArgs{
+		"kind":   kind,
+		"limit":  sql.GetLimit(opts.PageOptions),
+		"offset": sql.GetOffset(opts.PageOptions),
+	}
+	rows := db.Query(ctx, allTokens+`
+SELECT *
+FROM all_tokens
+WHERE (@kind::text IS NULL OR split_part(token_id, '-', 1) = @kind)
+ORDER BY created_at DESC
+LIMIT @limit::int
+OFFSET @offset::int
+`, args)
+	items, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Token])
+	if err != nil {
+		return nil, err
+	}
+	count, err := db.Int(ctx, allTokens+`
+SELECT count(*)
+FROM all_tokens
+WHERE (@kind::text IS NULL OR split_part(token_id, '-', 1) = @kind)
+`, args)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewPage(items, opts.PageOptions, &count), nil
+}
+
+// listUnusedSince lists tokens that have not been used since the given time,
+// including tokens created before that time and never used.
+func (db *pgdb) listUnusedSince(ctx context.Context, since time.Time) ([]*Token, error) {
+	rows := db.Query(ctx, allTokens+`
+SELECT *
+FROM all_tokens
+WHERE coalesce(last_used_at, created_at) < $1
+`, since)
+	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Token])
+}
+
+// recordUsage records when and from where a token was used. Usage is recorded
+// no more than once every usageInterval.
+func (db *pgdb) recordUsage(ctx context.Context, id resource.TfeID, usedAt time.Time, ip *string) error {
+	kind, ok := persistedKinds[id.Kind()]
+	if !ok {
+		return nil
+	}
+	_, err := db.Exec(ctx, fmt.Sprintf(`
+UPDATE %s
+SET last_used_at = $2,
+    last_used_ip = $3
+WHERE %s = $1
+AND (last_used_at IS NULL OR last_used_at < $4)
+`, kind.table, kind.idColumn), id, usedAt, ip, usedAt.Add(-usageInterval))
+	if errors.Is(err, internal.ErrResourceNotFound) {
+		// Usage was recorded recently.
+		return nil
+	}
+	return err
+}
+
+// revoke adds tokens to the revocation list and deletes them. The
+// organizations to which each token pertained are returned, keyed by token ID.
+func (db *pgdb) revoke(ctx context.Context, ids []resource.TfeID, revokedAt time.Time) (map[resource.TfeID][]string, error) {
+	organizations := make(map[resource.TfeID][]string, len(ids))
+	err := db.Tx(ctx, func(ctx context.Context) error {
+		for _, id := range ids {
+			kind, ok := persistedKinds[id.Kind()]
+			if !ok {
+				return fmt.Errorf("cannot revoke token of kind: %s", id.Kind())
+			}
+			orgs, err := db.listOrganizations(ctx, id)
+			if err != nil {
+				return fmt.Errorf("listing organizations of token %s: %w", id, err)
+			}
+			organizations[id] = orgs
+			_, err = db.Exec(ctx, `
+INSERT INTO revoked_tokens (
+    token_id,
+    revoked_at
+) VALUES (
+    $1,
+    $2
+) ON CONFLICT (token_id) DO NOTHING
+`, id, revokedAt)
+			if err != nil && !errors.Is(err, internal.ErrResourceNotFound) {
+				return err
+			}
+			_, err = db.Exec(ctx, fmt.Sprintf(`
+DELETE
+FROM %s
+WHERE %s = $1
+`, kind.table, kind.idColumn), id)
+			// The token may have been deleted since it was selected for
+			// revocation, in which case it is still added to the revocation
+			// list.
+			if err != nil && !errors.Is(err, internal.ErrResourceNotFound) {
+				return fmt.Errorf("deleting token %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// listOrganizations lists the names of the organizations to which a token
+// pertains: the organization of a team, organization or agent token, or the
+// organizations of which the owner of a user token is a member.
+func (db *pgdb) listOrganizations(ctx context.Context, id resource.TfeID) ([]string, error) {
+	rows := db.Query(ctx, `
+SELECT t.organization_name
+FROM tokens
+JOIN team_memberships USING (username)
+JOIN teams t USING (team_id)
+WHERE token_id = $1
+UNION
+SELECT t.organization_name
+FROM team_tokens tt
+JOIN teams t USING (team_id)
+WHERE tt.team_token_id = $1
+UNION
+SELECT organization_name
+FROM organization_tokens
+WHERE organization_token_id = $1
+UNION
+SELECT ap.organization_name
+FROM agent_tokens at
+JOIN agent_pools ap USING (agent_pool_id)
+WHERE at.agent_token_id = $1
+`, id)
+	return sql.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (db *pgdb) listRevoked(ctx context.Context) ([]resource.TfeID, error) {
+	rows := db.Query(ctx, `
+SELECT token_id
+FROM revoked_tokens
+`)
+	return sql.CollectRows(rows, pgx.RowTo[resource.TfeID])
+}
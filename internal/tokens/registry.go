@@ -2,16 +2,30 @@ package tokens
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/authz"
+	otfhttp "github.com/leg100/otf/internal/http"
+	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
+// ErrRevokedToken is returned when authenticating with a revoked token.
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// revocationListTTL is how long the revocation list is cached before it is
+// reloaded from the database. A token revoked via another otfd node is
+// rejected by this node within this duration.
+const revocationListTTL = 10 * time.Second
+
 type (
 	// registry provides a means of registering different kinds of tokens with the
 	// authentication middleware, e.g. user tokens, team tokens, etc.
@@ -22,9 +36,24 @@ type (
 	// of the different types of subjects - organizations, teams, users, etc, - to
 	// other packages.
 	registry struct {
-		kinds map[resource.Kind]SubjectGetter
-		mu    sync.Mutex
-		key   jwk.Key
+		kinds  map[resource.Kind]SubjectGetter
+		mu     sync.Mutex
+		key    jwk.Key
+		db     registryDB
+		logger logr.Logger
+
+		// revoked caches the revocation list, avoiding a database query for
+		// every authenticated request.
+		revoked       map[resource.TfeID]struct{}
+		revokedLoaded time.Time
+		revokedMu     sync.Mutex
+	}
+
+	// registryDB retrieves the revocation list and records the usage of
+	// tokens.
+	registryDB interface {
+		listRevoked(ctx context.Context) ([]resource.TfeID, error)
+		recordUsage(ctx context.Context, id resource.TfeID, usedAt time.Time, ip *string) error
 	}
 
 	// SubjectGetter retrieves an OTF subject given the jwtSubject string, which is the
@@ -51,12 +80,82 @@ func (r *registry) GetSubject(ctx context.Context, token []byte) (authz.Subject,
 		return nil, err
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	revoked, err := r.isRevoked(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("checking token revocation list: %w", err)
+	}
+	if revoked {
+		return nil, ErrRevokedToken
+	}
 
+	r.mu.Lock()
 	subjectGetter, ok := r.kinds[id.Kind()]
+	r.mu.Unlock()
 	if !ok {
 		return nil, fmt.Errorf("unknown authentication token kind: %s", id.Kind())
 	}
-	return subjectGetter(ctx, id)
+	subject, err := subjectGetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to record usage of the token should not prevent authentication.
+	var ip *string
+	if addr, ok := otfhttp.ClientIPFromContext(ctx); ok {
+		ip = &addr
+	}
+	if err := r.db.recordUsage(ctx, id, internal.CurrentTimestamp(nil), ip); err != nil {
+		r.logger.Error(err, "recording token usage", "token", id)
+	}
+	// Restrict the subject's access if the token is scoped.
+	if claim, ok := parsed.Get(scopeClaim); ok {
+		scope, err := parseScope(claim)
+		if err != nil {
+			return nil, fmt.Errorf("parsing token scope: %w", err)
+		}
+		return &authz.ScopedSubject{Subject: subject, Scope: scope}, nil
+	}
+	return subject, nil
+}
+
+// isRevoked determines whether a token is on the revocation list, reloading the
+// cached list if it has expired.
+func (r *registry) isRevoked(ctx context.Context, id resource.TfeID) (bool, error) {
+	r.revokedMu.Lock()
+	defer r.revokedMu.Unlock()
+
+	if r.revoked == nil || time.Since(r.revokedLoaded) > revocationListTTL {
+		ids, err := r.db.listRevoked(ctx)
+		if err != nil {
+			return false, err
+		}
+		r.revoked = make(map[resource.TfeID]struct{}, len(ids))
+		for _, id := range ids {
+			r.revoked[id] = struct{}{}
+		}
+		r.revokedLoaded = time.Now()
+	}
+	_, ok := r.revoked[id]
+	return ok, nil
+}
+
+// invalidateRevoked discards the cached revocation list, forcing it to be
+// reloaded upon the next authentication.
+func (r *registry) invalidateRevoked() {
+	r.revokedMu.Lock()
+	r.revoked = nil
+	r.revokedMu.Unlock()
+}
+
+// parseScope parses the value of a scope claim, which has been unmarshaled from
+// JSON into a generic value.
+func parseScope(claim any) (authz.Scope, error) {
+	b, err := json.Marshal(claim)
+	if err != nil {
+		return authz.Scope{}, err
+	}
+	var scope authz.Scope
+	if err := json.Unmarshal(b, &scope); err != nil {
+		return authz.Scope{}, err
+	}
+	return scope, nil
 }
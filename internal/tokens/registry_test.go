@@ -0,0 +1,122 @@
+package tokens
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/logr"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_GetSubject(t *testing.T) {
+	db := &fakeRegistryDB{}
+	svc, err := NewService(Options{Logger: logr.Discard(), Secret: []byte("abcdef123")})
+	require.NoError(t, err)
+	svc.registry.db = db
+
+	want := &authz.Superuser{Username: "bobby"}
+	svc.RegisterKind(resource.UserTokenKind, func(context.Context, resource.TfeID) (authz.Subject, error) {
+		return want, nil
+	})
+	tokenID := resource.NewTfeID(resource.UserTokenKind)
+
+	t.Run("unscoped", func(t *testing.T) {
+		token, err := svc.NewToken(tokenID, nil)
+		require.NoError(t, err)
+
+		got, err := svc.GetSubject(t.Context(), token)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("scoped", func(t *testing.T) {
+		scope := authz.Scope{
+			Actions:    []resource.Action{resource.Get},
+			Kinds:      []resource.Kind{resource.WorkspaceKind},
+			Workspaces: []resource.TfeID{resource.NewTfeID(resource.WorkspaceKind)},
+			Tags:       []string{"dev"},
+			CIDRs:      []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		}
+		token, err := svc.NewToken(tokenID, nil, WithScope(scope))
+		require.NoError(t, err)
+
+		got, err := svc.GetSubject(t.Context(), token)
+		require.NoError(t, err)
+		assert.Equal(t, &authz.ScopedSubject{Subject: want, Scope: scope}, got)
+	})
+
+	t.Run("records usage", func(t *testing.T) {
+		token, err := svc.NewToken(tokenID, nil)
+		require.NoError(t, err)
+
+		_, err = svc.GetSubject(t.Context(), token)
+		require.NoError(t, err)
+		assert.Equal(t, tokenID, db.used)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		token, err := svc.NewToken(tokenID, nil)
+		require.NoError(t, err)
+
+		db.revoked = tokenID
+		svc.invalidateRevoked()
+		_, err = svc.GetSubject(t.Context(), token)
+		assert.ErrorIs(t, err, ErrRevokedToken)
+	})
+}
+
+func TestRegistry_RevocationListCache(t *testing.T) {
+	db := &fakeRegistryDB{}
+	r := &registry{db: db}
+	tokenID := resource.NewTfeID(resource.UserTokenKind)
+
+	revoked, err := r.isRevoked(t.Context(), tokenID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Equal(t, 1, db.loads)
+
+	// The revocation list is cached.
+	db.revoked = tokenID
+	revoked, err = r.isRevoked(t.Context(), tokenID)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Equal(t, 1, db.loads)
+
+	// The revocation list is reloaded once it expires...
+	r.revokedLoaded = time.Now().Add(-revocationListTTL - time.Second)
+	revoked, err = r.isRevoked(t.Context(), tokenID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, 2, db.loads)
+
+	// ...or once it is invalidated.
+	r.invalidateRevoked()
+	_, err = r.isRevoked(t.Context(), tokenID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, db.loads)
+}
+
+type fakeRegistryDB struct {
+	revoked resource.TfeID
+	used    resource.TfeID
+	// loads is the number of times the revocation list has been retrieved.
+	loads int
+}
+
+func (f *fakeRegistryDB) listRevoked(context.Context) ([]resource.TfeID, error) {
+	f.loads++
+	if f.revoked == (resource.TfeID{}) {
+		return nil, nil
+	}
+	return []resource.TfeID{f.revoked}, nil
+}
+
+func (f *fakeRegistryDB) recordUsage(_ context.Context, id resource.TfeID, _ time.Time, _ *string) error {
+	f.used = id
+	return nil
+}
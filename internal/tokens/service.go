@@ -1,8 +1,15 @@
 package tokens
 
 import (
+	"context"
+	"time"
+
+	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
+	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/sql"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 )
 
@@ -14,18 +21,34 @@ type (
 	Service struct {
 		*registry
 		*factory
-		logger logr.Logger
+		logger     logr.Logger
+		authorizer *authz.Authorizer
+		db         *pgdb
+		audit      audit.Recorder
 	}
 
 	Options struct {
-		Logger logr.Logger
-		Secret []byte
+		Logger        logr.Logger
+		Secret        []byte
+		DB            *sql.DB
+		Authorizer    *authz.Authorizer
+		AuditRecorder audit.Recorder
 	}
 )
 
+// organizationName identifies an organization by its name. The organization
+// package is not used because it depends upon this package.
+type organizationName string
+
+func (n organizationName) String() string    { return string(n) }
+func (organizationName) Kind() resource.Kind { return resource.OrganizationKind }
+
 func NewService(opts Options) (*Service, error) {
 	svc := Service{
-		logger: opts.Logger,
+		logger:     opts.Logger,
+		authorizer: opts.Authorizer,
+		db:         &pgdb{opts.DB},
+		audit:      opts.AuditRecorder,
 	}
 	key, err := jwk.FromRaw([]byte(opts.Secret))
 	if err != nil {
@@ -33,8 +56,72 @@ func NewService(opts Options) (*Service, error) {
 	}
 	svc.factory = &factory{key: key}
 	svc.registry = &registry{
-		kinds: make(map[resource.Kind]SubjectGetter),
-		key:   key,
+		kinds:  make(map[resource.Kind]SubjectGetter),
+		key:    key,
+		db:     svc.db,
+		logger: opts.Logger,
 	}
 	return &svc, nil
 }
+
+// ListTokens lists tokens of all kinds across the site. Only the site admin
+// can list tokens.
+func (s *Service) ListTokens(ctx context.Context, opts ListOptions) (*resource.Page[*Token], error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.List, resource.TokenKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.db.list(ctx, opts)
+	if err != nil {
+		s.logger.Error(err, "listing tokens", "subject", subject)
+		return nil, err
+	}
+	s.logger.V(9).Info("listed tokens", "count", len(page.Items), "subject", subject)
+	return page, nil
+}
+
+// ListTokensUnusedSince lists tokens of all kinds that have not been used since
+// the given time. Only the site admin can list tokens.
+func (s *Service) ListTokensUnusedSince(ctx context.Context, since time.Time) ([]*Token, error) {
+	subject, err := s.authorizer.Authorize(ctx, resource.List, resource.TokenKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.db.listUnusedSince(ctx, since)
+	if err != nil {
+		s.logger.Error(err, "listing unused tokens", "since", since, "subject", subject)
+		return nil, err
+	}
+	s.logger.V(9).Info("listed unused tokens", "since", since, "count", len(tokens), "subject", subject)
+	return tokens, nil
+}
+
+// RevokeTokens revokes tokens, adding them to the revocation list and deleting
+// them. Only the site admin can revoke tokens.
+func (s *Service) RevokeTokens(ctx context.Context, ids []resource.TfeID) error {
+	subject, err := s.authorizer.Authorize(ctx, resource.Delete, resource.TokenKind, resource.SiteID)
+	if err != nil {
+		return err
+	}
+	organizations, err := s.db.revoke(ctx, ids, internal.CurrentTimestamp(nil))
+	if err != nil {
+		s.logger.Error(err, "revoking tokens", "tokens", ids, "subject", subject)
+		return err
+	}
+	// Reject the revoked tokens immediately rather than once the cached
+	// revocation list expires.
+	s.invalidateRevoked()
+	s.logger.V(0).Info("revoked tokens", "tokens", ids, "subject", subject)
+	// Record the revocation in the audit trail of each organization to which
+	// a token pertained.
+	for id, orgs := range organizations {
+		for _, org := range orgs {
+			s.audit.Record(ctx, audit.RecordOptions{
+				Organization: organizationName(org),
+				Action:       resource.Revoke,
+				ResourceID:   id,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+type (
+	// Token provides information about an API token of any kind, i.e. a user,
+	// team, organization or agent token.
+	Token struct {
+		ID          resource.TfeID `db:"token_id"`
+		CreatedAt   time.Time      `db:"created_at"`
+		Description string
+		// Owner identifies the entity to which the token belongs, e.g. a
+		// username, or the name of a team, organization or agent pool.
+		Owner string
+		// Optional expiry.
+		Expiry *time.Time
+		// LastUsedAt is when the token was last used to authenticate a
+		// request; nil if it has never been used.
+		LastUsedAt *time.Time `db:"last_used_at"`
+		// LastUsedIP is the IP address of the client that last used the token.
+		LastUsedIP *string `db:"last_used_ip"`
+	}
+
+	// ListOptions are options for listing tokens.
+	ListOptions struct {
+		resource.PageOptions
+		// Optionally filter tokens by kind.
+		Kind *resource.Kind `schema:"kind"`
+	}
+)
+
+func (t *Token) GetID() resource.TfeID { return t.ID }
+
+// Kind returns the kind of token.
+func (t *Token) Kind() resource.Kind { return t.ID.Kind() }
+
+// persistedKinds are the kinds of tokens that are persisted to the database,
+// mapped to their table and ID column. Other kinds of token, e.g. session
+// tokens, are not persisted.
+var persistedKinds = map[resource.Kind]struct {
+	table    string
+	idColumn string
+}{
+	resource.UserTokenKind:         {table: "tokens", idColumn: "token_id"},
+	resource.TeamTokenKind:         {table: "team_tokens", idColumn: "team_token_id"},
+	resource.OrganizationTokenKind: {table: "organization_tokens", idColumn: "organization_token_id"},
+	resource.AgentTokenKind:        {table: "agent_tokens", idColumn: "agent_token_id"},
+}
@@ -7,29 +7,48 @@ import (
 	"strings"
 	"time"
 
+	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
-// factory constructs new tokens using a JWK
-type factory struct {
-	key jwk.Key
+// scopeClaim is the name of the JWT claim containing the scope of a token.
+const scopeClaim = "scope"
+
+type (
+	// factory constructs new tokens using a JWK
+	factory struct {
+		key jwk.Key
+	}
+
+	// NewTokenOption is an option for constructing a new token.
+	NewTokenOption func(*jwt.Builder)
+)
+
+// WithClaim adds a custom claim to the token.
+func WithClaim(name string, value any) NewTokenOption {
+	return func(builder *jwt.Builder) {
+		builder.Claim(name, value)
+	}
+}
+
+// WithScope restricts the access granted by the token to the given scope.
+func WithScope(scope authz.Scope) NewTokenOption {
+	return WithClaim(scopeClaim, scope)
 }
 
-func (f *factory) NewToken(subjectID resource.TfeID, expiry *time.Time) ([]byte, error) {
+func (f *factory) NewToken(subjectID resource.TfeID, expiry *time.Time, opts ...NewTokenOption) ([]byte, error) {
 	builder := jwt.NewBuilder().
 		Subject(subjectID.String()).
 		IssuedAt(time.Now())
 	if expiry != nil {
 		builder = builder.Expiration(*expiry)
 	}
-	// TODO: permit caller to add claims
-	//
-	//for k, v := range opts.Claims {
-	//	builder = builder.Claim(k, v)
-	//}
+	for _, fn := range opts {
+		fn(builder)
+	}
 	token, err := builder.Build()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/http/decode"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tokens"
+	"github.com/leg100/otf/internal/ui/helpers"
+)
+
+type Handlers struct {
+	Client TokensService
+}
+
+type TokensService interface {
+	ListTokens(ctx context.Context, opts tokens.ListOptions) (*resource.Page[*tokens.Token], error)
+	RevokeTokens(ctx context.Context, ids []resource.TfeID) error
+}
+
+func (h *Handlers) AddHandlers(r *mux.Router) {
+	r.HandleFunc("/admin/tokens", h.listTokens).Methods("GET")
+	r.HandleFunc("/admin/tokens/revoke", h.revokeTokens).Methods("POST")
+}
+
+func (h *Handlers) listTokens(w http.ResponseWriter, r *http.Request) {
+	var opts tokens.ListOptions
+	if err := decode.All(&opts, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	page, err := h.Client.ListTokens(r.Context(), opts)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
+	helpers.RenderPage(
+		listTokens(listTokensProps{
+			page: page,
+			kind: opts.Kind,
+		}),
+		"all tokens",
+		w,
+		r,
+		helpers.WithBreadcrumbs(
+			helpers.Breadcrumb{Name: "All Tokens"},
+		),
+	)
+}
+
+func (h *Handlers) revokeTokens(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		TokenIDs []string `schema:"token_ids"`
+	}
+	if err := decode.Form(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	if len(params.TokenIDs) == 0 {
+		helpers.FlashError(w, "no tokens selected")
+		http.Redirect(w, r, path.SiteTokens(), http.StatusFound)
+		return
+	}
+	ids := make([]resource.TfeID, len(params.TokenIDs))
+	for i, s := range params.TokenIDs {
+		id, err := resource.ParseTfeID(s)
+		if err != nil {
+			helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+			return
+		}
+		ids[i] = id
+	}
+	if err := h.Client.RevokeTokens(r.Context(), ids); err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+	helpers.FlashSuccess(w, fmt.Sprintf("revoked %d token(s)", len(ids)))
+	http.Redirect(w, r, path.SiteTokens(), http.StatusFound)
+}
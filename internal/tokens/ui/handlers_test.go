@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/leg100/otf/internal/tokens"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens_WebHandlers(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		token := &tokens.Token{
+			ID:         resource.NewTfeID(resource.UserTokenKind),
+			CreatedAt:  time.Now(),
+			Owner:      "bobby",
+			LastUsedAt: new(time.Now()),
+			LastUsedIP: new("10.0.0.1"),
+		}
+		h := &Handlers{Client: &fakeClient{tokens: []*tokens.Token{token}}}
+
+		r := httptest.NewRequest("GET", "/?kind=ut", nil)
+		r = r.WithContext(authz.AddSubjectToContext(context.Background(), &authz.Superuser{}))
+		w := httptest.NewRecorder()
+		h.listTokens(w, r)
+		assert.Equal(t, 200, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "10.0.0.1")
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		client := &fakeClient{}
+		h := &Handlers{Client: client}
+		ids := []resource.TfeID{
+			resource.NewTfeID(resource.UserTokenKind),
+			resource.NewTfeID(resource.TeamTokenKind),
+		}
+
+		form := url.Values{"token_ids": {ids[0].String(), ids[1].String()}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.revokeTokens(w, r)
+		testutils.AssertRedirect(t, w, path.SiteTokens())
+		assert.Equal(t, ids, client.revoked)
+	})
+}
+
+type fakeClient struct {
+	tokens  []*tokens.Token
+	revoked []resource.TfeID
+}
+
+func (f *fakeClient) ListTokens(_ context.Context, opts tokens.ListOptions) (*resource.Page[*tokens.Token], error) {
+	return resource.NewPage(f.tokens, opts.PageOptions, nil), nil
+}
+
+func (f *fakeClient) RevokeTokens(_ context.Context, ids []resource.TfeID) error {
+	f.revoked = ids
+	return nil
+}
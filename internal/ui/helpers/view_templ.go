@@ -21,6 +21,8 @@ var RunStatusThemeMappings = map[runstatus.Status]string{
 	runstatus.Applying:           "accent",
 	runstatus.Canceled:           "warning",
 	runstatus.Confirmed:          "info",
+	runstatus.CostEstimating:     "primary",
+	runstatus.CostEstimated:      "info",
 	runstatus.Discarded:          "warning",
 	runstatus.Errored:            "error",
 	runstatus.ForceCanceled:      "warning",
@@ -29,6 +31,15 @@ var RunStatusThemeMappings = map[runstatus.Status]string{
 	runstatus.Planned:            "info",
 	runstatus.PlannedAndFinished: "success",
 	runstatus.Planning:           "primary",
+	runstatus.PolicyChecking:     "primary",
+	runstatus.PolicyChecked:      "info",
+	runstatus.PolicyOverride:     "warning",
+	runstatus.PrePlanRunning:     "primary",
+	runstatus.PrePlanCompleted:   "info",
+	runstatus.PostPlanRunning:    "primary",
+	runstatus.PostPlanCompleted:  "info",
+	runstatus.PreApplyRunning:    "primary",
+	runstatus.PreApplyCompleted:  "info",
 }
 
 func RunStatusBadge(runID resource.TfeID, status runstatus.Status) templ.Component {
@@ -64,7 +75,7 @@ func RunStatusBadge(runID resource.TfeID, status runstatus.Status) templ.Compone
 		var templ_7745c5c3_Var3 string
 		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.ResolveAttributeValue(runID.String() + "-status")
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 27, Col: 38}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 38, Col: 38}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var3)
 		if templ_7745c5c3_Err != nil {
@@ -90,7 +101,7 @@ func RunStatusBadge(runID resource.TfeID, status runstatus.Status) templ.Compone
 		var templ_7745c5c3_Var5 templ.SafeURL
 		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinURLErrs(path.Get(runID))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 28, Col: 27}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 39, Col: 27}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 		if templ_7745c5c3_Err != nil {
@@ -103,7 +114,7 @@ func RunStatusBadge(runID resource.TfeID, status runstatus.Status) templ.Compone
 		var templ_7745c5c3_Var6 string
 		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(strings.ReplaceAll(string(status), "_", " "))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 28, Col: 76}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 39, Col: 76}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 		if templ_7745c5c3_Err != nil {
@@ -203,7 +214,7 @@ func FilterLabel(status runstatus.Status) templ.Component {
 		var templ_7745c5c3_Var11 string
 		templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(strings.ReplaceAll(string(status), "_", " "))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 49, Col: 48}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/ui/helpers/view.templ`, Line: 60, Col: 48}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 		if templ_7745c5c3_Err != nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/leg100/otf/internal"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tokens"
 	"github.com/leg100/otf/internal/ui/helpers"
 )
 
@@ -16,7 +17,7 @@ type Service struct {
 }
 
 type SessionClient interface {
-	NewToken(subjectID resource.TfeID, expiry *time.Time) ([]byte, error)
+	NewToken(subjectID resource.TfeID, expiry *time.Time, opts ...tokens.NewTokenOption) ([]byte, error)
 }
 
 func NewService(logger logr.Logger, client SessionClient) *Service {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/tokens"
 	"github.com/leg100/otf/internal/path"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,6 @@ func TestService_StartSession(t *testing.T) {
 
 type fakeSessionClient struct{}
 
-func (f *fakeSessionClient) NewToken(subjectID resource.TfeID, expiry *time.Time) ([]byte, error) {
+func (f *fakeSessionClient) NewToken(subjectID resource.TfeID, expiry *time.Time, opts ...tokens.NewTokenOption) ([]byte, error) {
 	return []byte("fake token"), nil
 }
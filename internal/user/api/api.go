@@ -23,6 +23,7 @@ type (
 		Delete(ctx context.Context, username user.Username) error
 		AddTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error
 		RemoveTeamMembership(ctx context.Context, teamID resource.TfeID, usernames []user.Username) error
+		CreateToken(ctx context.Context, opts user.CreateUserTokenOptions) (*user.UserToken, []byte, error)
 	}
 
 	teamMembers struct {
@@ -36,6 +37,8 @@ func (a *API) AddHandlers(r *mux.Router) {
 
 	r.HandleFunc("/teams/{team_id}/relationships/users", a.addTeamMembers).Methods("POST")
 	r.HandleFunc("/teams/{team_id}/relationships/users", a.removeTeamMembers).Methods("DELETE")
+
+	r.HandleFunc("/current-user/tokens", a.createToken).Methods("POST")
 }
 
 func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
@@ -110,3 +113,17 @@ func (a *API) removeTeamMembers(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (a *API) createToken(w http.ResponseWriter, r *http.Request) {
+	var opts user.CreateUserTokenOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	_, token, err := a.Client.CreateToken(r.Context(), opts)
+	if err != nil {
+		tfeapi.Error(w, err)
+		return
+	}
+	w.Write(token)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/url"
@@ -75,3 +76,17 @@ func (c *Client) RemoveTeamMembership(ctx context.Context, teamID resource.TfeID
 	}
 	return nil
 }
+
+// CreateToken creates a user token for the current user via HTTP, returning
+// only the token.
+func (c *Client) CreateToken(ctx context.Context, opts user.CreateUserTokenOptions) (*user.UserToken, []byte, error) {
+	req, err := c.NewRequest("POST", "current-user/tokens", &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.Do(ctx, req, &buf); err != nil {
+		return nil, nil, err
+	}
+	return nil, buf.Bytes(), nil
+}
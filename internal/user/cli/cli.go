@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/leg100/otf/internal/authz"
 	otfhttp "github.com/leg100/otf/internal/http"
 	"github.com/leg100/otf/internal/user"
 	userapi "github.com/leg100/otf/internal/user/api"
@@ -17,6 +18,7 @@ type userCLI struct {
 type userCLIClient interface {
 	Create(ctx context.Context, username string, opts ...user.NewUserOption) (*user.User, error)
 	Delete(ctx context.Context, username user.Username) error
+	CreateToken(ctx context.Context, opts user.CreateUserTokenOptions) (*user.UserToken, []byte, error)
 }
 
 func NewUserCommand(apiClient *otfhttp.Client) *cobra.Command {
@@ -35,6 +37,7 @@ func NewUserCommand(apiClient *otfhttp.Client) *cobra.Command {
 
 	cmd.AddCommand(cli.userNewCommand())
 	cmd.AddCommand(cli.userDeleteCommand())
+	cmd.AddCommand(cli.userTokenCommand())
 
 	return cmd
 }
@@ -77,3 +80,54 @@ func (a *userCLI) userDeleteCommand() *cobra.Command {
 		},
 	}
 }
+
+func (a *userCLI) userTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "User token management",
+	}
+
+	cmd.AddCommand(a.userTokenNewCommand())
+
+	return cmd
+}
+
+func (a *userCLI) userTokenNewCommand() *cobra.Command {
+	var (
+		description string
+		spec        authz.ScopeSpec
+	)
+	cmd := &cobra.Command{
+		Use:           "new",
+		Short:         "Create a new token for the current user",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, err := spec.Parse()
+			if err != nil {
+				return err
+			}
+			_, token, err := a.client.CreateToken(cmd.Context(), user.CreateUserTokenOptions{
+				Description: description,
+				Scope:       scope,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully created user token: %s\n", token)
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&description, "description", "", "Provide a description for the token.")
+	cmd.MarkFlagRequired("description")
+
+	cmd.Flags().StringSliceVar(&spec.Actions, "action", nil, "Restrict the token to the given actions, e.g. get, list.")
+	cmd.Flags().StringSliceVar(&spec.Kinds, "kind", nil, "Restrict the token to the given kinds of resources, e.g. ws, run.")
+	cmd.Flags().StringSliceVar(&spec.Workspaces, "workspace-id", nil, "Restrict the token to the workspaces with the given IDs.")
+	cmd.Flags().StringSliceVar(&spec.Tags, "tag", nil, "Restrict the token to workspaces with any of the given tags.")
+	cmd.Flags().StringSliceVar(&spec.CIDRs, "cidr", nil, "Restrict the token to requests from the given IP address ranges.")
+
+	return cmd
+}
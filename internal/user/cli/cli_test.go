@@ -3,8 +3,10 @@ package cli
 import (
 	"bytes"
 	"context"
+	"net/netip"
 	"testing"
 
+	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/user"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,6 +14,7 @@ import (
 
 type fakeClient struct {
 	user *user.User
+	opts user.CreateUserTokenOptions
 }
 
 func (f *fakeClient) Create(context.Context, string, ...user.NewUserOption) (*user.User, error) {
@@ -22,6 +25,11 @@ func (f *fakeClient) Delete(context.Context, user.Username) error {
 	return nil
 }
 
+func (f *fakeClient) CreateToken(_ context.Context, opts user.CreateUserTokenOptions) (*user.UserToken, []byte, error) {
+	f.opts = opts
+	return nil, []byte("secret-token"), nil
+}
+
 func TestUserNewCommand(t *testing.T) {
 	cli := &userCLI{
 		client: &fakeClient{
@@ -51,3 +59,27 @@ func TestUserDeleteCommand(t *testing.T) {
 
 	assert.Equal(t, "Successfully deleted user bobby\n", got.String())
 }
+
+func TestUserTokenNewCommand(t *testing.T) {
+	client := &fakeClient{}
+	cli := &userCLI{client: client}
+	cmd := cli.userTokenNewCommand()
+
+	cmd.SetArgs([]string{
+		"--description", "ci",
+		"--action", "get,list",
+		"--kind", "ws",
+		"--tag", "dev",
+		"--cidr", "10.0.0.0/8",
+	})
+	got := bytes.Buffer{}
+	cmd.SetOut(&got)
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "Successfully created user token: secret-token\n", got.String())
+	assert.Equal(t, "ci", client.opts.Description)
+	assert.Equal(t, []resource.Action{resource.Get, resource.List}, client.opts.Scope.Actions)
+	assert.Equal(t, []resource.Kind{resource.WorkspaceKind}, client.opts.Scope.Kinds)
+	assert.Equal(t, []string{"dev"}, client.opts.Scope.Tags)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, client.opts.Scope.CIDRs)
+}
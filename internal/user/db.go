@@ -28,13 +28,15 @@ INSERT INTO users (
     created_at,
     updated_at,
     username,
-	avatar_url
+	avatar_url,
+    disabled
 ) VALUES (
     @user_id,
     @created_at,
     @updated_at,
     @username,
-	@avatar_url
+	@avatar_url,
+    @disabled
 )
 `,
 			pgx.NamedArgs{
@@ -43,6 +45,7 @@ INSERT INTO users (
 				"updated_at": user.UpdatedAt,
 				"username":   user.Username,
 				"avatar_url": user.AvatarURL,
+				"disabled":   user.Disabled,
 			},
 		)
 		if err != nil {
@@ -76,6 +79,49 @@ WHERE username = $2
 	return err
 }
 
+func (db *pgdb) updateUser(ctx context.Context, userID resource.TfeID, fn func(context.Context, *User) error) (*User, error) {
+	return sql.Updater(
+		ctx,
+		db.DB,
+		func(ctx context.Context) (*User, error) {
+			rows := db.Query(ctx, `
+SELECT
+    u.*,
+    (
+        SELECT array_agg(t.*)::teams[]
+        FROM teams t
+        JOIN team_memberships tm USING (team_id)
+        WHERE tm.username = u.username
+        GROUP BY tm.username
+    ) AS teams
+FROM users u
+WHERE u.user_id = $1
+FOR UPDATE OF u
+`, userID)
+			return sql.CollectOneRow(rows, scan)
+		},
+		fn,
+		func(ctx context.Context, user *User) error {
+			_, err := db.Exec(ctx, `
+UPDATE users
+SET
+    username = @username,
+    disabled = @disabled,
+    updated_at = @updated_at
+WHERE user_id = @user_id
+`,
+				pgx.NamedArgs{
+					"user_id":    user.ID,
+					"username":   user.Username,
+					"disabled":   user.Disabled,
+					"updated_at": user.UpdatedAt,
+				},
+			)
+			return err
+		},
+	)
+}
+
 func (db *pgdb) listUsers(ctx context.Context) ([]*User, error) {
 	rows := db.Query(ctx, `
 SELECT
@@ -285,6 +331,7 @@ func scan(row pgx.CollectableRow) (*User, error) {
 		UpdatedAt time.Time      `db:"updated_at"`
 		SiteAdmin bool           `db:"site_admin"`
 		AvatarURL *string        `db:"avatar_url"`
+		Disabled  bool
 		Username  Username
 		Teams     []team.Model
 	}
@@ -299,6 +346,7 @@ func scan(row pgx.CollectableRow) (*User, error) {
 		SiteAdmin: m.SiteAdmin,
 		Username:  m.Username,
 		AvatarURL: m.AvatarURL,
+		Disabled:  m.Disabled,
 	}
 	// Only allocate if there are any teams; tests for equality otherwise fail
 	// comparing nil with an empty slice.
@@ -321,25 +369,28 @@ INSERT INTO tokens (
     token_id,
     created_at,
     description,
-    username
+    username,
+    scope
 ) VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
 `,
 		token.ID,
 		token.CreatedAt,
 		token.Description,
 		token.Username,
+		token.Scope,
 	)
 	return err
 }
 
 func (db *pgdb) listUserTokens(ctx context.Context, username Username) ([]*UserToken, error) {
 	rows := db.Query(ctx, `
-SELECT token_id, created_at, description, username
+SELECT token_id, created_at, description, username, scope, last_used_at
 FROM tokens
 WHERE username = $1
 `, username)
@@ -348,7 +399,7 @@ WHERE username = $1
 
 func (db *pgdb) getUserToken(ctx context.Context, id resource.TfeID) (*UserToken, error) {
 	rows := db.Query(ctx, `
-SELECT token_id, created_at, description, username
+SELECT token_id, created_at, description, username, scope, last_used_at
 FROM tokens
 WHERE token_id = $1
 `, id)
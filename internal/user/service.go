@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
@@ -15,7 +16,10 @@ import (
 	"github.com/leg100/otf/internal/tokens"
 )
 
-var ErrCannotDeleteOnlyOwner = errors.New("cannot remove the last owner")
+var (
+	ErrCannotDeleteOnlyOwner = errors.New("cannot remove the last owner")
+	ErrUserDisabled          = errors.New("user is disabled")
+)
 
 type (
 	// Alias service to permit embedding it with other services in a struct
@@ -28,6 +32,7 @@ type (
 
 		teams *team.Service
 		db    *pgdb
+		audit audit.Recorder
 
 		*userTokenFactory
 	}
@@ -37,6 +42,7 @@ type (
 		TokensService *tokens.Service
 		TeamService   *team.Service
 		Authorizer    *authz.Authorizer
+		AuditRecorder audit.Recorder
 
 		*sql.DB
 		logr.Logger
@@ -52,6 +58,7 @@ func NewService(opts Options) *Service {
 			tokens: opts.TokensService,
 		},
 		teams: opts.TeamService,
+		audit: opts.AuditRecorder,
 	}
 
 	// Whenever an owners team is created, add the creator as a member.
@@ -77,12 +84,12 @@ func NewService(opts Options) *Service {
 	// Register with auth middleware the user token kind and a means of
 	// retrieving user corresponding to token.
 	opts.TokensService.RegisterKind(resource.UserTokenKind, func(ctx context.Context, tokenID resource.TfeID) (authz.Subject, error) {
-		return svc.GetUser(ctx, UserSpec{AuthenticationTokenID: &tokenID})
+		return svc.getEnabledUser(ctx, UserSpec{AuthenticationTokenID: &tokenID})
 	})
 	// Register with auth middleware the user session kind and a means of
 	// retrieving user corresponding to token.
 	opts.TokensService.RegisterKind(resource.UserKind, func(ctx context.Context, tokenID resource.TfeID) (authz.Subject, error) {
-		return svc.GetUser(ctx, UserSpec{UserID: &tokenID})
+		return svc.getEnabledUser(ctx, UserSpec{UserID: &tokenID})
 	})
 
 	return &svc
@@ -155,6 +162,39 @@ func (a *Service) GetUser(ctx context.Context, spec UserSpec) (*User, error) {
 	return user, nil
 }
 
+// getEnabledUser retrieves a user for the purposes of authentication, returning
+// an error if the user is disabled.
+func (a *Service) getEnabledUser(ctx context.Context, spec UserSpec) (*User, error) {
+	user, err := a.GetUser(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+	return user, nil
+}
+
+// UpdateUser updates a user's account.
+func (a *Service) UpdateUser(ctx context.Context, userID resource.TfeID, opts UpdateUserOptions) (*User, error) {
+	subject, err := a.Authorize(ctx, resource.Update, resource.UserKind, resource.SiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.db.updateUser(ctx, userID, func(ctx context.Context, user *User) error {
+		return user.update(opts)
+	})
+	if err != nil {
+		a.Error(err, "updating user", "user_id", userID, "subject", subject)
+		return nil, err
+	}
+
+	a.V(0).Info("updated user", "username", user.Username, "disabled", user.Disabled, "subject", subject)
+
+	return user, nil
+}
+
 // List lists all users.
 func (a *Service) List(ctx context.Context) ([]*User, error) {
 	_, err := a.Authorize(ctx, resource.List, resource.UserKind, resource.SiteID)
@@ -253,6 +293,12 @@ func (a *Service) AddTeamMembership(ctx context.Context, teamID resource.TfeID,
 	}
 
 	a.V(0).Info("added team membership", "users", usernames, "team", teamID, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: team.Organization,
+		Action:       resource.Add,
+		ResourceID:   team.ID,
+		After:        map[string]any{"users": usernames},
+	})
 
 	return nil
 }
@@ -285,6 +331,12 @@ func (a *Service) RemoveTeamMembership(ctx context.Context, teamID resource.TfeI
 		return err
 	}
 	a.V(0).Info("removed team membership", "users", usernames, "team", teamID, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: team.Organization,
+		Action:       resource.Remove,
+		ResourceID:   team.ID,
+		Before:       map[string]any{"users": usernames},
+	})
 
 	return nil
 }
@@ -313,12 +365,16 @@ func (a *Service) SetSiteAdmins(ctx context.Context, usernames ...string) error
 // User API token endpoints
 
 // CreateToken creates a user token. Only users can create a user token, and
-// they can only create a token for themselves.
+// they can only create a token for themselves. A user authenticated with a
+// scoped token cannot create tokens, lest they escape the scope.
 func (a *Service) CreateToken(ctx context.Context, opts CreateUserTokenOptions) (*UserToken, []byte, error) {
 	user, err := UserFromContext(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
+	if _, scoped := authz.ScopeFromContext(ctx); scoped {
+		return nil, nil, internal.ErrAccessNotPermitted
+	}
 
 	ut, token, err := a.NewUserToken(user.Username, opts)
 	if err != nil {
@@ -332,6 +388,11 @@ func (a *Service) CreateToken(ctx context.Context, opts CreateUserTokenOptions)
 	}
 
 	a.V(1).Info("created user token", "user", user)
+	a.recordTokenEvent(ctx, user, audit.RecordOptions{
+		Action:     resource.Create,
+		ResourceID: ut.ID,
+		After:      ut.LogValue(),
+	})
 
 	return ut, token, nil
 }
@@ -350,6 +411,9 @@ func (a *Service) DeleteToken(ctx context.Context, tokenID resource.TfeID) error
 	if err != nil {
 		return err
 	}
+	if _, scoped := authz.ScopeFromContext(ctx); scoped {
+		return internal.ErrAccessNotPermitted
+	}
 
 	token, err := a.db.getUserToken(ctx, tokenID)
 	if err != nil {
@@ -367,6 +431,21 @@ func (a *Service) DeleteToken(ctx context.Context, tokenID resource.TfeID) error
 	}
 
 	a.V(1).Info("deleted user token", "username", user)
+	a.recordTokenEvent(ctx, user, audit.RecordOptions{
+		Action:     resource.Delete,
+		ResourceID: token.ID,
+		Before:     token.LogValue(),
+	})
 
 	return nil
 }
+
+// recordTokenEvent records an audit event for a change to a user token. A user
+// token is not specific to an organization, so the event is recorded in the
+// audit trail of each organization of which the user is a member.
+func (a *Service) recordTokenEvent(ctx context.Context, user *User, opts audit.RecordOptions) {
+	for _, org := range user.Organizations() {
+		opts.Organization = org
+		a.audit.Record(ctx, opts)
+	}
+}
@@ -1,9 +1,11 @@
 package user
 
 import (
+	"log/slog"
 	"time"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/tokens"
 )
@@ -15,12 +17,19 @@ type (
 		CreatedAt   time.Time      `db:"created_at"`
 		Description string
 		Username    Username // Token belongs to a user
+		// Scope restricts the access granted by the token.
+		Scope authz.Scope
+		// LastUsedAt is when the token was last used to authenticate a
+		// request; nil if it has never been used.
+		LastUsedAt *time.Time `db:"last_used_at"`
 	}
 
 	// CreateUserTokenOptions are options for creating a user token via the service
 	// endpoint
 	CreateUserTokenOptions struct {
 		Description string
+		// Scope optionally restricts the access granted by the token.
+		Scope authz.Scope
 	}
 
 	userTokenFactory struct {
@@ -34,11 +43,29 @@ func (f *userTokenFactory) NewUserToken(username Username, opts CreateUserTokenO
 		CreatedAt:   internal.CurrentTimestamp(nil),
 		Description: opts.Description,
 		Username:    username,
+		Scope:       opts.Scope,
+	}
+	var tokenOpts []tokens.NewTokenOption
+	if !opts.Scope.IsZero() {
+		tokenOpts = append(tokenOpts, tokens.WithScope(opts.Scope))
 	}
 	// nil for expiry because user token never expires
-	token, err := f.tokens.NewToken(ut.ID, nil)
+	token, err := f.tokens.NewToken(ut.ID, nil, tokenOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
 	return &ut, token, nil
 }
+
+// LogValue implements slog.LogValuer.
+func (t *UserToken) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("id", t.ID.String()),
+		slog.String("description", t.Description),
+		slog.String("username", t.Username.String()),
+	}
+	if !t.Scope.IsZero() {
+		attrs = append(attrs, slog.Any("scope", t.Scope.Restrictions()))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/http/decode"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
@@ -163,12 +164,33 @@ func (h *Handlers) newUserToken(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) createUserToken(w http.ResponseWriter, r *http.Request) {
-	var opts user.CreateUserTokenOptions
-	if err := decode.Form(&opts, r); err != nil {
+	var params struct {
+		Description string   `schema:"description"`
+		Actions     []string `schema:"actions"`
+		Kinds       []string `schema:"kinds"`
+		Workspaces  []string `schema:"workspaces"`
+		Tags        []string `schema:"tags"`
+		CIDRs       []string `schema:"cidrs"`
+	}
+	if err := decode.Form(&params, r); err != nil {
+		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	scope, err := authz.ScopeSpec{
+		Actions:    params.Actions,
+		Kinds:      params.Kinds,
+		Workspaces: params.Workspaces,
+		Tags:       params.Tags,
+		CIDRs:      params.CIDRs,
+	}.Parse()
+	if err != nil {
 		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
 		return
 	}
-	_, token, err := h.Client.CreateToken(r.Context(), opts)
+	_, token, err := h.Client.CreateToken(r.Context(), user.CreateUserTokenOptions{
+		Description: params.Description,
+		Scope:       scope,
+	})
 	if err != nil {
 		helpers.Error(r, w, err.Error())
 		return
@@ -18,6 +18,7 @@ type LoginHandlers struct {
 
 type loginClient interface {
 	Clients() []*authenticator.OAuthClient
+	SAMLClient() *authenticator.SAMLClient
 	StartSession(w http.ResponseWriter, r *http.Request, userID resource.TfeID) error
 }
 
@@ -28,7 +29,7 @@ func (h *LoginHandlers) AddHandlers(r *mux.Router) {
 }
 
 func (h *LoginHandlers) loginHandler(w http.ResponseWriter, r *http.Request) {
-	helpers.Render(login(h.Client.Clients()), w, r)
+	helpers.Render(login(h.Client.Clients(), h.Client.SAMLClient()), w, r)
 }
 
 // adminLoginPromptHandler presents a prompt for logging in as site admin
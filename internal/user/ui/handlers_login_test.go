@@ -87,6 +87,10 @@ func (f *fakeLoginClient) Clients() []*authenticator.OAuthClient {
 	return f.clients
 }
 
+func (f *fakeLoginClient) SAMLClient() *authenticator.SAMLClient {
+	return nil
+}
+
 func (f *fakeLoginClient) StartSession(w http.ResponseWriter, r *http.Request, userID resource.TfeID) error {
 	http.Redirect(w, r, path.Profile(), http.StatusFound)
 	return nil
@@ -27,6 +27,8 @@ type (
 		Teams []*team.Team
 		// AvatarURL is the URL of an avatar depicting user.
 		AvatarURL *string
+		// Disabled users cannot authenticate.
+		Disabled bool `jsonapi:"attribute" json:"disabled"`
 	}
 
 	// ListOptions are options for the ListUsers endpoint.
@@ -42,6 +44,11 @@ type (
 		Username string `json:"username"`
 	}
 
+	UpdateUserOptions struct {
+		Username *string
+		Disabled *bool
+	}
+
 	UserSpec struct {
 		UserID                *resource.TfeID
 		Username              *Username
@@ -72,7 +79,23 @@ func WithTeams(memberships ...*team.Team) NewUserOption {
 	}
 }
 
-func (u *User) String() string { return u.Username.String() }
+func (u *User) update(opts UpdateUserOptions) error {
+	if opts.Username != nil {
+		username, err := NewUsername(*opts.Username)
+		if err != nil {
+			return err
+		}
+		u.Username = username
+	}
+	if opts.Disabled != nil {
+		u.Disabled = *opts.Disabled
+	}
+	u.UpdatedAt = internal.CurrentTimestamp(nil)
+	return nil
+}
+
+func (u *User) String() string        { return u.Username.String() }
+func (u *User) GetID() resource.TfeID { return u.ID }
 
 // PictureURL avoids an import cycle with the UI components package, allowing
 // the layout template to retrieve the current user's avatar URL.
@@ -121,6 +144,10 @@ func (u *User) IsSiteAdmin() bool {
 }
 
 func (u *User) CanAccess(action resource.Action, kind resource.Kind, req authz.Request) bool {
+	// Disabled user can do nothing
+	if u.Disabled {
+		return false
+	}
 	// Site admin can do whatever it wants
 	if u.IsSiteAdmin() {
 		return true
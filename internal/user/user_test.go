@@ -8,6 +8,7 @@ import (
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/team"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSiteAdminCanAccessOrganization(t *testing.T) {
@@ -31,6 +32,37 @@ func TestOwnerCanAccessOrganization(t *testing.T) {
 	assert.True(t, u.CanAccess(resource.List, resource.RunKind, authz.Request{ID: org}))
 }
 
+func TestDisabledOwnerCannotAccessOrganization(t *testing.T) {
+	org := organization.NewTestName(t)
+	u := User{
+		Teams: []*team.Team{
+			{
+				Name:         "owners",
+				Organization: org,
+			},
+		},
+		Disabled: true,
+	}
+	assert.False(t, u.CanAccess(resource.List, resource.RunKind, authz.Request{ID: org}))
+}
+
+func TestUser_Update(t *testing.T) {
+	u, err := NewUser("bobby")
+	require.NoError(t, err)
+
+	err = u.update(UpdateUserOptions{
+		Username: new("robert"),
+		Disabled: new(true),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "robert", u.Username.String())
+	assert.True(t, u.Disabled)
+
+	err = u.update(UpdateUserOptions{Username: new("")})
+	assert.Error(t, err)
+}
+
 func TestUser_Organizations(t *testing.T) {
 	org1 := organization.NewTestName(t)
 	org2 := organization.NewTestName(t)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
@@ -15,18 +16,26 @@ import (
 // pgdb is a database of variables on postgres
 type pgdb struct {
 	*sql.DB // provides access to generated SQL queries
+
+	// cipher encrypts the values of sensitive variables
+	cipher *encryption.Cipher
 }
 
 func (pdb *pgdb) createVariable(ctx context.Context, parentID resource.TfeID, v *Variable) error {
+	value, err := pdb.encryptValue(ctx, v)
+	if err != nil {
+		return err
+	}
 	args := pgx.NamedArgs{
 		"variable_id": v.ID,
 		"key":         v.Key,
-		"value":       v.Value,
+		"value":       value,
 		"description": v.Description,
 		"category":    v.Category,
 		"sensitive":   v.Sensitive,
 		"hcl":         v.HCL,
 		"version_id":  v.VersionID,
+		"source":      v.Source,
 	}
 
 	switch parentID.Kind() {
@@ -38,7 +47,7 @@ func (pdb *pgdb) createVariable(ctx context.Context, parentID resource.TfeID, v
 		return fmt.Errorf("unsupported variable parent kind: %s", parentID.Kind())
 	}
 
-	_, err := pdb.Exec(ctx, `
+	_, err = pdb.Exec(ctx, `
 INSERT INTO variables (
     variable_id,
     key,
@@ -48,6 +57,7 @@ INSERT INTO variables (
     sensitive,
     hcl,
     version_id,
+    source,
 	workspace_id,
 	variable_set_id
 ) VALUES (
@@ -59,6 +69,7 @@ INSERT INTO variables (
     @sensitive,
     @hcl,
     @version_id,
+    @source,
     @workspace_id,
     @variable_set_id
 )
@@ -80,6 +91,7 @@ SELECT
 	v.sensitive,
 	v.hcl,
 	v.version_id,
+	v.source,
 	v.workspace_id AS parent_id
 FROM variables v
 WHERE v.workspace_id = $1
@@ -95,6 +107,7 @@ SELECT
 	v.sensitive,
 	v.hcl,
 	v.version_id,
+	v.source,
 	v.variable_set_id AS parent_id
 FROM variables v
 WHERE v.variable_set_id = $1
@@ -103,7 +116,7 @@ WHERE v.variable_set_id = $1
 		return nil, fmt.Errorf("invalid variable parent kind: %s", parentID.Kind())
 	}
 	rows := pdb.Query(ctx, q, parentID)
-	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Variable])
+	return pdb.collectVariables(ctx, rows)
 }
 
 func (pdb *pgdb) listGlobalVariables(ctx context.Context, organization organization.Name) ([]*Variable, error) {
@@ -117,13 +130,14 @@ SELECT
 	v.sensitive,
 	v.hcl,
 	v.version_id,
+	v.source,
 	v.variable_set_id AS parent_id
 FROM variables v
 JOIN variable_sets vs USING (variable_set_id)
 WHERE vs.global IS true
 AND vs.organization_name = $1
 `, organization)
-	return sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Variable])
+	return pdb.collectVariables(ctx, rows)
 }
 
 func (pdb *pgdb) getVariable(ctx context.Context, variableID resource.ID) (*Variable, error) {
@@ -137,11 +151,19 @@ SELECT
 	v.sensitive,
 	v.hcl,
 	v.version_id,
+	v.source,
 	COALESCE(v.workspace_id, v.variable_set_id) AS parent_id
 FROM variables v
 WHERE v.variable_id = $1
 `, variableID)
-	return sql.CollectExactlyOneRow(row, pgx.RowToAddrOfStructByName[Variable])
+	v, err := sql.CollectExactlyOneRow(row, pgx.RowToAddrOfStructByName[Variable])
+	if err != nil {
+		return nil, err
+	}
+	if err := pdb.decryptValue(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
 }
 
 func (pdb *pgdb) deleteVariable(ctx context.Context, variableID resource.TfeID) (*Variable, error) {
@@ -235,7 +257,14 @@ FROM variable_sets vs
 WHERE vs.variable_set_id = $1
 `,
 		setID)
-	return sql.CollectExactlyOneRow(row, scanVariableSet)
+	set, err := sql.CollectExactlyOneRow(row, scanVariableSet)
+	if err != nil {
+		return nil, err
+	}
+	if err := pdb.decryptSets(ctx, set); err != nil {
+		return nil, err
+	}
+	return set, nil
 }
 
 func (pdb *pgdb) listVariableSets(ctx context.Context, organization organization.Name) ([]*VariableSet, error) {
@@ -255,7 +284,7 @@ SELECT
 FROM variable_sets vs
 WHERE organization_name = $1
 `, organization)
-	return sql.CollectRows(rows, scanVariableSet)
+	return pdb.collectVariableSets(ctx, rows)
 }
 
 func (pdb *pgdb) listVariableSetsByWorkspace(ctx context.Context, workspaceID resource.TfeID) ([]*VariableSet, error) {
@@ -293,7 +322,7 @@ JOIN (organizations o JOIN workspaces w ON o.name = w.organization_name) ON o.na
 WHERE vs.global IS true
 AND w.workspace_id = $1
 `, workspaceID)
-	return sql.CollectRows(rows, scanVariableSet)
+	return pdb.collectVariableSets(ctx, rows)
 }
 
 func (pdb *pgdb) deleteVariableSet(ctx context.Context, setID resource.TfeID) error {
@@ -321,6 +350,7 @@ func scanVariableSet(row pgx.CollectableRow) (*VariableSet, error) {
 		VersionID     string           `db:"version_id"`
 		WorkspaceID   resource.TfeID   `db:"workspace_id"`
 		VariableSetID resource.TfeID   `db:"variable_set_id"`
+		Source        *Source          `db:"source"`
 	}
 	type setModel struct {
 		ID           resource.TfeID    `db:"variable_set_id"`
@@ -354,6 +384,7 @@ func scanVariableSet(row pgx.CollectableRow) (*VariableSet, error) {
 			Sensitive:   varModel.Sensitive,
 			HCL:         varModel.HCL,
 			ParentID:    vs.ID,
+			Source:      varModel.Source,
 		}
 	}
 	return vs, nil
@@ -408,7 +439,11 @@ AND workspace_id = $2
 }
 
 func (pdb *pgdb) updateVariable(ctx context.Context, v *Variable) error {
-	_, err := pdb.Exec(ctx, `
+	value, err := pdb.encryptValue(ctx, v)
+	if err != nil {
+		return err
+	}
+	_, err = pdb.Exec(ctx, `
 UPDATE variables
 SET
     key = $1,
@@ -417,17 +452,82 @@ SET
     category = $4,
     sensitive = $5,
     hcl = $6,
-    version_id = $7
-WHERE variable_id = $8
+    version_id = $7,
+    source = $8
+WHERE variable_id = $9
 `,
 		v.Key,
-		v.Value,
+		value,
 		v.Description,
 		v.Category,
 		v.Sensitive,
 		v.HCL,
 		v.VersionID,
+		v.Source,
 		v.ID,
 	)
 	return err
 }
+
+func (pdb *pgdb) collectVariables(ctx context.Context, rows pgx.Rows) ([]*Variable, error) {
+	vars, err := sql.CollectRows(rows, pgx.RowToAddrOfStructByName[Variable])
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vars {
+		if err := pdb.decryptValue(ctx, v); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+func (pdb *pgdb) collectVariableSets(ctx context.Context, rows pgx.Rows) ([]*VariableSet, error) {
+	sets, err := sql.CollectRows(rows, scanVariableSet)
+	if err != nil {
+		return nil, err
+	}
+	if err := pdb.decryptSets(ctx, sets...); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+func (pdb *pgdb) decryptSets(ctx context.Context, sets ...*VariableSet) error {
+	for _, set := range sets {
+		for _, v := range set.Variables {
+			if err := pdb.decryptValue(ctx, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encryptValue returns the value of the variable to be written to the
+// database, encrypting it if the variable is sensitive.
+func (pdb *pgdb) encryptValue(ctx context.Context, v *Variable) (string, error) {
+	if !v.Sensitive {
+		return v.Value, nil
+	}
+	value, err := pdb.cipher.EncryptString(ctx, v.Value)
+	if err != nil {
+		return "", fmt.Errorf("encrypting value of variable %s: %w", v.ID, err)
+	}
+	return value, nil
+}
+
+// decryptValue decrypts the value of a variable read from the database if the
+// variable is sensitive. The values of other variables are never encrypted, and
+// are returned as-is even if they resemble an encrypted value.
+func (pdb *pgdb) decryptValue(ctx context.Context, v *Variable) error {
+	if !v.Sensitive {
+		return nil
+	}
+	value, err := pdb.cipher.DecryptString(ctx, v.Value)
+	if err != nil {
+		return fmt.Errorf("decrypting value of variable %s: %w", v.ID, err)
+	}
+	v.Value = value
+	return nil
+}
@@ -0,0 +1,33 @@
+package variable
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/leg100/otf/internal/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecryptValue(t *testing.T) {
+	kms, err := encryption.NewLocalKMS(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	db := &pgdb{cipher: encryption.NewCipher(kms)}
+
+	t.Run("sensitive", func(t *testing.T) {
+		v := &Variable{Value: "top-secret", Sensitive: true}
+		encrypted, err := db.encryptValue(t.Context(), v)
+		require.NoError(t, err)
+		v.Value = encrypted
+
+		require.NoError(t, db.decryptValue(t.Context(), v))
+		assert.Equal(t, "top-secret", v.Value)
+	})
+
+	t.Run("non-sensitive value resembling an encrypted value", func(t *testing.T) {
+		v := &Variable{Value: "otfenc:v1:not-actually-encrypted"}
+
+		require.NoError(t, db.decryptValue(t.Context(), v))
+		assert.Equal(t, "otfenc:v1:not-actually-encrypted", v.Value)
+	})
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/run"
+	"github.com/leg100/otf/internal/secrets"
 	"github.com/leg100/otf/internal/sql"
 	"github.com/leg100/otf/internal/workspace"
 )
@@ -25,6 +28,8 @@ type (
 		db        *pgdb
 		runs      runClient
 		conflicts *conflictChecker
+		audit     audit.Recorder
+		secrets   *secrets.Resolver
 	}
 
 	Options struct {
@@ -33,6 +38,11 @@ type (
 		Authorizer       *authz.Authorizer
 		DB               *sql.DB
 		Logger           logr.Logger
+		AuditRecorder    audit.Recorder
+		// Cipher encrypts the values of sensitive variables.
+		Cipher *encryption.Cipher
+		// Secrets resolves the values of variables with an external source.
+		Secrets *secrets.Resolver
 	}
 
 	runClient interface {
@@ -41,13 +51,15 @@ type (
 )
 
 func NewService(opts Options) *Service {
-	db := &pgdb{opts.DB}
+	db := &pgdb{DB: opts.DB, cipher: opts.Cipher}
 	svc := Service{
 		Logger:     opts.Logger,
 		Authorizer: opts.Authorizer,
 		db:         db,
 		runs:       opts.RunClient,
 		conflicts:  &conflictChecker{client: db},
+		audit:      opts.AuditRecorder,
+		secrets:    opts.Secrets,
 	}
 
 	// Provide a means of looking up a variables's parent resource ID.
@@ -91,7 +103,42 @@ func (s *Service) ListEffectiveVariables(ctx context.Context, runID resource.Tfe
 	if err != nil {
 		return nil, err
 	}
-	return Merge(sets, vars, run), nil
+	merged := Merge(sets, vars, run)
+	// Retrieve the values of variables with an external source. The values
+	// are only returned to the caller and are never persisted.
+	scope := secrets.Scope{
+		Organization: run.Organization,
+		WorkspaceID:  run.WorkspaceID,
+	}
+	for i, v := range merged {
+		if v.Source == nil {
+			continue
+		}
+		value, err := s.secrets.Resolve(ctx, v.Source.Provider, scope, v.Source.Reference)
+		if err != nil {
+			err = fmt.Errorf("retrieving value of variable %s from %s secret provider: %w", v.Key, v.Source.Provider, err)
+			s.Error(err, "resolving variable source", "run_id", runID, "variable", v)
+			return nil, err
+		}
+		resolved := *v
+		resolved.Value = value
+		merged[i] = &resolved
+	}
+	return merged, nil
+}
+
+// SecretProviders lists the names of the secret providers from which variables
+// can source their values.
+func (s *Service) SecretProviders() []string {
+	return s.secrets.Providers()
+}
+
+// checkSource checks that the provider of a variable's source is configured.
+func (s *Service) checkSource(v *Variable) error {
+	if v.Source != nil && !s.secrets.HasProvider(v.Source.Provider) {
+		return fmt.Errorf("%w: %s", secrets.ErrUnknownProvider, v.Source.Provider)
+	}
+	return nil
 }
 
 func (s *Service) CreateVariable(ctx context.Context, parentID resource.TfeID, opts CreateVariableOptions) (*Variable, error) {
@@ -105,6 +152,12 @@ func (s *Service) CreateVariable(ctx context.Context, parentID resource.TfeID, o
 		return nil, fmt.Errorf("creating variable: %w", err)
 	}
 	s.V(1).Info("created variable", "subject", subject, "variable", v)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     parentID,
+		Action:     resource.Create,
+		ResourceID: v.ID,
+		After:      v.LogValue(),
+	})
 
 	return v, nil
 }
@@ -114,6 +167,9 @@ func (s *Service) createVariable(ctx context.Context, parentID resource.TfeID, o
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkSource(v); err != nil {
+		return nil, err
+	}
 	err = s.db.Lock(ctx, "variables", func(ctx context.Context) error {
 		if err := s.conflicts.checkVariable(ctx, v); err != nil {
 			return fmt.Errorf("checking for conflicts: %w", err)
@@ -139,6 +195,13 @@ func (s *Service) UpdateVariable(ctx context.Context, variableID resource.TfeID,
 		return nil, err
 	}
 	s.V(1).Info("updated variable", "subject", subject, "before", before, "after", after)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     after.ParentID,
+		Action:     resource.Update,
+		ResourceID: after.ID,
+		Before:     before.LogValue(),
+		After:      after.LogValue(),
+	})
 
 	return after, nil
 }
@@ -161,6 +224,9 @@ func (s *Service) updateVariable(ctx context.Context, variableID resource.TfeID,
 		if err := after.update(opts); err != nil {
 			return err
 		}
+		if err := s.checkSource(&after); err != nil {
+			return err
+		}
 
 		// Check if any of the updates have resulted in any conflicts
 		if err := s.conflicts.checkVariable(ctx, &after); err != nil {
@@ -220,6 +286,12 @@ func (s *Service) DeleteVariable(ctx context.Context, variableID resource.TfeID)
 		return nil, err
 	}
 	s.V(1).Info("deleted variable", "subject", subject, "variable", v)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     v.ParentID,
+		Action:     resource.Delete,
+		ResourceID: v.ID,
+		Before:     v.LogValue(),
+	})
 
 	return v, nil
 }
@@ -251,6 +323,12 @@ func (s *Service) CreateVariableSet(ctx context.Context, organization organizati
 	}
 
 	s.V(1).Info("created variable set", "subject", subject, "set", set)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: organization,
+		Action:       resource.Create,
+		ResourceID:   set.ID,
+		After:        set.LogValue(),
+	})
 
 	return set, nil
 }
@@ -288,6 +366,13 @@ func (s *Service) UpdateVariableSet(ctx context.Context, setID resource.TfeID, o
 		return nil, err
 	}
 	s.V(1).Info("updated variable set", "subject", subject, "before", before, "after", &after)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: after.Organization,
+		Action:       resource.Update,
+		ResourceID:   after.ID,
+		Before:       before.LogValue(),
+		After:        after.LogValue(),
+	})
 
 	return &after, nil
 }
@@ -358,6 +443,12 @@ func (s *Service) DeleteVariableSet(ctx context.Context, setID resource.TfeID) (
 		return nil, err
 	}
 	s.V(1).Info("deleted variable set", "subject", subject, "set", set)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: set.Organization,
+		Action:       resource.Delete,
+		ResourceID:   set.ID,
+		Before:       set.LogValue(),
+	})
 
 	return set, nil
 }
@@ -379,6 +470,12 @@ func (s *Service) ApplySetToWorkspaces(ctx context.Context, setID resource.TfeID
 		return err
 	}
 	s.V(1).Info("applied variable set to workspaces", "subject", subject, "set", set, "workspaces", workspaceIDs)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: set.Organization,
+		Action:       resource.Add,
+		ResourceID:   set.ID,
+		After:        map[string]any{"workspaces": workspaceIDs},
+	})
 
 	return nil
 }
@@ -400,6 +497,12 @@ func (s *Service) DeleteSetFromWorkspaces(ctx context.Context, setID resource.Tf
 		return err
 	}
 	s.V(1).Info("removed variable set from workspaces", "subject", subject, "set", set, "workspaces", workspaceIDs)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: set.Organization,
+		Action:       resource.Remove,
+		ResourceID:   set.ID,
+		Before:       map[string]any{"workspaces": workspaceIDs},
+	})
 
 	return nil
 }
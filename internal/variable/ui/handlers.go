@@ -39,6 +39,8 @@ type Client interface {
 	UpdateVariableSet(ctx context.Context, setID resource.TfeID, opts variable.UpdateVariableSetOptions) (*variable.VariableSet, error)
 	DeleteVariableSet(ctx context.Context, setID resource.TfeID) (*variable.VariableSet, error)
 
+	SecretProviders() []string
+
 	GetWorkspace(context.Context, resource.TfeID) (*workspace.Workspace, error)
 	ListWorkspaces(ctx context.Context, opts workspace.ListOptions) (*resource.Page[*workspace.Workspace], error)
 }
@@ -46,6 +48,10 @@ type Client interface {
 // templates holds dependencies needed by templ templates.
 type templates struct {
 	authz.Interface
+
+	// secretProviders are the names of the secret providers from which
+	// variables can source their values.
+	secretProviders []string
 }
 
 type (
@@ -57,6 +63,9 @@ type (
 		Sensitive   bool
 		HCL         bool
 		ParentID    resource.TfeID `schema:"parent_id,required"`
+
+		SourceProvider  string `schema:"source_provider"`
+		SourceReference string `schema:"source_reference"`
 	}
 
 	updateVariableParams struct {
@@ -67,6 +76,9 @@ type (
 		Sensitive   *bool
 		HCL         bool
 		VariableID  resource.TfeID `schema:"variable_id,required"`
+
+		SourceProvider  string `schema:"source_provider"`
+		SourceReference string `schema:"source_reference"`
 	}
 )
 
@@ -74,7 +86,10 @@ func NewHandlers(variables Client, authorizer authz.Interface) *Handlers {
 	return &Handlers{
 		client:     variables,
 		authorizer: authorizer,
-		templates:  &templates{Interface: authorizer},
+		templates: &templates{
+			Interface:       authorizer,
+			secretProviders: variables.SecretProviders(),
+		},
 	}
 }
 
@@ -492,14 +507,24 @@ func (h *Handlers) createVariable(w http.ResponseWriter, r *http.Request) {
 		*params.Value = strings.ReplaceAll(*params.Value, "\r\n", "\n")
 	}
 
-	variable, err := h.client.CreateVariable(r.Context(), params.ParentID, variable.CreateVariableOptions{
+	opts := variable.CreateVariableOptions{
 		Key:         params.Key,
 		Value:       params.Value,
 		Description: params.Description,
 		Category:    params.Category,
 		Sensitive:   &params.Sensitive,
 		HCL:         &params.HCL,
-	})
+	}
+	// A variable sourcing its value from a secret provider has no value of
+	// its own, so ignore anything entered in the value field.
+	if params.SourceProvider != "" {
+		opts.Value = nil
+		opts.Source = &variable.Source{
+			Provider:  params.SourceProvider,
+			Reference: params.SourceReference,
+		}
+	}
+	variable, err := h.client.CreateVariable(r.Context(), params.ParentID, opts)
 	if err != nil {
 		helpers.Error(r, w, err.Error())
 		return
@@ -578,14 +603,23 @@ func (h *Handlers) updateVariable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	v, err := h.client.UpdateVariable(r.Context(), params.VariableID, variable.UpdateVariableOptions{
+	opts := variable.UpdateVariableOptions{
 		Key:         params.Key,
 		Value:       params.Value,
 		Description: params.Description,
 		Category:    params.Category,
 		Sensitive:   params.Sensitive,
 		HCL:         &params.HCL,
-	})
+		// An empty provider removes any existing source.
+		Source: &variable.Source{
+			Provider:  params.SourceProvider,
+			Reference: params.SourceReference,
+		},
+	}
+	if params.SourceProvider != "" {
+		opts.Value = nil
+	}
+	v, err := h.client.UpdateVariable(r.Context(), params.VariableID, opts)
 	if err != nil {
 		helpers.Error(r, w, err.Error())
 		return
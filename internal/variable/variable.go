@@ -28,6 +28,7 @@ var (
 	ErrVariableKeyMaxExceeded         = fmt.Errorf("maximum variable key size (%d chars) exceeded", VariableKeyMaxChars)
 	ErrVariableValueMaxExceeded       = fmt.Errorf("maximum variable value size of %d KB exceeded", VariableValueMaxKB)
 	ErrVariableConflict               = errors.New("variable conflicts with another variable with the same name and type")
+	ErrVariableSourceAndValue         = errors.New("a variable with a source cannot also have a value")
 )
 
 type (
@@ -45,11 +46,26 @@ type (
 		// variable set variable.
 		ParentID resource.TfeID `jsonapi:"attribute" json:"parent_id" db:"parent_id"`
 
+		// Source is the external source of the variable's value. Nil if the
+		// value is stored in OTF.
+		Source *Source `jsonapi:"attribute" json:"source,omitempty" db:"source"`
+
 		// OTF doesn't use this internally but the go-tfe integration tests
 		// expect it to be a random value that changes on every update.
 		VersionID string
 	}
 
+	// Source is an external source of a variable's value, such as a secret in
+	// HashiCorp Vault. The value is retrieved from the source whenever a run's
+	// variables are retrieved, and is never stored in OTF.
+	Source struct {
+		// Provider is the name of the secret provider, e.g. vault.
+		Provider string `json:"provider"`
+		// Reference identifies the secret. Its format depends on the provider,
+		// e.g. prod/db#password for vault.
+		Reference string `json:"reference"`
+	}
+
 	CreateVariableOptions struct {
 		Key         *string
 		Value       *string
@@ -57,6 +73,7 @@ type (
 		Category    *VariableCategory
 		Sensitive   *bool
 		HCL         *bool
+		Source      *Source
 
 		generateVersion
 	}
@@ -68,6 +85,9 @@ type (
 		Category    *VariableCategory
 		Sensitive   *bool
 		HCL         *bool
+		// Source sets the external source of the variable's value. Set a
+		// source with an empty provider to remove the source.
+		Source *Source
 
 		generateVersion
 	}
@@ -101,6 +121,15 @@ func newVariable(parentID resource.TfeID, opts CreateVariableOptions) (*Variable
 	}
 
 	// Optional fields
+	if opts.Sensitive != nil {
+		v.Sensitive = *opts.Sensitive
+	}
+	// Set source after sensitive because a source makes a variable sensitive.
+	if opts.Source != nil {
+		if err := v.setSource(*opts.Source); err != nil {
+			return nil, err
+		}
+	}
 	if opts.Value != nil {
 		if err := v.setValue(*opts.Value); err != nil {
 			return nil, err
@@ -111,12 +140,12 @@ func newVariable(parentID resource.TfeID, opts CreateVariableOptions) (*Variable
 			return nil, err
 		}
 	}
-	if opts.Sensitive != nil {
-		v.Sensitive = *opts.Sensitive
-	}
 	if opts.HCL != nil {
 		v.HCL = *opts.HCL
 	}
+	if v.Source != nil && v.Value != "" {
+		return nil, ErrVariableSourceAndValue
+	}
 	return &v, nil
 }
 
@@ -135,7 +164,9 @@ func (v *Variable) LogValue() slog.Value {
 		slog.Bool("sensitive", v.Sensitive),
 		slog.String("parent_id", v.ParentID.String()),
 	}
-	if v.Sensitive {
+	if v.Source != nil {
+		attrs = append(attrs, slog.String("source", v.Source.String()))
+	} else if v.Sensitive {
 		attrs = append(attrs, slog.String("value", "*****"))
 	} else {
 		attrs = append(attrs, slog.String("value", v.Value))
@@ -152,11 +183,19 @@ func (v *Variable) update(opts UpdateVariableOptions) error {
 			return err
 		}
 	}
+	if opts.Source != nil {
+		if err := v.setSource(*opts.Source); err != nil {
+			return err
+		}
+	}
 	if opts.Value != nil {
 		if err := v.setValue(*opts.Value); err != nil {
 			return err
 		}
 	}
+	if v.Source != nil && v.Value != "" {
+		return ErrVariableSourceAndValue
+	}
 	if opts.Description != nil {
 		if err := v.setDescription(*opts.Description); err != nil {
 			return err
@@ -204,6 +243,25 @@ func (v *Variable) setValue(value string) error {
 	return nil
 }
 
+// setSource sets the external source of the variable's value, removing any
+// value stored in OTF. A source with an empty provider removes the source. A
+// variable with a source is always sensitive, because its value is a secret.
+func (v *Variable) setSource(src Source) error {
+	src.Provider = strings.TrimSpace(src.Provider)
+	src.Reference = strings.TrimSpace(src.Reference)
+	if src.Provider == "" {
+		v.Source = nil
+		return nil
+	}
+	if src.Reference == "" {
+		return errors.New("a variable source requires a reference")
+	}
+	v.Source = &src
+	v.Value = ""
+	v.Sensitive = true
+	return nil
+}
+
 func (v *Variable) setDescription(desc string) error {
 	if len(desc) > VariableDescriptionMaxChars {
 		return ErrVariableDescriptionMaxExceeded
@@ -229,6 +287,10 @@ func (v *Variable) setSensitive(sensitive bool) error {
 	return nil
 }
 
+func (s Source) String() string {
+	return s.Provider + ":" + s.Reference
+}
+
 // Matches determines whether variable is contained in vars, i.e. shares the
 // same ID.
 func (v *Variable) Matches(vars []*Variable) bool {
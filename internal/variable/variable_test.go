@@ -103,6 +103,57 @@ func TestVariable_Update(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "value to source",
+			opts: UpdateVariableOptions{Source: &Source{Provider: "vault", Reference: "prod/db#password"}},
+			before: Variable{
+				Key:      "foo",
+				Value:    "bar",
+				Category: CategoryTerraform,
+			},
+			after: Variable{
+				Key:       "foo",
+				Category:  CategoryTerraform,
+				Sensitive: true,
+				Source:    &Source{Provider: "vault", Reference: "prod/db#password"},
+			},
+		},
+		{
+			name: "source to value",
+			opts: UpdateVariableOptions{Source: &Source{}, Value: new("bar")},
+			before: Variable{
+				Key:       "foo",
+				Category:  CategoryTerraform,
+				Sensitive: true,
+				Source:    &Source{Provider: "vault", Reference: "prod/db#password"},
+			},
+			after: Variable{
+				Key:       "foo",
+				Value:     "bar",
+				Category:  CategoryTerraform,
+				Sensitive: true,
+			},
+		},
+		{
+			name: "value with existing source",
+			opts: UpdateVariableOptions{Value: new("bar")},
+			before: Variable{
+				Key:      "foo",
+				Category: CategoryTerraform,
+				Source:   &Source{Provider: "vault", Reference: "prod/db#password"},
+			},
+			err: true,
+		},
+		{
+			name: "source without reference",
+			opts: UpdateVariableOptions{Source: &Source{Provider: "vault"}},
+			before: Variable{
+				Key:      "foo",
+				Value:    "bar",
+				Category: CategoryTerraform,
+			},
+			err: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -118,6 +169,18 @@ func TestVariable_Update(t *testing.T) {
 	}
 }
 
+func TestNewVariable_Source(t *testing.T) {
+	// a variable with a source is sensitive regardless of the sensitive option
+	v, err := newVariable(resource.NewTfeID(resource.WorkspaceKind), CreateVariableOptions{
+		Key:       new("foo"),
+		Category:  new(CategoryTerraform),
+		Sensitive: new(false),
+		Source:    &Source{Provider: "vault", Reference: "prod/db#password"},
+	})
+	require.NoError(t, err)
+	assert.True(t, v.Sensitive)
+}
+
 func TestWriteTerraformVariables(t *testing.T) {
 	dir := t.TempDir()
 	parentID := resource.NewTfeID(resource.WorkspaceKind)
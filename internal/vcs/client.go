@@ -25,8 +25,27 @@ type (
 		ListTags(ctx context.Context, opts ListTagsOptions) ([]string, error)
 		// ListPullRequestFiles returns the paths of files that are modified in the pull request
 		ListPullRequestFiles(ctx context.Context, repo Repo, pull int) ([]string, error)
+		// GetPullRequest retrieves a pull request.
+		GetPullRequest(ctx context.Context, repo Repo, pull int) (PullRequest, error)
+		// HasWriteAccess determines whether a user has write access to the
+		// repo. The user is identified by the SenderID of an event.
+		HasWriteAccess(ctx context.Context, repo Repo, user string) (bool, error)
 		// GetCommit retrieves commit from the repo with the given git ref
 		GetCommit(ctx context.Context, repo Repo, ref string) (Commit, error)
+		// CreatePullRequestComment creates a comment on a pull request,
+		// returning the provider's unique ID for the comment.
+		CreatePullRequestComment(ctx context.Context, opts CreatePullRequestCommentOptions) (string, error)
+		// UpdatePullRequestComment replaces the body of an existing comment on
+		// a pull request.
+		UpdatePullRequestComment(ctx context.Context, opts UpdatePullRequestCommentOptions) error
+	}
+
+	// ChangedFilesLister is implemented by clients of kinds whose push events
+	// don't list the paths of changed files.
+	ChangedFilesLister interface {
+		// ListChangedFiles returns the paths of files changed between two
+		// commits.
+		ListChangedFiles(ctx context.Context, repo Repo, from, to string) ([]string, error)
 	}
 
 	// NewTokenClientOptions are options for creating a client using a personal
@@ -98,6 +117,36 @@ type (
 		Description string
 	}
 
+	// PullRequest is a pull request (or merge request) on a VCS repo.
+	PullRequest struct {
+		Number    int
+		URL       string
+		Title     string
+		Branch    string // head branch
+		CommitSHA string // head commit
+		CommitURL string
+		Approved  bool // approved by at least one reviewer
+		Mergeable bool // no conflicts with the base branch
+		Fork      bool // head branch belongs to a fork of the repo
+	}
+
+	// CreatePullRequestCommentOptions are options for creating a comment on a
+	// pull request.
+	CreatePullRequestCommentOptions struct {
+		Repo              Repo   // <owner>/<repo>
+		PullRequestNumber int    // pull request number
+		Body              string // markdown
+	}
+
+	// UpdatePullRequestCommentOptions are options for updating a comment on a
+	// pull request.
+	UpdatePullRequestCommentOptions struct {
+		Repo              Repo   // <owner>/<repo>
+		PullRequestNumber int    // pull request number
+		ID                string // vcs' comment ID
+		Body              string // markdown
+	}
+
 	Commit struct {
 		SHA    string
 		URL    string
@@ -2,10 +2,12 @@ package vcs
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
@@ -16,12 +18,18 @@ type pgdb struct {
 	// provides access to generated SQL queries
 	*sql.DB
 	kinds *kindDB
+	// cipher encrypts tokens
+	cipher *encryption.Cipher
 }
 
 func (db *pgdb) create(ctx context.Context, provider *Provider) error {
+	token, err := db.encryptToken(ctx, provider.Token)
+	if err != nil {
+		return err
+	}
 	args := pgx.NamedArgs{
 		"id":                   provider.ID,
-		"token":                provider.Token,
+		"token":                token,
 		"created_at":           provider.CreatedAt,
 		"name":                 provider.Name,
 		"vcs_kind":             provider.Kind.ID,
@@ -37,7 +45,7 @@ func (db *pgdb) create(ctx context.Context, provider *Provider) error {
 		args["install_organization"] = provider.Installation.Organization
 	}
 
-	_, err := db.Exec(ctx, `
+	_, err = db.Exec(ctx, `
 INSERT INTO vcs_providers (
     vcs_provider_id,
     token,
@@ -85,9 +93,13 @@ FOR UPDATE OF v
 		},
 		fn,
 		func(ctx context.Context, provider *Provider) error {
+			token, err := db.encryptToken(ctx, provider.Token)
+			if err != nil {
+				return err
+			}
 			args := pgx.NamedArgs{
 				"id":       provider.ID,
-				"token":    provider.Token,
+				"token":    token,
 				"name":     provider.Name,
 				"base_url": provider.BaseURL,
 			}
@@ -97,7 +109,7 @@ FOR UPDATE OF v
 				args["install_username"] = provider.Installation.Username
 				args["install_organization"] = provider.Installation.Organization
 			}
-			_, err := db.Exec(ctx, `
+			_, err = db.Exec(ctx, `
 UPDATE vcs_providers
 SET
 	name = @name,
@@ -197,6 +209,13 @@ func (db *pgdb) scanMany(ctx context.Context, row pgx.Rows) ([]*Provider, error)
 }
 
 func (db *pgdb) toProvider(ctx context.Context, m model) (*Provider, error) {
+	if m.Token != nil {
+		token, err := db.cipher.DecryptString(ctx, *m.Token)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting token for vcs provider %s: %w", m.VCSProviderID, err)
+		}
+		m.Token = &token
+	}
 	cfg := ClientConfig{
 		Token:   m.Token,
 		BaseURL: m.BaseURL,
@@ -232,3 +251,16 @@ func (db *pgdb) toProvider(ctx context.Context, m model) (*Provider, error) {
 	}
 	return &provider, nil
 }
+
+// encryptToken encrypts a token for writing to the database. A nil token is
+// returned as nil.
+func (db *pgdb) encryptToken(ctx context.Context, token *string) (*string, error) {
+	if token == nil {
+		return nil, nil
+	}
+	encrypted, err := db.cipher.EncryptString(ctx, *token)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting token: %w", err)
+	}
+	return &encrypted, nil
+}
@@ -14,6 +14,7 @@ const (
 	EventTypePull         EventType = "pull"
 	EventTypePush         EventType = "push"
 	EventTypeTag          EventType = "tag"
+	EventTypeComment      EventType = "comment" // comment on a pull request
 	EventTypeInstallation EventType = "install" // github-app installation
 )
 
@@ -73,11 +74,24 @@ type (
 		SenderUsername  string
 		SenderAvatarURL string
 		SenderHTMLURL   string
+		// SenderID identifies the sender to the provider's API, for the
+		// purposes of checking their permissions. Depending on the provider
+		// it is either their username or their unique ID. Always set for
+		// Comment event types.
+		SenderID string
+
+		// Body of a comment. Only applicable to Comment event types.
+		Comment string
 
 		// Paths of files that have been added/modified/removed. Only applicable
 		// to Push and Tag events types.
 		Paths []string
 
+		// The commit that a branch or tag pointed to prior to a push. Only set
+		// by kinds whose push events don't list the paths of changed files,
+		// in which case the paths are retrieved via ChangedFilesLister.
+		PreviousCommitSHA string
+
 		// Only set if event is from a github app
 		GithubAppInstallID *int64
 	}
@@ -3,7 +3,9 @@ package vcs
 import (
 	"context"
 
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/encryption"
 	"github.com/leg100/otf/internal/logr"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
@@ -20,6 +22,7 @@ type (
 		*authz.Authorizer
 
 		db                *pgdb
+		audit             audit.Recorder
 		beforeDeleteHooks []func(context.Context, *Provider) error
 
 		*factory
@@ -32,6 +35,9 @@ type (
 		SourceIconRegistrar SourceIconRegistrar
 		SkipTLSVerification bool
 		Authorizer          *authz.Authorizer
+		AuditRecorder       audit.Recorder
+		// Cipher encrypts tokens.
+		Cipher *encryption.Cipher
 	}
 )
 
@@ -43,10 +49,12 @@ func NewService(opts Options) *Service {
 		Authorizer: opts.Authorizer,
 		factory:    &factory,
 		db: &pgdb{
-			DB:    opts.DB,
-			kinds: kindDB,
+			DB:     opts.DB,
+			kinds:  kindDB,
+			cipher: opts.Cipher,
 		},
 		kindDB: kindDB,
+		audit:  opts.AuditRecorder,
 	}
 	return &svc
 }
@@ -67,6 +75,12 @@ func (a *Service) CreateVCSProvider(ctx context.Context, opts CreateOptions) (*P
 		return nil, err
 	}
 	a.V(0).Info("created vcs provider", "provider", provider, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: provider.Organization,
+		Action:       resource.Create,
+		ResourceID:   provider.ID,
+		After:        provider.LogValue(),
+	})
 	return provider, nil
 }
 
@@ -94,6 +108,13 @@ func (a *Service) UpdateVCSProvider(ctx context.Context, id resource.TfeID, opts
 		return nil, err
 	}
 	a.V(0).Info("updated vcs provider", "before", &before, "after", after, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: after.Organization,
+		Action:       resource.Update,
+		ResourceID:   after.ID,
+		Before:       before.LogValue(),
+		After:        after.LogValue(),
+	})
 	return after, nil
 }
 
@@ -174,6 +195,12 @@ func (a *Service) DeleteVCSProvider(ctx context.Context, id resource.TfeID) (*Pr
 		return nil, err
 	}
 	a.V(0).Info("deleted vcs provider", "provider", provider, "subject", subject)
+	a.audit.Record(ctx, audit.RecordOptions{
+		Organization: provider.Organization,
+		Action:       resource.Delete,
+		ResourceID:   provider.ID,
+		Before:       provider.LogValue(),
+	})
 	return provider, nil
 }
 
@@ -102,6 +102,7 @@ func (a *tfe) createWorkspace(w http.ResponseWriter, r *http.Request) {
 	opts := workspace.CreateOptions{
 		AgentPoolID:                params.AgentPoolID,
 		AllowDestroyPlan:           params.AllowDestroyPlan,
+		AssessmentsEnabled:         params.AssessmentsEnabled,
 		AutoApply:                  params.AutoApply,
 		AutoApplyRunTrigger:        params.AutoApplyRunTrigger,
 		Description:                params.Description,
@@ -110,6 +111,7 @@ func (a *tfe) createWorkspace(w http.ResponseWriter, r *http.Request) {
 		MigrationEnvironment:       params.MigrationEnvironment,
 		Name:                       params.Name,
 		Organization:               params.Organization,
+		Priority:                   params.Priority,
 		QueueAllRuns:               params.QueueAllRuns,
 		SpeculativeEnabled:         params.SpeculativeEnabled,
 		SourceName:                 params.SourceName,
@@ -120,6 +122,9 @@ func (a *tfe) createWorkspace(w http.ResponseWriter, r *http.Request) {
 		TriggerPatterns:            params.TriggerPatterns,
 		WorkingDirectory:           params.WorkingDirectory,
 	}
+	if params.Project != nil {
+		opts.ProjectID = &params.Project.ID
+	}
 	// convert from json:api structs to tag specs
 	opts.Tags = make([]workspace.TagSpec, len(params.Tags))
 	for i, tag := range params.Tags {
@@ -235,6 +240,7 @@ func (a *tfe) listWorkspaces(w http.ResponseWriter, r *http.Request) {
 		Organization: &pathParams.Organization,
 		PageOptions:  resource.PageOptions(params.PageOptions),
 		Tags:         internal.SplitCSV(params.Tags),
+		ProjectID:    params.ProjectID,
 	})
 	if err != nil {
 		tfeapi.Error(w, err)
@@ -330,7 +336,7 @@ func (a *tfe) unlock(w http.ResponseWriter, r *http.Request, force bool) {
 
 	ws, err := a.client.Unlock(r.Context(), id, nil, force)
 	if err != nil {
-		if internal.ErrorIs(err, workspace.ErrWorkspaceAlreadyUnlocked, workspace.ErrWorkspaceLockedByRun) {
+		if internal.ErrorIs(err, workspace.ErrWorkspaceAlreadyUnlocked, workspace.ErrWorkspaceLockedByRun, workspace.ErrWorkspaceLockedByPullRequest) {
 			tfeapi.Error(w, err, tfeapi.WithStatus(http.StatusConflict))
 		} else {
 			tfeapi.Error(w, err)
@@ -404,12 +410,14 @@ func (a *tfe) updateWorkspace(w http.ResponseWriter, r *http.Request, workspaceI
 	opts := workspace.UpdateOptions{
 		AgentPoolID:                params.AgentPoolID,
 		AllowDestroyPlan:           params.AllowDestroyPlan,
+		AssessmentsEnabled:         params.AssessmentsEnabled,
 		AutoApply:                  params.AutoApply,
 		AutoApplyRunTrigger:        params.AutoApplyRunTrigger,
 		Description:                params.Description,
 		ExecutionKind:              params.ExecutionMode,
 		GlobalRemoteState:          params.GlobalRemoteState,
 		Name:                       params.Name,
+		Priority:                   params.Priority,
 		QueueAllRuns:               params.QueueAllRuns,
 		SpeculativeEnabled:         params.SpeculativeEnabled,
 		StructuredRunOutputEnabled: params.StructuredRunOutputEnabled,
@@ -431,6 +439,12 @@ func (a *tfe) updateWorkspace(w http.ResponseWriter, r *http.Request, workspaceI
 		opts.AlwaysTrigger = new(true)
 	}
 
+	if params.Project != nil {
+		opts.UpdateProjectOptions = &workspace.UpdateProjectOptions{
+			ProjectID: &params.Project.ID,
+		}
+	}
+
 	if params.VCSRepo.Set {
 		if params.VCSRepo.Valid {
 			// client has provided non-null vcs options, which means they either
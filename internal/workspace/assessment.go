@@ -0,0 +1,36 @@
+package workspace
+
+import (
+	"time"
+
+	"github.com/leg100/otf/internal/resource"
+)
+
+const (
+	// AssessmentDrifted indicates the real infrastructure has drifted from
+	// the workspace state.
+	AssessmentDrifted AssessmentStatus = "drifted"
+	// AssessmentNoDrift indicates the real infrastructure matches the
+	// workspace state.
+	AssessmentNoDrift AssessmentStatus = "no_drift"
+	// AssessmentFailed indicates the assessment could not be completed.
+	AssessmentFailed AssessmentStatus = "failed"
+)
+
+type (
+	// AssessmentStatus is the outcome of a health assessment.
+	AssessmentStatus string
+
+	// Assessment is the outcome of the most recent health assessment of a
+	// workspace, i.e. a refresh-only plan checking for drift.
+	Assessment struct {
+		RunID      resource.TfeID
+		Status     AssessmentStatus
+		AssessedAt time.Time
+	}
+)
+
+// Drifted determines whether drift was detected.
+func (a *Assessment) Drifted() bool {
+	return a != nil && a.Status == AssessmentDrifted
+}
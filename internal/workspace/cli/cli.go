@@ -52,7 +52,10 @@ func NewCommand(apiClient *otfhttp.Client) *cobra.Command {
 }
 
 func (a *CLI) workspaceListCommand() *cobra.Command {
-	var organization organization.Name
+	var (
+		organization organization.Name
+		projectID    resource.TfeID
+	)
 
 	cmd := &cobra.Command{
 		Use:           "list",
@@ -60,11 +63,15 @@ func (a *CLI) workspaceListCommand() *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			listOpts := workspace.ListOptions{
+				Organization: &organization,
+			}
+			if cmd.Flags().Changed("project") {
+				listOpts.ProjectID = &projectID
+			}
 			list, err := resource.ListAll(func(opts resource.PageOptions) (*resource.Page[*workspace.Workspace], error) {
-				return a.client.ListWorkspaces(cmd.Context(), workspace.ListOptions{
-					PageOptions:  opts,
-					Organization: &organization,
-				})
+				listOpts.PageOptions = opts
+				return a.client.ListWorkspaces(cmd.Context(), listOpts)
 			})
 			if err != nil {
 				return fmt.Errorf("retrieving existing workspaces: %w", err)
@@ -78,6 +85,7 @@ func (a *CLI) workspaceListCommand() *cobra.Command {
 	}
 
 	cmd.Flags().Var(&organization, "organization", "Organization workspace belongs to")
+	cmd.Flags().Var(&projectID, "project", "Only list workspaces belonging to the project with this ID")
 	cmd.MarkFlagRequired("organization")
 
 	return cmd
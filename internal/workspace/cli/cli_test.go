@@ -76,6 +76,20 @@ func TestWorkspaceList(t *testing.T) {
 	want := fmt.Sprintf("%s\n%s\n", ws1.Name, ws2.Name)
 	assert.Equal(t, want, got.String())
 
+	t.Run("filter by project", func(t *testing.T) {
+		projectID := testutils.ParseID(t, "prj-123")
+		ws3 := &workspace.Workspace{Name: "in-project", ProjectID: &projectID}
+		app := &CLI{
+			client: &workspace.FakeService{Workspaces: []*workspace.Workspace{ws1, ws3}},
+		}
+		cmd := app.workspaceListCommand()
+		cmd.SetArgs([]string{"--organization", "acme-corp", "--project", "prj-123"})
+		got := bytes.Buffer{}
+		cmd.SetOut(&got)
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, "in-project\n", got.String())
+	})
+
 	t.Run("missing organization", func(t *testing.T) {
 		cmd := app.workspaceListCommand()
 		cmd.SetArgs([]string{"automatize"})
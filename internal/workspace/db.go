@@ -28,12 +28,16 @@ type pgdb struct {
 
 func (db *pgdb) create(ctx context.Context, ws *Workspace) error {
 	var (
-		allowCLIApply bool
-		branch        string
-		VCSTagsRegex  *string
+		allowCLIApply       bool
+		pullRequestComments bool
+		pullRequestCommands bool
+		branch              string
+		VCSTagsRegex        *string
 	)
 	if ws.Connection != nil {
 		allowCLIApply = ws.Connection.AllowCLIApply
+		pullRequestComments = ws.Connection.PullRequestComments
+		pullRequestCommands = ws.Connection.PullRequestCommands
 		branch = ws.Connection.Branch
 		VCSTagsRegex = &ws.Connection.TagsRegex
 	}
@@ -66,7 +70,12 @@ INSERT INTO workspaces (
     vcs_tags_regex,
     working_directory,
     organization_name,
-	engine
+	engine,
+	assessments_enabled,
+	project_id,
+	priority,
+	pull_request_comments,
+	pull_request_commands
 ) VALUES (
     $1,
     $2,
@@ -95,7 +104,12 @@ INSERT INTO workspaces (
     $25,
     $26,
 	$27,
-	$28
+	$28,
+	$29,
+	$30,
+	$31,
+	$32,
+	$33
 )
 `,
 		ws.ID,
@@ -126,6 +140,11 @@ INSERT INTO workspaces (
 		ws.WorkingDirectory,
 		ws.Organization,
 		ws.Engine,
+		ws.AssessmentsEnabled,
+		ws.ProjectID,
+		ws.Priority,
+		pullRequestComments,
+		pullRequestCommands,
 	)
 	return err
 }
@@ -140,12 +159,16 @@ func (db *pgdb) update(ctx context.Context, workspaceID resource.TfeID, fn func(
 		fn,
 		func(ctx context.Context, ws *Workspace) error {
 			var (
-				allowCLIApply bool
-				branch        string
-				VCSTagsRegex  *string
+				allowCLIApply       bool
+				pullRequestComments bool
+				pullRequestCommands bool
+				branch              string
+				VCSTagsRegex        *string
 			)
 			if ws.Connection != nil {
 				allowCLIApply = ws.Connection.AllowCLIApply
+				pullRequestComments = ws.Connection.PullRequestComments
+				pullRequestCommands = ws.Connection.PullRequestCommands
 				branch = ws.Connection.Branch
 				VCSTagsRegex = &ws.Connection.TagsRegex
 			}
@@ -172,8 +195,13 @@ func (db *pgdb) update(ctx context.Context, workspaceID resource.TfeID, fn func(
 					working_directory             = $18,
 					updated_at                    = $19,
 					engine                        = $20,
-					ssh_key_id                    = $21
-				WHERE workspace_id = $22
+					ssh_key_id                    = $21,
+					assessments_enabled           = $22,
+					project_id                    = $23,
+					priority                      = $24,
+					pull_request_comments         = $25,
+					pull_request_commands         = $26
+				WHERE workspace_id = $27
 			`,
 				ws.Mode.AgentPoolID(),
 				ws.AllowDestroyPlan,
@@ -196,6 +224,11 @@ func (db *pgdb) update(ctx context.Context, workspaceID resource.TfeID, fn func(
 				ws.UpdatedAt,
 				ws.Engine,
 				ws.SSHKeyID,
+				ws.AssessmentsEnabled,
+				ws.ProjectID,
+				ws.Priority,
+				pullRequestComments,
+				pullRequestCommands,
 				ws.ID,
 			)
 			return err
@@ -225,6 +258,27 @@ FOR UPDATE OF w
 	return sql.CollectOneRow(row, scan)
 }
 
+// setAssessment records the outcome of a health assessment of the specified
+// workspace.
+func (db *pgdb) setAssessment(ctx context.Context, workspaceID resource.TfeID, assessment Assessment) (*Workspace, error) {
+	_, err := db.Exec(ctx, `
+UPDATE workspaces
+SET assessment_run_id = $1,
+    assessment_status = $2,
+    assessed_at = $3
+WHERE workspace_id = $4
+`,
+		assessment.RunID,
+		assessment.Status,
+		assessment.AssessedAt,
+		workspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.get(ctx, workspaceID)
+}
+
 // setLatestRun sets the ID of the current run for the specified workspace.
 func (db *pgdb) setLatestRun(ctx context.Context, workspaceID, runID resource.TfeID) (*Workspace, error) {
 	_, err := db.Exec(ctx, `
@@ -254,6 +308,11 @@ func (db *pgdb) list(ctx context.Context, opts ListOptions) (*resource.Page[*Wor
 	if len(opts.Status) > 0 {
 		status = internal.ToStringSlice(opts.Status)
 	}
+	// Project is optional.
+	var projectID *resource.TfeID
+	if opts.ProjectID != nil && !opts.ProjectID.IsZero() {
+		projectID = opts.ProjectID
+	}
 
 	rows := db.Query(ctx, `
 WITH tags_grouped_by_workspace AS (
@@ -276,6 +335,7 @@ WHERE w.name                LIKE '%' || @search || '%'
 AND   w.organization_name   LIKE ANY(@organization::text[])
 AND   ((@status::text[] IS NULL) OR (r.status = ANY(@status::text[])))
 AND   ((@tags::text[] IS NULL) OR (cardinality(@tags::text[]) = 0) OR (t.tags @> @tags::text[]))
+AND   ((@project_id::text IS NULL) OR (w.project_id = @project_id))
 ORDER BY w.name ASC
 LIMIT @limit::int
 OFFSET @offset::int
@@ -284,6 +344,7 @@ OFFSET @offset::int
 		"organization": []string{organization},
 		"status":       status,
 		"tags":         opts.Tags,
+		"project_id":   projectID,
 		"limit":        sql.GetLimit(opts.PageOptions),
 		"offset":       sql.GetOffset(opts.PageOptions),
 	})
@@ -309,6 +370,7 @@ workspaces AS (
 	AND   w.organization_name LIKE ANY(@organization::text[])
 	AND   ((@status::text[] IS NULL) OR (r.status = ANY(@status::text[])))
 	AND   ((@tags::text[] IS NULL) OR (cardinality(@tags::text[]) = 0) OR (t.tags @> @tags::text[]))
+	AND   ((@project_id::text IS NULL) OR (w.project_id = @project_id))
 )
 SELECT count(*)
 FROM workspaces
@@ -317,6 +379,7 @@ FROM workspaces
 		"organization": []string{organization},
 		"status":       status,
 		"tags":         opts.Tags,
+		"project_id":   projectID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("counting workspaces: %w", err)
@@ -366,14 +429,17 @@ SELECT
     r.status AS latest_run_status,
 	(rc.*)::"repo_connections" AS connection
 FROM workspaces w
-JOIN workspace_permissions p USING (workspace_id)
 LEFT JOIN runs r ON w.latest_run_id = r.run_id
 LEFT JOIN repo_connections rc ON w.workspace_id = rc.workspace_id
-JOIN teams t USING (team_id)
-JOIN team_memberships tm USING (team_id)
-JOIN users u ON tm.username = u.username
 WHERE w.organization_name  = $1
-AND   u.username           = $2
+AND   EXISTS (
+    SELECT 1
+    FROM team_memberships tm
+    LEFT JOIN workspace_permissions wp ON wp.team_id = tm.team_id AND wp.workspace_id = w.workspace_id
+    LEFT JOIN project_permissions pp ON pp.team_id = tm.team_id AND pp.project_id = w.project_id
+    WHERE tm.username = $2
+    AND   (wp.team_id IS NOT NULL OR pp.team_id IS NOT NULL)
+)
 ORDER BY w.updated_at DESC
 LIMIT $3::int
 OFFSET $4::int
@@ -391,12 +457,15 @@ OFFSET $4::int
 	count, err := db.Int(ctx, `
 SELECT count(*)
 FROM workspaces w
-JOIN workspace_permissions p USING (workspace_id)
-JOIN teams t USING (team_id)
-JOIN team_memberships tm USING (team_id)
-JOIN users u USING (username)
 WHERE w.organization_name = $1
-AND   u.username          = $2
+AND   EXISTS (
+    SELECT 1
+    FROM team_memberships tm
+    LEFT JOIN workspace_permissions wp ON wp.team_id = tm.team_id AND wp.workspace_id = w.workspace_id
+    LEFT JOIN project_permissions pp ON pp.team_id = tm.team_id AND pp.project_id = w.project_id
+    WHERE tm.username = $2
+    AND   (wp.team_id IS NOT NULL OR pp.team_id IS NOT NULL)
+)
 `,
 		organization,
 		username,
@@ -505,6 +574,27 @@ AND team_id = $2
 	return nil
 }
 
+// checkProject checks that the project the workspace belongs to, if any, is in
+// the same organization as the workspace.
+func (db *pgdb) checkProject(ctx context.Context, ws *Workspace) error {
+	if ws.ProjectID == nil {
+		return nil
+	}
+	var org organization.Name
+	err := db.QueryRow(ctx, `
+SELECT organization_name
+FROM projects
+WHERE project_id = $1
+`, ws.ProjectID).Scan(&org)
+	if err != nil {
+		return err
+	}
+	if org != ws.Organization {
+		return ErrProjectInDifferentOrganization
+	}
+	return nil
+}
+
 func (db *pgdb) GetWorkspacePolicy(ctx context.Context, workspaceID resource.ID) (Policy, error) {
 	row := db.QueryRow(ctx, `
 SELECT
@@ -513,7 +603,12 @@ SELECT
         SELECT array_agg(wp.*)::workspace_permissions[]
         FROM workspace_permissions wp
         WHERE wp.workspace_id = w.workspace_id
-    ) AS workspace_permissions
+    ) AS workspace_permissions,
+    (
+        SELECT array_agg(pp.*)::project_permissions[]
+        FROM project_permissions pp
+        WHERE pp.project_id = w.project_id
+    ) AS project_permissions
 FROM workspaces w
 WHERE w.workspace_id = $1
 `,
@@ -523,16 +618,23 @@ WHERE w.workspace_id = $1
 		TeamID      resource.TfeID `db:"team_id"`
 		Role        string
 	}
+	type projectPermissionModel struct {
+		ProjectID resource.TfeID `db:"project_id"`
+		TeamID    resource.TfeID `db:"team_id"`
+		Role      string
+	}
 	var (
 		globalRemoteState bool
 		perms             []workspacePermissionModel
+		projectPerms      []projectPermissionModel
 	)
-	if err := row.Scan(&globalRemoteState, &perms); err != nil {
+	if err := row.Scan(&globalRemoteState, &perms, &projectPerms); err != nil {
 		return Policy{}, err
 	}
 	policy := Policy{
-		globalRemoteState: globalRemoteState,
-		Permissions:       make([]Permission, len(perms)),
+		globalRemoteState:  globalRemoteState,
+		Permissions:        make([]Permission, len(perms)),
+		ProjectPermissions: make([]Permission, len(projectPerms)),
 	}
 	for i, perm := range perms {
 		role, err := authz.WorkspaceRoleFromString(perm.Role)
@@ -544,6 +646,16 @@ WHERE w.workspace_id = $1
 			Role:   role,
 		}
 	}
+	for i, perm := range projectPerms {
+		role, err := authz.WorkspaceRoleFromString(perm.Role)
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.ProjectPermissions[i] = Permission{
+			TeamID: perm.TeamID,
+			Role:   role,
+		}
+	}
 	return policy, nil
 }
 
@@ -555,6 +667,10 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		AgentPoolID                *resource.TfeID   `db:"agent_pool_id"`
 		AllowDestroyPlan           bool              `db:"allow_destroy_plan"`
 		AllowCLIApply              bool              `db:"allow_cli_apply"`
+		AssessmentsEnabled         bool              `db:"assessments_enabled"`
+		AssessmentStatus           *AssessmentStatus `db:"assessment_status"`
+		AssessmentRunID            *resource.TfeID   `db:"assessment_run_id"`
+		AssessedAt                 *time.Time        `db:"assessed_at"`
 		AutoApply                  bool              `db:"auto_apply"`
 		AutoApplyRunTrigger        bool              `db:"auto_apply_run_trigger"`
 		Branch                     string            `db:"branch"`
@@ -565,6 +681,7 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		GlobalRemoteState          bool              `db:"global_remote_state"`
 		MigrationEnvironment       string            `db:"migration_environment"`
 		Name                       string            `db:"name"`
+		Priority                   int               `db:"priority"`
 		QueueAllRuns               bool              `db:"queue_all_runs"`
 		SpeculativeEnabled         bool              `db:"speculative_enabled"`
 		StructuredRunOutputEnabled bool              `db:"structured_run_output_enabled"`
@@ -581,8 +698,13 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		VCSTagsRegex               *string           `db:"vcs_tags_regex"`
 		LockUsername               *user.Username    `db:"lock_username"`
 		LockRunID                  *resource.TfeID   `db:"lock_run_id"`
+		LockPullRequestRepo        *vcs.Repo         `db:"lock_pull_request_repo"`
+		LockPullRequestNumber      *int              `db:"lock_pull_request_number"`
 		CurrentStateVersionID      *resource.TfeID   `db:"current_state_version_id"`
 		SSHKeyID                   *resource.TfeID   `db:"ssh_key_id"`
+		ProjectID                  *resource.TfeID   `db:"project_id"`
+		PullRequestComments        bool              `db:"pull_request_comments"`
+		PullRequestCommands        bool              `db:"pull_request_commands"`
 		Connection                 *connections.Connection
 		Engine                     *engine.Engine `db:"engine"`
 	}
@@ -595,6 +717,7 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		CreatedAt:                  m.CreatedAt,
 		UpdatedAt:                  m.UpdatedAt,
 		AllowDestroyPlan:           m.AllowDestroyPlan,
+		AssessmentsEnabled:         m.AssessmentsEnabled,
 		AutoApply:                  m.AutoApply,
 		AutoApplyRunTrigger:        m.AutoApplyRunTrigger,
 		CanQueueDestroyPlan:        m.CanQueueDestroyPlan,
@@ -603,6 +726,7 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		GlobalRemoteState:          m.GlobalRemoteState,
 		MigrationEnvironment:       m.MigrationEnvironment,
 		Name:                       m.Name,
+		Priority:                   m.Priority,
 		QueueAllRuns:               m.QueueAllRuns,
 		SpeculativeEnabled:         m.SpeculativeEnabled,
 		StructuredRunOutputEnabled: m.StructuredRunOutputEnabled,
@@ -616,6 +740,7 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		TriggerPrefixes:            m.TriggerPrefixes,
 		Engine:                     m.Engine,
 		SSHKeyID:                   m.SSHKeyID,
+		ProjectID:                  m.ProjectID,
 	}
 
 	mode, err := execution.NewMode(m.ExecutionKind, m.AgentPoolID)
@@ -626,10 +751,12 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 
 	if m.Connection != nil {
 		ws.Connection = &Connection{
-			AllowCLIApply: m.AllowCLIApply,
-			VCSProviderID: m.Connection.VCSProviderID,
-			Repo:          m.Connection.Repo,
-			Branch:        m.Branch,
+			AllowCLIApply:       m.AllowCLIApply,
+			PullRequestComments: m.PullRequestComments,
+			PullRequestCommands: m.PullRequestCommands,
+			VCSProviderID:       m.Connection.VCSProviderID,
+			Repo:                m.Connection.Repo,
+			Branch:              m.Branch,
 		}
 		if m.VCSTagsRegex != nil {
 			ws.Connection.TagsRegex = *m.VCSTagsRegex
@@ -642,10 +769,23 @@ func scan(row pgx.CollectableRow) (*Workspace, error) {
 		}
 	}
 
+	if m.AssessmentStatus != nil && m.AssessmentRunID != nil && m.AssessedAt != nil {
+		ws.Assessment = &Assessment{
+			RunID:      *m.AssessmentRunID,
+			Status:     *m.AssessmentStatus,
+			AssessedAt: *m.AssessedAt,
+		}
+	}
+
 	if m.LockUsername != nil {
 		ws.Lock = *m.LockUsername
 	} else if m.LockRunID != nil {
 		ws.Lock = *m.LockRunID
+	} else if m.LockPullRequestRepo != nil && m.LockPullRequestNumber != nil {
+		ws.Lock = PullRequestLock{
+			Repo:   *m.LockPullRequestRepo,
+			Number: *m.LockPullRequestNumber,
+		}
 	}
 	return ws, err
 }
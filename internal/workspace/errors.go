@@ -6,6 +6,7 @@ var (
 	ErrWorkspaceAlreadyLocked         = errors.New("workspace already locked")
 	ErrWorkspaceLockedByDifferentUser = errors.New("workspace locked by different user")
 	ErrWorkspaceLockedByRun           = errors.New("workspace is locked by Run")
+	ErrWorkspaceLockedByPullRequest   = errors.New("workspace is locked by pull request")
 	ErrWorkspaceAlreadyUnlocked       = errors.New("workspace already unlocked")
 	ErrWorkspaceUnlockDenied          = errors.New("unauthorized to unlock workspace")
 	ErrWorkspaceInvalidLock           = errors.New("invalid workspace lock")
@@ -16,4 +17,6 @@ var (
 	ErrTriggerPatternsAndAlwaysTrigger = errors.New("cannot specify both trigger-patterns and always-trigger")
 	ErrInvalidTriggerPattern           = errors.New("invalid trigger glob pattern")
 	ErrInvalidTagsRegex                = errors.New("invalid vcs tags regular expression")
+
+	ErrProjectInDifferentOrganization = errors.New("project belongs to a different organization")
 )
@@ -4,6 +4,7 @@ import (
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/user"
+	"github.com/leg100/otf/internal/vcs"
 )
 
 type Event struct {
@@ -12,12 +13,15 @@ type Event struct {
 	Organization organization.Name `json:"organization_name"`
 	LockUsername *user.Username    `json:"lock_username"`
 	LockRunID    *resource.TfeID   `json:"lock_run_id"`
+
+	LockPullRequestRepo   *vcs.Repo `json:"lock_pull_request_repo"`
+	LockPullRequestNumber *int      `json:"lock_pull_request_number"`
 }
 
 func (e Event) GetID() resource.TfeID { return e.ID }
 
 func (e Event) Locked() bool {
-	return e.LockUsername != nil || e.LockRunID != nil
+	return e.LockUsername != nil || e.LockRunID != nil || e.LockPullRequestNumber != nil
 }
 
 func (e Event) Lock() resource.ID {
@@ -27,5 +31,11 @@ func (e Event) Lock() resource.ID {
 	if e.LockRunID != nil {
 		return *e.LockRunID
 	}
+	if e.LockPullRequestRepo != nil && e.LockPullRequestNumber != nil {
+		return PullRequestLock{
+			Repo:   *e.LockPullRequestRepo,
+			Number: *e.LockPullRequestNumber,
+		}
+	}
 	return nil
 }
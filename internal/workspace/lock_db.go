@@ -5,6 +5,7 @@ import (
 
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sql"
+	"github.com/leg100/otf/internal/vcs"
 )
 
 // toggleLock toggles the workspace lock state in the DB.
@@ -21,6 +22,8 @@ func (db *pgdb) toggleLock(ctx context.Context, workspaceID resource.TfeID, togg
 		func(ctx context.Context, ws *Workspace) error {
 			var (
 				runID, username resource.ID
+				pullRepo        *vcs.Repo
+				pullNumber      *int
 			)
 			if ws.Locked() {
 				switch ws.Lock.Kind() {
@@ -28,6 +31,13 @@ func (db *pgdb) toggleLock(ctx context.Context, workspaceID resource.TfeID, togg
 					runID = ws.Lock
 				case resource.UserKind:
 					username = ws.Lock
+				case resource.PullRequestKind:
+					lock, ok := ws.Lock.(PullRequestLock)
+					if !ok {
+						return ErrWorkspaceInvalidLock
+					}
+					pullRepo = &lock.Repo
+					pullNumber = &lock.Number
 				default:
 					return ErrWorkspaceInvalidLock
 				}
@@ -36,11 +46,15 @@ func (db *pgdb) toggleLock(ctx context.Context, workspaceID resource.TfeID, togg
 UPDATE workspaces
 SET
     lock_username = $1,
-    lock_run_id = $2
-WHERE workspace_id = $3
+    lock_run_id = $2,
+    lock_pull_request_repo = $3,
+    lock_pull_request_number = $4
+WHERE workspace_id = $5
 `,
 				username,
 				runID,
+				pullRepo,
+				pullNumber,
 				workspaceID,
 			)
 			return err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/user"
 )
@@ -34,6 +35,15 @@ func (s *Service) Lock(ctx context.Context, workspaceID resource.TfeID, runID *r
 		return nil, err
 	}
 	s.V(1).Info("locked workspace", "subject", id, "workspace", workspaceID)
+	// Only record locks made by users; locks made on behalf of runs are
+	// recorded as part of the run.
+	if runID == nil {
+		s.audit.Record(ctx, audit.RecordOptions{
+			Organization: ws.Organization,
+			Action:       resource.Lock,
+			ResourceID:   ws.ID,
+		})
+	}
 
 	return ws, nil
 }
@@ -71,6 +81,76 @@ func (s *Service) Unlock(ctx context.Context, workspaceID resource.TfeID, runID
 		return nil, err
 	}
 	s.V(1).Info("unlocked workspace", "subject", id, "workspace", workspaceID, "forced", force)
+	if runID == nil {
+		action := resource.Unlock
+		if force {
+			action = resource.ForceUnlock
+		}
+		s.audit.Record(ctx, audit.RecordOptions{
+			Organization: ws.Organization,
+			Action:       action,
+			ResourceID:   ws.ID,
+		})
+	}
+
+	return ws, nil
+}
+
+// LockPullRequest locks the workspace on behalf of a pull request. Locking a
+// workspace that is already locked by the pull request has no effect.
+func (s *Service) LockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock PullRequestLock) (*Workspace, error) {
+	subject, err := s.Authorize(ctx, resource.Lock, resource.WorkspaceKind, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	var acquired bool
+	ws, err := s.db.toggleLock(ctx, workspaceID, func(ws *Workspace) error {
+		acquired = !ws.Locked()
+		return ws.Enlock(lock)
+	})
+	if err != nil {
+		s.Error(err, "locking workspace", "subject", subject, "pull_request", lock, "workspace", workspaceID)
+		return nil, err
+	}
+	if acquired {
+		s.V(1).Info("locked workspace", "subject", subject, "pull_request", lock, "workspace", workspaceID)
+		s.audit.Record(ctx, audit.RecordOptions{
+			Organization: ws.Organization,
+			Action:       resource.Lock,
+			ResourceID:   ws.ID,
+			After:        map[string]any{"pull_request": lock.String()},
+		})
+	}
+	return ws, nil
+}
 
+// UnlockPullRequest releases the workspace lock if it is held by the pull
+// request; otherwise it has no effect.
+func (s *Service) UnlockPullRequest(ctx context.Context, workspaceID resource.TfeID, lock PullRequestLock) (*Workspace, error) {
+	subject, err := s.Authorize(ctx, resource.Unlock, resource.WorkspaceKind, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	var released bool
+	ws, err := s.db.toggleLock(ctx, workspaceID, func(ws *Workspace) error {
+		if ws.Lock != lock {
+			return nil
+		}
+		released = true
+		return ws.Unlock(lock, false)
+	})
+	if err != nil {
+		s.Error(err, "unlocking workspace", "subject", subject, "pull_request", lock, "workspace", workspaceID)
+		return nil, err
+	}
+	if released {
+		s.V(1).Info("unlocked workspace", "subject", subject, "pull_request", lock, "workspace", workspaceID)
+		s.audit.Record(ctx, audit.RecordOptions{
+			Organization: ws.Organization,
+			Action:       resource.Unlock,
+			ResourceID:   ws.ID,
+			Before:       map[string]any{"pull_request": lock.String()},
+		})
+	}
 	return ws, nil
 }
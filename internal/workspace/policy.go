@@ -7,6 +7,10 @@ import (
 
 type Policy struct {
 	Permissions []Permission
+	// ProjectPermissions are the permissions assigned to the project the
+	// workspace belongs to, if any. They apply to teams lacking a
+	// workspace-specific permission.
+	ProjectPermissions []Permission
 	// Whether workspace permits its state to be consumed by all workspaces in
 	// the organization.
 	globalRemoteState bool
@@ -24,12 +28,18 @@ func (p Policy) Check(subject resource.ID, action resource.Action, kind resource
 	switch subject.Kind() {
 	case resource.TeamKind:
 		// Team can only access workspace if a specific permission has been
-		// assigned to the team.
+		// assigned to the team, either on the workspace or on its project. A
+		// workspace permission takes precedence over a project permission.
 		for _, perm := range p.Permissions {
 			if subject == perm.TeamID {
 				return perm.Role.IsAllowed(action, kind)
 			}
 		}
+		for _, perm := range p.ProjectPermissions {
+			if subject == perm.TeamID {
+				return perm.Role.IsAllowed(action, kind)
+			}
+		}
 	case resource.JobKind:
 		// Job is allowed to retrieve the state of this workspace if the
 		// workspace has allowed global remote state sharing.
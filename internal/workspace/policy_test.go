@@ -0,0 +1,45 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/leg100/otf/internal/authz"
+	"github.com/leg100/otf/internal/resource"
+	"github.com/leg100/otf/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	var (
+		team1 = testutils.ParseID(t, "team-1")
+		team2 = testutils.ParseID(t, "team-2")
+		team3 = testutils.ParseID(t, "team-3")
+	)
+	policy := Policy{
+		Permissions: []Permission{
+			{TeamID: team1, Role: authz.WorkspaceReadRole},
+		},
+		ProjectPermissions: []Permission{
+			{TeamID: team1, Role: authz.WorkspaceAdminRole},
+			{TeamID: team2, Role: authz.WorkspaceWriteRole},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		subject resource.ID
+		action  resource.Action
+		want    bool
+	}{
+		{"workspace permission takes precedence", team1, resource.Apply, false},
+		{"workspace permission", team1, resource.Get, true},
+		{"project permission", team2, resource.Apply, true},
+		{"no permission", team3, resource.Get, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Check(tt.subject, tt.action, resource.RunKind)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
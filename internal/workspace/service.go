@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/leg100/otf/internal"
+	"github.com/leg100/otf/internal/audit"
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/connections"
 	"github.com/leg100/otf/internal/engine"
@@ -30,6 +31,7 @@ type (
 		db          *pgdb
 		broker      pubsub.SubscriptionService[*Event]
 		connections *connections.Service
+		audit       audit.Recorder
 
 		beforeCreateHooks []func(context.Context, *Workspace) error
 		afterCreateHooks  []func(context.Context, *Workspace) error
@@ -45,11 +47,13 @@ type (
 		DefaultEngine     *engine.Engine
 		Broker            pubsub.SubscriptionService[*Event]
 		Client            serviceClient
+		AuditRecorder     audit.Recorder
 	}
 
 	serviceClient interface {
 		GetOrganization(ctx context.Context, name organization.Name) (*organization.Organization, error)
 		GetLatest(ctx context.Context, engine *engine.Engine) (string, time.Time, error)
+		GetEngine(ctx context.Context, name string) (*engine.Engine, error)
 	}
 )
 
@@ -60,6 +64,7 @@ func NewService(opts Options) *Service {
 		Authorizer:  opts.Authorizer,
 		db:          db,
 		connections: opts.ConnectionService,
+		audit:       opts.AuditRecorder,
 		factory: &factory{
 			defaultEngine: opts.DefaultEngine,
 			client:        opts.Client,
@@ -85,6 +90,14 @@ func NewService(opts Options) *Service {
 	opts.Authorizer.WorkspacePolicyGetter = func(ctx context.Context, workspaceID resource.ID) (authz.WorkspacePolicy, error) {
 		return db.GetWorkspacePolicy(ctx, workspaceID)
 	}
+	// Provide the authorizer with the ability to retrieve workspace tags.
+	opts.Authorizer.WorkspaceTagsGetter = func(ctx context.Context, workspaceID resource.ID) ([]string, error) {
+		ws, err := db.get(ctx, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		return ws.Tags, nil
+	}
 	return &svc
 }
 
@@ -110,6 +123,9 @@ func (s *Service) CreateWorkspace(ctx context.Context, opts CreateOptions) (*Wor
 				return err
 			}
 		}
+		if err := s.db.checkProject(ctx, ws); err != nil {
+			return err
+		}
 		if err := s.db.create(ctx, ws); err != nil {
 			return err
 		}
@@ -140,6 +156,12 @@ func (s *Service) CreateWorkspace(ctx context.Context, opts CreateOptions) (*Wor
 	}
 
 	s.V(0).Info("created workspace", "id", ws.ID, "name", ws.Name, "organization", ws.Organization, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: ws.Organization,
+		Action:       resource.Create,
+		ResourceID:   ws.ID,
+		After:        ws,
+	})
 
 	return ws, nil
 }
@@ -229,17 +251,37 @@ func (s *Service) UpdateWorkspace(ctx context.Context, workspaceID resource.TfeI
 	}
 
 	// update the workspace and optionally connect/disconnect to/from vcs repo.
-	var updated *Workspace
+	var before, updated *Workspace
 	err = s.db.Tx(ctx, func(ctx context.Context) error {
 		var connect *bool
 		updated, err = s.db.update(ctx, workspaceID, func(ctx context.Context, ws *Workspace) (err error) {
+			before = new(*ws)
 			for _, hook := range s.beforeUpdateHooks {
 				if err := hook(ctx, ws); err != nil {
 					return err
 				}
 			}
 			connect, err = ws.Update(opts)
-			return err
+			if err != nil {
+				return err
+			}
+			if (opts.Engine != nil || opts.EngineVersion != nil) && !ws.EngineVersion.Latest {
+				eng, err := s.client.GetEngine(ctx, ws.Engine.String())
+				if err != nil {
+					return err
+				}
+				if err := eng.CheckVersion(ws.EngineVersion.String()); err != nil {
+					return err
+				}
+				org, err := s.client.GetOrganization(ctx, ws.Organization)
+				if err != nil {
+					return err
+				}
+				if err := org.CheckEngineVersion(ws.EngineVersion.String()); err != nil {
+					return err
+				}
+			}
+			return s.db.checkProject(ctx, ws)
 		})
 		if err != nil {
 			return err
@@ -263,6 +305,13 @@ func (s *Service) UpdateWorkspace(ctx context.Context, workspaceID resource.TfeI
 	}
 
 	s.V(0).Info("updated workspace", "workspace", workspaceID, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: updated.Organization,
+		Action:       resource.Update,
+		ResourceID:   updated.ID,
+		Before:       before,
+		After:        updated,
+	})
 
 	return updated, nil
 }
@@ -291,6 +340,12 @@ func (s *Service) DeleteWorkspace(ctx context.Context, workspaceID resource.TfeI
 	}
 
 	s.V(0).Info("deleted workspace", "id", ws.ID, "name", ws.Name, "subject", subject)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Organization: ws.Organization,
+		Action:       resource.Delete,
+		ResourceID:   ws.ID,
+		Before:       ws,
+	})
 
 	return ws, nil
 }
@@ -307,6 +362,12 @@ func (s *Service) SetWorkspacePermission(ctx context.Context, workspaceID, teamI
 	}
 
 	s.V(0).Info("set workspace permission", "team_id", teamID, "role", role, "subject", subject, "workspace", workspaceID)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     workspaceID,
+		Action:     resource.SetPermission,
+		ResourceID: workspaceID,
+		After:      map[string]any{"team_id": teamID, "role": role.String()},
+	})
 
 	// TODO: publish event
 
@@ -320,9 +381,20 @@ func (s *Service) UnsetWorkspacePermission(ctx context.Context, workspaceID, tea
 		return err
 	}
 
+	if err := s.db.UnsetWorkspacePermission(ctx, workspaceID, teamID); err != nil {
+		s.Error(err, "unsetting workspace permission", "team_id", teamID, "subject", subject, "workspace", workspaceID)
+		return err
+	}
+
 	s.V(0).Info("unset workspace permission", "team_id", teamID, "subject", subject, "workspace", workspaceID)
+	s.audit.Record(ctx, audit.RecordOptions{
+		Parent:     workspaceID,
+		Action:     resource.UnsetPermission,
+		ResourceID: workspaceID,
+		Before:     map[string]any{"team_id": teamID},
+	})
 	// TODO: publish event
-	return s.db.UnsetWorkspacePermission(ctx, workspaceID, teamID)
+	return nil
 }
 
 // GetWorkspacePolicy retrieves the authorization policy for a workspace.
@@ -373,6 +445,18 @@ func (s *Service) disconnect(ctx context.Context, workspaceID resource.TfeID) er
 	return nil
 }
 
+// SetWorkspaceAssessment records the outcome of a health assessment of the
+// workspace.
+func (s *Service) SetWorkspaceAssessment(ctx context.Context, workspaceID resource.TfeID, assessment Assessment) (*Workspace, error) {
+	ws, err := s.db.setAssessment(ctx, workspaceID, assessment)
+	if err != nil {
+		s.Error(err, "recording assessment", "workspace", workspaceID, "run", assessment.RunID)
+		return nil, err
+	}
+	s.V(1).Info("recorded assessment", "workspace", workspaceID, "run", assessment.RunID, "status", assessment.Status)
+	return ws, nil
+}
+
 // SetWorkspaceLatestRun sets the latest run for the workspace
 func (s *Service) SetWorkspaceLatestRun(ctx context.Context, workspaceID, runID resource.TfeID) (*Workspace, error) {
 	return s.db.setLatestRun(ctx, workspaceID, runID)
@@ -62,7 +62,16 @@ func (f *FakeService) UpdateWorkspace(_ context.Context, _ resource.TfeID, opts
 }
 
 func (f *FakeService) ListWorkspaces(ctx context.Context, opts ListOptions) (*resource.Page[*Workspace], error) {
-	return resource.NewPage(f.Workspaces, opts.PageOptions, nil), nil
+	workspaces := f.Workspaces
+	if opts.ProjectID != nil {
+		workspaces = nil
+		for _, ws := range f.Workspaces {
+			if ws.ProjectID != nil && *ws.ProjectID == *opts.ProjectID {
+				workspaces = append(workspaces, ws)
+			}
+		}
+	}
+	return resource.NewPage(workspaces, opts.PageOptions, nil), nil
 }
 
 func (f *FakeService) GetWorkspace(context.Context, resource.TfeID) (*Workspace, error) {
@@ -122,6 +131,14 @@ func (f *fakeClient) GetOrganization(ctx context.Context, name organization.Name
 	return f.org, nil
 }
 
+func (f *fakeClient) GetEngine(ctx context.Context, name string) (*engine.Engine, error) {
+	var e engine.Engine
+	if err := e.Set(name); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
 func (f *fakeClient) GetLatest(ctx context.Context, engine *engine.Engine) (string, time.Time, error) {
 	return f.latestVersion, time.Time{}, nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/leg100/otf/internal/authz"
 	"github.com/leg100/otf/internal/configversion"
 	"github.com/leg100/otf/internal/organization"
+	"github.com/leg100/otf/internal/project"
 	"github.com/leg100/otf/internal/resource"
 	"github.com/leg100/otf/internal/sshkey"
 	"github.com/leg100/otf/internal/tfeapi"
@@ -23,6 +24,7 @@ type TFEWorkspace struct {
 	Actions                    *TFEWorkspaceActions           `jsonapi:"attribute" json:"actions"`
 	AgentPoolID                *resource.TfeID                `jsonapi:"attribute" json:"agent-pool-id"`
 	AllowDestroyPlan           bool                           `jsonapi:"attribute" json:"allow-destroy-plan"`
+	AssessmentsEnabled         bool                           `jsonapi:"attribute" json:"assessments-enabled"`
 	AutoApply                  bool                           `jsonapi:"attribute" json:"auto-apply"`
 	AutoApplyRunTrigger        bool                           `jsonapi:"attribute" json:"auto-apply-run-trigger"`
 	CanQueueDestroyPlan        bool                           `jsonapi:"attribute" json:"can-queue-destroy-plan"`
@@ -52,6 +54,7 @@ type TFEWorkspace struct {
 	ApplyDurationAverage       time.Duration                  `jsonapi:"attribute" json:"apply-duration-average"`
 	PlanDurationAverage        time.Duration                  `jsonapi:"attribute" json:"plan-duration-average"`
 	PolicyCheckFailures        int                            `jsonapi:"attribute" json:"policy-check-failures"`
+	Priority                   int                            `jsonapi:"attribute" json:"priority"`
 	RunFailures                int                            `jsonapi:"attribute" json:"run-failures"`
 	RunsCount                  int                            `jsonapi:"attribute" json:"workspace-kpis-runs-count"`
 	TagNames                   []string                       `jsonapi:"attribute" json:"tag-names"`
@@ -63,6 +66,7 @@ type TFEWorkspace struct {
 	Organization                *organization.TFEOrganization          `jsonapi:"relationship" json:"organization"`
 	Outputs                     []*TFEWorkspaceOutput                  `jsonapi:"relationship" json:"outputs"`
 	SSHKey                      *sshkey.TFESSHKey                      `jsonapi:"relationship" json:"ssh-key"`
+	Project                     *project.TFEProject                    `jsonapi:"relationship" json:"project"`
 }
 
 type TFEWorkspaceSettingOverwrites struct {
@@ -136,7 +140,7 @@ type TFEWorkspaceListOptions struct {
 	WildcardName string `schema:"search[wildcard-name],omitempty"`
 
 	// Optional: A filter string to list all the workspaces linked to a given project id in the organization.
-	ProjectID resource.TfeID `schema:"filter[project][id],omitempty"`
+	ProjectID *resource.TfeID `schema:"filter[project][id],omitempty"`
 
 	// Optional: A list of relations to include. See available resources https://developer.hashicorp.com/terraform/cloud-docs/api-docs/workspaces#available-related-resources
 	// Include []WSIncludeOpt `url:"include,omitempty"`
@@ -158,6 +162,9 @@ type TFEWorkspaceCreateOptions struct {
 	// Whether destroy plans can be queued on the workspace.
 	AllowDestroyPlan *bool `jsonapi:"attribute" json:"allow-destroy-plan,omitempty"`
 
+	// Whether health assessments are enabled for the workspace.
+	AssessmentsEnabled *bool `jsonapi:"attribute" json:"assessments-enabled,omitempty"`
+
 	// Whether to automatically apply changes when a Terraform plan is successful.
 	AutoApply *bool `jsonapi:"attribute" json:"auto-apply,omitempty"`
 
@@ -199,6 +206,10 @@ type TFEWorkspaceCreateOptions struct {
 	// Organization the workspace belongs to. Required.
 	Organization *organization.Name `schema:"organization_name"`
 
+	// Priority of the workspace's jobs relative to jobs of the same
+	// kind belonging to other workspaces. Not part of the TFE API.
+	Priority *int `jsonapi:"attribute" json:"priority,omitempty"`
+
 	// Whether to queue all runs. Unless this is set to true, runs triggered by
 	// a webhook will not be queued until at least one run is manually queued.
 	QueueAllRuns *bool `jsonapi:"attribute" json:"queue-all-runs,omitempty"`
@@ -250,6 +261,10 @@ type TFEWorkspaceCreateOptions struct {
 	// A list of tags to attach to the workspace. If the tag does not already
 	// exist, it is created and added to the workspace.
 	Tags []*TFETag `jsonapi:"relationship" json:"tags,omitempty"`
+
+	// The project to which the workspace belongs. If omitted, the workspace
+	// does not belong to a project.
+	Project *project.TFEProject `jsonapi:"relationship" json:"project,omitempty"`
 }
 
 // TFEWorkspaceUpdateOptions represents the options for updating a workspace.
@@ -268,6 +283,9 @@ type TFEWorkspaceUpdateOptions struct {
 	// Whether destroy plans can be queued on the workspace.
 	AllowDestroyPlan *bool `jsonapi:"attribute" json:"allow-destroy-plan,omitempty"`
 
+	// Whether health assessments are enabled for the workspace.
+	AssessmentsEnabled *bool `jsonapi:"attribute" json:"assessments-enabled,omitempty"`
+
 	// Whether to automatically apply changes when a Terraform plan is successful.
 	AutoApply *bool `jsonapi:"attribute" json:"auto-apply,omitempty"`
 
@@ -302,6 +320,10 @@ type TFEWorkspaceUpdateOptions struct {
 	// Use ExecutionMode instead.
 	Operations *bool `jsonapi:"attribute" json:"operations,omitempty"`
 
+	// Priority of the workspace's jobs relative to jobs of the same
+	// kind belonging to other workspaces. Not part of the TFE API.
+	Priority *int `jsonapi:"attribute" json:"priority,omitempty"`
+
 	// Whether to queue all runs. Unless this is set to true, runs triggered by
 	// a webhook will not be queued until at least one run is manually queued.
 	QueueAllRuns *bool `jsonapi:"attribute" json:"queue-all-runs,omitempty"`
@@ -341,6 +363,9 @@ type TFEWorkspaceUpdateOptions struct {
 	// the environment when multiple environments exist within the same
 	// repository.
 	WorkingDirectory *string `jsonapi:"attribute" json:"working-directory,omitempty"`
+
+	// The project to move the workspace into.
+	Project *project.TFEProject `jsonapi:"relationship" json:"project,omitempty"`
 }
 
 func (opts *TFEWorkspaceUpdateOptions) Validate() error {
@@ -467,6 +492,7 @@ func ToTFE(a *authz.Authorizer, from *Workspace, r *http.Request) (*TFEWorkspace
 			IsDestroyable: true,
 		},
 		AllowDestroyPlan:     from.AllowDestroyPlan,
+		AssessmentsEnabled:   from.AssessmentsEnabled,
 		AgentPoolID:          from.Mode.AgentPoolID(),
 		AutoApply:            from.AutoApply,
 		AutoApplyRunTrigger:  from.AutoApplyRunTrigger,
@@ -482,6 +508,7 @@ func ToTFE(a *authz.Authorizer, from *Workspace, r *http.Request) (*TFEWorkspace
 		// Operations is deprecated but clients and go-tfe tests still use it
 		Operations:                 from.Mode.Kind() == execution.RemoteKind,
 		Permissions:                perms,
+		Priority:                   from.Priority,
 		QueueAllRuns:               from.QueueAllRuns,
 		SpeculativeEnabled:         from.SpeculativeEnabled,
 		SourceName:                 from.SourceName,
@@ -508,6 +535,9 @@ func ToTFE(a *authz.Authorizer, from *Workspace, r *http.Request) (*TFEWorkspace
 	if from.SSHKeyID != nil {
 		to.SSHKey = &sshkey.TFESSHKey{ID: *from.SSHKeyID}
 	}
+	if from.ProjectID != nil {
+		to.Project = &project.TFEProject{ID: *from.ProjectID}
+	}
 
 	// Add VCS repo to json:api struct if connected. NOTE: the terraform CLI
 	// uses the presence of VCS repo to determine whether to allow a terraform
@@ -14,6 +14,7 @@ import (
 	"github.com/leg100/otf/internal/http/decode"
 	"github.com/leg100/otf/internal/organization"
 	"github.com/leg100/otf/internal/path"
+	"github.com/leg100/otf/internal/project"
 	"github.com/leg100/otf/internal/resource"
 	runpkg "github.com/leg100/otf/internal/run"
 	"github.com/leg100/otf/internal/sshkey"
@@ -51,7 +52,10 @@ type WorkspaceService interface {
 	GetVCSProvider(ctx context.Context, id resource.TfeID) (*vcs.Provider, error)
 	ListVCSProviders(ctx context.Context, organization organization.Name) ([]*vcs.Provider, error)
 	GetLatest(ctx context.Context, e *enginepkg.Engine) (string, time.Time, error)
+	ListEngines(ctx context.Context) ([]*enginepkg.Engine, error)
 	ListSSHKeys(ctx context.Context, org organization.Name) ([]*sshkey.SSHKey, error)
+	GetProject(ctx context.Context, id resource.TfeID) (*project.Project, error)
+	ListProjects(ctx context.Context, org organization.Name) ([]*project.Project, error)
 	GetRun(ctx context.Context, id resource.TfeID) (*runpkg.Run, error)
 }
 
@@ -117,6 +121,13 @@ func (h *Handlers) listWorkspaces(w http.ResponseWriter, r *http.Request) {
 		tagStrings[i] = tag.Name
 	}
 
+	// retrieve all projects for listing in project filter
+	projects, err := h.Client.ListProjects(r.Context(), *params.Organization)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
 	page, err := h.Client.ListWorkspaces(r.Context(), params.ListOptions)
 	if err != nil {
 		helpers.Error(r, w, err.Error())
@@ -131,6 +142,8 @@ func (h *Handlers) listWorkspaces(w http.ResponseWriter, r *http.Request) {
 		status:              params.Status,
 		statusFilterVisible: params.StatusFilterVisible,
 		tagFilterVisible:    params.TagFilterVisible,
+		projects:            projects,
+		selectedProject:     params.ProjectID,
 		pageOptions:         params.PageOptions,
 		page:                page,
 	}
@@ -257,8 +270,18 @@ func (h *Handlers) getWorkspace(w http.ResponseWriter, r *http.Request) {
 		latestRunTable = h.SingleRunTable(run)
 	}
 
+	var prj *project.Project
+	if ws.ProjectID != nil {
+		prj, err = h.Client.GetProject(r.Context(), *ws.ProjectID)
+		if err != nil {
+			helpers.Error(r, w, err.Error())
+			return
+		}
+	}
+
 	props := workspaceGetProps{
 		ws:                 ws,
+		project:            prj,
 		workspaceLockInfo:  lockInfo,
 		vcsProvider:        provider,
 		canApply:           h.Authorizer.CanAccess(r.Context(), resource.Apply, resource.RunKind, ws.ID),
@@ -327,9 +350,16 @@ func (h *Handlers) editWorkspace(w http.ResponseWriter, r *http.Request) {
 		poolsURL += "?agent_pool_id=" + ws.Mode.AgentPoolID().String()
 	}
 
+	projects, err := h.Client.ListProjects(r.Context(), ws.Organization)
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
 	props := workspaceEditProps{
 		ws:       ws,
 		poolsURL: poolsURL,
+		projects: projects,
 	}
 	helpers.RenderPage(
 		workspaceEdit(props),
@@ -357,6 +387,10 @@ func (h *Handlers) updateWorkspace(w http.ResponseWriter, r *http.Request) {
 		WorkingDirectory      string             `schema:"working_directory"`
 		WorkspaceID           resource.TfeID     `schema:"workspace_id,required"`
 		GlobalRemoteState     bool               `schema:"global_remote_state"`
+		AssessmentsEnabled    bool               `schema:"assessments_enabled"`
+		StructuredRunOutput   bool               `schema:"structured_run_output_enabled"`
+		Priority              int                `schema:"priority"`
+		ProjectID             resource.TfeID     `schema:"project_id"`
 	}
 	if err := decode.All(&params, r); err != nil {
 		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
@@ -364,13 +398,21 @@ func (h *Handlers) updateWorkspace(w http.ResponseWriter, r *http.Request) {
 	}
 
 	opts := workspace.UpdateOptions{
-		AutoApply:         &params.AutoApply,
-		Name:              &params.Name,
-		Description:       &params.Description,
-		ExecutionKind:     &params.ExecutionKind,
-		Engine:            params.Engine,
-		WorkingDirectory:  &params.WorkingDirectory,
-		GlobalRemoteState: &params.GlobalRemoteState,
+		AutoApply:                  &params.AutoApply,
+		Name:                       &params.Name,
+		Description:                &params.Description,
+		ExecutionKind:              &params.ExecutionKind,
+		Engine:                     params.Engine,
+		WorkingDirectory:           &params.WorkingDirectory,
+		GlobalRemoteState:          &params.GlobalRemoteState,
+		AssessmentsEnabled:         &params.AssessmentsEnabled,
+		Priority:                   &params.Priority,
+		StructuredRunOutputEnabled: &params.StructuredRunOutput,
+	}
+	// an empty project ID removes the workspace from its project
+	opts.UpdateProjectOptions = &workspace.UpdateProjectOptions{}
+	if !params.ProjectID.IsZero() {
+		opts.UpdateProjectOptions.ProjectID = &params.ProjectID
 	}
 	if params.LatestEngineVersion {
 		opts.EngineVersion = &workspace.Version{Latest: true}
@@ -749,6 +791,7 @@ func (h *Handlers) unsetWorkspacePermission(w http.ResponseWriter, r *http.Reque
 
 type engine struct {
 	name    string
+	custom  bool
 	latest  bool
 	version string
 }
@@ -766,11 +809,18 @@ func (h *Handlers) editEngine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	available, err := h.Client.ListEngines(r.Context())
+	if err != nil {
+		helpers.Error(r, w, err.Error())
+		return
+	}
+
 	// Construct list of engines for template
-	engines := make([]engine, len(enginepkg.Engines()))
+	engines := make([]engine, len(available))
 	current := ""
-	for i, engine := range enginepkg.Engines() {
+	for i, engine := range available {
 		engines[i].name = engine.String()
+		engines[i].custom = engine.Custom()
 		if engine.String() == ws.Engine.String() {
 			current = engine.String()
 			engines[i].latest = ws.EngineVersion.Latest
@@ -889,6 +939,8 @@ func (h *Handlers) updateVCS(w http.ResponseWriter, r *http.Request) {
 		CustomTagsRegex     string         `schema:"custom_tags_regex"`
 		AllowCLIApply       bool           `schema:"allow_cli_apply"`
 		SpeculativeEnabled  bool           `schema:"speculative_enabled"`
+		PullRequestComments bool           `schema:"pull_request_comments"`
+		PullRequestCommands bool           `schema:"pull_request_commands"`
 	}
 	if err := decode.All(&params, r); err != nil {
 		helpers.Error(r, w, err.Error(), helpers.WithStatus(http.StatusUnprocessableEntity))
@@ -909,8 +961,10 @@ func (h *Handlers) updateVCS(w http.ResponseWriter, r *http.Request) {
 	if ws.Connection != nil {
 		// workspace is connected, so set connection fields
 		opts.ConnectOptions = &workspace.ConnectOptions{
-			AllowCLIApply: &params.AllowCLIApply,
-			Branch:        &params.VCSBranch,
+			AllowCLIApply:       &params.AllowCLIApply,
+			PullRequestComments: &params.PullRequestComments,
+			PullRequestCommands: &params.PullRequestCommands,
+			Branch:              &params.VCSBranch,
 		}
 		switch params.VCSTriggerStrategy {
 		case vcsTriggerAlways:
@@ -28,6 +28,7 @@ type (
 		CreatedAt                  time.Time         `jsonapi:"attribute" json:"created_at"`
 		UpdatedAt                  time.Time         `jsonapi:"attribute" json:"updated_at"`
 		AllowDestroyPlan           bool              `jsonapi:"attribute" json:"allow_destroy_plan"`
+		AssessmentsEnabled         bool              `jsonapi:"attribute" json:"assessments_enabled"`
 		AutoApply                  bool              `jsonapi:"attribute" json:"auto_apply"`
 		AutoApplyRunTrigger        bool              `jsonapi:"attribute" json:"auto_apply_run_trigger"`
 		CanQueueDestroyPlan        bool              `jsonapi:"attribute" json:"can_queue_destroy_plan"`
@@ -50,9 +51,21 @@ type (
 		EngineVersion              *Version          `jsonapi:"attribute" json:"engine_version"`
 		Mode                       execution.Mode    `jsonapi:"attribute" json:"mode"`
 
+		// Assessment is the outcome of the most recent health assessment, or
+		// nil if the workspace has not been assessed.
+		Assessment *Assessment `jsonapi:"attribute" json:"assessment"`
+
 		// SSHKeyID is the ID of the SSH key assigned to this workspace, if any.
 		SSHKeyID *resource.TfeID `jsonapi:"attribute" json:"ssh_key_id"`
 
+		// ProjectID is the ID of the project the workspace belongs to, if any.
+		ProjectID *resource.TfeID `jsonapi:"attribute" json:"project_id"`
+
+		// Priority of the workspace's jobs relative to jobs of the same kind
+		// belonging to other workspaces; jobs with a higher priority are
+		// allocated to runners first.
+		Priority int `jsonapi:"attribute" json:"priority"`
+
 		// VCS Connection; nil means the workspace is not connected.
 		Connection *Connection
 
@@ -84,15 +97,34 @@ type (
 		// possible to run a terraform apply via the CLI. Setting this to true
 		// overrides this behaviour.
 		AllowCLIApply bool
+
+		// Post a comment to pull requests summarising the plan of each
+		// speculative run triggered by the pull request.
+		PullRequestComments bool
+
+		// Permit plans and applies to be triggered by commenting on pull
+		// requests.
+		PullRequestCommands bool
+	}
+
+	// PullRequestLock identifies a pull request holding a workspace lock. A pull
+	// request locks a workspace when it is planned from a pull request
+	// comment, and holds the lock until it is merged or closed, or until the
+	// lock is released with a comment.
+	PullRequestLock struct {
+		Repo   vcs.Repo
+		Number int
 	}
 
 	ConnectOptions struct {
 		RepoPath      *vcs.Repo
 		VCSProviderID *resource.TfeID
 
-		Branch        *string
-		TagsRegex     *string
-		AllowCLIApply *bool
+		Branch              *string
+		TagsRegex           *string
+		AllowCLIApply       *bool
+		PullRequestComments *bool
+		PullRequestCommands *bool
 	}
 
 	ExecutionMode string
@@ -101,6 +133,7 @@ type (
 	CreateOptions struct {
 		AgentPoolID                *resource.TfeID
 		AllowDestroyPlan           *bool
+		AssessmentsEnabled         *bool
 		AutoApply                  *bool
 		AutoApplyRunTrigger        *bool
 		Description                *string
@@ -121,6 +154,8 @@ type (
 		WorkingDirectory           *string
 		Organization               *organization.Name
 		SSHKeyID                   *resource.TfeID
+		ProjectID                  *resource.TfeID
+		Priority                   *int
 
 		// Always trigger runs. A value of true is mutually exclusive with
 		// setting TriggerPatterns or ConnectOptions.TagsRegex.
@@ -132,6 +167,7 @@ type (
 	UpdateOptions struct {
 		AgentPoolID                *resource.TfeID `json:"agent-pool-id,omitempty"`
 		AllowDestroyPlan           *bool
+		AssessmentsEnabled         *bool
 		AutoApply                  *bool
 		AutoApplyRunTrigger        *bool
 		Name                       *string
@@ -139,6 +175,7 @@ type (
 		ExecutionKind              *execution.Kind `json:"execution-mode,omitempty"`
 		GlobalRemoteState          *bool
 		Operations                 *bool
+		Priority                   *int
 		QueueAllRuns               *bool
 		SpeculativeEnabled         *bool
 		StructuredRunOutputEnabled *bool
@@ -164,6 +201,10 @@ type (
 		// updateSSHKeyOptions, if non-nil, either assigns or unassigns an SSH
 		// key to the workspace.
 		*UpdateSSHKeyOptions
+
+		// UpdateProjectOptions, if non-nil, either moves the workspace into a
+		// project or removes it from its project.
+		*UpdateProjectOptions
 	}
 
 	UpdateSSHKeyOptions struct {
@@ -172,6 +213,12 @@ type (
 		SSHKeyID *resource.TfeID
 	}
 
+	UpdateProjectOptions struct {
+		// ProjectID, if non-nil, is the project the workspace is moved into;
+		// if nil the workspace is removed from its project.
+		ProjectID *resource.TfeID
+	}
+
 	// ListOptions are options for paginating and filtering a list of
 	// Workspaces
 	ListOptions struct {
@@ -179,6 +226,7 @@ type (
 		Tags         []string           `schema:"search[tags]"`
 		Status       []runstatus.Status `schema:"search[status]"`
 		Organization *organization.Name `schema:"organization_name"`
+		ProjectID    *resource.TfeID    `schema:"filter[project][id]"`
 
 		resource.PageOptions
 	}
@@ -201,6 +249,7 @@ type (
 
 	factoryClient interface {
 		GetLatest(ctx context.Context, engine *engine.Engine) (string, time.Time, error)
+		GetEngine(ctx context.Context, name string) (*engine.Engine, error)
 		GetOrganization(ctx context.Context, name organization.Name) (*organization.Organization, error)
 	}
 )
@@ -245,6 +294,9 @@ func (f *factory) NewWorkspace(ctx context.Context, opts CreateOptions) (*Worksp
 	if opts.AllowDestroyPlan != nil {
 		ws.AllowDestroyPlan = *opts.AllowDestroyPlan
 	}
+	if opts.AssessmentsEnabled != nil {
+		ws.AssessmentsEnabled = *opts.AssessmentsEnabled
+	}
 	if opts.AutoApply != nil {
 		ws.AutoApply = *opts.AutoApply
 	}
@@ -257,6 +309,9 @@ func (f *factory) NewWorkspace(ctx context.Context, opts CreateOptions) (*Worksp
 	if opts.GlobalRemoteState != nil {
 		ws.GlobalRemoteState = *opts.GlobalRemoteState
 	}
+	if opts.Priority != nil {
+		ws.Priority = *opts.Priority
+	}
 	if opts.QueueAllRuns != nil {
 		ws.QueueAllRuns = *opts.QueueAllRuns
 	}
@@ -286,12 +341,27 @@ func (f *factory) NewWorkspace(ctx context.Context, opts CreateOptions) (*Worksp
 		// TODO: use constructor
 		ws.EngineVersion = &Version{semver: latest}
 	}
+	eng, err := f.client.GetEngine(ctx, ws.Engine.String())
+	if err != nil {
+		return nil, err
+	}
+	if !ws.EngineVersion.Latest {
+		if err := eng.CheckVersion(ws.EngineVersion.String()); err != nil {
+			return nil, err
+		}
+		if err := org.CheckEngineVersion(ws.EngineVersion.String()); err != nil {
+			return nil, err
+		}
+	}
 	if opts.WorkingDirectory != nil {
 		ws.WorkingDirectory = *opts.WorkingDirectory
 	}
 	if opts.SSHKeyID != nil {
 		ws.SSHKeyID = opts.SSHKeyID
 	}
+	if opts.ProjectID != nil {
+		ws.ProjectID = opts.ProjectID
+	}
 	// TriggerPrefixes are not used but OTF persists it in order to pass go-tfe
 	// integration tests.
 	if opts.TriggerPrefixes != nil {
@@ -341,12 +411,13 @@ func (ws *Workspace) Locked() bool {
 	return ws.Lock != nil
 }
 
-// Enlock locks the workspace with the given ID. The ID must be either a run or user ID.
+// Enlock locks the workspace with the given ID. The ID must be either a run,
+// user or pull request ID.
 func (ws *Workspace) Enlock(id resource.ID) error {
 	switch id.Kind() {
-	case resource.UserKind, resource.RunKind:
+	case resource.UserKind, resource.RunKind, resource.PullRequestKind:
 	default:
-		return errors.New("workspace can only be locked by a user or a run")
+		return errors.New("workspace can only be locked by a user, a run or a pull request")
 	}
 	if ws.Lock == nil {
 		ws.Lock = id
@@ -357,16 +428,20 @@ func (ws *Workspace) Enlock(id resource.ID) error {
 		ws.Lock = id
 		return nil
 	}
+	// a pull request can lock a workspace it has already locked
+	if id.Kind() == resource.PullRequestKind && ws.Lock == id {
+		return nil
+	}
 	return ErrWorkspaceAlreadyLocked
 }
 
-// Unlock the workspace with the given ID. The ID must be either a run or user
-// ID.
+// Unlock the workspace with the given ID. The ID must be either a run, user or
+// pull request ID.
 func (ws *Workspace) Unlock(id resource.ID, force bool) error {
 	switch id.Kind() {
-	case resource.UserKind, resource.RunKind:
+	case resource.UserKind, resource.RunKind, resource.PullRequestKind:
 	default:
-		return errors.New("workspace can only be unlocked by a user or a run")
+		return errors.New("workspace can only be unlocked by a user, a run or a pull request")
 	}
 	if ws.Lock == nil {
 		return ErrWorkspaceAlreadyUnlocked
@@ -381,12 +456,27 @@ func (ws *Workspace) Unlock(id resource.ID, force bool) error {
 		ws.Lock = nil
 		return nil
 	}
-	if ws.Lock.Kind() == resource.RunKind {
+	switch ws.Lock.Kind() {
+	case resource.RunKind:
 		return ErrWorkspaceLockedByRun
+	case resource.PullRequestKind:
+		return ErrWorkspaceLockedByPullRequest
 	}
 	return ErrWorkspaceLockedByDifferentUser
 }
 
+// LockedByPullRequest determines whether the workspace is locked by the given
+// pull request.
+func (ws *Workspace) LockedByPullRequest(repo vcs.Repo, pull int) bool {
+	return ws.Lock == PullRequestLock{Repo: repo, Number: pull}
+}
+
+func (l PullRequestLock) String() string {
+	return fmt.Sprintf("%s#%d", l.Repo, l.Number)
+}
+
+func (l PullRequestLock) Kind() resource.Kind { return resource.PullRequestKind }
+
 // LogValue implements slog.LogValuer.
 func (ws *Workspace) LogValue() slog.Value {
 	return slog.GroupValue(
@@ -412,6 +502,10 @@ func (ws *Workspace) Update(opts UpdateOptions) (*bool, error) {
 		ws.AllowDestroyPlan = *opts.AllowDestroyPlan
 		updated = true
 	}
+	if opts.AssessmentsEnabled != nil {
+		ws.AssessmentsEnabled = *opts.AssessmentsEnabled
+		updated = true
+	}
 	if opts.AutoApply != nil {
 		ws.AutoApply = *opts.AutoApply
 		updated = true
@@ -442,6 +536,10 @@ func (ws *Workspace) Update(opts UpdateOptions) (*bool, error) {
 		ws.GlobalRemoteState = *opts.GlobalRemoteState
 		updated = true
 	}
+	if opts.Priority != nil {
+		ws.Priority = *opts.Priority
+		updated = true
+	}
 	if opts.QueueAllRuns != nil {
 		ws.QueueAllRuns = *opts.QueueAllRuns
 		updated = true
@@ -470,6 +568,10 @@ func (ws *Workspace) Update(opts UpdateOptions) (*bool, error) {
 		ws.SSHKeyID = opts.UpdateSSHKeyOptions.SSHKeyID
 		updated = true
 	}
+	if opts.UpdateProjectOptions != nil {
+		ws.ProjectID = opts.UpdateProjectOptions.ProjectID
+		updated = true
+	}
 	// TriggerPrefixes are not used but OTF persists it in order to pass go-tfe
 	// integration tests.
 	if opts.TriggerPrefixes != nil {
@@ -543,6 +645,14 @@ func (ws *Workspace) Update(opts UpdateOptions) (*bool, error) {
 				ws.Connection.AllowCLIApply = *opts.AllowCLIApply
 				updated = true
 			}
+			if opts.PullRequestComments != nil {
+				ws.Connection.PullRequestComments = *opts.PullRequestComments
+				updated = true
+			}
+			if opts.PullRequestCommands != nil {
+				ws.Connection.PullRequestCommands = *opts.PullRequestCommands
+				updated = true
+			}
 		}
 	}
 	if updated {
@@ -566,6 +676,12 @@ func (ws *Workspace) addConnection(opts *ConnectOptions) error {
 	if opts.AllowCLIApply != nil {
 		ws.Connection.AllowCLIApply = *opts.AllowCLIApply
 	}
+	if opts.PullRequestComments != nil {
+		ws.Connection.PullRequestComments = *opts.PullRequestComments
+	}
+	if opts.PullRequestCommands != nil {
+		ws.Connection.PullRequestCommands = *opts.PullRequestCommands
+	}
 	if opts.TagsRegex != nil {
 		if err := ws.setTagsRegex(*opts.TagsRegex); err != nil {
 			return fmt.Errorf("invalid tags-regex: %w", err)
@@ -476,6 +476,8 @@ var (
 	burglarTestID = user.MustUsername("burglar")
 	runTestID1    = resource.NewTfeID(resource.RunKind)
 	runTestID2    = resource.NewTfeID(resource.RunKind)
+	pullTestLock1 = PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 1}
+	pullTestLock2 = PullRequestLock{Repo: vcs.NewMustRepo("leg100", "otf"), Number: 2}
 )
 
 func TestWorkspace_Lock(t *testing.T) {
@@ -497,6 +499,22 @@ func TestWorkspace_Lock(t *testing.T) {
 		err := ws.Enlock(bobby)
 		require.Equal(t, ErrWorkspaceAlreadyLocked, err)
 	})
+	t.Run("pull request can lock a workspace it has already locked", func(t *testing.T) {
+		ws := &Workspace{Lock: pullTestLock1}
+		err := ws.Enlock(pullTestLock1)
+		require.NoError(t, err)
+		assert.True(t, ws.LockedByPullRequest(pullTestLock1.Repo, pullTestLock1.Number))
+	})
+	t.Run("pull request cannot lock a workspace locked by another pull request", func(t *testing.T) {
+		ws := &Workspace{Lock: pullTestLock1}
+		err := ws.Enlock(pullTestLock2)
+		require.Equal(t, ErrWorkspaceAlreadyLocked, err)
+	})
+	t.Run("run cannot lock a workspace locked by a pull request", func(t *testing.T) {
+		ws := &Workspace{Lock: pullTestLock1}
+		err := ws.Enlock(runTestID1)
+		require.Equal(t, ErrWorkspaceAlreadyLocked, err)
+	})
 }
 
 func TestWorkspace_Unlock(t *testing.T) {
@@ -527,4 +545,15 @@ func TestWorkspace_Unlock(t *testing.T) {
 		require.NoError(t, err)
 		assert.False(t, ws.Locked())
 	})
+	t.Run("pull request can unlock its own lock", func(t *testing.T) {
+		ws := &Workspace{Lock: pullTestLock1}
+		err := ws.Unlock(pullTestLock1, false)
+		require.NoError(t, err)
+		assert.False(t, ws.Locked())
+	})
+	t.Run("user cannot unlock a pull request's lock", func(t *testing.T) {
+		ws := &Workspace{Lock: pullTestLock1}
+		err := ws.Unlock(bobby, false)
+		require.Equal(t, ErrWorkspaceLockedByPullRequest, err)
+	})
 }